@@ -0,0 +1,538 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"r3/config"
+	"r3/db"
+	"r3/tools"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	redacted = json.RawMessage(`"[redacted]"`)
+
+	// payload keys containing secrets (lower case, without underscores), matched if key contains or ends with value
+	secretKeysContain = []string{"pass", "privatekey", "salt", "secret"}
+	secretKeysSuffix  = []string{"hash", "pw", "token"}
+
+	// requests with payloads that are secret as a whole
+	secretRequests = map[string][]string{
+		"loginExportKey": {"set"},
+		"transfer":       {"storeExportKey"},
+	}
+
+	// tables storing the objects of admin ressources, to compare their states before & after changes
+	// objects with large contents (such as icons) are left out
+	ressourceTables = map[string]string{
+		"api":           "app.api",
+		"article":       "app.article",
+		"attribute":     "app.attribute",
+		"clientEvent":   "app.client_event",
+		"collection":    "app.collection",
+		"form":          "app.form",
+		"jsFunction":    "app.js_function",
+		"ldap":          "instance.ldap",
+		"login":         "instance.login",
+		"loginForm":     "app.login_form",
+		"loginTemplate": "instance.login_template",
+		"mailAccount":   "instance.mail_account",
+		"menuTab":       "app.menu_tab",
+		"module":        "app.module",
+		"oauthClient":   "instance.oauth_client",
+		"pgFunction":    "app.pg_function",
+		"pgIndex":       "app.pg_index",
+		"pgTrigger":     "app.pg_trigger",
+		"preset":        "app.preset",
+		"relation":      "app.relation",
+		"role":          "app.role",
+		"samlIdp":       "instance.saml_idp",
+		"scimClient":    "instance.scim_client",
+		"searchBar":     "app.search_bar",
+		"variable":      "app.variable",
+		"widget":        "app.widget",
+	}
+)
+
+// fixed key of advisory lock, which keeps the hash chain linear
+const advisoryLockChain int64 = 7_345_102_877
+
+// writes audit log entry in its own transaction
+// used for admin actions outside of websocket transactions
+func Write(ctx context.Context, loginId int64, address string, ressource string, action string, payload json.RawMessage) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	l, err := Create_tx(ctx, tx, loginId, address, ressource, action, payload, nil)
+	if err != nil {
+		return err
+	}
+	if err := Write_tx(ctx, tx, []types.AuditLog{l}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// returns current state of the object affected by a request, secrets redacted
+// empty if the ressource has no stored state or the object does not exist
+func GetState_tx(ctx context.Context, tx pgx.Tx, ressource string, payload json.RawMessage) (json.RawMessage, error) {
+	table, exists := ressourceTables[ressource]
+	if !exists {
+		return nil, nil
+	}
+	objectId := getObjectId(payload)
+	if !objectId.Valid {
+		return nil, nil
+	}
+
+	var state []byte
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT ROW_TO_JSON(t)
+		FROM %s AS t
+		WHERE t.id::TEXT = $1
+	`, table), objectId.String).Scan(&state); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return redact(state), nil
+}
+
+// creates audit log entry for an executed request, not chained or stored yet
+// state before is compared with the current state of the affected object, which includes the changes of the request
+func Create_tx(ctx context.Context, tx pgx.Tx, loginId int64, address string, ressource string, action string,
+	payload json.RawMessage, stateBefore json.RawMessage) (types.AuditLog, error) {
+
+	var l types.AuditLog
+	l.Date = tools.GetTimeUnixMilli()
+	l.Address = address
+	l.Ressource = ressource
+	l.Action = action
+
+	if loginId != 0 {
+		l.LoginId = pgtype.Int8{Int64: loginId, Valid: true}
+		if err := tx.QueryRow(ctx, `
+			SELECT name
+			FROM instance.login
+			WHERE id = $1
+		`, loginId).Scan(&l.LoginName); err != nil && err != pgx.ErrNoRows {
+			return l, err
+		}
+	}
+
+	// compare states before & after the request
+	l.ObjectId = getObjectId(payload)
+	stateAfter, err := GetState_tx(ctx, tx, ressource, payload)
+	if err != nil {
+		return l, err
+	}
+	if stateBefore != nil || stateAfter != nil {
+		if stateBefore == nil {
+			stateBefore = json.RawMessage("null")
+		}
+		if stateAfter == nil {
+			stateAfter = json.RawMessage("null")
+		}
+		diff, err := json.Marshal(getDiff(stateBefore, stateAfter))
+		if err != nil {
+			return l, err
+		}
+		l.Diff = pgtype.Text{String: string(diff), Valid: true}
+	}
+
+	// redact secrets
+	if slices.Contains(secretRequests[ressource], action) {
+		payload = redacted
+	}
+	payload = redact(payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+	l.Payload = string(payload)
+	return l, nil
+}
+
+// stores audit log entries, each chained to the previous entry by its hash
+// the chain is guarded by an advisory lock, which is held until the transaction ends
+// to keep concurrent admin transactions apart, it should be called right before committing
+func Write_tx(ctx context.Context, tx pgx.Tx, logs []types.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT PG_ADVISORY_XACT_LOCK($1)`, advisoryLockChain); err != nil {
+		return err
+	}
+
+	var idPrev int64
+	var hashPrev string
+	if err := tx.QueryRow(ctx, `
+		SELECT id, hash
+		FROM instance.audit_log
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&idPrev, &hashPrev); err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+
+	// previous entry is committed, as the chain lock is held until commit
+	if idPrev != 0 {
+		if err := setHead(chainHead{idPrev, hashPrev}); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range logs {
+		l.HashPrev = hashPrev
+
+		hash, err := getHash(l, true)
+		if err != nil {
+			return err
+		}
+		l.Hash = hash
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.audit_log (date_milli, login_id, login_name, address,
+				ressource, action, object_id, payload, diff, hash_prev, hash)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		`, l.Date, l.LoginId, l.LoginName, l.Address, l.Ressource, l.Action,
+			l.ObjectId, l.Payload, l.Diff, l.HashPrev, l.Hash); err != nil {
+
+			return err
+		}
+		hashPrev = l.Hash
+	}
+	return nil
+}
+
+func Get_tx(ctx context.Context, tx pgx.Tx, dateFrom pgtype.Int8, dateTo pgtype.Int8, limit int, offset int,
+	ressource string, byString string) ([]types.AuditLog, int, error) {
+
+	logs := make([]types.AuditLog, 0)
+	total := 0
+
+	var qb tools.QueryBuilder
+	qb.UseDollarSigns()
+	qb.AddList("SELECT", []string{"id", "date_milli", "login_id", "login_name", "address", "ressource",
+		"action", "object_id", "payload", "diff", "hash_prev", "hash"})
+	qb.SetFrom("instance.audit_log")
+
+	if ressource != "" {
+		qb.Add("WHERE", `ressource = {RESSOURCE}`)
+		qb.AddPara("{RESSOURCE}", ressource)
+	}
+
+	if byString != "" {
+		qb.Add("WHERE", `(
+			login_name ILIKE {NAME} OR
+			object_id  ILIKE {NAME} OR
+			payload    ILIKE {NAME}
+		)`)
+		qb.AddPara("{NAME}", fmt.Sprintf("%%%s%%", byString))
+	}
+
+	if dateFrom.Valid {
+		qb.Add("WHERE", "date_milli >= {DATEFROM}")
+		qb.AddPara("{DATEFROM}", dateFrom.Int64*1000)
+	}
+
+	if dateTo.Valid {
+		qb.Add("WHERE", "date_milli <= {DATETO}")
+		qb.AddPara("{DATETO}", dateTo.Int64*1000)
+	}
+
+	qb.Add("ORDER", "id DESC")
+	qb.SetOffset(offset)
+	qb.SetLimit(limit)
+
+	query, err := qb.GetQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := tx.Query(ctx, query, qb.GetParaValues()...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l, err := scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		l.Date = int64(l.Date / 1000)
+		logs = append(logs, l)
+	}
+
+	// get total count
+	qb.UseDollarSigns()
+	qb.Reset("SELECT")
+	qb.Reset("ORDER")
+	qb.Reset("LIMIT")
+	qb.Reset("OFFSET")
+	qb.Add("SELECT", "COUNT(*)")
+
+	query, err = qb.GetQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.QueryRow(ctx, query, qb.GetParaValues()...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// verifies hash chain of all audit log entries, including its anchored head
+// returns count of verified entries and ID of first entry that does not match the chain
+// if entries were removed from the end of the chain, the ID of the anchored head is returned
+func Verify_tx(ctx context.Context, tx pgx.Tx) (int64, pgtype.Int8, error) {
+
+	var count int64
+	var idPrev int64
+	var hashPrev string
+
+	// head is read before entries, as it is never newer than the committed entries
+	head, err := getHead()
+	if err != nil {
+		return 0, pgtype.Int8{}, err
+	}
+	headFound := false
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, date_milli, login_id, login_name, address, ressource,
+			action, object_id, payload, diff, hash_prev, hash
+		FROM instance.audit_log
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return 0, pgtype.Int8{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l, err := scan(rows)
+		if err != nil {
+			return 0, pgtype.Int8{}, err
+		}
+
+		hash, err := getHash(l, l.Id > config.File.Audit.UnkeyedIdMax)
+		if err != nil {
+			return 0, pgtype.Int8{}, err
+		}
+		if l.HashPrev != hashPrev || l.Hash != hash || (l.Id == head.Id && l.Hash != head.Hash) {
+			return count, pgtype.Int8{Int64: l.Id, Valid: true}, nil
+		}
+		if l.Id == head.Id {
+			headFound = true
+		}
+		idPrev = l.Id
+		hashPrev = l.Hash
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, pgtype.Int8{}, err
+	}
+
+	if head.Id != 0 && !headFound {
+		return count, pgtype.Int8{Int64: head.Id, Valid: true}, nil
+	}
+
+	// verified chain is anchored as well
+	if idPrev != 0 {
+		if err := setHead(chainHead{idPrev, hashPrev}); err != nil {
+			return 0, pgtype.Int8{}, err
+		}
+	}
+	return count, pgtype.Int8{}, nil
+}
+
+// writes audit log entries as JSON lines, oldest entry first
+// dates are kept in milliseconds, as hashes are calculated with them
+func Export(ctx context.Context, w io.Writer, dateFrom pgtype.Int8, dateTo pgtype.Int8) error {
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, date_milli, login_id, login_name, address, ressource,
+			action, object_id, payload, diff, hash_prev, hash
+		FROM instance.audit_log
+		WHERE ($1::BIGINT IS NULL OR date_milli >= $1 * 1000)
+		AND   ($2::BIGINT IS NULL OR date_milli <= $2 * 1000)
+		ORDER BY id ASC
+	`, dateFrom, dateTo)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	enc := json.NewEncoder(w)
+	for rows.Next() {
+		l, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(l); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scan(rows pgx.Rows) (types.AuditLog, error) {
+	var l types.AuditLog
+	err := rows.Scan(&l.Id, &l.Date, &l.LoginId, &l.LoginName, &l.Address, &l.Ressource,
+		&l.Action, &l.ObjectId, &l.Payload, &l.Diff, &l.HashPrev, &l.Hash)
+
+	return l, err
+}
+
+// hash over all entry values, except ID & hash itself
+// keyed hashes (HMAC) use the audit key from the configuration file, which is not stored in the database
+// date is expected in milliseconds, as stored
+func getHash(l types.AuditLog, keyed bool) (string, error) {
+	l.Id = 0
+	l.Hash = ""
+
+	b, err := json.Marshal(l)
+	if err != nil {
+		return "", err
+	}
+	if !keyed {
+		hash := sha256.Sum256(b)
+		return hex.EncodeToString(hash[:]), nil
+	}
+	mac := hmac.New(sha256.New, []byte(config.File.Audit.Key))
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// returns ID of affected object from request payload, if available
+// payload is either an object with ID or the ID itself (as for deletions)
+func getObjectId(payload json.RawMessage) pgtype.Text {
+	id := payload
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &obj); err == nil {
+		id = obj["id"]
+	}
+
+	var v any
+	if err := json.Unmarshal(id, &v); err != nil {
+		return pgtype.Text{}
+	}
+	switch v.(type) {
+	case float64, string:
+		text := strings.Trim(string(id), `"`)
+		if text == "" || text == "0" || text == "00000000-0000-0000-0000-000000000000" {
+			return pgtype.Text{}
+		}
+		return pgtype.Text{String: text, Valid: true}
+	}
+	return pgtype.Text{}
+}
+
+// returns changed top level keys of JSON objects
+func getDiff(before json.RawMessage, after json.RawMessage) []types.AuditLogDiff {
+	diffs := make([]types.AuditLogDiff, 0)
+
+	var objBefore, objAfter map[string]json.RawMessage
+	if json.Unmarshal(before, &objBefore) != nil || json.Unmarshal(after, &objAfter) != nil {
+		if !jsonEqual(before, after) {
+			diffs = append(diffs, types.AuditLogDiff{Before: before, After: after})
+		}
+		return diffs
+	}
+
+	keys := make([]string, 0)
+	for k := range objBefore {
+		keys = append(keys, k)
+	}
+	for k := range objAfter {
+		if _, exists := objBefore[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		b, a := objBefore[k], objAfter[k]
+		if b == nil {
+			b = json.RawMessage("null")
+		}
+		if a == nil {
+			a = json.RawMessage("null")
+		}
+		if !jsonEqual(b, a) {
+			diffs = append(diffs, types.AuditLogDiff{Key: k, Before: b, After: a})
+		}
+	}
+	return diffs
+}
+
+func jsonEqual(a json.RawMessage, b json.RawMessage) bool {
+	var bufA, bufB bytes.Buffer
+	if json.Compact(&bufA, a) != nil || json.Compact(&bufB, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
+
+// replaces values of secret keys in JSON payload, recursively
+func redact(payload json.RawMessage) json.RawMessage {
+	var v any
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return payload
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return redacted
+	}
+	return b
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, sub := range t {
+			if sub != nil && sub != "" && isSecretKey(k) {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactValue(sub)
+		}
+	case []any:
+		for i, sub := range t {
+			t[i] = redactValue(sub)
+		}
+	}
+	return v
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "_", "")
+	for _, s := range secretKeysContain {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	for _, s := range secretKeysSuffix {
+		if strings.HasSuffix(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"r3/config"
+	"r3/log"
+	"r3/tools"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// the chain head is anchored in a file next to the configuration file, outside of the database
+// entries cut from the end of the chain are detected by comparing the chain with its anchored head
+var head_mx = &sync.Mutex{}
+
+type chainHead struct {
+	Id   int64  `json:"id"`
+	Hash string `json:"hash"`
+}
+
+// creates key for audit log hashes if it does not exist yet
+// the key is stored in the configuration file, entries already written keep their unkeyed hashes
+func InitKey_tx(ctx context.Context, tx pgx.Tx) error {
+	if config.File.Audit.Key != "" {
+		return nil
+	}
+
+	// no entries must be written while the key is introduced
+	if _, err := tx.Exec(ctx, `SELECT PG_ADVISORY_XACT_LOCK($1)`, advisoryLockChain); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, date_milli, login_id, login_name, address, ressource,
+			action, object_id, payload, diff, hash_prev, hash
+		FROM instance.audit_log
+		ORDER BY id DESC
+		LIMIT 1
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var idMax int64
+	for rows.Next() {
+		l, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		hash, err := getHash(l, false)
+		if err != nil {
+			return err
+		}
+		if hash != l.Hash {
+			log.Warning(log.ContextServer, "audit log entries were hashed with a key, the audit key must be the same on all cluster nodes", nil)
+		}
+		idMax = l.Id
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	config.File.Audit.Key = tools.RandStringRunes(48)
+	config.File.Audit.UnkeyedIdMax = idMax
+	return config.WriteFile()
+}
+
+func getHeadFilePath() string {
+	filePath := config.GetConfigFilepath()
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + "_audit_head.json"
+}
+
+// returns anchored chain head, empty if no head was anchored yet
+func getHead() (chainHead, error) {
+	head_mx.Lock()
+	defer head_mx.Unlock()
+
+	var h chainHead
+	b, err := os.ReadFile(getHeadFilePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return h, nil
+		}
+		return h, err
+	}
+	return h, json.Unmarshal(b, &h)
+}
+
+// anchors chain head, heads older than the anchored one are ignored
+// only committed entries must be anchored
+func setHead(h chainHead) error {
+	head_mx.Lock()
+	defer head_mx.Unlock()
+
+	var hCurr chainHead
+	b, err := os.ReadFile(getHeadFilePath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &hCurr); err != nil {
+			return err
+		}
+	}
+	if h.Id <= hCurr.Id {
+		return nil
+	}
+
+	b, err = json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getHeadFilePath(), b, 0600)
+}
@@ -1,17 +1,21 @@
 package backup
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"r3/config"
+	"r3/db"
 	"r3/log"
 	"r3/tools"
 	"r3/tools/compress"
 	"r3/types"
+	"strings"
 	"sync"
 )
 
@@ -21,6 +25,7 @@ var (
 	subPathDb       = "database"         // path within backup dir for database dump
 	subPathCerts    = "certificates.zip" // path within backup dir for certificate files
 	subPathFiles    = "files.zip"        // path within backup dir for attribute files
+	subPathMeta     = "backup.json"      // path within backup dir for backup definition, never encrypted
 	subPathTransfer = "transfer.zip"     // path within backup dir for transfer files
 )
 
@@ -28,10 +33,17 @@ func Run() error {
 	access_mx.Lock()
 	defer access_mx.Unlock()
 
+	// get custom jobs
+	jobs, err := getJobsActive()
+	if err != nil {
+		return err
+	}
+
 	// check if anything is to be done
 	if config.GetUint64("backupDaily") == 0 &&
 		config.GetUint64("backupWeekly") == 0 &&
-		config.GetUint64("backupMonthly") == 0 {
+		config.GetUint64("backupMonthly") == 0 &&
+		len(jobs) == 0 {
 
 		log.Info(log.ContextBackup, "no backup jobs active, do nothing")
 		return nil
@@ -54,7 +66,7 @@ func Run() error {
 	now := tools.GetTimeUnix()
 	jobRan := false // limit to one job per run
 
-	var runOne = func(jobName string, keepVersions uint64, interval int64) error {
+	var runOne = func(jobName string, scope string, keepVersions uint64, interval int64) error {
 		log.Info(log.ContextBackup, fmt.Sprintf("is considering job '%s' for execution", jobName))
 
 		var timestampLatest int64
@@ -73,7 +85,7 @@ func Run() error {
 			log.Error(log.ContextBackup, fmt.Sprintf("could not delete old versions of job '%s'", jobName), err)
 			return err
 		}
-		if err := jobBackup(&tocFile, jobName); err != nil {
+		if err := jobBackup(&tocFile, jobName, scope); err != nil {
 			log.Error(log.ContextBackup, fmt.Sprintf("could not execute job '%s'", jobName), err)
 			return err
 		}
@@ -82,17 +94,25 @@ func Run() error {
 	}
 
 	if !jobRan && config.GetUint64("backupMonthly") == 1 {
-		if err := runOne("monthly", config.GetUint64("backupCountMonthly"), 2592000); err != nil {
+		if err := runOne("monthly", scopeFull, config.GetUint64("backupCountMonthly"), 2592000); err != nil {
 			return err
 		}
 	}
 	if !jobRan && config.GetUint64("backupWeekly") == 1 {
-		if err := runOne("weekly", config.GetUint64("backupCountWeekly"), 604800); err != nil {
+		if err := runOne("weekly", scopeFull, config.GetUint64("backupCountWeekly"), 604800); err != nil {
 			return err
 		}
 	}
 	if !jobRan && config.GetUint64("backupDaily") == 1 {
-		if err := runOne("daily", config.GetUint64("backupCountDaily"), 86400); err != nil {
+		if err := runOne("daily", scopeFull, config.GetUint64("backupCountDaily"), 86400); err != nil {
+			return err
+		}
+	}
+	for _, j := range jobs {
+		if jobRan {
+			break
+		}
+		if err := runOne(j.Name, j.Scope, uint64(j.KeepCount), int64(j.IntervalHours)*3600); err != nil {
 			return err
 		}
 	}
@@ -155,56 +175,130 @@ func jobCleanup(tocFile *types.BackupTocFile, jobName string, countKeep uint64)
 	}
 	return nil
 }
-func jobBackup(tocFile *types.BackupTocFile, jobName string) error {
+func jobBackup(tocFile *types.BackupTocFile, jobName string, scope string) error {
 	log.Info(log.ContextBackup, fmt.Sprintf("started for job '%s'", jobName))
 
+	encryptKey, err := getEncryptKey()
+	if err != nil {
+		return err
+	}
+
 	newTimestamp := tools.GetTimeUnix()
 	jobDir := getBackupJobDir(newTimestamp, jobName)
-
-	// database backup
-	dbPath := filepath.Join(jobDir, subPathDb)
-	if err := os.MkdirAll(dbPath, 0755); err != nil {
+	if err := os.MkdirAll(jobDir, 0755); err != nil {
 		return err
 	}
-	if err := dumpDb(dbPath); err != nil {
-		return err
+
+	// writes backup file, encrypted while being written if encryption is enabled
+	var writeFile = func(target string, fn func(w io.Writer) error) error {
+		if encryptKey != nil {
+			return writeEncryptedFile(target+encryptSuffix, encryptKey, fn)
+		}
+		fileOut, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer fileOut.Close()
+
+		if err := fn(fileOut); err != nil {
+			return err
+		}
+		return fileOut.Sync()
 	}
 
-	// certificates backup
-	target := filepath.Join(jobDir, subPathCerts)
-	if err := compress.Path(target, config.File.Paths.Certificates); err != nil {
-		return err
+	if scope != scopeFiles {
+		// database backup
+		dbPath := filepath.Join(jobDir, subPathDb)
+		if err := os.MkdirAll(dbPath, 0755); err != nil {
+			return err
+		}
+
+		if encryptKey == nil {
+			if err := dumpDb(dbPath); err != nil {
+				return err
+			}
+		} else {
+			// database dump (directory format for parallel dump & restore) cannot be encrypted while written
+			// dump is written to a separate, private directory, which is removed on all exit paths
+			dbPathUnencrypted, err := os.MkdirTemp(filepath.Dir(jobDir), fmt.Sprintf("%s_unencrypted_", filepath.Base(jobDir)))
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dbPathUnencrypted)
+
+			if err := dumpDb(dbPathUnencrypted); err != nil {
+				return err
+			}
+			if err := encryptDirTo(dbPathUnencrypted, dbPath, encryptKey); err != nil {
+				return err
+			}
+			if err := os.RemoveAll(dbPathUnencrypted); err != nil {
+				return err
+			}
+		}
+	}
+
+	if scope != scopeDatabase {
+		// certificates, files & transfer backups
+		for target, source := range map[string]string{
+			subPathCerts:    config.File.Paths.Certificates,
+			subPathFiles:    config.File.Paths.Files,
+			subPathTransfer: config.File.Paths.Transfer,
+		} {
+			if err := writeFile(filepath.Join(jobDir, target), func(w io.Writer) error {
+				return compress.PathToWriter(w, source)
+			}); err != nil {
+				return err
+			}
+		}
 	}
 
 	// config backup
-	target = filepath.Join(jobDir, subPathConfig)
-	if err := tools.FileCopy(config.GetConfigFilepath(), target, false); err != nil {
+	if err := writeFile(filepath.Join(jobDir, subPathConfig), func(w io.Writer) error {
+		fileIn, err := os.Open(config.GetConfigFilepath())
+		if err != nil {
+			return err
+		}
+		defer fileIn.Close()
+
+		_, err = io.Copy(w, fileIn)
+		return err
+	}); err != nil {
 		return err
 	}
 
-	// files backup
-	target = filepath.Join(jobDir, subPathFiles)
-	if err := compress.Path(target, config.File.Paths.Files); err != nil {
+	// backup definition, allows restoring backups without TOC file (like copies from remote targets)
+	// never encrypted, to be readable without private key
+	backup := types.BackupDef{
+		AppBuild:  config.GetAppVersion().Build,
+		Encrypted: encryptKey != nil,
+		JobName:   jobName,
+		Scope:     scope,
+		Targets:   make([]string, 0),
+		Timestamp: newTimestamp,
+	}
+	jsonFile, err := json.MarshalIndent(backup, "", "\t")
+	if err != nil {
 		return err
 	}
-
-	// transfer backup
-	target = filepath.Join(jobDir, subPathTransfer)
-	if err := compress.Path(target, config.File.Paths.Transfer); err != nil {
+	if err := os.WriteFile(filepath.Join(jobDir, subPathMeta), jsonFile, 0644); err != nil {
 		return err
 	}
 
 	// update TOC file
-	tocFile.Backups = append(tocFile.Backups, types.BackupDef{
-		AppBuild:  config.GetAppVersion().Build,
-		JobName:   jobName,
-		Timestamp: newTimestamp,
-	})
+	tocFile.Backups = append(tocFile.Backups, backup)
 	if err := tocFileWrite(*tocFile); err != nil {
 		return err
 	}
 	log.Info(log.ContextBackup, fmt.Sprintf("successfully completed job '%s'", jobName))
-	return nil
+
+	// upload to remote targets, local backup is kept if uploads fail
+	targets, errUpload := uploadToTargets(jobDir, jobName)
+	tocFile.Backups[len(tocFile.Backups)-1].Targets = targets
+	if err := tocFileWrite(*tocFile); err != nil {
+		return err
+	}
+	return errUpload
 }
 
 // helpers
@@ -224,6 +318,27 @@ func dumpDb(path string) error {
 	cmd.Env = append(cmd.Env, fmt.Sprintf("PGPASSWORD=%s", config.File.Db.Pass))
 	return cmd.Run()
 }
+func restoreDb(path string, dbName string) error {
+	args := []string{
+		"-h", config.File.Db.Host,
+		"-p", fmt.Sprintf("%d", config.File.Db.Port),
+		"-d", dbName,
+		"-U", config.File.Db.User,
+		"-j", "4", // number of parallel jobs
+		"-Fd", // custom format, from file directory
+		path,
+	}
+
+	cmd := exec.Command(getPgRestorePath(), args...)
+	tools.CmdAddSysProgAttrs(cmd)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("LC_MESSAGES=%s", "en_US"))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("PGPASSWORD=%s", config.File.Db.Pass))
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore database dump, %v, %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
 func TocFileReadCreate() (types.BackupTocFile, error) {
 	var tocFile = types.BackupTocFile{}
 	var path = getTocFilePath()
@@ -242,7 +357,20 @@ func TocFileReadCreate() (types.BackupTocFile, error) {
 	if err != nil {
 		return tocFile, err
 	}
-	return tocFile, json.Unmarshal(tools.RemoveUtf8Bom(jsonFile), &tocFile)
+	if err := json.Unmarshal(tools.RemoveUtf8Bom(jsonFile), &tocFile); err != nil {
+		return tocFile, err
+	}
+
+	// backups before scopes were introduced are full backups
+	for i, backup := range tocFile.Backups {
+		if backup.Scope == "" {
+			tocFile.Backups[i].Scope = scopeFull
+		}
+		if backup.Targets == nil {
+			tocFile.Backups[i].Targets = make([]string, 0)
+		}
+	}
+	return tocFile, nil
 }
 func tocFileWrite(tocFile types.BackupTocFile) error {
 	jsonFile, err := json.MarshalIndent(tocFile, "", "\t")
@@ -254,6 +382,29 @@ func tocFileWrite(tocFile types.BackupTocFile) error {
 func getTocFilePath() string {
 	return filepath.Join(config.GetString("backupDir"), "backups_toc.json")
 }
+func getJobsActive() ([]types.BackupJob, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	jobs, err := JobGet_tx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobsActive := make([]types.BackupJob, 0)
+	for _, j := range jobs {
+		if j.Active {
+			jobsActive = append(jobsActive, j)
+		}
+	}
+	return jobsActive, nil
+}
 func getBackupJobDir(timestamp int64, jobName string) string {
 	return filepath.Join(config.GetString("backupDir"), fmt.Sprintf("%d_%s", timestamp, jobName))
 }
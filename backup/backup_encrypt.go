@@ -0,0 +1,333 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"r3/config"
+	"r3/tools"
+	"strings"
+)
+
+// backup files can be encrypted with a public RSA key, the private key is only required to restore backups
+// each file is encrypted with its own random AES-256 key, stored encrypted with the public key (RSA-OAEP, SHA-256)
+// file layout: magic, length of encrypted key (uint16), encrypted key, chunks
+// chunk layout: length of sealed data (uint32), sealed data (AES-GCM)
+// chunks use their index as nonce, the last chunk is authenticated as such to detect truncated files
+
+var (
+	encryptChunkSize = 1024 * 1024
+	encryptMagic     = []byte("R3BACKUP1")
+	encryptSuffix    = ".enc"
+)
+
+// returns public key for backup encryption, nil if encryption is disabled
+func getEncryptKey() (*rsa.PublicKey, error) {
+	keyPem := config.GetString("backupEncryptKey")
+	if strings.TrimSpace(keyPem) == "" {
+		return nil, nil
+	}
+
+	block, _ := pem.Decode([]byte(keyPem))
+	if block == nil {
+		return nil, errors.New("backup encryption key is not in PEM format")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse backup encryption key, %v", err)
+	}
+	keyRsa, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("backup encryption key must be an RSA public key")
+	}
+	return keyRsa, nil
+}
+
+func readDecryptKey(filePath string) (*rsa.PrivateKey, error) {
+	keyPem, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyPem)
+	if block == nil {
+		return nil, errors.New("backup decryption key is not in PEM format")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse backup decryption key, %v", err)
+	}
+	keyRsa, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("backup decryption key must be an RSA private key")
+	}
+	return keyRsa, nil
+}
+
+// writes encrypted copies of all files in directory to target directory, each source file is removed once encrypted
+func encryptDirTo(dir string, targetDir string, key *rsa.PublicKey) error {
+	return filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		pathRel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(targetDir, pathRel+encryptSuffix)
+
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		}
+		if err := encryptFile(filePath, target, key); err != nil {
+			return err
+		}
+		return os.Remove(filePath)
+	})
+}
+
+// writes decrypted copy of backup directory to target directory
+func decryptDir(dir string, targetDir string, key *rsa.PrivateKey) error {
+	return filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		pathRel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(targetDir, strings.TrimSuffix(pathRel, encryptSuffix))
+
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		}
+		if !strings.HasSuffix(filePath, encryptSuffix) {
+			return tools.FileCopy(filePath, target, false)
+		}
+		return decryptFile(filePath, target, key)
+	})
+}
+
+func encryptFile(filePath string, target string, key *rsa.PublicKey) error {
+	fileIn, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer fileIn.Close()
+
+	return writeEncryptedFile(target, key, func(w io.Writer) error {
+		_, err := io.Copy(w, fileIn)
+		return err
+	})
+}
+
+// creates encrypted file from data written by given function, data is encrypted while being written
+// incomplete target file is removed on failure
+func writeEncryptedFile(target string, key *rsa.PublicKey, fn func(w io.Writer) error) error {
+	fileOut, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer fileOut.Close()
+
+	if err := func() error {
+		writer, err := newEncryptWriter(fileOut, key)
+		if err != nil {
+			return err
+		}
+		if err := fn(writer); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		return fileOut.Sync()
+	}(); err != nil {
+		fileOut.Close()
+		os.Remove(target)
+		return err
+	}
+	return nil
+}
+
+func decryptFile(filePath string, target string, key *rsa.PrivateKey) error {
+	fileIn, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer fileIn.Close()
+
+	fileOut, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer fileOut.Close()
+
+	// header with encrypted file key
+	reader := bufio.NewReader(fileIn)
+	magic := make([]byte, len(encryptMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || !bytes.Equal(magic, encryptMagic) {
+		return fmt.Errorf("file '%s' is not an encrypted backup file", filepath.Base(filePath))
+	}
+	var fileKeyEncLen uint16
+	if err := binary.Read(reader, binary.BigEndian, &fileKeyEncLen); err != nil {
+		return err
+	}
+	fileKeyEnc := make([]byte, fileKeyEncLen)
+	if _, err := io.ReadFull(reader, fileKeyEnc); err != nil {
+		return err
+	}
+	fileKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, fileKeyEnc, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt file key of '%s', wrong private key?", filepath.Base(filePath))
+	}
+
+	aead, err := getEncryptAead(fileKey)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(fileOut)
+	for index := uint64(0); ; index++ {
+		var sealedLen uint32
+		if err := binary.Read(reader, binary.BigEndian, &sealedLen); err != nil {
+			return fmt.Errorf("file '%s' is incomplete", filepath.Base(filePath))
+		}
+		if int(sealedLen) > encryptChunkSize+aead.Overhead() {
+			return fmt.Errorf("file '%s' is corrupted", filepath.Base(filePath))
+		}
+		sealed := make([]byte, sealedLen)
+		if _, err := io.ReadFull(reader, sealed); err != nil {
+			return fmt.Errorf("file '%s' is incomplete", filepath.Base(filePath))
+		}
+		_, err = reader.Peek(1)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		last := err == io.EOF
+
+		chunk, err := aead.Open(nil, getEncryptNonce(aead, index), sealed, getEncryptChunkData(last))
+		if err != nil {
+			return fmt.Errorf("file '%s' is corrupted or incomplete", filepath.Base(filePath))
+		}
+		if _, err := writer.Write(chunk); err != nil {
+			return err
+		}
+		if last {
+			break
+		}
+	}
+	return writer.Flush()
+}
+
+// encrypting writer, data is sealed in chunks as it is written
+// the last chunk is only sealed on close, as it must be authenticated as such
+type encryptWriter struct {
+	aead   cipher.AEAD
+	chunk  []byte
+	index  uint64
+	writer *bufio.Writer
+}
+
+// writes header with encrypted file key to writer, returns writer for file content
+func newEncryptWriter(w io.Writer, key *rsa.PublicKey) (*encryptWriter, error) {
+	fileKey := make([]byte, 32)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+	fileKeyEnc, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, fileKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := getEncryptAead(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &encryptWriter{
+		aead:   aead,
+		chunk:  make([]byte, 0, encryptChunkSize),
+		writer: bufio.NewWriter(w),
+	}
+	e.writer.Write(encryptMagic)
+	binary.Write(e.writer, binary.BigEndian, uint16(len(fileKeyEnc)))
+	if _, err := e.writer.Write(fileKeyEnc); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) != 0 {
+		// full chunk is only sealed once more data follows
+		if len(e.chunk) == encryptChunkSize {
+			if err := e.seal(false); err != nil {
+				return 0, err
+			}
+		}
+		c := min(encryptChunkSize-len(e.chunk), len(p))
+		e.chunk = append(e.chunk, p[:c]...)
+		p = p[c:]
+	}
+	return n, nil
+}
+func (e *encryptWriter) Close() error {
+	if err := e.seal(true); err != nil {
+		return err
+	}
+	return e.writer.Flush()
+}
+func (e *encryptWriter) seal(last bool) error {
+	sealed := e.aead.Seal(nil, getEncryptNonce(e.aead, e.index), e.chunk, getEncryptChunkData(last))
+	binary.Write(e.writer, binary.BigEndian, uint32(len(sealed)))
+	if _, err := e.writer.Write(sealed); err != nil {
+		return err
+	}
+	e.chunk = e.chunk[:0]
+	e.index++
+	return nil
+}
+
+// helpers
+func getEncryptAead(fileKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+func getEncryptChunkData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+func getEncryptNonce(aead cipher.AEAD, index uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+	return nonce
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"r3/types"
+	"regexp"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// custom backup jobs are defined in addition to the fixed daily, weekly & monthly jobs
+// job names are part of backup directory names
+
+var (
+	jobNamesFixed = []string{"daily", "weekly", "monthly"}
+	jobNameRegex  = regexp.MustCompile(`^[a-z0-9\-]+$`)
+	scopeDatabase = "database" // database & config file
+	scopeFiles    = "files"    // files, certificates, transfer files & config file
+	scopeFull     = "full"     // everything
+)
+
+func JobGet_tx(ctx context.Context, tx pgx.Tx) ([]types.BackupJob, error) {
+	jobs := make([]types.BackupJob, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, name, scope, interval_hours, keep_count, active
+		FROM instance.backup_job
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return jobs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var j types.BackupJob
+		if err := rows.Scan(&j.Id, &j.Name, &j.Scope, &j.IntervalHours, &j.KeepCount, &j.Active); err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// replaces all custom backup jobs with the given ones
+// existing backups of removed jobs are kept until they are deleted manually
+func JobSet_tx(ctx context.Context, tx pgx.Tx, jobs []types.BackupJob) error {
+
+	// remove jobs first, their names can be reused
+	ids := make([]int32, 0)
+	for _, j := range jobs {
+		if j.Id != 0 {
+			ids = append(ids, j.Id)
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.backup_job
+		WHERE id <> ALL($1)
+	`, ids); err != nil {
+		return err
+	}
+
+	for _, j := range jobs {
+		if !jobNameRegex.MatchString(j.Name) || len(j.Name) > 32 {
+			return fmt.Errorf("invalid backup job name '%s', allowed are lower case letters, numbers and hyphens", j.Name)
+		}
+		if slices.Contains(jobNamesFixed, j.Name) {
+			return fmt.Errorf("backup job name '%s' is reserved", j.Name)
+		}
+		if j.IntervalHours < 1 || j.KeepCount < 1 {
+			return fmt.Errorf("backup job '%s' requires an interval and at least one kept version", j.Name)
+		}
+
+		if j.Id == 0 {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO instance.backup_job (name, scope, interval_hours, keep_count, active)
+				VALUES ($1,$2,$3,$4,$5)
+			`, j.Name, j.Scope, j.IntervalHours, j.KeepCount, j.Active); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.backup_job
+			SET name = $1, scope = $2, interval_hours = $3, keep_count = $4, active = $5
+			WHERE id = $6
+		`, j.Name, j.Scope, j.IntervalHours, j.KeepCount, j.Active, j.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
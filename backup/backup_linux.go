@@ -5,3 +5,6 @@ package backup
 func getPgDumpPath() string {
 	return "pg_dump"
 }
+func getPgRestorePath() string {
+	return "pg_restore"
+}
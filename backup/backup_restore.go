@@ -0,0 +1,484 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"r3/config"
+	"r3/db"
+	"r3/db/embedded"
+	"r3/log"
+	"r3/tools"
+	"r3/tools/compress"
+	"r3/types"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// backups are restored from the command line into a stopped instance
+// database, files and config file are replaced by the backup contents
+// replaced database, directories and config file are kept next to their originals
+
+// restores the backup in the given job directory, progress messages are sent to the given function
+// encrypted backups require the path to the private key file
+func Restore(jobDir string, keyPath string, progress func(string)) error {
+	access_mx.Lock()
+	defer access_mx.Unlock()
+
+	jobDir = filepath.Clean(jobDir)
+
+	backup, err := getRestoreBackup(jobDir)
+	if err != nil {
+		return err
+	}
+	if backup.AppBuild > config.GetAppVersion().Build {
+		return fmt.Errorf("backup was created by a newer version (build %d), this instance runs build %d",
+			backup.AppBuild, config.GetAppVersion().Build)
+	}
+
+	// encrypted backups are decrypted into a temporary directory first
+	if backup.Encrypted {
+		if keyPath == "" {
+			return errors.New("backup is encrypted, private key file must be given")
+		}
+		key, err := readDecryptKey(keyPath)
+		if err != nil {
+			return fmt.Errorf("failed to read private key, %v", err)
+		}
+		tempDir, err := os.MkdirTemp(config.File.Paths.Temp, "restore_")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+
+		progress(fmt.Sprintf("decrypting backup '%s'", filepath.Base(jobDir)))
+		if err := decryptDir(jobDir, tempDir, key); err != nil {
+			return fmt.Errorf("failed to decrypt backup, %v", err)
+		}
+		jobDir = tempDir
+	}
+
+	restoreDatabase := backup.Scope != scopeFiles
+	subPathsFiles := map[string]string{
+		subPathCerts:    config.File.Paths.Certificates,
+		subPathFiles:    config.File.Paths.Files,
+		subPathTransfer: config.File.Paths.Transfer,
+	}
+	if backup.Scope == scopeDatabase {
+		subPathsFiles = map[string]string{}
+	}
+
+	subPathsRequired := []string{subPathConfig}
+	if restoreDatabase {
+		subPathsRequired = append(subPathsRequired, subPathDb)
+	}
+	for subPath := range subPathsFiles {
+		subPathsRequired = append(subPathsRequired, subPath)
+	}
+	for _, subPath := range subPathsRequired {
+		exists, err := tools.Exists(filepath.Join(jobDir, subPath))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("backup is incomplete, '%s' is missing", subPath)
+		}
+	}
+
+	// embedded database can only be started if it is not used by a running instance
+	if config.File.Db.Embedded {
+		progress("starting embedded database")
+		embedded.SetPaths()
+		if err := embedded.Start(); err != nil {
+			return fmt.Errorf("failed to start embedded database, %v", err)
+		}
+		defer embedded.Stop()
+	}
+	if err := db.OpenWait(15, config.File.Db); err != nil {
+		return fmt.Errorf("failed to open database connection, %v", err)
+	}
+	defer db.Close()
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	if err := restoreCheckStopped(ctx); err != nil {
+		return err
+	}
+
+	// database
+	suffixReplaced := fmt.Sprintf("_replaced_%d", tools.GetTimeUnix())
+	if restoreDatabase {
+		progress(fmt.Sprintf("restoring database from backup '%d_%s' (build %d)",
+			backup.Timestamp, backup.JobName, backup.AppBuild))
+
+		if err := restoreDatabaseReplace(ctx, filepath.Join(jobDir, subPathDb), suffixReplaced); err != nil {
+			return err
+		}
+		progress(fmt.Sprintf("replaced database is kept as '%s%s'", config.File.Db.Name, suffixReplaced))
+	}
+
+	// files
+	for subPath, target := range subPathsFiles {
+		progress(fmt.Sprintf("restoring '%s' to '%s'", subPath, target))
+		if err := restorePath(filepath.Join(jobDir, subPath), target, suffixReplaced); err != nil {
+			return err
+		}
+	}
+
+	// config file, database connection and paths are kept as they are specific to the restoring system
+	progress("restoring configuration file")
+	return restoreConfigFile(filepath.Join(jobDir, subPathConfig), suffixReplaced)
+}
+
+// restores the latest backup into a scratch database and checks its consistency
+// the result is stored in the TOC file
+func Verify() error {
+	access_mx.Lock()
+	defer access_mx.Unlock()
+
+	if config.GetUint64("backupVerify") == 0 {
+		log.Info(log.ContextBackup, "backup verification is disabled, do nothing")
+		return nil
+	}
+	if config.GetString("backupDir") == "" {
+		log.Info(log.ContextBackup, "backup directory not defined, nothing to verify")
+		return nil
+	}
+
+	tocFile, err := TocFileReadCreate()
+	if err != nil {
+		return err
+	}
+
+	// encrypted backups cannot be read by the server, file-only backups contain no database
+	index := -1
+	for i, backup := range tocFile.Backups {
+		if backup.Encrypted || backup.Scope == scopeFiles {
+			continue
+		}
+		if index == -1 || backup.Timestamp > tocFile.Backups[index].Timestamp {
+			index = i
+		}
+	}
+	if index == -1 {
+		log.Info(log.ContextBackup, "no verifiable backups found, nothing to verify")
+		return nil
+	}
+	backup := &tocFile.Backups[index]
+	jobDir := getBackupJobDir(backup.Timestamp, backup.JobName)
+
+	if backup.VerifyDate.Valid {
+		log.Info(log.ContextBackup, fmt.Sprintf("latest backup '%s' is already verified", jobDir))
+		return nil
+	}
+
+	log.Info(log.ContextBackup, fmt.Sprintf("started verification of '%s'", jobDir))
+	errVerify := verifyJob(jobDir)
+
+	backup.VerifyDate = pgtype.Int8{Int64: tools.GetTimeUnix(), Valid: true}
+	backup.VerifyError = pgtype.Text{}
+	if errVerify != nil {
+		backup.VerifyError = pgtype.Text{String: errVerify.Error(), Valid: true}
+	}
+	if err := tocFileWrite(tocFile); err != nil {
+		return err
+	}
+
+	if errVerify != nil {
+		log.Error(log.ContextBackup, fmt.Sprintf("verification of '%s' failed", jobDir), errVerify)
+		return errVerify
+	}
+	log.Info(log.ContextBackup, fmt.Sprintf("successfully verified '%s'", jobDir))
+	return nil
+}
+
+// restore helpers
+// backup definition is read from backup meta file, older backups must be listed in the TOC file of their parent directory
+func getRestoreBackup(jobDir string) (types.BackupDef, error) {
+	var backup types.BackupDef
+
+	jsonFile, err := os.ReadFile(filepath.Join(jobDir, subPathMeta))
+	if errors.Is(err, os.ErrNotExist) {
+		backup, err = getTocBackup(jobDir)
+		if err != nil {
+			return backup, err
+		}
+	} else if err != nil {
+		return backup, err
+	} else if err := json.Unmarshal(tools.RemoveUtf8Bom(jsonFile), &backup); err != nil {
+		return backup, err
+	}
+
+	if backup.Scope == "" {
+		backup.Scope = scopeFull
+	}
+	return backup, nil
+}
+func getTocBackup(jobDir string) (types.BackupDef, error) {
+	var tocFile types.BackupTocFile
+
+	jsonFile, err := os.ReadFile(filepath.Join(filepath.Dir(jobDir), "backups_toc.json"))
+	if err != nil {
+		return types.BackupDef{}, fmt.Errorf("failed to read TOC file of backup directory, %v", err)
+	}
+	if err := json.Unmarshal(tools.RemoveUtf8Bom(jsonFile), &tocFile); err != nil {
+		return types.BackupDef{}, err
+	}
+
+	for _, backup := range tocFile.Backups {
+		if fmt.Sprintf("%d_%s", backup.Timestamp, backup.JobName) == filepath.Base(jobDir) {
+			return backup, nil
+		}
+	}
+	return types.BackupDef{}, fmt.Errorf("backup '%s' is not listed in TOC file", filepath.Base(jobDir))
+}
+
+// fails if any cluster node of the instance is active
+// nodes that did not check in recently are considered stopped
+func restoreCheckStopped(ctx context.Context) error {
+
+	// instance schemas do not exist in empty databases
+	var initialized bool
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT TO_REGCLASS('instance_cluster.node') IS NOT NULL
+	`).Scan(&initialized); err != nil {
+		return err
+	}
+	if !initialized {
+		return nil
+	}
+
+	var names []string
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(ARRAY_AGG(name), '{}')
+		FROM instance_cluster.node
+		WHERE running
+		AND date_check_in > $1 - (
+			SELECT value::BIGINT
+			FROM instance.config
+			WHERE name = 'clusterNodeMissingAfter'
+		)
+	`, tools.GetTimeUnix()).Scan(&names); err != nil {
+		return err
+	}
+	if len(names) != 0 {
+		return fmt.Errorf("instance must be stopped before restoring, active cluster nodes: %s",
+			strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// restores database dump into a new database, which replaces the current database only if the restore succeeded
+// the current database is kept, renamed with the given suffix
+func restoreDatabaseReplace(ctx context.Context, dumpPath string, suffixReplaced string) error {
+	name := pgx.Identifier{config.File.Db.Name}.Sanitize()
+	nameReplaced := pgx.Identifier{config.File.Db.Name + suffixReplaced}.Sanitize()
+	nameRestore := pgx.Identifier{config.File.Db.Name + "_restore"}.Sanitize()
+
+	// database might remain from an interrupted restore
+	if _, err := db.Pool.Exec(ctx, fmt.Sprintf(`DROP DATABASE IF EXISTS %s`, nameRestore)); err != nil {
+		return err
+	}
+	if _, err := db.Pool.Exec(ctx, fmt.Sprintf(`CREATE DATABASE %s`, nameRestore)); err != nil {
+		return fmt.Errorf("failed to create database for restore, %v", err)
+	}
+	if err := restoreDb(dumpPath, config.File.Db.Name+"_restore"); err != nil {
+		if _, errDrop := db.Pool.Exec(ctx, fmt.Sprintf(`DROP DATABASE IF EXISTS %s`, nameRestore)); errDrop != nil {
+			log.Warning(log.ContextBackup, "failed to remove database of failed restore", errDrop)
+		}
+		return err
+	}
+
+	// databases can only be renamed without open connections, maintenance database is used instead
+	db.Close()
+
+	dbConfig := config.File.Db
+	dbConfig.Name = "postgres"
+	con, err := db.OpenConn(ctx, dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to maintenance database, %v", err)
+	}
+	defer con.Close(ctx)
+
+	tx, err := con.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`ALTER DATABASE %s RENAME TO %s`, name, nameReplaced)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`ALTER DATABASE %s RENAME TO %s`, nameRestore, name)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// replaces target directory with the contents of the given zip file
+// an existing target directory is renamed with the given suffix
+func restorePath(zipPath string, targetPath string, suffixReplaced string) error {
+	if targetPath == "" {
+		return fmt.Errorf("target path for '%s' is not defined in config file", filepath.Base(zipPath))
+	}
+
+	exists, err := tools.Exists(targetPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := os.Rename(targetPath, targetPath+suffixReplaced); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(targetPath, 0700); err != nil {
+		return err
+	}
+	return compress.Extract(zipPath, targetPath)
+}
+
+func restoreConfigFile(filePath string, suffixReplaced string) error {
+	jsonFile, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	var file types.FileType
+	if err := json.Unmarshal(tools.RemoveUtf8Bom(jsonFile), &file); err != nil {
+		return err
+	}
+	file.Db = config.File.Db
+	file.Paths = config.File.Paths
+
+	if err := tools.FileCopy(config.GetConfigFilepath(), config.GetConfigFilepath()+suffixReplaced, false); err != nil {
+		return err
+	}
+	config.File = file
+	return config.WriteFile()
+}
+
+// verification helpers
+func verifyJob(jobDir string) error {
+
+	// compressed files must be readable
+	for _, subPath := range []string{subPathCerts, subPathFiles, subPathTransfer} {
+		zipReader, err := zip.OpenReader(filepath.Join(jobDir, subPath))
+		if err != nil {
+			return fmt.Errorf("failed to read '%s', %v", subPath, err)
+		}
+		zipReader.Close()
+	}
+
+	// restore database dump into scratch database
+	dbConfig := config.File.Db
+	dbConfig.Name = fmt.Sprintf("%s_verify", config.File.Db.Name)
+	dbName := pgx.Identifier{dbConfig.Name}.Sanitize()
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	// scratch database might remain from an interrupted verification
+	if _, err := db.Pool.Exec(ctx, fmt.Sprintf(`DROP DATABASE IF EXISTS %s`, dbName)); err != nil {
+		return err
+	}
+	if _, err := db.Pool.Exec(ctx, fmt.Sprintf(`CREATE DATABASE %s`, dbName)); err != nil {
+		return fmt.Errorf("failed to create scratch database, %v", err)
+	}
+	defer func() {
+		ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+		defer ctxCanc()
+
+		if _, err := db.Pool.Exec(ctx, fmt.Sprintf(`DROP DATABASE IF EXISTS %s`, dbName)); err != nil {
+			log.Warning(log.ContextBackup, "failed to remove scratch database", err)
+		}
+	}()
+
+	if err := restoreDb(filepath.Join(jobDir, subPathDb), dbConfig.Name); err != nil {
+		return err
+	}
+	return verifyDb(dbConfig)
+}
+
+// checks consistency between schema definitions and database objects of restored database
+func verifyDb(dbConfig types.FileTypeDb) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	con, err := db.OpenConn(ctx, dbConfig)
+	if err != nil {
+		return err
+	}
+	defer con.Close(ctx)
+
+	var dbVersionCut string
+	if err := con.QueryRow(ctx, `
+		SELECT value
+		FROM instance.config
+		WHERE name = 'dbVersionCut'
+	`).Scan(&dbVersionCut); err != nil {
+		return fmt.Errorf("failed to read database version, %v", err)
+	}
+
+	for _, check := range []struct {
+		message string
+		query   string
+	}{
+		{"schemas of modules are missing", `
+			SELECT m.name
+			FROM app.module AS m
+			WHERE NOT EXISTS (
+				SELECT nspname
+				FROM pg_namespace
+				WHERE nspname = m.name
+			)
+		`},
+		{"tables of relations are missing", `
+			SELECT m.name || '.' || r.name
+			FROM app.relation AS r
+			JOIN app.module   AS m ON m.id = r.module_id
+			WHERE TO_REGCLASS(QUOTE_IDENT(m.name) || '.' || QUOTE_IDENT(r.name)) IS NULL
+		`},
+		// file attributes are not stored as columns
+		{"columns of attributes are missing", `
+			SELECT m.name || '.' || r.name || '.' || a.name
+			FROM app.attribute AS a
+			JOIN app.relation  AS r ON r.id = a.relation_id
+			JOIN app.module    AS m ON m.id = r.module_id
+			WHERE a.content <> 'files'
+			AND NOT EXISTS (
+				SELECT attname
+				FROM pg_attribute
+				WHERE attrelid = TO_REGCLASS(QUOTE_IDENT(m.name) || '.' || QUOTE_IDENT(r.name))
+				AND   attname  = a.name
+				AND   NOT attisdropped
+			)
+		`},
+	} {
+		names := make([]string, 0)
+		rows, err := con.Query(ctx, check.query)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return err
+			}
+			names = append(names, name)
+		}
+		rows.Close()
+
+		if len(names) != 0 {
+			return fmt.Errorf("%s: %s", check.message, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
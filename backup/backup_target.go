@@ -0,0 +1,276 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"r3/db"
+	"r3/log"
+	"r3/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// backups are copied to remote targets after they were created
+// uploads are verified by comparing file sizes, transfers are protected by the protocols themselves
+//  (SSH message authentication for SFTP, content hashes for S3)
+// each target keeps its own number of backup versions per job
+
+// stored secrets (SFTP password & private key, S3 secret key) are never sent to admin clients
+// clients receive a mask instead, which is sent back if the secret is kept
+const targetSecretMask = "**********"
+
+// connection to a backup target, paths are relative to the target base path and use forward slashes
+type targetConn interface {
+	close()
+	listDirs() ([]string, error)
+	removeDir(name string) error
+	size(remotePath string) (int64, error)
+	upload(filePath string, remotePath string) error
+}
+
+func TargetDel_tx(ctx context.Context, tx pgx.Tx, id int32) error {
+	_, err := tx.Exec(ctx, `DELETE FROM instance.backup_target WHERE id = $1`, id)
+	return err
+}
+
+// returns targets for admin clients, stored secrets are masked
+func TargetGet_tx(ctx context.Context, tx pgx.Tx) ([]types.BackupTarget, error) {
+	targets, err := targetGet_tx(ctx, tx)
+	if err != nil {
+		return targets, err
+	}
+	for i, t := range targets {
+		if t.Password != "" {
+			targets[i].Password = targetSecretMask
+		}
+		if t.PrivateKey != "" {
+			targets[i].PrivateKey = targetSecretMask
+		}
+	}
+	return targets, nil
+}
+
+// replaces masked secrets with the stored secrets of the target
+func TargetUnmaskSecrets_tx(ctx context.Context, tx pgx.Tx, t *types.BackupTarget) error {
+	if t.Password != targetSecretMask && t.PrivateKey != targetSecretMask {
+		return nil
+	}
+
+	var password, privateKey string
+	if err := tx.QueryRow(ctx, `
+		SELECT password, private_key
+		FROM instance.backup_target
+		WHERE id = $1
+	`, t.Id).Scan(&password, &privateKey); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("masked secrets can only be used for existing backup targets")
+		}
+		return err
+	}
+	if t.Password == targetSecretMask {
+		t.Password = password
+	}
+	if t.PrivateKey == targetSecretMask {
+		t.PrivateKey = privateKey
+	}
+	return nil
+}
+
+func targetGet_tx(ctx context.Context, tx pgx.Tx) ([]types.BackupTarget, error) {
+	targets := make([]types.BackupTarget, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, name, content, active, host_name, host_port, host_key, path,
+			bucket, region, username, password, private_key, keep_count
+		FROM instance.backup_target
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return targets, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t types.BackupTarget
+		if err := rows.Scan(&t.Id, &t.Name, &t.Content, &t.Active, &t.HostName, &t.HostPort,
+			&t.HostKey, &t.Path, &t.Bucket, &t.Region, &t.Username, &t.Password,
+			&t.PrivateKey, &t.KeepCount); err != nil {
+
+			return targets, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func TargetSet_tx(ctx context.Context, tx pgx.Tx, t types.BackupTarget) error {
+	if t.KeepCount < 0 {
+		return errors.New("number of kept backups must not be negative")
+	}
+	if err := TargetUnmaskSecrets_tx(ctx, tx, &t); err != nil {
+		return err
+	}
+
+	if t.Id == 0 {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO instance.backup_target (name, content, active, host_name,
+				host_port, host_key, path, bucket, region, username, password,
+				private_key, keep_count)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+		`, t.Name, t.Content, t.Active, t.HostName, t.HostPort, t.HostKey, t.Path,
+			t.Bucket, t.Region, t.Username, t.Password, t.PrivateKey, t.KeepCount)
+
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		UPDATE instance.backup_target
+		SET name = $1, content = $2, active = $3, host_name = $4, host_port = $5,
+			host_key = $6, path = $7, bucket = $8, region = $9, username = $10,
+			password = $11, private_key = $12, keep_count = $13
+		WHERE id = $14
+	`, t.Name, t.Content, t.Active, t.HostName, t.HostPort, t.HostKey, t.Path,
+		t.Bucket, t.Region, t.Username, t.Password, t.PrivateKey, t.KeepCount, t.Id)
+
+	return err
+}
+
+// connects to target and reads its contents
+func TargetTest(t types.BackupTarget) error {
+	conn, err := openTargetConn(t)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	_, err = conn.listDirs()
+	return err
+}
+
+// uploads backup directory to all active targets, returns names of targets with verified uploads
+func uploadToTargets(jobDir string, jobName string) ([]string, error) {
+	names := make([]string, 0)
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return names, err
+	}
+	defer tx.Rollback(ctx)
+
+	targets, err := targetGet_tx(ctx, tx)
+	if err != nil {
+		return names, err
+	}
+	tx.Rollback(ctx) // do not keep transaction open during uploads
+
+	errs := make([]error, 0)
+	for _, t := range targets {
+		if !t.Active {
+			continue
+		}
+		log.Info(log.ContextBackup, fmt.Sprintf("is uploading '%s' to target '%s'", jobDir, t.Name))
+
+		if err := uploadToTarget(t, jobDir, jobName); err != nil {
+			log.Error(log.ContextBackup, fmt.Sprintf("could not upload to target '%s'", t.Name), err)
+			errs = append(errs, fmt.Errorf("upload to target '%s' failed, %v", t.Name, err))
+			continue
+		}
+		names = append(names, t.Name)
+	}
+	return names, errors.Join(errs...)
+}
+
+func uploadToTarget(t types.BackupTarget, jobDir string, jobName string) error {
+	conn, err := openTargetConn(t)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	if err := filepath.Walk(jobDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		pathRel, err := filepath.Rel(jobDir, filePath)
+		if err != nil {
+			return err
+		}
+		remotePath := path.Join(filepath.Base(jobDir), filepath.ToSlash(pathRel))
+
+		if err := conn.upload(filePath, remotePath); err != nil {
+			return err
+		}
+		size, err := conn.size(remotePath)
+		if err != nil {
+			return err
+		}
+		if size != info.Size() {
+			return fmt.Errorf("upload of '%s' could not be verified, target has %d bytes instead of %d",
+				remotePath, size, info.Size())
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if t.KeepCount == 0 {
+		return nil
+	}
+	return targetCleanup(conn, jobName, t.KeepCount)
+}
+
+// deletes the oldest backups of job on target above the keep count
+func targetCleanup(conn targetConn, jobName string, keepCount int) error {
+	names, err := conn.listDirs()
+	if err != nil {
+		return err
+	}
+
+	timestamps := make([]int64, 0)
+	for _, name := range names {
+		timestampStr, job, found := strings.Cut(name, "_")
+		if !found || job != jobName {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		timestamps = append(timestamps, timestamp)
+	}
+	slices.Sort(timestamps)
+
+	for i := 0; i < len(timestamps)-keepCount; i++ {
+		name := fmt.Sprintf("%d_%s", timestamps[i], jobName)
+		log.Info(log.ContextBackup, fmt.Sprintf("is deleting '%s' from target", name))
+
+		if err := conn.removeDir(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func openTargetConn(t types.BackupTarget) (targetConn, error) {
+	switch t.Content {
+	case "local":
+		return openTargetLocal(t)
+	case "s3":
+		return openTargetS3(t)
+	case "sftp":
+		return openTargetSftp(t)
+	}
+	return nil, fmt.Errorf("unknown backup target type '%s'", t.Content)
+}
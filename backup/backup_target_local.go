@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"r3/types"
+)
+
+// local targets are directories of the server, like mounted network shares
+
+type targetLocal struct {
+	path string
+}
+
+func openTargetLocal(t types.BackupTarget) (targetConn, error) {
+	if t.Path == "" {
+		return nil, errors.New("target directory is not defined")
+	}
+	info, err := os.Stat(t.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("target path '%s' is not a directory", t.Path)
+	}
+	return &targetLocal{path: t.Path}, nil
+}
+
+func (t *targetLocal) close() {}
+
+func (t *targetLocal) listDirs() ([]string, error) {
+	names := make([]string, 0)
+
+	entries, err := os.ReadDir(t.path)
+	if err != nil {
+		return names, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (t *targetLocal) removeDir(name string) error {
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("invalid directory name '%s'", name)
+	}
+	return os.RemoveAll(filepath.Join(t.path, name))
+}
+
+func (t *targetLocal) size(remotePath string) (int64, error) {
+	info, err := os.Stat(filepath.Join(t.path, filepath.FromSlash(remotePath)))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (t *targetLocal) upload(filePath string, remotePath string) error {
+	target := filepath.Join(t.path, filepath.FromSlash(remotePath))
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return err
+	}
+
+	fileIn, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer fileIn.Close()
+
+	fileOut, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer fileOut.Close()
+
+	if _, err := io.Copy(fileOut, fileIn); err != nil {
+		return err
+	}
+	return fileOut.Sync()
+}
@@ -0,0 +1,381 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"r3/config"
+	"r3/types"
+	"slices"
+	"strings"
+	"time"
+)
+
+// S3 targets use path-style requests, signed with AWS signature version 4
+// works with AWS S3 as well as S3-compatible storage services
+// uploads are checked by the storage service via content hashes, large files are uploaded in parts
+
+var (
+	s3PartSize       int64 = 64 * 1024 * 1024 // files above this size are uploaded in parts
+	s3TimeoutRequest int64 = 1800             // timeout for single request in seconds, includes uploads of file parts
+)
+
+type targetS3 struct {
+	accessKey string
+	bucket    string
+	client    http.Client
+	endpoint  *url.URL
+	prefix    string // key prefix of backup directories, empty or ending with a slash
+	region    string
+	secretKey string
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func openTargetS3(t types.BackupTarget) (targetConn, error) {
+	if t.HostName == "" || t.Bucket == "" || t.Region == "" || t.Username == "" || t.Password == "" {
+		return nil, errors.New("S3 endpoint, bucket, region, access key and secret key must be defined")
+	}
+	endpoint, err := url.Parse(t.HostName)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("S3 endpoint '%s' must be an HTTP(S) URL", t.HostName)
+	}
+
+	client, err := config.GetHttpClient(false, s3TimeoutRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &targetS3{
+		accessKey: t.Username,
+		bucket:    t.Bucket,
+		client:    client,
+		endpoint:  endpoint,
+		region:    t.Region,
+		secretKey: t.Password,
+	}
+	if prefix := strings.Trim(t.Path, "/"); prefix != "" {
+		c.prefix = prefix + "/"
+	}
+	return c, nil
+}
+
+func (c *targetS3) close() {}
+
+func (c *targetS3) listDirs() ([]string, error) {
+	names := make([]string, 0)
+
+	prefixes, err := c.list(c.prefix, true)
+	if err != nil {
+		return names, err
+	}
+	for _, prefix := range prefixes {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(prefix, c.prefix), "/"))
+	}
+	return names, nil
+}
+
+func (c *targetS3) removeDir(name string) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid directory name '%s'", name)
+	}
+	keys, err := c.list(c.prefix+name+"/", false)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		res, err := c.request(http.MethodDelete, key, nil, nil, 0, "", nil)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+	}
+	return nil
+}
+
+func (c *targetS3) size(remotePath string) (int64, error) {
+	res, err := c.request(http.MethodHead, c.prefix+remotePath, nil, nil, 0, "", nil)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	return res.ContentLength, nil
+}
+
+func (c *targetS3) upload(filePath string, remotePath string) error {
+	key := c.prefix + remotePath
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() <= s3PartSize {
+		res, err := c.requestWithContent(http.MethodPut, key, nil, io.NewSectionReader(file, 0, info.Size()))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		return nil
+	}
+
+	// multipart upload
+	res, err := c.request(http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0, "", nil)
+	if err != nil {
+		return err
+	}
+	var initiate struct {
+		UploadId string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(res.Body).Decode(&initiate)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	type part struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	var complete struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}
+
+	if err := func() error {
+		for offset, number := int64(0), 1; offset < info.Size(); offset, number = offset+s3PartSize, number+1 {
+			res, err := c.requestWithContent(http.MethodPut, key, url.Values{
+				"partNumber": {fmt.Sprintf("%d", number)},
+				"uploadId":   {initiate.UploadId},
+			}, io.NewSectionReader(file, offset, min(s3PartSize, info.Size()-offset)))
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			complete.Parts = append(complete.Parts, part{PartNumber: number, ETag: res.Header.Get("ETag")})
+		}
+
+		body, err := xml.Marshal(complete)
+		if err != nil {
+			return err
+		}
+		res, err := c.requestWithContent(http.MethodPost, key, url.Values{"uploadId": {initiate.UploadId}},
+			io.NewSectionReader(bytes.NewReader(body), 0, int64(len(body))))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		// completion can fail after the response status was sent
+		resBody, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(resBody, []byte("<Error>")) {
+			return getS3Error(res.StatusCode, resBody)
+		}
+		return nil
+	}(); err != nil {
+		// remove uploaded parts
+		if res, errAbort := c.request(http.MethodDelete, key, url.Values{"uploadId": {initiate.UploadId}},
+			nil, 0, "", nil); errAbort == nil {
+
+			res.Body.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+// returns keys with given prefix, or common prefixes of the next level if delimited
+func (c *targetS3) list(prefix string, delimited bool) ([]string, error) {
+	out := make([]string, 0)
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if delimited {
+		query.Set("delimiter", "/")
+	}
+
+	for {
+		res, err := c.request(http.MethodGet, "", query, nil, 0, "", nil)
+		if err != nil {
+			return out, err
+		}
+		var result struct {
+			CommonPrefixes []struct {
+				Prefix string `xml:"Prefix"`
+			} `xml:"CommonPrefixes"`
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return out, err
+		}
+
+		for _, p := range result.CommonPrefixes {
+			out = append(out, p.Prefix)
+		}
+		for _, content := range result.Contents {
+			out = append(out, content.Key)
+		}
+		if !result.IsTruncated {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+	return out, nil
+}
+
+// sends request with content, hashes of the content are sent for the storage service to check
+func (c *targetS3) requestWithContent(method string, key string, query url.Values, content *io.SectionReader) (*http.Response, error) {
+	hashSha256 := sha256.New()
+	hashMd5 := md5.New()
+	if _, err := io.Copy(io.MultiWriter(hashSha256, hashMd5), content); err != nil {
+		return nil, err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return c.request(method, key, query, content, content.Size(), hex.EncodeToString(hashSha256.Sum(nil)),
+		map[string]string{"content-md5": base64.StdEncoding.EncodeToString(hashMd5.Sum(nil))})
+}
+
+func (c *targetS3) request(method string, key string, query url.Values, body io.Reader, bodyLength int64,
+	bodyHash string, headers map[string]string) (*http.Response, error) {
+
+	if bodyHash == "" {
+		bodyHash = hex.EncodeToString(sha256.New().Sum(nil))
+	}
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	now := time.Now().UTC()
+	dateShort := now.Format("20060102")
+	headers["host"] = c.endpoint.Host
+	headers["x-amz-content-sha256"] = bodyHash
+	headers["x-amz-date"] = now.Format("20060102T150405Z")
+
+	// canonical request
+	uriPath := strings.TrimSuffix(c.endpoint.Path, "/") + "/" + s3Encode(c.bucket, false)
+	if key != "" {
+		uriPath += "/" + s3Encode(key, true)
+	}
+	queryParts := make([]string, 0)
+	for k, values := range query {
+		for _, v := range values {
+			queryParts = append(queryParts, s3Encode(k, false)+"="+s3Encode(v, false))
+		}
+	}
+	slices.Sort(queryParts)
+	queryString := strings.Join(queryParts, "&")
+
+	headerNames := make([]string, 0)
+	for k := range headers {
+		headerNames = append(headerNames, k)
+	}
+	slices.Sort(headerNames)
+	headerLines := make([]string, 0)
+	for _, k := range headerNames {
+		headerLines = append(headerLines, fmt.Sprintf("%s:%s\n", k, strings.TrimSpace(headers[k])))
+	}
+	headersSigned := strings.Join(headerNames, ";")
+
+	requestCanonical := strings.Join([]string{method, uriPath, queryString,
+		strings.Join(headerLines, ""), headersSigned, bodyHash}, "\n")
+
+	// signature
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", dateShort, c.region)
+	requestHash := sha256.Sum256([]byte(requestCanonical))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", headers["x-amz-date"], scope,
+		hex.EncodeToString(requestHash[:])}, "\n")
+
+	signingKey := []byte("AWS4" + c.secretKey)
+	for _, part := range []string{dateShort, c.region, "s3", "aws4_request"} {
+		signingKey = s3Hmac(signingKey, part)
+	}
+	signature := hex.EncodeToString(s3Hmac(signingKey, stringToSign))
+
+	// send request
+	requestUrl := fmt.Sprintf("%s://%s%s", c.endpoint.Scheme, c.endpoint.Host, uriPath)
+	if queryString != "" {
+		requestUrl = fmt.Sprintf("%s?%s", requestUrl, queryString)
+	}
+	req, err := http.NewRequest(method, requestUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = bodyLength
+	for k, v := range headers {
+		if k != "host" {
+			req.Header.Set(k, v)
+		}
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, headersSigned, signature))
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		return nil, getS3Error(res.StatusCode, resBody)
+	}
+	return res, nil
+}
+
+// helpers
+func getS3Error(statusCode int, body []byte) error {
+	var e s3Error
+	if err := xml.Unmarshal(body, &e); err != nil || e.Code == "" {
+		return fmt.Errorf("S3 request failed with status %d", statusCode)
+	}
+	return fmt.Errorf("S3 request failed with status %d, %s: %s", statusCode, e.Code, e.Message)
+}
+
+// URI encoding as required by AWS signature version 4
+func s3Encode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && keepSlash) {
+
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func s3Hmac(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,533 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"r3/types"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTP targets use a minimal SFTP client (protocol version 3, draft-ietf-secsh-filexfer-02)
+// it covers the operations required to store backups: writing files, reading directories & removing entries
+// the host key must be known, connections to hosts presenting other keys are refused
+
+const (
+	sftpPacketInit    = 1
+	sftpPacketVersion = 2
+	sftpPacketOpen    = 3
+	sftpPacketClose   = 4
+	sftpPacketWrite   = 6
+	sftpPacketOpendir = 11
+	sftpPacketReaddir = 12
+	sftpPacketRemove  = 13
+	sftpPacketMkdir   = 14
+	sftpPacketRmdir   = 15
+	sftpPacketStat    = 17
+	sftpPacketStatus  = 101
+	sftpPacketHandle  = 102
+	sftpPacketName    = 104
+	sftpPacketAttrs   = 105
+
+	sftpAttrSize      = 0x00000001
+	sftpAttrUidGid    = 0x00000002
+	sftpAttrPerms     = 0x00000004
+	sftpAttrAcModTime = 0x00000008
+	sftpAttrExtended  = 0x80000000
+
+	sftpOpenWrite = 0x00000002
+	sftpOpenCreat = 0x00000008
+	sftpOpenTrunc = 0x00000010
+
+	sftpStatusOk         = 0
+	sftpStatusEof        = 1
+	sftpStatusNoSuchFile = 2
+
+	sftpPermsDir      = 0040000
+	sftpPermsTypeMask = 0170000
+
+	sftpWriteChunkSize = 32768 // data per write request
+	sftpWritesPending  = 16    // write requests sent before waiting for responses
+)
+
+type targetSftp struct {
+	client  *ssh.Client
+	session *ssh.Session
+	reader  io.Reader
+	writer  io.WriteCloser
+	path    string
+	reqId   uint32
+}
+
+type sftpAttrs struct {
+	isDir bool
+	size  int64
+}
+
+type sftpEntry struct {
+	name  string
+	isDir bool
+}
+
+func openTargetSftp(t types.BackupTarget) (targetConn, error) {
+	if t.HostName == "" || t.Username == "" {
+		return nil, errors.New("SFTP host name and user must be defined")
+	}
+
+	var hostKey ssh.PublicKey
+	if t.HostKey != "" {
+		var err error
+		hostKey, _, _, _, err = ssh.ParseAuthorizedKey([]byte(t.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SFTP host key, %v", err)
+		}
+	}
+
+	auths := make([]ssh.AuthMethod, 0)
+	if t.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(t.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SFTP private key, %v", err)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if t.Password != "" {
+		auths = append(auths, ssh.Password(t.Password))
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(t.HostName, strconv.Itoa(t.HostPort)), &ssh.ClientConfig{
+		Auth:    auths,
+		Timeout: 30 * time.Second,
+		User:    t.Username,
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			// presented key is returned, so that it can be checked & stored
+			keyPresented := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+			if hostKey == nil {
+				return fmt.Errorf("SFTP host key is not defined, host presented key '%s'", keyPresented)
+			}
+			if !bytes.Equal(key.Marshal(), hostKey.Marshal()) {
+				return fmt.Errorf("SFTP host key does not match, host presented key '%s'", keyPresented)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &targetSftp{client: client, path: t.Path}
+	if c.path == "" {
+		c.path = "."
+	}
+
+	c.session, err = client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if c.writer, err = c.session.StdinPipe(); err != nil {
+		c.close()
+		return nil, err
+	}
+	if c.reader, err = c.session.StdoutPipe(); err != nil {
+		c.close()
+		return nil, err
+	}
+	if err := c.session.RequestSubsystem("sftp"); err != nil {
+		c.close()
+		return nil, err
+	}
+
+	// protocol version negotiation
+	if err := c.sendPacket(sftpPacketInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		c.close()
+		return nil, err
+	}
+	packetType, _, err := c.readPacket()
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	if packetType != sftpPacketVersion {
+		c.close()
+		return nil, fmt.Errorf("unexpected SFTP response type %d", packetType)
+	}
+	return c, nil
+}
+
+func (c *targetSftp) close() {
+	if c.session != nil {
+		c.session.Close()
+	}
+	c.client.Close()
+}
+
+func (c *targetSftp) listDirs() ([]string, error) {
+	names := make([]string, 0)
+
+	entries, err := c.readDir(c.path)
+	if err != nil {
+		return names, err
+	}
+	for _, entry := range entries {
+		if entry.isDir {
+			names = append(names, entry.name)
+		}
+	}
+	return names, nil
+}
+
+func (c *targetSftp) removeDir(name string) error {
+	if name == "" || strings.Contains(name, "/") || name == ".." {
+		return fmt.Errorf("invalid directory name '%s'", name)
+	}
+	return c.removeAll(path.Join(c.path, name))
+}
+
+func (c *targetSftp) size(remotePath string) (int64, error) {
+	attrs, err := c.stat(path.Join(c.path, remotePath))
+	return attrs.size, err
+}
+
+func (c *targetSftp) upload(filePath string, remotePath string) error {
+	remotePath = path.Join(c.path, remotePath)
+	if err := c.mkdirAll(path.Dir(remotePath)); err != nil {
+		return err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	handle, err := c.open(remotePath, sftpOpenWrite|sftpOpenCreat|sftpOpenTrunc)
+	if err != nil {
+		return err
+	}
+
+	// write requests are pipelined, responses are only checked for errors
+	chunk := make([]byte, sftpWriteChunkSize)
+	var offset uint64
+	var pending int
+	for {
+		n, errRead := io.ReadFull(file, chunk)
+		if n > 0 {
+			payload := sftpAppendString(c.nextId(), handle)
+			payload = binary.BigEndian.AppendUint64(payload, offset)
+			payload = sftpAppendString(payload, string(chunk[:n]))
+
+			if err := c.sendPacket(sftpPacketWrite, payload); err != nil {
+				return err
+			}
+			offset += uint64(n)
+			pending++
+		}
+		for pending > 0 && (pending >= sftpWritesPending || errRead != nil) {
+			if err := c.readStatus(); err != nil {
+				return err
+			}
+			pending--
+		}
+		if errRead == io.EOF || errRead == io.ErrUnexpectedEOF {
+			break
+		}
+		if errRead != nil {
+			return errRead
+		}
+	}
+	return c.closeHandle(handle)
+}
+
+// operations
+func (c *targetSftp) closeHandle(handle string) error {
+	if err := c.sendPacket(sftpPacketClose, sftpAppendString(c.nextId(), handle)); err != nil {
+		return err
+	}
+	return c.readStatus()
+}
+
+func (c *targetSftp) mkdirAll(dirPath string) error {
+	if dirPath == "." || dirPath == "/" {
+		return nil
+	}
+	attrs, err := c.stat(dirPath)
+	if err == nil {
+		if !attrs.isDir {
+			return fmt.Errorf("SFTP path '%s' is not a directory", dirPath)
+		}
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := c.mkdirAll(path.Dir(dirPath)); err != nil {
+		return err
+	}
+
+	payload := sftpAppendString(c.nextId(), dirPath)
+	payload = binary.BigEndian.AppendUint32(payload, 0) // no attributes
+	if err := c.sendPacket(sftpPacketMkdir, payload); err != nil {
+		return err
+	}
+	return c.readStatus()
+}
+
+func (c *targetSftp) open(filePath string, flags uint32) (string, error) {
+	payload := sftpAppendString(c.nextId(), filePath)
+	payload = binary.BigEndian.AppendUint32(payload, flags)
+	payload = binary.BigEndian.AppendUint32(payload, 0) // no attributes
+	if err := c.sendPacket(sftpPacketOpen, payload); err != nil {
+		return "", err
+	}
+	return c.readHandle()
+}
+
+func (c *targetSftp) readDir(dirPath string) ([]sftpEntry, error) {
+	entries := make([]sftpEntry, 0)
+
+	if err := c.sendPacket(sftpPacketOpendir, sftpAppendString(c.nextId(), dirPath)); err != nil {
+		return entries, err
+	}
+	handle, err := c.readHandle()
+	if err != nil {
+		return entries, err
+	}
+
+	for {
+		if err := c.sendPacket(sftpPacketReaddir, sftpAppendString(c.nextId(), handle)); err != nil {
+			return entries, err
+		}
+		packetType, data, err := c.readPacket()
+		if err != nil {
+			return entries, err
+		}
+		if packetType == sftpPacketStatus {
+			if err := sftpGetStatusErr(data); err != io.EOF {
+				return entries, err
+			}
+			break
+		}
+		if packetType != sftpPacketName {
+			return entries, fmt.Errorf("unexpected SFTP response type %d", packetType)
+		}
+
+		d := sftpDecoder{data: data}
+		d.uint32() // request ID
+		count := d.uint32()
+		for i := uint32(0); i < count && d.err == nil; i++ {
+			name := d.string()
+			d.string() // long name
+			attrs := d.attrs()
+			if name != "." && name != ".." {
+				entries = append(entries, sftpEntry{name: name, isDir: attrs.isDir})
+			}
+		}
+		if d.err != nil {
+			return entries, d.err
+		}
+	}
+	return entries, c.closeHandle(handle)
+}
+
+func (c *targetSftp) removeAll(dirPath string) error {
+	entries, err := c.readDir(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entryPath := path.Join(dirPath, entry.name)
+		if entry.isDir {
+			if err := c.removeAll(entryPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.sendPacket(sftpPacketRemove, sftpAppendString(c.nextId(), entryPath)); err != nil {
+			return err
+		}
+		if err := c.readStatus(); err != nil {
+			return err
+		}
+	}
+	if err := c.sendPacket(sftpPacketRmdir, sftpAppendString(c.nextId(), dirPath)); err != nil {
+		return err
+	}
+	return c.readStatus()
+}
+
+func (c *targetSftp) stat(filePath string) (sftpAttrs, error) {
+	if err := c.sendPacket(sftpPacketStat, sftpAppendString(c.nextId(), filePath)); err != nil {
+		return sftpAttrs{}, err
+	}
+	packetType, data, err := c.readPacket()
+	if err != nil {
+		return sftpAttrs{}, err
+	}
+	if packetType == sftpPacketStatus {
+		return sftpAttrs{}, sftpGetStatusErr(data)
+	}
+	if packetType != sftpPacketAttrs {
+		return sftpAttrs{}, fmt.Errorf("unexpected SFTP response type %d", packetType)
+	}
+
+	d := sftpDecoder{data: data}
+	d.uint32() // request ID
+	attrs := d.attrs()
+	return attrs, d.err
+}
+
+// packets
+func (c *targetSftp) nextId() []byte {
+	c.reqId++
+	return binary.BigEndian.AppendUint32(nil, c.reqId)
+}
+
+func (c *targetSftp) readHandle() (string, error) {
+	packetType, data, err := c.readPacket()
+	if err != nil {
+		return "", err
+	}
+	if packetType == sftpPacketStatus {
+		if err := sftpGetStatusErr(data); err != nil {
+			return "", err
+		}
+	}
+	if packetType != sftpPacketHandle {
+		return "", fmt.Errorf("unexpected SFTP response type %d", packetType)
+	}
+
+	d := sftpDecoder{data: data}
+	d.uint32() // request ID
+	handle := d.string()
+	return handle, d.err
+}
+
+func (c *targetSftp) readPacket() (byte, []byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	if length == 0 || length > 1024*1024 {
+		return 0, nil, fmt.Errorf("invalid SFTP packet length %d", length)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, packet); err != nil {
+		return 0, nil, err
+	}
+	return packet[0], packet[1:], nil
+}
+
+func (c *targetSftp) readStatus() error {
+	packetType, data, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if packetType != sftpPacketStatus {
+		return fmt.Errorf("unexpected SFTP response type %d", packetType)
+	}
+	return sftpGetStatusErr(data)
+}
+
+func (c *targetSftp) sendPacket(packetType byte, payload []byte) error {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+	packet = append(packet, packetType)
+	_, err := c.writer.Write(append(packet, payload...))
+	return err
+}
+
+// helpers
+func sftpAppendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// returns error of status response, io.EOF for end of file & os.ErrNotExist for missing files
+func sftpGetStatusErr(data []byte) error {
+	d := sftpDecoder{data: data}
+	d.uint32() // request ID
+	code := d.uint32()
+	message := d.string()
+	if d.err != nil {
+		return d.err
+	}
+
+	switch code {
+	case sftpStatusOk:
+		return nil
+	case sftpStatusEof:
+		return io.EOF
+	case sftpStatusNoSuchFile:
+		return fmt.Errorf("SFTP error, %s, %w", message, os.ErrNotExist)
+	}
+	return fmt.Errorf("SFTP error %d, %s", code, message)
+}
+
+type sftpDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *sftpDecoder) attrs() sftpAttrs {
+	var attrs sftpAttrs
+	flags := d.uint32()
+	if flags&sftpAttrSize != 0 {
+		attrs.size = int64(d.uint64())
+	}
+	if flags&sftpAttrUidGid != 0 {
+		d.uint64()
+	}
+	if flags&sftpAttrPerms != 0 {
+		attrs.isDir = d.uint32()&sftpPermsTypeMask == sftpPermsDir
+	}
+	if flags&sftpAttrAcModTime != 0 {
+		d.uint64()
+	}
+	if flags&sftpAttrExtended != 0 {
+		count := d.uint32()
+		for i := uint32(0); i < count && d.err == nil; i++ {
+			d.string()
+			d.string()
+		}
+	}
+	return attrs
+}
+func (d *sftpDecoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.data) {
+		d.err = errors.New("invalid SFTP packet")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+func (d *sftpDecoder) string() string {
+	return string(d.bytes(int(d.uint32())))
+}
+func (d *sftpDecoder) uint32() uint32 {
+	b := d.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+func (d *sftpDecoder) uint64() uint64 {
+	b := d.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
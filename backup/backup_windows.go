@@ -14,3 +14,9 @@ func getPgDumpPath() string {
 	}
 	return "pg_dump"
 }
+func getPgRestorePath() string {
+	if config.File.Db.Embedded {
+		return filepath.Join(embedded.GetDbBinPath(), "pg_restore")
+	}
+	return "pg_restore"
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"r3/db"
 	"r3/types"
+	"slices"
 	"sync"
 
 	"github.com/gofrs/uuid"
@@ -15,6 +16,9 @@ import (
 var (
 	access_mx        sync.RWMutex
 	loginIdMapAccess = make(map[int64]types.LoginAccess) // access permissions by login ID
+
+	// attribute masks, ordered by how little they reveal
+	attributeMasksByStrength = []string{"last4", "year", "hash", "placeholder"}
 )
 
 // get effective access for specified login
@@ -87,21 +91,32 @@ func load_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
 		return err
 	}
 
+	adminPermissions := make([]string, 0)
+	if err := tx.QueryRow(ctx, `
+		SELECT admin_permissions::TEXT[]
+		FROM instance.login
+		WHERE id = $1
+	`, loginId).Scan(&adminPermissions); err != nil {
+		return err
+	}
+
 	Schema_mx.RLock()
 	defer Schema_mx.RUnlock()
 	access_mx.Lock()
 	defer access_mx.Unlock()
 
 	loginIdMapAccess[loginId] = types.LoginAccess{
-		RoleIds:     roleIds,
-		Api:         make(map[uuid.UUID]types.Access),
-		Attribute:   make(map[uuid.UUID]types.Access),
-		ClientEvent: make(map[uuid.UUID]types.Access),
-		Collection:  make(map[uuid.UUID]types.Access),
-		Menu:        make(map[uuid.UUID]types.Access),
-		Relation:    make(map[uuid.UUID]types.Access),
-		SearchBar:   make(map[uuid.UUID]types.Access),
-		Widget:      make(map[uuid.UUID]types.Access),
+		RoleIds:          roleIds,
+		AdminPermissions: adminPermissions,
+		Api:              make(map[uuid.UUID]types.Access),
+		Attribute:        make(map[uuid.UUID]types.Access),
+		ClientEvent:      make(map[uuid.UUID]types.Access),
+		Collection:       make(map[uuid.UUID]types.Access),
+		Menu:             make(map[uuid.UUID]types.Access),
+		Relation:         make(map[uuid.UUID]types.Access),
+		SearchBar:        make(map[uuid.UUID]types.Access),
+		Widget:           make(map[uuid.UUID]types.Access),
+		AttributeMask:    make(map[uuid.UUID]string),
 	}
 
 	for _, roleId := range roleIds {
@@ -186,6 +201,37 @@ func load_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
 			}
 		}
 	}
+
+	// resolve attribute masks
+	// if roles mask the same attribute differently, the mask revealing the least applies
+	for _, roleId := range roleIds {
+		for id, mask := range RoleIdMap[roleId].AttributeMasks {
+			if maskOther, exists := loginIdMapAccess[loginId].AttributeMask[id]; !exists ||
+				slices.Index(attributeMasksByStrength, maskOther) < slices.Index(attributeMasksByStrength, mask) {
+
+				loginIdMapAccess[loginId].AttributeMask[id] = mask
+			}
+		}
+	}
+
+	// a role granting read access to an attribute without masking it reveals its values
+	for _, roleId := range roleIds {
+		role := RoleIdMap[roleId]
+
+		for id, _ := range loginIdMapAccess[loginId].AttributeMask {
+			if _, exists := role.AttributeMasks[id]; exists {
+				continue
+			}
+
+			access, exists := role.AccessAttributes[id]
+			if !exists {
+				access, exists = role.AccessRelations[AttributeIdMap[id].RelationId]
+			}
+			if exists && access >= types.AccessRead {
+				delete(loginIdMapAccess[loginId].AttributeMask, id)
+			}
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"r3/login/login_external"
+	"r3/login/login_metaMap"
+	"r3/login/login_roleAssign"
+	"r3/types"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	samlIdp_mx        sync.RWMutex
+	samlIdpIdMap      map[int32]types.SamlIdp      // full map of all SAML identity providers
+	samlIdpIdMapLogin map[int32]types.SamlIdpLogin // public references of SAML identity providers for login page
+)
+
+func GetSamlIdp(id int32) (types.SamlIdp, error) {
+	samlIdp_mx.RLock()
+	defer samlIdp_mx.RUnlock()
+
+	p, exists := samlIdpIdMap[id]
+	if !exists {
+		return p, fmt.Errorf("SAML identity provider with ID %d does not exist", id)
+	}
+	return p, nil
+}
+func GetSamlIdpMap() map[int32]types.SamlIdp {
+	samlIdp_mx.RLock()
+	defer samlIdp_mx.RUnlock()
+
+	return samlIdpIdMap
+}
+func GetSamlIdpMapLogin() map[int32]types.SamlIdpLogin {
+	samlIdp_mx.RLock()
+	defer samlIdp_mx.RUnlock()
+
+	return samlIdpIdMapLogin
+}
+
+func LoadSamlIdpMap_tx(ctx context.Context, tx pgx.Tx) error {
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, login_template_id, name, entity_id, sso_url, certificate, sp_entity_id, acs_url,
+			attribute_admin, attribute_admin_value, attribute_roles, attribute_username
+		FROM instance.saml_idp
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	idMap := make(map[int32]types.SamlIdp)
+	idMapLogin := make(map[int32]types.SamlIdpLogin)
+
+	for rows.Next() {
+		var p types.SamlIdp
+		if err := rows.Scan(&p.Id, &p.LoginTemplateId, &p.Name, &p.EntityId, &p.SsoUrl, &p.Certificate,
+			&p.SpEntityId, &p.AcsUrl, &p.AttributeAdmin, &p.AttributeAdminValue, &p.AttributeRoles,
+			&p.AttributeUsername); err != nil {
+
+			return err
+		}
+		idMap[p.Id] = p
+		idMapLogin[p.Id] = types.SamlIdpLogin{
+			Id:   p.Id,
+			Name: p.Name,
+		}
+	}
+	rows.Close()
+
+	// retrieve login meta mapping
+	for k, p := range idMap {
+		p.LoginMetaMap, err = login_metaMap.Get_tx(ctx, tx, login_external.EntitySamlIdp, p.Id)
+		if err != nil {
+			return err
+		}
+		p.LoginRolesAssign, err = login_roleAssign.Get_tx(ctx, tx, login_external.EntitySamlIdp, p.Id)
+		if err != nil {
+			return err
+		}
+		idMap[k] = p
+	}
+
+	samlIdp_mx.Lock()
+	samlIdpIdMap = idMap
+	samlIdpIdMapLogin = idMapLogin
+	samlIdp_mx.Unlock()
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"r3/schema/loginForm"
 	"r3/schema/menuTab"
 	"r3/schema/module"
+	"r3/schema/numberRange"
 	"r3/schema/pgFunction"
 	"r3/schema/pgIndex"
 	"r3/schema/pgTrigger"
@@ -218,6 +219,7 @@ func updateSchemaCache_tx(ctx context.Context, tx pgx.Tx, moduleIds []uuid.UUID)
 		mod.Apis = make([]types.Api, 0)
 		mod.Docs = make([]types.Doc, 0)
 		mod.ClientEvents = make([]types.ClientEvent, 0)
+		mod.NumberRanges = make([]types.NumberRange, 0)
 		mod.SearchBars = make([]types.SearchBar, 0)
 		mod.Variables = make([]types.Variable, 0)
 		mod.Widgets = make([]types.Widget, 0)
@@ -389,6 +391,14 @@ func updateSchemaCache_tx(ctx context.Context, tx pgx.Tx, moduleIds []uuid.UUID)
 			return err
 		}
 
+		// get number ranges
+		log.Info(log.ContextCache, "load number ranges")
+
+		mod.NumberRanges, err = numberRange.Get_tx(ctx, tx, mod.Id)
+		if err != nil {
+			return err
+		}
+
 		// get variables
 		log.Info(log.ContextCache, "load variables")
 
@@ -90,6 +90,14 @@ func StartNode_tx(ctx context.Context, tx pgx.Tx) error {
 		`, nodeId); err != nil {
 			return err
 		}
+
+		// record subscriptions of clients before restart are gone
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance_cluster.node_subscription
+			WHERE node_id = $1
+		`, nodeId); err != nil {
+			return err
+		}
 	}
 
 	// store node details
@@ -99,7 +107,14 @@ func StartNode_tx(ctx context.Context, tx pgx.Tx) error {
 	return nil
 }
 func StopNode(ctx context.Context) error {
-	// on shutdown: Give up master role and disable running state
+	// on shutdown: Give up master role, disable running state and remove record subscriptions
+	if _, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance_cluster.node_subscription
+		WHERE node_id = $1
+	`, cache.GetNodeId()); err != nil {
+		return err
+	}
+
 	_, err := db.Pool.Exec(ctx, `
 		UPDATE instance_cluster.node
 		SET cluster_master = false, running = false
@@ -107,6 +122,30 @@ func StopNode(ctx context.Context) error {
 	`, cache.GetNodeId())
 	return err
 }
+
+// updates relations with record subscriptions of clients on this node
+// record changes of relations without subscriptions do not create node events
+func SetSubscriptionRelations(ctx context.Context, relationIdsAdd []uuid.UUID, relationIdsDel []uuid.UUID) error {
+	if len(relationIdsAdd) != 0 {
+		if _, err := db.Pool.Exec(ctx, `
+			INSERT INTO instance_cluster.node_subscription (node_id, relation_id)
+			SELECT $1, UNNEST($2::UUID[])
+			ON CONFLICT DO NOTHING
+		`, cache.GetNodeId(), relationIdsAdd); err != nil {
+			return err
+		}
+	}
+	if len(relationIdsDel) != 0 {
+		if _, err := db.Pool.Exec(ctx, `
+			DELETE FROM instance_cluster.node_subscription
+			WHERE node_id     = $1
+			AND   relation_id = ANY($2)
+		`, cache.GetNodeId(), relationIdsDel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 func DelNode_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
 	_, err := tx.Exec(ctx, `
 		DELETE FROM instance_cluster.node
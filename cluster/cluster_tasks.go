@@ -226,6 +226,28 @@ func KeystrokesRequested_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, ad
 	}
 	return nil
 }
+
+// renews access permissions of login on all nodes (including this one) after the transaction is committed
+// active sessions are kicked if permissions were revoked
+func LoginPermissionsChanged_tx(ctx context.Context, tx pgx.Tx, loginId int64, revoked bool) error {
+	target := types.ClusterEventTarget{LoginId: loginId}
+	contents := []string{"loginReauthorized"}
+	if revoked {
+		contents = append(contents, "loginDisabled")
+	}
+	for _, content := range contents {
+		if err := createEventsForOtherNodes_tx(ctx, tx, content, nil, target); err != nil {
+			return err
+		}
+		if err := CreateEventForNodes_tx(ctx, tx, []uuid.UUID{cache.GetNodeId()}, content, nil, target); err != nil {
+			return err
+		}
+	}
+
+	// wake up nodes to process events immediately, sent on commit
+	_, err := tx.Exec(ctx, `SELECT PG_NOTIFY('r3_node_event', '')`)
+	return err
+}
 func LoginDisabled_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, loginId int64) error {
 	target := types.ClusterEventTarget{LoginId: loginId}
 	if updateNodes {
@@ -277,6 +299,62 @@ func MasterAssigned(state bool) error {
 	SchedulerRestart <- true
 	return nil
 }
+func RecordsChanged(changes []types.ClusterEventRecordsChanged) {
+
+	// bulk updates create many events at once, merge them by relation, changing login & deletion state
+	type changeKey struct {
+		relationId uuid.UUID
+		loginId    int64
+		deleted    bool
+	}
+	type changeMerged struct {
+		attributeIds map[uuid.UUID]bool // nil if any change did not define attributes (unknown)
+		recordIds    map[int64]bool
+	}
+	keys := make([]changeKey, 0)
+	keyMapMerged := make(map[changeKey]changeMerged)
+
+	for _, c := range changes {
+		k := changeKey{relationId: c.RelationId, loginId: c.LoginId, deleted: c.Deleted}
+		m, exists := keyMapMerged[k]
+		if !exists {
+			keys = append(keys, k)
+			m = changeMerged{attributeIds: make(map[uuid.UUID]bool), recordIds: make(map[int64]bool)}
+		}
+		for _, id := range c.RecordIds {
+			m.recordIds[id] = true
+		}
+		if len(c.AttributeIds) == 0 {
+			m.attributeIds = nil
+		} else if m.attributeIds != nil {
+			for _, id := range c.AttributeIds {
+				m.attributeIds[id] = true
+			}
+		}
+		keyMapMerged[k] = m
+	}
+
+	for _, k := range keys {
+		m := keyMapMerged[k]
+		payload := types.ClusterEventRecordsChanged{
+			AttributeIds: make([]uuid.UUID, 0),
+			Deleted:      k.deleted,
+			LoginId:      k.loginId,
+			RecordIds:    make([]int64, 0),
+			RelationId:   k.relationId,
+		}
+		for id := range m.attributeIds {
+			payload.AttributeIds = append(payload.AttributeIds, id)
+		}
+		for id := range m.recordIds {
+			payload.RecordIds = append(payload.RecordIds, id)
+		}
+		WebsocketClientEvents <- types.ClusterEvent{
+			Content: "recordsChanged",
+			Payload: payload,
+		}
+	}
+}
 func ReposChanged(ctx context.Context, tx pgx.Tx, updateNodes bool) error {
 	if updateNodes {
 		if err := createEventsForOtherNodes_tx(ctx, tx, "reposChanged", nil, types.ClusterEventTarget{}); err != nil {
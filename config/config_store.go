@@ -18,7 +18,7 @@ var (
 	storeUint64      = make(map[string]uint64)
 	storeUint64Slice = make(map[string][]uint64)
 
-	NamesString = []string{"appName", "appNameShort", "backupDir",
+	NamesString = []string{"appName", "appNameShort", "backupDir", "backupEncryptKey",
 		"companyColorHeader", "companyColorLogin", "companyLoginImage",
 		"companyLogo", "companyLogoUrl", "companyName", "companyWelcome", "css",
 		"dbVersionCut", "exportPrivateKey", "iconPwa1", "iconPwa2",
@@ -27,19 +27,19 @@ var (
 
 	NamesStringSlice = []string{"adminMailAddresses", "hotkeyModExcl"}
 
-	NamesUint64 = []string{"backupDaily", "backupMonthly", "backupWeekly",
+	NamesUint64 = []string{"backupDaily", "backupMonthly", "backupWeekly", "backupVerify",
 		"backupCountDaily", "backupCountMonthly", "backupCountWeekly",
 		"bruteforceAttempts", "bruteforceProtection", "builderMode",
 		"clusterNodeMissingAfter", "dbTimeoutCsv", "dbTimeoutDataRest",
 		"dbTimeoutDataWs", "dbTimeoutIcs", "filesKeepDaysDeleted",
 		"fileVersionsKeepCount", "fileVersionsKeepDays", "icsDaysPost",
-		"icsDaysPre", "icsDownload", "imagerThumbWidth", "logApi", "logBackup",
+		"icsDaysPre", "icsDownload", "imagerThumbWidth", "jobsKeepDays", "logApi", "logBackup",
 		"logCache", "logCluster", "logCsv", "logDoc", "logFile", "logImager",
 		"logLdap", "logMail", "logModule", "logOauth", "logServer", "logScheduler",
-		"logTransfer", "logWebsocket", "logsKeepDays", "mailTrafficKeepDays",
+		"logTransfer", "logWebsocket", "logsKeepDays", "mailTrafficKeepDays", "moduleArchiveKeepCount",
 		"productionMode", "pwForceDigit", "pwForceLower", "pwForceSpecial",
-		"pwForceUpper", "pwLengthMin", "systemMsgDate0", "systemMsgDate1",
-		"systemMsgMaintenance", "tokenExpiryHours", "tokenKeepEnable"}
+		"pwForceUpper", "pwLengthMin", "repoServeActive", "scheduleRunsKeepDays", "systemMsgDate0",
+		"systemMsgDate1", "systemMsgMaintenance", "tokenExpiryHours", "tokenKeepEnable"}
 
 	NamesUint64Slice = []string{"loginBackgrounds"}
 )
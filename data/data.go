@@ -73,6 +73,11 @@ func relationUsesLogging(retentionCount pgtype.Int4, retentionDays pgtype.Int4)
 	return (retentionCount.Valid && retentionCount.Int32 != 0) || (retentionDays.Valid && retentionDays.Int32 != 0)
 }
 
+// check whether a relation keeps deleted records in its recycle bin
+func relationUsesRecycle(recycleDays pgtype.Int4) bool {
+	return recycleDays.Valid && recycleDays.Int32 != 0
+}
+
 // get the names of policy blacklist & whitelist functions (empty strings if no functions are available)
 // functions are available if a relation policy fits the given logins role memberships for the given action
 func getPolicyFunctionNames(loginId int64, policies []types.RelationPolicy, action string) (string, string, error) {
@@ -46,12 +46,29 @@ func Del_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordId int64
 		return err
 	}
 
-	_, err = tx.Exec(ctx, fmt.Sprintf(`
+	// keep record in recycle bin, if enabled
+	// encrypted relations are excluded, as data keys are deleted with the record
+	if relationUsesRecycle(rel.RecycleDays) && !rel.Encryption {
+		if err := recycle_tx(ctx, tx, rel, mod, recordId, loginId, tableAlias, policyFilter); err != nil {
+			return err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf(`
 		DELETE FROM "%s"."%s" AS "%s"
 		WHERE "%s"."%s" = $1
 		%s
 	`, mod.Name, rel.Name, tableAlias, tableAlias,
 		schema.PkName, policyFilter), recordId)
 
-	return err
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+
+	if err := SearchIndexDel_tx(ctx, tx, rel.Id, []int64{recordId}); err != nil {
+		return err
+	}
+
+	// inform subscribed clients about deleted record
+	return recordsChanged_tx(ctx, tx, rel.Id, []int64{recordId}, true, []uuid.UUID{})
 }
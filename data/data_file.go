@@ -218,6 +218,14 @@ func FileApplyVersion_tx(ctx context.Context, tx pgx.Tx, isNewFile bool, attribu
 		return err
 	}
 
+	// queue text extraction of new file version
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.file_text (file_id,version,name)
+		VALUES ($1,$2,$3)
+	`, fileId, fileVersion, fileName); err != nil {
+		return err
+	}
+
 	// skip change log if new file or file is not attached to any record
 	// new file change logs are stored when record is saved
 	if isNewFile || len(recordIds) == 0 {
@@ -0,0 +1,26 @@
+package data
+
+import (
+	"fmt"
+	"r3/schema"
+
+	"github.com/gofrs/uuid"
+)
+
+// returns SQL expression for searchable text of all files of a files attribute assigned to a record
+// includes file names and extracted text of latest file versions, text is extracted in the background
+func getFilesTextExpr(attributeId uuid.UUID, recordIdExpr string) string {
+	return fmt.Sprintf(`(
+		SELECT STRING_AGG(CONCAT_WS(' ', r.name, t.content), ' ')
+		FROM instance_file."%s" AS r
+		LEFT JOIN instance.file_text AS t
+			ON  t.file_id = r.file_id
+			AND t.version = (
+				SELECT MAX(v.version)
+				FROM instance.file_version AS v
+				WHERE v.file_id = r.file_id
+			)
+		WHERE r.record_id = %s
+		AND   r.date_delete IS NULL
+	)`, schema.GetFilesTableName(attributeId), recordIdExpr)
+}
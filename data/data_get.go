@@ -97,11 +97,13 @@ func Get_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, q
 		}
 
 		results = append(results, types.DataGetResult{
-			IndexRecordIds:     indexRecordIds,
-			IndexRecordEncKeys: indexRecordEncKeys,
-			IndexesPermNoDel:   make([]int, 0),
-			IndexesPermNoSet:   make([]int, 0),
-			Values:             values,
+			IndexRecordIds:      indexRecordIds,
+			IndexRecordEncKeys:  indexRecordEncKeys,
+			IndexRecordVersions: make(map[int]int64),
+			IndexesPermNoDel:    make([]int, 0),
+			IndexesPermNoSet:    make([]int, 0),
+			Values:              values,
+			ValuesNoAsOf:        make([]int, 0),
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -207,6 +209,56 @@ func Get_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, q
 		}
 	}
 
+	// get record versions of result records for all relations (base & joined)
+	if data.GetVersion && len(results) != 0 {
+		for index, relationId := range indexRelationIds {
+			recordIds := make([]int64, 0)
+			for _, res := range results {
+				switch v := res.IndexRecordIds[index].(type) {
+				case int32:
+					recordIds = append(recordIds, int64(v))
+				case int64:
+					recordIds = append(recordIds, v)
+				}
+			}
+			if len(recordIds) == 0 {
+				continue
+			}
+
+			recordIdMapVersion, err := getRecordVersions_tx(ctx, tx, relationId, recordIds, false)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			for i, res := range results {
+				var recordId int64
+				switch v := res.IndexRecordIds[index].(type) {
+				case int32:
+					recordId = int64(v)
+				case int64:
+					recordId = v
+				default:
+					continue
+				}
+				if version, exists := recordIdMapVersion[recordId]; exists {
+					results[i].IndexRecordVersions[index] = version
+				}
+			}
+		}
+	}
+
+	// reconstruct values from change logs
+	if data.AsOf.Valid && len(results) != 0 {
+		if err := applyAsOf_tx(ctx, tx, data, results); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// mask sensitive values, after reconstructing as they might be restored from change logs
+	if len(results) != 0 {
+		applyMasks(data, results, loginId)
+	}
+
 	// check for encrypted attributes in expressions
 	for _, expr := range data.Expressions {
 
@@ -347,6 +399,13 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 		}
 	}
 
+	// add filter for records that existed at the as-of date
+	if !isSubQuery && data.AsOf.Valid {
+		if asOfFilter := getAsOfFilter(rel, getRelationCode(data.IndexSource, nestingLevel), data.AsOf.Int64, queryArgs); asOfFilter != "" {
+			inWhere = append(inWhere, asOfFilter)
+		}
+	}
+
 	// add filters to query, replacing first AND with WHERE
 	queryWhere := strings.Replace(strings.Join(inWhere, ""), "AND", "WHERE", 1)
 
@@ -434,7 +493,7 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 	}
 
 	// build ORDER BY
-	queryOrder, err := getQueryLineOrderBy(data, nestingLevel)
+	queryOrder, err := getQueryLineOrderBy(data, loginId, nestingLevel)
 	if err != nil {
 		return "", err
 	}
@@ -642,7 +701,9 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]any, loginId int64,
 	}
 
 	var opFtsDictAtrId pgtype.UUID
+	exprRegconfigFiles := exprRegconfigSimple
 	isOpFts := isFtsOperator(filter.Operator)
+	isOpFtsFiles := false
 	isOpLike := isLikeOperator(filter.Operator)
 	isOpNull := isNullOperator(filter.Operator)
 
@@ -662,6 +723,14 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]any, loginId int64,
 				return "", handler.ErrSchemaUnknownAttribute(s.AttributeId.Bytes)
 			}
 
+			// file contents are searched with the dictionary chosen by the requestor
+			if schema.IsContentFiles(atr.Content) {
+				isOpFtsFiles = true
+				if filter.Side1.FtsDict.Valid && cache.GetSearchDictionaryIsValid(filter.Side1.FtsDict.String) {
+					exprRegconfigFiles = fmt.Sprintf("'%s'", filter.Side1.FtsDict.String)
+				}
+			}
+
 			// we can apply a dictionary attribute (eg. regconfig) from an text index (GIN) if available
 			rel := cache.RelationIdMap[atr.RelationId]
 			for _, ind := range rel.Indexes {
@@ -693,8 +762,27 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]any, loginId int64,
 			if !exists {
 				return "", handler.ErrSchemaUnknownAttribute(s.AttributeId.Bytes)
 			}
+			// files attributes have no column, their extracted file contents can be searched
+			// file contents require the same access as downloading the files, masked files never match
+			if schema.IsContentFiles(atr.Content) {
+				if !isOpFts {
+					return "", errors.New("files attributes can only be filtered by full text search")
+				}
+				expr := "NULL::TEXT"
+				if (loginId == -1 || authorizedAttributes(loginId, []uuid.UUID{atr.Id}, types.AccessRead)) && getAttributeMask(loginId, atr.Id) == "" {
+					expr = getFilesTextExpr(atr.Id, getAttributeCode(getRelationCode(s.AttributeIndex, s.AttributeNested), schema.PkName))
+				}
+				return getFtsExpression(exprRegconfigFiles, expr, isSide0), nil
+			}
+
 			atrExpr := getAttributeCode(getRelationCode(s.AttributeIndex, s.AttributeNested), atr.Name)
 
+			// masked values can only be checked for existence, other comparisons would reveal them
+			// NULLIF keeps the data type of the attribute while never matching any value
+			if !isOpNull && getAttributeMask(loginId, atr.Id) != "" {
+				atrExpr = fmt.Sprintf("NULLIF(%s,%s)", atrExpr, atrExpr)
+			}
+
 			if isOpFts {
 				exprRegconfig := exprRegconfigSimple
 				if opFtsDictAtrId.Valid {
@@ -740,6 +828,9 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]any, loginId int64,
 		// add value to query arguments and refer to it via placeholder
 		*queryArgs = append(*queryArgs, s.Value)
 
+		if isOpFtsFiles {
+			return getFtsExpression(exprRegconfigFiles, fmt.Sprintf("$%d", (len(*queryArgs))), isSide0), nil
+		}
 		if isOpFts {
 			exprRegconfig := exprRegconfigSimple
 			if opFtsDictAtrId.Valid && s.FtsDict.Valid && cache.GetSearchDictionaryIsValid(s.FtsDict.String) {
@@ -787,16 +878,26 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]any, loginId int64,
 		getBrackets(filter.Side1.Brackets, true)), nil
 }
 
-func getQueryLineOrderBy(data types.DataGet, nestingLevel int) (string, error) {
+func getQueryLineOrderBy(data types.DataGet, loginId int64, nestingLevel int) (string, error) {
 
 	if len(data.Orders) == 0 {
 		return "", nil
 	}
 
-	orderItems := make([]string, len(data.Orders))
+	orderItems := make([]string, 0, len(data.Orders))
 	var alias string
 
-	for i, ord := range data.Orders {
+	for _, ord := range data.Orders {
+
+		// ordering by masked values would reveal their order, ignore these orders
+		if ord.AttributeId.Valid && getAttributeMask(loginId, ord.AttributeId.Bytes) != "" {
+			continue
+		}
+		if ord.ExpressionPos.Valid && int(ord.ExpressionPos.Int32) < len(data.Expressions) {
+			if _, mask := getExpressionMask(data.Expressions[ord.ExpressionPos.Int32], loginId); mask != "" {
+				continue
+			}
+		}
 
 		if ord.AttributeId.Valid {
 
@@ -832,11 +933,14 @@ func getQueryLineOrderBy(data types.DataGet, nestingLevel int) (string, error) {
 		}
 
 		if ord.Ascending {
-			orderItems[i] = fmt.Sprintf("%s ASC", alias)
+			orderItems = append(orderItems, fmt.Sprintf("%s ASC", alias))
 		} else {
-			orderItems[i] = fmt.Sprintf("%s DESC NULLS LAST", alias)
+			orderItems = append(orderItems, fmt.Sprintf("%s DESC NULLS LAST", alias))
 		}
 	}
+	if len(orderItems) == 0 {
+		return "", nil
+	}
 	return fmt.Sprintf("\nORDER BY %s", strings.Join(orderItems, ", ")), nil
 }
 
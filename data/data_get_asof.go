@@ -0,0 +1,302 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// returns positions of data GET expressions, whose values cannot be reconstructed from change logs
+func GetAsOfExpressionsInvalid(data types.DataGet) []int {
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	return getAsOfExpressionsInvalid(data)
+}
+
+// only direct attribute values of relations with change logs can be reconstructed
+// sub queries, aggregations, file attributes & relationship values from other relations are not logged per record
+func getAsOfExpressionsInvalid(data types.DataGet) []int {
+	positions := make([]int, 0)
+
+	aggregationUsed := false
+	for _, expr := range data.Expressions {
+		if expr.Aggregator.Valid || expr.GroupBy {
+			aggregationUsed = true
+			break
+		}
+	}
+
+	for pos, expr := range data.Expressions {
+		if expr.ReturnNull {
+			continue
+		}
+		if aggregationUsed || !expr.AttributeId.Valid || expr.AttributeIdNm.Valid || expr.OutsideIn {
+			positions = append(positions, pos)
+			continue
+		}
+
+		atr, exists := cache.AttributeIdMap[expr.AttributeId.Bytes]
+		if !exists || schema.IsContentFiles(atr.Content) {
+			positions = append(positions, pos)
+			continue
+		}
+
+		rel, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			positions = append(positions, pos)
+			continue
+		}
+
+		// primary key never changes
+		if atr.Name == schema.PkName {
+			continue
+		}
+		if !relationUsesLogging(rel.RetentionCount, rel.RetentionDays) {
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
+
+// returns SQL filter for source relation, to only include records that existed at the as-of date
+// records that were logged before the as-of date existed, records without any logs have not changed since logging was enabled
+// records only logged after the as-of date are excluded, they were created afterwards
+// records whose older logs might have been removed by retention are kept, they are marked as not reconstructable
+// deleted records cannot be reconstructed, only currently existing records are included
+func getAsOfFilter(rel types.Relation, relCode string, asOf int64, queryArgs *[]any) string {
+	if !relationUsesLogging(rel.RetentionCount, rel.RetentionDays) {
+		return ""
+	}
+
+	*queryArgs = append(*queryArgs, rel.Id)
+	argRel := len(*queryArgs)
+	*queryArgs = append(*queryArgs, asOf)
+	argDate := len(*queryArgs)
+
+	havingRetention := ""
+	if rel.RetentionCount.Valid {
+		*queryArgs = append(*queryArgs, rel.RetentionCount.Int32)
+		havingRetention = fmt.Sprintf("\n\tAND   COUNT(*) < $%d", len(*queryArgs))
+	}
+
+	return fmt.Sprintf("\nAND NOT EXISTS (\n"+
+		"\tSELECT 1\n"+
+		"\tFROM instance.data_log\n"+
+		"\tWHERE relation_id    = $%d\n"+
+		"\tAND   record_id_wofk = \"%s\".\"%s\"\n"+
+		"\tHAVING MIN(date_change) > $%d%s\n"+
+		")", argRel, relCode, schema.PkName, argDate, havingRetention)
+}
+
+// replaces expression values of results with their values at the as-of date, reconstructed from change logs
+// values that cannot be reconstructed are replaced with NULL, values whose logs were removed by retention are marked per result
+// current values are only kept if they were never changed after the as-of date
+// filters, orders & joins are applied to current values
+func applyAsOf_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, results []types.DataGetResult) error {
+
+	positionsInvalid := getAsOfExpressionsInvalid(data)
+	indexMapAttributeIds := make(map[int][]uuid.UUID)
+
+	for pos, expr := range data.Expressions {
+		if slices.Contains(positionsInvalid, pos) {
+			for i := range results {
+				results[i].Values[pos] = nil
+			}
+			continue
+		}
+		if expr.ReturnNull {
+			continue
+		}
+
+		atr := cache.AttributeIdMap[expr.AttributeId.Bytes]
+		if atr.Name == schema.PkName {
+			continue
+		}
+		if !slices.Contains(indexMapAttributeIds[expr.Index], atr.Id) {
+			indexMapAttributeIds[expr.Index] = append(indexMapAttributeIds[expr.Index], atr.Id)
+		}
+	}
+
+	for index, attributeIds := range indexMapAttributeIds {
+
+		recordIds := make([]int64, 0)
+		for _, result := range results {
+			if recordId, valid := getRecordIdFromValue(result.IndexRecordIds[index]); valid {
+				recordIds = append(recordIds, recordId)
+			}
+		}
+
+		// values without logs were not changed since, values only logged afterwards were not set
+		// if older logs might have been removed by retention, values only logged afterwards are unknown
+		rel := cache.RelationIdMap[cache.AttributeIdMap[attributeIds[0]].RelationId]
+		recordIdsPurged, recordIdsUnknown, err := getAsOfRecordsPurged_tx(ctx, tx, rel, recordIds, data.AsOf.Int64)
+		if err != nil {
+			return err
+		}
+
+		attributeIdMapRecordIdMapValue := make(map[uuid.UUID]map[int64]any)
+		attributeIdMapRecordIdsUnknown := make(map[uuid.UUID]map[int64]bool)
+		for _, attributeId := range attributeIds {
+			recordIdMapValue, recordIdsLoggedAfter, err := getAsOfValues_tx(ctx, tx, cache.AttributeIdMap[attributeId], recordIds, data.AsOf.Int64)
+			if err != nil {
+				return err
+			}
+			attributeIdMapRecordIdMapValue[attributeId] = recordIdMapValue
+			attributeIdMapRecordIdsUnknown[attributeId] = make(map[int64]bool)
+
+			for recordId := range recordIdsPurged {
+				if recordIdsUnknown[recordId] || recordIdsLoggedAfter[recordId] {
+					attributeIdMapRecordIdsUnknown[attributeId][recordId] = true
+				}
+			}
+		}
+
+		for i, result := range results {
+			recordId, valid := getRecordIdFromValue(result.IndexRecordIds[index])
+
+			for pos, expr := range data.Expressions {
+				if expr.Index != index || expr.ReturnNull || !expr.AttributeId.Valid {
+					continue
+				}
+				recordIdMapValue, exists := attributeIdMapRecordIdMapValue[expr.AttributeId.Bytes]
+				if !exists {
+					continue
+				}
+				if !valid {
+					results[i].Values[pos] = nil
+					continue
+				}
+				if attributeIdMapRecordIdsUnknown[expr.AttributeId.Bytes][recordId] {
+					results[i].Values[pos] = nil
+					results[i].ValuesNoAsOf = append(results[i].ValuesNoAsOf, pos)
+					continue
+				}
+				if value, exists := recordIdMapValue[recordId]; exists {
+					results[i].Values[pos] = value
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// returns records whose older change logs might have been removed by retention
+// retention removes the oldest logs of a record beyond its retained count, records with less logs were not affected
+// also returns records whose retained logs all start after the as-of date, their state at the as-of date is unknown
+func getAsOfRecordsPurged_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, recordIds []int64,
+	asOf int64) (map[int64]bool, map[int64]bool, error) {
+
+	recordIdsPurged := make(map[int64]bool)
+	recordIdsUnknown := make(map[int64]bool)
+
+	// without retention count, logs are never removed
+	if !rel.RetentionCount.Valid {
+		return recordIdsPurged, recordIdsUnknown, nil
+	}
+
+	// records without any logs are included, all their logs might have been removed
+	rows, err := tx.Query(ctx, `
+		SELECT r.id, MIN(d.date_change), COUNT(d.id)
+		FROM UNNEST($2::BIGINT[]) AS r(id)
+		LEFT JOIN instance.data_log AS d
+			ON  d.relation_id    = $1
+			AND d.record_id_wofk = r.id
+		GROUP BY r.id
+	`, rel.Id, recordIds)
+	if err != nil {
+		return recordIdsPurged, recordIdsUnknown, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recordId, count int64
+		var dateFirst pgtype.Int8
+		if err := rows.Scan(&recordId, &dateFirst, &count); err != nil {
+			return recordIdsPurged, recordIdsUnknown, err
+		}
+		if count < int64(rel.RetentionCount.Int32) {
+			continue
+		}
+		recordIdsPurged[recordId] = true
+
+		if !dateFirst.Valid || dateFirst.Int64 > asOf {
+			recordIdsUnknown[recordId] = true
+		}
+	}
+	return recordIdsPurged, recordIdsUnknown, rows.Err()
+}
+
+// returns latest logged attribute values of records up to the as-of date
+// records with values only logged after the as-of date are returned with NULL & marked as logged after, records without logged values are not returned
+// logged JSON values are cast to the attribute type, to match values of regular data queries
+func getAsOfValues_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute, recordIds []int64, asOf int64) (map[int64]any, map[int64]bool, error) {
+
+	recordIdMapValue := make(map[int64]any)
+	recordIdsLoggedAfter := make(map[int64]bool)
+
+	castType := atr.Content
+	if schema.IsContentRelationship(atr.Content) {
+		rel, exists := cache.RelationIdMap[atr.RelationshipId.Bytes]
+		if !exists {
+			return recordIdMapValue, recordIdsLoggedAfter, handler.ErrSchemaUnknownRelation(atr.RelationshipId.Bytes)
+		}
+		atrPk, exists := cache.AttributeIdMap[rel.AttributeIdPk]
+		if !exists {
+			return recordIdMapValue, recordIdsLoggedAfter, handler.ErrSchemaUnknownAttribute(rel.AttributeIdPk)
+		}
+		castType = atrPk.Content
+	}
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT DISTINCT ON (d.record_id_wofk)
+			d.record_id_wofk,
+			d.date_change > $3,
+			CASE WHEN d.date_change <= $3 THEN (v.value::JSONB #>> '{}')::%s END
+		FROM instance.data_log_value AS v
+		JOIN instance.data_log       AS d ON d.id = v.data_log_id
+		WHERE d.relation_id     = $1
+		AND   d.record_id_wofk  = ANY($2)
+		AND   v.attribute_id    = $4
+		AND   v.attribute_id_nm IS NULL
+		AND   v.outside_in      = FALSE
+		ORDER BY d.record_id_wofk, d.date_change <= $3 DESC, d.date_change DESC
+	`, castType), atr.RelationId, recordIds, asOf, atr.Id)
+	if err != nil {
+		return recordIdMapValue, recordIdsLoggedAfter, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return recordIdMapValue, recordIdsLoggedAfter, err
+		}
+		if recordId, valid := values[0].(int64); valid {
+			recordIdMapValue[recordId] = values[2]
+
+			if loggedAfter, valid := values[1].(bool); valid && loggedAfter {
+				recordIdsLoggedAfter[recordId] = true
+			}
+		}
+	}
+	return recordIdMapValue, recordIdsLoggedAfter, rows.Err()
+}
+
+func getRecordIdFromValue(v any) (int64, bool) {
+	switch id := v.(type) {
+	case int32:
+		return int64(id), id != 0
+	case int64:
+		return id, id != 0
+	}
+	return 0, false
+}
@@ -52,8 +52,10 @@ func ResolveQueryLookups(joins []types.QueryJoin, lookups []types.QueryLookup) m
 // executes a data SET call from a list of ordered any values
 // uses columns to recognize attribute (and their orders)
 // uses query joins/lookups to recognize relationships and resolve records via unique indexes
+// optional version of base record rejects the data SET if the identified record was changed since
 func FromInterfaceValues_tx(ctx context.Context, tx pgx.Tx, loginId int64, valuesIn []any, columns []types.Column,
-	joins []types.QueryJoin, lookups []types.QueryLookup, indexMapPgIndexAttributeIds map[int][]uuid.UUID) (map[int]int64, error) {
+	joins []types.QueryJoin, lookups []types.QueryLookup, indexMapPgIndexAttributeIds map[int][]uuid.UUID,
+	version pgtype.Int8) (map[int]int64, error) {
 
 	indexRecordIds := make(map[int]int64)
 
@@ -303,5 +305,15 @@ func FromInterfaceValues_tx(ctx context.Context, tx pgx.Tx, loginId int64, value
 			}
 		}
 	}
+
+	// apply known version of base record, data SET is rejected if record was changed since
+	if version.Valid {
+		dataSet := dataSetsByIndex[0]
+		if dataSet.RecordId == 0 {
+			return indexRecordIds, errors.New("record version is given but no existing record was identified")
+		}
+		dataSet.Version = version
+		dataSetsByIndex[0] = dataSet
+	}
 	return data.Set_tx(ctx, tx, dataSetsByIndex, loginId)
 }
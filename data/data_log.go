@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"r3/cache"
 	"r3/db"
 	"r3/handler"
+	"r3/schema"
 	"r3/tools"
 	"r3/types"
 	"slices"
@@ -139,6 +141,7 @@ func GetLogs_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, attributeI
 		}
 		logs = append(logs, l)
 	}
+	applyMasksToLogs(logs, loginId)
 	return logs, nil
 }
 
@@ -272,3 +275,101 @@ func setLogValue_tx(ctx context.Context, tx pgx.Tx, logId uuid.UUID, atr types.D
 	}
 	return nil
 }
+
+// restores attribute values of a record to their state after the given change log
+// file & encrypted attributes cannot be restored, file changes are logged as deltas & encrypted values depend on data keys
+// attribute values are applied as regular data change, access permissions & policies are checked
+func RestoreLog_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordId int64,
+	dataLogId uuid.UUID, attributeIds []uuid.UUID, loginId int64) error {
+
+	cache.Schema_mx.RLock()
+	attributeIdsRestore := make([]uuid.UUID, 0)
+	for _, id := range attributeIds {
+		atr, exists := cache.AttributeIdMap[id]
+		if !exists {
+			cache.Schema_mx.RUnlock()
+			return handler.ErrSchemaUnknownAttribute(id)
+		}
+		// attributes of the relation itself or relationship attributes referring to it (1:n, 1:1 outside-in)
+		if atr.RelationId != relationId && (!atr.RelationshipId.Valid || atr.RelationshipId.Bytes != relationId) {
+			cache.Schema_mx.RUnlock()
+			return fmt.Errorf("attribute '%s' does not belong to relation of record", atr.Name)
+		}
+		if schema.IsContentFiles(atr.Content) || atr.Encrypted {
+			continue
+		}
+		attributeIdsRestore = append(attributeIdsRestore, id)
+	}
+	authorized := authorizedAttributes(loginId, attributeIdsRestore, types.AccessRead)
+	cache.Schema_mx.RUnlock()
+
+	if !authorized {
+		return errors.New(handler.ErrUnauthorized)
+	}
+	if len(attributeIdsRestore) == 0 {
+		return nil
+	}
+
+	var dateChange int64
+	if err := tx.QueryRow(ctx, `
+		SELECT date_change
+		FROM instance.data_log
+		WHERE id             = $1
+		AND   relation_id    = $2
+		AND   record_id_wofk = $3
+	`, dataLogId, relationId, recordId).Scan(&dateChange); err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("change log '%s' does not exist for record %d", dataLogId, recordId)
+		}
+		return err
+	}
+
+	// get latest logged value for each attribute up to the given change log
+	// on same change date, the given change log wins
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (v.attribute_id, v.attribute_id_nm, v.outside_in)
+			v.attribute_id, v.attribute_id_nm, v.outside_in, v.value
+		FROM instance.data_log_value AS v
+		JOIN instance.data_log       AS d ON d.id = v.data_log_id
+		WHERE d.relation_id    =  $1
+		AND   d.record_id_wofk =  $2
+		AND   d.date_change    <= $3
+		AND   v.attribute_id   =  ANY($4)
+		ORDER BY v.attribute_id, v.attribute_id_nm, v.outside_in,
+			d.date_change DESC, (d.id = $5) DESC
+	`, relationId, recordId, dateChange, attributeIdsRestore, dataLogId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	attributes := make([]types.DataSetAttribute, 0)
+	for rows.Next() {
+		var a types.DataSetAttribute
+		var value pgtype.Text
+		if err := rows.Scan(&a.AttributeId, &a.AttributeIdNm, &a.OutsideIn, &value); err != nil {
+			return err
+		}
+		if value.Valid {
+			if err := json.Unmarshal([]byte(value.String), &a.Value); err != nil {
+				return err
+			}
+		}
+		attributes = append(attributes, a)
+	}
+	rows.Close()
+
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	_, err = Set_tx(ctx, tx, map[int]types.DataSet{
+		0: {
+			RelationId:  relationId,
+			AttributeId: uuid.Nil,
+			RecordId:    recordId,
+			Attributes:  attributes,
+		},
+	}, loginId)
+	return err
+}
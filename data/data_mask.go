@@ -0,0 +1,162 @@
+package data
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"r3/cache"
+	"r3/config"
+	"r3/types"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// masks hide sensitive attribute values from logins whose roles only allow masked access
+// values are masked before they leave the server, clients never receive the original values
+
+const maskPlaceholder = "****"
+
+// returns key for hash masks, derived from the instance token secret which never leaves the server
+func getMaskHashKey() []byte {
+	key := sha256.Sum256([]byte("attributeMask" + config.GetString("tokenSecret")))
+	return key[:]
+}
+
+// returns mask applied to attribute values for login, empty if values are not masked
+func getAttributeMask(loginId int64, attributeId uuid.UUID) string {
+
+	// system tasks read unmasked values
+	if loginId == -1 {
+		return ""
+	}
+
+	access, err := cache.GetAccessById(loginId)
+	if err != nil {
+		return ""
+	}
+	return access.AttributeMask[attributeId]
+}
+
+// returns attribute & mask applied to values of data GET expression for login, empty mask if values are not masked
+func getExpressionMask(expr types.DataGetExpression, loginId int64) (uuid.UUID, string) {
+
+	// sub query expressions return the value of their only expression
+	exprAtr := expr
+	if expr.Query.RelationId != uuid.Nil && len(expr.Query.Expressions) != 0 {
+		exprAtr = expr.Query.Expressions[0]
+	}
+
+	// relationship expressions from other relations return record IDs, not attribute values
+	if !exprAtr.AttributeId.Valid || exprAtr.OutsideIn {
+		return uuid.Nil, ""
+	}
+
+	// counts do not reveal values
+	if expr.Aggregator.Valid && expr.Aggregator.String == "count" {
+		return uuid.Nil, ""
+	}
+	return exprAtr.AttributeId.Bytes, getAttributeMask(loginId, exprAtr.AttributeId.Bytes)
+}
+
+// masks values of data GET results in place
+func applyMasks(data types.DataGet, results []types.DataGetResult, loginId int64) {
+
+	for i, expr := range data.Expressions {
+
+		attributeId, mask := getExpressionMask(expr, loginId)
+		if mask == "" {
+			continue
+		}
+
+		for j := range results {
+			if expr.Aggregator.Valid && !isMaskableAggregator(expr.Aggregator.String) {
+				// aggregations over multiple values cannot be masked, remove them
+				results[j].Values[i] = nil
+				continue
+			}
+			results[j].Values[i] = maskValue(attributeId, mask, results[j].Values[i])
+		}
+	}
+}
+
+// masks values of attribute change logs in place
+func applyMasksToLogs(logs []types.DataLog, loginId int64) {
+	for i, l := range logs {
+		for j, a := range l.Attributes {
+			if a.OutsideIn {
+				continue
+			}
+			if mask := getAttributeMask(loginId, a.AttributeId); mask != "" {
+				logs[i].Attributes[j].Value = maskValue(a.AttributeId, mask, a.Value)
+			}
+		}
+	}
+}
+
+// masks values of recycle bin entries in place
+func applyMasksToRecycle(entries []types.DataRecycle, loginId int64) {
+	for i, e := range entries {
+		for attributeId, value := range e.Values {
+			if mask := getAttributeMask(loginId, attributeId); mask != "" {
+				entries[i].Values[attributeId] = maskValue(attributeId, mask, value)
+			}
+		}
+	}
+}
+
+// aggregators that return a single, unchanged value
+func isMaskableAggregator(aggregator string) bool {
+	return aggregator == "max" || aggregator == "min" || aggregator == "record"
+}
+
+func maskValue(attributeId uuid.UUID, mask string, value any) any {
+	if value == nil {
+		return nil
+	}
+
+	switch mask {
+	case "last4":
+		v, ok := value.(string)
+		if !ok {
+			return maskPlaceholder
+		}
+		runes := []rune(v)
+		if len(runes) <= 4 {
+			return maskPlaceholder
+		}
+		return maskPlaceholder + string(runes[len(runes)-4:])
+
+	case "hash":
+		// hash is stable per attribute, identical values can be recognized without being revealed
+		v, ok := value.(string)
+		if !ok {
+			return maskPlaceholder
+		}
+		// keyed with instance secret, as low-entropy values (phone numbers, birth dates) could be brute-forced otherwise
+		mac := hmac.New(sha256.New, getMaskHashKey())
+		mac.Write([]byte(attributeId.String() + v))
+		return hex.EncodeToString(mac.Sum(nil))[:16]
+
+	case "year":
+		// dates are stored as unix timestamps, truncate to first day of year
+		var getYear = func(unix int64) int64 {
+			return time.Date(time.Unix(unix, 0).UTC().Year(), 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+		}
+		switch v := value.(type) {
+		case int32:
+			return int32(getYear(int64(v)))
+		case int64:
+			return getYear(v)
+		case float64:
+			return float64(getYear(int64(v)))
+		}
+		return nil
+	}
+
+	// placeholder, also used for unknown masks as values must never be returned unmasked
+	if _, ok := value.(string); ok {
+		return maskPlaceholder
+	}
+	return nil
+}
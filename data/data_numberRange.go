@@ -0,0 +1,69 @@
+package data
+
+import (
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/types"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// adds number range allocations to insert statement of new record
+// numbers are allocated by the instance function within the same transaction, rollbacks release them again
+// given values are kept (e.g. from data imports), numbers are only assigned to empty attributes
+func addNumberRanges(mod types.Module, rel types.Relation, names []string,
+	params []string, values []any) ([]string, []string, []any, error) {
+
+	for _, n := range mod.NumberRanges {
+		if !n.AttributeId.Valid {
+			continue
+		}
+		atr, exists := cache.AttributeIdMap[n.AttributeId.Bytes]
+		if !exists {
+			return names, params, values, handler.ErrSchemaUnknownAttribute(n.AttributeId.Bytes)
+		}
+		if atr.RelationId != rel.Id {
+			continue
+		}
+
+		// scope value is taken from the new record
+		scope := pgtype.Text{}
+		if n.AttributeIdScope.Valid {
+			atrScope, exists := cache.AttributeIdMap[n.AttributeIdScope.Bytes]
+			if !exists {
+				return names, params, values, handler.ErrSchemaUnknownAttribute(n.AttributeIdScope.Bytes)
+			}
+			if i := slices.Index(names, fmt.Sprintf(`"%s"`, atrScope.Name)); i != -1 {
+				scope = getNumberRangeScope(values[i])
+			}
+		}
+		values = append(values, n.Id, scope)
+		call := fmt.Sprintf(`instance.number_range_next($%d, $%d)`, len(values)-1, len(values))
+
+		if i := slices.Index(names, fmt.Sprintf(`"%s"`, atr.Name)); i != -1 {
+			params[i] = fmt.Sprintf(`COALESCE(%s, %s)`, params[i], call)
+		} else {
+			names = append(names, fmt.Sprintf(`"%s"`, atr.Name))
+			params = append(params, call)
+		}
+	}
+	return names, params, values, nil
+}
+
+// returns scope value as text, matching the text cast of the value inside the database
+func getNumberRangeScope(value any) pgtype.Text {
+	switch v := value.(type) {
+	case nil:
+		return pgtype.Text{}
+	case float64:
+		return pgtype.Text{String: strconv.FormatFloat(v, 'f', -1, 64), Valid: true}
+	case int64:
+		return pgtype.Text{String: strconv.FormatInt(v, 10), Valid: true}
+	case string:
+		return pgtype.Text{String: v, Valid: true}
+	}
+	return pgtype.Text{String: fmt.Sprintf("%v", value), Valid: true}
+}
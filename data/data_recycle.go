@@ -0,0 +1,491 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/handler"
+	"r3/schema"
+	"r3/tools"
+	"r3/types"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// delete recycle bin entries according to recycle settings of their relations
+func DelRecycleBackground() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	now := tools.GetTimeUnix()
+
+	for _, r := range cache.RelationIdMap {
+
+		// delete all entries for relations with disabled recycle bin
+		if !relationUsesRecycle(r.RecycleDays) {
+			if _, err := tx.Exec(ctx, `
+				DELETE FROM instance.data_recycle
+				WHERE relation_id = $1
+			`, r.Id); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.data_recycle
+			WHERE relation_id = $1
+			AND   date_delete < $2
+		`, r.Id, now-(int64(r.RecycleDays.Int32)*86400)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// get recycle bin entries of relation, newest first
+// requires delete access to relation, as entries can be restored or purged
+func RecycleGet_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, loginId int64,
+	limit int, offset int) ([]types.DataRecycle, int, error) {
+
+	entries := make([]types.DataRecycle, 0)
+	total := 0
+
+	if !authorizedRelation(loginId, relationId, types.AccessDelete) {
+		return entries, total, errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return entries, total, handler.ErrSchemaUnknownRelation(relationId)
+	}
+
+	// only show values of readable, non-encrypted attributes
+	attributeIdsByName := make(map[string]uuid.UUID)
+	for _, atr := range rel.Attributes {
+		if atr.Encrypted || schema.IsContentFiles(atr.Content) {
+			continue
+		}
+		if !authorizedAttributes(loginId, []uuid.UUID{atr.Id}, types.AccessRead) {
+			continue
+		}
+		attributeIdsByName[atr.Name] = atr.Id
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT r.id, r.record_id, r.date_delete, l.name, r.record_values
+		FROM instance.data_recycle AS r
+		LEFT JOIN instance.login AS l ON l.id = r.login_id_wofk
+		WHERE r.relation_id = $1
+		ORDER BY r.date_delete DESC, r.record_id DESC
+		LIMIT $2
+		OFFSET $3
+	`, relationId, limit, offset)
+	if err != nil {
+		return entries, total, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e types.DataRecycle
+		var values map[string]any
+
+		if err := rows.Scan(&e.Id, &e.RecordId, &e.DateDelete, &e.LoginName, &values); err != nil {
+			return entries, total, err
+		}
+		e.RelationId = relationId
+		e.Values = make(map[uuid.UUID]any)
+
+		for name, value := range values {
+			if id, exists := attributeIdsByName[name]; exists {
+				e.Values[id] = value
+			}
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	// apply masks as for regular data retrieval
+	applyMasksToRecycle(entries, loginId)
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.data_recycle
+		WHERE relation_id = $1
+	`, relationId).Scan(&total); err != nil {
+		return entries, total, err
+	}
+	return entries, total, nil
+}
+
+// deletes recycle bin entry permanently
+func RecycleDel_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID, loginId int64) error {
+
+	relationId, err := getRecycleRelationId_tx(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if !authorizedRelation(loginId, relationId, types.AccessDelete) {
+		return errors.New(handler.ErrUnauthorized)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM instance.data_recycle
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// restores record from recycle bin with its original ID
+// restores file assignments & references from other records, that were removed on deletion
+// restoring re-creates the record, access, policies, validation & logging are applied as for regular data changes
+// returns ID of restored record
+func RecycleRestore_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID, loginId int64) (int64, error) {
+
+	var relationId uuid.UUID
+	var recordId int64
+	var values json.RawMessage
+	var refs map[uuid.UUID][]int64
+
+	if err := tx.QueryRow(ctx, `
+		SELECT relation_id, record_id, record_values, refs
+		FROM instance.data_recycle
+		WHERE id = $1
+	`, id).Scan(&relationId, &recordId, &values, &refs); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("recycle bin entry '%s' does not exist", id)
+		}
+		return 0, err
+	}
+
+	// restore creates a record that was deleted, both create (WRITE) & DELETE access are required
+	if !authorizedRelation(loginId, relationId, types.AccessWrite) ||
+		!authorizedRelation(loginId, relationId, types.AccessDelete) {
+
+		return 0, errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return 0, handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return 0, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	// get file assignments
+	type recycleFile struct {
+		attributeId uuid.UUID
+		fileId      uuid.UUID
+		name        string
+		dateDelete  pgtype.Int8
+	}
+	files := make([]recycleFile, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT attribute_id, file_id, name, date_delete
+		FROM instance.data_recycle_file
+		WHERE data_recycle_id = $1
+	`, id)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f recycleFile
+		if err := rows.Scan(&f.attributeId, &f.fileId, &f.name, &f.dateDelete); err != nil {
+			return 0, err
+		}
+		files = append(files, f)
+	}
+	rows.Close()
+
+	// collect restored attribute values, used for access checks, validation & data log
+	// numbers are kept as they are, to not lose precision of large integers
+	valuesByName := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(values))
+	decoder.UseNumber()
+	if err := decoder.Decode(&valuesByName); err != nil {
+		return 0, err
+	}
+
+	attributes := make([]types.DataSetAttribute, 0)
+	attributeIds := make([]uuid.UUID, 0)
+	fileAttributeIndexes := make([]int, 0)
+	columnNames := make([]string, 0)
+
+	for _, atr := range rel.Attributes {
+		if atr.Id == rel.AttributeIdPk {
+			columnNames = append(columnNames, fmt.Sprintf(`"%s"`, atr.Name))
+			continue
+		}
+
+		if schema.IsContentFiles(atr.Content) {
+			changes := types.DataSetFileChanges{FileIdMapChange: make(map[uuid.UUID]types.DataSetFileChange)}
+			for _, f := range files {
+				if f.attributeId == atr.Id && !f.dateDelete.Valid {
+					changes.FileIdMapChange[f.fileId] = types.DataSetFileChange{Action: "create", Name: f.name, Version: -1}
+				}
+			}
+			if len(changes.FileIdMapChange) != 0 {
+				fileAttributeIndexes = append(fileAttributeIndexes, len(attributes))
+				attributes = append(attributes, types.DataSetAttribute{AttributeId: atr.Id, Value: changes})
+				attributeIds = append(attributeIds, atr.Id)
+			}
+			continue
+		}
+
+		// attributes that were created since are not inserted, their columns receive their defaults
+		v, exists := valuesByName[atr.Name]
+		if !exists {
+			continue
+		}
+		columnNames = append(columnNames, fmt.Sprintf(`"%s"`, atr.Name))
+
+		if v != nil {
+			attributes = append(attributes, types.DataSetAttribute{AttributeId: atr.Id, Value: v})
+			attributeIds = append(attributeIds, atr.Id)
+		}
+	}
+	if !authorizedAttributes(loginId, attributeIds, types.AccessWrite) {
+		return 0, errors.New(handler.ErrUnauthorized)
+	}
+
+	// only columns of stored values are inserted, values of attributes that were deleted since are ignored
+	columns := strings.Join(columnNames, ", ")
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO "%s"."%s" (%s)
+		SELECT %s FROM JSONB_POPULATE_RECORD(NULL::"%s"."%s", $1)
+	`, mod.Name, rel.Name, columns, columns, mod.Name, rel.Name), values); err != nil {
+		return 0, err
+	}
+
+	// restored record must be accessible for changes by login
+	tableAlias := "t"
+	policyFilter, err := getPolicyFilter(loginId, "update", tableAlias, rel.Policies)
+	if err != nil {
+		return 0, err
+	}
+	if policyFilter != "" {
+		var accessible bool
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT EXISTS(
+				SELECT "%s"
+				FROM "%s"."%s" AS "%s"
+				WHERE "%s"."%s" = $1
+				%s
+			)
+		`, schema.PkName, mod.Name, rel.Name, tableAlias, tableAlias, schema.PkName, policyFilter),
+			recordId).Scan(&accessible); err != nil {
+
+			return 0, err
+		}
+		if !accessible {
+			return 0, errors.New(handler.ErrUnauthorized)
+		}
+	}
+
+	// restore file assignments
+	for _, f := range files {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO instance_file."%s" (file_id, record_id, name, date_delete)
+			VALUES ($1,$2,$3,$4)
+		`, schema.GetFilesTableName(f.attributeId)), f.fileId, recordId, f.name, f.dateDelete); err != nil {
+			return 0, err
+		}
+	}
+
+	// restore references from other records, if they were not reassigned since
+	// references are only restored if login may change them
+	for atrId, recordIds := range refs {
+		atr, exists := cache.AttributeIdMap[atrId]
+		if !exists || !atr.RelationshipId.Valid || atr.RelationshipId.Bytes != relationId {
+			continue
+		}
+		if !authorizedAttributes(loginId, []uuid.UUID{atr.Id}, types.AccessWrite) {
+			continue
+		}
+		relRef, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			continue
+		}
+		modRef, exists := cache.ModuleIdMap[relRef.ModuleId]
+		if !exists {
+			continue
+		}
+		policyFilterRef, err := getPolicyFilter(loginId, "update", tableAlias, relRef.Policies)
+		if err != nil {
+			return 0, err
+		}
+
+		recordIdsRestored := make([]int64, 0)
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			WITH restored AS (
+				UPDATE "%s"."%s" AS "%s"
+				SET "%s" = $1
+				WHERE "%s"."%s" = ANY($2)
+				AND   "%s"."%s" IS NULL
+				%s
+				RETURNING "%s"."%s"
+			)
+			SELECT COALESCE(ARRAY_AGG("%s"), '{}')
+			FROM restored
+		`, modRef.Name, relRef.Name, tableAlias, atr.Name, tableAlias, schema.PkName,
+			tableAlias, atr.Name, policyFilterRef, tableAlias, schema.PkName, schema.PkName),
+			recordId, recordIds).Scan(&recordIdsRestored); err != nil {
+
+			return 0, err
+		}
+		if len(recordIdsRestored) != 0 {
+			attributes = append(attributes, types.DataSetAttribute{
+				AttributeId: atr.Id,
+				OutsideIn:   true,
+				Value:       recordIdsRestored,
+			})
+		}
+	}
+
+	// validate restored record values against current validation rules
+	if err := validateRecord_tx(ctx, tx, rel, recordId, attributes, true); err != nil {
+		return 0, err
+	}
+
+	// log restored record as newly created
+	if relationUsesLogging(rel.RetentionCount, rel.RetentionDays) {
+		if err := setLog_tx(ctx, tx, relationId, attributes, fileAttributeIndexes,
+			true, nil, recordId, loginId); err != nil {
+
+			return 0, fmt.Errorf("failed to set data log, %v", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.data_recycle
+		WHERE id = $1
+	`, id); err != nil {
+		return 0, err
+	}
+
+	if err := SearchIndexSet_tx(ctx, tx, rel, []int64{recordId}); err != nil {
+		return 0, err
+	}
+
+	// inform subscribed clients about restored record
+	return recordId, recordsChanged_tx(ctx, tx, relationId, []int64{recordId}, false, []uuid.UUID{})
+}
+
+// stores record to be deleted in recycle bin, if visible to login
+// keeps file assignments & references from other records that are removed on deletion (SET NULL)
+// expects schema lock to be held
+func recycle_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, mod types.Module,
+	recordId int64, loginId int64, tableAlias string, policyFilter string) error {
+
+	var values json.RawMessage
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT TO_JSONB("%s")
+		FROM "%s"."%s" AS "%s"
+		WHERE "%s"."%s" = $1
+		%s
+	`, tableAlias, mod.Name, rel.Name, tableAlias, tableAlias,
+		schema.PkName, policyFilter), recordId).Scan(&values); err != nil {
+
+		// record does not exist or is not accessible, nothing is deleted
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	// references from other records, that are removed when record is deleted
+	refs := make(map[uuid.UUID][]int64)
+	for _, atr := range cache.AttributeIdMap {
+		if !atr.RelationshipId.Valid || atr.RelationshipId.Bytes != rel.Id || atr.OnDelete != "SET NULL" {
+			continue
+		}
+		relRef, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			continue
+		}
+		modRef, exists := cache.ModuleIdMap[relRef.ModuleId]
+		if !exists {
+			continue
+		}
+
+		var recordIds []int64
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT ARRAY_AGG("%s")
+			FROM "%s"."%s"
+			WHERE "%s" = $1
+		`, schema.PkName, modRef.Name, relRef.Name, atr.Name), recordId).Scan(&recordIds); err != nil {
+			return err
+		}
+		if len(recordIds) != 0 {
+			refs[atr.Id] = recordIds
+		}
+	}
+
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO instance.data_recycle (relation_id, record_id,
+			login_id_wofk, date_delete, record_values, refs)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		RETURNING id
+	`, rel.Id, recordId, loginId, tools.GetTimeUnix(), values, refs).Scan(&id); err != nil {
+		return err
+	}
+
+	// keep file assignments, files are kept as long as they are referenced
+	for _, atr := range rel.Attributes {
+		if !schema.IsContentFiles(atr.Content) {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO instance.data_recycle_file (data_recycle_id,
+				attribute_id, file_id, name, date_delete)
+			SELECT $1, $2, file_id, name, date_delete
+			FROM instance_file."%s"
+			WHERE record_id = $3
+		`, schema.GetFilesTableName(atr.Id)), id, atr.Id, recordId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getRecycleRelationId_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) (uuid.UUID, error) {
+	var relationId uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT relation_id
+		FROM instance.data_recycle
+		WHERE id = $1
+	`, id).Scan(&relationId)
+
+	if err == pgx.ErrNoRows {
+		return relationId, fmt.Errorf("recycle bin entry '%s' does not exist", id)
+	}
+	return relationId, err
+}
@@ -0,0 +1,371 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// global search index
+// values of searchable attributes are stored as text search vectors, one per configured dictionary
+// dictionaries are set per login language, the 'simple' dictionary is always included as fallback
+
+var searchLimitMax = 100
+
+// returns dictionaries to create search vectors for
+func getSearchDictionaries_tx(ctx context.Context, tx pgx.Tx) ([]string, error) {
+	var dicts []string
+	err := tx.QueryRow(ctx, `
+		SELECT ARRAY(
+			SELECT dictionary::TEXT
+			FROM instance.search_dictionary
+			UNION
+			SELECT 'simple'
+		)
+	`).Scan(&dicts)
+	return dicts, err
+}
+
+// returns SQL expression for search content of attribute, empty if attribute cannot be searched
+func getSearchContentExpr(atr types.Attribute, tableAlias string) string {
+	if atr.Encrypted {
+		return ""
+	}
+	if schema.IsContentText(atr.Content) {
+		return fmt.Sprintf(`"%s"."%s"::TEXT`, tableAlias, atr.Name)
+	}
+	if schema.IsContentFiles(atr.Content) {
+		return getFilesTextExpr(atr.Id, fmt.Sprintf(`"%s"."%s"`, tableAlias, schema.PkName))
+	}
+	return ""
+}
+
+// updates search index for records of relation, all records are updated if record IDs are nil
+// expects schema lock to be held
+func SearchIndexSet_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, recordIds []int64) error {
+	if len(rel.AttributeIdsSearch) == 0 {
+		return nil
+	}
+
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	dicts, err := getSearchDictionaries_tx(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, atrId := range rel.AttributeIdsSearch {
+		atr, exists := cache.AttributeIdMap[atrId]
+		if !exists {
+			return handler.ErrSchemaUnknownAttribute(atrId)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.search_index
+			WHERE attribute_id = $1
+			AND ($2::BIGINT[] IS NULL OR record_id = ANY($2))
+		`, atr.Id, recordIds); err != nil {
+			return err
+		}
+
+		expr := getSearchContentExpr(atr, "t")
+		if expr == "" {
+			continue
+		}
+
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO instance.search_index (relation_id, attribute_id, record_id, dictionary, content)
+			SELECT $1, $2, "v"."id", "d"."dict"::REGCONFIG, TO_TSVECTOR("d"."dict"::REGCONFIG, "v"."content")
+			FROM (
+				SELECT "t"."%s" AS "id", %s AS "content"
+				FROM "%s"."%s" AS "t"
+				WHERE ($3::BIGINT[] IS NULL OR "t"."%s" = ANY($3))
+			) AS "v"
+			CROSS JOIN UNNEST($4::TEXT[]) AS "d"("dict")
+			WHERE "v"."content" IS NOT NULL
+			AND   "v"."content" <> ''
+		`, schema.PkName, expr, mod.Name, rel.Name, schema.PkName),
+			rel.Id, atr.Id, recordIds, dicts); err != nil {
+
+			return err
+		}
+	}
+	return nil
+}
+
+// updates search index for records a file is assigned to, used after file text was extracted
+func SearchIndexSetForFile_tx(ctx context.Context, tx pgx.Tx, fileId uuid.UUID) error {
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	for _, rel := range cache.RelationIdMap {
+		recordIds := make([]int64, 0)
+		for _, atrId := range rel.AttributeIdsSearch {
+			atr, exists := cache.AttributeIdMap[atrId]
+			if !exists || !schema.IsContentFiles(atr.Content) {
+				continue
+			}
+
+			var recordIdsAtr []int64
+			if err := tx.QueryRow(ctx, fmt.Sprintf(`
+				SELECT COALESCE(ARRAY_AGG(record_id), '{}')
+				FROM instance_file."%s"
+				WHERE file_id = $1
+			`, schema.GetFilesTableName(atr.Id)), fileId).Scan(&recordIdsAtr); err != nil {
+				return err
+			}
+			for _, id := range recordIdsAtr {
+				if !slices.Contains(recordIds, id) {
+					recordIds = append(recordIds, id)
+				}
+			}
+		}
+		if len(recordIds) != 0 {
+			if err := SearchIndexSet_tx(ctx, tx, rel, recordIds); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removes records of relation from search index
+func SearchIndexDel_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordIds []int64) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.search_index
+		WHERE relation_id = $1
+		AND   record_id   = ANY($2)
+	`, relationId, recordIds)
+	return err
+}
+
+// rebuilds search index for all relations
+// records can also be changed outside of regular data requests (e. g. by PG functions), which are caught up by reindexing
+func SearchReindex() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := SearchReindex_tx(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+func SearchReindex_tx(ctx context.Context, tx pgx.Tx) error {
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	// remove attributes that are not searchable anymore
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.search_index
+		WHERE attribute_id NOT IN (
+			SELECT attribute_id
+			FROM app.relation_search_attribute
+		)
+	`); err != nil {
+		return err
+	}
+
+	for _, rel := range cache.RelationIdMap {
+		if err := SearchIndexSet_tx(ctx, tx, rel, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func SearchDictionariesGet_tx(ctx context.Context, tx pgx.Tx) ([]types.SearchDictionary, error) {
+	dicts := make([]types.SearchDictionary, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT language_code, dictionary::TEXT
+		FROM instance.search_dictionary
+		ORDER BY language_code ASC
+	`)
+	if err != nil {
+		return dicts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d types.SearchDictionary
+		if err := rows.Scan(&d.LanguageCode, &d.Dictionary); err != nil {
+			return dicts, err
+		}
+		dicts = append(dicts, d)
+	}
+	return dicts, nil
+}
+
+// sets dictionaries per login language, search index must be rebuilt to apply changes
+func SearchDictionariesSet_tx(ctx context.Context, tx pgx.Tx, dicts []types.SearchDictionary) error {
+
+	if _, err := tx.Exec(ctx, `DELETE FROM instance.search_dictionary`); err != nil {
+		return err
+	}
+	for _, d := range dicts {
+		if len(d.LanguageCode) != 5 {
+			return fmt.Errorf("invalid language code '%s'", d.LanguageCode)
+		}
+		if !cache.GetSearchDictionaryIsValid(d.Dictionary) {
+			return fmt.Errorf("unknown search dictionary '%s'", d.Dictionary)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.search_dictionary (language_code, dictionary)
+			VALUES ($1,$2::REGCONFIG)
+		`, d.LanguageCode, d.Dictionary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returns records matching search input across all relations, ordered by relevance
+// only records the login can access are returned, matches are only counted for readable & unmasked attributes
+func Search_tx(ctx context.Context, tx pgx.Tx, search types.DataSearch, loginId int64) ([]types.DataSearchResult, error) {
+
+	results := make([]types.DataSearchResult, 0)
+
+	if strings.TrimSpace(search.Input) == "" {
+		return results, nil
+	}
+	if search.Limit <= 0 || search.Limit > searchLimitMax {
+		search.Limit = searchLimitMax
+	}
+
+	// dictionary of login language
+	var dict string
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE((
+			SELECT d.dictionary::TEXT
+			FROM instance.search_dictionary AS d
+			JOIN instance.login_setting     AS s ON s.language_code = d.language_code
+			WHERE s.login_id = $1
+		), 'simple')
+	`, loginId).Scan(&dict); err != nil {
+		return results, err
+	}
+
+	// build search query for all searchable relations
+	queries := make([]string, 0)
+	values := []any{dict, search.Input}
+	relationIdMapFormId := make(map[uuid.UUID]pgtype.UUID)
+
+	cache.Schema_mx.RLock()
+	for _, rel := range cache.RelationIdMap {
+		if len(rel.AttributeIdsSearch) == 0 || !authorizedRelation(loginId, rel.Id, types.AccessRead) {
+			continue
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			cache.Schema_mx.RUnlock()
+			return results, handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+
+		attributeIds := make([]uuid.UUID, 0)
+		for _, atrId := range rel.AttributeIdsSearch {
+			if authorizedAttributes(loginId, []uuid.UUID{atrId}, types.AccessRead) && getAttributeMask(loginId, atrId) == "" {
+				attributeIds = append(attributeIds, atrId)
+			}
+		}
+		if len(attributeIds) == 0 {
+			continue
+		}
+
+		policyFilter, err := getPolicyFilter(loginId, "select", "t", rel.Policies)
+		if err != nil {
+			cache.Schema_mx.RUnlock()
+			return results, err
+		}
+
+		values = append(values, rel.Id, attributeIds)
+		queries = append(queries, fmt.Sprintf(`
+			SELECT $%d::UUID AS "relation_id", "i"."record_id", SUM(TS_RANK("i"."content", "q"."query")) AS "rank"
+			FROM instance.search_index AS "i"
+			CROSS JOIN WEBSEARCH_TO_TSQUERY($1::REGCONFIG, $2) AS "q"("query")
+			JOIN "%s"."%s" AS "t" ON "t"."%s" = "i"."record_id"
+			WHERE "i"."relation_id" = $%d
+			AND   "i"."attribute_id" = ANY($%d)
+			AND   "i"."dictionary"   = $1::REGCONFIG
+			AND   "i"."content" @@ "q"."query"
+			%s
+			GROUP BY "i"."record_id"
+		`, len(values)-1, mod.Name, rel.Name, schema.PkName, len(values)-1, len(values), policyFilter))
+
+		relationIdMapFormId[rel.Id] = rel.FormIdSearch
+	}
+	cache.Schema_mx.RUnlock()
+
+	if len(queries) == 0 {
+		return results, nil
+	}
+
+	values = append(values, search.Limit)
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT "relation_id", "record_id", "rank"
+		FROM (%s) AS "s"
+		ORDER BY "rank" DESC, "record_id" DESC
+		LIMIT $%d
+	`, strings.Join(queries, "UNION ALL"), len(values)), values...)
+	if err != nil {
+		return results, err
+	}
+	for rows.Next() {
+		var r types.DataSearchResult
+		if err := rows.Scan(&r.RelationId, &r.RecordId, &r.Rank); err != nil {
+			rows.Close()
+			return results, err
+		}
+		r.FormId = relationIdMapFormId[r.RelationId]
+		results = append(results, r)
+	}
+	rows.Close()
+
+	// add record titles for relations with readable titles
+	relationIdMapRecordIds := make(map[uuid.UUID][]int64)
+	cache.Schema_mx.RLock()
+	for _, r := range results {
+		rel, exists := cache.RelationIdMap[r.RelationId]
+		if !exists || len(rel.AttributeIdsTitle) == 0 ||
+			!authorizedAttributes(loginId, rel.AttributeIdsTitle, types.AccessRead) ||
+			slices.ContainsFunc(rel.AttributeIdsTitle, func(id uuid.UUID) bool { return getAttributeMask(loginId, id) != "" }) {
+
+			continue
+		}
+		relationIdMapRecordIds[r.RelationId] = append(relationIdMapRecordIds[r.RelationId], r.RecordId)
+	}
+	cache.Schema_mx.RUnlock()
+
+	if len(relationIdMapRecordIds) == 0 {
+		return results, nil
+	}
+	titles, err := GetRecordTitles_tx(ctx, tx, relationIdMapRecordIds, loginId)
+	if err != nil {
+		return results, err
+	}
+	for i, r := range results {
+		if title, exists := titles[r.RelationId][r.RecordId]; exists {
+			results[i].Title.String = title
+			results[i].Title.Valid = true
+		}
+	}
+	return results, nil
+}
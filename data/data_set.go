@@ -42,6 +42,17 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 	}
 	sort.Ints(indexes)
 
+	// reject changes to records that were changed since the requestor retrieved them
+	// checked before any changes are made, as these can update other records of this data SET
+	for _, index := range indexes {
+		dataSet := dataSetsByIndex[index]
+		if dataSet.RecordId != 0 && dataSet.Version.Valid && len(dataSet.Attributes) != 0 {
+			if err := checkRecordVersion_tx(ctx, tx, index, dataSet, loginId); err != nil {
+				return indexRecordIds, err
+			}
+		}
+	}
+
 	// set data for each index in ascending index order, important to resolve relationships
 	for _, index := range indexes {
 
@@ -66,6 +77,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 		for _, attribute := range dataSet.Attributes {
 			attributeIdsWriteAccess = append(attributeIdsWriteAccess, attribute.AttributeId)
 
+			// masked values are unknown to the login, they can only be set for new records
+			if !isNewRecord && getAttributeMask(loginId, attribute.AttributeId) != "" {
+				return indexRecordIds, errors.New(handler.ErrUnauthorized)
+			}
+
 			for _, preset := range rel.Presets {
 				if cache.GetPresetRecordId(preset.Id) != dataSet.RecordId {
 					continue
@@ -123,6 +139,13 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 			return indexRecordIds, err
 		}
 
+		// validate record values against attribute validation rules
+		if isNewRecord || len(dataSet.Attributes) != 0 {
+			if err := validateRecord_tx(ctx, tx, rel, indexRecordIds[index], dataSet.Attributes, isNewRecord); err != nil {
+				return indexRecordIds, err
+			}
+		}
+
 		// set encrypted record keys
 		if rel.Encryption {
 			if err := data_enc.SetKeys_tx(ctx, tx, rel.Id, indexRecordIds[index], dataSet.EncKeysSet); err != nil {
@@ -138,6 +161,18 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 				return indexRecordIds, fmt.Errorf("failed to set data log, %v", err)
 			}
 		}
+
+		// update search index & inform subscribed clients, if record was created or changed
+		if isNewRecord || len(dataSet.Attributes) != 0 {
+			if err := SearchIndexSet_tx(ctx, tx, rel, []int64{indexRecordIds[index]}); err != nil {
+				return indexRecordIds, err
+			}
+			if err := recordsChanged_tx(ctx, tx, dataSet.RelationId, []int64{indexRecordIds[index]},
+				false, attributeIdsWriteAccess); err != nil {
+
+				return indexRecordIds, err
+			}
+		}
 	}
 	return indexRecordIds, nil
 }
@@ -325,6 +360,13 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int, dataSetsByIndex m
 			}
 		}
 
+		// assign numbers from number ranges
+		var err error
+		names, params, values, err = addNumberRanges(mod, rel, names, params, values)
+		if err != nil {
+			return err
+		}
+
 		var newRecordId int64
 		var insertQuery string
 
@@ -0,0 +1,172 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// creates cluster events for changed records, to notify subscribed clients on all nodes
+// events are only visible to nodes after the transaction is committed
+func recordsChanged_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordIds []int64, deleted bool, attributeIds []uuid.UUID) error {
+
+	_, err := tx.Exec(ctx, `
+		SELECT instance.records_changed($1,$2,$3,$4)
+	`, relationId, recordIds, deleted, attributeIds)
+	return err
+}
+
+// returns record change as visible to login, based on relation & attribute access and relation policies
+// returns false if nothing of the change is visible
+// deleted records cannot be checked against policies anymore, relation access is sufficient
+// callers must only pass on deleted records that were visible to the login before
+// expects session config of login to be set, as policy functions are executed in its context
+func GetRecordsChangedAuthorized_tx(ctx context.Context, tx pgx.Tx, loginId int64,
+	change types.ClusterEventRecordsChanged) (types.ClusterEventRecordsChanged, bool, error) {
+
+	if !authorizedRelation(loginId, change.RelationId, types.AccessRead) {
+		return change, false, nil
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	// relation could have been deleted since
+	rel, exists := cache.RelationIdMap[change.RelationId]
+	if !exists {
+		return change, false, nil
+	}
+
+	// only inform about changes to readable attributes
+	if len(change.AttributeIds) != 0 {
+		attributeIds := make([]uuid.UUID, 0)
+		for _, atrId := range change.AttributeIds {
+			if authorizedAttributes(loginId, []uuid.UUID{atrId}, types.AccessRead) {
+				attributeIds = append(attributeIds, atrId)
+			}
+		}
+		if len(attributeIds) == 0 {
+			return change, false, nil
+		}
+		change.AttributeIds = attributeIds
+	}
+
+	if change.Deleted {
+		return change, true, nil
+	}
+
+	tableAlias := "t"
+	policyFilter, err := getPolicyFilter(loginId, "select", tableAlias, rel.Policies)
+	if err != nil || policyFilter == "" {
+		return change, err == nil, err
+	}
+
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return change, false, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	recordIds := make([]int64, 0)
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COALESCE(ARRAY_AGG("%s"."%s"), '{}')
+		FROM "%s"."%s" AS "%s"
+		WHERE "%s"."%s" = ANY($1)
+		%s
+	`, tableAlias, schema.PkName, mod.Name, rel.Name, tableAlias,
+		tableAlias, schema.PkName, policyFilter), change.RecordIds).Scan(&recordIds); err != nil {
+		return change, false, err
+	}
+
+	change.RecordIds = recordIds
+	return change, len(recordIds) != 0, nil
+}
+
+// returns whether changed records are part of the results of a subscribed query
+// the query is executed with its filters for each query relation index of the changed relation
+// expects session config of login to be set, as policy functions are executed in its context
+func GetRecordsChangedFiltered_tx(ctx context.Context, tx pgx.Tx, loginId int64,
+	query types.DataGet, change types.ClusterEventRecordsChanged) (bool, error) {
+
+	// resolve relation indexes of query
+	cache.Schema_mx.RLock()
+	indexRelationIds := map[int]uuid.UUID{0: query.RelationId}
+	for _, j := range query.Joins {
+		atr, exists := cache.AttributeIdMap[j.AttributeId]
+		if !exists {
+			cache.Schema_mx.RUnlock()
+			return false, handler.ErrSchemaUnknownAttribute(j.AttributeId)
+		}
+		if atr.RelationId == indexRelationIds[j.IndexFrom] {
+			indexRelationIds[j.Index] = atr.RelationshipId.Bytes
+		} else {
+			indexRelationIds[j.Index] = atr.RelationId
+		}
+	}
+	rel, exists := cache.RelationIdMap[change.RelationId]
+	cache.Schema_mx.RUnlock()
+
+	if !exists {
+		return false, nil
+	}
+
+	// put brackets around existing filters, as they can be connected with OR
+	filters := slices.Clone(query.Filters)
+	posFirst, posLast := -1, -1
+	for i, f := range filters {
+		if f.Index != 0 {
+			continue
+		}
+		if posFirst == -1 {
+			posFirst = i
+		}
+		posLast = i
+	}
+	if posFirst != -1 {
+		filters[posFirst].Side0.Brackets++
+		filters[posLast].Side1.Brackets++
+	}
+
+	for index, relationId := range indexRelationIds {
+		if relationId != change.RelationId {
+			continue
+		}
+
+		var sqlQuery string
+		results, _, err := Get_tx(ctx, tx, types.DataGet{
+			RelationId:  query.RelationId,
+			IndexSource: 0,
+			Joins:       query.Joins,
+			Expressions: make([]types.DataGetExpression, 0),
+			Filters: append(slices.Clone(filters), types.DataGetFilter{
+				Connector: "AND",
+				Index:     0,
+				Operator:  "= ANY",
+				Side0: types.DataGetFilterSide{
+					AttributeId:    pgtype.UUID{Bytes: rel.AttributeIdPk, Valid: true},
+					AttributeIndex: index,
+				},
+				Side1: types.DataGetFilterSide{
+					Value: change.RecordIds,
+				},
+			}),
+			Limit: 0,
+		}, loginId, &sqlQuery)
+
+		if err != nil {
+			return false, err
+		}
+		if len(results) != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
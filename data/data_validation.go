@@ -0,0 +1,215 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// validates record values against validation rules of relation attributes
+// values are checked after being written, as rules can refer to other values of the same record
+// new records are checked against all rules, existing records only against rules affected by the changed attributes
+func validateRecord_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, recordId int64,
+	attributes []types.DataSetAttribute, isNewRecord bool) error {
+
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	attributeIdsChanged := make([]uuid.UUID, 0)
+	for _, a := range attributes {
+		if !a.OutsideIn {
+			attributeIdsChanged = append(attributeIdsChanged, a.AttributeId)
+		}
+	}
+
+	type ruleCheck struct {
+		atr  types.Attribute
+		rule types.AttributeValidation
+	}
+	checks := make([]ruleCheck, 0)
+	expressions := make([]string, 0)
+	expressionsLock := make([]string, 0)
+	args := []any{recordId}
+
+	for _, atr := range rel.Attributes {
+		for _, rule := range atr.Validations {
+
+			if !isNewRecord && !slices.Contains(attributeIdsChanged, atr.Id) &&
+				!(rule.AttributeIdCompare.Valid && slices.Contains(attributeIdsChanged, uuid.UUID(rule.AttributeIdCompare.Bytes))) &&
+				!slices.ContainsFunc(rule.AttributeIdsScope, func(id uuid.UUID) bool { return slices.Contains(attributeIdsChanged, id) }) {
+
+				continue
+			}
+
+			expr, err := getValidationExpression(mod, rel, atr, rule, &args)
+			if err != nil {
+				return err
+			}
+			checks = append(checks, ruleCheck{atr, rule})
+			expressions = append(expressions, fmt.Sprintf("COALESCE(%s, TRUE)", expr))
+
+			if rule.Content == "unique" {
+				expressionsLock = append(expressionsLock, getValidationLockExpression(atr, rule))
+			}
+		}
+	}
+
+	if len(checks) == 0 {
+		return nil
+	}
+
+	// concurrent transactions could store the same unique values, as they cannot see each other´s changes
+	// lock unique values until the end of the transaction, before they are checked in a separate statement
+	//  checks of other transactions wait for the lock and then see the committed value
+	if len(expressionsLock) != 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			SELECT %s
+			FROM "%s"."%s" AS "t"
+			WHERE "t"."%s" = $1
+		`, strings.Join(expressionsLock, ", "), mod.Name, rel.Name, schema.PkName), recordId); err != nil {
+			return err
+		}
+	}
+
+	results := make([]bool, len(checks))
+	resultPtrs := make([]any, len(checks))
+	for i := range results {
+		resultPtrs[i] = &results[i]
+	}
+
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM "%s"."%s" AS "t"
+		WHERE "t"."%s" = $1
+	`, strings.Join(expressions, ", "), mod.Name, rel.Name, schema.PkName), args...).Scan(resultPtrs...); err != nil {
+
+		// record can be gone if removed by a trigger, nothing to validate
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	for i, valid := range results {
+		if !valid {
+			return getValidationError(checks[i].atr, checks[i].rule)
+		}
+	}
+	return nil
+}
+
+// returns SQL expression that is TRUE if record value matches validation rule
+// record relation must be available with table alias "t"
+func getValidationExpression(mod types.Module, rel types.Relation, atr types.Attribute,
+	rule types.AttributeValidation, args *[]any) (string, error) {
+
+	column := fmt.Sprintf(`"t"."%s"`, atr.Name)
+	isNumber := schema.IsContentNumber(atr.Content)
+
+	var addArg = func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	switch rule.Content {
+	case "regex":
+		return fmt.Sprintf(`%s ~ %s`, column, addArg(rule.Value.String)), nil
+
+	case "min", "max":
+		operator := ">="
+		if rule.Content == "max" {
+			operator = "<="
+		}
+		if isNumber {
+			return fmt.Sprintf(`%s::NUMERIC %s %s::NUMERIC`, column, operator, addArg(rule.Value.String)), nil
+		}
+		return fmt.Sprintf(`CHAR_LENGTH(%s) %s %s::INTEGER`, column, operator, addArg(rule.Value.String)), nil
+
+	case "values":
+		if isNumber {
+			return fmt.Sprintf(`%s::NUMERIC = ANY(%s::NUMERIC[])`, column, addArg(rule.Values)), nil
+		}
+		return fmt.Sprintf(`%s = ANY(%s::TEXT[])`, column, addArg(rule.Values)), nil
+
+	case "compare":
+		atrCompare, exists := cache.AttributeIdMap[rule.AttributeIdCompare.Bytes]
+		if !exists {
+			return "", handler.ErrSchemaUnknownAttribute(rule.AttributeIdCompare.Bytes)
+		}
+
+		// operator is checked when validation rule is stored
+		return fmt.Sprintf(`%s %s "t"."%s"`, column, rule.Operator.String, atrCompare.Name), nil
+
+	case "unique":
+		scopes := make([]string, 0)
+		for _, id := range rule.AttributeIdsScope {
+			atrScope, exists := cache.AttributeIdMap[id]
+			if !exists {
+				return "", handler.ErrSchemaUnknownAttribute(id)
+			}
+			scopes = append(scopes, fmt.Sprintf(`AND "u"."%s" IS NOT DISTINCT FROM "t"."%s"`, atrScope.Name, atrScope.Name))
+		}
+
+		return fmt.Sprintf(`%s IS NULL OR NOT EXISTS (
+			SELECT 1
+			FROM "%s"."%s" AS "u"
+			WHERE "u"."%s" = %s
+			AND   "u"."%s" <> "t"."%s"
+			%s
+		)`, column, mod.Name, rel.Name, atr.Name, column,
+			schema.PkName, schema.PkName, strings.Join(scopes, "\n")), nil
+	}
+	return "", fmt.Errorf("invalid validation rule '%s'", rule.Content)
+}
+
+// returns SQL expression that acquires a transaction lock for the value of a unique validation rule, including its scope
+// record relation must be available with table alias "t"
+func getValidationLockExpression(atr types.Attribute, rule types.AttributeValidation) string {
+	values := []string{fmt.Sprintf(`'%s'`, atr.Id), fmt.Sprintf(`"t"."%s"::TEXT`, atr.Name)}
+	for _, id := range rule.AttributeIdsScope {
+		if atrScope, exists := cache.AttributeIdMap[id]; exists {
+			values = append(values, fmt.Sprintf(`"t"."%s"::TEXT`, atrScope.Name))
+		}
+	}
+	return fmt.Sprintf(`PG_ADVISORY_XACT_LOCK(HASHTEXTEXTENDED(CONCAT_WS('|', %s), 0))`, strings.Join(values, ", "))
+}
+
+// returns error code for failed validation rule, to be translated by requestor
+func getValidationError(atr types.Attribute, rule types.AttributeValidation) error {
+	var number int
+	switch rule.Content {
+	case "regex":
+		number = handler.ErrCodeAppValidationRegex
+	case "min":
+		number = handler.ErrCodeAppValidationMinLength
+		if schema.IsContentNumber(atr.Content) {
+			number = handler.ErrCodeAppValidationMin
+		}
+	case "max":
+		number = handler.ErrCodeAppValidationMaxLength
+		if schema.IsContentNumber(atr.Content) {
+			number = handler.ErrCodeAppValidationMax
+		}
+	case "values":
+		number = handler.ErrCodeAppValidationValues
+	case "compare":
+		number = handler.ErrCodeAppValidationCompare
+	case "unique":
+		number = handler.ErrCodeAppValidationUnique
+	}
+
+	return handler.CreateErrCodeWithData(handler.ErrContextApp, number, struct {
+		AttributeId uuid.UUID `json:"attributeId"`
+		types.AttributeValidation
+	}{atr.Id, rule})
+}
@@ -0,0 +1,104 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// record versions are based on the system column 'xmin' (ID of the transaction that last changed the record)
+// it changes with every update to the record, regardless of its source (data SET, backend functions, imports, ...)
+
+// returns versions of given records, key: record ID
+// records are locked for update if requested, to keep versions valid until the transaction ends
+func getRecordVersions_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordIds []int64, lock bool) (map[int64]int64, error) {
+
+	recordIdMapVersion := make(map[int64]int64)
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return recordIdMapVersion, handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return recordIdMapVersion, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	lockClause := ""
+	if lock {
+		lockClause = "FOR UPDATE"
+	}
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT "%s", xmin::TEXT::BIGINT
+		FROM "%s"."%s"
+		WHERE "%s" = ANY($1)
+		%s
+	`, schema.PkName, mod.Name, rel.Name, schema.PkName, lockClause), recordIds)
+	if err != nil {
+		return recordIdMapVersion, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recordId, version int64
+		if err := rows.Scan(&recordId, &version); err != nil {
+			return recordIdMapVersion, err
+		}
+		recordIdMapVersion[recordId] = version
+	}
+	return recordIdMapVersion, rows.Err()
+}
+
+// rejects data SET if record was changed or deleted since the version known to the requestor
+// conflict error includes current values of attributes to be set, so the requestor can resolve the conflict
+func checkRecordVersion_tx(ctx context.Context, tx pgx.Tx, index int, dataSet types.DataSet, loginId int64) error {
+
+	recordIdMapVersion, err := getRecordVersions_tx(ctx, tx, dataSet.RelationId, []int64{dataSet.RecordId}, true)
+	if err != nil {
+		return err
+	}
+
+	version, exists := recordIdMapVersion[dataSet.RecordId]
+	if exists && version == dataSet.Version.Int64 {
+		return nil
+	}
+
+	conflict := types.DataSetVersionConflict{
+		Deleted:  !exists,
+		Index:    index,
+		RecordId: dataSet.RecordId,
+		Version:  version,
+		Values:   make([]any, 0),
+	}
+
+	if !conflict.Deleted {
+		// file attributes only include changes, their current values are not compared
+		fileAttributeIndexes := make([]int, 0)
+		for i, a := range dataSet.Attributes {
+			atr, exists := cache.AttributeIdMap[a.AttributeId]
+			if !exists {
+				return handler.ErrSchemaUnknownAttribute(a.AttributeId)
+			}
+			if schema.IsContentFiles(atr.Content) {
+				fileAttributeIndexes = append(fileAttributeIndexes, i)
+			}
+		}
+
+		current, err := collectCurrentValuesForLog_tx(ctx, tx, dataSet.RelationId, dataSet.Attributes,
+			fileAttributeIndexes, dataSet.RecordId, loginId)
+
+		if err != nil {
+			return err
+		}
+		conflict.Values = current.Values
+	}
+	return handler.CreateErrCodeWithData(handler.ErrContextApp, handler.ErrCodeAppRecordVersionConflict, conflict)
+}
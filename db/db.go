@@ -50,17 +50,7 @@ func OpenWait(timeoutSeconds int64, config types.FileTypeDb) error {
 
 func Open(config types.FileTypeDb) error {
 
-	sslMode := "disable"
-	if config.Ssl {
-		sslMode = "require"
-	}
-
-	// connect_timeout specifies how long new connections wait for DB to respond
-	// it has no influence on initial DB connection
-	conString := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s&connect_timeout=5",
-		config.User, url.QueryEscape(config.Pass), config.Host, config.Port, config.Name, sslMode)
-
-	poolConfig, err := pgxpool.ParseConfig(conString)
+	poolConfig, err := pgxpool.ParseConfig(getConnString(config))
 	if err != nil {
 		return err
 	}
@@ -99,3 +89,38 @@ func SetSessionConfig_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
 func Close() {
 	Pool.Close()
 }
+
+// opens a single connection, separate from the connection pool
+// used to access other databases on the same server (like scratch databases)
+func OpenConn(ctx context.Context, config types.FileTypeDb) (*pgx.Conn, error) {
+
+	conConfig, err := pgx.ParseConfig(getConnString(config))
+	if err != nil {
+		return nil, err
+	}
+	if config.Ssl {
+		conConfig.TLSConfig = &tls.Config{
+			InsecureSkipVerify: config.SslSkipVerify,
+			ServerName:         config.Host,
+		}
+	}
+
+	con, err := pgx.ConnectConfig(ctx, conConfig)
+	if err != nil {
+		return nil, err
+	}
+	pgxuuid.Register(con.TypeMap())
+	return con, nil
+}
+
+func getConnString(config types.FileTypeDb) string {
+	sslMode := "disable"
+	if config.Ssl {
+		sslMode = "require"
+	}
+
+	// connect_timeout specifies how long new connections wait for DB to respond
+	// it has no influence on initial DB connection
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s&connect_timeout=5",
+		config.User, url.QueryEscape(config.Pass), config.Host, config.Port, config.Name, sslMode)
+}
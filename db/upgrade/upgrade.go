@@ -1069,6 +1069,897 @@ var upgradeFunctions = map[string]func(ctx context.Context, tx pgx.Tx) (string,
 				RETURN FORMAT('{FILE_RAW:%s|%s}', file_id::TEXT, version);
 			END;
 			$BODY$;
+
+			-- SCIM provisioning clients
+			CREATE TABLE IF NOT EXISTS instance.scim_client (
+				id SERIAL NOT NULL,
+				login_template_id INTEGER,
+				name CHARACTER VARYING(64) NOT NULL,
+				token_hash TEXT NOT NULL,
+				CONSTRAINT scim_client_pkey PRIMARY KEY (id),
+				CONSTRAINT scim_client_token_hash_key UNIQUE (token_hash),
+				CONSTRAINT scim_client_login_template_id_fkey FOREIGN KEY (login_template_id)
+					REFERENCES instance.login_template (id) MATCH SIMPLE
+					ON UPDATE SET NULL
+					ON DELETE SET NULL
+			);
+			CREATE INDEX IF NOT EXISTS fki_scim_client_login_template_id_fkey
+				ON instance.scim_client USING btree (login_template_id ASC NULLS LAST);
+
+			ALTER TABLE instance.login ADD COLUMN     scim_client_id   INTEGER;
+			ALTER TABLE instance.login ADD COLUMN     scim_external_id TEXT;
+			ALTER TABLE instance.login ADD CONSTRAINT login_scim_client_id_fkey
+				FOREIGN KEY (scim_client_id)
+				REFERENCES instance.scim_client (id) MATCH SIMPLE
+				ON UPDATE NO ACTION
+				ON DELETE NO ACTION;
+
+			CREATE INDEX IF NOT EXISTS fki_login_scim_client_id_fkey
+				ON instance.login USING btree (scim_client_id ASC NULLS LAST);
+
+			ALTER TABLE instance.login_role_assign ADD COLUMN     scim_client_id INTEGER;
+			ALTER TABLE instance.login_role_assign ADD CONSTRAINT login_role_assign_scim_client_id_fkey
+				FOREIGN KEY (scim_client_id)
+				REFERENCES instance.scim_client (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE;
+
+			CREATE INDEX IF NOT EXISTS fki_login_role_assign_scim_client_id_fkey
+				ON instance.login_role_assign USING btree (scim_client_id ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.scim_group (
+				id UUID NOT NULL DEFAULT gen_random_uuid(),
+				scim_client_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				external_id TEXT,
+				CONSTRAINT scim_group_pkey PRIMARY KEY (id),
+				CONSTRAINT scim_group_scim_client_id_name_key UNIQUE (scim_client_id, name),
+				CONSTRAINT scim_group_scim_client_id_fkey FOREIGN KEY (scim_client_id)
+					REFERENCES instance.scim_client (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS fki_scim_group_scim_client_id_fkey
+				ON instance.scim_group USING btree (scim_client_id ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.scim_group_login (
+				scim_group_id UUID NOT NULL,
+				login_id INTEGER NOT NULL,
+				CONSTRAINT scim_group_login_pkey PRIMARY KEY (scim_group_id, login_id),
+				CONSTRAINT scim_group_login_scim_group_id_fkey FOREIGN KEY (scim_group_id)
+					REFERENCES instance.scim_group (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT scim_group_login_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS fki_scim_group_login_login_id_fkey
+				ON instance.scim_group_login USING btree (login_id ASC NULLS LAST);
+
+			-- SAML 2.0 identity providers
+			CREATE TABLE IF NOT EXISTS instance.saml_idp (
+				id SERIAL NOT NULL,
+				login_template_id INTEGER,
+				name CHARACTER VARYING(64) NOT NULL,
+				entity_id TEXT NOT NULL,
+				sso_url TEXT NOT NULL,
+				certificate TEXT NOT NULL,
+				sp_entity_id TEXT NOT NULL,
+				acs_url TEXT NOT NULL,
+				attribute_admin TEXT,
+				attribute_admin_value TEXT,
+				attribute_roles TEXT,
+				attribute_username TEXT,
+				CONSTRAINT saml_idp_pkey PRIMARY KEY (id),
+				CONSTRAINT saml_idp_login_template_id_fkey FOREIGN KEY (login_template_id)
+					REFERENCES instance.login_template (id) MATCH SIMPLE
+					ON UPDATE SET NULL
+					ON DELETE SET NULL
+			);
+			CREATE INDEX IF NOT EXISTS fki_saml_idp_login_template_id_fkey
+				ON instance.saml_idp USING btree (login_template_id ASC NULLS LAST);
+
+			-- pending SAML authentication requests, valid for a short time only
+			CREATE TABLE IF NOT EXISTS instance.saml_auth (
+				id TEXT NOT NULL,
+				saml_idp_id INTEGER NOT NULL,
+				date_expiry BIGINT NOT NULL,
+				code TEXT,
+				name_id TEXT,
+				attributes JSONB,
+				CONSTRAINT saml_auth_pkey PRIMARY KEY (id),
+				CONSTRAINT saml_auth_code_key UNIQUE (code),
+				CONSTRAINT saml_auth_saml_idp_id_fkey FOREIGN KEY (saml_idp_id)
+					REFERENCES instance.saml_idp (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS fki_saml_auth_saml_idp_id_fkey
+				ON instance.saml_auth USING btree (saml_idp_id ASC NULLS LAST);
+
+			ALTER TABLE instance.login ADD COLUMN     saml_idp_id  INTEGER;
+			ALTER TABLE instance.login ADD COLUMN     saml_name_id TEXT;
+			ALTER TABLE instance.login ADD CONSTRAINT login_saml_idp_id_fkey
+				FOREIGN KEY (saml_idp_id)
+				REFERENCES instance.saml_idp (id) MATCH SIMPLE
+				ON UPDATE NO ACTION
+				ON DELETE NO ACTION;
+
+			CREATE INDEX IF NOT EXISTS fki_login_saml_idp_id_fkey
+				ON instance.login USING btree (saml_idp_id ASC NULLS LAST);
+
+			ALTER TABLE instance.login_meta_map ADD COLUMN     saml_idp_id INTEGER;
+			ALTER TABLE instance.login_meta_map ADD CONSTRAINT login_meta_map_saml_idp_id_fkey
+				FOREIGN KEY (saml_idp_id)
+				REFERENCES instance.saml_idp (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE;
+
+			CREATE INDEX IF NOT EXISTS fki_login_meta_map_saml_idp_id_fkey
+				ON instance.login_meta_map USING btree (saml_idp_id ASC NULLS LAST);
+
+			ALTER TABLE instance.login_role_assign ADD COLUMN     saml_idp_id INTEGER;
+			ALTER TABLE instance.login_role_assign ADD CONSTRAINT login_role_assign_saml_idp_id_fkey
+				FOREIGN KEY (saml_idp_id)
+				REFERENCES instance.saml_idp (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE;
+
+			CREATE INDEX IF NOT EXISTS fki_login_role_assign_saml_idp_id_fkey
+				ON instance.login_role_assign USING btree (saml_idp_id ASC NULLS LAST);
+
+			-- LDAP failover hosts, group filter & delta sync
+			ALTER TABLE instance.ldap ADD COLUMN hosts_failover TEXT[] NOT NULL DEFAULT '{}';
+			ALTER TABLE instance.ldap ALTER COLUMN hosts_failover DROP DEFAULT;
+			ALTER TABLE instance.ldap ADD COLUMN filter_group_dn TEXT;
+			ALTER TABLE instance.ldap ADD COLUMN delta_attribute TEXT;
+			ALTER TABLE instance.ldap ADD COLUMN delta_host TEXT;
+			ALTER TABLE instance.ldap ADD COLUMN delta_value TEXT;
+			ALTER TABLE instance.ldap ADD COLUMN date_full_sync BIGINT;
+
+			-- admin permissions
+			CREATE TYPE instance.login_admin_permission AS ENUM ('builder','logins','logs','mails','system');
+			ALTER TABLE instance.login ADD COLUMN admin_permissions instance.login_admin_permission[] NOT NULL DEFAULT '{}';
+			ALTER TABLE instance.login ALTER COLUMN admin_permissions DROP DEFAULT;
+
+			-- audit log of administrative actions, append-only & hash-chained
+			CREATE TABLE IF NOT EXISTS instance.audit_log (
+				id BIGSERIAL NOT NULL,
+				date_milli BIGINT NOT NULL,
+				login_id INTEGER,
+				login_name TEXT NOT NULL,
+				address TEXT NOT NULL,
+				ressource TEXT NOT NULL,
+				action TEXT NOT NULL,
+				object_id TEXT,
+				payload TEXT NOT NULL,
+				diff TEXT,
+				hash_prev TEXT NOT NULL,
+				hash TEXT NOT NULL,
+				CONSTRAINT audit_log_pkey PRIMARY KEY (id)
+			);
+			CREATE INDEX IF NOT EXISTS ind_audit_log_date_milli_desc
+				ON instance.audit_log USING btree (date_milli DESC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_audit_log_ressource_object_id
+				ON instance.audit_log USING btree (ressource ASC NULLS LAST, object_id ASC NULLS LAST);
+
+			CREATE OR REPLACE FUNCTION instance.audit_log_protect()
+				RETURNS trigger
+				LANGUAGE 'plpgsql'
+			AS $BODY$
+			DECLARE
+			BEGIN
+				RAISE EXCEPTION 'audit log is append-only';
+			END;
+			$BODY$;
+
+			CREATE TRIGGER audit_log_protect
+				BEFORE UPDATE OR DELETE ON instance.audit_log
+				FOR EACH ROW EXECUTE FUNCTION instance.audit_log_protect();
+			CREATE TRIGGER audit_log_protect_truncate
+				BEFORE TRUNCATE ON instance.audit_log
+				FOR EACH STATEMENT EXECUTE FUNCTION instance.audit_log_protect();
+
+			-- recycle bin for deleted records
+			ALTER TABLE app.relation ADD COLUMN recycle_days INTEGER;
+
+			CREATE TABLE IF NOT EXISTS instance.data_recycle (
+				id UUID NOT NULL DEFAULT gen_random_uuid(),
+				relation_id UUID NOT NULL,
+				record_id BIGINT NOT NULL,
+				login_id_wofk INTEGER,
+				date_delete BIGINT NOT NULL,
+				record_values JSONB NOT NULL,
+				refs JSONB NOT NULL,
+				CONSTRAINT data_recycle_pkey PRIMARY KEY (id),
+				CONSTRAINT data_recycle_relation_id_fkey FOREIGN KEY (relation_id)
+					REFERENCES app.relation (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_data_recycle_relation_id_fkey
+				ON instance.data_recycle USING btree (relation_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_data_recycle_date_delete
+				ON instance.data_recycle USING btree (date_delete ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.data_recycle_file (
+				data_recycle_id UUID NOT NULL,
+				attribute_id UUID NOT NULL,
+				file_id UUID NOT NULL,
+				name TEXT NOT NULL,
+				date_delete BIGINT,
+				CONSTRAINT data_recycle_file_pkey PRIMARY KEY (data_recycle_id, attribute_id, file_id),
+				CONSTRAINT data_recycle_file_data_recycle_id_fkey FOREIGN KEY (data_recycle_id)
+					REFERENCES instance.data_recycle (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE,
+				CONSTRAINT data_recycle_file_attribute_id_fkey FOREIGN KEY (attribute_id)
+					REFERENCES app.attribute (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_recycle_file_file_id_fkey FOREIGN KEY (file_id)
+					REFERENCES instance.file (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_data_recycle_file_attribute_id_fkey
+				ON instance.data_recycle_file USING btree (attribute_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_data_recycle_file_file_id_fkey
+				ON instance.data_recycle_file USING btree (file_id ASC NULLS LAST);
+
+			-- files in recycle bin count as references, they must not be cleaned up
+			CREATE TRIGGER data_recycle_file_ref_counter_update
+				BEFORE INSERT OR DELETE ON instance.data_recycle_file
+				FOR EACH ROW EXECUTE FUNCTION instance.trg_file_ref_counter_update();
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupDataRecycle',86400,true,false,true,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupDataRecycle',0,0);
+
+			-- live record subscriptions
+			ALTER TYPE instance_cluster.node_event_content ADD VALUE 'recordsChanged';
+
+			CREATE TABLE IF NOT EXISTS instance_cluster.node_subscription (
+				node_id UUID NOT NULL,
+				relation_id UUID NOT NULL,
+				CONSTRAINT node_subscription_pkey PRIMARY KEY (node_id, relation_id),
+				CONSTRAINT node_subscription_node_id_fkey FOREIGN KEY (node_id)
+					REFERENCES instance_cluster.node (id) MATCH SIMPLE
+					ON UPDATE NO ACTION
+					ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS ind_node_subscription_relation_id
+				ON instance_cluster.node_subscription USING btree (relation_id ASC NULLS LAST);
+
+			CREATE OR REPLACE FUNCTION instance.records_changed(
+				relation_id UUID,
+				record_ids BIGINT[],
+				deleted BOOLEAN DEFAULT FALSE,
+				attribute_ids UUID[] DEFAULT ARRAY[]::UUID[])
+				RETURNS integer
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+			DECLARE
+			BEGIN
+				IF record_ids IS NULL OR CARDINALITY(record_ids) = 0 THEN
+					RETURN 0;
+				END IF;
+
+				-- only nodes with clients subscribed to the relation are informed
+				INSERT INTO instance_cluster.node_event (node_id,content,payload)
+				SELECT
+					s.node_id,
+					'recordsChanged',
+					JSONB_BUILD_OBJECT(
+						'relationId', $1,
+						'recordIds', $2,
+						'attributeIds', COALESCE($4, ARRAY[]::UUID[]),
+						'deleted', COALESCE($3, FALSE),
+						'loginId', COALESCE(instance.get_login_id(), 0)
+					)::TEXT
+				FROM instance_cluster.node_subscription AS s
+				WHERE s.relation_id = $1;
+
+				-- wake up nodes to process events immediately, sent on commit
+				IF FOUND THEN
+					PERFORM PG_NOTIFY('r3_node_event', '');
+				END IF;
+				RETURN 0;
+			END;
+			$BODY$;
+			
+			-- attribute validation rules
+			CREATE TYPE app.attribute_validation_content AS ENUM ('regex','min','max','values','compare','unique');
+			CREATE TABLE app.attribute_validation (
+			    attribute_id uuid NOT NULL,
+				"position" smallint NOT NULL,
+			    content app.attribute_validation_content NOT NULL,
+			    value text,
+			    "values" text[] NOT NULL,
+			    operator character varying(2),
+			    attribute_id_compare uuid,
+			    attribute_ids_scope uuid[] NOT NULL,
+			    CONSTRAINT attribute_validation_pkey PRIMARY KEY (attribute_id,"position"),
+			    CONSTRAINT attribute_validation_attribute_id_fkey FOREIGN KEY (attribute_id)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT attribute_validation_attribute_id_compare_fkey FOREIGN KEY (attribute_id_compare)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_attribute_validation_attribute_id_fkey
+				ON app.attribute_validation USING btree (attribute_id ASC NULLS LAST);
+			CREATE INDEX fki_attribute_validation_attribute_id_compare_fkey
+				ON app.attribute_validation USING btree (attribute_id_compare ASC NULLS LAST);
+			
+			-- attribute masks per role
+			CREATE TYPE app.role_attribute_mask_content AS ENUM ('last4','hash','placeholder','year');
+			CREATE TABLE app.role_attribute_mask (
+			    role_id uuid NOT NULL,
+			    attribute_id uuid NOT NULL,
+			    content app.role_attribute_mask_content NOT NULL,
+			    CONSTRAINT role_attribute_mask_pkey PRIMARY KEY (role_id,attribute_id),
+			    CONSTRAINT role_attribute_mask_role_id_fkey FOREIGN KEY (role_id)
+			        REFERENCES app.role (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT role_attribute_mask_attribute_id_fkey FOREIGN KEY (attribute_id)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_role_attribute_mask_role_id_fkey
+				ON app.role_attribute_mask USING btree (role_id ASC NULLS LAST);
+			CREATE INDEX fki_role_attribute_mask_attribute_id_fkey
+				ON app.role_attribute_mask USING btree (attribute_id ASC NULLS LAST);
+			
+			-- privacy settings for data subject access & erasure
+			CREATE TYPE instance.privacy_strategy AS ENUM ('delete','anonymize','keep');
+			CREATE TABLE instance.privacy_relation (
+			    relation_id uuid NOT NULL,
+			    person boolean NOT NULL,
+			    attribute_id_login uuid,
+			    strategy instance.privacy_strategy NOT NULL,
+			    attribute_ids_anonymize uuid[] NOT NULL,
+			    CONSTRAINT privacy_relation_pkey PRIMARY KEY (relation_id),
+			    CONSTRAINT privacy_relation_relation_id_fkey FOREIGN KEY (relation_id)
+			        REFERENCES app.relation (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT privacy_relation_attribute_id_login_fkey FOREIGN KEY (attribute_id_login)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE SET NULL
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_privacy_relation_attribute_id_login_fkey
+				ON instance.privacy_relation USING btree (attribute_id_login ASC NULLS LAST);
+			CREATE UNIQUE INDEX ind_privacy_relation_person
+				ON instance.privacy_relation USING btree (person) WHERE person;
+			
+			-- number ranges for gap-free document numbers
+			CREATE TYPE app.number_range_reset AS ENUM ('never','year','month','day');
+			CREATE TABLE app.number_range (
+			    id uuid NOT NULL,
+			    module_id uuid NOT NULL,
+			    attribute_id uuid,
+			    attribute_id_scope uuid,
+			    name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+			    comment text COLLATE pg_catalog."default",
+			    prefix text COLLATE pg_catalog."default" NOT NULL,
+			    suffix text COLLATE pg_catalog."default" NOT NULL,
+			    digits smallint NOT NULL,
+			    reset app.number_range_reset NOT NULL,
+			    CONSTRAINT number_range_pkey PRIMARY KEY (id),
+			    CONSTRAINT number_range_name_unique UNIQUE (module_id, name)
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT number_range_attribute_id_unique UNIQUE (attribute_id)
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT number_range_module_id_fkey FOREIGN KEY (module_id)
+			        REFERENCES app.module (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT number_range_attribute_id_fkey FOREIGN KEY (attribute_id)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE SET NULL
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT number_range_attribute_id_scope_fkey FOREIGN KEY (attribute_id_scope)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE SET NULL
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_number_range_module_id_fkey
+				ON app.number_range USING btree (module_id ASC NULLS LAST);
+			CREATE INDEX fki_number_range_attribute_id_scope_fkey
+				ON app.number_range USING btree (attribute_id_scope ASC NULLS LAST);
+			
+			-- last allocated number per number range, period & scope value
+			CREATE TABLE instance.number_range_state (
+			    number_range_id uuid NOT NULL,
+			    period text COLLATE pg_catalog."default" NOT NULL,
+			    scope text COLLATE pg_catalog."default" NOT NULL,
+			    number_last bigint NOT NULL,
+			    CONSTRAINT number_range_state_pkey PRIMARY KEY (number_range_id, period, scope),
+			    CONSTRAINT number_range_state_number_range_id_fkey FOREIGN KEY (number_range_id)
+			        REFERENCES app.number_range (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			
+			-- add new instance function: allocate next number of number range
+			-- numbers are gap-free as the state row stays locked until the transaction ends, rollbacks release the number
+			CREATE OR REPLACE FUNCTION instance.number_range_next(
+				number_range_id_in UUID,
+				scope_in TEXT DEFAULT NULL)
+				RETURNS TEXT
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+			DECLARE
+				_nr     app.number_range%ROWTYPE;
+				_number TEXT;
+				_period TEXT;
+			BEGIN
+				SELECT * INTO _nr
+				FROM app.number_range
+				WHERE id = number_range_id_in;
+				
+				IF NOT FOUND THEN
+					RAISE EXCEPTION 'number range % does not exist', number_range_id_in;
+				END IF;
+				
+				_period := CASE _nr.reset
+					WHEN 'year'  THEN TO_CHAR(NOW(),'YYYY')
+					WHEN 'month' THEN TO_CHAR(NOW(),'YYYY-MM')
+					WHEN 'day'   THEN TO_CHAR(NOW(),'YYYY-MM-DD')
+					ELSE ''
+				END;
+				
+				INSERT INTO instance.number_range_state AS s (number_range_id, period, scope, number_last)
+				VALUES (_nr.id, _period, COALESCE(scope_in,''), 1)
+				ON CONFLICT ON CONSTRAINT number_range_state_pkey
+				DO UPDATE SET number_last = s.number_last + 1
+				RETURNING s.number_last::TEXT INTO _number;
+				
+				IF LENGTH(_number) < _nr.digits THEN
+					_number := LPAD(_number, _nr.digits, '0');
+				END IF;
+				
+				RETURN instance.number_range_format(_nr.prefix) || _number || instance.number_range_format(_nr.suffix);
+			END;
+			$BODY$;
+			
+			CREATE OR REPLACE FUNCTION instance.number_range_format(pattern TEXT)
+				RETURNS TEXT
+				LANGUAGE 'sql'
+				COST 100
+				STABLE PARALLEL SAFE
+			AS $BODY$
+				SELECT REPLACE(REPLACE(REPLACE(REPLACE(pattern,
+					'{YYYY}', TO_CHAR(NOW(),'YYYY')),
+					'{YY}',   TO_CHAR(NOW(),'YY')),
+					'{MM}',   TO_CHAR(NOW(),'MM')),
+					'{DD}',   TO_CHAR(NOW(),'DD'));
+			$BODY$;
+			
+			-- global search
+			CREATE TABLE app.relation_search_attribute (
+			    relation_id uuid NOT NULL,
+			    attribute_id uuid NOT NULL,
+			    CONSTRAINT relation_search_attribute_pkey PRIMARY KEY (relation_id,attribute_id),
+			    CONSTRAINT relation_search_attribute_relation_id_fkey FOREIGN KEY (relation_id)
+			        REFERENCES app.relation (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT relation_search_attribute_attribute_id_fkey FOREIGN KEY (attribute_id)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_relation_search_attribute_relation_id_fkey
+				ON app.relation_search_attribute USING btree (relation_id ASC NULLS LAST);
+			CREATE INDEX fki_relation_search_attribute_attribute_id_fkey
+				ON app.relation_search_attribute USING btree (attribute_id ASC NULLS LAST);
+			
+			ALTER TABLE app.relation ADD COLUMN form_id_search uuid;
+			ALTER TABLE app.relation ADD CONSTRAINT relation_form_id_search_fkey FOREIGN KEY (form_id_search)
+				REFERENCES app.form (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE SET NULL
+				DEFERRABLE INITIALLY DEFERRED;
+			CREATE INDEX fki_relation_form_id_search_fkey
+				ON app.relation USING btree (form_id_search ASC NULLS LAST);
+			
+			CREATE TABLE instance.search_dictionary (
+			    language_code character(5) COLLATE pg_catalog."default" NOT NULL,
+			    dictionary regconfig NOT NULL,
+			    CONSTRAINT search_dictionary_pkey PRIMARY KEY (language_code)
+			);
+			
+			CREATE TABLE instance.search_index (
+			    relation_id uuid NOT NULL,
+			    attribute_id uuid NOT NULL,
+			    record_id bigint NOT NULL,
+			    dictionary regconfig NOT NULL,
+			    content tsvector NOT NULL,
+			    CONSTRAINT search_index_pkey PRIMARY KEY (attribute_id,record_id,dictionary),
+			    CONSTRAINT search_index_relation_id_fkey FOREIGN KEY (relation_id)
+			        REFERENCES app.relation (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED,
+			    CONSTRAINT search_index_attribute_id_fkey FOREIGN KEY (attribute_id)
+			        REFERENCES app.attribute (id) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX ind_search_index_relation_record
+				ON instance.search_index USING btree (relation_id ASC NULLS LAST, record_id ASC NULLS LAST);
+			CREATE INDEX ind_search_index_content
+				ON instance.search_index USING gin (content);
+			
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('searchReindex',86400,true,false,true,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('searchReindex',0,0);
+			
+			-- file text extraction
+			CREATE TABLE instance.file_text (
+			    file_id uuid NOT NULL,
+			    version integer NOT NULL,
+			    name text NOT NULL,
+			    content text,
+			    date_extract bigint,
+			    CONSTRAINT file_text_pkey PRIMARY KEY (file_id,version),
+			    CONSTRAINT file_text_file_version_fkey FOREIGN KEY (file_id,version)
+			        REFERENCES instance.file_version (file_id,version) MATCH SIMPLE
+			        ON UPDATE CASCADE
+			        ON DELETE CASCADE
+			        DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX ind_file_text_date_extract
+				ON instance.file_text USING btree (date_extract ASC NULLS FIRST);
+			
+			-- extract text from latest versions of existing files
+			INSERT INTO instance.file_text (file_id,version,name)
+				SELECT DISTINCT ON (file_id) file_id, version, ''
+				FROM instance.file_version
+				ORDER BY file_id, version DESC;
+			
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('filesTextExtract',60,true,false,true,true);
+			
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('filesTextExtract',0,0);
+			
+			-- PG function schedules with cron expressions, time zones & exclusion calendars
+			ALTER TYPE app.pg_function_schedule_interval ADD VALUE 'cron';
+			ALTER TABLE app.pg_function_schedule ADD COLUMN cron_expr character varying(128) COLLATE pg_catalog."default";
+			ALTER TABLE app.pg_function_schedule ADD COLUMN time_zone character varying(64) COLLATE pg_catalog."default";
+			ALTER TABLE app.pg_function_schedule ADD COLUMN attribute_id_exclude uuid;
+			ALTER TABLE app.pg_function_schedule ADD CONSTRAINT pg_function_schedule_attribute_id_exclude_fkey FOREIGN KEY (attribute_id_exclude)
+				REFERENCES app.attribute (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE SET NULL
+				DEFERRABLE INITIALLY DEFERRED;
+			CREATE INDEX fki_pg_function_schedule_attribute_id_exclude_fkey
+				ON app.pg_function_schedule USING btree (attribute_id_exclude ASC NULLS LAST);
+			
+			-- scheduler run history
+			CREATE TABLE instance.schedule_run (
+				id BIGSERIAL NOT NULL,
+				schedule_id integer NOT NULL,
+				node_id uuid NOT NULL,
+				date_milli_start bigint NOT NULL,
+				date_milli_end bigint,
+				success boolean,
+				error_message text COLLATE pg_catalog."default",
+				CONSTRAINT schedule_run_pkey PRIMARY KEY (id),
+				CONSTRAINT schedule_run_schedule_id_fkey FOREIGN KEY (schedule_id)
+					REFERENCES instance.schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_schedule_run_schedule_id_fkey
+				ON instance.schedule_run USING btree (schedule_id ASC NULLS LAST);
+			CREATE INDEX ind_schedule_run_date_milli_start
+				ON instance.schedule_run USING btree (date_milli_start DESC NULLS LAST);
+			
+			INSERT INTO instance.config (name,value) VALUES ('scheduleRunsKeepDays','90');
+			
+			-- scheduler alerts, sent via admin notification mails
+			CREATE TYPE instance.schedule_alert_content AS ENUM ('failures','missed','runtime');
+			CREATE TABLE instance.schedule_alert (
+				id SERIAL NOT NULL,
+				schedule_id integer NOT NULL,
+				content instance.schedule_alert_content NOT NULL,
+				threshold integer NOT NULL,
+				date_last_sent bigint NOT NULL,
+				CONSTRAINT schedule_alert_pkey PRIMARY KEY (id),
+				CONSTRAINT schedule_alert_schedule_id_content_key UNIQUE (schedule_id, content),
+				CONSTRAINT schedule_alert_schedule_id_fkey FOREIGN KEY (schedule_id)
+					REFERENCES instance.schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			-- background job queue, jobs are added from backend functions
+			CREATE TABLE instance.job_queue (
+				name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				concurrency integer NOT NULL DEFAULT 1,
+				CONSTRAINT job_queue_pkey PRIMARY KEY (name)
+			);
+			
+			CREATE TYPE instance.job_state AS ENUM ('waiting','running','done','failed');
+			CREATE TABLE instance.job (
+				id BIGSERIAL NOT NULL,
+				pg_function_id uuid NOT NULL,
+				queue character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				args jsonb,
+				priority integer NOT NULL,
+				state instance.job_state NOT NULL,
+				unique_key text COLLATE pg_catalog."default",
+				attempts integer NOT NULL,
+				attempts_max integer NOT NULL,
+				node_id uuid,
+				date_added bigint NOT NULL,
+				date_run_after bigint NOT NULL,
+				date_milli_start bigint,
+				date_milli_end bigint,
+				result text COLLATE pg_catalog."default",
+				error_message text COLLATE pg_catalog."default",
+				CONSTRAINT job_pkey PRIMARY KEY (id),
+				CONSTRAINT job_pg_function_id_fkey FOREIGN KEY (pg_function_id)
+					REFERENCES app.pg_function (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT job_queue_fkey FOREIGN KEY (queue)
+					REFERENCES instance.job_queue (name) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_job_pg_function_id_fkey
+				ON instance.job USING btree (pg_function_id ASC NULLS LAST);
+			CREATE INDEX fki_job_queue_fkey
+				ON instance.job USING btree (queue ASC NULLS LAST);
+			CREATE INDEX ind_job_waiting ON instance.job USING btree
+				(queue ASC NULLS LAST, priority DESC NULLS LAST, date_run_after ASC NULLS LAST)
+				WHERE state = 'waiting';
+			CREATE INDEX ind_job_date_milli_end
+				ON instance.job USING btree (date_milli_end ASC NULLS LAST);
+			
+			-- only one open job per function & unique key
+			CREATE UNIQUE INDEX ind_job_unique_key ON instance.job USING btree
+				(pg_function_id ASC NULLS LAST, unique_key ASC NULLS LAST)
+				WHERE unique_key IS NOT NULL AND state IN ('waiting','running');
+			
+			INSERT INTO instance.config (name,value) VALUES ('jobsKeepDays','7');
+			
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('jobsExecute',5,false,false,true,true);
+			
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('jobsExecute',0,0);
+			
+			-- add new instance function: add job to background job queue
+			-- returns ID of new job or of existing open job with the same unique key
+			CREATE OR REPLACE FUNCTION instance.job_add(
+				pg_function_id_in UUID,
+				args_in JSONB DEFAULT NULL,
+				queue_in TEXT DEFAULT 'default',
+				priority_in INTEGER DEFAULT 0,
+				run_after_in BIGINT DEFAULT NULL,
+				attempts_max_in INTEGER DEFAULT 3,
+				unique_key_in TEXT DEFAULT NULL)
+				RETURNS BIGINT
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+			DECLARE
+				_id BIGINT;
+			BEGIN
+				IF NOT EXISTS (
+					SELECT id
+					FROM app.pg_function
+					WHERE id = pg_function_id_in
+					AND   is_trigger = FALSE
+				) THEN
+					RAISE EXCEPTION 'backend function % does not exist or is a trigger function', pg_function_id_in;
+				END IF;
+			
+				IF attempts_max_in < 1 THEN
+					RAISE EXCEPTION 'max. attempts must be at least 1';
+				END IF;
+			
+				INSERT INTO instance.job_queue (name)
+				VALUES (queue_in)
+				ON CONFLICT DO NOTHING;
+			
+				INSERT INTO instance.job (pg_function_id, queue, args, priority, state, unique_key,
+					attempts, attempts_max, date_added, date_run_after)
+				VALUES (pg_function_id_in, queue_in, args_in, priority_in, 'waiting', unique_key_in,
+					0, attempts_max_in, EXTRACT(EPOCH FROM NOW()),
+					COALESCE(run_after_in, EXTRACT(EPOCH FROM NOW())))
+				ON CONFLICT (pg_function_id, unique_key)
+					WHERE unique_key IS NOT NULL AND state IN ('waiting','running')
+					DO NOTHING
+				RETURNING id INTO _id;
+			
+				IF _id IS NULL THEN
+					SELECT id INTO _id
+					FROM instance.job
+					WHERE pg_function_id = pg_function_id_in
+					AND   unique_key     = unique_key_in
+					AND   state IN ('waiting','running');
+				END IF;
+			
+				RETURN _id;
+			END;
+			$BODY$;
+			
+			-- archived versions of installed modules, stored before imports replace them
+			CREATE TABLE instance.module_archive (
+				id BIGSERIAL NOT NULL,
+				module_id uuid NOT NULL,
+				release_build integer NOT NULL,
+				release_build_app integer NOT NULL,
+				release_build_replaced_by integer,
+				hash character(44) NOT NULL,
+				file_name text COLLATE pg_catalog."default" NOT NULL,
+				date_created bigint NOT NULL,
+				CONSTRAINT module_archive_pkey PRIMARY KEY (id)
+			);
+			CREATE INDEX ind_module_archive_module_id
+				ON instance.module_archive USING btree (module_id ASC NULLS LAST, date_created DESC NULLS LAST);
+			
+			INSERT INTO instance.config (name,value) VALUES ('moduleArchiveKeepCount','5');
+			
+			-- data migration functions of module releases, executed once when upgrading across their build
+			CREATE TABLE IF NOT EXISTS app.release_migration (
+				module_id uuid NOT NULL,
+				build integer NOT NULL,
+				"position" smallint NOT NULL,
+				pg_function_id uuid NOT NULL,
+				CONSTRAINT release_migration_pkey PRIMARY KEY (module_id, build, position),
+				CONSTRAINT release_migration_release_fkey FOREIGN KEY (module_id, build)
+					REFERENCES app.release (module_id, build) MATCH FULL
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT release_migration_pg_function_id_fkey FOREIGN KEY (pg_function_id)
+					REFERENCES app.pg_function (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_release_migration_release_fkey        ON app.release_migration USING btree (module_id, build ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_release_migration_pg_function_id_fkey ON app.release_migration USING btree (pg_function_id   ASC NULLS LAST);
+			
+			-- executed data migrations of this instance
+			CREATE TABLE instance.module_migration (
+				module_id uuid NOT NULL,
+				build integer NOT NULL,
+				pg_function_id uuid NOT NULL,
+				release_build_from integer NOT NULL,
+				release_build_to integer NOT NULL,
+				date_executed bigint NOT NULL,
+				CONSTRAINT module_migration_pkey PRIMARY KEY (module_id, build, pg_function_id),
+				CONSTRAINT module_migration_module_id_fkey FOREIGN KEY (module_id)
+					REFERENCES app.module (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_module_migration_module_id_fkey ON instance.module_migration USING btree (module_id ASC NULLS LAST);
+			
+			-- instance serving as module repository for other instances
+			CREATE TABLE instance.repo_serve_module (
+				module_id uuid NOT NULL,
+				author text COLLATE pg_catalog."default" NOT NULL,
+				description text COLLATE pg_catalog."default" NOT NULL,
+				support_page text COLLATE pg_catalog."default" NOT NULL,
+				in_store boolean NOT NULL,
+				CONSTRAINT repo_serve_module_pkey PRIMARY KEY (module_id),
+				CONSTRAINT repo_serve_module_module_id_fkey FOREIGN KEY (module_id)
+					REFERENCES app.module (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE TABLE instance.repo_serve_login (
+				login_id integer NOT NULL,
+				CONSTRAINT repo_serve_login_pkey PRIMARY KEY (login_id),
+				CONSTRAINT repo_serve_login_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			
+			INSERT INTO instance.config (name,value) VALUES ('repoServeActive','0');
+			
+			-- backup restore verification
+			INSERT INTO instance.config (name,value) VALUES ('backupVerify','0');
+			
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('backupVerify',86400,true,false,false,true);
+			
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('backupVerify',0,0);
+			
+			-- backup encryption, custom backup jobs & remote backup targets
+			INSERT INTO instance.config (name,value) VALUES ('backupEncryptKey','');
+			
+			CREATE TYPE instance.backup_scope AS ENUM ('database','files','full');
+			CREATE TYPE instance.backup_target_content AS ENUM ('local','s3','sftp');
+			
+			CREATE TABLE instance.backup_job (
+				id SERIAL NOT NULL,
+				name character varying(32) COLLATE pg_catalog."default" NOT NULL,
+				scope instance.backup_scope NOT NULL,
+				interval_hours integer NOT NULL,
+				keep_count integer NOT NULL,
+				active boolean NOT NULL,
+				CONSTRAINT backup_job_pkey PRIMARY KEY (id),
+				CONSTRAINT backup_job_name_key UNIQUE (name)
+			);
+			CREATE TABLE instance.backup_target (
+				id SERIAL NOT NULL,
+				name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				content instance.backup_target_content NOT NULL,
+				active boolean NOT NULL,
+				host_name text COLLATE pg_catalog."default" NOT NULL,
+				host_port integer NOT NULL,
+				host_key text COLLATE pg_catalog."default" NOT NULL,
+				path text COLLATE pg_catalog."default" NOT NULL,
+				bucket text COLLATE pg_catalog."default" NOT NULL,
+				region text COLLATE pg_catalog."default" NOT NULL,
+				username text COLLATE pg_catalog."default" NOT NULL,
+				password text COLLATE pg_catalog."default" NOT NULL,
+				private_key text COLLATE pg_catalog."default" NOT NULL,
+				keep_count integer NOT NULL,
+				CONSTRAINT backup_target_pkey PRIMARY KEY (id)
+			);
 		`)
 		return "3.12", err
 	},
@@ -12,6 +12,7 @@ import (
 	"r3/handler"
 	"r3/log"
 	"r3/login/login_auth"
+	"r3/repo"
 	"regexp"
 	"slices"
 	"strconv"
@@ -20,6 +21,7 @@ import (
 )
 
 type getter struct {
+	asOf    int64 // unix time to reconstruct values for from change logs, current values if 0
 	limit   int
 	offset  int
 	verbose bool
@@ -28,7 +30,7 @@ type getter struct {
 }
 
 var (
-	defaultGetters  = []string{"limit", "offset", "verbose"}
+	defaultGetters  = []string{"as_of", "limit", "offset", "verbose"}
 	rxRelationIndex = regexp.MustCompile(`\(.+\)`)
 )
 
@@ -81,6 +83,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		Parse URL, such as:
 		GET    /api/lsw_invoices/contracts/v1?limit=10
 		GET    /api/lsw_invoices/contracts/v1/45
+		GET    /api/lsw_invoices/contracts/v1/45?as_of=1735689600
 		DELETE /api/lsw_invoices/contracts/v1/45
 
 		Rules:
@@ -123,6 +126,19 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	// URL processing complete, actually use API
 	log.Info(log.ContextApi, fmt.Sprintf("'%s.%s' (v%d) is called with %s (record ID: %d)", modName, apiName, version, r.Method, recordId))
 
+	// instance serves as module repository, repository API is emulated
+	if modName == repo.ServeModuleName && repo.ServeIsActive() {
+		if !isGet {
+			abort(http.StatusBadRequest, nil, fmt.Sprintf("HTTP method '%s' is not supported by this API", r.Method))
+			return
+		}
+		httpStatus, errToLog, err := handleRepoServe(ctx, w, r, login.Id, apiName, version)
+		if err != nil {
+			abort(httpStatus, errToLog, err.Error())
+		}
+		return
+	}
+
 	// resolve API by module+API names
 	api, err := cache.GetApiByNames(modName, apiName, version)
 	if err != nil {
@@ -171,6 +187,8 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			switch getter {
+			case "as_of":
+				getters.asOf = int64(n)
 			case "limit":
 				getters.limit = n
 			case "offset":
@@ -10,6 +10,7 @@ import (
 	"r3/data/data_query"
 	"r3/handler"
 	"r3/types"
+	"slices"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -78,6 +79,25 @@ func handleGet_tx(ctx context.Context, tx pgx.Tx, w http.ResponseWriter, api typ
 	// apply query sorting
 	dataGet.Orders = data_query.ConvertQueryToDataOrders(api.Query.Orders)
 
+	// reconstruct values from change logs, abort if not possible for all columns
+	// partial results would be mistaken for the state at the given time
+	if getters.asOf != 0 {
+		dataGet.AsOf = pgtype.Int8{Int64: getters.asOf, Valid: true}
+
+		if positions := data.GetAsOfExpressionsInvalid(dataGet); len(positions) != 0 {
+			names := make([]string, 0)
+			for _, pos := range positions {
+				if atr, exists := cache.AttributeIdMap[api.Columns[pos].AttributeId]; exists {
+					names = append(names, atr.Name)
+				}
+			}
+			return http.StatusBadRequest, nil, fmt.Errorf("values of columns cannot be reconstructed from change logs: %s", strings.Join(names, ", "))
+		}
+	}
+
+	// get current version of single record, to be used with If-Match for POST
+	dataGet.GetVersion = recordId != 0 && getters.asOf == 0
+
 	// get data
 	var query string
 	results, _, err := data.Get_tx(ctx, tx, dataGet, loginId, &query)
@@ -88,6 +108,21 @@ func handleGet_tx(ctx context.Context, tx pgx.Tx, w http.ResponseWriter, api typ
 		return http.StatusServiceUnavailable, nil, err
 	}
 
+	// change logs of single records might have been removed by retention
+	if getters.asOf != 0 {
+		names := make([]string, 0)
+		for _, result := range results {
+			for _, pos := range result.ValuesNoAsOf {
+				if atr, exists := cache.AttributeIdMap[api.Columns[pos].AttributeId]; exists && !slices.Contains(names, atr.Name) {
+					names = append(names, atr.Name)
+				}
+			}
+		}
+		if len(names) != 0 {
+			return http.StatusBadRequest, nil, fmt.Errorf("values of columns cannot be reconstructed, change logs were removed by retention: %s", strings.Join(names, ", "))
+		}
+	}
+
 	// parse output
 	rows := make([]any, 0)
 	if !getters.verbose {
@@ -146,6 +181,11 @@ func handleGet_tx(ctx context.Context, tx pgx.Tx, w http.ResponseWriter, api typ
 	if err != nil {
 		return http.StatusServiceUnavailable, err, fmt.Errorf(handler.ErrGeneral)
 	}
+	if dataGet.GetVersion && len(results) == 1 {
+		if version, exists := results[0].IndexRecordVersions[0]; exists {
+			w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+		}
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Write(payloadJson)
 
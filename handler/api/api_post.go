@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func handlePost_tx(ctx context.Context, tx pgx.Tx, w http.ResponseWriter, r *http.Request, api types.Api, loginId int64, languageCode string, getters getter) (int, error, error) {
@@ -75,10 +76,24 @@ func handlePost_tx(ctx context.Context, tx pgx.Tx, w http.ResponseWriter, r *htt
 		}
 	}
 
+	// optional version of base record, as retrieved via ETag from GET
+	// data is not saved if the record was changed since
+	var version pgtype.Int8
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		v, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`), 10, 64)
+		if err != nil {
+			return http.StatusBadRequest, err, fmt.Errorf("invalid If-Match header '%s', record version expected", ifMatch)
+		}
+		version = pgtype.Int8{Int64: v, Valid: true}
+	}
+
 	indexRecordIds, err := data_import.FromInterfaceValues_tx(ctx, tx, loginId, values, api.Columns,
-		api.Query.Joins, api.Query.Lookups, data_import.ResolveQueryLookups(api.Query.Joins, api.Query.Lookups))
+		api.Query.Joins, api.Query.Lookups, data_import.ResolveQueryLookups(api.Query.Joins, api.Query.Lookups), version)
 
 	if err != nil {
+		if handler.CheckForRecordVersionErrCode(err) {
+			return http.StatusPreconditionFailed, nil, err
+		}
 		return http.StatusConflict, nil, err
 	}
 
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"r3/handler"
+	"r3/repo"
+	"strconv"
+)
+
+// serves repository API calls from other instances, that use this instance as module repository
+func handleRepoServe(ctx context.Context, w http.ResponseWriter, r *http.Request, loginId int64,
+	apiName string, version int) (int, error, error) {
+
+	limit, offset := 0, 0
+	for getter, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		value := r.URL.Query().Get(getter)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return http.StatusBadRequest, err, fmt.Errorf("invalid value '%s' for %s", value, getter)
+		}
+		*target = n
+	}
+
+	res, err := repo.ServeApi(ctx, loginId, apiName, version, limit, offset)
+	if err != nil {
+		if errors.Is(err, repo.ErrServeUnauthorized) {
+			return http.StatusForbidden, err, fmt.Errorf(handler.ErrUnauthorized)
+		}
+		return http.StatusBadRequest, nil, err
+	}
+
+	payloadJson, err := json.Marshal(res)
+	if err != nil {
+		return http.StatusServiceUnavailable, err, fmt.Errorf(handler.ErrGeneral)
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(payloadJson)
+	return http.StatusOK, nil, nil
+}
@@ -0,0 +1,71 @@
+package audit_export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"r3/audit"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/login/login_auth"
+	"r3/request"
+	"r3/tools"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var genErr = "could not finish audit log export"
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	// get authentication token
+	token, err := handler.ReadGetterFromUrl(r, "token")
+	if err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutTransfer)
+	defer ctxCanc()
+
+	// authenticate via token
+	login, err := login_auth.Token(ctx, token)
+	if err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+
+	if err := request.CheckAdminAccess(login.Id, login.Admin, "audit", "export"); err != nil {
+		log.Error(log.ContextServer, genErr, errors.New(handler.ErrUnauthorized))
+		return
+	}
+
+	// optional date range (unix seconds)
+	var dateFrom, dateTo pgtype.Int8
+	if _, exists := r.URL.Query()["date_from"]; exists {
+		dateFrom.Int64, err = handler.ReadInt64GetterFromUrl(r, "date_from")
+		if err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		dateFrom.Valid = true
+	}
+	if _, exists := r.URL.Query()["date_to"]; exists {
+		dateTo.Int64, err = handler.ReadInt64GetterFromUrl(r, "date_to")
+		if err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		dateTo.Valid = true
+	}
+
+	w.Header().Set("Content-Type", "application/jsonl")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="audit_%d.jsonl"`, tools.GetTimeUnix()))
+
+	if err := audit.Export(ctx, w, dateFrom, dateTo); err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+}
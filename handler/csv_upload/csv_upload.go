@@ -26,6 +26,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func Handler(w http.ResponseWriter, r *http.Request) {
@@ -343,6 +344,7 @@ func importLine_tx(ctx context.Context, tx pgx.Tx, loginId int64, opt types.CsvO
 		}
 	}
 
-	_, err = data_import.FromInterfaceValues_tx(ctx, tx, loginId, valuesIn, columns, joins, lookups, indexMapPgIndexAttributeIds)
+	_, err = data_import.FromInterfaceValues_tx(ctx, tx, loginId, valuesIn, columns, joins, lookups,
+		indexMapPgIndexAttributeIds, pgtype.Int8{})
 	return err
 }
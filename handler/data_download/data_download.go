@@ -2,8 +2,10 @@ package data_download
 
 import (
 	"context"
+	"errors"
 	"mime"
 	"net/http"
+	"os"
 	"path"
 	"path/filepath"
 	"r3/bruteforce"
@@ -11,6 +13,7 @@ import (
 	"r3/data"
 	"r3/handler"
 	"r3/login/login_auth"
+	"r3/repo"
 	"time"
 )
 
@@ -53,6 +56,24 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// instance serves as module repository, module files are packaged from original transfer files
+	if repo.ServeIsFileAttribute(attributeId) && repo.ServeIsActive() {
+		filePath, err := repo.ServeFile(ctx, login.Id, fileId)
+		if err != nil {
+			if errors.Is(err, repo.ErrServeUnauthorized) {
+				handler.AbortRequest(w, handler.ContextDataDownload, err, handler.ErrUnauthorized)
+				return
+			}
+			handler.AbortRequest(w, handler.ContextDataDownload, err, handler.ErrGeneral)
+			return
+		}
+		defer os.Remove(filePath)
+
+		w.Header().Set("Content-Type", "application/zip")
+		http.ServeFile(w, r, filePath)
+		return
+	}
+
 	// check file access privilege
 	if err := data.MayAccessFile(login.Id, attributeId); err != nil {
 		handler.AbortRequest(w, handler.ContextDataDownload, err, handler.ErrUnauthorized)
@@ -13,6 +13,8 @@ import (
 	"r3/login/login_auth"
 	"r3/spooler/doc_create"
 	"r3/tools"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 var genErr = "could not finish document preview download"
@@ -50,6 +52,17 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// optional, reconstruct document data as of date from change logs
+	var asOf pgtype.Int8
+	if _, exists := r.URL.Query()["as_of"]; exists {
+		asOf.Int64, err = handler.ReadInt64GetterFromUrl(r, "as_of")
+		if err != nil {
+			handler.ServeErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+		asOf.Valid = true
+	}
+
 	filePath, err := tools.GetUniqueFilePath(config.File.Paths.Temp, 8999999, 9999999)
 	if err != nil {
 		handler.ServeErrorPage(w, http.StatusInternalServerError, errors.New(handler.ErrGeneral))
@@ -57,7 +70,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	filename, err := doc_create.Run(ctx, docId, login.Id, recordId, filePath)
+	filename, err := doc_create.Run(ctx, docId, login.Id, recordId, asOf, filePath)
 	if err != nil {
 		handler.ServeErrorPage(w, http.StatusInternalServerError, err)
 		return
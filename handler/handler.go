@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"r3/log"
 	"strconv"
@@ -31,6 +32,7 @@ const (
 	ContextLicenseUpload     handlerContext = 140
 	ContextManifestDownload  handlerContext = 150
 	ContextWebsocket         handlerContext = 160
+	ContextScim              handlerContext = 170
 
 	errHtml = `<!DOCTYPE html>
 <html style="height:100vh;font-family:'Roboto','Arial','Helvetica',sans-serif;font-size:20px;">
@@ -69,6 +71,7 @@ var (
 		ContextLicenseUpload:     "license_upload",
 		ContextManifestDownload:  "manifest_download",
 		ContextWebsocket:         "websocket",
+		ContextScim:              "scim",
 	}
 	NoImage []byte
 )
@@ -111,6 +114,13 @@ func ReadGetterFromUrlOptional(r *http.Request, name string) (string, error) {
 	}
 	return keys[0], nil
 }
+func GetRemoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
 func SetNoImage(v []byte) {
 	NoImage = v
 }
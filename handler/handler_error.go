@@ -45,6 +45,15 @@ const (
 	ErrCodeAppUnknownModule         int = 7
 	ErrCodeAppUnknownRelation       int = 8
 	ErrCodeAppUnknownAttribute      int = 9
+	ErrCodeAppRecordVersionConflict int = 10
+	ErrCodeAppValidationRegex       int = 11
+	ErrCodeAppValidationMin         int = 12
+	ErrCodeAppValidationMax         int = 13
+	ErrCodeAppValidationMinLength   int = 14
+	ErrCodeAppValidationMaxLength   int = 15
+	ErrCodeAppValidationValues      int = 16
+	ErrCodeAppValidationCompare     int = 17
+	ErrCodeAppValidationUnique      int = 18
 	ErrCodeCsvParseInt              int = 1
 	ErrCodeCsvParseFloat            int = 2
 	ErrCodeCsvParseDateTime         int = 3
@@ -71,11 +80,12 @@ const (
 
 var (
 	// errors
-	errContexts     = []errContext{ErrContextApp, ErrContextCsv, ErrContextDbs, ErrContextLic, ErrContextSec, ErrContextTrf}
-	errCodeDbsCache = regexp.MustCompile(fmt.Sprintf("^{ERR_DBS_%03d}", ErrCodeDbsChangedCachePlan))
-	errCodeLicRx    = regexp.MustCompile(`^{ERR_LIC_(\d{3})}`)
-	errCodeRx       = regexp.MustCompile(`^{ERR_([A-Z]{3})_(\d{3})}`)
-	errExpectedList = []errExpected{
+	errContexts       = []errContext{ErrContextApp, ErrContextCsv, ErrContextDbs, ErrContextLic, ErrContextSec, ErrContextTrf}
+	errCodeAppVersion = regexp.MustCompile(fmt.Sprintf("^{ERR_APP_%03d}", ErrCodeAppRecordVersionConflict))
+	errCodeDbsCache   = regexp.MustCompile(fmt.Sprintf("^{ERR_DBS_%03d}", ErrCodeDbsChangedCachePlan))
+	errCodeLicRx      = regexp.MustCompile(`^{ERR_LIC_(\d{3})}`)
+	errCodeRx         = regexp.MustCompile(`^{ERR_([A-Z]{3})_(\d{3})}`)
+	errExpectedList   = []errExpected{
 
 		// security/access
 		{ // unauthorized
@@ -221,6 +231,9 @@ func CheckForLicenseErrCode(err error) bool {
 func CheckForDbsCacheErrCode(err error) bool {
 	return errCodeDbsCache.MatchString(err.Error())
 }
+func CheckForRecordVersionErrCode(err error) bool {
+	return errCodeAppVersion.MatchString(err.Error())
+}
 
 // default schema errors
 func ErrSchemaUnknownApi(id uuid.UUID) error {
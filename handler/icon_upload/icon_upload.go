@@ -3,15 +3,19 @@ package icon_upload
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"r3/audit"
 	"r3/bruteforce"
 	"r3/config"
 	"r3/db"
 	"r3/handler"
 	"r3/login/login_auth"
+	"r3/request"
 	"r3/schema/icon"
+	"r3/types"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -74,7 +78,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if !login.Admin {
+		if err := request.CheckAdminAccess(login.Id, login.Admin, "icon", "set"); err != nil {
 			handler.AbortRequest(w, handler.ContextIconUpload, err, handler.ErrUnauthorized)
 			return
 		}
@@ -117,6 +121,20 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			handler.AbortRequest(w, handler.ContextIconUpload, err, handler.ErrGeneral)
 			return
 		}
+		payload, err := json.Marshal(map[string]uuid.UUID{"id": iconId, "moduleId": moduleId})
+		if err != nil {
+			handler.AbortRequest(w, handler.ContextIconUpload, err, handler.ErrGeneral)
+			return
+		}
+		auditLog, err := audit.Create_tx(ctx, tx, login.Id, handler.GetRemoteHost(r), "icon", "set", payload, nil)
+		if err != nil {
+			handler.AbortRequest(w, handler.ContextIconUpload, err, handler.ErrGeneral)
+			return
+		}
+		if err := audit.Write_tx(ctx, tx, []types.AuditLog{auditLog}); err != nil {
+			handler.AbortRequest(w, handler.ContextIconUpload, err, handler.ErrGeneral)
+			return
+		}
 		if err := tx.Commit(ctx); err != nil {
 			handler.AbortRequest(w, handler.ContextIconUpload, err, handler.ErrGeneral)
 			return
@@ -6,12 +6,15 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"r3/audit"
 	"r3/bruteforce"
 	"r3/cluster"
 	"r3/config"
 	"r3/db"
 	"r3/handler"
 	"r3/login/login_auth"
+	"r3/request"
+	"r3/types"
 	"time"
 )
 
@@ -60,7 +63,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if !login.Admin {
+		if err := request.CheckAdminAccess(login.Id, login.Admin, "license", "set"); err != nil {
 			handler.AbortRequest(w, handler.ContextLicenseUpload, err, handler.ErrUnauthorized)
 			return
 		}
@@ -94,6 +97,15 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			handler.AbortRequest(w, handler.ContextLicenseUpload, err, handler.ErrGeneral)
 			return
 		}
+		auditLog, err := audit.Create_tx(ctx, tx, login.Id, handler.GetRemoteHost(r), "license", "set", nil, nil)
+		if err != nil {
+			handler.AbortRequest(w, handler.ContextLicenseUpload, err, handler.ErrGeneral)
+			return
+		}
+		if err := audit.Write_tx(ctx, tx, []types.AuditLog{auditLog}); err != nil {
+			handler.AbortRequest(w, handler.ContextLicenseUpload, err, handler.ErrGeneral)
+			return
+		}
 		if err := tx.Commit(ctx); err != nil {
 			handler.AbortRequest(w, handler.ContextLicenseUpload, err, handler.ErrGeneral)
 			return
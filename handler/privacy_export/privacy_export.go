@@ -0,0 +1,89 @@
+package privacy_export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"r3/audit"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/login/login_auth"
+	"r3/privacy"
+	"r3/request"
+	"r3/tools"
+	"r3/types"
+)
+
+var genErr = "could not finish data subject export"
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	// get authentication token
+	token, err := handler.ReadGetterFromUrl(r, "token")
+	if err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutTransfer)
+	defer ctxCanc()
+
+	// authenticate via token
+	login, err := login_auth.Token(ctx, token)
+	if err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+
+	if err := request.CheckAdminAccess(login.Id, login.Admin, "privacy", "export"); err != nil {
+		log.Error(log.ContextServer, genErr, errors.New(handler.ErrUnauthorized))
+		return
+	}
+
+	// data subject, by login or by record
+	var subject types.PrivacySubject
+	if _, exists := r.URL.Query()["login_id"]; exists {
+		subject.LoginId.Int64, err = handler.ReadInt64GetterFromUrl(r, "login_id")
+		if err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		subject.LoginId.Valid = true
+	}
+	if _, exists := r.URL.Query()["relation_id"]; exists {
+		subject.RelationId.Bytes, err = handler.ReadUuidGetterFromUrl(r, "relation_id")
+		if err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		subject.RecordId.Int64, err = handler.ReadInt64GetterFromUrl(r, "record_id")
+		if err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		subject.RelationId.Valid = true
+		subject.RecordId.Valid = true
+	}
+
+	// exports of personal data are audited
+	payload, err := json.Marshal(subject)
+	if err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+	if err := audit.Write(ctx, login.Id, handler.GetRemoteHost(r), "privacy", "export", payload); err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data_subject_%d.zip"`, tools.GetTimeUnix()))
+
+	if err := privacy.Export(ctx, w, subject); err != nil {
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+}
@@ -0,0 +1,119 @@
+package saml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"r3/bruteforce"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/saml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var genErr = "could not finish SAML request"
+
+// SAML 2.0 service provider endpoints, per identity provider
+// GET  /saml/login/IDP_ID    starts SP-initiated login, redirects to identity provider
+// POST /saml/acs/IDP_ID      assertion consumer service, receives response from identity provider (HTTP-POST binding)
+// GET  /saml/metadata/IDP_ID service provider metadata, for registration at identity provider
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	if blocked := bruteforce.Check(r); blocked {
+		handler.ServeErrorPage(w, http.StatusTooManyRequests, errors.New(handler.ErrBruteforceBlock))
+		return
+	}
+
+	// 0 is empty, 1 = "saml", 2 = ACTION, 3 = IDP_ID
+	elements := strings.Split(strings.TrimSuffix(r.URL.Path, "/"), "/")
+	if len(elements) != 4 {
+		handler.ServeErrorPage(w, http.StatusNotFound, errors.New("invalid URL, expected: /saml/ACTION/IDP_ID"))
+		return
+	}
+	action := elements[2]
+
+	idpId, err := strconv.ParseInt(elements[3], 10, 32)
+	if err != nil {
+		handler.ServeErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+	p, err := cache.GetSamlIdp(int32(idpId))
+	if err != nil {
+		handler.ServeErrorPage(w, http.StatusNotFound, err)
+		return
+	}
+
+	var method = http.MethodGet
+	if action == "acs" {
+		method = http.MethodPost
+	}
+	if r.Method != method {
+		handler.ServeErrorPage(w, http.StatusMethodNotAllowed, fmt.Errorf("invalid HTTP method '%s'", r.Method))
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(),
+		time.Duration(int64(config.GetUint64("dbTimeoutDataWs")))*time.Second)
+
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		handler.ServeErrorPage(w, http.StatusInternalServerError, errors.New(handler.ErrGeneral))
+		log.Error(log.ContextServer, genErr, err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	switch action {
+	case "acs":
+		code, err := saml.ValidateResponse_tx(ctx, tx, p, r.PostFormValue("SAMLResponse"))
+		if err != nil {
+			// authentication errors are not returned, but logged
+			handler.ServeErrorPage(w, http.StatusUnauthorized, errors.New(handler.ErrAuthFailed))
+			log.Warning(log.ContextOauth, fmt.Sprintf("SAML response of identity provider '%s' is invalid", p.Name), err)
+			bruteforce.BadAttempt(r)
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			handler.ServeErrorPage(w, http.StatusInternalServerError, errors.New(handler.ErrGeneral))
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+
+		// client redeems one-time code to authenticate
+		http.Redirect(w, r, fmt.Sprintf("/?samlCode=%s", url.QueryEscape(code)), http.StatusSeeOther)
+
+	case "login":
+		urlRedirect, err := saml.CreateRequest_tx(ctx, tx, p)
+		if err == nil {
+			err = tx.Commit(ctx)
+		}
+		if err != nil {
+			handler.ServeErrorPage(w, http.StatusInternalServerError, errors.New(handler.ErrGeneral))
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		http.Redirect(w, r, urlRedirect, http.StatusFound)
+
+	case "metadata":
+		metadata, err := saml.GetMetadataSp(p)
+		if err != nil {
+			handler.ServeErrorPage(w, http.StatusInternalServerError, errors.New(handler.ErrGeneral))
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/samlmetadata+xml")
+		w.Write(metadata)
+
+	default:
+		handler.ServeErrorPage(w, http.StatusNotFound, fmt.Errorf("unknown action '%s'", action))
+	}
+}
@@ -0,0 +1,116 @@
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"r3/handler"
+	"r3/log"
+	"r3/saml"
+	"strings"
+)
+
+var (
+	localIdpTemplateLogin = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html style="font-family:'Roboto','Arial','Helvetica',sans-serif;">
+<head><title>Local SAML identity provider</title></head>
+<body style="background-color:#f2f2f2;">
+	<form method="post" style="max-width:600px;margin:40px auto;display:flex;flex-flow:column nowrap;gap:12px;padding:26px;border:1px solid #999;border-radius:8px;background-color:#fff;">
+		<h3 style="margin:0px;">Local SAML identity provider</h3>
+		<p style="margin:0px;">For development & testing only. Any entered user is authenticated without checks.</p>
+		<p style="margin:0px;">Service provider: <b>{{.SpEntityId}}</b></p>
+		<label>Name ID (username)<br /><input name="nameId" style="width:100%;" required autofocus /></label>
+		<label>Attributes, one per line as NAME=VALUE, repeat name for multiple values<br /><textarea name="attributes" rows="8" style="width:100%;"></textarea></label>
+		<input type="hidden" name="SAMLRequest" value="{{.SamlRequest}}" />
+		<input type="submit" value="Log in" />
+	</form>
+</body>
+</html>`))
+
+	localIdpTemplateResponse = template.Must(template.New("response").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Local SAML identity provider</title></head>
+<body onload="document.forms[0].submit();">
+	<form method="post" action="{{.AcsUrl}}">
+		<input type="hidden" name="SAMLResponse" value="{{.SamlResponse}}" />
+		<noscript><input type="submit" value="Continue" /></noscript>
+	</form>
+</body>
+</html>`))
+)
+
+// local SAML 2.0 identity provider, for development & testing only (must be enabled by start parameter)
+// GET  /saml/localidp/metadata identity provider metadata, to be imported as identity provider
+// GET  /saml/localidp/sso      receives request from service provider (HTTP-Redirect binding), shows login form
+// POST /saml/localidp/sso      posts signed response for entered user to service provider (HTTP-POST binding)
+func HandlerLocalIdp(w http.ResponseWriter, r *http.Request) {
+
+	scheme := "https"
+	if r.TLS == nil {
+		scheme = "http"
+	}
+	entityId := fmt.Sprintf("%s://%s/saml/localidp", scheme, r.Host)
+
+	switch fmt.Sprintf("%s %s", r.Method, strings.TrimSuffix(r.URL.Path, "/")) {
+	case "GET /saml/localidp/metadata":
+		metadata, err := saml.GetMetadataLocalIdp(entityId, fmt.Sprintf("%s/sso", entityId))
+		if err != nil {
+			handler.ServeErrorPage(w, http.StatusInternalServerError, errors.New(handler.ErrGeneral))
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/samlmetadata+xml")
+		w.Write(metadata)
+
+	case "GET /saml/localidp/sso":
+		samlRequest := r.URL.Query().Get("SAMLRequest")
+		req, err := saml.ReadRequestLocalIdp(samlRequest)
+		if err != nil {
+			handler.ServeErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		localIdpTemplateLogin.Execute(w, struct {
+			SamlRequest string
+			SpEntityId  string
+		}{samlRequest, req.SpEntityId})
+
+	case "POST /saml/localidp/sso":
+		req, err := saml.ReadRequestLocalIdp(r.PostFormValue("SAMLRequest"))
+		if err != nil {
+			handler.ServeErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+		nameId := strings.TrimSpace(r.PostFormValue("nameId"))
+		if nameId == "" {
+			handler.ServeErrorPage(w, http.StatusBadRequest, errors.New("name ID must not be empty"))
+			return
+		}
+
+		attributes := make(map[string][]string)
+		for _, line := range strings.Split(r.PostFormValue("attributes"), "\n") {
+			name, value, found := strings.Cut(strings.TrimSpace(line), "=")
+			if !found || strings.TrimSpace(name) == "" {
+				continue
+			}
+			name = strings.TrimSpace(name)
+			attributes[name] = append(attributes[name], strings.TrimSpace(value))
+		}
+
+		samlResponse, err := saml.CreateResponseLocalIdp(entityId, req, nameId, attributes)
+		if err != nil {
+			handler.ServeErrorPage(w, http.StatusInternalServerError, errors.New(handler.ErrGeneral))
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		localIdpTemplateResponse.Execute(w, struct {
+			AcsUrl       string
+			SamlResponse string
+		}{req.AcsUrl, samlResponse})
+
+	default:
+		handler.ServeErrorPage(w, http.StatusNotFound, errors.New("invalid URL, expected: /saml/localidp/metadata or /saml/localidp/sso"))
+	}
+}
@@ -0,0 +1,301 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"r3/bruteforce"
+	"r3/config"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/scim"
+	"r3/types"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	bodySizeMax int64 = 1024 * 1024 * 5 // 5 MiB, large enough for groups with many members
+	contentType       = "application/scim+json"
+)
+
+// SCIM 2.0 provisioning endpoint (RFC 7644), authenticated via bearer token of SCIM client
+// examples:
+// GET    /scim/v2/Users?filter=userName eq "john.doe"
+// POST   /scim/v2/Users
+// PATCH  /scim/v2/Users/45
+// DELETE /scim/v2/Groups/5b1f6a6c-0c5e-4b2c-9f3b-2d0d5a5b1e0f
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	if blocked := bruteforce.Check(r); blocked {
+		handler.AbortRequestNoLog(w, handler.ErrBruteforceBlock)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	r.Body = http.MaxBytesReader(w, r.Body, bodySizeMax)
+
+	// 0 is empty, 1 = "scim", 2 = "v2", 3 = RESOURCE, 4 = RESOURCE_ID (optional)
+	elements := strings.Split(strings.TrimSuffix(r.URL.Path, "/"), "/")
+	if len(elements) < 4 || len(elements) > 5 {
+		writeError(w, scim.Error{Status: http.StatusNotFound, Detail: "invalid URL, expected: /scim/v2/RESOURCE[/ID]"})
+		return
+	}
+	resource := elements[3]
+	resourceId := ""
+	if len(elements) == 5 {
+		resourceId = elements[4]
+	}
+
+	if !config.GetLicenseActive() {
+		writeError(w, scim.Error{Status: http.StatusForbidden, Detail: "no valid license"})
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(),
+		time.Duration(int64(config.GetUint64("dbTimeoutDataRest")))*time.Second)
+
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// authenticate SCIM client
+	client, err := scim.GetByToken_tx(ctx, tx, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil {
+		log.Warning(log.ContextApi, "SCIM authentication failed", err)
+		writeError(w, scim.Error{Status: http.StatusUnauthorized, Detail: handler.ErrUnauthorized})
+		bruteforce.BadAttempt(r)
+		return
+	}
+
+	log.Info(log.ContextApi, fmt.Sprintf("SCIM client '%s' calls %s on '%s' (ID: '%s')",
+		client.Name, r.Method, resource, resourceId))
+
+	res, httpCode, err := exec_tx(ctx, tx, r, client, resource, resourceId)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if res == nil {
+		w.WriteHeader(httpCode)
+		return
+	}
+	resJson, err := json.Marshal(res)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(httpCode)
+	w.Write(resJson)
+}
+
+func exec_tx(ctx context.Context, tx pgx.Tx, r *http.Request, client types.ScimClient,
+	resource string, id string) (any, int, error) {
+
+	var errMethod = scim.Error{Status: http.StatusMethodNotAllowed,
+		Detail: fmt.Sprintf("HTTP method '%s' is not supported for '%s'", r.Method, resource)}
+
+	// list parameters
+	filter := r.URL.Query().Get("filter")
+	startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+
+	switch resource {
+	case "ServiceProviderConfig":
+		if r.Method != http.MethodGet {
+			return nil, 0, errMethod
+		}
+		return getServiceProviderConfig(), http.StatusOK, nil
+
+	case "ResourceTypes":
+		if r.Method != http.MethodGet {
+			return nil, 0, errMethod
+		}
+		return getResourceTypes(), http.StatusOK, nil
+
+	case "Users":
+		switch r.Method {
+		case http.MethodDelete:
+			return nil, http.StatusNoContent, scim.UserDel_tx(ctx, tx, client, id)
+		case http.MethodGet:
+			if id == "" {
+				res, err := scim.UserList_tx(ctx, tx, client, filter, startIndex, count)
+				return res, http.StatusOK, err
+			}
+			res, err := scim.UserGet_tx(ctx, tx, client, id)
+			return res, http.StatusOK, err
+		case http.MethodPatch:
+			var req types.ScimPatch
+			if err := readBody(r, &req); err != nil || id == "" {
+				return nil, 0, errBadRequest(err)
+			}
+			res, err := scim.UserPatch_tx(ctx, tx, client, id, req)
+			return res, http.StatusOK, err
+		case http.MethodPost, http.MethodPut:
+			var req types.ScimUser
+			if err := readBody(r, &req); err != nil || (r.Method == http.MethodPut && id == "") {
+				return nil, 0, errBadRequest(err)
+			}
+			if r.Method == http.MethodPost {
+				id = ""
+			}
+			res, err := scim.UserSet_tx(ctx, tx, client, id, req)
+			if r.Method == http.MethodPost {
+				return res, http.StatusCreated, err
+			}
+			return res, http.StatusOK, err
+		}
+		return nil, 0, errMethod
+
+	case "Groups":
+		switch r.Method {
+		case http.MethodDelete:
+			return nil, http.StatusNoContent, scim.GroupDel_tx(ctx, tx, client, id)
+		case http.MethodGet:
+			if id == "" {
+				res, err := scim.GroupList_tx(ctx, tx, client, filter, startIndex, count)
+				return res, http.StatusOK, err
+			}
+			res, err := scim.GroupGet_tx(ctx, tx, client, id)
+			return res, http.StatusOK, err
+		case http.MethodPatch:
+			var req types.ScimPatch
+			if err := readBody(r, &req); err != nil || id == "" {
+				return nil, 0, errBadRequest(err)
+			}
+			res, err := scim.GroupPatch_tx(ctx, tx, client, id, req)
+			return res, http.StatusOK, err
+		case http.MethodPost, http.MethodPut:
+			var req types.ScimGroup
+			if err := readBody(r, &req); err != nil || (r.Method == http.MethodPut && id == "") {
+				return nil, 0, errBadRequest(err)
+			}
+			if r.Method == http.MethodPost {
+				id = ""
+			}
+			res, err := scim.GroupSet_tx(ctx, tx, client, id, req)
+			if r.Method == http.MethodPost {
+				return res, http.StatusCreated, err
+			}
+			return res, http.StatusOK, err
+		}
+		return nil, 0, errMethod
+	}
+	return nil, 0, scim.Error{Status: http.StatusNotFound, Detail: fmt.Sprintf("unknown resource '%s'", resource)}
+}
+
+func getResourceTypes() types.ScimListResponse {
+	type resourceType struct {
+		Schemas          []string `json:"schemas"`
+		Id               string   `json:"id"`
+		Name             string   `json:"name"`
+		Endpoint         string   `json:"endpoint"`
+		Schema           string   `json:"schema"`
+		SchemaExtensions []any    `json:"schemaExtensions"`
+	}
+	resourceTypes := []resourceType{
+		{
+			Schemas:  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			Id:       "User",
+			Name:     "User",
+			Endpoint: "/Users",
+			Schema:   scim.SchemaUser,
+			SchemaExtensions: []any{map[string]any{
+				"schema":   scim.SchemaEnterpriseUser,
+				"required": false,
+			}},
+		},
+		{
+			Schemas:          []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			Id:               "Group",
+			Name:             "Group",
+			Endpoint:         "/Groups",
+			Schema:           scim.SchemaGroup,
+			SchemaExtensions: []any{},
+		},
+	}
+	return types.ScimListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: len(resourceTypes),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	}
+}
+
+func getServiceProviderConfig() map[string]any {
+	var supported = func(v bool) map[string]any {
+		return map[string]any{"supported": v}
+	}
+	return map[string]any{
+		"schemas":        []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": 1000},
+		"changePassword": supported(true),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Authentication via bearer token, as defined for the SCIM client",
+		}},
+	}
+}
+
+func readBody(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return scim.Error{Status: http.StatusRequestEntityTooLarge, Detail: fmt.Sprintf("request body exceeds %d bytes", maxErr.Limit)}
+	}
+	return err
+}
+
+func errBadRequest(err error) error {
+	if err == nil {
+		err = errors.New("resource ID is required")
+	}
+
+	// keep SCIM errors of request body (like its size)
+	var scimErr scim.Error
+	if errors.As(err, &scimErr) {
+		return err
+	}
+	return scim.Error{Status: http.StatusBadRequest, Type: "invalidSyntax", Detail: err.Error()}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var scimErr scim.Error
+	if !errors.As(err, &scimErr) {
+		// unexpected errors are logged but not returned
+		log.Error(log.ContextApi, fmt.Sprintf("aborted %s request", handler.ContextNameMap[handler.ContextScim]), err)
+		scimErr = scim.Error{Status: http.StatusInternalServerError, Detail: handler.ErrGeneral}
+	}
+
+	resJson, _ := json.Marshal(types.ScimError{
+		Schemas:  []string{scim.SchemaError},
+		Status:   strconv.Itoa(scimErr.Status),
+		ScimType: scimErr.Type,
+		Detail:   scimErr.Detail,
+	})
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(scimErr.Status)
+	w.Write(resJson)
+}
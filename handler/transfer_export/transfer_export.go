@@ -11,6 +11,7 @@ import (
 	"r3/handler"
 	"r3/log"
 	"r3/login/login_auth"
+	"r3/request"
 	"r3/tools"
 	"r3/transfer"
 )
@@ -36,17 +37,11 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !login.Admin {
+	if err := request.CheckAdminAccess(login.Id, login.Admin, "transfer", "export"); err != nil {
 		log.Error(log.ContextServer, genErr, errors.New(handler.ErrUnauthorized))
 		return
 	}
 
-	exportKey, err := cache.GetExportKey(login.Id)
-	if err != nil {
-		log.Error(log.ContextServer, genErr, err)
-		return
-	}
-
 	// get module ID
 	moduleId, err := handler.ReadUuidGetterFromUrl(r, "module_id")
 	if err != nil {
@@ -60,9 +55,23 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := transfer.ExportToFile(ctx, moduleId, exportKey, filePath); err != nil {
-		log.Error(log.ContextServer, genErr, err)
-		return
+	// export as directory tree for version control, not signed
+	format, _ := handler.ReadGetterFromUrl(r, "format")
+	if format == "directory" {
+		if err := transfer.ExportToDirectoryZip(ctx, moduleId, filePath); err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+	} else {
+		exportKey, err := cache.GetExportKey(login.Id)
+		if err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
+		if err := transfer.ExportToFile(ctx, moduleId, exportKey, filePath); err != nil {
+			log.Error(log.ContextServer, genErr, err)
+			return
+		}
 	}
 	http.ServeFile(w, r, filePath)
 	if err := os.Remove(filePath); err != nil {
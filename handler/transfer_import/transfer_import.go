@@ -8,19 +8,24 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"r3/audit"
 	"r3/config"
 	"r3/db"
 	"r3/handler"
 	"r3/log"
 	"r3/login/login_auth"
+	"r3/request"
 	"r3/tools"
 	"r3/transfer"
+	"r3/types"
 )
 
 func Handler(res http.ResponseWriter, req *http.Request) {
 
 	res.Header().Set("Content-Type", "application/json")
 
+	var dryRunResult *types.TransferDryRun
+
 	finishRequest := func(err error) {
 
 		if err != nil {
@@ -29,9 +34,11 @@ func Handler(res http.ResponseWriter, req *http.Request) {
 		}
 
 		var response struct {
-			Success bool `json:"success"`
+			Success bool                  `json:"success"`
+			DryRun  *types.TransferDryRun `json:"dryRun,omitempty"`
 		}
 		response.Success = err == nil
+		response.DryRun = dryRunResult
 
 		responseJson, err := json.Marshal(response)
 		if err != nil {
@@ -50,7 +57,9 @@ func Handler(res http.ResponseWriter, req *http.Request) {
 	}
 
 	// loop form reader until empty
-	// fixed order: token first, then file
+	// fixed order: token & optional dry run flag & format first, then file
+	var dryRun bool
+	var format string
 	var token string
 	for {
 		part, err := reader.NextPart()
@@ -64,6 +73,16 @@ func Handler(res http.ResponseWriter, req *http.Request) {
 			buf.ReadFrom(part)
 			token = buf.String()
 			continue
+		case "dryRun":
+			buf := new(bytes.Buffer)
+			buf.ReadFrom(part)
+			dryRun = buf.String() == "true"
+			continue
+		case "format":
+			buf := new(bytes.Buffer)
+			buf.ReadFrom(part)
+			format = buf.String()
+			continue
 		}
 
 		ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutTransfer)
@@ -77,7 +96,14 @@ func Handler(res http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		if !login.Admin {
+		if err := request.CheckAdminAccess(login.Id, login.Admin, "repoModule", "install"); err != nil {
+			finishRequest(errors.New(handler.ErrUnauthorized))
+			return
+		}
+
+		// directory exports are not signed, their contents are not verified by trusted keys
+		// only full admins may import them & only on instances in builder mode
+		if format == "directory" && (!login.Admin || config.GetUint64("builderMode") != 1) {
 			finishRequest(errors.New(handler.ErrUnauthorized))
 			return
 		}
@@ -103,7 +129,39 @@ func Handler(res http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		if err := transfer.ImportFromFiles(ctx, []string{filePath}); err != nil {
+		// dry run, return changes without applying them
+		if dryRun {
+			var result types.TransferDryRun
+			if format == "directory" {
+				result, err = transfer.ImportFromDirectoryZip(ctx, filePath, true)
+			} else {
+				result, err = transfer.ImportDryRun(ctx, []string{filePath})
+			}
+			if err != nil {
+				finishRequest(err)
+				return
+			}
+			dryRunResult = &result
+			continue
+		}
+
+		// directory tree from version control or regular module package
+		if format == "directory" {
+			_, err = transfer.ImportFromDirectoryZip(ctx, filePath, false)
+		} else {
+			err = transfer.ImportFromFiles(ctx, []string{filePath})
+		}
+		if err != nil {
+			finishRequest(err)
+			return
+		}
+
+		payload, err := json.Marshal(map[string]string{"fileName": part.FileName()})
+		if err != nil {
+			finishRequest(err)
+			return
+		}
+		if err := audit.Write(ctx, login.Id, handler.GetRemoteHost(req), "transfer", "import", payload); err != nil {
 			finishRequest(err)
 			return
 		}
@@ -29,19 +29,21 @@ import (
 
 // a websocket client
 type clientType struct {
-	id          uuid.UUID                   // unique ID for client (for registering/de-registering login sessions)
-	address     string                      // IP address, no port
-	admin       bool                        // belongs to admin login?
-	ctx         context.Context             // context for requests from this client
-	ctxCancel   context.CancelFunc          // to abort requests in case of disconnect
-	device      types.WebsocketClientDevice // client device type (browser, fatClient)
-	ioFailure   atomic.Bool                 // client failed to read/write
-	local       bool                        // client is local (::1, 127.0.0.1)
-	loginId     int64                       // client login ID, 0 = not logged in yet
-	noAuth      bool                        // logged in without authentication (public auth, username only)
-	pwaModuleId uuid.UUID                   // ID of module for direct app access via subdomain, nil UUID if not used
-	write_mx    sync.Mutex                  // to force sequential writes
-	ws          *websocket.Conn             // websocket connection
+	id               uuid.UUID                     // unique ID for client (for registering/de-registering login sessions)
+	address          string                        // IP address, no port
+	admin            bool                          // belongs to admin login?
+	ctx              context.Context               // context for requests from this client
+	ctxCancel        context.CancelFunc            // to abort requests in case of disconnect
+	device           types.WebsocketClientDevice   // client device type (browser, fatClient)
+	ioFailure        atomic.Bool                   // client failed to read/write
+	local            bool                          // client is local (::1, 127.0.0.1)
+	loginId          int64                         // client login ID, 0 = not logged in yet
+	noAuth           bool                          // logged in without authentication (public auth, username only)
+	pwaModuleId      uuid.UUID                     // ID of module for direct app access via subdomain, nil UUID if not used
+	subscriptions    map[string]clientSubscription // record subscriptions, key: subscription ID defined by client
+	subscriptions_mx sync.Mutex                    // to access subscriptions
+	write_mx         sync.Mutex                    // to force sequential writes
+	ws               *websocket.Conn               // websocket connection
 }
 
 // a hub for all active websocket clients
@@ -109,18 +111,19 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	// create global request context with abort function
 	ctx, ctxCancel := context.WithCancel(context.Background())
 	client := &clientType{
-		id:          clientId,
-		address:     host,
-		admin:       false,
-		ctx:         ctx,
-		ctxCancel:   ctxCancel,
-		device:      types.WebsocketClientDeviceBrowser,
-		local:       host == "::1" || host == "127.0.0.1",
-		loginId:     0,
-		noAuth:      false,
-		pwaModuleId: cache.GetPwaModuleId(strings.Split(r.Host, ".")[0]), // assign PWA module ID if host matches any defined PWA direct app access rule
-		write_mx:    sync.Mutex{},
-		ws:          ws,
+		id:            clientId,
+		address:       host,
+		admin:         false,
+		ctx:           ctx,
+		ctxCancel:     ctxCancel,
+		device:        types.WebsocketClientDeviceBrowser,
+		local:         host == "::1" || host == "127.0.0.1",
+		loginId:       0,
+		noAuth:        false,
+		pwaModuleId:   cache.GetPwaModuleId(strings.Split(r.Host, ".")[0]), // assign PWA module ID if host matches any defined PWA direct app access rule
+		subscriptions: make(map[string]clientSubscription),
+		write_mx:      sync.Mutex{},
+		ws:            ws,
 	}
 
 	if r.Header.Get("User-Agent") == "r3-client-fat" {
@@ -155,6 +158,8 @@ func (hub *hubType) start() {
 
 		go func() {
 			// run DB calls in async func as they must not block hub operations during heavy DB load
+			client.subscriptionsClear()
+
 			if err := login_session.LogRemove(client.id); err != nil {
 				log.Error(log.ContextWebsocket, "failed to remove login session log", err)
 			}
@@ -196,6 +201,18 @@ func (hub *hubType) start() {
 			case "keystrokesRequested":
 				jsonMsg, err = prepareUnrequested("keystrokesRequested", event.Payload)
 				singleRecipient = true
+			case "recordsChanged":
+				// record changes are filtered by login access, once for all subscribed clients of a login
+				if change, ok := event.Payload.(types.ClusterEventRecordsChanged); ok {
+					clients := make([]*clientType, 0)
+					for client := range hub.clients {
+						if client.loginId != 0 {
+							clients = append(clients, client)
+						}
+					}
+					go sendRecordsChangedByLogin(clients, change)
+				}
+				continue
 			case "kick":
 				kick = true
 			case "kickNonAdmin":
@@ -325,10 +342,25 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 
 	defer ctxCanc()
 
-	// client can either authenticate or execute requests
+	// client can either authenticate, manage its record subscriptions or execute requests
 	authRequest := len(reqTrans.Requests) == 1 && reqTrans.Requests[0].Ressource == "auth"
+	subscriptionRequest := len(reqTrans.Requests) == 1 && reqTrans.Requests[0].Ressource == "subscription"
+
+	if subscriptionRequest {
+		// subscriptions are client states, no DB transaction is required
+		var res types.Response
+		payload, err := client.handleSubscription(ctx, reqTrans.Requests[0])
+		if err == nil {
+			res.Payload, err = json.Marshal(payload)
+		}
+		if err != nil {
+			resTrans.Responses = make([]types.Response, 0)
+			resTrans.Error = processReturnErr(err, client.admin, client.loginId, reqTrans.TransactionNr).Error()
+		} else {
+			resTrans.Responses = []types.Response{res}
+		}
 
-	if !authRequest {
+	} else if !authRequest {
 		// execute non-authentication transaction
 		resTrans.Responses, err = request.ExecTransaction(ctx, client.address, client.loginId,
 			client.admin, client.device, client.noAuth, reqTrans, false)
@@ -367,6 +399,9 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 		case "openId": // authentication via Open ID Connect
 			login, err = request_login.AuthOpenId(ctx, req.Payload)
 
+		case "saml": // authentication via SAML 2.0
+			login, err = request_login.AuthSaml(ctx, req.Payload)
+
 		case "token": // authentication via JSON web token
 			login, err = request_login.AuthToken(ctx, req.Payload)
 
@@ -0,0 +1,341 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"r3/cache"
+	"r3/cluster"
+	"r3/config"
+	"r3/data"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/types"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maximum number of record subscriptions per client
+const subscriptionsMax = 250
+
+// a record subscription of a client
+type clientSubscription struct {
+	query                  *types.DataGet        // subscribed query with filters, nil if not used
+	relationIds            []uuid.UUID           // relations of subscribed query, changes to any of their records are relevant if they match query filters
+	relationIdMapRecordIds map[uuid.UUID][]int64 // subscribed records, also records of query results known to client
+}
+
+// number of client subscriptions on this node by relation
+// stored for the node, so that changes to relations without subscriptions do not create node events
+var (
+	subscriptionRelationIdMapCount = make(map[uuid.UUID]int)
+	subscriptionRelation_mx        sync.Mutex
+)
+
+// returns all relations of subscription
+func (cs clientSubscription) getRelationIds() []uuid.UUID {
+	relationIds := slices.Clone(cs.relationIds)
+	for id := range cs.relationIdMapRecordIds {
+		if !slices.Contains(relationIds, id) {
+			relationIds = append(relationIds, id)
+		}
+	}
+	return relationIds
+}
+
+// updates subscription counts of relations, node subscriptions are only changed when first/last subscription of relation is added/removed
+func subscriptionRelationsUpdate(ctx context.Context, relationIdsAdd []uuid.UUID, relationIdsDel []uuid.UUID) error {
+	subscriptionRelation_mx.Lock()
+	defer subscriptionRelation_mx.Unlock()
+
+	relationIdsAddNode := make([]uuid.UUID, 0)
+	relationIdsDelNode := make([]uuid.UUID, 0)
+	for _, id := range relationIdsAdd {
+		if subscriptionRelationIdMapCount[id] == 0 {
+			relationIdsAddNode = append(relationIdsAddNode, id)
+		}
+		subscriptionRelationIdMapCount[id]++
+	}
+	for _, id := range relationIdsDel {
+		subscriptionRelationIdMapCount[id]--
+		if subscriptionRelationIdMapCount[id] <= 0 {
+			relationIdsDelNode = append(relationIdsDelNode, id)
+			delete(subscriptionRelationIdMapCount, id)
+		}
+	}
+
+	// relations added & removed in the same update are still subscribed
+	relationIdsDelNode = slices.DeleteFunc(relationIdsDelNode, func(id uuid.UUID) bool {
+		return subscriptionRelationIdMapCount[id] > 0
+	})
+
+	if err := cluster.SetSubscriptionRelations(ctx, relationIdsAddNode, relationIdsDelNode); err != nil {
+		// revert counts, subscription is rejected
+		for _, id := range relationIdsAdd {
+			subscriptionRelationIdMapCount[id]--
+			if subscriptionRelationIdMapCount[id] <= 0 {
+				delete(subscriptionRelationIdMapCount, id)
+			}
+		}
+		for _, id := range relationIdsDel {
+			subscriptionRelationIdMapCount[id]++
+		}
+		return err
+	}
+	return nil
+}
+
+// sets or deletes record subscriptions of client
+// subscriptions are bound to the websocket connection and must be renewed after reconnecting
+func (client *clientType) handleSubscription(ctx context.Context, req types.Request) (any, error) {
+	if client.loginId == 0 {
+		return nil, errors.New(handler.ErrUnauthorized)
+	}
+
+	var s types.DataSubscription
+	if err := json.Unmarshal(req.Payload, &s); err != nil {
+		return nil, err
+	}
+	if s.Id == "" {
+		return nil, fmt.Errorf("subscription ID must not be empty")
+	}
+
+	client.subscriptions_mx.Lock()
+	defer client.subscriptions_mx.Unlock()
+
+	// client was removed
+	if client.subscriptions == nil {
+		return nil, errors.New(handler.ErrUnauthorized)
+	}
+
+	csOld, exists := client.subscriptions[s.Id]
+	relationIdsOld := make([]uuid.UUID, 0)
+	if exists {
+		relationIdsOld = csOld.getRelationIds()
+	}
+
+	switch req.Action {
+	case "del":
+		if !exists {
+			return nil, nil
+		}
+		if err := subscriptionRelationsUpdate(ctx, nil, relationIdsOld); err != nil {
+			return nil, err
+		}
+		delete(client.subscriptions, s.Id)
+		return nil, nil
+	case "set":
+		if !exists && len(client.subscriptions) >= subscriptionsMax {
+			return nil, fmt.Errorf("subscription limit of %d is reached", subscriptionsMax)
+		}
+
+		cs := clientSubscription{
+			query:                  s.Query,
+			relationIds:            make([]uuid.UUID, 0),
+			relationIdMapRecordIds: s.RelationIdMapRecordIds,
+		}
+		if cs.relationIdMapRecordIds == nil {
+			cs.relationIdMapRecordIds = make(map[uuid.UUID][]int64)
+		}
+
+		// resolve relations of query, joined relations are identified by their relationship attributes
+		if s.Query != nil {
+			cs.relationIds = append(cs.relationIds, s.Query.RelationId)
+
+			cache.Schema_mx.RLock()
+			for _, j := range s.Query.Joins {
+				atr, exists := cache.AttributeIdMap[j.AttributeId]
+				if !exists {
+					cache.Schema_mx.RUnlock()
+					return nil, handler.ErrSchemaUnknownAttribute(j.AttributeId)
+				}
+				for _, id := range []uuid.UUID{atr.RelationId, atr.RelationshipId.Bytes} {
+					if !slices.Contains(cs.relationIds, id) {
+						cs.relationIds = append(cs.relationIds, id)
+					}
+				}
+			}
+			cache.Schema_mx.RUnlock()
+		}
+
+		if err := subscriptionRelationsUpdate(ctx, cs.getRelationIds(), relationIdsOld); err != nil {
+			return nil, err
+		}
+		client.subscriptions[s.Id] = cs
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown resource or action")
+}
+
+// removes all subscriptions of client, no new subscriptions can be added afterwards
+func (client *clientType) subscriptionsClear() {
+	client.subscriptions_mx.Lock()
+	defer client.subscriptions_mx.Unlock()
+
+	relationIds := make([]uuid.UUID, 0)
+	for _, cs := range client.subscriptions {
+		relationIds = append(relationIds, cs.getRelationIds()...)
+	}
+	client.subscriptions = nil
+
+	if len(relationIds) == 0 {
+		return
+	}
+	if err := subscriptionRelationsUpdate(context.Background(), nil, relationIds); err != nil {
+		log.Error(log.ContextWebsocket, "failed to remove record subscriptions", err)
+	}
+}
+
+// returns whether any client subscription might be affected by record change, before access & filters are checked
+// deleted records are only relevant if the client knows them
+func (client *clientType) isSubscribed(change types.ClusterEventRecordsChanged) bool {
+	client.subscriptions_mx.Lock()
+	defer client.subscriptions_mx.Unlock()
+
+	for _, s := range client.subscriptions {
+		if !change.Deleted && slices.Contains(s.relationIds, change.RelationId) {
+			return true
+		}
+		for _, recordId := range s.relationIdMapRecordIds[change.RelationId] {
+			if slices.Contains(change.RecordIds, recordId) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returns IDs of client subscriptions affected by record change, visible to its login
+// deleted records are reduced to the ones the client knows, as they cannot be checked against policies anymore
+// query matches are stored by query to be reused for other clients of the same login
+func (client *clientType) getSubscriptionIds_tx(ctx context.Context, tx pgx.Tx, change types.ClusterEventRecordsChanged,
+	queryMapMatch map[string]bool) ([]string, types.ClusterEventRecordsChanged) {
+
+	client.subscriptions_mx.Lock()
+	subscriptions := maps.Clone(client.subscriptions)
+	client.subscriptions_mx.Unlock()
+
+	ids := make([]string, 0)
+	recordIdsKnown := make([]int64, 0)
+
+	for id, s := range subscriptions {
+		isKnown := false
+		for _, recordId := range s.relationIdMapRecordIds[change.RelationId] {
+			if slices.Contains(change.RecordIds, recordId) {
+				isKnown = true
+				if !slices.Contains(recordIdsKnown, recordId) {
+					recordIdsKnown = append(recordIdsKnown, recordId)
+				}
+			}
+		}
+		if isKnown {
+			ids = append(ids, id)
+			continue
+		}
+		if change.Deleted || s.query == nil || !slices.Contains(s.relationIds, change.RelationId) {
+			continue
+		}
+
+		// records not known to the client must match the query filters
+		if len(s.query.Filters) == 0 {
+			ids = append(ids, id)
+			continue
+		}
+		queryJson, err := json.Marshal(s.query)
+		if err != nil {
+			log.Error(log.ContextWebsocket, "failed to prepare subscription query", err)
+			continue
+		}
+		match, exists := queryMapMatch[string(queryJson)]
+		if !exists {
+			match, err = data.GetRecordsChangedFiltered_tx(ctx, tx, client.loginId, *s.query, change)
+			if err != nil {
+				log.Error(log.ContextWebsocket, "failed to check changed records against subscription filters", err)
+				continue
+			}
+			queryMapMatch[string(queryJson)] = match
+		}
+		if match {
+			ids = append(ids, id)
+		}
+	}
+
+	if change.Deleted {
+		change.RecordIds = recordIdsKnown
+	}
+	return ids, change
+}
+
+// informs subscribed clients about record change, grouped by their logins
+func sendRecordsChangedByLogin(clients []*clientType, change types.ClusterEventRecordsChanged) {
+	loginIdMapClients := make(map[int64][]*clientType)
+	for _, client := range clients {
+		if client.isSubscribed(change) {
+			loginIdMapClients[client.loginId] = append(loginIdMapClients[client.loginId], client)
+		}
+	}
+	for loginId, clientsLogin := range loginIdMapClients {
+		go sendRecordsChanged(loginId, clientsLogin, change)
+	}
+}
+
+// informs clients of a login about record change, if visible to the login
+// access & policies are checked once for all clients of the login
+func sendRecordsChanged(loginId int64, clients []*clientType, change types.ClusterEventRecordsChanged) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(),
+		time.Duration(int64(config.GetUint64("dbTimeoutDataWs")))*time.Second)
+
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		log.Error(log.ContextWebsocket, "failed to check access to changed records", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// policy functions are executed in the context of the subscribed login
+	if err := db.SetSessionConfig_tx(ctx, tx, loginId); err != nil {
+		log.Error(log.ContextWebsocket, "failed to check access to changed records", err)
+		return
+	}
+
+	change, visible, err := data.GetRecordsChangedAuthorized_tx(ctx, tx, loginId, change)
+	if err != nil {
+		log.Error(log.ContextWebsocket, "failed to check access to changed records", err)
+		return
+	}
+	if !visible {
+		return
+	}
+
+	queryMapMatch := make(map[string]bool)
+	for _, client := range clients {
+
+		// records can be visible to login but not be relevant to subscriptions after filtering
+		subscriptionIds, changeClient := client.getSubscriptionIds_tx(ctx, tx, change, queryMapMatch)
+		if len(subscriptionIds) == 0 {
+			continue
+		}
+
+		jsonMsg, err := prepareUnrequested("recordsChanged", struct {
+			types.ClusterEventRecordsChanged
+			SubscriptionIds []string `json:"subscriptionIds"`
+		}{
+			ClusterEventRecordsChanged: changeClient,
+			SubscriptionIds:            subscriptionIds,
+		})
+		if err != nil {
+			log.Error(log.ContextWebsocket, "could not prepare unrequested transaction", err)
+			return
+		}
+		go client.write(jsonMsg)
+	}
+}
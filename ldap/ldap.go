@@ -34,6 +34,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx) ([]types.Ldap, error) {
 			l.login_template_id,
 			l.name,
 			l.host,
+			l.hosts_failover,
 			l.port,
 			l.bind_user_dn,
 			l.bind_user_pw,
@@ -42,6 +43,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx) ([]types.Ldap, error) {
 			l.key_attribute,
 			l.login_attribute,
 			l.member_attribute,
+			l.filter_group_dn,
+			l.delta_attribute,
 			l.assign_roles,
 			l.ms_ad_ext,
 			l.starttls,
@@ -71,9 +74,9 @@ func Get_tx(ctx context.Context, tx pgx.Tx) ([]types.Ldap, error) {
 		var l types.Ldap
 		var m types.LoginMeta
 		if err := rows.Scan(&l.Id, &l.LoginTemplateId, &l.Name, &l.Host,
-			&l.Port, &l.BindUserDn, &l.BindUserPw, &l.SearchClass, &l.SearchDn,
-			&l.KeyAttribute, &l.LoginAttribute, &l.MemberAttribute,
-			&l.AssignRoles, &l.MsAdExt, &l.Starttls, &l.Tls, &l.TlsVerify,
+			&l.HostsFailover, &l.Port, &l.BindUserDn, &l.BindUserPw, &l.SearchClass,
+			&l.SearchDn, &l.KeyAttribute, &l.LoginAttribute, &l.MemberAttribute,
+			&l.FilterGroupDn, &l.DeltaAttribute, &l.AssignRoles, &l.MsAdExt, &l.Starttls, &l.Tls, &l.TlsVerify,
 			&m.Department, &m.Email, &m.Location, &m.NameDisplay, &m.NameFore,
 			&m.NameSur, &m.Notes, &m.Organization, &m.PhoneFax, &m.PhoneLandline,
 			&m.PhoneMobile); err != nil {
@@ -95,19 +98,24 @@ func Get_tx(ctx context.Context, tx pgx.Tx) ([]types.Ldap, error) {
 
 func Set_tx(ctx context.Context, tx pgx.Tx, l types.Ldap) error {
 
+	if l.HostsFailover == nil {
+		l.HostsFailover = make([]string, 0)
+	}
+
 	if l.Id == 0 {
 		if err := tx.QueryRow(ctx, `
 			INSERT INTO instance.ldap (
 				login_template_id, name, host, port, bind_user_dn, bind_user_pw,
 				search_class, search_dn, key_attribute, login_attribute,
-				member_attribute, assign_roles, ms_ad_ext, starttls, tls, tls_verify
+				member_attribute, assign_roles, ms_ad_ext, starttls, tls, tls_verify,
+				hosts_failover, filter_group_dn, delta_attribute
 			)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)
 			RETURNING id
 		`, l.LoginTemplateId, l.Name, l.Host, l.Port, l.BindUserDn, l.BindUserPw,
 			l.SearchClass, l.SearchDn, l.KeyAttribute, l.LoginAttribute,
 			l.MemberAttribute, l.AssignRoles, l.MsAdExt, l.Starttls, l.Tls,
-			l.TlsVerify).Scan(&l.Id); err != nil {
+			l.TlsVerify, l.HostsFailover, l.FilterGroupDn, l.DeltaAttribute).Scan(&l.Id); err != nil {
 
 			return err
 		}
@@ -118,12 +126,15 @@ func Set_tx(ctx context.Context, tx pgx.Tx, l types.Ldap) error {
 				bind_user_dn = $5, bind_user_pw = $6, search_class = $7,
 				search_dn = $8, key_attribute = $9, login_attribute = $10,
 				member_attribute = $11, assign_roles = $12, ms_ad_ext = $13,
-				starttls = $14, tls = $15, tls_verify = $16
-			WHERE id = $17
+				starttls = $14, tls = $15, tls_verify = $16, hosts_failover = $17,
+				filter_group_dn = $18, delta_attribute = $19,
+				-- connection or filter settings might have changed, next run is a full sync
+				delta_host = NULL, delta_value = NULL, date_full_sync = NULL
+			WHERE id = $20
 		`, l.LoginTemplateId, l.Name, l.Host, l.Port, l.BindUserDn, l.BindUserPw,
 			l.SearchClass, l.SearchDn, l.KeyAttribute, l.LoginAttribute,
 			l.MemberAttribute, l.AssignRoles, l.MsAdExt, l.Starttls, l.Tls,
-			l.TlsVerify, l.Id); err != nil {
+			l.TlsVerify, l.HostsFailover, l.FilterGroupDn, l.DeltaAttribute, l.Id); err != nil {
 
 			return err
 		}
@@ -12,7 +12,7 @@ import (
 // authenticate against LDAP profile
 func Check(ldapId int32, username string, password string) error {
 
-	ldapConn, ldap, err := ldap_conn.ConnectAndBind(ldapId)
+	ldapConn, ldap, _, err := ldap_conn.ConnectAndBind(ldapId)
 	if err != nil {
 		log.Error(log.ContextLdap, "failed to connect or bind", err)
 		return err
@@ -23,8 +23,8 @@ func Check(ldapId int32, username string, password string) error {
 	search, err := ldapConn.Search(goldap.NewSearchRequest(
 		ldap.SearchDn,
 		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf("(&(objectClass=%s)(%s=%s))", ldap.SearchClass,
-			ldap.LoginAttribute, username),
+		fmt.Sprintf("(&(objectClass=%s)(%s=%s))", goldap.EscapeFilter(ldap.SearchClass),
+			ldap.LoginAttribute, goldap.EscapeFilter(username)),
 		[]string{"dn"},
 		nil,
 	))
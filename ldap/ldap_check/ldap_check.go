@@ -6,7 +6,7 @@ import (
 
 func Run(ldapId int32) error {
 
-	ldapConn, _, err := ldap_conn.ConnectAndBind(ldapId)
+	ldapConn, _, _, err := ldap_conn.ConnectAndBind(ldapId)
 	if err != nil {
 		return err
 	}
@@ -11,49 +11,70 @@ import (
 )
 
 // connect to a LDAP profile
-func ConnectAndBind(ldapId int32) (*goldap.Conn, types.Ldap, error) {
+// primary host is tried first, then failover hosts in defined order
+// returns connection, LDAP profile and host that connection was established with
+func ConnectAndBind(ldapId int32) (*goldap.Conn, types.Ldap, string, error) {
 
 	ldap, err := cache.GetLdap(ldapId)
 	if err != nil {
-		return nil, ldap, err
+		return nil, ldap, "", err
 	}
 
+	hosts := append([]string{ldap.Host}, ldap.HostsFailover...)
+	for i, host := range hosts {
+		ldapConn, err := connectAndBindHost(ldap, host)
+		if err == nil {
+			return ldapConn, ldap, host, nil
+		}
+		if i == len(hosts)-1 {
+			return nil, ldap, "", err
+		}
+		log.Warning(log.ContextLdap, fmt.Sprintf("failed to connect to host '%s', trying next host", host), err)
+	}
+	return nil, ldap, "", fmt.Errorf("no host defined for LDAP '%s'", ldap.Name)
+}
+
+func connectAndBindHost(ldap types.Ldap, host string) (*goldap.Conn, error) {
+
 	// prepare bind string
 	protocol := "ldap"
 	if ldap.Tls {
 		protocol = "ldaps"
 	}
-	bind := fmt.Sprintf("%s://%s:%d", protocol, ldap.Host, ldap.Port)
+	bind := fmt.Sprintf("%s://%s:%d", protocol, host, ldap.Port)
 
 	// prepare TLS config
 	tlsConfig := tls.Config{
 		InsecureSkipVerify: !ldap.TlsVerify,
-		ServerName:         ldap.Host,
+		ServerName:         host,
 	}
 
 	log.Info(log.ContextLdap, fmt.Sprintf("connecting to '%s'", bind))
 
 	var ldapConn *goldap.Conn
+	var err error
 	if ldap.Tls {
 		ldapConn, err = goldap.DialURL(bind, goldap.DialWithTLSConfig(&tlsConfig))
 		if err != nil {
-			return nil, ldap, err
+			return nil, err
 		}
 	} else {
 		ldapConn, err = goldap.DialURL(bind)
 		if err != nil {
-			return nil, ldap, err
+			return nil, err
 		}
 		if ldap.Starttls {
 			if err := ldapConn.StartTLS(&tlsConfig); err != nil {
-				return nil, ldap, err
+				ldapConn.Close()
+				return nil, err
 			}
 		}
 	}
 
 	// bind with reading user
 	if err := ldapConn.Bind(ldap.BindUserDn, ldap.BindUserPw); err != nil {
-		return nil, ldap, err
+		ldapConn.Close()
+		return nil, err
 	}
-	return ldapConn, ldap, nil
+	return ldapConn, nil
 }
@@ -1,20 +1,26 @@
 package ldap_import
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"r3/cache"
 	"r3/config"
+	"r3/db"
 	"r3/ldap/ldap_conn"
 	"r3/log"
 	"r3/login"
+	"r3/tools"
 	"r3/types"
 	"slices"
+	"sort"
+	"strconv"
 	"unicode/utf8"
 
 	goldap "github.com/go-ldap/ldap/v3"
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type loginType struct {
@@ -28,6 +34,10 @@ var (
 	msAdExtDisabledAtrFlags = []string{"514", "546", "66050",
 		"66082", "262658", "262690", "328194", "328226"}
 	pageSize uint32 = 30
+
+	// delta sync cannot detect removed users or changed group memberships (group objects change, not users)
+	// full sync is executed at least once within this interval
+	fullSyncIntervalSec int64 = 60 * 60 * 24
 )
 
 func RunAll() error {
@@ -39,24 +49,54 @@ func RunAll() error {
 	}
 
 	for _, ldap := range ldapIdMap {
-		if err := run(ldap.Id); err != nil {
+		if _, err := run(ldap.Id, false); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func run(ldapId int32) error {
+// returns changes that a full login import would apply, without applying them
+func Preview(ldapId int32) ([]types.LdapImportChange, error) {
+	if !config.GetLicenseActive() {
+		return nil, errors.New("no valid license")
+	}
+	return run(ldapId, true)
+}
+
+func run(ldapId int32, dryRun bool) ([]types.LdapImportChange, error) {
+	changes := make([]types.LdapImportChange, 0)
 
-	ldapConn, ldap, err := ldap_conn.ConnectAndBind(ldapId)
+	ldapConn, ldap, host, err := ldap_conn.ConnectAndBind(ldapId)
 	if err != nil {
-		return err
+		return changes, err
 	}
 	defer ldapConn.Close()
 
+	// delta sync, only query objects changed since last run
+	// delta values (like USNs) are local to each directory server, delta state is only valid for the host it was taken from
+	// dry runs always compare all objects
+	deltaAttribute := ""
+	deltaValue := ""
+	if ldap.DeltaAttribute.Valid {
+		deltaAttribute = ldap.DeltaAttribute.String
+	}
+	if deltaAttribute != "" && !dryRun {
+		deltaValue, err = getDeltaValue(ldap.Id, host)
+		if err != nil {
+			return changes, err
+		}
+	}
+	isDelta := deltaValue != ""
+	deltaValueMax := deltaValue
+
 	// define attributes to lookup and filters to apply
 	attributes := []string{"dn", ldap.KeyAttribute, ldap.LoginAttribute}
 
+	if deltaAttribute != "" {
+		attributes = append(attributes, deltaAttribute)
+	}
+
 	// MS AD, add user account control (currently for account (de)activation)
 	if ldap.MsAdExt {
 		attributes = append(attributes, "userAccountControl")
@@ -112,7 +152,7 @@ func run(ldapId int32) error {
 
 	// LDAP auto role assignment removes existing roles from user, defining no roles here would remove all access
 	if ldap.AssignRoles && len(ldap.LoginRolesAssign) == 0 {
-		return errors.New("no roles are defined for assignment by LDAP group")
+		return changes, errors.New("no roles are defined for assignment by LDAP group")
 	}
 
 	// if LDAP auto role assignment is disabled, remove defined role assignments (do not need to be queried)
@@ -125,21 +165,24 @@ func run(ldapId int32) error {
 	// * query of just users (without we´d loose users that have no defined group DN assigned)
 	ldap.LoginRolesAssign = append(ldap.LoginRolesAssign, types.LoginRoleAssign{}) // empty group DN
 
+	// filters applied to all queries: object class, group filter (if set) and delta (if delta sync)
+	filtersBase := fmt.Sprintf("(objectClass=%s)", goldap.EscapeFilter(ldap.SearchClass))
+
+	if ldap.FilterGroupDn.Valid && ldap.FilterGroupDn.String != "" {
+		filtersBase += getGroupFilter(ldap, ldap.FilterGroupDn.String)
+	}
+	if isDelta {
+		filtersBase += fmt.Sprintf("(%s>=%s)", deltaAttribute, goldap.EscapeFilter(deltaValue))
+	}
+
 	for _, role := range ldap.LoginRolesAssign {
 
-		filters := fmt.Sprintf("(&(objectClass=%s))", ldap.SearchClass)
+		filters := fmt.Sprintf("(&%s)", filtersBase)
 
 		// set filters to search for group DN if role assignment is active
 		// group DN is empty if just users are queried
 		if ldap.AssignRoles && role.SearchString != "" {
-
-			if ldap.MsAdExt {
-				filters = fmt.Sprintf("(&(objectClass=%s)(%s:1.2.840.113556.1.4.1941:=%s))",
-					ldap.SearchClass, ldap.MemberAttribute, role.SearchString)
-			} else {
-				filters = fmt.Sprintf("(&(objectClass=%s)(%s=%s))",
-					ldap.SearchClass, ldap.MemberAttribute, role.SearchString)
-			}
+			filters = fmt.Sprintf("(&%s%s)", filtersBase, getGroupFilter(ldap, role.SearchString))
 		}
 
 		// paged LDAP request
@@ -158,7 +201,7 @@ func run(ldapId int32) error {
 				controls))
 
 			if err != nil {
-				return err
+				return changes, err
 			}
 
 			for _, entry := range response.Entries {
@@ -170,7 +213,13 @@ func run(ldapId int32) error {
 				if utf8.Valid(keyRaw) {
 					key = string(keyRaw)
 				} else {
-					key = fmt.Sprintf(base64.StdEncoding.EncodeToString(keyRaw))
+					key = base64.StdEncoding.EncodeToString(keyRaw)
+				}
+
+				if deltaAttribute != "" {
+					if v := entry.GetAttributeValue(deltaAttribute); isDeltaValueHigher(v, deltaValueMax) {
+						deltaValueMax = v
+					}
 				}
 
 				l, exists := logins[key]
@@ -246,14 +295,100 @@ func run(ldapId int32) error {
 
 	// import logins
 	for key, l := range logins {
-		log.Info(log.ContextLdap, fmt.Sprintf("processing login '%s' (key: %s, roles: %d)", l.name, key, len(l.roleIds)))
+		if !dryRun {
+			log.Info(log.ContextLdap, fmt.Sprintf("processing login '%s' (key: %s, roles: %d)", l.name, key, len(l.roleIds)))
+		}
 
-		if err := login.SetLdapLogin(ldap, key, l.name, l.active, l.meta, l.roleIds); err != nil {
+		change, changed, err := login.SetLdapLogin(ldap, key, l.name, l.active, l.meta, l.roleIds, dryRun)
+		if err != nil {
 			log.Warning(log.ContextLdap, fmt.Sprintf("failed to import login '%s'", l.name), err)
 			continue
 		}
+		if changed {
+			changes = append(changes, change)
+		}
 	}
 
-	log.Info(log.ContextLdap, fmt.Sprintf("finished login import for '%s'", ldap.Name))
-	return nil
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	if dryRun {
+		return changes, nil
+	}
+
+	if deltaAttribute != "" {
+		if err := setDeltaState(ldap.Id, host, deltaValueMax, !isDelta); err != nil {
+			return changes, err
+		}
+	}
+	syncType := "full"
+	if isDelta {
+		syncType = "delta"
+	}
+	log.Info(log.ContextLdap, fmt.Sprintf("finished %s login import for '%s' (changes: %d)",
+		syncType, ldap.Name, len(changes)))
+
+	return changes, nil
+}
+
+func getGroupFilter(ldap types.Ldap, groupDn string) string {
+	if ldap.MsAdExt {
+		// LDAP_MATCHING_RULE_IN_CHAIN, includes nested group memberships
+		return fmt.Sprintf("(%s:1.2.840.113556.1.4.1941:=%s)", ldap.MemberAttribute, goldap.EscapeFilter(groupDn))
+	}
+	return fmt.Sprintf("(%s=%s)", ldap.MemberAttribute, goldap.EscapeFilter(groupDn))
+}
+
+// returns delta value of last run, empty if full sync is required
+// full sync is required if there is no delta state, if connected host changed or if last full sync is too old
+func getDeltaValue(ldapId int32, host string) (string, error) {
+	var deltaHost, deltaValue pgtype.Text
+	var dateFullSync pgtype.Int8
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT delta_host, delta_value, date_full_sync
+		FROM instance.ldap
+		WHERE id = $1
+	`, ldapId).Scan(&deltaHost, &deltaValue, &dateFullSync); err != nil {
+		return "", err
+	}
+
+	if !deltaValue.Valid || !dateFullSync.Valid || deltaHost.String != host ||
+		dateFullSync.Int64 < tools.GetTimeUnix()-fullSyncIntervalSec {
+		return "", nil
+	}
+	return deltaValue.String, nil
+}
+
+func setDeltaState(ldapId int32, host string, deltaValue string, isFullSync bool) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE instance.ldap
+		SET delta_host = $1, delta_value = NULLIF($2, ''),
+			date_full_sync = CASE WHEN $3 THEN $4 ELSE date_full_sync END
+		WHERE id = $5
+	`, host, deltaValue, isFullSync, tools.GetTimeUnix(), ldapId)
+	return err
+}
+
+// compares delta values, numeric (uSNChanged) or generalized time (modifyTimestamp, same format is compared as text)
+func isDeltaValueHigher(value string, valueMax string) bool {
+	if value == "" {
+		return false
+	}
+	if valueMax == "" {
+		return true
+	}
+	n, errN := strconv.ParseInt(value, 10, 64)
+	nMax, errNMax := strconv.ParseInt(valueMax, 10, 64)
+	if errN == nil && errNMax == nil {
+		return n > nMax
+	}
+	return value > valueMax
 }
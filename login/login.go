@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"r3/cache"
+	"r3/cluster"
 	"r3/db"
 	"r3/handler"
 	"r3/log"
@@ -80,8 +81,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, byId int64, byString string, orderBy
 
 	var qb tools.QueryBuilder
 	qb.UseDollarSigns()
-	qb.AddList("SELECT", []string{"l.id", "l.ldap_id", "l.oauth_client_id", "l.name",
-		"l.admin", "l.limited", "l.no_auth", "l.active", "l.token_expiry_hours"})
+	qb.AddList("SELECT", []string{"l.id", "l.ldap_id", "l.oauth_client_id", "l.scim_client_id", "l.saml_idp_id", "l.name",
+		"l.admin", "l.admin_permissions::TEXT[]", "l.limited", "l.no_auth", "l.active", "l.token_expiry_hours"})
 
 	qb.SetFrom("instance.login AS l")
 
@@ -140,6 +141,10 @@ func Get_tx(ctx context.Context, tx pgx.Tx, byId int64, byString string, orderBy
 			qb.Add("ORDER", fmt.Sprintf("l.limited %s, l.name ASC", orderAscSql))
 		case "oauth":
 			qb.Add("ORDER", fmt.Sprintf("l.oauth_client_id %s, l.name ASC", orderAscSql))
+		case "saml":
+			qb.Add("ORDER", fmt.Sprintf("l.saml_idp_id %s, l.name ASC", orderAscSql))
+		case "scim":
+			qb.Add("ORDER", fmt.Sprintf("l.scim_client_id %s, l.name ASC", orderAscSql))
 		default:
 			qb.Add("ORDER", fmt.Sprintf("l.name %s", orderAscSql))
 		}
@@ -162,7 +167,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, byId int64, byString string, orderBy
 		var l types.LoginAdmin
 		var records []string
 
-		if err := rows.Scan(&l.Id, &l.LdapId, &l.OauthClientId, &l.Name, &l.Admin, &l.Limited,
+		if err := rows.Scan(&l.Id, &l.LdapId, &l.OauthClientId, &l.ScimClientId, &l.SamlIdpId, &l.Name, &l.Admin, &l.AdminPermissions, &l.Limited,
 			&l.NoAuth, &l.Active, &l.TokenExpiryHours, &records); err != nil {
 
 			return logins, 0, err
@@ -276,9 +281,9 @@ func Set_tx(ctx context.Context, tx pgx.Tx, id int64, loginTemplateId pgtype.Int
 		if err := tx.QueryRow(ctx, `
 			INSERT INTO instance.login (
 				ldap_id, ldap_key, oauth_client_id, oauth_iss, oauth_sub, name, salt, hash,
-				salt_kdf, admin, no_auth, limited, active, token_expiry_hours, date_favorites
+				salt_kdf, admin, admin_permissions, no_auth, limited, active, token_expiry_hours, date_favorites
 			)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,0)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,'{}',$11,$12,$13,$14,0)
 			RETURNING id
 		`, ldapId, ldapKey, oauthClientId, oauthIss, oauthSub, name, &salt, &hash,
 			saltKdf, admin, noAuth, isLimited, active, tokenExpiryHours).Scan(&id); err != nil {
@@ -307,7 +312,8 @@ func Set_tx(ctx context.Context, tx pgx.Tx, id int64, loginTemplateId pgtype.Int
 	} else {
 		if _, err := tx.Exec(ctx, `
 			UPDATE instance.login
-			SET name = $1, admin = $2, no_auth = $3, limited = $4, active = $5, token_expiry_hours = $6
+			SET name = $1, admin = $2, no_auth = $3, active = $5, token_expiry_hours = $6,
+				limited = $4 AND CARDINALITY(admin_permissions) = 0 -- logins with admin permissions are never limited
 			WHERE id = $7
 		`, name, admin, noAuth, isLimited, active, tokenExpiryHours, id); err != nil {
 			return 0, err
@@ -534,6 +540,63 @@ func CreateAdmin(username string, password string) error {
 	return tx.Commit(ctx)
 }
 
+// sets admin permissions of login, full admins have all permissions regardless
+// logins with admin permissions are never limited
+// on change, access cache of login is renewed after commit and its sessions are re-authorized, or kicked if permissions were revoked
+func SetAdminPermissions_tx(ctx context.Context, tx pgx.Tx, id int64, permissions []string) error {
+	if permissions == nil {
+		permissions = make([]string, 0)
+	}
+
+	var name string
+	var permissionsEx []string
+	if err := tx.QueryRow(ctx, `
+		SELECT name, admin_permissions::TEXT[]
+		FROM instance.login
+		WHERE id = $1
+	`, id).Scan(&name, &permissionsEx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE instance.login
+		SET admin_permissions = $1::TEXT[]::instance.login_admin_permission[],
+			limited = limited AND CARDINALITY($1::TEXT[]) = 0
+		WHERE id = $2
+	`, permissions, id); err != nil {
+		return err
+	}
+
+	granted, revoked := false, false
+	for _, p := range permissions {
+		if !slices.Contains(permissionsEx, p) {
+			granted = true
+		}
+	}
+	for _, p := range permissionsEx {
+		if !slices.Contains(permissions, p) {
+			revoked = true
+		}
+	}
+	if !granted && !revoked {
+		return nil
+	}
+
+	log.Info(log.ContextServer, fmt.Sprintf("admin permissions of user account '%s' changed, renewing access permissions", name))
+	return cluster.LoginPermissionsChanged_tx(ctx, tx, id, revoked)
+}
+
+// returns whether login is full admin or has any admin permissions
+func GetIsAnyAdmin_tx(ctx context.Context, tx pgx.Tx, id int64) (bool, error) {
+	var isAnyAdmin bool
+	err := tx.QueryRow(ctx, `
+		SELECT admin OR CARDINALITY(admin_permissions) <> 0
+		FROM instance.login
+		WHERE id = $1
+	`, id).Scan(&isAnyAdmin)
+	return isAnyAdmin, err
+}
+
 // reset all TOTP keys
 func ResetTotp_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
 	_, err := tx.Exec(ctx, `
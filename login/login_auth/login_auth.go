@@ -25,6 +25,7 @@ const (
 	loginTypeLocal  loginType = "local"  // auth via credentials, credentials managed in internal login backend
 	loginTypeNoAuth loginType = "noAuth" // auth via login name (public user)
 	loginTypeOauth  loginType = "oauth"  // auth via ext. provider (Open ID connect)
+	loginTypeSaml   loginType = "saml"   // auth via ext. provider (SAML 2.0)
 )
 
 func createToken(loginId int64, name string, admin bool, loginType loginType, tokenExpiryHours pgtype.Int4) (string, error) {
@@ -0,0 +1,203 @@
+package login_auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/log"
+	"r3/login"
+	"r3/login/login_clusterEvent"
+	"r3/login/login_metaMap"
+	"r3/saml"
+	"r3/types"
+	"slices"
+	"sort"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// performs authentication for login by using one-time code of a validated SAML 2.0 response
+// if login is not known but authentication succeeds, login is created
+func Saml(ctx context.Context, code string) (types.LoginAuthResult, error) {
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// code can only be redeemed once
+	samlIdpId, nameId, attributes, err := saml.RedeemCode_tx(ctx, tx, code)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	p, err := cache.GetSamlIdp(samlIdpId)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	// get known login details, unknown login is created
+	var l = types.LoginAuthResult{
+		Admin:     false,
+		Id:        0,
+		MfaTokens: make([]types.LoginMfaToken, 0),
+	}
+	var active bool
+	var tokenExpiryHours pgtype.Int4
+	var roleIds []uuid.UUID
+	var roleIdsEx []uuid.UUID
+	var metaEx types.LoginMeta
+	var limited = false
+	var newLogin = false
+	var adminChanged = false
+	var metaChanged = false
+	var rolesChanged = false
+
+	if err := tx.QueryRow(ctx, `
+		SELECT l.id, l.salt_kdf, l.admin, l.limited, l.token_expiry_hours, l.active, ARRAY(
+				SELECT role_id
+				FROM instance.login_role
+				WHERE login_id = l.id
+				ORDER BY role_id
+			)::UUID[],
+			COALESCE(m.department, ''),
+			COALESCE(m.email, ''),
+			COALESCE(m.location, ''),
+			COALESCE(m.name_display, ''),
+			COALESCE(m.name_fore, ''),
+			COALESCE(m.name_sur, ''),
+			COALESCE(m.notes, ''),
+			COALESCE(m.organization, ''),
+			COALESCE(m.phone_fax, ''),
+			COALESCE(m.phone_landline, ''),
+			COALESCE(m.phone_mobile, '')
+		FROM      instance.login      AS l
+		LEFT JOIN instance.login_meta AS m ON m.login_id = l.id
+		WHERE l.saml_idp_id  = $1
+		AND   l.saml_name_id = $2
+	`, samlIdpId, nameId).Scan(&l.Id, &l.SaltKdf, &l.Admin, &limited, &tokenExpiryHours, &active, &roleIdsEx,
+		&metaEx.Department, &metaEx.Email, &metaEx.Location, &metaEx.NameDisplay, &metaEx.NameFore, &metaEx.NameSur,
+		&metaEx.Notes, &metaEx.Organization, &metaEx.PhoneFax, &metaEx.PhoneLandline, &metaEx.PhoneMobile); err != nil {
+
+		if err == pgx.ErrNoRows {
+			newLogin = true
+		} else {
+			return types.LoginAuthResult{}, err
+		}
+	}
+
+	// read mapped login meta data from assertion attributes, first value is used
+	attributesSingle := make(map[string]any)
+	for k, values := range attributes {
+		if len(values) != 0 {
+			attributesSingle[k] = values[0]
+		}
+	}
+	meta := login_metaMap.ReadMetaFromMapIf(p.LoginMetaMap, attributesSingle)
+	if newLogin {
+		metaEx = meta
+	} else {
+		metaEx, metaChanged = login_metaMap.UpdateChangedMeta(p.LoginMetaMap, metaEx, meta)
+	}
+
+	// log returned attributes for troubleshooting
+	attributesReadable, err := json.MarshalIndent(attributes, "", "\t")
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	log.Info(log.ContextOauth, fmt.Sprintf("SAML authentication successful for name ID '%s', received attributes:\n%s",
+		nameId, attributesReadable))
+
+	// read username from assertion attribute, name ID is used if not defined
+	l.Name = nameId
+	if p.AttributeUsername.Valid && p.AttributeUsername.String != "" {
+		values, ok := attributes[p.AttributeUsername.String]
+		if !ok || len(values) == 0 || values[0] == "" {
+			return types.LoginAuthResult{}, fmt.Errorf("SAML assertion does not contain username attribute '%s'", p.AttributeUsername.String)
+		}
+		l.Name = values[0]
+	}
+
+	// read admin value from assertion attribute, if active
+	if p.AttributeAdmin.Valid && p.AttributeAdminValue.Valid {
+		values, ok := attributes[p.AttributeAdmin.String]
+		if !ok {
+			return types.LoginAuthResult{}, fmt.Errorf("SAML assertion does not contain admin attribute '%s'", p.AttributeAdmin.String)
+		}
+		adminNew := slices.Contains(values, p.AttributeAdminValue.String)
+		if l.Admin != adminNew {
+			l.Admin = adminNew
+			adminChanged = true
+		}
+	}
+
+	if err := preAuthChecks(l.Id, l.Admin, limited, !newLogin); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	// role assignment via attribute values
+	if p.AttributeRoles.Valid && p.AttributeRoles.String != "" {
+		if values, ok := attributes[p.AttributeRoles.String]; ok {
+			// if value is used in any role assignment, assign role
+			for _, assign := range p.LoginRolesAssign {
+				if slices.Contains(values, assign.SearchString) {
+					roleIds = append(roleIds, assign.RoleId)
+				}
+			}
+		}
+		sort.Slice(roleIds, func(i, j int) bool {
+			return roleIds[i].String() < roleIds[j].String()
+		})
+		if !slices.Equal(roleIdsEx, roleIds) {
+			roleIdsEx = roleIds
+			rolesChanged = true
+		}
+	}
+
+	// set login if new or anything changed
+	// inactive users cannot authenticate via SAML, so there is no way to disable users this way
+	//  but if the current active state is disabled, it must re-enable the user
+	if newLogin || adminChanged || metaChanged || rolesChanged || !active {
+		l.Id, err = login.Set_tx(ctx, tx, l.Id, p.LoginTemplateId, pgtype.Int4{}, pgtype.Text{}, pgtype.Int4{},
+			pgtype.Text{}, pgtype.Text{}, l.Name, "", l.Admin, false, true, tokenExpiryHours, metaEx, roleIdsEx,
+			[]types.LoginAdminRecordSet{})
+
+		if err != nil {
+			return types.LoginAuthResult{}, err
+		}
+		if newLogin {
+			if _, err := tx.Exec(ctx, `
+				UPDATE instance.login
+				SET saml_idp_id = $1, saml_name_id = $2
+				WHERE id = $3
+			`, samlIdpId, nameId, l.Id); err != nil {
+				return types.LoginAuthResult{}, err
+			}
+		}
+		if active && rolesChanged {
+			login_clusterEvent.Reauth_tx(ctx, tx, l.Id, l.Name)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	// everything in order, auth successful
+	l.Token, err = createToken(l.Id, l.Name, l.Admin, loginTypeSaml, tokenExpiryHours)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	if err := cache.LoadAccessIfUnknown(l.Id); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	if meta.NameDisplay != "" {
+		l.Name = meta.NameDisplay
+	}
+	return l, nil
+}
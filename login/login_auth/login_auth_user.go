@@ -47,6 +47,7 @@ func User(ctx context.Context, username string, password string, mfaTokenId pgty
 		WHERE l.active
 		AND   l.name            = $1
 		AND   l.oauth_client_id IS NULL
+		AND   l.saml_idp_id     IS NULL
 	`, l.Name).Scan(&l.Id, &ldapId, &salt, &hash, &l.SaltKdf, &l.Admin, &l.NoAuth, &limited, &tokenExpiryHours, &nameDisplay); err != nil {
 
 		if err == pgx.ErrNoRows {
@@ -8,10 +8,12 @@ import (
 const (
 	EntityLdap        = "ldap"
 	EntityOauthClient = "oauth_client"
+	EntitySamlIdp     = "saml_idp"
+	EntityScimClient  = "scim_client"
 )
 
 func ValidateEntity(entity string) error {
-	if !slices.Contains([]string{EntityLdap, EntityOauthClient}, entity) {
+	if !slices.Contains([]string{EntityLdap, EntityOauthClient, EntitySamlIdp, EntityScimClient}, entity) {
 		return fmt.Errorf("invalid external login entity '%s'", entity)
 	}
 	return nil
@@ -9,6 +9,7 @@ import (
 	"r3/login/login_meta"
 	"r3/login/login_metaMap"
 	"r3/types"
+	"slices"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
@@ -18,8 +19,16 @@ import (
 // updates internal login backend with logins from LDAP
 // uses unique key value to update login record
 // can optionally update login roles
-func SetLdapLogin(ldap types.Ldap, ldapKey string, name string,
-	active bool, meta types.LoginMeta, roleIds []uuid.UUID) error {
+// returns applied changes (or changes that would be applied if dry run) and whether anything changed
+func SetLdapLogin(ldap types.Ldap, ldapKey string, name string, active bool,
+	meta types.LoginMeta, roleIds []uuid.UUID, dryRun bool) (types.LdapImportChange, bool, error) {
+
+	var change = types.LdapImportChange{
+		Key:            ldapKey,
+		Name:           name,
+		RoleIdsAdded:   make([]uuid.UUID, 0),
+		RoleIdsRemoved: make([]uuid.UUID, 0),
+	}
 
 	// existing login details
 	var loginId int64
@@ -36,7 +45,7 @@ func SetLdapLogin(ldap types.Ldap, ldapKey string, name string,
 
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
-		return err
+		return change, false, err
 	}
 	defer tx.Rollback(ctx)
 
@@ -61,7 +70,7 @@ func SetLdapLogin(ldap types.Ldap, ldapKey string, name string,
 		&adminEx, &activeEx, &roleIdsEx, &rolesEqual)
 
 	if err != nil && err != pgx.ErrNoRows {
-		return err
+		return change, false, err
 	}
 
 	newLogin := err == pgx.ErrNoRows
@@ -75,14 +84,36 @@ func SetLdapLogin(ldap types.Ldap, ldapKey string, name string,
 	} else {
 		metaEx, err = login_meta.Get_tx(ctx, tx, loginId)
 		if err != nil {
-			return err
+			return change, false, err
 		}
 		metaEx, metaChanged = login_metaMap.UpdateChangedMeta(ldap.LoginMetaMap, metaEx, meta)
 	}
 
 	// abort if no changes are there to apply
 	if !newLogin && nameEx == name && activeEx == active && !rolesChanged && !metaChanged {
-		return nil
+		return change, false, nil
+	}
+
+	change.Create = newLogin
+	change.Change = !newLogin && (nameEx != name || metaChanged)
+	change.Disable = !newLogin && activeEx && !active
+	change.Enable = !newLogin && !activeEx && active
+
+	if rolesChanged {
+		for _, roleId := range roleIds {
+			if !slices.Contains(roleIdsEx, roleId) {
+				change.RoleIdsAdded = append(change.RoleIdsAdded, roleId)
+			}
+		}
+		for _, roleId := range roleIdsEx {
+			if !slices.Contains(roleIds, roleId) {
+				change.RoleIdsRemoved = append(change.RoleIdsRemoved, roleId)
+			}
+		}
+	}
+
+	if dryRun {
+		return change, true, nil
 	}
 
 	// update if name, active state or roles changed
@@ -99,7 +130,7 @@ func SetLdapLogin(ldap types.Ldap, ldapKey string, name string,
 		pgtype.Text{}, pgtype.Text{}, name, "", adminEx, false, active, pgtype.Int4{}, metaEx, roleIdsEx,
 		[]types.LoginAdminRecordSet{}); err != nil {
 
-		return err
+		return change, false, err
 	}
 
 	if active && rolesChanged {
@@ -108,5 +139,5 @@ func SetLdapLogin(ldap types.Ldap, ldapKey string, name string,
 	if !active && activeEx {
 		login_clusterEvent.Kick_tx(ctx, tx, loginId, name)
 	}
-	return tx.Commit(ctx)
+	return change, true, tx.Commit(ctx)
 }
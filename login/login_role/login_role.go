@@ -50,6 +50,17 @@ func Set_tx(ctx context.Context, tx pgx.Tx, loginId int64, roleIds []uuid.UUID)
 	return nil
 }
 
+// returns IDs of all logins with the role, including inactive ones
+func GetRoleLogins_tx(ctx context.Context, tx pgx.Tx, roleId uuid.UUID) ([]int64, error) {
+	loginIds := make([]int64, 0)
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(ARRAY_AGG(login_id), '{}')
+		FROM instance.login_role
+		WHERE role_id = $1
+	`, roleId).Scan(&loginIds)
+	return loginIds, err
+}
+
 func SetRoleLogins_tx(ctx context.Context, tx pgx.Tx, roleId uuid.UUID, loginIds []int64) error {
 
 	if _, err := tx.Exec(ctx, `
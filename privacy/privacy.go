@@ -0,0 +1,320 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// data subjects are persons whose data is stored in the instance
+// records referencing a data subject are found by following relationship attributes across all modules
+
+var (
+	findDepthMax = 10 // maximum number of relationship hops from data subject
+	strategies   = []string{"delete", "anonymize", "keep"}
+)
+
+func Get_tx(ctx context.Context, tx pgx.Tx) ([]types.PrivacyRelation, error) {
+	relations := make([]types.PrivacyRelation, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT relation_id, person, attribute_id_login, strategy, attribute_ids_anonymize
+		FROM instance.privacy_relation
+	`)
+	if err != nil {
+		return relations, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r types.PrivacyRelation
+		if err := rows.Scan(&r.RelationId, &r.Person, &r.AttributeIdLogin,
+			&r.Strategy, &r.AttributeIdsAnonymize); err != nil {
+
+			return relations, err
+		}
+		relations = append(relations, r)
+	}
+	return relations, nil
+}
+
+func Set_tx(ctx context.Context, tx pgx.Tx, relations []types.PrivacyRelation) error {
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM instance.privacy_relation`); err != nil {
+		return err
+	}
+
+	for _, r := range relations {
+		if r.AttributeIdsAnonymize == nil {
+			r.AttributeIdsAnonymize = make([]uuid.UUID, 0)
+		}
+		if err := check(r); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.privacy_relation (relation_id, person,
+				attribute_id_login, strategy, attribute_ids_anonymize)
+			VALUES ($1,$2,$3,$4,$5)
+		`, r.RelationId, r.Person, r.AttributeIdLogin, r.Strategy, r.AttributeIdsAnonymize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func check(r types.PrivacyRelation) error {
+
+	rel, exists := cache.RelationIdMap[r.RelationId]
+	if !exists {
+		return handler.ErrSchemaUnknownRelation(r.RelationId)
+	}
+	if !slices.Contains(strategies, r.Strategy) {
+		return fmt.Errorf("invalid erasure strategy '%s'", r.Strategy)
+	}
+
+	if r.AttributeIdLogin.Valid {
+		atr, exists := cache.AttributeIdMap[r.AttributeIdLogin.Bytes]
+		if !exists {
+			return handler.ErrSchemaUnknownAttribute(r.AttributeIdLogin.Bytes)
+		}
+		if !r.Person || atr.RelationId != rel.Id || !schema.IsContentNumber(atr.Content) {
+			return errors.New("login attribute must be an integer attribute of the person relation")
+		}
+	}
+
+	for _, id := range r.AttributeIdsAnonymize {
+		atr, exists := cache.AttributeIdMap[id]
+		if !exists {
+			return handler.ErrSchemaUnknownAttribute(id)
+		}
+		if atr.RelationId != rel.Id || atr.Id == rel.AttributeIdPk {
+			return fmt.Errorf("attribute '%s' cannot be anonymized for relation '%s'", atr.Name, rel.Name)
+		}
+		if !atr.Nullable && !schema.IsContentFiles(atr.Content) {
+			return fmt.Errorf("attribute '%s' cannot be anonymized as it is not nullable", atr.Name)
+		}
+	}
+	return nil
+}
+
+// returns records of data subject, grouped by relation
+func Find_tx(ctx context.Context, tx pgx.Tx, subject types.PrivacySubject) ([]types.PrivacyRecords, error) {
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	return find_tx(ctx, tx, subject)
+}
+
+// finds records of data subject and all records referencing them, directly or via other records
+// records referenced by found records are included if their relation has privacy settings (like addresses of a person)
+// referenced records are not followed back, as other data subjects might reference them as well
+// records of the person relation are only included for the data subject itself, other persons might reference it
+// expects schema lock to be held
+func find_tx(ctx context.Context, tx pgx.Tx, subject types.PrivacySubject) ([]types.PrivacyRecords, error) {
+
+	settings, err := Get_tx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	relationIdMapStrategy := make(map[uuid.UUID]string)
+	relationIdPerson := uuid.Nil
+	for _, s := range settings {
+		relationIdMapStrategy[s.RelationId] = s.Strategy
+		if s.Person {
+			relationIdPerson = s.RelationId
+		}
+	}
+
+	found := make([]types.PrivacyRecords, 0)
+	relationIdMapIndex := make(map[uuid.UUID]int)
+	relationIdMapIndexReferenced := make(map[uuid.UUID]int)
+
+	// adds records, returns records not found before
+	var add = func(relationId uuid.UUID, recordIds []int64, referenced bool) []int64 {
+		indexMap := relationIdMapIndex
+		if referenced {
+			indexMap = relationIdMapIndexReferenced
+		}
+		index, exists := indexMap[relationId]
+		if !exists {
+			strategy, exists := relationIdMapStrategy[relationId]
+			if !exists {
+				strategy = "keep"
+			}
+			found = append(found, types.PrivacyRecords{
+				RelationId: relationId,
+				RecordIds:  make([]int64, 0),
+				Referenced: referenced,
+				Strategy:   strategy,
+			})
+			index = len(found) - 1
+			indexMap[relationId] = index
+		}
+
+		recordIdsNew := make([]int64, 0)
+		for _, id := range recordIds {
+			if !slices.Contains(found[index].RecordIds, id) {
+				found[index].RecordIds = append(found[index].RecordIds, id)
+				recordIdsNew = append(recordIdsNew, id)
+			}
+		}
+		return recordIdsNew
+	}
+
+	// collect records of data subject
+	relationIdMapRecordIds := make(map[uuid.UUID][]int64)
+
+	if subject.RelationId.Valid && subject.RecordId.Valid {
+		relationIdMapRecordIds[subject.RelationId.Bytes] = []int64{subject.RecordId.Int64}
+	}
+	if subject.LoginId.Valid {
+		// records assigned to login via person relation & login forms of modules
+		attributeIdsLogin := make([]uuid.UUID, 0)
+		for _, s := range settings {
+			if s.Person && s.AttributeIdLogin.Valid {
+				attributeIdsLogin = append(attributeIdsLogin, s.AttributeIdLogin.Bytes)
+			}
+		}
+		for _, mod := range cache.ModuleIdMap {
+			for _, lf := range mod.LoginForms {
+				if !slices.Contains(attributeIdsLogin, lf.AttributeIdLogin) {
+					attributeIdsLogin = append(attributeIdsLogin, lf.AttributeIdLogin)
+				}
+			}
+		}
+
+		for _, atrId := range attributeIdsLogin {
+			atr, exists := cache.AttributeIdMap[atrId]
+			if !exists {
+				return nil, handler.ErrSchemaUnknownAttribute(atrId)
+			}
+			recordIds, err := getRecordIds_tx(ctx, tx, atr, subject.LoginId.Int64)
+			if err != nil {
+				return nil, err
+			}
+			if len(recordIds) != 0 {
+				relationIdMapRecordIds[atr.RelationId] = append(relationIdMapRecordIds[atr.RelationId], recordIds...)
+			}
+		}
+	}
+
+	for relationId, recordIds := range relationIdMapRecordIds {
+		add(relationId, recordIds, false)
+	}
+
+	// follow references to & from found records, breadth first
+	relationIdMapRecordIdsReferenced := make(map[uuid.UUID][]int64)
+	for depth := 0; depth < findDepthMax && (len(relationIdMapRecordIds) != 0 || len(relationIdMapRecordIdsReferenced) != 0); depth++ {
+		relationIdMapRecordIdsNext := make(map[uuid.UUID][]int64)
+		relationIdMapRecordIdsReferencedNext := make(map[uuid.UUID][]int64)
+
+		// records referencing found records
+		for relationId, recordIds := range relationIdMapRecordIds {
+			for _, atr := range cache.AttributeIdMap {
+				if !atr.RelationshipId.Valid || atr.RelationshipId.Bytes != relationId ||
+					!schema.IsContentRelationship(atr.Content) || atr.RelationId == relationIdPerson {
+
+					continue
+				}
+
+				recordIdsRef, err := getRecordIds_tx(ctx, tx, atr, recordIds)
+				if err != nil {
+					return nil, err
+				}
+				if recordIdsNew := add(atr.RelationId, recordIdsRef, false); len(recordIdsNew) != 0 {
+					relationIdMapRecordIdsNext[atr.RelationId] = append(relationIdMapRecordIdsNext[atr.RelationId], recordIdsNew...)
+				}
+			}
+		}
+
+		// records referenced by found records, only for relations with privacy settings
+		for _, m := range []map[uuid.UUID][]int64{relationIdMapRecordIds, relationIdMapRecordIdsReferenced} {
+			for relationId, recordIds := range m {
+				for _, atr := range cache.AttributeIdMap {
+					if atr.RelationId != relationId || !atr.RelationshipId.Valid ||
+						!schema.IsContentRelationship(atr.Content) || atr.RelationshipId.Bytes == relationIdPerson {
+
+						continue
+					}
+					if _, exists := relationIdMapStrategy[atr.RelationshipId.Bytes]; !exists {
+						continue
+					}
+
+					recordIdsRef, err := getRecordIdsReferenced_tx(ctx, tx, atr, recordIds)
+					if err != nil {
+						return nil, err
+					}
+					relationIdRef := uuid.UUID(atr.RelationshipId.Bytes)
+					if recordIdsNew := add(relationIdRef, recordIdsRef, true); len(recordIdsNew) != 0 {
+						relationIdMapRecordIdsReferencedNext[relationIdRef] = append(relationIdMapRecordIdsReferencedNext[relationIdRef], recordIdsNew...)
+					}
+				}
+			}
+		}
+		relationIdMapRecordIds = relationIdMapRecordIdsNext
+		relationIdMapRecordIdsReferenced = relationIdMapRecordIdsReferencedNext
+	}
+
+	// remove relations without records
+	return slices.DeleteFunc(found, func(r types.PrivacyRecords) bool { return len(r.RecordIds) == 0 }), nil
+}
+
+// returns IDs of records whose attribute matches value (or any value, if array)
+func getRecordIds_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute, value any) ([]int64, error) {
+	rel, exists := cache.RelationIdMap[atr.RelationId]
+	if !exists {
+		return nil, handler.ErrSchemaUnknownRelation(atr.RelationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return nil, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	operator := "="
+	if _, isArray := value.([]int64); isArray {
+		operator = "= ANY"
+	}
+
+	recordIds := make([]int64, 0)
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COALESCE(ARRAY_AGG("%s"), '{}')
+		FROM "%s"."%s"
+		WHERE "%s" %s($1)
+	`, schema.PkName, mod.Name, rel.Name, atr.Name, operator), value).Scan(&recordIds)
+
+	return recordIds, err
+}
+
+// returns IDs of records referenced by the attribute of the given records
+func getRecordIdsReferenced_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute, recordIds []int64) ([]int64, error) {
+	rel, exists := cache.RelationIdMap[atr.RelationId]
+	if !exists {
+		return nil, handler.ErrSchemaUnknownRelation(atr.RelationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return nil, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	recordIdsRef := make([]int64, 0)
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COALESCE(ARRAY_AGG(DISTINCT "%s"), '{}')
+		FROM "%s"."%s"
+		WHERE "%s" = ANY($1)
+		AND   "%s" IS NOT NULL
+	`, atr.Name, mod.Name, rel.Name, schema.PkName, atr.Name), recordIds).Scan(&recordIdsRef)
+
+	return recordIdsRef, err
+}
@@ -0,0 +1,492 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"r3/cache"
+	"r3/data"
+	"r3/handler"
+	"r3/login"
+	"r3/schema"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// erases records of data subject according to erasure strategies of their relations
+// change logs, recycle bin entries & search index entries of erased values are removed as well
+// files of erased records are deleted if no other records reference them
+// referenced records (like addresses) are only erased if no records outside of the data subject reference them
+// returns the affected records
+func Erase_tx(ctx context.Context, tx pgx.Tx, subject types.PrivacySubject, delLogin bool) ([]types.PrivacyRecords, error) {
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	found, err := find_tx(ctx, tx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := Get_tx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	relationIdMapAnonymize := make(map[uuid.UUID][]uuid.UUID)
+	for _, s := range settings {
+		relationIdMapAnonymize[s.RelationId] = s.AttributeIdsAnonymize
+	}
+
+	// referenced records shared with other data subjects are kept
+	for i, f := range found {
+		if !f.Referenced {
+			continue
+		}
+		found[i].RecordIds, err = getRecordIdsUnshared_tx(ctx, tx, f, found)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// records found later reference records found earlier, erase in reverse order
+	// referenced records are referenced by records found earlier, erase them afterwards in found order
+	order := make([]types.PrivacyRecords, 0, len(found))
+	for _, f := range slices.Backward(found) {
+		if !f.Referenced {
+			order = append(order, f)
+		}
+	}
+	for _, f := range found {
+		if f.Referenced {
+			order = append(order, f)
+		}
+	}
+
+	fileIds := make([]uuid.UUID, 0)
+	for _, f := range order {
+		if len(f.RecordIds) == 0 {
+			continue
+		}
+		rel, exists := cache.RelationIdMap[f.RelationId]
+		if !exists {
+			return nil, handler.ErrSchemaUnknownRelation(f.RelationId)
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			return nil, handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+
+		switch f.Strategy {
+		case "delete":
+			fileIdsRel, err := getFileIdsDelete_tx(ctx, tx, rel, f.RecordIds)
+			if err != nil {
+				return nil, err
+			}
+			fileIds = append(fileIds, fileIdsRel...)
+
+			if err := eraseDelete_tx(ctx, tx, mod, rel, f.RecordIds); err != nil {
+				return nil, err
+			}
+		case "anonymize":
+			fileIdsRel, err := getFileIds_tx(ctx, tx, relationIdMapAnonymize[rel.Id], f.RecordIds)
+			if err != nil {
+				return nil, err
+			}
+			fileIds = append(fileIds, fileIdsRel...)
+
+			if err := eraseAnonymize_tx(ctx, tx, mod, rel, f.RecordIds, relationIdMapAnonymize[rel.Id]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if delLogin && subject.LoginId.Valid {
+		if err := login.Del_tx(ctx, tx, subject.LoginId.Int64); err != nil {
+			return nil, err
+		}
+	}
+
+	// delete files last, they cannot be restored if the transaction fails afterwards
+	if err := eraseFiles_tx(ctx, tx, fileIds); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// returns IDs of referenced records that are not referenced by records outside of the found records
+func getRecordIdsUnshared_tx(ctx context.Context, tx pgx.Tx, f types.PrivacyRecords, found []types.PrivacyRecords) ([]int64, error) {
+	recordIds := f.RecordIds
+
+	for _, atr := range cache.AttributeIdMap {
+		if len(recordIds) == 0 {
+			break
+		}
+		if !atr.RelationshipId.Valid || atr.RelationshipId.Bytes != f.RelationId ||
+			!schema.IsContentRelationship(atr.Content) {
+
+			continue
+		}
+		rel, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			return nil, handler.ErrSchemaUnknownRelation(atr.RelationId)
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			return nil, handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+
+		recordIdsFound := make([]int64, 0)
+		for _, fr := range found {
+			if fr.RelationId == rel.Id {
+				recordIdsFound = append(recordIdsFound, fr.RecordIds...)
+			}
+		}
+
+		recordIdsShared := make([]int64, 0)
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT COALESCE(ARRAY_AGG(DISTINCT "%s"), '{}')
+			FROM "%s"."%s"
+			WHERE "%s" = ANY($1)
+			AND   "%s" <> ALL($2)
+		`, atr.Name, mod.Name, rel.Name, atr.Name, schema.PkName), recordIds, recordIdsFound).Scan(&recordIdsShared); err != nil {
+			return nil, err
+		}
+
+		recordIds = slices.DeleteFunc(slices.Clone(recordIds), func(id int64) bool {
+			return slices.Contains(recordIdsShared, id)
+		})
+	}
+	return recordIds, nil
+}
+
+// returns IDs of files assigned to records of the relation, including files of records deleted via cascading relationships
+// records deleted via cascade are not known beforehand, all their files are included
+// files are only deleted if they lost all references, see eraseFiles_tx
+func getFileIdsDelete_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, recordIds []int64) ([]uuid.UUID, error) {
+
+	attributeIds := make([]uuid.UUID, 0)
+	for _, atr := range rel.Attributes {
+		attributeIds = append(attributeIds, atr.Id)
+	}
+	fileIds, err := getFileIds_tx(ctx, tx, attributeIds, recordIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, relCascade := range getRelationsCascading(rel.Id) {
+		for _, atr := range relCascade.Attributes {
+			if !schema.IsContentFiles(atr.Content) {
+				continue
+			}
+			fileIdsCascade := make([]uuid.UUID, 0)
+			if err := tx.QueryRow(ctx, fmt.Sprintf(`
+				SELECT COALESCE(ARRAY_AGG(DISTINCT file_id), '{}')
+				FROM instance_file."%s"
+			`, schema.GetFilesTableName(atr.Id))).Scan(&fileIdsCascade); err != nil {
+				return nil, err
+			}
+			fileIds = append(fileIds, fileIdsCascade...)
+		}
+	}
+
+	// files of deleted records in recycle bin
+	fileIdsRecycle := make([]uuid.UUID, 0)
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(ARRAY_AGG(DISTINCT f.file_id), '{}')
+		FROM instance.data_recycle_file AS f
+		JOIN instance.data_recycle      AS r ON r.id = f.data_recycle_id
+		WHERE r.relation_id = $1
+		AND   r.record_id   = ANY($2)
+	`, rel.Id, recordIds).Scan(&fileIdsRecycle); err != nil {
+		return nil, err
+	}
+	return append(fileIds, fileIdsRecycle...), nil
+}
+
+// returns IDs of files assigned to records via the given attributes, non-file attributes are ignored
+func getFileIds_tx(ctx context.Context, tx pgx.Tx, attributeIds []uuid.UUID, recordIds []int64) ([]uuid.UUID, error) {
+	fileIds := make([]uuid.UUID, 0)
+	for _, id := range attributeIds {
+		atr, exists := cache.AttributeIdMap[id]
+		if !exists {
+			return nil, handler.ErrSchemaUnknownAttribute(id)
+		}
+		if !schema.IsContentFiles(atr.Content) {
+			continue
+		}
+
+		fileIdsAtr := make([]uuid.UUID, 0)
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			SELECT COALESCE(ARRAY_AGG(DISTINCT file_id), '{}')
+			FROM instance_file."%s"
+			WHERE record_id = ANY($1)
+		`, schema.GetFilesTableName(atr.Id)), recordIds).Scan(&fileIdsAtr); err != nil {
+			return nil, err
+		}
+		fileIds = append(fileIds, fileIdsAtr...)
+	}
+	return fileIds, nil
+}
+
+// deletes the given files including all versions & thumbnails, if no records reference them anymore
+func eraseFiles_tx(ctx context.Context, tx pgx.Tx, fileIds []uuid.UUID) error {
+	if len(fileIds) == 0 {
+		return nil
+	}
+
+	type fileVersion struct {
+		fileId  uuid.UUID
+		version int64
+	}
+	fileVersions := make([]fileVersion, 0)
+	fileIdsDel := make([]uuid.UUID, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT f.id, v.version
+		FROM instance.file AS f
+		LEFT JOIN instance.file_version AS v ON v.file_id = f.id
+		WHERE f.id = ANY($1)
+		AND   f.ref_counter = 0
+	`, fileIds)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var fv fileVersion
+		var version pgtype.Int8
+		if err := rows.Scan(&fv.fileId, &version); err != nil {
+			rows.Close()
+			return err
+		}
+		if !slices.Contains(fileIdsDel, fv.fileId) {
+			fileIdsDel = append(fileIdsDel, fv.fileId)
+		}
+		if version.Valid {
+			fv.version = version.Int64
+			fileVersions = append(fileVersions, fv)
+		}
+	}
+	rows.Close()
+
+	// file versions & their texts are deleted via cascade
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.file
+		WHERE id = ANY($1)
+	`, fileIdsDel); err != nil {
+		return err
+	}
+
+	for _, fv := range fileVersions {
+		if err := eraseFilePath(data.GetFilePathVersion(fv.fileId, fv.version)); err != nil {
+			return err
+		}
+	}
+	for _, id := range fileIdsDel {
+		if err := eraseFilePath(data.GetFilePathThumb(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eraseFilePath(filePath string) error {
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func eraseDelete_tx(ctx context.Context, tx pgx.Tx, mod types.Module, rel types.Relation, recordIds []int64) error {
+
+	// records deleted via cascading relationships are gone after deletion, get them first
+	recordIdsByRelation, err := getRecordsCascading_tx(ctx, tx, rel.Id, recordIds)
+	if err != nil {
+		return err
+	}
+
+	// file assignments are removed with their records
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		DELETE FROM "%s"."%s"
+		WHERE "%s" = ANY($1)
+	`, mod.Name, rel.Name, schema.PkName), recordIds); err != nil {
+		return err
+	}
+
+	// remove search index entries, change logs & recycle bin entries of deleted records, including records deleted via cascading relationships
+	for relationId, ids := range recordIdsByRelation {
+		if err := data.SearchIndexDel_tx(ctx, tx, relationId, ids); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.data_log
+			WHERE relation_id    = $1
+			AND   record_id_wofk = ANY($2)
+		`, relationId, ids); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.data_recycle
+			WHERE relation_id = $1
+			AND   record_id   = ANY($2)
+		`, relationId, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eraseAnonymize_tx(ctx context.Context, tx pgx.Tx, mod types.Module, rel types.Relation,
+	recordIds []int64, attributeIds []uuid.UUID) error {
+
+	if len(attributeIds) == 0 {
+		return nil
+	}
+
+	sets := make([]string, 0)
+	for _, id := range attributeIds {
+		atr, exists := cache.AttributeIdMap[id]
+		if !exists {
+			return handler.ErrSchemaUnknownAttribute(id)
+		}
+
+		if schema.IsContentFiles(atr.Content) {
+			if _, err := tx.Exec(ctx, fmt.Sprintf(`
+				DELETE FROM instance_file."%s"
+				WHERE record_id = ANY($1)
+			`, schema.GetFilesTableName(atr.Id)), recordIds); err != nil {
+				return err
+			}
+			continue
+		}
+		sets = append(sets, fmt.Sprintf(`"%s" = NULL`, atr.Name))
+	}
+
+	if len(sets) != 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			UPDATE "%s"."%s"
+			SET %s
+			WHERE "%s" = ANY($1)
+		`, mod.Name, rel.Name, strings.Join(sets, ", "), schema.PkName), recordIds); err != nil {
+			return err
+		}
+	}
+
+	// anonymized values must not be found via search index
+	if err := data.SearchIndexSet_tx(ctx, tx, rel, recordIds); err != nil {
+		return err
+	}
+
+	// remove logged values of anonymized attributes, then logs without any values left
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.data_log_value
+		WHERE attribute_id = ANY($3)
+		AND   data_log_id IN (
+			SELECT id
+			FROM instance.data_log
+			WHERE relation_id    = $1
+			AND   record_id_wofk = ANY($2)
+		)
+	`, rel.Id, recordIds, attributeIds); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.data_log AS l
+		WHERE l.relation_id    = $1
+		AND   l.record_id_wofk = ANY($2)
+		AND   l.comment IS NULL
+		AND   NOT EXISTS (
+			SELECT 1
+			FROM instance.data_log_value
+			WHERE data_log_id = l.id
+		)
+	`, rel.Id, recordIds)
+	return err
+}
+
+// returns relations whose records are deleted via cascading relationships when records of the given relation are deleted
+func getRelationsCascading(relationId uuid.UUID) []types.Relation {
+	relationIds := []uuid.UUID{relationId}
+	relations := make([]types.Relation, 0)
+
+	for i := 0; i < len(relationIds); i++ {
+		for _, atr := range cache.AttributeIdMap {
+			if !atr.RelationshipId.Valid || atr.RelationshipId.Bytes != relationIds[i] ||
+				atr.OnDelete != "CASCADE" || slices.Contains(relationIds, atr.RelationId) {
+
+				continue
+			}
+			if rel, exists := cache.RelationIdMap[atr.RelationId]; exists {
+				relationIds = append(relationIds, rel.Id)
+				relations = append(relations, rel)
+			}
+		}
+	}
+	return relations
+}
+
+// returns IDs of records by relation, that are deleted via cascading relationships when the given records are deleted, including the given records
+// recycle bin entries of records that referenced deleted records via cascading relationships are removed, as they cannot be restored anymore
+func getRecordsCascading_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordIds []int64) (map[uuid.UUID][]int64, error) {
+
+	type cascadeRecords struct {
+		relationId uuid.UUID
+		recordIds  []int64
+	}
+	recordIdsByRelation := map[uuid.UUID][]int64{relationId: recordIds}
+	queue := []cascadeRecords{{relationId, recordIds}}
+
+	for len(queue) != 0 {
+		parent := queue[0]
+		queue = queue[1:]
+
+		for _, atr := range cache.AttributeIdMap {
+			if !atr.RelationshipId.Valid || atr.RelationshipId.Bytes != parent.relationId || atr.OnDelete != "CASCADE" {
+				continue
+			}
+			rel, exists := cache.RelationIdMap[atr.RelationId]
+			if !exists {
+				return nil, handler.ErrSchemaUnknownRelation(atr.RelationId)
+			}
+			mod, exists := cache.ModuleIdMap[rel.ModuleId]
+			if !exists {
+				return nil, handler.ErrSchemaUnknownModule(rel.ModuleId)
+			}
+
+			if _, err := tx.Exec(ctx, `
+				DELETE FROM instance.data_recycle
+				WHERE relation_id = $1
+				AND   (record_values->>$2::TEXT)::BIGINT = ANY($3)
+			`, rel.Id, atr.Name, parent.recordIds); err != nil {
+				return nil, err
+			}
+
+			// records already known are skipped (self-referencing relationships)
+			ids := make([]int64, 0)
+			if err := tx.QueryRow(ctx, fmt.Sprintf(`
+				SELECT COALESCE(ARRAY_AGG("%s"), '{}')
+				FROM "%s"."%s"
+				WHERE "%s" = ANY($1)
+				AND NOT "%s" = ANY($2)
+			`, schema.PkName, mod.Name, rel.Name, atr.Name, schema.PkName),
+				parent.recordIds, recordIdsByRelation[rel.Id]).Scan(&ids); err != nil {
+				return nil, err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			recordIdsByRelation[rel.Id] = append(recordIdsByRelation[rel.Id], ids...)
+			queue = append(queue, cascadeRecords{rel.Id, ids})
+		}
+	}
+	return recordIdsByRelation, nil
+}
@@ -0,0 +1,215 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"r3/cache"
+	"r3/data"
+	"r3/db"
+	"r3/handler"
+	"r3/schema"
+	"r3/tools"
+	"r3/types"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type exportFile struct {
+	Name string `json:"name"`
+	Path string `json:"path"` // path of file within export archive
+}
+type exportRelation struct {
+	Module   string           `json:"module"`
+	Relation string           `json:"relation"`
+	Strategy string           `json:"strategy"` // erasure strategy of relation
+	Records  []map[string]any `json:"records"`
+}
+type exportSubject struct {
+	DateExport int64            `json:"dateExport"`
+	Login      json.RawMessage  `json:"login"` // login with meta data & roles, null if data subject has no login
+	Relations  []exportRelation `json:"relations"`
+}
+
+// writes ZIP archive with all data of data subject to writer
+// record values are stored as JSON, files are included in their latest version
+// encrypted values are exported as stored, they can only be decrypted by their owners
+func Export(ctx context.Context, w io.Writer, subject types.PrivacySubject) error {
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	found, err := find_tx(ctx, tx, subject)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	out := exportSubject{
+		DateExport: tools.GetTimeUnix(),
+		Login:      json.RawMessage("null"),
+		Relations:  make([]exportRelation, 0),
+	}
+
+	if subject.LoginId.Valid {
+		if err := tx.QueryRow(ctx, `
+			SELECT JSONB_BUILD_OBJECT(
+				'id',   l.id,
+				'name', l.name,
+				'meta', TO_JSONB(m) - 'login_id',
+				'roles', ARRAY(
+					SELECT CONCAT(mo.name, '.', r.name)
+					FROM instance.login_role AS lr
+					JOIN app.role            AS r  ON r.id  = lr.role_id
+					JOIN app.module          AS mo ON mo.id = r.module_id
+					WHERE lr.login_id = l.id
+					ORDER BY 1
+				)
+			)
+			FROM instance.login           AS l
+			LEFT JOIN instance.login_meta AS m ON m.login_id = l.id
+			WHERE l.id = $1
+		`, subject.LoginId.Int64).Scan(&out.Login); err != nil && err != pgx.ErrNoRows {
+			return err
+		}
+	}
+
+	for _, f := range found {
+		rel, exists := cache.RelationIdMap[f.RelationId]
+		if !exists {
+			return handler.ErrSchemaUnknownRelation(f.RelationId)
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			return handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+
+		r := exportRelation{
+			Module:   mod.Name,
+			Relation: rel.Name,
+			Strategy: f.Strategy,
+			Records:  make([]map[string]any, 0),
+		}
+
+		recordIds := make([]int64, 0)
+		rows, err := tx.Query(ctx, fmt.Sprintf(`
+			SELECT "t"."%s", TO_JSONB("t")
+			FROM "%s"."%s" AS "t"
+			WHERE "t"."%s" = ANY($1)
+			ORDER BY "t"."%s" ASC
+		`, schema.PkName, mod.Name, rel.Name, schema.PkName, schema.PkName), f.RecordIds)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var recordId int64
+			var record map[string]any
+			if err := rows.Scan(&recordId, &record); err != nil {
+				rows.Close()
+				return err
+			}
+			recordIds = append(recordIds, recordId)
+			r.Records = append(r.Records, record)
+		}
+		rows.Close()
+
+		// add files to archive, reference them in their records
+		for _, atr := range rel.Attributes {
+			if !schema.IsContentFiles(atr.Content) {
+				continue
+			}
+			for i, recordId := range recordIds {
+				files, err := exportFiles_tx(ctx, tx, zw, atr, recordId,
+					path.Join("files", mod.Name, rel.Name, fmt.Sprintf("%d", recordId), atr.Name))
+
+				if err != nil {
+					return err
+				}
+				r.Records[i][atr.Name] = files
+			}
+		}
+		out.Relations = append(out.Relations, r)
+	}
+
+	fw, err := zw.Create("subject.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func exportFiles_tx(ctx context.Context, tx pgx.Tx, zw *zip.Writer, atr types.Attribute,
+	recordId int64, dir string) ([]exportFile, error) {
+
+	files := make([]exportFile, 0)
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT r.file_id, r.name, (
+			SELECT MAX(v.version)
+			FROM instance.file_version AS v
+			WHERE v.file_id = r.file_id
+		)
+		FROM instance_file."%s" AS r
+		WHERE r.record_id = $1
+		ORDER BY r.name ASC
+	`, schema.GetFilesTableName(atr.Id)), recordId)
+	if err != nil {
+		return files, err
+	}
+
+	type file struct {
+		id      uuid.UUID
+		name    string
+		version int64
+	}
+	filesDb := make([]file, 0)
+	for rows.Next() {
+		var f file
+		if err := rows.Scan(&f.id, &f.name, &f.version); err != nil {
+			rows.Close()
+			return files, err
+		}
+		filesDb = append(filesDb, f)
+	}
+	rows.Close()
+
+	for _, f := range filesDb {
+		// file IDs keep paths unique, as file names can repeat
+		name := strings.NewReplacer("/", "_", "\\", "_").Replace(f.name)
+		filePath := path.Join(dir, f.id.String(), name)
+
+		fr, err := os.Open(data.GetFilePathVersion(f.id, f.version))
+		if err != nil {
+			return files, err
+		}
+		fw, err := zw.Create(filePath)
+		if err != nil {
+			fr.Close()
+			return files, err
+		}
+		_, err = io.Copy(fw, fr)
+		fr.Close()
+		if err != nil {
+			return files, err
+		}
+		files = append(files, exportFile{Name: f.name, Path: filePath})
+	}
+	return files, nil
+}
@@ -13,6 +13,8 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"r3/audit"
+	"r3/backup"
 	"r3/bruteforce"
 	"r3/cache"
 	"r3/cluster"
@@ -25,6 +27,7 @@ import (
 	"r3/handler"
 	"r3/handler/api"
 	"r3/handler/api_auth"
+	"r3/handler/audit_export"
 	"r3/handler/cache_download"
 	"r3/handler/client_download"
 	"r3/handler/csv_download"
@@ -39,6 +42,9 @@ import (
 	"r3/handler/ics_download"
 	"r3/handler/license_upload"
 	"r3/handler/manifest_download"
+	"r3/handler/privacy_export"
+	"r3/handler/saml"
+	"r3/handler/scim"
 	"r3/handler/transfer_export"
 	"r3/handler/transfer_import"
 	"r3/handler/websocket"
@@ -48,6 +54,7 @@ import (
 	"r3/login/login_session"
 	"r3/scheduler"
 	"r3/spooler/doc_create"
+	"r3/spooler/file_text"
 	"r3/tools"
 	"strings"
 	"sync/atomic"
@@ -76,7 +83,10 @@ var (
 		http             bool
 		keepWorkDir      bool
 		open             bool
+		restore          string
+		restoreKey       string
 		run              bool
+		samlLocalIdp     bool
 		serviceName      string
 		serviceStart     bool
 		serviceStop      bool
@@ -122,6 +132,9 @@ func main() {
 	flag.BoolVar(&cli.http, "http", false, "Start with HTTP (not encrypted, for testing/development only, combined with -run)")
 	flag.BoolVar(&cli.keepWorkDir, "keepworkdir", false, "Do not change working directory to directory of executable")
 	flag.BoolVar(&cli.open, "open", false, fmt.Sprintf("Open URL of %s in default browser (combined with -run)", appName))
+	flag.StringVar(&cli.restore, "restore", "", "Restore backup from given backup directory, replaces database, files and config file (instance must be stopped, database user must be able to create databases)")
+	flag.StringVar(&cli.restoreKey, "restorekey", "", "Private key file (PEM) to decrypt encrypted backup (combined with -restore)")
+	flag.BoolVar(&cli.samlLocalIdp, "samllocalidp", false, "(Development) Serve local SAML identity provider at /saml/localidp/, authenticates any entered user without checks (combined with -run)")
 	flag.BoolVar(&cli.run, "run", false, fmt.Sprintf("Run %s from within this console (see 'config.json' for configuration)", appName))
 	flag.BoolVar(&cli.debug, "debug", false, "Logs all events regardless of configured log level (combined with -run)")
 	flag.BoolVar(&cli.serviceInstall, "install", false, fmt.Sprintf("Install %s service", appName))
@@ -276,6 +289,14 @@ func main() {
 		}
 		return
 	}
+	if cli.restore != "" {
+		if err := backup.Restore(cli.restore, cli.restoreKey, func(msg string) { prg.logger.Info(msg) }); err != nil {
+			prg.logger.Errorf("failed to restore backup, %v", err)
+			return
+		}
+		prg.logger.Info("backup was successfully restored, database is upgraded on next start if required")
+		return
+	}
 
 	// main executable can be used to open the app in default browser even if its not started (-open without -run)
 	// used for shortcuts in start menu when installed on Windows systems with desktop experience
@@ -401,6 +422,9 @@ func (prg *program) execute(svc service.Service) {
 	// prepare image processing
 	data_image.PrepareProcessing(cli.imageMagick)
 
+	// prepare file text extraction
+	file_text.PrepareProcessing()
+
 	log.Info(log.ContextServer, fmt.Sprintf("is ready to start application (%s)", appVersion))
 
 	// start scheduler (must start after module cache)
@@ -433,6 +457,7 @@ func (prg *program) execute(svc service.Service) {
 
 	mux.HandleFunc("/api/", api.Handler)
 	mux.HandleFunc("/api/auth", api_auth.Handler)
+	mux.HandleFunc("/audit/export", audit_export.Handler)
 	mux.HandleFunc("/cache/download/", cache_download.Handler)
 	mux.HandleFunc("/csv/download/", csv_download.Handler)
 	mux.HandleFunc("/csv/upload", csv_upload.Handler)
@@ -446,6 +471,12 @@ func (prg *program) execute(svc service.Service) {
 	mux.HandleFunc("/ics/download/", ics_download.Handler)
 	mux.HandleFunc("/license/upload", license_upload.Handler)
 	mux.HandleFunc("/manifests/", manifest_download.Handler)
+	mux.HandleFunc("/privacy/export", privacy_export.Handler)
+	mux.HandleFunc("/saml/", saml.Handler)
+	if cli.samlLocalIdp {
+		mux.HandleFunc("/saml/localidp/", saml.HandlerLocalIdp)
+	}
+	mux.HandleFunc("/scim/v2/", scim.Handler)
 	mux.HandleFunc("/websocket", websocket.Handler)
 	mux.HandleFunc("/export/", transfer_export.Handler)
 	mux.HandleFunc("/import", transfer_import.Handler)
@@ -545,6 +576,11 @@ func initSystem(ctx context.Context) error {
 		return err
 	}
 
+	// create key for audit log hash chain
+	if err := audit.InitKey_tx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create audit key, %v", err)
+	}
+
 	// remove login sessions logs for this cluster node (in case they were not removed on shutdown)
 	if err := login_session.LogsRemoveForNode_tx(ctx, tx); err != nil {
 		return err
@@ -613,6 +649,9 @@ func initCaches(ctx context.Context) error {
 	if err := cache.LoadRepos_tx(ctx, tx); err != nil {
 		return fmt.Errorf("failed to initialize repository cache, %v", err)
 	}
+	if err := cache.LoadSamlIdpMap_tx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to initialize SAML identity provider cache, %v", err)
+	}
 	if err := ldap.UpdateCache_tx(ctx, tx); err != nil {
 		return fmt.Errorf("failed to initialize LDAP cache, %v", err)
 	}
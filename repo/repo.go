@@ -9,8 +9,43 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// responses of repository API, module with its latest release
+type repoModuleResponse struct {
+	Module struct {
+		Uuid       uuid.UUID   `json:"uuid"`
+		Name       string      `json:"name"`
+		InStore    bool        `json:"in_store"`
+		LogSummary pgtype.Text `json:"log_summary"`
+	} `json:"0(module)"`
+	Release struct {
+		ReleaseBuild    int                      `json:"release_build"`
+		ReleaseBuildApp int                      `json:"release_build_app"`
+		ReleaseDate     int64                    `json:"release_date"`
+		File            []types.DataGetValueFile `json:"file"`
+	} `json:"1(module_release)"`
+	Author struct {
+		Name string `json:"name"`
+	} `json:"2(author)"`
+}
+
+// responses of repository API, translated module meta
+type repoModuleMetaResponse struct {
+	Meta struct {
+		Description string `json:"description"`
+		SupportPage string `json:"support_page"`
+		Title       string `json:"title"`
+	} `json:"0(module_transl_meta)"`
+	Module struct {
+		Uuid uuid.UUID `json:"uuid"`
+	} `json:"1(module)"`
+	Language struct {
+		Code string `json:"code"`
+	} `json:"2(language)"`
+}
+
 func Del_Tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
 	if _, err := tx.Exec(ctx, `DELETE FROM instance.repo WHERE id = $1`, id); err != nil {
 		return err
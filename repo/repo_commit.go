@@ -72,39 +72,7 @@ func RepoCommit(ctx context.Context, loginId int64, repoId uuid.UUID, credUser,
 		})
 	}
 
-	categoryIndexMapLogs := make(map[int][]string)
-	for i := range mod.ReleaseLogCategories {
-		categoryIndexMapLogs[i] = make([]string, 0)
-	}
-	for _, r := range mod.Releases {
-		if r.Build <= repoModuleBuild {
-			continue
-		}
-
-		for _, l := range r.Logs {
-			if l.Category >= len(mod.ReleaseLogCategories) {
-				continue
-			}
-			categoryIndexMapLogs[l.Category] = append(categoryIndexMapLogs[l.Category], l.Content)
-		}
-	}
-
-	var logHtml strings.Builder
-	var logHide bool = true
-
-	logHtml.WriteString("<ul>")
-	for i, logs := range categoryIndexMapLogs {
-		if len(logs) == 0 {
-			continue
-		}
-		logHtml.WriteString(fmt.Sprintf("<li>%s<ul><li>%s</li></ul></li>", mod.ReleaseLogCategories[i], strings.Join(logs, "</li><li>")))
-		logHide = false
-	}
-	logHtml.WriteString("</ul>")
-
-	if logHide {
-		logHtml.Reset()
-	}
+	logHtml, logHide := getReleaseLogHtml(mod, repoModuleBuild)
 
 	// export module file
 	filePath, err := tools.GetUniqueFilePath(config.File.Paths.Temp, 8999999, 9999999)
@@ -123,7 +91,7 @@ func RepoCommit(ctx context.Context, loginId int64, repoId uuid.UUID, credUser,
 	}
 
 	// upload module release with module file
-	return repoCommitAttach(repo.Url, repo.SkipVerify, token, repoModuleId, mod, fileId, fileName, logHtml.String(), logHide)
+	return repoCommitAttach(repo.Url, repo.SkipVerify, token, repoModuleId, mod, fileId, fileName, logHtml, logHide)
 }
 
 func repoCommitAttach(baseUrl string, skipVerify bool, token string, repoModuleId int64,
@@ -269,3 +237,43 @@ func repoCommitUploadFile(baseUrl string, skipVerify bool, token, filePath, file
 	}
 	return res.Id, nil
 }
+
+// returns release logs of all releases after the given build as HTML list, grouped by category
+// returns empty string and hide = true, if there are no logs
+func getReleaseLogHtml(mod types.Module, buildAfter int64) (string, bool) {
+
+	categoryIndexMapLogs := make(map[int][]string)
+	for i := range mod.ReleaseLogCategories {
+		categoryIndexMapLogs[i] = make([]string, 0)
+	}
+	for _, r := range mod.Releases {
+		if r.Build <= buildAfter {
+			continue
+		}
+
+		for _, l := range r.Logs {
+			if l.Category >= len(mod.ReleaseLogCategories) {
+				continue
+			}
+			categoryIndexMapLogs[l.Category] = append(categoryIndexMapLogs[l.Category], l.Content)
+		}
+	}
+
+	var logHtml strings.Builder
+	var logHide bool = true
+
+	logHtml.WriteString("<ul>")
+	for i, logs := range categoryIndexMapLogs {
+		if len(logs) == 0 {
+			continue
+		}
+		logHtml.WriteString(fmt.Sprintf("<li>%s<ul><li>%s</li></ul></li>", mod.ReleaseLogCategories[i], strings.Join(logs, "</li><li>")))
+		logHide = false
+	}
+	logHtml.WriteString("</ul>")
+
+	if logHide {
+		return "", true
+	}
+	return logHtml.String(), false
+}
@@ -11,6 +11,7 @@ import (
 	"r3/db"
 	"r3/tools"
 	"r3/transfer"
+	"r3/types"
 
 	"github.com/gofrs/uuid"
 )
@@ -19,6 +20,45 @@ import (
 var fileAttributeId = "b28e8f5c-ebeb-4565-941b-4d942eedc588"
 
 func InstallModules(ctx context.Context, moduleIds []uuid.UUID) error {
+	filePaths, err := downloadModules(ctx, moduleIds)
+	if err != nil {
+		return err
+	}
+	return transfer.ImportFromFiles(ctx, filePaths)
+}
+
+// returns changes that installing the modules would apply, without applying them
+func InstallModulesDryRun(ctx context.Context, moduleIds []uuid.UUID) (types.TransferDryRun, error) {
+	filePaths, err := downloadModules(ctx, moduleIds)
+	if err != nil {
+		return types.TransferDryRun{}, err
+	}
+	defer func() {
+		for _, filePath := range filePaths {
+			os.Remove(filePath)
+		}
+	}()
+	return transfer.ImportDryRun(ctx, filePaths)
+}
+
+func InstallModulesNewVersions(ctx context.Context) error {
+	moduleIds, err := getModuleIdsNewVersions(ctx)
+	if err != nil {
+		return err
+	}
+	return InstallModules(ctx, moduleIds)
+}
+
+func InstallModulesNewVersionsDryRun(ctx context.Context) (types.TransferDryRun, error) {
+	moduleIds, err := getModuleIdsNewVersions(ctx)
+	if err != nil {
+		return types.TransferDryRun{}, err
+	}
+	return InstallModulesDryRun(ctx, moduleIds)
+}
+
+// downloads module files for highest available build versions, returns file paths
+func downloadModules(ctx context.Context, moduleIds []uuid.UUID) ([]string, error) {
 
 	type repoFile struct {
 		FileId uuid.UUID
@@ -39,7 +79,7 @@ func InstallModules(ctx context.Context, moduleIds []uuid.UUID) error {
 			ORDER BY rm.release_build DESC
 			LIMIT 1
 		`, moduleId).Scan(&rf.RepoId, &rf.FileId); err != nil {
-			return err
+			return nil, err
 		}
 		repoFiles = append(repoFiles, rf)
 	}
@@ -48,14 +88,14 @@ func InstallModules(ctx context.Context, moduleIds []uuid.UUID) error {
 	for _, rf := range repoFiles {
 		filePath, err := download(rf.RepoId, rf.FileId)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		filePaths = append(filePaths, filePath)
 	}
-	return transfer.ImportFromFiles(ctx, filePaths)
+	return filePaths, nil
 }
 
-func InstallModulesNewVersions(ctx context.Context) error {
+func getModuleIdsNewVersions(ctx context.Context) ([]uuid.UUID, error) {
 
 	// get all installed modules, that can be updated from repository
 	// only include modules that are compatible with the platform build
@@ -69,9 +109,9 @@ func InstallModulesNewVersions(ctx context.Context) error {
 		AND   rm.release_build_app <= $1
 		AND   r.active
 	`, config.GetAppVersion().Build).Scan(&moduleIds); err != nil {
-		return err
+		return nil, err
 	}
-	return InstallModules(ctx, moduleIds)
+	return moduleIds, nil
 }
 
 func download(repoId, fileId uuid.UUID) (string, error) {
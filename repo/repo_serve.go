@@ -0,0 +1,377 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/handler"
+	"r3/tools"
+	"r3/transfer"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// instances can serve published modules to other instances, which add them as regular repositories
+// the repository API is emulated: other instances authenticate with local logins and fetch modules
+//  with the same calls they use for the repository application
+// published modules are served with their original, signed transfer files
+
+// name of the repository application, requests to it are served if the instance acts as repository
+const ServeModuleName = "lsw_repo"
+
+var ErrServeUnauthorized = errors.New("login may not fetch modules from this repository")
+
+func ServeGet_tx(ctx context.Context, tx pgx.Tx) (types.RepoServe, error) {
+	var s types.RepoServe
+	s.Logins = make([]types.RepoServeLogin, 0)
+	s.Modules = make([]types.RepoServeModule, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.id, l.name
+		FROM instance.repo_serve_login AS r
+		JOIN instance.login            AS l ON l.id = r.login_id
+		ORDER BY l.name ASC
+	`)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var l types.RepoServeLogin
+		if err := rows.Scan(&l.Id, &l.Name); err != nil {
+			rows.Close()
+			return s, err
+		}
+		s.Logins = append(s.Logins, l)
+	}
+	rows.Close()
+
+	s.Modules, err = getServeModules_tx(ctx, tx)
+	if err != nil {
+		return s, err
+	}
+
+	// check which modules can be served
+	for i, m := range s.Modules {
+		if err := checkServeModule_tx(ctx, tx, m.ModuleId, s.Modules); err != nil {
+			s.Modules[i].FileError = pgtype.Text{String: err.Error(), Valid: true}
+		}
+	}
+	return s, nil
+}
+
+func ServeSet_tx(ctx context.Context, tx pgx.Tx, s types.RepoServe) error {
+
+	loginIds := make([]int64, 0)
+	for _, l := range s.Logins {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.repo_serve_login (login_id)
+			VALUES ($1)
+			ON CONFLICT DO NOTHING
+		`, l.Id); err != nil {
+			return err
+		}
+		loginIds = append(loginIds, l.Id)
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.repo_serve_login
+		WHERE login_id <> ALL($1)
+	`, loginIds); err != nil {
+		return err
+	}
+
+	moduleIds := make([]uuid.UUID, 0)
+	for _, m := range s.Modules {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.repo_serve_module (module_id, author,
+				description, support_page, in_store)
+			VALUES ($1,$2,$3,$4,$5)
+			ON CONFLICT (module_id)
+			DO UPDATE SET author = $2, description = $3,
+				support_page = $4, in_store = $5
+		`, m.ModuleId, m.Author, m.Description, m.SupportPage, m.InStore); err != nil {
+			return err
+		}
+		moduleIds = append(moduleIds, m.ModuleId)
+	}
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.repo_serve_module
+		WHERE module_id <> ALL($1)
+	`, moduleIds)
+
+	return err
+}
+
+// returns whether repository requests are served by this instance
+// if the repository application is installed, its own APIs are used instead
+func ServeIsActive() bool {
+	if config.GetUint64("repoServeActive") != 1 {
+		return false
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	for _, m := range cache.ModuleIdMap {
+		if m.Name == ServeModuleName {
+			return false
+		}
+	}
+	return true
+}
+
+// returns whether the given attribute is the module file attribute of the repository application
+func ServeIsFileAttribute(attributeId uuid.UUID) bool {
+	return attributeId.String() == fileAttributeId
+}
+
+// returns response for repository API call, as expected by other instances
+func ServeApi(ctx context.Context, loginId int64, apiName string, version int, limit int, offset int) (any, error) {
+
+	if version != 1 {
+		return nil, fmt.Errorf("repository API version %d is not supported", version)
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := serveCheckAccess_tx(ctx, tx, loginId); err != nil {
+		return nil, err
+	}
+
+	modules, err := getServeModulesAvailable_tx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch apiName {
+	case "module":
+		res := make([]repoModuleResponse, 0)
+		for _, sm := range modules {
+			cache.Schema_mx.RLock()
+			mod := cache.ModuleIdMap[sm.ModuleId]
+			cache.Schema_mx.RUnlock()
+
+			var r repoModuleResponse
+			r.Module.Uuid = mod.Id
+			r.Module.Name = mod.Name
+			r.Module.InStore = sm.InStore
+			r.Author.Name = sm.Author
+			r.Release.ReleaseBuild = mod.ReleaseBuild
+			r.Release.ReleaseBuildApp = mod.ReleaseBuildApp
+			r.Release.ReleaseDate = mod.ReleaseDate
+
+			// module ID is used as file ID, the file always contains the installed version
+			r.Release.File = []types.DataGetValueFile{{
+				Id:   mod.Id,
+				Name: fmt.Sprintf("%s.rei3", mod.Name),
+			}}
+
+			if logHtml, logHide := getReleaseLogHtml(mod, int64(mod.ReleaseBuild-1)); !logHide {
+				r.Module.LogSummary = pgtype.Text{String: logHtml, Valid: true}
+			}
+			res = append(res, r)
+		}
+		start, end := getServePageRange(len(res), limit, offset)
+		return res[start:end], nil
+
+	case "module_meta":
+		res := make([]repoModuleMetaResponse, 0)
+		for _, sm := range modules {
+			cache.Schema_mx.RLock()
+			mod := cache.ModuleIdMap[sm.ModuleId]
+			cache.Schema_mx.RUnlock()
+
+			// english meta is always included, as other instances fall back to it
+			languageCodes := slices.Clone(mod.Languages)
+			if !slices.Contains(languageCodes, "en_us") {
+				languageCodes = append(languageCodes, "en_us")
+			}
+			for _, code := range languageCodes {
+				title, exists := mod.Captions["moduleTitle"][code]
+				if !exists || title == "" {
+					title, exists = mod.Captions["moduleTitle"][mod.LanguageMain]
+					if !exists || title == "" {
+						title = mod.Name
+					}
+				}
+
+				var r repoModuleMetaResponse
+				r.Meta.Description = sm.Description
+				r.Meta.SupportPage = sm.SupportPage
+				r.Meta.Title = title
+				r.Module.Uuid = mod.Id
+				r.Language.Code = code
+				res = append(res, r)
+			}
+		}
+		start, end := getServePageRange(len(res), limit, offset)
+		return res[start:end], nil
+	}
+	return nil, fmt.Errorf("unknown repository API '%s'", apiName)
+}
+
+// writes compressed file of published module and its dependencies to temp path, returns file path
+// caller is responsible for removing the file
+func ServeFile(ctx context.Context, loginId int64, fileId uuid.UUID) (string, error) {
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := serveCheckAccess_tx(ctx, tx, loginId); err != nil {
+		return "", err
+	}
+
+	modules, err := getServeModulesAvailable_tx(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	if !slices.ContainsFunc(modules, func(m types.RepoServeModule) bool { return m.ModuleId == fileId }) {
+		return "", fmt.Errorf("module '%s' is not published", fileId)
+	}
+
+	filePath, err := tools.GetUniqueFilePath(config.File.Paths.Temp, 8999999, 9999999)
+	if err != nil {
+		return "", err
+	}
+	if err := transfer.ExportOriginalsToFile(ctx, fileId, filePath); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+	return filePath, nil
+}
+
+func serveCheckAccess_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
+	var exists bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT login_id
+			FROM instance.repo_serve_login
+			WHERE login_id = $1
+		)
+	`, loginId).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrServeUnauthorized
+	}
+	return nil
+}
+
+func getServeModules_tx(ctx context.Context, tx pgx.Tx) ([]types.RepoServeModule, error) {
+	modules := make([]types.RepoServeModule, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT module_id, author, description, support_page, in_store
+		FROM instance.repo_serve_module
+	`)
+	if err != nil {
+		return modules, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m types.RepoServeModule
+		if err := rows.Scan(&m.ModuleId, &m.Author, &m.Description, &m.SupportPage, &m.InStore); err != nil {
+			return modules, err
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+// returns published modules that can be served, ordered by name for stable paging
+func getServeModulesAvailable_tx(ctx context.Context, tx pgx.Tx) ([]types.RepoServeModule, error) {
+	modules, err := getServeModules_tx(ctx, tx)
+	if err != nil {
+		return modules, err
+	}
+
+	names := make(map[uuid.UUID]string)
+	available := make([]types.RepoServeModule, 0)
+	for _, m := range modules {
+		cache.Schema_mx.RLock()
+		mod, exists := cache.ModuleIdMap[m.ModuleId]
+		cache.Schema_mx.RUnlock()
+
+		if !exists {
+			continue
+		}
+		if err := checkServeModule_tx(ctx, tx, m.ModuleId, modules); err != nil {
+			continue
+		}
+		names[m.ModuleId] = mod.Name
+		available = append(available, m)
+	}
+
+	slices.SortFunc(available, func(a, b types.RepoServeModule) int {
+		return strings.Compare(names[a.ModuleId], names[b.ModuleId])
+	})
+	return available, nil
+}
+
+// checks whether a published module can be served
+// served files include all dependencies, which must be published as well
+func checkServeModule_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, modulesPublished []types.RepoServeModule) error {
+
+	moduleIdsChecked := make([]uuid.UUID, 0)
+
+	var checkDependencies func(id uuid.UUID) error
+	checkDependencies = func(id uuid.UUID) error {
+		if slices.Contains(moduleIdsChecked, id) {
+			return nil
+		}
+		moduleIdsChecked = append(moduleIdsChecked, id)
+
+		cache.Schema_mx.RLock()
+		mod, exists := cache.ModuleIdMap[id]
+		cache.Schema_mx.RUnlock()
+
+		if !exists {
+			return handler.ErrSchemaUnknownModule(id)
+		}
+		if !slices.ContainsFunc(modulesPublished, func(m types.RepoServeModule) bool { return m.ModuleId == id }) {
+			return fmt.Errorf("dependency '%s' is not published", mod.Name)
+		}
+		for _, idDependsOn := range mod.DependsOn {
+			if err := checkDependencies(idDependsOn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := checkDependencies(moduleId); err != nil {
+		return err
+	}
+
+	_, err := transfer.GetOriginalFilePaths_tx(ctx, tx, moduleId)
+	return err
+}
+
+// returns start & end index of requested page within list of given length
+func getServePageRange(count int, limit int, offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= count {
+		return count, count
+	}
+	if limit <= 0 || offset+limit > count {
+		return offset, count
+	}
+	return offset, offset + limit
+}
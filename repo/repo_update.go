@@ -12,7 +12,6 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // update repositories in individual transactions
@@ -227,31 +226,13 @@ func removeModules_tx(ctx context.Context, tx pgx.Tx, repoId uuid.UUID, repoModu
 
 func getModules(token string, baseUrl string, skipVerify bool, repoModuleMap map[uuid.UUID]types.RepoModule) error {
 
-	type moduleResponse struct {
-		Module struct {
-			Uuid       uuid.UUID   `json:"uuid"`
-			Name       string      `json:"name"`
-			InStore    bool        `json:"in_store"`
-			LogSummary pgtype.Text `json:"log_summary"`
-		} `json:"0(module)"`
-		Release struct {
-			ReleaseBuild    int                      `json:"release_build"`
-			ReleaseBuildApp int                      `json:"release_build_app"`
-			ReleaseDate     int64                    `json:"release_date"`
-			File            []types.DataGetValueFile `json:"file"`
-		} `json:"1(module_release)"`
-		Author struct {
-			Name string `json:"name"`
-		} `json:"2(author)"`
-	}
-
 	limit := 100
 	offset := 0
 
 	for true {
 		url := fmt.Sprintf("%s/api/lsw_repo/module/v1?limit=%d&offset=%d", baseUrl, limit, offset)
 
-		var res []moduleResponse
+		var res []repoModuleResponse
 		if err := httpCallGet(token, url, skipVerify, "", &res); err != nil {
 			return err
 		}
@@ -286,27 +267,13 @@ func getModules(token string, baseUrl string, skipVerify bool, repoModuleMap map
 
 func getModuleMetas(token string, baseUrl string, skipVerify bool, repoModuleMap map[uuid.UUID]types.RepoModule) error {
 
-	type moduleMetaResponse struct {
-		Meta struct {
-			Description string `json:"description"`
-			SupportPage string `json:"support_page"`
-			Title       string `json:"title"`
-		} `json:"0(module_transl_meta)"`
-		Module struct {
-			Uuid uuid.UUID `json:"uuid"`
-		} `json:"1(module)"`
-		Language struct {
-			Code string `json:"code"`
-		} `json:"2(language)"`
-	}
-
 	limit := 100
 	offset := 0
 
 	for true {
 		url := fmt.Sprintf("%s/api/lsw_repo/module_meta/v1?limit=%d&offset=%d", baseUrl, limit, offset)
 
-		var res []moduleMetaResponse
+		var res []repoModuleMetaResponse
 		if err := httpCallGet(token, url, skipVerify, "", &res); err != nil {
 			return err
 		}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"r3/audit"
 	"r3/cache"
 	"r3/cluster"
 	"r3/config"
@@ -45,11 +46,12 @@ func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin
 	}
 
 	// execute and create response for each request
+	auditLogs := make([]types.AuditLog, 0)
 	responses := make([]types.Response, 0)
 	for _, req := range reqTrans.Requests {
 		log.Info(log.ContextWebsocket, fmt.Sprintf("TRANSACTION %d, %s %s, payload: %s", reqTrans.TransactionNr, req.Action, req.Ressource, req.Payload))
 
-		payload, err := Exec_tx(ctx, tx, address, loginId, isAdmin, device, isNoAuth, req.Ressource, req.Action, req.Payload)
+		payload, err := Exec_tx(ctx, tx, address, loginId, isAdmin, device, isNoAuth, req.Ressource, req.Action, req.Payload, &auditLogs)
 		if err != nil {
 			return nil, err
 		}
@@ -63,13 +65,19 @@ func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin
 	}
 
 	if !reqTrans.NoDbTx {
+		// audit log is written last, its hash chain lock is kept until commit
+		if err := audit.Write_tx(ctx, tx, auditLogs); err != nil {
+			return nil, err
+		}
 		return responses, tx.Commit(ctx)
 	}
 	return responses, nil
 }
 
+// executes a single request
+// audit log entries of admin requests are collected if a collection is given, otherwise written immediately
 func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAdmin bool, device types.WebsocketClientDevice,
-	isNoAuth bool, ressource string, action string, reqJson json.RawMessage) (any, error) {
+	isNoAuth bool, ressource string, action string, reqJson json.RawMessage, auditLogs *[]types.AuditLog) (any, error) {
 
 	// public requests: accessible to all
 	switch ressource {
@@ -109,6 +117,8 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		switch action {
 		case "del":
 			return DataDel_tx(ctx, tx, reqJson, loginId)
+		case "delRecycle":
+			return DataRecycleDel_tx(ctx, tx, reqJson, loginId)
 		case "get":
 			return DataGet_tx(ctx, tx, reqJson, loginId)
 		case "getKeys":
@@ -117,6 +127,14 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return DataLogGet_tx(ctx, tx, reqJson, loginId)
 		case "getRecordTitles":
 			return DataGetRecordTitles_tx(ctx, tx, reqJson, loginId)
+		case "getRecycle":
+			return DataRecycleGet_tx(ctx, tx, reqJson, loginId)
+		case "restoreLog":
+			return DataLogRestore_tx(ctx, tx, reqJson, loginId)
+		case "restoreRecycle":
+			return DataRecycleRestore_tx(ctx, tx, reqJson, loginId)
+		case "search":
+			return DataSearch_tx(ctx, tx, reqJson, loginId)
 		case "set":
 			return DataSet_tx(ctx, tx, reqJson, loginId)
 		case "setKeys":
@@ -247,10 +265,34 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 	}
 
 	// authorized requests: admin
-	if !isAdmin {
-		return nil, errors.New(handler.ErrUnauthorized)
+	if err := CheckAdminAccess(loginId, isAdmin, ressource, action); err != nil {
+		return nil, err
+	}
+
+	if auditIsIgnored(action) {
+		return execAdmin_tx(ctx, tx, loginId, isAdmin, ressource, action, reqJson)
 	}
 
+	// keep state of affected object before changing it
+	var stateBefore json.RawMessage
+	if tx != nil {
+		var err error
+		stateBefore, err = audit.GetState_tx(ctx, tx, ressource, reqJson)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := execAdmin_tx(ctx, tx, loginId, isAdmin, ressource, action, reqJson)
+	if err != nil {
+		return nil, err
+	}
+	return res, auditCreate_tx(ctx, tx, address, loginId, ressource, action, reqJson, stateBefore, auditLogs)
+}
+
+func execAdmin_tx(ctx context.Context, tx pgx.Tx, loginId int64, isAdmin bool,
+	ressource string, action string, reqJson json.RawMessage) (any, error) {
+
 	switch ressource {
 	case "api":
 		switch action {
@@ -270,6 +312,13 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return ArticleSet_tx(ctx, tx, reqJson)
 		}
+	case "audit":
+		switch action {
+		case "get":
+			return AuditGet_tx(ctx, tx, reqJson)
+		case "verify":
+			return AuditVerify_tx(ctx, tx)
+		}
 	case "attribute":
 		switch action {
 		case "del":
@@ -279,8 +328,20 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		}
 	case "backup":
 		switch action {
+		case "delTarget":
+			return BackupTargetDel_tx(ctx, tx, reqJson)
 		case "get":
 			return BackupGet()
+		case "getJobs":
+			return BackupJobGet_tx(ctx, tx)
+		case "getTargets":
+			return BackupTargetGet_tx(ctx, tx)
+		case "setJobs":
+			return BackupJobSet_tx(ctx, tx, reqJson)
+		case "setTarget":
+			return BackupTargetSet_tx(ctx, tx, reqJson)
+		case "testTarget":
+			return BackupTargetTest_tx(ctx, tx, reqJson)
 		}
 	case "bruteforce":
 		switch action {
@@ -313,7 +374,7 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "get":
 			return ConfigGet()
 		case "set":
-			return ConfigSet_tx(ctx, tx, reqJson)
+			return ConfigSet_tx(ctx, tx, reqJson, isAdmin)
 		}
 	case "cluster":
 		switch action {
@@ -388,6 +449,8 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return LdapDel_tx(ctx, tx, reqJson)
 		case "get":
 			return LdapGet_tx(ctx, tx)
+		case "preview":
+			return LdapPreview(reqJson)
 		case "reload":
 			return nil, ldap.UpdateCache_tx(ctx, tx)
 		case "set":
@@ -408,7 +471,7 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 	case "login":
 		switch action {
 		case "del":
-			return request_login.Del_tx(ctx, tx, reqJson)
+			return request_login.Del_tx(ctx, tx, reqJson, isAdmin)
 		case "get":
 			return request_login.Get_tx(ctx, tx, reqJson)
 		case "getIsNotUnique":
@@ -418,17 +481,17 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "getRecords":
 			return request_login.GetRecords_tx(ctx, tx, reqJson)
 		case "kick":
-			return request_login.Kick(ctx, tx, reqJson)
+			return request_login.Kick(ctx, tx, reqJson, isAdmin)
 		case "reauth":
-			return request_login.Reauth_tx(ctx, tx, reqJson)
+			return request_login.Reauth_tx(ctx, tx, reqJson, isAdmin)
 		case "reauthAll":
 			return request_login.ReauthAll_tx(ctx, tx)
 		case "resetTotp":
-			return request_login.ResetTotp_tx(ctx, tx, reqJson)
+			return request_login.ResetTotp_tx(ctx, tx, reqJson, isAdmin)
 		case "set":
-			return request_login.Set_tx(ctx, tx, reqJson)
+			return request_login.Set_tx(ctx, tx, reqJson, isAdmin)
 		case "setMembers":
-			return request_login.SetMembers_tx(ctx, tx, reqJson)
+			return request_login.SetMembers_tx(ctx, tx, reqJson, isAdmin)
 		}
 	case "loginExportKey":
 		switch action {
@@ -471,6 +534,24 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return request_login.TemplateSet_tx(ctx, tx, reqJson)
 		}
+	case "job":
+		switch action {
+		case "del":
+			return JobDel_tx(ctx, tx, reqJson)
+		case "get":
+			return JobGet_tx(ctx, tx, reqJson)
+		case "retry":
+			return JobRetry_tx(ctx, tx, reqJson)
+		}
+	case "jobQueue":
+		switch action {
+		case "del":
+			return JobQueueDel_tx(ctx, tx, reqJson)
+		case "get":
+			return JobQueueGet_tx(ctx, tx)
+		case "set":
+			return JobQueueSet_tx(ctx, tx, reqJson)
+		}
 	case "mailAccount":
 		switch action {
 		case "del":
@@ -514,6 +595,17 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return ModuleSet_tx(ctx, tx, reqJson)
 		}
+	case "moduleArchive":
+		switch action {
+		case "del":
+			return ModuleArchiveDel_tx(ctx, tx, reqJson)
+		case "get":
+			return ModuleArchiveGet_tx(ctx, tx, reqJson)
+		case "getMigrations":
+			return ModuleArchiveGetMigrations_tx(ctx, tx, reqJson)
+		case "rollback":
+			return ModuleArchiveRollback(ctx, reqJson)
+		}
 	case "moduleMeta":
 		switch action {
 		case "setLanguagesCustom":
@@ -521,6 +613,13 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "setOptions":
 			return ModuleMetaSetOptions_tx(ctx, tx, reqJson)
 		}
+	case "numberRange":
+		switch action {
+		case "del":
+			return NumberRangeDel_tx(ctx, tx, reqJson)
+		case "set":
+			return NumberRangeSet_tx(ctx, tx, reqJson)
+		}
 	case "oauthClient":
 		switch action {
 		case "del":
@@ -543,6 +642,8 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return PgFunctionDel_tx(ctx, tx, reqJson)
 		case "execAny": // admin may exec any non-trigger backend function
 			return PgFunctionExec_tx(ctx, tx, reqJson, false)
+		case "schedulePreview":
+			return PgFunctionSchedulePreview_tx(ctx, tx, reqJson)
 		case "set":
 			return PgFunctionSet_tx(ctx, tx, reqJson)
 		}
@@ -567,6 +668,17 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return PresetSet_tx(ctx, tx, reqJson)
 		}
+	case "privacy":
+		switch action {
+		case "erase":
+			return PrivacyErase_tx(ctx, tx, reqJson, isAdmin)
+		case "find":
+			return PrivacyFind_tx(ctx, tx, reqJson)
+		case "get":
+			return PrivacyGet_tx(ctx, tx)
+		case "set":
+			return PrivacySet_tx(ctx, tx, reqJson)
+		}
 	case "pwaDomain":
 		switch action {
 		case "reset":
@@ -604,6 +716,17 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return RepoModuleInstall(ctx, reqJson)
 		case "installAll":
 			return nil, repo.InstallModulesNewVersions(ctx)
+		case "installAllDryRun":
+			return repo.InstallModulesNewVersionsDryRun(ctx)
+		case "installDryRun":
+			return RepoModuleInstallDryRun(ctx, reqJson)
+		}
+	case "repoServe":
+		switch action {
+		case "get":
+			return repo.ServeGet_tx(ctx, tx)
+		case "set":
+			return RepoServeSet_tx(ctx, tx, reqJson)
 		}
 	case "role":
 		switch action {
@@ -612,10 +735,29 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return RoleSet_tx(ctx, tx, reqJson)
 		}
+	case "samlIdp":
+		switch action {
+		case "del":
+			return SamlIdpDel_tx(ctx, tx, reqJson)
+		case "get":
+			return SamlIdpGet()
+		case "parseMetadata":
+			return SamlIdpParseMetadata(reqJson)
+		case "reload":
+			return SamlIdpReload_tx(ctx, tx)
+		case "set":
+			return SamlIdpSet_tx(ctx, tx, reqJson)
+		}
 	case "scheduler":
 		switch action {
 		case "get":
 			return schedulersGet_tx(ctx, tx)
+		case "getAlerts":
+			return schedulerAlertsGet_tx(ctx, tx)
+		case "getRuns":
+			return schedulerRunsGet_tx(ctx, tx, reqJson)
+		case "setAlerts":
+			return schedulerAlertsSet_tx(ctx, tx, reqJson)
 		}
 	case "schema":
 		switch action {
@@ -624,6 +766,15 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "reload":
 			return SchemaReload_tx(ctx, tx, reqJson)
 		}
+	case "scimClient":
+		switch action {
+		case "del":
+			return ScimClientDel_tx(ctx, tx, reqJson)
+		case "get":
+			return ScimClientGet_tx(ctx, tx)
+		case "set":
+			return ScimClientSet_tx(ctx, tx, reqJson)
+		}
 	case "searchBar":
 		switch action {
 		case "del":
@@ -631,6 +782,15 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return SearchBarSet_tx(ctx, tx, reqJson)
 		}
+	case "searchIndex":
+		switch action {
+		case "get":
+			return SearchIndexGet_tx(ctx, tx)
+		case "reindex":
+			return SearchIndexReindex_tx(ctx, tx)
+		case "set":
+			return SearchIndexSet_tx(ctx, tx, reqJson)
+		}
 	case "task":
 		switch action {
 		case "informChanged":
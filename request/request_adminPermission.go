@@ -0,0 +1,138 @@
+package request
+
+import (
+	"errors"
+	"r3/cache"
+	"r3/handler"
+	"slices"
+)
+
+// admin permissions, assignable to logins to delegate parts of the instance administration
+// full admins are not restricted by admin permissions
+const (
+	adminPermissionBuilder = "builder" // module builder (schema changes, transfers), backend functions make this highly privileged
+	adminPermissionLogins  = "logins"  // user administration (logins, login templates, sessions, role memberships)
+	adminPermissionLogs    = "logs"    // log viewer
+	adminPermissionMails   = "mails"   // mail accounts, spooler & traffic
+	adminPermissionSystem  = "system"  // system configuration (config, identity providers, backups, cluster, files, modules, repos, tasks)
+)
+
+// no admin permission is sufficient, only full admins have access
+// used for actions that could be used to gain full admin access (trust anchors, authentication sources, module installation)
+var adminPermissionsNone = []string{}
+
+// configuration options that only full admins may change
+var adminOnlyConfigNames = []string{"backupEncryptKey", "builderMode", "exportPrivateKey", "repoPublicKeys"}
+
+// admin permissions required for admin resources, one of the permissions is sufficient
+var adminPermissionsByResource = map[string][]string{
+	"api":            {adminPermissionBuilder},
+	"article":        {adminPermissionBuilder},
+	"attribute":      {adminPermissionBuilder},
+	"audit":          {adminPermissionLogs},
+	"backup":         {adminPermissionSystem},
+	"bruteforce":     {adminPermissionSystem},
+	"captionMap":     {adminPermissionBuilder, adminPermissionSystem},
+	"clientEvent":    {adminPermissionBuilder},
+	"collection":     {adminPermissionBuilder},
+	"config":         {adminPermissionSystem},
+	"cluster":        {adminPermissionSystem},
+	"dataSql":        {adminPermissionBuilder},
+	"doc":            {adminPermissionBuilder},
+	"field":          {adminPermissionBuilder},
+	"file":           {adminPermissionSystem},
+	"form":           {adminPermissionBuilder},
+	"icon":           {adminPermissionBuilder},
+	"job":            {adminPermissionSystem},
+	"jobQueue":       {adminPermissionSystem},
+	"jsFunction":     {adminPermissionBuilder},
+	"key":            {adminPermissionBuilder},
+	"ldap":           {adminPermissionSystem},
+	"license":        {adminPermissionSystem},
+	"log":            {adminPermissionLogs},
+	"login":          {adminPermissionLogins},
+	"loginExportKey": {adminPermissionBuilder},
+	"loginForm":      {adminPermissionBuilder},
+	"loginRepoCred":  {adminPermissionSystem},
+	"loginSession":   {adminPermissionLogins},
+	"loginTemplate":  {adminPermissionLogins},
+	"mailAccount":    {adminPermissionMails},
+	"mailSpooler":    {adminPermissionMails},
+	"mailTraffic":    {adminPermissionMails},
+	"menuTab":        {adminPermissionBuilder},
+	"module":         {adminPermissionBuilder},
+	"moduleArchive":  {adminPermissionSystem},
+	"moduleMeta":     {adminPermissionSystem},
+	"numberRange":    {adminPermissionBuilder},
+	"oauthClient":    {adminPermissionSystem},
+	"package":        {adminPermissionSystem},
+	"pgFunction":     {adminPermissionBuilder},
+	"pgIndex":        {adminPermissionBuilder},
+	"pgTrigger":      {adminPermissionBuilder},
+	"preset":         {adminPermissionBuilder},
+	"privacy":        {adminPermissionSystem},
+	"pwaDomain":      {adminPermissionSystem},
+	"relation":       {adminPermissionBuilder},
+	"repo":           {adminPermissionSystem},
+	"repoModule":     {adminPermissionSystem},
+	"repoServe":      {adminPermissionSystem},
+	"role":           {adminPermissionBuilder},
+	"samlIdp":        {adminPermissionSystem},
+	"scheduler":      {adminPermissionSystem},
+	"schema":         {adminPermissionBuilder},
+	"scimClient":     {adminPermissionSystem},
+	"searchBar":      {adminPermissionBuilder},
+	"searchIndex":    {adminPermissionSystem},
+	"task":           {adminPermissionSystem},
+	"transfer":       {adminPermissionBuilder},
+	"variable":       {adminPermissionBuilder},
+	"widget":         {adminPermissionBuilder},
+}
+
+// admin permissions required for specific admin actions, overwrites permissions of resource
+var adminPermissionsByAction = map[string]map[string][]string{
+	"loginSession": {
+		// concurrent login count is shown in admin navigation
+		"getConcurrent": {adminPermissionBuilder, adminPermissionLogins, adminPermissionLogs,
+			adminPermissionMails, adminPermissionSystem},
+	},
+
+	// authentication sources, could authenticate as or grant admin logins
+	"ldap":        {"del": adminPermissionsNone, "set": adminPermissionsNone},
+	"oauthClient": {"del": adminPermissionsNone, "set": adminPermissionsNone},
+	"samlIdp":     {"del": adminPermissionsNone, "set": adminPermissionsNone},
+	"scimClient":  {"del": adminPermissionsNone, "set": adminPermissionsNone},
+
+	// module installation & rollback, modules can execute backend functions
+	"moduleArchive": {"rollback": adminPermissionsNone},
+	"package":       {"install": adminPermissionsNone},
+	"repoModule":    {"install": adminPermissionsNone, "installAll": adminPermissionsNone},
+}
+
+// checks whether login may access admin resource & action
+// full admins have access to everything, others need admin permission for resource
+// also used by HTTP handlers for admin functions outside of websocket requests
+func CheckAdminAccess(loginId int64, isAdmin bool, ressource string, action string) error {
+	if isAdmin {
+		return nil
+	}
+	access, err := cache.GetAccessById(loginId)
+	if err != nil {
+		return err
+	}
+
+	permissions, exists := adminPermissionsByAction[ressource][action]
+	if !exists {
+		permissions, exists = adminPermissionsByResource[ressource]
+		if !exists {
+			return errors.New(handler.ErrUnauthorized)
+		}
+	}
+
+	for _, p := range permissions {
+		if slices.Contains(access.AdminPermissions, p) {
+			return nil
+		}
+	}
+	return errors.New(handler.ErrUnauthorized)
+}
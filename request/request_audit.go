@@ -0,0 +1,82 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/audit"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// admin actions that do not change anything, not written to audit log
+// actions starting with 'get' are always ignored
+var auditActionsIgnore = []string{"check", "find", "parseMetadata", "preview", "verify"}
+
+func auditIsIgnored(action string) bool {
+	return strings.HasPrefix(action, "get") || slices.Contains(auditActionsIgnore, action)
+}
+
+// creates audit log entry for executed admin request, state before is compared to the current state
+// entries are collected to be written right before the transaction is committed
+// without transaction or collection, the entry is written immediately
+func auditCreate_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, ressource string,
+	action string, reqJson json.RawMessage, stateBefore json.RawMessage, auditLogs *[]types.AuditLog) error {
+
+	if tx == nil {
+		return audit.Write(ctx, loginId, address, ressource, action, reqJson)
+	}
+
+	l, err := audit.Create_tx(ctx, tx, loginId, address, ressource, action, reqJson, stateBefore)
+	if err != nil {
+		return err
+	}
+	if auditLogs == nil {
+		return audit.Write_tx(ctx, tx, []types.AuditLog{l})
+	}
+	*auditLogs = append(*auditLogs, l)
+	return nil
+}
+
+func AuditGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+
+	var (
+		err error
+		req struct {
+			ByString  string      `json:"byString"`
+			DateFrom  pgtype.Int8 `json:"dateFrom"`
+			DateTo    pgtype.Int8 `json:"dateTo"`
+			Limit     int         `json:"limit"`
+			Offset    int         `json:"offset"`
+			Ressource string      `json:"ressource"`
+		}
+		res struct {
+			Logs  []types.AuditLog `json:"logs"`
+			Total int              `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Logs, res.Total, err = audit.Get_tx(ctx, tx, req.DateFrom, req.DateTo,
+		req.Limit, req.Offset, req.Ressource, req.ByString)
+
+	return res, err
+}
+
+func AuditVerify_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+
+	var (
+		err error
+		res struct {
+			Count    int64       `json:"count"`    // entries verified
+			IdBroken pgtype.Int8 `json:"idBroken"` // first entry not matching the hash chain
+		}
+	)
+	res.Count, res.IdBroken, err = audit.Verify_tx(ctx, tx)
+	return res, err
+}
@@ -1,9 +1,13 @@
 package request
 
 import (
+	"context"
+	"encoding/json"
 	"r3/backup"
 	"r3/config"
 	"r3/types"
+
+	"github.com/jackc/pgx/v5"
 )
 
 func BackupGet() (interface{}, error) {
@@ -14,3 +18,44 @@ func BackupGet() (interface{}, error) {
 	}
 	return backup.TocFileReadCreate()
 }
+
+// backup jobs
+func BackupJobGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
+	return backup.JobGet_tx(ctx, tx)
+}
+func BackupJobSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req []types.BackupJob
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, backup.JobSet_tx(ctx, tx, req)
+}
+
+// backup targets
+func BackupTargetDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req int32
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, backup.TargetDel_tx(ctx, tx, req)
+}
+func BackupTargetGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
+	return backup.TargetGet_tx(ctx, tx)
+}
+func BackupTargetSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req types.BackupTarget
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, backup.TargetSet_tx(ctx, tx, req)
+}
+func BackupTargetTest_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req types.BackupTarget
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if err := backup.TargetUnmaskSecrets_tx(ctx, tx, &req); err != nil {
+		return nil, err
+	}
+	return nil, backup.TargetTest(req)
+}
@@ -61,13 +61,27 @@ func ConfigGet() (any, error) {
 	return res, nil
 }
 
-func ConfigSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+func ConfigSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
 
 	var req map[string]string
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
 
+	// trust anchors & builder mode can only be changed by full admins
+	if !isAdmin {
+		current, err := ConfigGet()
+		if err != nil {
+			return nil, err
+		}
+		currentMap := current.(map[string]string)
+		for _, name := range adminOnlyConfigNames {
+			if value, exists := req[name]; exists && value != currentMap[name] {
+				return nil, fmt.Errorf("configuration option '%s' can only be changed by full admins", name)
+			}
+		}
+	}
+
 	// check for config changes that have specific consequences
 	productionModeChange := false
 	if value, exists := req["productionMode"]; exists &&
@@ -19,8 +19,9 @@ func DataGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId
 		query string
 		req   types.DataGet
 		res   struct {
-			Count int64                 `json:"count"`
-			Rows  []types.DataGetResult `json:"rows"`
+			Count             int64                 `json:"count"`
+			Rows              []types.DataGetResult `json:"rows"`
+			ExpressionsNoAsOf []int                 `json:"expressionsNoAsOf"` // positions of expressions that could not be reconstructed for as-of date
 		}
 	)
 
@@ -28,6 +29,11 @@ func DataGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId
 		return nil, err
 	}
 
+	res.ExpressionsNoAsOf = make([]int, 0)
+	if req.AsOf.Valid {
+		res.ExpressionsNoAsOf = data.GetAsOfExpressionsInvalid(req)
+	}
+
 	res.Rows, res.Count, err = data.Get_tx(ctx, tx, req, loginId, &query)
 	if err != nil {
 		if query != "" {
@@ -85,6 +91,68 @@ func DataLogGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, logi
 	return data.GetLogs_tx(ctx, tx, req.RelationId, req.AttributeIds, req.RecordIds, loginId)
 }
 
+func DataLogRestore_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (any, error) {
+
+	var req struct {
+		RelationId   uuid.UUID   `json:"relationId"`
+		RecordId     int64       `json:"recordId"`
+		DataLogId    uuid.UUID   `json:"dataLogId"`
+		AttributeIds []uuid.UUID `json:"attributeIds"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, data.RestoreLog_tx(ctx, tx, req.RelationId, req.RecordId, req.DataLogId, req.AttributeIds, loginId)
+}
+
+func DataRecycleGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (any, error) {
+
+	var (
+		err error
+		req struct {
+			RelationId uuid.UUID `json:"relationId"`
+			Limit      int       `json:"limit"`
+			Offset     int       `json:"offset"`
+		}
+		res struct {
+			Entries []types.DataRecycle `json:"entries"`
+			Total   int                 `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Entries, res.Total, err = data.RecycleGet_tx(ctx, tx, req.RelationId, loginId, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+func DataRecycleDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (any, error) {
+
+	var req struct {
+		Id uuid.UUID `json:"id"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, data.RecycleDel_tx(ctx, tx, req.Id, loginId)
+}
+func DataRecycleRestore_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (any, error) {
+
+	var req struct {
+		Id uuid.UUID `json:"id"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return data.RecycleRestore_tx(ctx, tx, req.Id, loginId)
+}
+
 func DataSqlGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (any, error) {
 
 	var req types.DataGet
@@ -132,3 +200,11 @@ func DataGetRecordTitles_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMess
 	}
 	return data.GetRecordTitles_tx(ctx, tx, relationIdMapRecordIds, loginId)
 }
+
+func DataSearch_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (any, error) {
+	var req types.DataSearch
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return data.Search_tx(ctx, tx, req, loginId)
+}
@@ -30,9 +30,10 @@ func DocCopy_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, e
 
 func DocCreate(ctx context.Context, reqJson json.RawMessage, loginId int64) (any, error) {
 	var req struct {
-		DocId             uuid.UUID `json:"docId"`
-		RecordIdLoad      int64     `json:"recordIdLoad"`
-		AttributeIdTarget uuid.UUID `json:"attributeIdTarget"`
+		DocId             uuid.UUID   `json:"docId"`
+		RecordIdLoad      int64       `json:"recordIdLoad"`
+		AttributeIdTarget uuid.UUID   `json:"attributeIdTarget"`
+		AsOf              pgtype.Int8 `json:"asOf"`
 	}
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
@@ -45,7 +46,7 @@ func DocCreate(ctx context.Context, reqJson json.RawMessage, loginId int64) (any
 	if err != nil {
 		return nil, err
 	}
-	filename, err := doc_create.Run(ctx, req.DocId, loginId, req.RecordIdLoad, filePath)
+	filename, err := doc_create.Run(ctx, req.DocId, loginId, req.RecordIdLoad, req.AsOf, filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,210 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/tools"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func JobDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Ids []int64 `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	// running jobs are finished by their node
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.job
+		WHERE id = ANY($1)
+		AND   state <> 'running'
+	`, req.Ids)
+
+	return nil, err
+}
+
+func JobGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var (
+		req struct {
+			Limit  int    `json:"limit"`
+			Offset int    `json:"offset"`
+			Queue  string `json:"queue"` // empty for all queues
+			State  string `json:"state"` // empty for all states
+		}
+		res struct {
+			Jobs  []types.Job `json:"jobs"`
+			Total int64       `json:"total"`
+		}
+	)
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Jobs = make([]types.Job, 0)
+
+	var qb tools.QueryBuilder
+	qb.UseDollarSigns()
+	qb.AddList("SELECT", []string{"j.id", "j.pg_function_id", "j.queue", "j.args::TEXT",
+		"j.priority", "j.state", "j.unique_key", "j.attempts", "j.attempts_max", "n.name",
+		"j.date_added", "j.date_run_after", "j.date_milli_start", "j.date_milli_end",
+		"j.result", "j.error_message"})
+	qb.SetFrom("instance.job AS j")
+	qb.Add("JOIN", "LEFT JOIN instance_cluster.node AS n ON n.id = j.node_id")
+
+	if req.Queue != "" {
+		qb.Add("WHERE", "j.queue = {QUEUE}")
+		qb.AddPara("{QUEUE}", req.Queue)
+	}
+	if req.State != "" {
+		qb.Add("WHERE", "j.state::TEXT = {STATE}")
+		qb.AddPara("{STATE}", req.State)
+	}
+
+	qb.Add("ORDER", "j.id DESC")
+	qb.SetOffset(req.Offset)
+	qb.SetLimit(req.Limit)
+
+	query, err := qb.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, query, qb.GetParaValues()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var j types.Job
+		if err := rows.Scan(&j.Id, &j.PgFunctionId, &j.Queue, &j.Args, &j.Priority,
+			&j.State, &j.UniqueKey, &j.Attempts, &j.AttemptsMax, &j.NodeName,
+			&j.DateAdded, &j.DateRunAfter, &j.DateMilliStart, &j.DateMilliEnd,
+			&j.Result, &j.ErrorMessage); err != nil {
+
+			return nil, err
+		}
+		res.Jobs = append(res.Jobs, j)
+	}
+	rows.Close()
+
+	// get total count
+	qb.UseDollarSigns()
+	qb.Reset("SELECT")
+	qb.Reset("ORDER")
+	qb.Reset("LIMIT")
+	qb.Reset("OFFSET")
+	qb.Add("SELECT", "COUNT(*)")
+
+	query, err = qb.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRow(ctx, query, qb.GetParaValues()...).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// sets failed jobs to be executed again, with all attempts available
+func JobRetry_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Ids []int64 `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE instance.job
+		SET state = 'waiting', attempts = 0, date_run_after = $1
+		WHERE id = ANY($2)
+		AND   state = 'failed'
+	`, tools.GetTimeUnix(), req.Ids)
+
+	return nil, err
+}
+
+func JobQueueDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	var running bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT id
+			FROM instance.job
+			WHERE queue = $1
+			AND   state = 'running'
+		)
+	`, req.Name).Scan(&running); err != nil {
+		return nil, err
+	}
+	if running {
+		return nil, fmt.Errorf("cannot delete job queue '%s' while jobs are running", req.Name)
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.job_queue
+		WHERE name = $1
+	`, req.Name)
+
+	return nil, err
+}
+
+func JobQueueGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+	queues := make([]types.JobQueue, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT q.name, q.concurrency,
+			COUNT(j.id) FILTER (WHERE j.state = 'waiting'),
+			COUNT(j.id) FILTER (WHERE j.state = 'running'),
+			COUNT(j.id) FILTER (WHERE j.state = 'failed')
+		FROM instance.job_queue AS q
+		LEFT JOIN instance.job AS j ON j.queue = q.name
+		GROUP BY q.name, q.concurrency
+		ORDER BY q.name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var q types.JobQueue
+		if err := rows.Scan(&q.Name, &q.Concurrency, &q.Waiting, &q.Running, &q.Failed); err != nil {
+			return nil, err
+		}
+		queues = append(queues, q)
+	}
+	return queues, nil
+}
+
+func JobQueueSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req []types.JobQueue
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	for _, q := range req {
+		if q.Concurrency < 1 {
+			return nil, fmt.Errorf("concurrency of job queue '%s' must be at least 1", q.Name)
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.job_queue
+			SET concurrency = $1
+			WHERE name = $2
+		`, q.Concurrency, q.Name); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"r3/ldap"
 	"r3/ldap/ldap_check"
+	"r3/ldap/ldap_import"
 	"r3/types"
 
 	"github.com/jackc/pgx/v5"
@@ -41,3 +42,14 @@ func LdapCheck(reqJson json.RawMessage) (any, error) {
 	}
 	return nil, ldap_check.Run(req.Id)
 }
+
+// returns changes that a full login import would apply, without applying them
+func LdapPreview(reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Id int32 `json:"id"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return ldap_import.Preview(req.Id)
+}
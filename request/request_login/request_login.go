@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/base32"
 	"encoding/json"
+	"errors"
 	"r3/cluster"
+	"r3/handler"
 	"r3/login"
 	"r3/login/login_meta"
 	"r3/login/login_role"
 	"r3/types"
+	"slices"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
@@ -66,7 +69,7 @@ func SetTokenFixed_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, l
 }
 
 // admin requests
-func Del_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+func Del_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
 
 	var req struct {
 		Id int64 `json:"id"`
@@ -74,6 +77,9 @@ func Del_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
+	if err := checkChangeAllowed_tx(ctx, tx, req.Id, isAdmin); err != nil {
+		return nil, err
+	}
 	return nil, login.Del_tx(ctx, tx, req.Id)
 }
 func Get_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
@@ -152,7 +158,7 @@ func GetRecords_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any
 	}
 	return login.GetRecords_tx(ctx, tx, req.AttributeIdLookup, req.IdsExclude, req.ById, req.ByString)
 }
-func Set_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+func Set_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
 
 	var req struct {
 		Id               int64                       `json:"id"`
@@ -160,6 +166,7 @@ func Set_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error
 		Pass             string                      `json:"pass"`
 		Active           bool                        `json:"active"`
 		Admin            bool                        `json:"admin"`
+		AdminPermissions []string                    `json:"adminPermissions"`
 		NoAuth           bool                        `json:"noAuth"`
 		TokenExpiryHours pgtype.Int4                 `json:"tokenExpiryHours"`
 		Meta             types.LoginMeta             `json:"meta"`
@@ -171,12 +178,31 @@ func Set_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
+
+	// only full admins may grant admin permissions
+	if !isAdmin && (req.Admin || len(req.AdminPermissions) != 0) {
+		return nil, errors.New(handler.ErrUnauthorized)
+	}
+	if err := checkChangeAllowed_tx(ctx, tx, req.Id, isAdmin); err != nil {
+		return nil, err
+	}
+
 	// LDAP / OAUTH details are only used during login creation, which happens during LDAP import or OAUTH authentication
-	return login.Set_tx(ctx, tx, req.Id, req.TemplateId, pgtype.Int4{}, pgtype.Text{},
+	id, err := login.Set_tx(ctx, tx, req.Id, req.TemplateId, pgtype.Int4{}, pgtype.Text{},
 		pgtype.Int4{}, pgtype.Text{}, pgtype.Text{}, req.Name, req.Pass, req.Admin,
 		req.NoAuth, req.Active, req.TokenExpiryHours, req.Meta, req.RoleIds, req.Records)
+
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		if err := login.SetAdminPermissions_tx(ctx, tx, id, req.AdminPermissions); err != nil {
+			return nil, err
+		}
+	}
+	return id, nil
 }
-func SetMembers_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+func SetMembers_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
 
 	var req struct {
 		RoleId   uuid.UUID `json:"roleId"`
@@ -186,9 +212,29 @@ func SetMembers_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
+
+	// logins that are added to or removed from the role
+	loginIdsOld, err := login_role.GetRoleLogins_tx(ctx, tx, req.RoleId)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range loginIdsOld {
+		if !slices.Contains(req.LoginIds, id) {
+			if err := checkChangeAllowed_tx(ctx, tx, id, isAdmin); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, id := range req.LoginIds {
+		if !slices.Contains(loginIdsOld, id) {
+			if err := checkChangeAllowed_tx(ctx, tx, id, isAdmin); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return nil, login_role.SetRoleLogins_tx(ctx, tx, req.RoleId, req.LoginIds)
 }
-func Kick(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+func Kick(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
 
 	var req struct {
 		Id int64 `json:"id"`
@@ -197,9 +243,12 @@ func Kick(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error)
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
+	if err := checkChangeAllowed_tx(ctx, tx, req.Id, isAdmin); err != nil {
+		return nil, err
+	}
 	return nil, cluster.LoginDisabled_tx(ctx, tx, true, req.Id)
 }
-func Reauth_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+func Reauth_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
 
 	var req struct {
 		Id int64 `json:"id"`
@@ -208,17 +257,39 @@ func Reauth_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, er
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
+	if err := checkChangeAllowed_tx(ctx, tx, req.Id, isAdmin); err != nil {
+		return nil, err
+	}
 	return nil, cluster.LoginReauthorized_tx(ctx, tx, true, req.Id)
 }
 func ReauthAll_tx(ctx context.Context, tx pgx.Tx) (any, error) {
 	return nil, cluster.LoginReauthorizedAll_tx(ctx, tx, true)
 }
-func ResetTotp_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+func ResetTotp_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
 	var req struct {
 		Id int64 `json:"id"`
 	}
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return nil, err
 	}
+	if err := checkChangeAllowed_tx(ctx, tx, req.Id, isAdmin); err != nil {
+		return nil, err
+	}
 	return nil, login.ResetTotp_tx(ctx, tx, req.Id)
 }
+
+// logins with any admin permissions can only be changed by full admins
+// otherwise delegated user administration could take over admin accounts
+func checkChangeAllowed_tx(ctx context.Context, tx pgx.Tx, id int64, isAdmin bool) error {
+	if isAdmin || id == 0 {
+		return nil
+	}
+	isAnyAdmin, err := login.GetIsAnyAdmin_tx(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if isAnyAdmin {
+		return errors.New(handler.ErrUnauthorized)
+	}
+	return nil
+}
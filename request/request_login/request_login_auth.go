@@ -42,6 +42,19 @@ func AuthOpenId(ctx context.Context, reqJson json.RawMessage) (types.LoginAuthRe
 	return login_auth.OpenId(ctx, req.OauthClientId, req.Code, req.CodeVerifier)
 }
 
+// attempt login via one-time code of validated SAML response
+// applies login ID, admin to provided parameters if successful
+func AuthSaml(ctx context.Context, reqJson json.RawMessage) (types.LoginAuthResult, error) {
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	return login_auth.Saml(ctx, req.Code)
+}
+
 // attempt login via JWT
 // applies login ID, admin and no auth state to provided parameters if successful
 func AuthToken(ctx context.Context, reqJson json.RawMessage) (types.LoginAuthResult, error) {
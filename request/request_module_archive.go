@@ -0,0 +1,45 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/transfer"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func ModuleArchiveDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var id int64
+	if err := json.Unmarshal(reqJson, &id); err != nil {
+		return nil, err
+	}
+	return nil, transfer.ArchiveDel_tx(ctx, tx, id)
+}
+
+func ModuleArchiveGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var moduleId uuid.UUID
+	if err := json.Unmarshal(reqJson, &moduleId); err != nil {
+		return nil, err
+	}
+	return transfer.ArchiveGet_tx(ctx, tx, moduleId)
+}
+
+func ModuleArchiveGetMigrations_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var moduleId uuid.UUID
+	if err := json.Unmarshal(reqJson, &moduleId); err != nil {
+		return nil, err
+	}
+	return transfer.MigrationsGet_tx(ctx, tx, moduleId)
+}
+
+func ModuleArchiveRollback(ctx context.Context, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Id     int64 `json:"id"`
+		DryRun bool  `json:"dryRun"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return transfer.RollbackFromArchive(ctx, req.Id, req.DryRun)
+}
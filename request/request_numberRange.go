@@ -0,0 +1,27 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/schema/numberRange"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func NumberRangeDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req uuid.UUID
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, numberRange.Del_tx(ctx, tx, req)
+}
+
+func NumberRangeSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req types.NumberRange
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, numberRange.Set_tx(ctx, tx, req)
+}
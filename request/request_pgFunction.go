@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"r3/cache"
 	"r3/handler"
+	"r3/scheduler"
 	"r3/schema/pgFunction"
 	"r3/types"
 	"strings"
@@ -69,6 +70,17 @@ func PgFunctionExec_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	return returnIf, nil
 }
 
+func PgFunctionSchedulePreview_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Count    int                      `json:"count"`
+		Schedule types.PgFunctionSchedule `json:"schedule"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return scheduler.GetSchedulePreview_tx(ctx, tx, req.Schedule, req.Count)
+}
+
 func PgFunctionSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
 	var req types.PgFunction
 	if err := json.Unmarshal(reqJson, &req); err != nil {
@@ -0,0 +1,58 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"r3/handler"
+	"r3/login"
+	"r3/privacy"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func PrivacyErase_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, isAdmin bool) (any, error) {
+
+	var req struct {
+		Subject  types.PrivacySubject `json:"subject"`
+		DelLogin bool                 `json:"delLogin"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	// only full admins may delete admin logins
+	if req.DelLogin && req.Subject.LoginId.Valid && !isAdmin {
+		isAnyAdmin, err := login.GetIsAnyAdmin_tx(ctx, tx, req.Subject.LoginId.Int64)
+		if err != nil {
+			return nil, err
+		}
+		if isAnyAdmin {
+			return nil, errors.New(handler.ErrUnauthorized)
+		}
+	}
+	return privacy.Erase_tx(ctx, tx, req.Subject, req.DelLogin)
+}
+
+func PrivacyFind_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+
+	var req types.PrivacySubject
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return privacy.Find_tx(ctx, tx, req)
+}
+
+func PrivacyGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+	return privacy.Get_tx(ctx, tx)
+}
+
+func PrivacySet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+
+	var req []types.PrivacyRelation
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, privacy.Set_tx(ctx, tx, req)
+}
@@ -45,6 +45,7 @@ func PublicGet() (any, error) {
 		ProductionMode         uint64                            `json:"productionMode"`
 		PwaDomainMap           map[string]uuid.UUID              `json:"pwaDomainMap"`
 		ReposFeedback          []types.RepoFeedback              `json:"reposFeedback"`
+		SamlIdpIdMapLogin      map[int32]types.SamlIdpLogin      `json:"samlIdpIdMapLogin"`
 		SearchDictionaries     []string                          `json:"searchDictionaries"`
 		SystemMsg              types.SystemMsg                   `json:"systemMsg"`
 		TokenKeepEnable        bool                              `json:"tokenKeepEnable"`
@@ -73,6 +74,7 @@ func PublicGet() (any, error) {
 		ProductionMode:         config.GetUint64("productionMode"),
 		PwaDomainMap:           cache.GetPwaDomainMap(),
 		ReposFeedback:          cache.GetReposFeedback(),
+		SamlIdpIdMapLogin:      cache.GetSamlIdpMapLogin(),
 		SearchDictionaries:     cache.GetSearchDictionaries(),
 		SystemMsg: types.SystemMsg{
 			Date0:       config.GetUint64("systemMsgDate0"),
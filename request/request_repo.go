@@ -79,3 +79,19 @@ func RepoModuleInstall(ctx context.Context, reqJson json.RawMessage) (any, error
 	}
 	return nil, repo.InstallModules(ctx, []uuid.UUID{moduleId})
 }
+
+func RepoModuleInstallDryRun(ctx context.Context, reqJson json.RawMessage) (any, error) {
+	var moduleId uuid.UUID
+	if err := json.Unmarshal(reqJson, &moduleId); err != nil {
+		return nil, err
+	}
+	return repo.InstallModulesDryRun(ctx, []uuid.UUID{moduleId})
+}
+
+func RepoServeSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req types.RepoServe
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, repo.ServeSet_tx(ctx, tx, req)
+}
@@ -0,0 +1,110 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/cache"
+	"r3/login"
+	"r3/login/login_external"
+	"r3/login/login_metaMap"
+	"r3/login/login_roleAssign"
+	"r3/saml"
+	"r3/types"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func SamlIdpDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var id int32
+	if err := json.Unmarshal(reqJson, &id); err != nil {
+		return nil, err
+	}
+
+	if err := login.DelByExternalProvider_tx(ctx, tx, login_external.EntitySamlIdp, id); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.saml_idp
+		WHERE id = $1
+	`, id)
+	return nil, err
+}
+
+func SamlIdpGet() (any, error) {
+	return cache.GetSamlIdpMap(), nil
+}
+
+// reads identity provider metadata XML, returns identity provider with entity ID, SSO URL and certificate filled
+func SamlIdpParseMetadata(reqJson json.RawMessage) (any, error) {
+	var metadata string
+	if err := json.Unmarshal(reqJson, &metadata); err != nil {
+		return nil, err
+	}
+	return saml.ParseMetadata(metadata)
+}
+
+func SamlIdpReload_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+	return nil, cache.LoadSamlIdpMap_tx(ctx, tx)
+}
+
+func SamlIdpSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req types.SamlIdp
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	if err := saml.CheckIdp(req); err != nil {
+		return nil, err
+	}
+
+	if req.Id == 0 {
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO instance.saml_idp (login_template_id, name, entity_id, sso_url, certificate,
+				sp_entity_id, acs_url, attribute_admin, attribute_admin_value, attribute_roles,
+				attribute_username)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+			RETURNING id
+		`, req.LoginTemplateId, req.Name, req.EntityId, req.SsoUrl, req.Certificate, req.SpEntityId,
+			req.AcsUrl, req.AttributeAdmin, req.AttributeAdminValue, req.AttributeRoles,
+			req.AttributeUsername).Scan(&req.Id); err != nil {
+
+			return nil, err
+		}
+
+		// service provider URLs can refer to the ID of the new identity provider
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.saml_idp
+			SET sp_entity_id = REPLACE(sp_entity_id, '{ID}', id::TEXT),
+				acs_url = REPLACE(acs_url, '{ID}', id::TEXT)
+			WHERE id = $1
+		`, req.Id); err != nil {
+			return nil, err
+		}
+	} else {
+		req.SpEntityId = strings.ReplaceAll(req.SpEntityId, "{ID}", fmt.Sprintf("%d", req.Id))
+		req.AcsUrl = strings.ReplaceAll(req.AcsUrl, "{ID}", fmt.Sprintf("%d", req.Id))
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.saml_idp
+			SET login_template_id = $1, name = $2, entity_id = $3, sso_url = $4, certificate = $5,
+				sp_entity_id = $6, acs_url = $7, attribute_admin = $8, attribute_admin_value = $9,
+				attribute_roles = $10, attribute_username = $11
+			WHERE id = $12
+		`, req.LoginTemplateId, req.Name, req.EntityId, req.SsoUrl, req.Certificate, req.SpEntityId,
+			req.AcsUrl, req.AttributeAdmin, req.AttributeAdminValue, req.AttributeRoles,
+			req.AttributeUsername, req.Id); err != nil {
+
+			return nil, err
+		}
+	}
+	if err := login_metaMap.Set_tx(ctx, tx, login_external.EntitySamlIdp, req.Id, req.LoginMetaMap); err != nil {
+		return nil, err
+	}
+	if err := login_roleAssign.Set_tx(ctx, tx, login_external.EntitySamlIdp, req.Id, req.LoginRolesAssign); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
@@ -2,9 +2,16 @@ package request
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"r3/scheduler"
+	"r3/tools"
+	"r3/types"
+	"slices"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
@@ -15,6 +22,7 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
 		DateSuccess int64  `json:"dateSuccess"`
 	}
 	type task struct {
+		Id                   int64         `json:"id"` // schedule ID
 		Active               bool          `json:"active"`
 		ActiveOnly           bool          `json:"activeOnly"`
 		ClusterMasterOnly    bool          `json:"clusterMasterOnly"`
@@ -30,7 +38,7 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
 	tasks := make([]task, 0)
 
 	rows, err := tx.Query(ctx, `
-		SELECT fs.pg_function_id,
+		SELECT s.id, fs.pg_function_id,
 			s.pg_function_schedule_id,
 			s.date_attempt,
 			s.date_success,
@@ -70,7 +78,7 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
 	for rows.Next() {
 		var t task
 
-		if err := rows.Scan(&t.PgFunctionId, &t.PgFunctionScheduleId,
+		if err := rows.Scan(&t.Id, &t.PgFunctionId, &t.PgFunctionScheduleId,
 			&t.DateAttempt, &t.DateSuccess, &t.TaskName, &t.IntervalType,
 			&t.IntervalValue, &t.ClusterMasterOnly, &t.ActiveOnly,
 			&t.Active, &t.NodeMeta); err != nil {
@@ -81,3 +89,155 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
 	}
 	return tasks, nil
 }
+
+func schedulerRunsGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var (
+		req struct {
+			Limit      int         `json:"limit"`
+			Offset     int         `json:"offset"`
+			OnlyFailed bool        `json:"onlyFailed"`
+			ScheduleId pgtype.Int8 `json:"scheduleId"`
+		}
+		res struct {
+			Runs  []types.ScheduleRun `json:"runs"`
+			Total int                 `json:"total"`
+		}
+	)
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Runs = make([]types.ScheduleRun, 0)
+
+	var qb tools.QueryBuilder
+	qb.UseDollarSigns()
+	qb.AddList("SELECT", []string{"r.id", "r.schedule_id", "n.name", "r.date_milli_start",
+		"r.date_milli_end", "r.success", "r.error_message"})
+	qb.SetFrom("instance.schedule_run AS r")
+	qb.Add("JOIN", "LEFT JOIN instance_cluster.node AS n ON n.id = r.node_id")
+
+	if req.ScheduleId.Valid {
+		qb.Add("WHERE", "r.schedule_id = {SCHEDULE_ID}")
+		qb.AddPara("{SCHEDULE_ID}", req.ScheduleId.Int64)
+	}
+	if req.OnlyFailed {
+		qb.Add("WHERE", "r.success = FALSE")
+	}
+
+	qb.Add("ORDER", "r.date_milli_start DESC")
+	qb.SetOffset(req.Offset)
+	qb.SetLimit(req.Limit)
+
+	query, err := qb.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, query, qb.GetParaValues()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r types.ScheduleRun
+		if err := rows.Scan(&r.Id, &r.ScheduleId, &r.NodeName, &r.DateStart,
+			&r.DateEnd, &r.Success, &r.ErrorMessage); err != nil {
+
+			return nil, err
+		}
+		if r.DateEnd.Valid {
+			r.Duration = pgtype.Int8{Int64: r.DateEnd.Int64 - r.DateStart, Valid: true}
+			r.DateEnd.Int64 = r.DateEnd.Int64 / 1000
+		}
+		r.DateStart = r.DateStart / 1000
+		res.Runs = append(res.Runs, r)
+	}
+	rows.Close()
+
+	// get total count
+	qb.UseDollarSigns()
+	qb.Reset("SELECT")
+	qb.Reset("ORDER")
+	qb.Reset("LIMIT")
+	qb.Reset("OFFSET")
+	qb.Add("SELECT", "COUNT(*)")
+
+	query, err = qb.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRow(ctx, query, qb.GetParaValues()...).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func schedulerAlertsGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+	alerts := make([]types.ScheduleAlert, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, schedule_id, content, threshold, date_last_sent
+		FROM instance.schedule_alert
+		ORDER BY schedule_id ASC, content ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a types.ScheduleAlert
+		if err := rows.Scan(&a.Id, &a.ScheduleId, &a.Content, &a.Threshold, &a.DateLastSent); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
+
+// replaces all alerts of a schedule
+func schedulerAlertsSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Alerts     []types.ScheduleAlert `json:"alerts"`
+		ScheduleId int64                 `json:"scheduleId"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	contents := make([]string, 0)
+	for _, a := range req.Alerts {
+		if !slices.Contains([]string{"failures", "missed", "runtime"}, a.Content) {
+			return nil, fmt.Errorf("invalid alert content '%s'", a.Content)
+		}
+		if a.Threshold < 1 {
+			return nil, fmt.Errorf("alert threshold must be at least 1")
+		}
+		if a.Content == "runtime" {
+			reduced, err := scheduler.GetScheduleRunHistoryReduced_tx(ctx, tx, req.ScheduleId)
+			if err != nil {
+				return nil, err
+			}
+			if reduced {
+				return nil, fmt.Errorf("runtime alerts are not available for tasks with reduced run history")
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.schedule_alert (schedule_id, content, threshold, date_last_sent)
+			VALUES ($1,$2,$3,0)
+			ON CONFLICT (schedule_id, content)
+			DO UPDATE SET threshold = $3
+		`, req.ScheduleId, a.Content, a.Threshold); err != nil {
+			return nil, err
+		}
+		contents = append(contents, a.Content)
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.schedule_alert
+		WHERE schedule_id = $1
+		AND content::TEXT <> ALL($2)
+	`, req.ScheduleId, contents)
+	return nil, err
+}
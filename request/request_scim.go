@@ -0,0 +1,36 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/scim"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func ScimClientDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		Id int32 `json:"id"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, scim.Del_tx(ctx, tx, req.Id)
+}
+
+func ScimClientGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+	return scim.Get_tx(ctx, tx)
+}
+
+// returns new bearer token if one was generated, empty otherwise
+func ScimClientSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+	var req struct {
+		types.ScimClient
+		TokenRenew bool `json:"tokenRenew"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return scim.Set_tx(ctx, tx, req.ScimClient, req.TokenRenew)
+}
@@ -0,0 +1,27 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/data"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func SearchIndexGet_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+	return data.SearchDictionariesGet_tx(ctx, tx)
+}
+
+func SearchIndexReindex_tx(ctx context.Context, tx pgx.Tx) (any, error) {
+	return nil, data.SearchReindex_tx(ctx, tx)
+}
+
+func SearchIndexSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (any, error) {
+
+	var req []types.SearchDictionary
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, data.SearchDictionariesSet_tx(ctx, tx, req)
+}
@@ -0,0 +1,160 @@
+package saml
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"r3/types"
+	"regexp"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	bindingPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	bindingRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	nameIdFormat    = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	nsAssertion     = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsMetadata      = "urn:oasis:names:tc:SAML:2.0:metadata"
+	nsProtocol      = "urn:oasis:names:tc:SAML:2.0:protocol"
+	statusSuccess   = "urn:oasis:names:tc:SAML:2.0:status:Success"
+)
+
+var rxWhiteSpace = regexp.MustCompile(`\s+`)
+
+type metadataEntity struct {
+	EntityId         string `xml:"entityID,attr"`
+	IdpSsoDescriptor *struct {
+		KeyDescriptors []struct {
+			Use          string   `xml:"use,attr"`
+			Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"KeyDescriptor"`
+		SsoServices []struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+		} `xml:"SingleSignOnService"`
+	} `xml:"IDPSSODescriptor"`
+}
+
+// reads identity provider metadata XML, either single entity or entity list (first identity provider is used)
+// returns identity provider with entity ID, single sign-on URL and signing certificate
+func ParseMetadata(metadata string) (types.SamlIdp, error) {
+	var p types.SamlIdp
+	var root struct {
+		XMLName xml.Name
+		metadataEntity
+		Entities []metadataEntity `xml:"EntityDescriptor"`
+	}
+	if err := xml.Unmarshal([]byte(metadata), &root); err != nil {
+		return p, fmt.Errorf("failed to read SAML metadata, %s", err)
+	}
+
+	entities := []metadataEntity{root.metadataEntity}
+	if root.XMLName.Local == "EntitiesDescriptor" {
+		entities = root.Entities
+	}
+
+	for _, e := range entities {
+		if e.IdpSsoDescriptor == nil {
+			continue
+		}
+		p.EntityId = e.EntityId
+
+		for _, s := range e.IdpSsoDescriptor.SsoServices {
+			if s.Binding == bindingRedirect {
+				p.SsoUrl = s.Location
+				break
+			}
+		}
+		for _, k := range e.IdpSsoDescriptor.KeyDescriptors {
+			if k.Use != "" && k.Use != "signing" {
+				continue
+			}
+			if len(k.Certificates) != 0 {
+				certDer, err := base64.StdEncoding.DecodeString(rxWhiteSpace.ReplaceAllString(k.Certificates[0], ""))
+				if err != nil {
+					return p, fmt.Errorf("failed to decode signing certificate, %s", err)
+				}
+				p.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}))
+				break
+			}
+		}
+
+		if p.SsoUrl == "" {
+			return p, errors.New("SAML metadata does not contain single sign-on service with HTTP-Redirect binding")
+		}
+		if p.Certificate == "" {
+			return p, errors.New("SAML metadata does not contain signing certificate")
+		}
+		_, err := parseCertificate(p.Certificate)
+		return p, err
+	}
+	return p, errors.New("SAML metadata does not contain identity provider")
+}
+
+// checks identity provider definition before it is stored
+func CheckIdp(p types.SamlIdp) error {
+	if p.EntityId == "" || p.SpEntityId == "" {
+		return errors.New("entity IDs of SAML identity and service provider must not be empty")
+	}
+	for _, u := range []string{p.SsoUrl, p.AcsUrl} {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return err
+		}
+		if !parsed.IsAbs() {
+			return fmt.Errorf("SAML URL '%s' must be absolute", u)
+		}
+	}
+	_, err := parseCertificate(p.Certificate)
+	return err
+}
+
+// returns metadata XML of this service provider for given identity provider, used for registration at the identity provider
+func GetMetadataSp(p types.SamlIdp) ([]byte, error) {
+	doc := etree.NewDocument()
+	doc.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+
+	entity := doc.CreateElement("md:EntityDescriptor")
+	entity.CreateAttr("xmlns:md", nsMetadata)
+	entity.CreateAttr("entityID", p.SpEntityId)
+
+	sp := entity.CreateElement("md:SPSSODescriptor")
+	sp.CreateAttr("AuthnRequestsSigned", "false")
+	sp.CreateAttr("WantAssertionsSigned", "true")
+	sp.CreateAttr("protocolSupportEnumeration", nsProtocol)
+	sp.CreateElement("md:NameIDFormat").SetText(nameIdFormat)
+
+	acs := sp.CreateElement("md:AssertionConsumerService")
+	acs.CreateAttr("Binding", bindingPost)
+	acs.CreateAttr("Location", p.AcsUrl)
+	acs.CreateAttr("index", "0")
+	acs.CreateAttr("isDefault", "true")
+
+	doc.Indent(2)
+	return doc.WriteToBytes()
+}
+
+func parseCertificate(certPem string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(certPem)))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM certificate of SAML identity provider")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// returns random ID, usable as XML ID (must not start with a digit)
+func getRandomId() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("_%s", hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,211 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// local identity provider, stand-in for real identity providers during development & testing
+// it authenticates nobody, any entered name ID is confirmed with a signed assertion
+// signing key is generated on first use and lost on restart, service providers must import its metadata again
+
+// authentication request, as received by the local identity provider
+type LocalIdpRequest struct {
+	Id         string `xml:"ID,attr"`
+	AcsUrl     string `xml:"AssertionConsumerServiceURL,attr"`
+	SpEntityId string `xml:"Issuer"`
+}
+
+var localIdp struct {
+	certDer []byte
+	err     error
+	key     *rsa.PrivateKey
+	once    sync.Once
+}
+
+// returns metadata XML of local identity provider, to be imported as identity provider
+func GetMetadataLocalIdp(entityId string, ssoUrl string) ([]byte, error) {
+	_, certDer, err := getLocalIdpKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	doc.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+
+	entity := doc.CreateElement("md:EntityDescriptor")
+	entity.CreateAttr("xmlns:md", nsMetadata)
+	entity.CreateAttr("xmlns:ds", dsig.Namespace)
+	entity.CreateAttr("entityID", entityId)
+
+	idp := entity.CreateElement("md:IDPSSODescriptor")
+	idp.CreateAttr("WantAuthnRequestsSigned", "false")
+	idp.CreateAttr("protocolSupportEnumeration", nsProtocol)
+
+	key := idp.CreateElement("md:KeyDescriptor")
+	key.CreateAttr("use", "signing")
+	key.CreateElement("ds:KeyInfo").CreateElement("ds:X509Data").CreateElement("ds:X509Certificate").SetText(
+		base64.StdEncoding.EncodeToString(certDer))
+
+	idp.CreateElement("md:NameIDFormat").SetText(nameIdFormat)
+
+	sso := idp.CreateElement("md:SingleSignOnService")
+	sso.CreateAttr("Binding", bindingRedirect)
+	sso.CreateAttr("Location", ssoUrl)
+
+	doc.Indent(2)
+	return doc.WriteToBytes()
+}
+
+// reads authentication request of service provider (HTTP-Redirect binding)
+func ReadRequestLocalIdp(samlRequest string) (LocalIdpRequest, error) {
+	var req LocalIdpRequest
+
+	reqDeflated, err := base64.StdEncoding.DecodeString(rxWhiteSpace.ReplaceAllString(samlRequest, ""))
+	if err != nil {
+		return req, fmt.Errorf("failed to decode SAML request, %s", err)
+	}
+	reqXml, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(reqDeflated)), 1024*1024))
+	if err != nil {
+		return req, fmt.Errorf("failed to inflate SAML request, %s", err)
+	}
+	if err := xml.Unmarshal(reqXml, &req); err != nil {
+		return req, fmt.Errorf("failed to read SAML request, %s", err)
+	}
+	if req.Id == "" || req.AcsUrl == "" || req.SpEntityId == "" {
+		return req, errors.New("SAML request must contain ID, issuer and assertion consumer service URL")
+	}
+	return req, nil
+}
+
+// creates signed response with assertion for given name ID & attributes
+// returns base64 encoded response, to be posted to the assertion consumer service (HTTP-POST binding)
+func CreateResponseLocalIdp(entityId string, req LocalIdpRequest, nameId string, attributes map[string][]string) (string, error) {
+	key, certDer, err := getLocalIdpKeyPair()
+	if err != nil {
+		return "", err
+	}
+	responseId, err := getRandomId()
+	if err != nil {
+		return "", err
+	}
+	assertionId, err := getRandomId()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	nowText := now.Format(time.RFC3339)
+	expiryText := now.Add(time.Second * requestExpirySec).Format(time.RFC3339)
+
+	// assertion declares its own namespace, as it is signed on its own
+	assertion := etree.NewElement("saml:Assertion")
+	assertion.CreateAttr("xmlns:saml", nsAssertion)
+	assertion.CreateAttr("ID", assertionId)
+	assertion.CreateAttr("Version", "2.0")
+	assertion.CreateAttr("IssueInstant", nowText)
+	assertion.CreateElement("saml:Issuer").SetText(entityId)
+
+	subject := assertion.CreateElement("saml:Subject")
+	nameIdEl := subject.CreateElement("saml:NameID")
+	nameIdEl.CreateAttr("Format", nameIdFormat)
+	nameIdEl.SetText(nameId)
+	confirmation := subject.CreateElement("saml:SubjectConfirmation")
+	confirmation.CreateAttr("Method", "urn:oasis:names:tc:SAML:2.0:cm:bearer")
+	confirmationData := confirmation.CreateElement("saml:SubjectConfirmationData")
+	confirmationData.CreateAttr("InResponseTo", req.Id)
+	confirmationData.CreateAttr("NotOnOrAfter", expiryText)
+	confirmationData.CreateAttr("Recipient", req.AcsUrl)
+
+	conditions := assertion.CreateElement("saml:Conditions")
+	conditions.CreateAttr("NotBefore", nowText)
+	conditions.CreateAttr("NotOnOrAfter", expiryText)
+	conditions.CreateElement("saml:AudienceRestriction").CreateElement("saml:Audience").SetText(req.SpEntityId)
+
+	authn := assertion.CreateElement("saml:AuthnStatement")
+	authn.CreateAttr("AuthnInstant", nowText)
+	authn.CreateAttr("SessionIndex", assertionId)
+	authn.CreateElement("saml:AuthnContext").CreateElement("saml:AuthnContextClassRef").SetText(
+		"urn:oasis:names:tc:SAML:2.0:ac:classes:unspecified")
+
+	if len(attributes) != 0 {
+		statement := assertion.CreateElement("saml:AttributeStatement")
+		for name, values := range attributes {
+			atr := statement.CreateElement("saml:Attribute")
+			atr.CreateAttr("Name", name)
+			for _, v := range values {
+				atr.CreateElement("saml:AttributeValue").SetText(v)
+			}
+		}
+	}
+
+	signingCtx, err := dsig.NewSigningContext(key, [][]byte{certDer})
+	if err != nil {
+		return "", err
+	}
+	signingCtx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+
+	assertionSigned, err := signingCtx.SignEnveloped(assertion)
+	if err != nil {
+		return "", err
+	}
+
+	doc := etree.NewDocument()
+	response := doc.CreateElement("samlp:Response")
+	response.CreateAttr("xmlns:samlp", nsProtocol)
+	response.CreateAttr("xmlns:saml", nsAssertion)
+	response.CreateAttr("ID", responseId)
+	response.CreateAttr("Version", "2.0")
+	response.CreateAttr("IssueInstant", nowText)
+	response.CreateAttr("Destination", req.AcsUrl)
+	response.CreateAttr("InResponseTo", req.Id)
+	response.CreateElement("saml:Issuer").SetText(entityId)
+	response.CreateElement("samlp:Status").CreateElement("samlp:StatusCode").CreateAttr("Value", statusSuccess)
+	response.AddChild(assertionSigned)
+
+	responseXml, err := doc.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(responseXml), nil
+}
+
+func getLocalIdpKeyPair() (*rsa.PrivateKey, []byte, error) {
+	localIdp.once.Do(func() {
+		localIdp.key, localIdp.err = rsa.GenerateKey(rand.Reader, 2048)
+		if localIdp.err != nil {
+			return
+		}
+		serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+		if err != nil {
+			localIdp.err = err
+			return
+		}
+		template := x509.Certificate{
+			SerialNumber:          serial,
+			Subject:               pkix.Name{CommonName: "Local SAML identity provider"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().AddDate(10, 0, 0),
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+			BasicConstraintsValid: true,
+		}
+		localIdp.certDer, localIdp.err = x509.CreateCertificate(rand.Reader, &template, &template,
+			&localIdp.key.PublicKey, localIdp.key)
+	})
+	return localIdp.key, localIdp.certDer, localIdp.err
+}
@@ -0,0 +1,86 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"net/url"
+	"r3/tools"
+	"r3/types"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/jackc/pgx/v5"
+)
+
+// time in seconds, in which an authentication request must be answered by the identity provider
+const requestExpirySec = 300
+
+// creates SP-initiated authentication request and stores its ID for validating the response
+// returns URL of identity provider to redirect to (HTTP-Redirect binding)
+func CreateRequest_tx(ctx context.Context, tx pgx.Tx, p types.SamlIdp) (string, error) {
+
+	id, err := getRandomId()
+	if err != nil {
+		return "", err
+	}
+
+	// remove expired requests
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.saml_auth
+		WHERE date_expiry < $1
+	`, tools.GetTimeUnix()); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.saml_auth (id, saml_idp_id, date_expiry)
+		VALUES ($1,$2,$3)
+	`, id, p.Id, tools.GetTimeUnix()+requestExpirySec); err != nil {
+		return "", err
+	}
+
+	doc := etree.NewDocument()
+	req := doc.CreateElement("samlp:AuthnRequest")
+	req.CreateAttr("xmlns:samlp", nsProtocol)
+	req.CreateAttr("xmlns:saml", nsAssertion)
+	req.CreateAttr("ID", id)
+	req.CreateAttr("Version", "2.0")
+	req.CreateAttr("IssueInstant", time.Now().UTC().Format(time.RFC3339))
+	req.CreateAttr("Destination", p.SsoUrl)
+	req.CreateAttr("AssertionConsumerServiceURL", p.AcsUrl)
+	req.CreateAttr("ProtocolBinding", bindingPost)
+	req.CreateElement("saml:Issuer").SetText(p.SpEntityId)
+
+	policy := req.CreateElement("samlp:NameIDPolicy")
+	policy.CreateAttr("Format", nameIdFormat)
+	policy.CreateAttr("AllowCreate", "true")
+
+	reqXml, err := doc.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+
+	// HTTP-Redirect binding: raw DEFLATE, base64, URL encoded
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(reqXml); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(p.SsoUrl)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,242 @@
+package saml
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"r3/tools"
+	"r3/types"
+	"slices"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/jackc/pgx/v5"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const (
+	clockSkew     = 3 * time.Minute // tolerated time difference between service and identity provider
+	codeExpirySec = 60              // time in seconds, in which the one-time code must be redeemed by the client
+)
+
+type assertion struct {
+	Issuer  string `xml:"Issuer"`
+	Subject struct {
+		NameId        string `xml:"NameID"`
+		Confirmations []struct {
+			Method string `xml:"Method,attr"`
+			Data   struct {
+				InResponseTo string    `xml:"InResponseTo,attr"`
+				NotOnOrAfter time.Time `xml:"NotOnOrAfter,attr"`
+				Recipient    string    `xml:"Recipient,attr"`
+			} `xml:"SubjectConfirmationData"`
+		} `xml:"SubjectConfirmation"`
+	} `xml:"Subject"`
+	Conditions struct {
+		NotBefore            time.Time `xml:"NotBefore,attr"`
+		NotOnOrAfter         time.Time `xml:"NotOnOrAfter,attr"`
+		AudienceRestrictions []struct {
+			Audiences []string `xml:"Audience"`
+		} `xml:"AudienceRestriction"`
+	} `xml:"Conditions"`
+	Attributes []struct {
+		Name   string   `xml:"Name,attr"`
+		Values []string `xml:"AttributeValue"`
+	} `xml:"AttributeStatement>Attribute"`
+}
+
+// validates SAML response (HTTP-POST binding) from identity provider for a pending authentication request
+// either the response or the contained assertion must be signed by the identity provider
+// returns one-time code, which the client redeems to authenticate
+func ValidateResponse_tx(ctx context.Context, tx pgx.Tx, p types.SamlIdp, samlResponse string) (string, error) {
+
+	responseXml, err := base64.StdEncoding.DecodeString(rxWhiteSpace.ReplaceAllString(samlResponse, ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode SAML response, %s", err)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(responseXml); err != nil {
+		return "", fmt.Errorf("failed to read SAML response, %s", err)
+	}
+	responseEl := doc.Root()
+	if responseEl == nil || responseEl.Tag != "Response" || responseEl.NamespaceURI() != nsProtocol {
+		return "", errors.New("SAML response has no valid response element")
+	}
+
+	// check response status, not relevant for security as the assertion is checked regardless
+	if status := responseEl.FindElement("./Status/StatusCode"); status == nil || status.SelectAttrValue("Value", "") != statusSuccess {
+		var code, message = "", ""
+		if status != nil {
+			code = status.SelectAttrValue("Value", "")
+		}
+		if m := responseEl.FindElement("./Status/StatusMessage"); m != nil {
+			message = m.Text()
+		}
+		return "", fmt.Errorf("SAML response was not successful, status: '%s', message: '%s'", code, message)
+	}
+
+	cert, err := parseCertificate(p.Certificate)
+	if err != nil {
+		return "", err
+	}
+	validationCtx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{cert},
+	})
+	validationCtx.IdAttribute = "ID"
+
+	// validate signed response, only validated (returned) elements are used afterwards
+	responseSigned := hasSignature(responseEl)
+	if responseSigned {
+		responseEl, err = validationCtx.Validate(responseEl)
+		if err != nil {
+			return "", fmt.Errorf("failed to validate SAML response signature, %s", err)
+		}
+	}
+
+	if len(responseEl.SelectElements("EncryptedAssertion")) != 0 {
+		return "", errors.New("encrypted SAML assertions are not supported")
+	}
+	assertionEls := responseEl.SelectElements("Assertion")
+	if len(assertionEls) != 1 {
+		return "", fmt.Errorf("SAML response must contain exactly 1 assertion, %d given", len(assertionEls))
+	}
+	assertionEl := assertionEls[0]
+	if assertionEl.NamespaceURI() != nsAssertion {
+		return "", errors.New("SAML assertion has invalid namespace")
+	}
+
+	// validate signed assertion, must be signed if response is not
+	if hasSignature(assertionEl) {
+		nsCtx, err := etreeutils.NSBuildParentContext(assertionEl)
+		if err != nil {
+			return "", err
+		}
+		assertionEl, err = etreeutils.NSDetatch(nsCtx, assertionEl)
+		if err != nil {
+			return "", err
+		}
+		assertionEl, err = validationCtx.Validate(assertionEl)
+		if err != nil {
+			return "", fmt.Errorf("failed to validate SAML assertion signature, %s", err)
+		}
+	} else if !responseSigned {
+		return "", errors.New("SAML response and assertion are both unsigned")
+	}
+
+	assertionDoc := etree.NewDocument()
+	assertionDoc.SetRoot(assertionEl)
+	assertionXml, err := assertionDoc.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+	var a assertion
+	if err := xml.Unmarshal(assertionXml, &a); err != nil {
+		return "", fmt.Errorf("failed to read SAML assertion, %s", err)
+	}
+
+	// check assertion content
+	now := time.Now()
+	if a.Issuer != p.EntityId {
+		return "", fmt.Errorf("SAML assertion issuer '%s' does not match identity provider entity ID", a.Issuer)
+	}
+	if !a.Conditions.NotBefore.IsZero() && now.Add(clockSkew).Before(a.Conditions.NotBefore) {
+		return "", errors.New("SAML assertion is not yet valid")
+	}
+	if !a.Conditions.NotOnOrAfter.IsZero() && !now.Add(-clockSkew).Before(a.Conditions.NotOnOrAfter) {
+		return "", errors.New("SAML assertion has expired")
+	}
+	for _, r := range a.Conditions.AudienceRestrictions {
+		if !slices.Contains(r.Audiences, p.SpEntityId) {
+			return "", fmt.Errorf("SAML assertion is not meant for audience '%s'", p.SpEntityId)
+		}
+	}
+	if a.Subject.NameId == "" {
+		return "", errors.New("SAML assertion does not contain name ID")
+	}
+
+	// bearer confirmation must reference a pending request for this service provider
+	requestId := ""
+	for _, c := range a.Subject.Confirmations {
+		if c.Method != "urn:oasis:names:tc:SAML:2.0:cm:bearer" || c.Data.Recipient != p.AcsUrl ||
+			c.Data.InResponseTo == "" || !now.Add(-clockSkew).Before(c.Data.NotOnOrAfter) {
+			continue
+		}
+		requestId = c.Data.InResponseTo
+		break
+	}
+	if requestId == "" {
+		return "", errors.New("SAML assertion does not contain valid bearer subject confirmation")
+	}
+
+	attributes := make(map[string][]string)
+	for _, atr := range a.Attributes {
+		attributes[atr.Name] = append(attributes[atr.Name], atr.Values...)
+	}
+	attributesJson, err := json.Marshal(attributes)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := getRandomId()
+	if err != nil {
+		return "", err
+	}
+
+	// each request can only be answered once
+	tag, err := tx.Exec(ctx, `
+		UPDATE instance.saml_auth
+		SET code = $1, name_id = $2, attributes = $3, date_expiry = $4
+		WHERE id          = $5
+		AND   saml_idp_id = $6
+		AND   code        IS NULL
+		AND   date_expiry >= $7
+	`, code, a.Subject.NameId, attributesJson, tools.GetTimeUnix()+codeExpirySec,
+		requestId, p.Id, tools.GetTimeUnix())
+	if err != nil {
+		return "", err
+	}
+	if tag.RowsAffected() != 1 {
+		return "", fmt.Errorf("SAML authentication request '%s' is unknown, expired or already answered", requestId)
+	}
+	return code, nil
+}
+
+// redeems one-time code of validated SAML response
+// returns ID of identity provider, name ID and attributes of the authenticated subject
+func RedeemCode_tx(ctx context.Context, tx pgx.Tx, code string) (int32, string, map[string][]string, error) {
+
+	var idpId int32
+	var dateExpiry int64
+	var nameId string
+	var attributes map[string][]string
+
+	if err := tx.QueryRow(ctx, `
+		DELETE FROM instance.saml_auth
+		WHERE code = $1
+		RETURNING saml_idp_id, date_expiry, name_id, attributes
+	`, code).Scan(&idpId, &dateExpiry, &nameId, &attributes); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, "", nil, errors.New("unknown SAML authentication code")
+		}
+		return 0, "", nil, err
+	}
+	if dateExpiry < tools.GetTimeUnix() {
+		return 0, "", nil, errors.New("SAML authentication code has expired")
+	}
+	return idpId, nameId, attributes, nil
+}
+
+func hasSignature(el *etree.Element) bool {
+	for _, c := range el.ChildElements() {
+		if c.Tag == dsig.SignatureTag && c.NamespaceURI() == dsig.Namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -18,11 +18,14 @@ import (
 	"r3/schema"
 	"r3/spooler/doc_create"
 	"r3/spooler/file_process"
+	"r3/spooler/file_text"
+	"r3/spooler/job_execute"
 	"r3/spooler/mail_attach"
 	"r3/spooler/mail_receive"
 	"r3/spooler/mail_send"
 	"r3/spooler/rest_send"
 	"r3/tools"
+	"r3/tools/cron"
 	"slices"
 	"sync"
 	"sync/atomic"
@@ -53,7 +56,7 @@ type taskSchedule struct {
 	id                int64  // schedule ID
 	clusterMasterOnly bool   // schedule only to be executed by cluster master (instead of by all nodes)
 	interval          int64  // execution interval
-	intervalType      string // type of interval (seconds, minutes, hours, days, weeks, months, years, once)
+	intervalType      string // type of interval (seconds, minutes, hours, days, weeks, months, years, once, cron)
 	runLastUnix       int64  // unix time of last execution time of this schedule
 
 	// target day for interval types weeks/months
@@ -63,6 +66,12 @@ type taskSchedule struct {
 	atHour   int
 	atMinute int
 	atSecond int
+
+	// PG function schedule options
+	attributeIdExclude pgtype.UUID    // date attribute to read days to exclude from
+	cron               cron.Schedule  // parsed cron expression, for interval type cron
+	excludeDays        map[int64]bool // days on which the schedule does not run, as unix time at UTC midnight
+	location           *time.Location // time zone the schedule is evaluated in
 }
 
 var (
@@ -73,7 +82,7 @@ var (
 	secondsKeepNewFiles     int64          = 60 * 60 * 8     // how long to keep new files with no references (in case record is not saved yet)
 	tasks                   []task                           // all tasks
 	tasks_mx                               = &sync.RWMutex{} // control access to tasks
-	tasksDisabledMirrorMode []string       = []string{"adminMails", "backupRun", "mailAttach", "mailRetrieve", "mailSend", "restExecute"}
+	tasksDisabledMirrorMode []string       = []string{"adminMails", "backupRun", "backupVerify", "mailAttach", "mailRetrieve", "mailSend", "restExecute"}
 	OsExit                  chan os.Signal = make(chan os.Signal)
 
 	// main loop
@@ -89,6 +98,9 @@ func Start() {
 	loadTasks.Store(true)
 	nextExecutionUnix.Store(0)
 
+	// process cluster events when notified, in addition to scheduled processing
+	go clusterListenEvents()
+
 	for {
 		time.Sleep(loopInterval)
 		if loopStopping.Load() {
@@ -233,12 +245,38 @@ func runTaskByIndex(taskIndex int) {
 			t.nameLog), err)
 	}
 
+	// high frequency system tasks store their run history only after execution & not for every run
+	var runId int64
+	var errRun error
+	historyReduced := getTaskRunHistoryReduced(t)
+	dateMilliStart := tools.GetTimeUnixMilli()
+
+	if !historyReduced {
+		runId, errRun = storeTaskRunStart(t)
+		if errRun != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to store its run history",
+				t.nameLog), errRun)
+		}
+	}
+
 	if t.isSystemTask {
 		err = t.fn()
 	} else {
 		err = runPgFunction(t.pgFunctionId)
 	}
 
+	if historyReduced {
+		if err := storeTaskRunReduced(t, dateMilliStart, err); err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to store its run history",
+				t.nameLog), err)
+		}
+	} else if errRun == nil {
+		if err := storeTaskRunEnd(runId, err); err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to store its run history",
+				t.nameLog), err)
+		}
+	}
+
 	if err == nil {
 		if err := storeTaskDate(t, "success"); err != nil {
 			log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to update its meta data", t.nameLog), err)
@@ -256,6 +294,13 @@ func runTaskByIndex(taskIndex int) {
 	} else {
 		s := t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext]
 		s.runLastUnix = tools.GetTimeUnix()
+
+		// days to exclude might have changed since last run
+		if s.attributeIdExclude.Valid {
+			if err := updateScheduleExcludeDays(&s); err != nil {
+				log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to update days to exclude", t.nameLog), err)
+			}
+		}
 		t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext] = s
 		t.runNextUnix, t.pgFunctionScheduleIdNext = getNextRunScheduleFromTask(t)
 	}
@@ -343,6 +388,9 @@ func load() error {
 		case "backupRun":
 			t.nameLog = "Integrated full backups"
 			t.fn = backup.Run
+		case "backupVerify":
+			t.nameLog = "Verification of integrated backups"
+			t.fn = backup.Verify
 		case "cleanupBruteforce":
 			t.nameLog = "Cleanup of bruteforce cache"
 			t.fn = bruteforce.ClearHostMap
@@ -352,6 +400,9 @@ func load() error {
 		case "cleanupDataLogs":
 			t.nameLog = "Cleanup of data change logs"
 			t.fn = data.DelLogsBackground
+		case "cleanupDataRecycle":
+			t.nameLog = "Cleanup of data recycle bin"
+			t.fn = data.DelRecycleBackground
 		case "cleanupLogs":
 			t.nameLog = "Cleanup of system logs"
 			t.fn = cleanupLogs
@@ -376,12 +427,18 @@ func load() error {
 		case "filesProcess":
 			t.nameLog = "File processing"
 			t.fn = file_process.DoAll
+		case "filesTextExtract":
+			t.nameLog = "File text extraction"
+			t.fn = file_text.DoAll
 		case "httpCertRenew":
 			t.nameLog = "Reload of updated HTTP certificate"
 			t.fn = cache.CheckRenewCert
 		case "importLdapLogins":
 			t.nameLog = "Import from LDAP connections"
 			t.fn = ldap_import.RunAll
+		case "jobsExecute":
+			t.nameLog = "Background job execution"
+			t.fn = job_execute.DoAll
 		case "mailAttach":
 			t.nameLog = "Email attachment transfer"
 			t.fn = mail_attach.DoAll
@@ -397,6 +454,9 @@ func load() error {
 		case "restExecute":
 			t.nameLog = "REST call execution"
 			t.fn = rest_send.DoAll
+		case "searchReindex":
+			t.nameLog = "Rebuild of global search index"
+			t.fn = data.SearchReindex
 		case "systemMsgMaintenance":
 			t.nameLog = "Set maintenance mode after system message"
 			t.fn = systemMsgMaintenance
@@ -414,9 +474,19 @@ func load() error {
 	if cache.GetIsClusterMaster() {
 		pgFunctionIdMapTasks := make(map[uuid.UUID]task)
 
-		rows, err = db.Pool.Query(context.Background(), `
+		ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+		defer ctxCanc()
+
+		tx, err := db.Pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		rows, err = db.Pool.Query(ctx, `
 			SELECT f.name, fs.pg_function_id, fs.id, fs.at_hour, fs.at_minute,
 				fs.at_second, fs.at_day, fs.interval_type, fs.interval_value,
+				fs.cron_expr, fs.time_zone, fs.attribute_id_exclude,
 				s.id, s.date_attempt
 			FROM app.pg_function AS f
 			INNER JOIN app.pg_function_schedule AS fs ON fs.pg_function_id = f.id
@@ -435,17 +505,25 @@ func load() error {
 			var t task
 			var s taskSchedule
 			var pgFunctionScheduleId uuid.UUID
+			var cronExpr, timeZone pgtype.Text
+			var attributeIdExclude pgtype.UUID
 
 			t.pgFunctionScheduleIdMap = make(map[uuid.UUID]taskSchedule)
 
 			if err := rows.Scan(&t.name, &t.pgFunctionId, &pgFunctionScheduleId,
 				&s.atHour, &s.atMinute, &s.atSecond, &s.atDay, &s.intervalType,
-				&s.interval, &s.id, &s.runLastUnix); err != nil {
+				&s.interval, &cronExpr, &timeZone, &attributeIdExclude, &s.id,
+				&s.runLastUnix); err != nil {
 
 				return err
 			}
 			t.nameLog = t.name
 
+			if err := setScheduleOptions_tx(ctx, tx, &s, cronExpr, timeZone, attributeIdExclude); err != nil {
+				log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' has invalid schedule, it is ignored", t.nameLog), err)
+				continue
+			}
+
 			if _, exists := pgFunctionIdMapTasks[t.pgFunctionId]; exists {
 				t = pgFunctionIdMapTasks[t.pgFunctionId]
 			}
@@ -520,16 +598,39 @@ func getNextRunFromSchedule(s taskSchedule) int64 {
 	// simple intervals, just add seconds
 	switch s.intervalType {
 	case "seconds":
-		return s.runLastUnix + s.interval
+		return s.getNextRunNotExcluded(s.runLastUnix + s.interval)
 	case "minutes":
-		return s.runLastUnix + (s.interval * 60)
+		return s.getNextRunNotExcluded(s.runLastUnix + (s.interval * 60))
 	case "hours":
-		return s.runLastUnix + (s.interval * 60 * 60)
+		return s.getNextRunNotExcluded(s.runLastUnix + (s.interval * 60 * 60))
+	}
+
+	// use server time zone if schedule has none (system tasks)
+	loc := s.location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	// cron expressions, run at next matching time
+	// schedules that never ran or missed runs for a long time are continued from now
+	if s.intervalType == "cron" {
+		from := s.runLastUnix
+		if from == 0 {
+			from = tools.GetTimeUnix()
+		}
+		tm, found := s.cron.Next(time.Unix(from, 0).In(loc), s.isExcludedDay)
+		if !found && from < tools.GetTimeUnix() {
+			tm, found = s.cron.Next(time.Unix(tools.GetTimeUnix(), 0).In(loc), s.isExcludedDay)
+		}
+		if !found {
+			return -1
+		}
+		return tm.Unix()
 	}
 
 	// more complex intervals, add dates and set to target day/time
-	// as no timezone is defined, tm will be in local time, which will affect all date operations
-	tm := time.Unix(s.runLastUnix, 0)
+	// all date operations happen in the schedule time zone, keeping target times across DST changes
+	tm := time.Unix(s.runLastUnix, 0).In(loc)
 
 	switch s.intervalType {
 	case "days":
@@ -564,11 +665,55 @@ func getNextRunFromSchedule(s taskSchedule) int64 {
 		targetMonth = 1
 	}
 
-	// apply target month/day and time at local time
+	// apply target month/day and time at schedule time zone
 	tm = time.Date(tm.Year(), targetMonth, targetDay, s.atHour, s.atMinute,
 		s.atSecond, 0, tm.Location())
 
-	return tm.Unix()
+	return s.getNextRunNotExcluded(tm.Unix())
+}
+
+// moves next run to the first day that is not excluded
+// sub-day intervals continue at the start of that day, others keep their target time
+func (s taskSchedule) getNextRunNotExcluded(nextRun int64) int64 {
+	if len(s.excludeDays) == 0 {
+		return nextRun
+	}
+
+	loc := s.location
+	if loc == nil {
+		loc = time.Local
+	}
+	tm := time.Unix(nextRun, 0).In(loc)
+
+	for i := 0; i < excludeDaysLookupMax; i++ {
+		y, m, d := tm.Date()
+		if !s.isExcludedDay(y, m, d) {
+			return tm.Unix()
+		}
+		switch s.intervalType {
+		case "seconds", "minutes", "hours":
+			tm = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		default:
+			tm = tm.AddDate(0, 0, 1)
+		}
+	}
+	return -1
+}
+func (s taskSchedule) isExcludedDay(y int, m time.Month, d int) bool {
+	return s.excludeDays[time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()]
+}
+
+func updateScheduleExcludeDays(s *taskSchedule) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	return setScheduleExcludeDays_tx(ctx, tx, s)
 }
 
 func storeTaskDate(t task, dateContent string) error {
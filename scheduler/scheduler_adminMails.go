@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"html"
 	"r3/config"
 	"r3/db"
 	"r3/log"
@@ -22,6 +23,10 @@ func adminMails() error {
 		licenseExpirationSubject     string
 		oauthClientExpirationBody    string
 		oauthClientExpirationSubject string
+		scheduleAlertFailuresBody    string
+		scheduleAlertMissedBody      string
+		scheduleAlertRuntimeBody     string
+		scheduleAlertSubject         string
 	}{
 		intro: `<p>You are receiving this message, because your email address has been added to the REI3 admin notification list.</p>
 		<p>To change this setting, please visit your REI3 instance: {URL}</p>`,
@@ -29,17 +34,22 @@ func adminMails() error {
 		licenseExpirationSubject:     `Your REI3 Professional license is about to expire`,
 		oauthClientExpirationBody:    `<p>Your OAuth client expires on: {DATE}</p>`,
 		oauthClientExpirationSubject: `Your REI3 OAuth client is about to expire`,
+		scheduleAlertFailuresBody:    `<p>Task '{NAME}' failed {COUNT} time(s) in a row. Last error: {ERROR}</p>`,
+		scheduleAlertMissedBody:      `<p>Task '{NAME}' was scheduled to run at {DATE} but has not been executed.</p>`,
+		scheduleAlertRuntimeBody:     `<p>Task '{NAME}' ran for {COUNT} second(s), exceeding the limit of {THRESHOLD} second(s).</p>`,
+		scheduleAlertSubject:         `REI3 scheduler alert for task '{NAME}'`,
 	}
 
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
 	defer ctxCanc()
 
-	var sendMail = func(subject string, body string, dateExpiration int64, reason string) error {
+	// sends mail to admin receivers, returns false if there are none
+	var sendMail_tx = func(tx pgx.Tx, subject string, body string, dateExpiration int64) (bool, error) {
 		// get mail receivers
 		toList := config.GetStringSlice("adminMailAddresses")
 		if len(toList) == 0 {
 			log.Warning(log.ContextServer, "cannot send admin notification mails", fmt.Errorf("no mail receivers defined"))
-			return nil
+			return false, nil
 		}
 
 		// apply intro
@@ -49,15 +59,22 @@ func adminMails() error {
 		body = strings.Replace(body, "{URL}", config.GetString("publicHostName"), -1)
 		body = strings.Replace(body, "{DATE}", time.Unix(dateExpiration, 0).String(), -1)
 
+		if _, err := tx.Exec(ctx, `
+			SELECT instance.mail_send($1,$2,$3)
+		`, subject, body, strings.Join(toList, ",")); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	var sendMail = func(subject string, body string, dateExpiration int64, reason string) error {
 		tx, err := db.Pool.Begin(ctx)
 		if err != nil {
 			return err
 		}
 		defer tx.Rollback(ctx)
 
-		if _, err := tx.Exec(ctx, `
-			SELECT instance.mail_send($1,$2,$3)
-		`, subject, body, strings.Join(toList, ",")); err != nil {
+		if sent, err := sendMail_tx(tx, subject, body, dateExpiration); err != nil || !sent {
 			return err
 		}
 
@@ -71,6 +88,43 @@ func adminMails() error {
 		return tx.Commit(ctx)
 	}
 
+	var sendMailScheduleAlert = func(alert scheduleAlert) error {
+		subject := strings.Replace(templates.scheduleAlertSubject, "{NAME}", alert.name, -1)
+
+		var body string
+		switch alert.content {
+		case "failures":
+			body = templates.scheduleAlertFailuresBody
+		case "missed":
+			body = templates.scheduleAlertMissedBody
+		case "runtime":
+			body = templates.scheduleAlertRuntimeBody
+		}
+		body = strings.Replace(body, "{NAME}", html.EscapeString(alert.name), -1)
+		body = strings.Replace(body, "{COUNT}", fmt.Sprintf("%d", alert.count), -1)
+		body = strings.Replace(body, "{ERROR}", html.EscapeString(alert.errorMessage), -1)
+		body = strings.Replace(body, "{THRESHOLD}", fmt.Sprintf("%d", alert.threshold), -1)
+
+		tx, err := db.Pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if sent, err := sendMail_tx(tx, subject, body, alert.date); err != nil || !sent {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.schedule_alert
+			SET date_last_sent = $1
+			WHERE id = $2
+		`, tools.GetTimeUnix(), alert.id); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
 	// collect admin mail definitions
 	type adminMail struct {
 		reason         string
@@ -150,5 +204,16 @@ func adminMails() error {
 			reasonsSent = append(reasonsSent, am.reason)
 		}
 	}
+
+	// send scheduler alerts
+	alerts, err := getScheduleAlertsDue(ctx)
+	if err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		if err := sendMailScheduleAlert(alert); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -55,6 +55,10 @@ func cleanupLogs() error {
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
 	defer ctxCanc()
 
+	if err := cleanupScheduleRuns(ctx); err != nil {
+		return err
+	}
+
 	keepForDays := config.GetUint64("logsKeepDays")
 	if keepForDays == 0 {
 		return nil
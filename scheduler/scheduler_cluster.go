@@ -9,14 +9,69 @@ import (
 	"r3/db"
 	"r3/log"
 	"r3/types"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
+var clusterProcessEvents_mx sync.Mutex
+
+// listens to database notifications, to process cluster events as soon as they are created
+// scheduled event processing remains as fallback, in case notifications are missed
+func clusterListenEvents() {
+	process := make(chan bool, 1)
+	go func() {
+		for range process {
+			if err := clusterProcessEvents(); err != nil {
+				log.Error(log.ContextCluster, "failed to process cluster events", err)
+			}
+		}
+	}()
+
+	for !loopStopping.Load() {
+		if err := clusterListen(process); err != nil {
+			log.Warning(log.ContextCluster, "lost listener for cluster events, retrying", err)
+		}
+		time.Sleep(time.Second * 5)
+	}
+}
+func clusterListen(process chan bool) error {
+	ctx := context.Background()
+
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		conn.Exec(ctx, `UNLISTEN *`)
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, `LISTEN r3_node_event`); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := conn.Conn().WaitForNotification(ctx); err != nil {
+			return err
+		}
+
+		// notifications received while events are being processed, are handled by the next run
+		select {
+		case process <- true:
+		default:
+		}
+	}
+}
+
 // collect cluster events from shared database for node to react to
 func clusterProcessEvents() error {
+	clusterProcessEvents_mx.Lock()
+	defer clusterProcessEvents_mx.Unlock()
+
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
 	defer ctxCanc()
 
@@ -63,20 +118,23 @@ func clusterProcessEvents() error {
 
 	// react to collected events
 	collectionUpdates := make([]types.ClusterEventCollectionUpdated, 0)
+	recordsChanged := make([]types.ClusterEventRecordsChanged, 0)
 
 	for _, e := range events {
-		if err := clusterProcessEvent(ctx, tx, e, &collectionUpdates); err != nil {
+		if err := clusterProcessEvent(ctx, tx, e, &collectionUpdates, &recordsChanged); err != nil {
 			return err
 		}
 	}
 
-	// apply collection updates
+	// apply collection updates & record changes
 	cluster.CollectionsUpdated(collectionUpdates)
+	cluster.RecordsChanged(recordsChanged)
 
 	return tx.Commit(ctx)
 }
 
-func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent, collectionUpdates *[]types.ClusterEventCollectionUpdated) error {
+func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent,
+	collectionUpdates *[]types.ClusterEventCollectionUpdated, recordsChanged *[]types.ClusterEventRecordsChanged) error {
 
 	log.Info(log.ContextCluster, fmt.Sprintf("node is reacting to event '%s'", e.Content))
 	var err error
@@ -142,6 +200,13 @@ func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent, c
 			return err
 		}
 		err = cluster.MasterAssigned(p.State)
+	case "recordsChanged":
+		var p types.ClusterEventRecordsChanged
+		if err := json.Unmarshal(jsonPayload, &p); err != nil {
+			return err
+		}
+		*recordsChanged = append(*recordsChanged, p)
+		err = nil
 	case "reposChanged":
 		err = cluster.ReposChanged(ctx, tx, false)
 	case "schemaChanged":
@@ -0,0 +1,275 @@
+package scheduler
+
+import (
+	"context"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/tools"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// system tasks running more often store only failed runs & changes to their run outcome
+	// high frequency tasks (like background job execution) would otherwise create millions of history entries
+	runHistoryReducedIntervalMax int64 = 600
+
+	runSuccessLastMap    = make(map[int64]bool) // outcome of last stored run by schedule ID, for tasks with reduced run history
+	runSuccessLastMap_mx = &sync.Mutex{}
+)
+
+type scheduleAlert struct {
+	id           int64  // alert ID
+	scheduleId   int64  // schedule ID
+	content      string // failures, missed, runtime
+	count        int64  // failures: consecutive failed runs, runtime: seconds of longest run
+	date         int64  // missed: unix time at which the task should have run
+	dateLastSent int64  // unix time at which the alert was last sent
+	errorMessage string // failures: last error
+	name         string // task name
+	threshold    int64
+}
+
+// returns the ID of the schedule that the task is executing
+func getTaskScheduleId(t task) int64 {
+	if t.isSystemTask {
+		return t.taskSchedule.id
+	}
+	return t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext].id
+}
+
+// returns whether the task only stores failed runs & changes to its run outcome in its run history
+func getTaskRunHistoryReduced(t task) bool {
+	return t.isSystemTask && t.taskSchedule.interval < runHistoryReducedIntervalMax
+}
+
+// returns whether the schedule belongs to a system task that only stores failed runs & changes to its run outcome
+// runtime alerts are not available for these tasks, as neither successful nor running executions are stored
+func GetScheduleRunHistoryReduced_tx(ctx context.Context, tx pgx.Tx, scheduleId int64) (bool, error) {
+	var reduced bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM instance.schedule AS s
+			INNER JOIN instance.task AS t ON t.name = s.task_name
+			WHERE s.id = $1
+			AND   t.interval_seconds < $2
+		)
+	`, scheduleId, runHistoryReducedIntervalMax).Scan(&reduced)
+	return reduced, err
+}
+
+// stores start of task execution in run history, returns run ID
+func storeTaskRunStart(t task) (int64, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	var runId int64
+	err := db.Pool.QueryRow(ctx, `
+		INSERT INTO instance.schedule_run (schedule_id, node_id, date_milli_start)
+		VALUES ($1,$2,$3)
+		RETURNING id
+	`, getTaskScheduleId(t), cache.GetNodeId(), tools.GetTimeUnixMilli()).Scan(&runId)
+	return runId, err
+}
+
+// stores end and outcome of task execution in run history
+func storeTaskRunEnd(runId int64, errRun error) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	var errMessage any = nil
+	if errRun != nil {
+		errMessage = errRun.Error()
+	}
+
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE instance.schedule_run
+		SET date_milli_end = $1, success = $2, error_message = $3
+		WHERE id = $4
+	`, tools.GetTimeUnixMilli(), errRun == nil, errMessage, runId)
+	return err
+}
+
+// stores finished task execution in reduced run history
+// failed runs are always stored, successful runs only if the previous stored run failed or the outcome is unknown (after startup)
+func storeTaskRunReduced(t task, dateMilliStart int64, errRun error) error {
+	scheduleId := getTaskScheduleId(t)
+
+	runSuccessLastMap_mx.Lock()
+	successLast, exists := runSuccessLastMap[scheduleId]
+	runSuccessLastMap_mx.Unlock()
+
+	if errRun == nil && exists && successLast {
+		return nil
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	var errMessage any = nil
+	if errRun != nil {
+		errMessage = errRun.Error()
+	}
+
+	if _, err := db.Pool.Exec(ctx, `
+		INSERT INTO instance.schedule_run (schedule_id, node_id,
+			date_milli_start, date_milli_end, success, error_message)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, scheduleId, cache.GetNodeId(), dateMilliStart, tools.GetTimeUnixMilli(),
+		errRun == nil, errMessage); err != nil {
+
+		return err
+	}
+
+	runSuccessLastMap_mx.Lock()
+	runSuccessLastMap[scheduleId] = errRun == nil
+	runSuccessLastMap_mx.Unlock()
+	return nil
+}
+
+// deletes expired run history entries
+func cleanupScheduleRuns(ctx context.Context) error {
+	keepForDays := config.GetUint64("scheduleRunsKeepDays")
+	if keepForDays == 0 {
+		return nil
+	}
+
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.schedule_run
+		WHERE date_milli_start < $1
+	`, (tools.GetTimeUnix()-(secondsOneDay*int64(keepForDays)))*1000)
+	return err
+}
+
+// returns scheduler alerts that reached their thresholds since they were last sent
+func getScheduleAlertsDue(ctx context.Context) ([]scheduleAlert, error) {
+	alerts := make([]scheduleAlert, 0)
+	alertsDue := make([]scheduleAlert, 0)
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT a.id, a.schedule_id, a.content, a.threshold, a.date_last_sent,
+			COALESCE(s.task_name, CONCAT(m.name, '.', f.name))
+		FROM instance.schedule_alert AS a
+		INNER JOIN instance.schedule        AS s  ON s.id  = a.schedule_id
+		LEFT  JOIN app.pg_function_schedule AS fs ON fs.id = s.pg_function_schedule_id
+		LEFT  JOIN app.pg_function          AS f  ON f.id  = fs.pg_function_id
+		LEFT  JOIN app.module               AS m  ON m.id  = f.module_id
+		LEFT  JOIN instance.task            AS t  ON t.name = s.task_name
+		
+		-- runs of tasks with reduced run history are not stored while running or if successful
+		WHERE a.content <> 'runtime'
+		OR    t.interval_seconds IS NULL
+		OR    t.interval_seconds >= $1
+	`, runHistoryReducedIntervalMax)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var a scheduleAlert
+		if err := rows.Scan(&a.id, &a.scheduleId, &a.content, &a.threshold, &a.dateLastSent, &a.name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	rows.Close()
+
+	now := tools.GetTimeUnix()
+	for _, a := range alerts {
+		switch a.content {
+		case "failures":
+			// failed runs since last successful run, alert once per streak of failures
+			var dateFirstFailed pgtype.Int8
+			var errorMessage pgtype.Text
+			if err := db.Pool.QueryRow(ctx, `
+				SELECT COUNT(*), MIN(r.date_milli_start), (
+					SELECT error_message
+					FROM instance.schedule_run
+					WHERE schedule_id = $1
+					AND   success = FALSE
+					ORDER BY date_milli_start DESC
+					LIMIT 1
+				)
+				FROM instance.schedule_run AS r
+				WHERE r.schedule_id = $1
+				AND   r.success = FALSE
+				AND   r.date_milli_start > COALESCE((
+					SELECT MAX(date_milli_start)
+					FROM instance.schedule_run
+					WHERE schedule_id = $1
+					AND   success
+				),0)
+			`, a.scheduleId).Scan(&a.count, &dateFirstFailed, &errorMessage); err != nil {
+				return nil, err
+			}
+			if a.count < a.threshold || dateFirstFailed.Int64 < a.dateLastSent*1000 {
+				continue
+			}
+			a.errorMessage = errorMessage.String
+
+		case "runtime":
+			// runs (finished or still running) exceeding the runtime threshold, started since the alert was last sent
+			var durationMax pgtype.Int8
+			if err := db.Pool.QueryRow(ctx, `
+				SELECT MAX(COALESCE(date_milli_end, $2) - date_milli_start)
+				FROM instance.schedule_run
+				WHERE schedule_id = $1
+				AND   date_milli_start > $3
+				AND   COALESCE(date_milli_end, $2) - date_milli_start > $4
+			`, a.scheduleId, now*1000, a.dateLastSent*1000, a.threshold*1000).Scan(&durationMax); err != nil {
+				return nil, err
+			}
+			if !durationMax.Valid {
+				continue
+			}
+			a.count = durationMax.Int64 / 1000
+
+		case "missed":
+			// schedule is overdue by more than the threshold, alert once per missed execution
+			dateExpected, found := getScheduleRunExpected(a.scheduleId)
+			if !found || now < dateExpected+a.threshold || a.dateLastSent >= dateExpected {
+				continue
+			}
+			a.date = dateExpected
+
+		default:
+			continue
+		}
+		alertsDue = append(alertsDue, a)
+	}
+	return alertsDue, nil
+}
+
+// returns the unix time at which a loaded schedule is expected to run next
+// running tasks are ignored, as they cannot miss their execution
+func getScheduleRunExpected(scheduleId int64) (int64, bool) {
+	tasks_mx.RLock()
+	defer tasks_mx.RUnlock()
+
+	for _, t := range tasks {
+		if t.running {
+			continue
+		}
+
+		var schedules []taskSchedule
+		if t.isSystemTask {
+			schedules = append(schedules, t.taskSchedule)
+		} else {
+			for _, s := range t.pgFunctionScheduleIdMap {
+				schedules = append(schedules, s)
+			}
+		}
+		for _, s := range schedules {
+			if s.id != scheduleId {
+				continue
+			}
+			next := getNextRunFromSchedule(s)
+			return next, next != -1
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"r3/schema"
+	"r3/tools"
+	"r3/tools/cron"
+	"r3/types"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	previewCountMax              = 100              // max. number of run times to preview
+	excludeDaysLookupMax         = 366 * 5          // max. number of days to skip when looking for a day that is not excluded
+	secondsExcludeDaysKeep int64 = 60 * 60 * 24 * 7 // how long excluded days in the past are kept
+)
+
+// returns the next run times of a PG function schedule, starting from now
+func GetSchedulePreview_tx(ctx context.Context, tx pgx.Tx, ps types.PgFunctionSchedule, count int) ([]int64, error) {
+
+	if count < 1 || count > previewCountMax {
+		count = previewCountMax
+	}
+
+	s := taskSchedule{
+		atDay:        ps.AtDay,
+		atHour:       ps.AtHour,
+		atMinute:     ps.AtMinute,
+		atSecond:     ps.AtSecond,
+		interval:     int64(ps.IntervalValue),
+		intervalType: ps.IntervalType,
+		runLastUnix:  tools.GetTimeUnix(),
+	}
+	if err := setScheduleOptions_tx(ctx, tx, &s, ps.CronExpr, ps.TimeZone, ps.AttributeIdExclude); err != nil {
+		return nil, err
+	}
+
+	// 'once' runs immediately, only if never executed before
+	if s.intervalType == "once" {
+		return []int64{s.runLastUnix}, nil
+	}
+
+	out := make([]int64, 0)
+	for len(out) < count {
+		next := getNextRunFromSchedule(s)
+		if next == -1 || next <= s.runLastUnix {
+			break
+		}
+		out = append(out, next)
+		s.runLastUnix = next
+	}
+	return out, nil
+}
+
+// applies cron expression, time zone & days to exclude to task schedule
+func setScheduleOptions_tx(ctx context.Context, tx pgx.Tx, s *taskSchedule, cronExpr pgtype.Text,
+	timeZone pgtype.Text, attributeIdExclude pgtype.UUID) error {
+
+	var err error
+	if s.intervalType == "cron" {
+		s.cron, err = cron.Parse(cronExpr.String)
+		if err != nil {
+			return fmt.Errorf("invalid cron expression '%s', %s", cronExpr.String, err)
+		}
+	}
+
+	s.location = time.Local
+	if timeZone.Valid {
+		s.location, err = time.LoadLocation(timeZone.String)
+		if err != nil {
+			return fmt.Errorf("invalid time zone '%s', %s", timeZone.String, err)
+		}
+	}
+
+	s.attributeIdExclude = attributeIdExclude
+	return setScheduleExcludeDays_tx(ctx, tx, s)
+}
+
+// reads days to exclude from the date attribute of the schedule
+func setScheduleExcludeDays_tx(ctx context.Context, tx pgx.Tx, s *taskSchedule) error {
+	s.excludeDays = make(map[int64]bool)
+
+	if !s.attributeIdExclude.Valid {
+		return nil
+	}
+
+	modName, relName, atrName, _, err := schema.GetAttributeDetailsById_tx(ctx, tx,
+		uuid.UUID(s.attributeIdExclude.Bytes))
+
+	if err != nil {
+		return err
+	}
+
+	// date attribute values are unix times at UTC midnight
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT DISTINCT "%s"
+		FROM "%s"."%s"
+		WHERE "%s" >= $1
+	`, atrName, modName, relName, atrName), tools.GetTimeUnix()-secondsExcludeDaysKeep)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day int64
+		if err := rows.Scan(&day); err != nil {
+			return err
+		}
+		s.excludeDays[day] = true
+	}
+	return rows.Err()
+}
@@ -57,6 +57,11 @@ func Del_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
 		}
 	}
 
+	// remove attribute from validation rules of other attributes
+	if err := delValidationScopes_tx(ctx, tx, id); err != nil {
+		return err
+	}
+
 	// delete attribute reference
 	_, err = tx.Exec(ctx, `DELETE FROM app.attribute WHERE id = $1`, id)
 	return err
@@ -100,6 +105,10 @@ func Get_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID) ([]types.Attri
 		if err != nil {
 			return nil, err
 		}
+		attributes[i].Validations, err = getValidations_tx(ctx, tx, atr.Id)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return attributes, nil
 }
@@ -464,6 +473,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute, fromLocal bool)
 		}
 	}
 
+	// set validation rules
+	if err := setValidations_tx(ctx, tx, atr); err != nil {
+		return err
+	}
+
 	// set captions
 	return caption.Set_tx(ctx, tx, atr.Id, atr.Captions)
 }
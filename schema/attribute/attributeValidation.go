@@ -0,0 +1,199 @@
+package attribute
+
+import (
+	"context"
+	"fmt"
+	"r3/schema"
+	"r3/types"
+	"slices"
+	"strconv"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var validationContents = []string{"regex", "min", "max", "values", "compare", "unique"}
+var validationOperators = []string{"=", "<>", "<", "<=", ">", ">="}
+
+func getValidations_tx(ctx context.Context, tx pgx.Tx, attributeId uuid.UUID) ([]types.AttributeValidation, error) {
+	validations := make([]types.AttributeValidation, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT content, value, values, operator, attribute_id_compare, attribute_ids_scope
+		FROM app.attribute_validation
+		WHERE attribute_id = $1
+		ORDER BY position ASC
+	`, attributeId)
+	if err != nil {
+		return validations, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v types.AttributeValidation
+
+		if err := rows.Scan(&v.Content, &v.Value, &v.Values, &v.Operator,
+			&v.AttributeIdCompare, &v.AttributeIdsScope); err != nil {
+
+			return validations, err
+		}
+		validations = append(validations, v)
+	}
+	return validations, nil
+}
+
+// sets validation rules of existing attribute
+// used by imports, as rules can refer to attributes that did not exist when their attribute was set
+func SetValidations_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
+	return setValidations_tx(ctx, tx, atr)
+}
+
+func setValidations_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.attribute_validation
+		WHERE attribute_id = $1
+	`, atr.Id); err != nil {
+		return err
+	}
+
+	for i, v := range atr.Validations {
+		if v.Values == nil {
+			v.Values = make([]string, 0)
+		}
+		if v.AttributeIdsScope == nil {
+			v.AttributeIdsScope = make([]uuid.UUID, 0)
+		}
+		if err := checkValidation_tx(ctx, tx, atr, v); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.attribute_validation (
+				attribute_id, position, content, value, values,
+				operator, attribute_id_compare, attribute_ids_scope
+			)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		`, atr.Id, i, v.Content, v.Value, v.Values, v.Operator,
+			v.AttributeIdCompare, v.AttributeIdsScope); err != nil {
+
+			return err
+		}
+	}
+	return nil
+}
+
+// removes attribute from uniqueness scopes of validation rules
+// rules comparing with the attribute are removed via foreign key
+func delValidationScopes_tx(ctx context.Context, tx pgx.Tx, attributeId uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE app.attribute_validation
+		SET attribute_ids_scope = ARRAY_REMOVE(attribute_ids_scope, $1)
+		WHERE $1 = ANY(attribute_ids_scope)
+	`, attributeId)
+	return err
+}
+
+func checkValidation_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute, v types.AttributeValidation) error {
+
+	if !slices.Contains(validationContents, v.Content) {
+		return fmt.Errorf("invalid validation rule '%s'", v.Content)
+	}
+	if atr.Encrypted || schema.IsContentFiles(atr.Content) {
+		return fmt.Errorf("validation rules are not supported for encrypted or files attributes")
+	}
+
+	isNumber := schema.IsContentNumber(atr.Content)
+	isText := schema.IsContentText(atr.Content)
+
+	switch v.Content {
+	case "regex":
+		if !isText {
+			return fmt.Errorf("validation rule '%s' requires a text attribute", v.Content)
+		}
+
+		// patterns are evaluated by the database, use it to check syntax
+		if _, err := tx.Exec(ctx, `SELECT '' ~ $1`, v.Value.String); err != nil {
+			return fmt.Errorf("invalid regex pattern for validation rule, %s", err)
+		}
+	case "min", "max":
+		if !isText && !isNumber {
+			return fmt.Errorf("validation rule '%s' requires a text or number attribute", v.Content)
+		}
+		if isText {
+			if _, err := strconv.Atoi(v.Value.String); err != nil {
+				return fmt.Errorf("invalid text length '%s' for validation rule '%s'", v.Value.String, v.Content)
+			}
+		} else {
+			if _, err := strconv.ParseFloat(v.Value.String, 64); err != nil {
+				return fmt.Errorf("invalid number '%s' for validation rule '%s'", v.Value.String, v.Content)
+			}
+		}
+	case "values":
+		if !isText && !isNumber {
+			return fmt.Errorf("validation rule '%s' requires a text or number attribute", v.Content)
+		}
+		if len(v.Values) == 0 {
+			return fmt.Errorf("validation rule '%s' requires at least one value", v.Content)
+		}
+		if isNumber {
+			for _, value := range v.Values {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return fmt.Errorf("invalid number '%s' for validation rule '%s'", value, v.Content)
+				}
+			}
+		}
+	case "compare":
+		if !slices.Contains(validationOperators, v.Operator.String) {
+			return fmt.Errorf("invalid operator '%s' for validation rule '%s'", v.Operator.String, v.Content)
+		}
+		if !v.AttributeIdCompare.Valid || v.AttributeIdCompare.Bytes == atr.Id {
+			return fmt.Errorf("validation rule '%s' requires another attribute to compare with", v.Content)
+		}
+		if schema.IsContentRelationship(atr.Content) {
+			return fmt.Errorf("validation rule '%s' does not support relationship attributes", v.Content)
+		}
+
+		content, err := getValidationAttributeContent_tx(ctx, tx, atr, v.AttributeIdCompare.Bytes)
+		if err != nil {
+			return err
+		}
+		if content != atr.Content && !(isText && schema.IsContentText(content)) && !(isNumber && schema.IsContentNumber(content)) {
+			return fmt.Errorf("validation rule '%s' requires attributes with compatible types", v.Content)
+		}
+	case "unique":
+		for _, id := range v.AttributeIdsScope {
+			if id == atr.Id {
+				return fmt.Errorf("validation rule '%s' cannot use its own attribute as scope", v.Content)
+			}
+			if _, err := getValidationAttributeContent_tx(ctx, tx, atr, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// returns content of attribute referenced by a validation rule
+// referenced attributes must be on the same relation and hold comparable, unencrypted values
+func getValidationAttributeContent_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute, attributeId uuid.UUID) (string, error) {
+	var relationId uuid.UUID
+	var content string
+	var encrypted bool
+
+	if err := tx.QueryRow(ctx, `
+		SELECT relation_id, content, encrypted
+		FROM app.attribute
+		WHERE id = $1
+	`, attributeId).Scan(&relationId, &content, &encrypted); err != nil {
+		return "", err
+	}
+
+	if relationId != atr.RelationId {
+		return "", fmt.Errorf("validation rules can only refer to attributes of the same relation")
+	}
+	if encrypted || schema.IsContentFiles(content) {
+		return "", fmt.Errorf("validation rules cannot refer to encrypted or files attributes")
+	}
+	return content, nil
+}
@@ -22,6 +22,7 @@ func FixMissingZeroRelease(releases []types.Release) []types.Release {
 			BuildApp:    0,
 			DateCreated: 0,
 			Logs:        make([]types.ReleaseLog, 0),
+			Migrations:  make([]uuid.UUID, 0),
 		})
 	}
 	return releases
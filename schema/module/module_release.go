@@ -2,7 +2,9 @@ package module
 
 import (
 	"context"
+	"fmt"
 	"r3/types"
+	"slices"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
@@ -36,6 +38,10 @@ func getReleases_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) ([]types.Relea
 		if err != nil {
 			return nil, err
 		}
+		releases[i].Migrations, err = getReleaseMigrations_tx(ctx, tx, id, r.Build)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return releases, nil
 }
@@ -63,6 +69,18 @@ func getReleaseLogs_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID, build int64
 	}
 	return logs, nil
 }
+func getReleaseMigrations_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID, build int64) ([]uuid.UUID, error) {
+
+	pgFunctionIds := make([]uuid.UUID, 0)
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(ARRAY_AGG(pg_function_id ORDER BY position ASC), '{}')
+		FROM app.release_migration
+		WHERE module_id = $1
+		AND   build     = $2
+	`, id, build).Scan(&pgFunctionIds)
+
+	return pgFunctionIds, err
+}
 
 func setReleases_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, releases []types.Release) error {
 
@@ -82,6 +100,9 @@ func setReleases_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, releases
 		if err := setReleaseLogs_tx(ctx, tx, moduleId, r.Build, r.Logs); err != nil {
 			return err
 		}
+		if err := setReleaseMigrations_tx(ctx, tx, moduleId, r.Build, r.Migrations); err != nil {
+			return err
+		}
 	}
 
 	_, err := tx.Exec(ctx, `DELETE FROM app.release WHERE module_id = $1 AND build <> ALL($2)`, moduleId, buildsKeep)
@@ -110,3 +131,26 @@ func setReleaseLogs_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, build
 	`, moduleId, build, positionsKeep)
 	return err
 }
+func setReleaseMigrations_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, build int64, pgFunctionIds []uuid.UUID) error {
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.release_migration
+		WHERE module_id = $1
+		AND   build     = $2
+	`, moduleId, build); err != nil {
+		return err
+	}
+
+	for i, pgFunctionId := range pgFunctionIds {
+		if slices.Contains(pgFunctionIds[:i], pgFunctionId) {
+			return fmt.Errorf("migration function '%s' is assigned twice to release build %d", pgFunctionId, build)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.release_migration (module_id, build, position, pg_function_id)
+			VALUES ($1,$2,$3,$4)
+		`, moduleId, build, i, pgFunctionId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package numberRange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/schema"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var resets = []string{"never", "year", "month", "day"}
+
+func Del_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(ctx, `DELETE FROM app.number_range WHERE id = $1`, id)
+	return err
+}
+
+func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.NumberRange, error) {
+
+	numberRanges := make([]types.NumberRange, 0)
+	rows, err := tx.Query(ctx, `
+		SELECT id, attribute_id, attribute_id_scope, name, comment, prefix, suffix, digits, reset
+		FROM app.number_range
+		WHERE module_id = $1
+		ORDER BY name ASC
+	`, moduleId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n types.NumberRange
+		n.ModuleId = moduleId
+		if err := rows.Scan(&n.Id, &n.AttributeId, &n.AttributeIdScope, &n.Name, &n.Comment,
+			&n.Prefix, &n.Suffix, &n.Digits, &n.Reset); err != nil {
+
+			return nil, err
+		}
+		numberRanges = append(numberRanges, n)
+	}
+	return numberRanges, nil
+}
+
+func Set_tx(ctx context.Context, tx pgx.Tx, n types.NumberRange) error {
+
+	if n.Digits < 0 || n.Digits > 20 {
+		return errors.New("number range digits must be between 0 and 20")
+	}
+	if !slices.Contains(resets, n.Reset) {
+		return fmt.Errorf("invalid number range reset '%s'", n.Reset)
+	}
+	if n.AttributeIdScope.Valid && !n.AttributeId.Valid {
+		return errors.New("number range scope requires an attribute to assign numbers to")
+	}
+
+	if n.AttributeId.Valid {
+		// numbers are assigned when records are created, target attribute must be a text attribute of the same module
+		var content string
+		var moduleId, relationId uuid.UUID
+		if err := tx.QueryRow(ctx, `
+			SELECT a.content, a.relation_id, r.module_id
+			FROM app.attribute AS a
+			JOIN app.relation  AS r ON r.id = a.relation_id
+			WHERE a.id = $1
+		`, n.AttributeId.Bytes).Scan(&content, &relationId, &moduleId); err != nil {
+			return err
+		}
+		if moduleId != n.ModuleId || !schema.IsContentText(content) {
+			return errors.New("number range attribute must be a text attribute of the same application")
+		}
+
+		if n.AttributeIdScope.Valid {
+			var relationIdScope uuid.UUID
+			if err := tx.QueryRow(ctx, `
+				SELECT relation_id
+				FROM app.attribute
+				WHERE id = $1
+			`, n.AttributeIdScope.Bytes).Scan(&relationIdScope); err != nil {
+				return err
+			}
+			if relationIdScope != relationId || n.AttributeIdScope.Bytes == n.AttributeId.Bytes {
+				return errors.New("number range scope must be another attribute of the same relation")
+			}
+		}
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO app.number_range (id, module_id, attribute_id, attribute_id_scope,
+			name, comment, prefix, suffix, digits, reset)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		ON CONFLICT (id)
+		DO UPDATE SET attribute_id = $3, attribute_id_scope = $4, name = $5,
+			comment = $6, prefix = $7, suffix = $8, digits = $9, reset = $10
+	`, n.Id, n.ModuleId, n.AttributeId, n.AttributeIdScope, n.Name, n.Comment,
+		n.Prefix, n.Suffix, n.Digits, n.Reset)
+
+	return err
+}
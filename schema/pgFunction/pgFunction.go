@@ -8,10 +8,12 @@ import (
 	"r3/schema"
 	"r3/schema/caption"
 	"r3/schema/compatible"
+	"r3/tools/cron"
 	"r3/types"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
@@ -77,7 +79,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.PgFunct
 func getSchedules_tx(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID) ([]types.PgFunctionSchedule, error) {
 
 	rows, err := tx.Query(ctx, `
-		SELECT id, at_second, at_minute, at_hour, at_day, interval_type, interval_value
+		SELECT id, at_second, at_minute, at_hour, at_day, attribute_id_exclude,
+			cron_expr, interval_type, interval_value, time_zone
 		FROM app.pg_function_schedule
 		WHERE pg_function_id = $1
 		ORDER BY id ASC
@@ -90,7 +93,9 @@ func getSchedules_tx(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID) ([]
 	schedules := make([]types.PgFunctionSchedule, 0)
 	for rows.Next() {
 		var s types.PgFunctionSchedule
-		if err := rows.Scan(&s.Id, &s.AtSecond, &s.AtMinute, &s.AtHour, &s.AtDay, &s.IntervalType, &s.IntervalValue); err != nil {
+		if err := rows.Scan(&s.Id, &s.AtSecond, &s.AtMinute, &s.AtHour, &s.AtDay, &s.AttributeIdExclude,
+			&s.CronExpr, &s.IntervalType, &s.IntervalValue, &s.TimeZone); err != nil {
+
 			return nil, err
 		}
 		schedules = append(schedules, s)
@@ -204,16 +209,23 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 		// overwrite invalid inputs
 		s.AtDay = schema.GetValidAtDay(s.IntervalType, s.AtDay)
 
+		if err := checkSchedule_tx(ctx, tx, s); err != nil {
+			return err
+		}
+
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO app.pg_function_schedule (
 				id, pg_function_id, at_second, at_minute, at_hour, at_day,
-				interval_type, interval_value
+				attribute_id_exclude, cron_expr, interval_type, interval_value,
+				time_zone
 			)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
 			ON CONFLICT (id)
 			DO UPDATE SET at_second = $3, at_minute = $4, at_hour = $5, at_day = $6,
-				interval_type = $7, interval_value = $8
-		`, s.Id, fnc.Id, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay, s.IntervalType, s.IntervalValue); err != nil {
+				attribute_id_exclude = $7, cron_expr = $8, interval_type = $9,
+				interval_value = $10, time_zone = $11
+		`, s.Id, fnc.Id, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay, s.AttributeIdExclude,
+			s.CronExpr, s.IntervalType, s.IntervalValue, s.TimeZone); err != nil {
 			return err
 		}
 
@@ -253,6 +265,33 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 	return err
 }
 
+func checkSchedule_tx(ctx context.Context, tx pgx.Tx, s types.PgFunctionSchedule) error {
+	if s.IntervalType == "cron" {
+		if _, err := cron.Parse(s.CronExpr.String); err != nil {
+			return fmt.Errorf("invalid cron expression '%s', %s", s.CronExpr.String, err)
+		}
+	}
+	if s.TimeZone.Valid {
+		if _, err := time.LoadLocation(s.TimeZone.String); err != nil {
+			return fmt.Errorf("invalid time zone '%s', %s", s.TimeZone.String, err)
+		}
+	}
+	if s.AttributeIdExclude.Valid {
+		var isDate bool
+		if err := tx.QueryRow(ctx, `
+			SELECT content_use = 'date'
+			FROM app.attribute
+			WHERE id = $1
+		`, s.AttributeIdExclude).Scan(&isDate); err != nil {
+			return err
+		}
+		if !isDate {
+			return errors.New("schedule exclusion attribute must be a date attribute")
+		}
+	}
+	return nil
+}
+
 // recreate all PG functions, affected by a changed entity for which a dependency exists
 // relevant entities: modules, relations, attributes, pg functions
 func RecreateAffectedBy_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uuid.UUID) error {
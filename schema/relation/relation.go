@@ -72,7 +72,8 @@ func delPkSeq_tx(ctx context.Context, tx pgx.Tx, modName string, id uuid.UUID) e
 func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Relation, error) {
 
 	rows, err := tx.Query(ctx, `
-		SELECT r.id, r.name, r.comment, r.encryption, r.retention_count, r.retention_days, (
+		SELECT r.id, r.name, r.comment, r.encryption, r.retention_count, r.retention_days,
+			r.recycle_days, r.form_id_search, (
 			SELECT id
 			FROM app.attribute
 			WHERE relation_id = r.id
@@ -81,6 +82,10 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Relatio
 			SELECT ARRAY_AGG(attribute_id ORDER BY position ASC)
 			FROM app.relation_record_title
 			WHERE relation_id = r.id
+		),(
+			SELECT ARRAY_AGG(attribute_id)
+			FROM app.relation_search_attribute
+			WHERE relation_id = r.id
 		)
 		FROM app.relation AS r
 		WHERE r.module_id = $2
@@ -95,13 +100,17 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Relatio
 	for rows.Next() {
 		var r types.Relation
 		if err := rows.Scan(&r.Id, &r.Name, &r.Comment, &r.Encryption, &r.RetentionCount,
-			&r.RetentionDays, &r.AttributeIdPk, &r.AttributeIdsTitle); err != nil {
+			&r.RetentionDays, &r.RecycleDays, &r.FormIdSearch, &r.AttributeIdPk,
+			&r.AttributeIdsTitle, &r.AttributeIdsSearch); err != nil {
 
 			return nil, err
 		}
 		if r.AttributeIdsTitle == nil {
 			r.AttributeIdsTitle = make([]uuid.UUID, 0)
 		}
+		if r.AttributeIdsSearch == nil {
+			r.AttributeIdsSearch = make([]uuid.UUID, 0)
+		}
 		r.ModuleId = moduleId
 		r.Attributes = make([]types.Attribute, 0)
 		r.Triggers = make([]types.PgTrigger, 0)
@@ -147,9 +156,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, fromLocal bool)
 		// update relation reference
 		if _, err := tx.Exec(ctx, `
 			UPDATE app.relation
-			SET name = $1, comment = $2, retention_count = $3, retention_days = $4
-			WHERE id = $5
-		`, rel.Name, rel.Comment, rel.RetentionCount, rel.RetentionDays, rel.Id); err != nil {
+			SET name = $1, comment = $2, retention_count = $3, retention_days = $4,
+				recycle_days = $5, form_id_search = $6
+			WHERE id = $7
+		`, rel.Name, rel.Comment, rel.RetentionCount, rel.RetentionDays, rel.RecycleDays,
+			rel.FormIdSearch, rel.Id); err != nil {
 			return err
 		}
 
@@ -170,10 +181,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, fromLocal bool)
 
 		// insert relation reference
 		if _, err := tx.Exec(ctx, `
-			INSERT INTO app.relation (id, module_id, name, comment,
-				encryption, retention_count, retention_days)
-			VALUES ($1,$2,$3,$4,$5,$6,$7)
-		`, rel.Id, rel.ModuleId, rel.Name, rel.Comment, rel.Encryption, rel.RetentionCount, rel.RetentionDays); err != nil {
+			INSERT INTO app.relation (id, module_id, name, comment, encryption,
+				retention_count, retention_days, recycle_days, form_id_search)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		`, rel.Id, rel.ModuleId, rel.Name, rel.Comment, rel.Encryption, rel.RetentionCount,
+			rel.RetentionDays, rel.RecycleDays, rel.FormIdSearch); err != nil {
 			return err
 		}
 
@@ -224,6 +236,24 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, fromLocal bool)
 		return err
 	}
 
+	// update global search attributes
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.relation_search_attribute
+		WHERE relation_id = $1
+		AND attribute_id <> ALL($2)
+	`, rel.Id, rel.AttributeIdsSearch); err != nil {
+		return err
+	}
+	for _, id := range rel.AttributeIdsSearch {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.relation_search_attribute (relation_id, attribute_id)
+			VALUES ($1,$2)
+			ON CONFLICT DO NOTHING
+		`, rel.Id, id); err != nil {
+			return err
+		}
+	}
+
 	if err := caption.Set_tx(ctx, tx, rel.Id, rel.Captions); err != nil {
 		return err
 	}
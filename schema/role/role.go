@@ -51,7 +51,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Role, e
 	}
 	rows.Close()
 
-	// get access & captions
+	// get access, masks & captions
 	for i, r := range roles {
 
 		r, err = getAccess_tx(ctx, tx, r)
@@ -59,6 +59,11 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Role, e
 			return nil, err
 		}
 
+		r.AttributeMasks, err = getMasks_tx(ctx, tx, r.Id)
+		if err != nil {
+			return nil, err
+		}
+
 		r.Captions, err = caption.Get_tx(ctx, tx, schema.DbRole, r.Id, []string{"roleTitle", "roleDesc"})
 		if err != nil {
 			return nil, err
@@ -210,6 +215,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, role types.Role) error {
 			return err
 		}
 	}
+
+	// set attribute masks
+	if err := setMasks_tx(ctx, tx, role.Id, role.AttributeMasks); err != nil {
+		return err
+	}
 	return caption.Set_tx(ctx, tx, role.Id, role.Captions)
 }
 
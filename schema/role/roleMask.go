@@ -0,0 +1,88 @@
+package role
+
+import (
+	"context"
+	"fmt"
+	"r3/schema"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var maskContentsText = []string{"last4", "hash", "placeholder"}
+var maskContentsDate = []string{"year"}
+
+func getMasks_tx(ctx context.Context, tx pgx.Tx, roleId uuid.UUID) (map[uuid.UUID]string, error) {
+	masks := make(map[uuid.UUID]string)
+
+	rows, err := tx.Query(ctx, `
+		SELECT attribute_id, content
+		FROM app.role_attribute_mask
+		WHERE role_id = $1
+	`, roleId)
+	if err != nil {
+		return masks, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attributeId uuid.UUID
+		var content string
+		if err := rows.Scan(&attributeId, &content); err != nil {
+			return masks, err
+		}
+		masks[attributeId] = content
+	}
+	return masks, nil
+}
+
+func setMasks_tx(ctx context.Context, tx pgx.Tx, roleId uuid.UUID, masks map[uuid.UUID]string) error {
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.role_attribute_mask
+		WHERE role_id = $1
+	`, roleId); err != nil {
+		return err
+	}
+
+	for attributeId, content := range masks {
+		if err := checkMask_tx(ctx, tx, attributeId, content); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.role_attribute_mask (role_id, attribute_id, content)
+			VALUES ($1,$2,$3)
+		`, roleId, attributeId, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// masks are applied to readable values, they must fit the attribute type
+// encrypted values are only readable by clients, they cannot be masked by the server
+func checkMask_tx(ctx context.Context, tx pgx.Tx, attributeId uuid.UUID, content string) error {
+	var atrContent, atrContentUse string
+	var encrypted bool
+
+	if err := tx.QueryRow(ctx, `
+		SELECT content, content_use, encrypted
+		FROM app.attribute
+		WHERE id = $1
+	`, attributeId).Scan(&atrContent, &atrContentUse, &encrypted); err != nil {
+		return err
+	}
+
+	if encrypted {
+		return fmt.Errorf("encrypted attributes cannot be masked")
+	}
+	if schema.IsContentText(atrContent) && slices.Contains(maskContentsText, content) {
+		return nil
+	}
+	if (atrContentUse == "date" || atrContentUse == "datetime") && slices.Contains(maskContentsDate, content) {
+		return nil
+	}
+	return fmt.Errorf("invalid attribute mask '%s' for attribute type '%s'", content, atrContent)
+}
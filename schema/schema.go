@@ -49,6 +49,7 @@ const (
 	DbMenu                  DbEntity = "menu"
 	DbMenuTab               DbEntity = "menu_tab"
 	DbModule                DbEntity = "module"
+	DbNumberRange           DbEntity = "number_range"
 	DbPgFunction            DbEntity = "pg_function"
 	DbPgFunctionSchedule    DbEntity = "pg_function_schedule"
 	DbPgIndex               DbEntity = "pg_index"
@@ -188,6 +189,10 @@ func CheckId_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID, entity DbEntity, p
 func IsContentFiles(content string) bool {
 	return content == "files"
 }
+func IsContentNumber(content string) bool {
+	return content == "integer" || content == "bigint" || content == "numeric" ||
+		content == "real" || content == "double precision"
+}
 func IsContentNumeric(content string) bool {
 	return content == "numeric"
 }
@@ -479,6 +484,31 @@ func ValidateDependency_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) e
 			name1.String)
 	}
 
+	// check PG function schedule access to external exclusion attributes
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*), STRING_AGG(f.name, ', ')
+		FROM app.pg_function_schedule AS s
+		INNER JOIN app.pg_function AS f ON f.id = s.pg_function_id
+		INNER JOIN app.attribute   AS a ON a.id = s.attribute_id_exclude
+		INNER JOIN app.relation    AS r ON r.id = a.relation_id
+		INNER JOIN app.module      AS m ON m.id = f.module_id AND m.id = $1
+		
+		-- dependency
+		WHERE r.module_id <> m.id
+		AND   r.module_id NOT IN (
+			SELECT module_id_on
+			FROM app.module_depends
+			WHERE module_id = m.id
+		)
+	`, moduleId).Scan(&cnt, &name1); err != nil {
+		return err
+	}
+
+	if cnt != 0 {
+		return fmt.Errorf("dependency check failed, schedules of backend function(s) '%s' excluding days from independent module(s)",
+			name1.String)
+	}
+
 	// check widget access to external forms
 	if err := tx.QueryRow(ctx, `
 		SELECT COUNT(*), STRING_AGG(f.name, ', ')
@@ -0,0 +1,144 @@
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"r3/login"
+	"r3/login/login_external"
+	"r3/login/login_roleAssign"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func Del_tx(ctx context.Context, tx pgx.Tx, id int32) error {
+
+	if err := login.DelByExternalProvider_tx(ctx, tx, login_external.EntityScimClient, id); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.scim_client
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func Get_tx(ctx context.Context, tx pgx.Tx) ([]types.ScimClient, error) {
+	clients := make([]types.ScimClient, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, login_template_id, name
+		FROM instance.scim_client
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return clients, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c types.ScimClient
+		if err := rows.Scan(&c.Id, &c.LoginTemplateId, &c.Name); err != nil {
+			return clients, err
+		}
+		clients = append(clients, c)
+	}
+	rows.Close()
+
+	for i, c := range clients {
+		clients[i].LoginRolesAssign, err = login_roleAssign.Get_tx(ctx, tx, login_external.EntityScimClient, c.Id)
+		if err != nil {
+			return clients, err
+		}
+	}
+	return clients, nil
+}
+
+// returns SCIM client that is authenticated by the given bearer token
+// only token hashes are stored, the token is compared via its hash
+func GetByToken_tx(ctx context.Context, tx pgx.Tx, token string) (types.ScimClient, error) {
+
+	if token == "" {
+		return types.ScimClient{}, errors.New("no SCIM client found for token")
+	}
+
+	var id int32
+	if err := tx.QueryRow(ctx, `
+		SELECT id
+		FROM instance.scim_client
+		WHERE token_hash = $1
+	`, getTokenHash(token)).Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return types.ScimClient{}, errors.New("no SCIM client found for token")
+		}
+		return types.ScimClient{}, err
+	}
+
+	clients, err := Get_tx(ctx, tx)
+	if err != nil {
+		return types.ScimClient{}, err
+	}
+	for _, c := range clients {
+		if c.Id == id {
+			return c, nil
+		}
+	}
+	return types.ScimClient{}, errors.New("no SCIM client found for token")
+}
+
+// stores SCIM client, new clients always receive a bearer token
+// returns newly generated token, which is only shown once as only its hash is stored
+func Set_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, tokenRenew bool) (string, error) {
+
+	var token string
+	if c.Id == 0 || tokenRenew {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		token = hex.EncodeToString(b)
+	}
+
+	if c.Id == 0 {
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO instance.scim_client (login_template_id, name, token_hash)
+			VALUES ($1,$2,$3)
+			RETURNING id
+		`, c.LoginTemplateId, c.Name, getTokenHash(token)).Scan(&c.Id); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.scim_client
+			SET login_template_id = $1, name = $2
+			WHERE id = $3
+		`, c.LoginTemplateId, c.Name, c.Id); err != nil {
+			return "", err
+		}
+
+		if tokenRenew {
+			if _, err := tx.Exec(ctx, `
+				UPDATE instance.scim_client
+				SET token_hash = $1
+				WHERE id = $2
+			`, getTokenHash(token), c.Id); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err := login_roleAssign.Set_tx(ctx, tx, login_external.EntityScimClient, c.Id, c.LoginRolesAssign); err != nil {
+		return "", err
+	}
+
+	// role assignments might have changed, update all provisioned logins
+	return token, updateLoginRolesAll_tx(ctx, tx, c)
+}
+
+func getTokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
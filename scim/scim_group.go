@@ -0,0 +1,281 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"r3/tools"
+	"r3/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func GroupDel_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string) error {
+	g, err := GroupGet_tx(ctx, tx, c, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.scim_group
+		WHERE id = $1
+	`, g.Id); err != nil {
+		return err
+	}
+
+	// former members might lose roles
+	for _, m := range g.Members {
+		loginId, err := parseLoginId(m.Value)
+		if err != nil {
+			return err
+		}
+		if err := updateLoginRoles_tx(ctx, tx, c, loginId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GroupGet_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string) (types.ScimGroup, error) {
+	g := types.ScimGroup{
+		Schemas: []string{SchemaGroup},
+		Members: make([]types.ScimMultiValue, 0),
+		Meta:    types.ScimMeta{ResourceType: "Group"},
+	}
+
+	groupId, err := uuid.FromString(id)
+	if err != nil {
+		return g, errNotFound("Group", id)
+	}
+
+	var externalId pgtype.Text
+	if err := tx.QueryRow(ctx, `
+		SELECT name, external_id
+		FROM instance.scim_group
+		WHERE id             = $1
+		AND   scim_client_id = $2
+	`, groupId, c.Id).Scan(&g.DisplayName, &externalId); err != nil {
+		if err == pgx.ErrNoRows {
+			return g, errNotFound("Group", id)
+		}
+		return g, err
+	}
+	g.Id = groupId.String()
+	g.ExternalId = externalId.String
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.id, l.name
+		FROM instance.scim_group_login AS gl
+		JOIN instance.login            AS l ON l.id = gl.login_id
+		WHERE gl.scim_group_id = $1
+		ORDER BY l.name ASC
+	`, groupId)
+	if err != nil {
+		return g, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loginId int64
+		var name string
+		if err := rows.Scan(&loginId, &name); err != nil {
+			return g, err
+		}
+		g.Members = append(g.Members, types.ScimMultiValue{
+			Value:   strconv.FormatInt(loginId, 10),
+			Display: name,
+		})
+	}
+	return g, nil
+}
+
+func GroupList_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, filter string, startIndex int, count int) (types.ScimListResponse, error) {
+
+	res := types.ScimListResponse{Schemas: []string{SchemaListResponse}}
+	groups := make([]types.ScimGroup, 0)
+
+	attribute, value, err := parseFilter(filter, []string{"id", "externalId", "displayName"})
+	if err != nil {
+		return res, err
+	}
+
+	var qb tools.QueryBuilder
+	qb.UseDollarSigns()
+	qb.AddList("SELECT", []string{"id"})
+	qb.SetFrom("instance.scim_group")
+	qb.Add("WHERE", "scim_client_id = {CLIENT_ID}")
+	qb.AddPara("{CLIENT_ID}", c.Id)
+
+	switch attribute {
+	case "id":
+		qb.Add("WHERE", "id::TEXT = {VALUE}")
+		qb.AddPara("{VALUE}", strings.ToLower(value))
+	case "externalid":
+		qb.Add("WHERE", "external_id = {VALUE}")
+		qb.AddPara("{VALUE}", value)
+	case "displayname":
+		qb.Add("WHERE", "name = {VALUE}")
+		qb.AddPara("{VALUE}", value)
+	}
+
+	queryCnt, err := qb.GetQuery()
+	if err != nil {
+		return res, err
+	}
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM (%s) AS c`, queryCnt),
+		qb.GetParaValues()...).Scan(&res.TotalResults); err != nil {
+
+		return res, err
+	}
+
+	var offset int
+	res.StartIndex, count, offset = parsePaging(startIndex, count)
+	qb.Add("ORDER", "name ASC")
+	qb.SetLimit(count)
+	qb.SetOffset(offset)
+
+	query, err := qb.GetQuery()
+	if err != nil {
+		return res, err
+	}
+	rows, err := tx.Query(ctx, query, qb.GetParaValues()...)
+	if err != nil {
+		return res, err
+	}
+	groupIds := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return res, err
+		}
+		groupIds = append(groupIds, id)
+	}
+	rows.Close()
+
+	for _, id := range groupIds {
+		g, err := GroupGet_tx(ctx, tx, c, id.String())
+		if err != nil {
+			return res, err
+		}
+		groups = append(groups, g)
+	}
+	res.ItemsPerPage = len(groups)
+	res.Resources = groups
+	return res, nil
+}
+
+func GroupPatch_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string, patch types.ScimPatch) (types.ScimGroup, error) {
+	g, err := GroupGet_tx(ctx, tx, c, id)
+	if err != nil {
+		return g, err
+	}
+	if err := applyPatch(&g, patch, SchemaGroup); err != nil {
+		return g, err
+	}
+	return GroupSet_tx(ctx, tx, c, id, g)
+}
+
+// creates (empty ID) or replaces SCIM group including its members
+// roles of added or removed members are updated based on the role assignments of the SCIM client
+func GroupSet_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string, g types.ScimGroup) (types.ScimGroup, error) {
+
+	g.DisplayName = strings.TrimSpace(g.DisplayName)
+	if g.DisplayName == "" {
+		return g, errBadRequest("invalidValue", "displayName must not be empty")
+	}
+	externalId := pgtype.Text{String: g.ExternalId, Valid: g.ExternalId != ""}
+
+	// groups names are unique per SCIM client
+	var groupId uuid.UUID
+	var isNotUnique bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT id
+			FROM instance.scim_group
+			WHERE scim_client_id = $1
+			AND   name           = $2
+			AND   id::TEXT      <> $3
+		)
+	`, c.Id, g.DisplayName, strings.ToLower(id)).Scan(&isNotUnique); err != nil {
+		return g, err
+	}
+	if isNotUnique {
+		return g, errConflict(fmt.Sprintf("displayName '%s' is already taken", g.DisplayName))
+	}
+
+	loginIdsOld := make([]int64, 0)
+	if id == "" {
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO instance.scim_group (scim_client_id, name, external_id)
+			VALUES ($1,$2,$3)
+			RETURNING id
+		`, c.Id, g.DisplayName, externalId).Scan(&groupId); err != nil {
+			return g, err
+		}
+	} else {
+		gEx, err := GroupGet_tx(ctx, tx, c, id)
+		if err != nil {
+			return g, err
+		}
+		groupId = uuid.FromStringOrNil(gEx.Id)
+
+		for _, m := range gEx.Members {
+			loginId, err := parseLoginId(m.Value)
+			if err != nil {
+				return g, err
+			}
+			loginIdsOld = append(loginIdsOld, loginId)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.scim_group
+			SET name = $1, external_id = $2
+			WHERE id = $3
+		`, g.DisplayName, externalId, groupId); err != nil {
+			return g, err
+		}
+	}
+
+	// members must be logins provisioned by the same SCIM client
+	loginIdsNew := make([]int64, 0)
+	for _, m := range g.Members {
+		l, err := getLogin_tx(ctx, tx, c, m.Value)
+		if err != nil {
+			return g, errBadRequest("invalidValue", fmt.Sprintf("member '%s' is not a known user", m.Value))
+		}
+		if !slices.Contains(loginIdsNew, l.id) {
+			loginIdsNew = append(loginIdsNew, l.id)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.scim_group_login
+		WHERE scim_group_id = $1
+	`, groupId); err != nil {
+		return g, err
+	}
+	for _, loginId := range loginIdsNew {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.scim_group_login (scim_group_id, login_id)
+			VALUES ($1,$2)
+		`, groupId, loginId); err != nil {
+			return g, err
+		}
+	}
+
+	// group name might have changed, update roles of old and new members
+	for _, loginId := range loginIdsOld {
+		if !slices.Contains(loginIdsNew, loginId) {
+			loginIdsNew = append(loginIdsNew, loginId)
+		}
+	}
+	for _, loginId := range loginIdsNew {
+		if err := updateLoginRoles_tx(ctx, tx, c, loginId); err != nil {
+			return g, err
+		}
+	}
+	return GroupGet_tx(ctx, tx, c, groupId.String())
+}
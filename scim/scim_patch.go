@@ -0,0 +1,248 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"r3/types"
+	"regexp"
+	"strings"
+)
+
+var (
+	// attribute path, such as: 'active', 'name.givenName', 'emails[type eq "work"].value', 'members[value eq "12"]'
+	rxPatchPath = regexp.MustCompile(`^([A-Za-z0-9_$-]+)(?:\[\s*([A-Za-z0-9_$-]+)\s+eq\s+"([^"]*)"\s*\])?(?:\.([A-Za-z0-9_$-]+))?$`)
+)
+
+// applies SCIM patch operations to a resource (user or group)
+// resource is converted to a generic JSON object, patched and converted back
+func applyPatch(resource any, patch types.ScimPatch, coreSchema string) error {
+
+	resJson, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	obj := make(map[string]any)
+	if err := json.Unmarshal(resJson, &obj); err != nil {
+		return err
+	}
+
+	for _, op := range patch.Operations {
+		var value any
+		if len(op.Value) != 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return errBadRequest("invalidValue", err.Error())
+			}
+		}
+
+		opName := strings.ToLower(op.Op)
+		switch opName {
+		case "add", "replace":
+			if op.Path == "" {
+				// no path, value contains attributes to set
+				values, ok := value.(map[string]any)
+				if !ok {
+					return errBadRequest("invalidValue", "value must be an object if no path is given")
+				}
+				for path, v := range values {
+					if err := applyPatchPath(obj, opName, path, v, coreSchema); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		case "remove":
+			if op.Path == "" {
+				return errBadRequest("noTarget", "path is required for remove operation")
+			}
+		default:
+			return errBadRequest("invalidSyntax", fmt.Sprintf("unknown patch operation '%s'", op.Op))
+		}
+
+		if err := applyPatchPath(obj, opName, op.Path, value, coreSchema); err != nil {
+			return err
+		}
+	}
+
+	// some identity providers send boolean values as strings
+	if v, ok := obj["active"].(string); ok {
+		obj["active"] = strings.ToLower(v) == "true"
+	}
+
+	resJson, err = json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resJson, resource); err != nil {
+		return errBadRequest("invalidValue", err.Error())
+	}
+	return nil
+}
+
+func applyPatchPath(obj map[string]any, op string, path string, value any, coreSchema string) error {
+
+	// resolve schema prefix: core schema attributes are on root level, extension attributes are nested
+	if strings.HasPrefix(path, "urn:") {
+		prefixExt := SchemaEnterpriseUser + ":"
+
+		switch {
+		case strings.HasPrefix(path, coreSchema+":"):
+			path = strings.TrimPrefix(path, coreSchema+":")
+
+		case strings.EqualFold(path, SchemaEnterpriseUser):
+			return applyPatchValue(obj, op, SchemaEnterpriseUser, value)
+
+		case len(path) > len(prefixExt) && strings.EqualFold(path[:len(prefixExt)], prefixExt):
+			ext, ok := obj[SchemaEnterpriseUser].(map[string]any)
+			if !ok {
+				ext = make(map[string]any)
+			}
+			if err := applyPatchPath(ext, op, path[len(prefixExt):], value, coreSchema); err != nil {
+				return err
+			}
+			obj[SchemaEnterpriseUser] = ext
+			return nil
+
+		default:
+			return errBadRequest("invalidPath", fmt.Sprintf("schema of path '%s' is not supported", path))
+		}
+	}
+
+	m := rxPatchPath.FindStringSubmatch(path)
+	if len(m) != 5 {
+		return errBadRequest("invalidPath", fmt.Sprintf("path '%s' is not supported", path))
+	}
+	attribute, filterAttribute, filterValue, subAttribute := m[1], m[2], m[3], m[4]
+
+	key, exists := getKeyCaseInsensitive(obj, attribute)
+	if !exists {
+		key = attribute
+	}
+
+	// simple attribute, such as 'active'
+	if filterAttribute == "" && subAttribute == "" {
+		return applyPatchValue(obj, op, key, value)
+	}
+
+	// sub attribute of complex attribute, such as 'name.givenName'
+	if filterAttribute == "" {
+		sub, ok := obj[key].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+		}
+		if err := applyPatchPath(sub, op, subAttribute, value, coreSchema); err != nil {
+			return err
+		}
+		obj[key] = sub
+		return nil
+	}
+
+	// multi-valued attribute with filter, such as 'emails[type eq "work"].value'
+	items, _ := obj[key].([]any)
+	itemsNew := make([]any, 0)
+	matched := false
+	for _, itemIf := range items {
+		item, ok := itemIf.(map[string]any)
+		if !ok {
+			itemsNew = append(itemsNew, itemIf)
+			continue
+		}
+		filterKey, _ := getKeyCaseInsensitive(item, filterAttribute)
+		if fmt.Sprintf("%v", item[filterKey]) != filterValue {
+			itemsNew = append(itemsNew, item)
+			continue
+		}
+		matched = true
+
+		if subAttribute == "" {
+			switch op {
+			case "add", "replace":
+				if v, ok := value.(map[string]any); ok {
+					itemsNew = append(itemsNew, v)
+				}
+			}
+			// removed if not replaced
+			continue
+		}
+		subKey, exists := getKeyCaseInsensitive(item, subAttribute)
+		if !exists {
+			subKey = subAttribute
+		}
+		if err := applyPatchValue(item, op, subKey, value); err != nil {
+			return err
+		}
+		itemsNew = append(itemsNew, item)
+	}
+
+	// add new item if filter did not match, such as setting a work email if none exists yet
+	if !matched && op != "remove" {
+		item := map[string]any{filterAttribute: filterValue}
+		if subAttribute != "" {
+			item[subAttribute] = value
+		} else if v, ok := value.(map[string]any); ok {
+			for k, vSub := range v {
+				item[k] = vSub
+			}
+		}
+		itemsNew = append(itemsNew, item)
+	}
+	obj[key] = itemsNew
+	return nil
+}
+
+func applyPatchValue(obj map[string]any, op string, key string, value any) error {
+	switch op {
+	case "add":
+		// values are added to multi-valued attributes, otherwise replaced
+		existing, isArrayEx := obj[key].([]any)
+		if isArrayEx {
+			if values, ok := value.([]any); ok {
+				obj[key] = append(existing, values...)
+				return nil
+			}
+			obj[key] = append(existing, value)
+			return nil
+		}
+		obj[key] = value
+	case "replace":
+		obj[key] = value
+	case "remove":
+		// if values are given, only matching values are removed from multi-valued attributes
+		existing, isArrayEx := obj[key].([]any)
+		values, isArray := value.([]any)
+		if !isArrayEx || !isArray {
+			delete(obj, key)
+			return nil
+		}
+		existingNew := make([]any, 0)
+		for _, e := range existing {
+			eMap, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			keep := true
+			for _, v := range values {
+				if vMap, ok := v.(map[string]any); ok && fmt.Sprintf("%v", vMap["value"]) == fmt.Sprintf("%v", eMap["value"]) {
+					keep = false
+				}
+			}
+			if keep {
+				existingNew = append(existingNew, e)
+			}
+		}
+		obj[key] = existingNew
+	}
+	return nil
+}
+
+// SCIM attribute names are case insensitive
+func getKeyCaseInsensitive(obj map[string]any, name string) (string, bool) {
+	if _, exists := obj[name]; exists {
+		return name, true
+	}
+	for k := range obj {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,92 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	SchemaEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+
+	listCountDefault = 100
+	listCountMax     = 1000
+)
+
+var (
+	// only simple equality filters are supported, such as: userName eq "john.doe"
+	rxFilter = regexp.MustCompile(`(?i)^\s*([a-z.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+)
+
+// error with HTTP status and SCIM error type, to be returned to the SCIM client
+type Error struct {
+	Status int
+	Type   string // SCIM error type, example: 'uniqueness', 'invalidFilter'
+	Detail string
+}
+
+func (e Error) Error() string {
+	return e.Detail
+}
+
+func errBadRequest(scimType string, detail string) error {
+	return Error{Status: http.StatusBadRequest, Type: scimType, Detail: detail}
+}
+func errConflict(detail string) error {
+	return Error{Status: http.StatusConflict, Type: "uniqueness", Detail: detail}
+}
+func errNotFound(resource string, id string) error {
+	return Error{Status: http.StatusNotFound, Detail: fmt.Sprintf("%s '%s' not found", resource, id)}
+}
+
+// parses equality filter, returns lower case attribute name and value to compare against
+func parseFilter(filter string, attributesValid []string) (string, string, error) {
+	if filter == "" {
+		return "", "", nil
+	}
+	m := rxFilter.FindStringSubmatch(filter)
+	if len(m) != 3 {
+		return "", "", errBadRequest("invalidFilter", fmt.Sprintf("filter '%s' is not supported, only 'ATTRIBUTE eq \"VALUE\"'", filter))
+	}
+	attribute := strings.ToLower(m[1])
+	for _, a := range attributesValid {
+		if strings.ToLower(a) == attribute {
+			value, err := strconv.Unquote(fmt.Sprintf(`"%s"`, m[2]))
+			if err != nil {
+				return "", "", errBadRequest("invalidFilter", err.Error())
+			}
+			return attribute, value, nil
+		}
+	}
+	return "", "", errBadRequest("invalidFilter", fmt.Sprintf("filter attribute '%s' is not supported", m[1]))
+}
+
+// applies SCIM paging defaults and limits, returns SQL limit and offset
+func parsePaging(startIndex int, count int) (int, int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count == 0 || count > listCountMax {
+		count = listCountDefault
+	}
+	return startIndex, count, startIndex - 1
+}
+
+// resource IDs for users are login IDs, invalid IDs are treated as not found
+func parseLoginId(id string) (int64, error) {
+	loginId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || loginId <= 0 {
+		return 0, errNotFound("User", id)
+	}
+	return loginId, nil
+}
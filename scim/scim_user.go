@@ -0,0 +1,415 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"r3/login"
+	"r3/login/login_clusterEvent"
+	"r3/login/login_meta"
+	"r3/login/login_role"
+	"r3/tools"
+	"r3/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// existing login, as provisioned by SCIM client
+type loginType struct {
+	id               int64
+	name             string
+	admin            bool
+	active           bool
+	externalId       pgtype.Text
+	tokenExpiryHours pgtype.Int4
+	meta             types.LoginMeta
+	roleIds          []uuid.UUID
+}
+
+func UserDel_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string) error {
+	l, err := getLogin_tx(ctx, tx, c, id)
+	if err != nil {
+		return err
+	}
+	login_clusterEvent.Kick_tx(ctx, tx, l.id, l.name)
+	return login.Del_tx(ctx, tx, l.id)
+}
+
+func UserGet_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string) (types.ScimUser, error) {
+	l, err := getLogin_tx(ctx, tx, c, id)
+	if err != nil {
+		return types.ScimUser{}, err
+	}
+	return getUserFromLogin_tx(ctx, tx, l)
+}
+
+func UserList_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, filter string, startIndex int, count int) (types.ScimListResponse, error) {
+
+	res := types.ScimListResponse{Schemas: []string{SchemaListResponse}}
+	users := make([]types.ScimUser, 0)
+
+	attribute, value, err := parseFilter(filter, []string{"id", "externalId", "userName"})
+	if err != nil {
+		return res, err
+	}
+
+	var qb tools.QueryBuilder
+	qb.UseDollarSigns()
+	qb.AddList("SELECT", []string{"id"})
+	qb.SetFrom("instance.login")
+	qb.Add("WHERE", "scim_client_id = {CLIENT_ID}")
+	qb.AddPara("{CLIENT_ID}", c.Id)
+
+	switch attribute {
+	case "id":
+		loginId, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			loginId = 0
+		}
+		qb.Add("WHERE", "id = {VALUE}")
+		qb.AddPara("{VALUE}", loginId)
+	case "externalid":
+		qb.Add("WHERE", "scim_external_id = {VALUE}")
+		qb.AddPara("{VALUE}", value)
+	case "username":
+		qb.Add("WHERE", "name = {VALUE}")
+		qb.AddPara("{VALUE}", strings.ToLower(value)) // usernames are case insensitive
+	}
+
+	// total count before paging
+	queryCnt, err := qb.GetQuery()
+	if err != nil {
+		return res, err
+	}
+	if err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM (%s) AS c`, queryCnt),
+		qb.GetParaValues()...).Scan(&res.TotalResults); err != nil {
+
+		return res, err
+	}
+
+	var offset int
+	res.StartIndex, count, offset = parsePaging(startIndex, count)
+	qb.Add("ORDER", "id ASC")
+	qb.SetLimit(count)
+	qb.SetOffset(offset)
+
+	query, err := qb.GetQuery()
+	if err != nil {
+		return res, err
+	}
+	rows, err := tx.Query(ctx, query, qb.GetParaValues()...)
+	if err != nil {
+		return res, err
+	}
+	loginIds := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return res, err
+		}
+		loginIds = append(loginIds, id)
+	}
+	rows.Close()
+
+	for _, id := range loginIds {
+		u, err := UserGet_tx(ctx, tx, c, strconv.FormatInt(id, 10))
+		if err != nil {
+			return res, err
+		}
+		users = append(users, u)
+	}
+	res.ItemsPerPage = len(users)
+	res.Resources = users
+	return res, nil
+}
+
+func UserPatch_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string, patch types.ScimPatch) (types.ScimUser, error) {
+	u, err := UserGet_tx(ctx, tx, c, id)
+	if err != nil {
+		return u, err
+	}
+	if err := applyPatch(&u, patch, SchemaUser); err != nil {
+		return u, err
+	}
+	return UserSet_tx(ctx, tx, c, id, u)
+}
+
+// creates (empty ID) or replaces SCIM user
+// group memberships are ignored, as they are managed via group resources
+func UserSet_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string, u types.ScimUser) (types.ScimUser, error) {
+
+	u.UserName = strings.TrimSpace(u.UserName)
+	if u.UserName == "" {
+		return u, errBadRequest("invalidValue", "userName must not be empty")
+	}
+
+	isNew := id == ""
+	active := u.Active == nil || *u.Active
+	externalId := pgtype.Text{String: u.ExternalId, Valid: u.ExternalId != ""}
+
+	var l loginType
+	var err error
+	if isNew {
+		l.roleIds = make([]uuid.UUID, 0)
+	} else {
+		l, err = getLogin_tx(ctx, tx, c, id)
+		if err != nil {
+			return u, err
+		}
+	}
+
+	// login names are unique over all logins, regardless of their origin
+	isNotUnique, err := login_meta.GetIsNotUnique_tx(ctx, tx, l.id, "name", strings.ToLower(u.UserName))
+	if err != nil {
+		return u, err
+	}
+	if isNotUnique {
+		return u, errConflict(fmt.Sprintf("userName '%s' is already taken", u.UserName))
+	}
+
+	metaNew := getMetaFromUser(u)
+	metaNew.Notes = l.meta.Notes // notes are not provisioned, keep existing
+
+	loginId, err := login.Set_tx(ctx, tx, l.id, c.LoginTemplateId, pgtype.Int4{}, pgtype.Text{}, pgtype.Int4{},
+		pgtype.Text{}, pgtype.Text{}, u.UserName, u.Password, l.admin, false, active, l.tokenExpiryHours,
+		metaNew, l.roleIds, []types.LoginAdminRecordSet{})
+
+	if err != nil {
+		return u, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE instance.login
+		SET scim_client_id = $1, scim_external_id = $2
+		WHERE id = $3
+	`, c.Id, externalId, loginId); err != nil {
+		return u, err
+	}
+
+	if !isNew && l.active && !active {
+		login_clusterEvent.Kick_tx(ctx, tx, loginId, l.name)
+	}
+	return UserGet_tx(ctx, tx, c, strconv.FormatInt(loginId, 10))
+}
+
+// returns login provisioned by given SCIM client, fails if login is not found or is not provisioned by this client
+func getLogin_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, id string) (loginType, error) {
+	var l loginType
+
+	loginId, err := parseLoginId(id)
+	if err != nil {
+		return l, err
+	}
+
+	if err := tx.QueryRow(ctx, `
+		SELECT id, name, admin, active, scim_external_id, token_expiry_hours
+		FROM instance.login
+		WHERE id             = $1
+		AND   scim_client_id = $2
+	`, loginId, c.Id).Scan(&l.id, &l.name, &l.admin, &l.active, &l.externalId, &l.tokenExpiryHours); err != nil {
+		if err == pgx.ErrNoRows {
+			return l, errNotFound("User", id)
+		}
+		return l, err
+	}
+
+	l.meta, err = login_meta.Get_tx(ctx, tx, l.id)
+	if err != nil {
+		return l, err
+	}
+	l.roleIds, err = login_role.Get_tx(ctx, tx, l.id)
+	return l, err
+}
+
+func getUserFromLogin_tx(ctx context.Context, tx pgx.Tx, l loginType) (types.ScimUser, error) {
+	active := l.active
+	u := types.ScimUser{
+		Schemas:     []string{SchemaUser},
+		Id:          strconv.FormatInt(l.id, 10),
+		ExternalId:  l.externalId.String,
+		UserName:    l.name,
+		Active:      &active,
+		DisplayName: l.meta.NameDisplay,
+		Name: types.ScimName{
+			GivenName:  l.meta.NameFore,
+			FamilyName: l.meta.NameSur,
+		},
+		Emails:       make([]types.ScimMultiValue, 0),
+		PhoneNumbers: make([]types.ScimMultiValue, 0),
+		Addresses:    make([]types.ScimAddress, 0),
+		Groups:       make([]types.ScimMultiValue, 0),
+		Meta:         types.ScimMeta{ResourceType: "User"},
+	}
+
+	if l.meta.Email != "" {
+		u.Emails = append(u.Emails, types.ScimMultiValue{Value: l.meta.Email, Type: "work", Primary: true})
+	}
+	if l.meta.PhoneLandline != "" {
+		u.PhoneNumbers = append(u.PhoneNumbers, types.ScimMultiValue{Value: l.meta.PhoneLandline, Type: "work"})
+	}
+	if l.meta.PhoneMobile != "" {
+		u.PhoneNumbers = append(u.PhoneNumbers, types.ScimMultiValue{Value: l.meta.PhoneMobile, Type: "mobile"})
+	}
+	if l.meta.PhoneFax != "" {
+		u.PhoneNumbers = append(u.PhoneNumbers, types.ScimMultiValue{Value: l.meta.PhoneFax, Type: "fax"})
+	}
+	if l.meta.Location != "" {
+		u.Addresses = append(u.Addresses, types.ScimAddress{Locality: l.meta.Location, Type: "work", Primary: true})
+	}
+	if l.meta.Department != "" || l.meta.Organization != "" {
+		u.Schemas = append(u.Schemas, SchemaEnterpriseUser)
+		u.Enterprise = &types.ScimEnterpriseUser{
+			Department:   l.meta.Department,
+			Organization: l.meta.Organization,
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT g.id, g.name
+		FROM instance.scim_group_login AS gl
+		JOIN instance.scim_group       AS g ON g.id = gl.scim_group_id
+		WHERE gl.login_id = $1
+		ORDER BY g.name ASC
+	`, l.id)
+	if err != nil {
+		return u, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupId uuid.UUID
+		var name string
+		if err := rows.Scan(&groupId, &name); err != nil {
+			return u, err
+		}
+		u.Groups = append(u.Groups, types.ScimMultiValue{Value: groupId.String(), Display: name})
+	}
+	return u, nil
+}
+
+// maps SCIM user attributes to login meta data
+func getMetaFromUser(u types.ScimUser) types.LoginMeta {
+	m := types.LoginMeta{
+		NameDisplay: u.DisplayName,
+		NameFore:    u.Name.GivenName,
+		NameSur:     u.Name.FamilyName,
+	}
+	if m.NameDisplay == "" {
+		m.NameDisplay = u.Name.Formatted
+	}
+
+	// primary email, otherwise first
+	for i, e := range u.Emails {
+		if e.Primary || i == 0 {
+			m.Email = e.Value
+		}
+		if e.Primary {
+			break
+		}
+	}
+
+	for _, p := range u.PhoneNumbers {
+		switch strings.ToLower(p.Type) {
+		case "fax":
+			m.PhoneFax = p.Value
+		case "mobile":
+			m.PhoneMobile = p.Value
+		default:
+			if m.PhoneLandline == "" || p.Primary {
+				m.PhoneLandline = p.Value
+			}
+		}
+	}
+
+	// primary address, otherwise first
+	for i, a := range u.Addresses {
+		if a.Primary || i == 0 {
+			m.Location = a.Locality
+			if m.Location == "" {
+				m.Location = a.Formatted
+			}
+		}
+		if a.Primary {
+			break
+		}
+	}
+
+	if u.Enterprise != nil {
+		m.Department = u.Enterprise.Department
+		m.Organization = u.Enterprise.Organization
+	}
+	return m
+}
+
+// updates roles of SCIM provisioned login based on its SCIM group memberships
+func updateLoginRoles_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient, loginId int64) error {
+
+	l, err := getLogin_tx(ctx, tx, c, strconv.FormatInt(loginId, 10))
+	if err != nil {
+		return err
+	}
+
+	var groupNames []string
+	if err := tx.QueryRow(ctx, `
+		SELECT ARRAY(
+			SELECT g.name
+			FROM instance.scim_group_login AS gl
+			JOIN instance.scim_group       AS g ON g.id = gl.scim_group_id
+			WHERE gl.login_id       = $1
+			AND   g.scim_client_id  = $2
+		)
+	`, l.id, c.Id).Scan(&groupNames); err != nil {
+		return err
+	}
+
+	// if group name is used in any role assignment, assign role
+	roleIds := make([]uuid.UUID, 0)
+	for _, assign := range c.LoginRolesAssign {
+		if slices.Contains(groupNames, assign.SearchString) && !slices.Contains(roleIds, assign.RoleId) {
+			roleIds = append(roleIds, assign.RoleId)
+		}
+	}
+
+	var sortFnc = func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) }
+	slices.SortFunc(roleIds, sortFnc)
+	slices.SortFunc(l.roleIds, sortFnc)
+	if slices.Equal(roleIds, l.roleIds) {
+		return nil
+	}
+
+	if _, err := login.Set_tx(ctx, tx, l.id, c.LoginTemplateId, pgtype.Int4{}, pgtype.Text{}, pgtype.Int4{},
+		pgtype.Text{}, pgtype.Text{}, l.name, "", l.admin, false, l.active, l.tokenExpiryHours,
+		l.meta, roleIds, []types.LoginAdminRecordSet{}); err != nil {
+
+		return err
+	}
+
+	if l.active {
+		login_clusterEvent.Reauth_tx(ctx, tx, l.id, l.name)
+	}
+	return nil
+}
+
+func updateLoginRolesAll_tx(ctx context.Context, tx pgx.Tx, c types.ScimClient) error {
+	loginIds := make([]int64, 0)
+	if err := tx.QueryRow(ctx, `
+		SELECT ARRAY(
+			SELECT id
+			FROM instance.login
+			WHERE scim_client_id = $1
+		)
+	`, c.Id).Scan(&loginIds); err != nil {
+		return err
+	}
+
+	for _, id := range loginIds {
+		if err := updateLoginRoles_tx(ctx, tx, c, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
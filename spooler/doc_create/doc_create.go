@@ -28,6 +28,7 @@ type doc struct {
 	p          *fpdf.Fpdf                // PDF document
 	data       map[int]map[uuid.UUID]any // values retrieved from document query, [relationIndex][attributeId]
 	fontKeyMap map[string]bool           // fonts used
+	asOf       pgtype.Int8               // unix time to reconstruct values for from change logs, current values if empty
 
 	evalCounter  int // for unique eval references
 	imageCounter int // for unique image references
@@ -176,7 +177,7 @@ func do(j docJob) error {
 		}
 	}
 
-	fileName, err := Run(ctx, j.DocId, -1, j.RecordIdLoad.Int64, filePath)
+	fileName, err := Run(ctx, j.DocId, -1, j.RecordIdLoad.Int64, pgtype.Int8{}, filePath)
 	if err != nil {
 		return err
 	}
@@ -246,7 +247,8 @@ func do(j docJob) error {
 }
 
 // returns filename of generated document
-func Run(ctx context.Context, docId uuid.UUID, loginId int64, recordId int64, pathOut string) (string, error) {
+// if as-of date is set, document data is reconstructed from change logs
+func Run(ctx context.Context, docId uuid.UUID, loginId int64, recordId int64, asOf pgtype.Int8, pathOut string) (string, error) {
 
 	cache.Schema_mx.RLock()
 	docDef, exists := cache.DocIdMap[docId]
@@ -266,6 +268,7 @@ func Run(ctx context.Context, docId uuid.UUID, loginId int64, recordId int64, pa
 	doc := &doc{
 		data:       make(map[int]map[uuid.UUID]any),
 		fontKeyMap: make(map[string]bool),
+		asOf:       asOf,
 	}
 
 	if docHasData {
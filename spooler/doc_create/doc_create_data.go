@@ -3,11 +3,14 @@ package doc_create
 import (
 	"context"
 	"fmt"
+	"r3/cache"
 	"r3/data"
 	"r3/data/data_query"
 	"r3/db"
 	"r3/tools"
 	"r3/types"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -34,6 +37,10 @@ func getDataDoc(ctx context.Context, doc *doc, loginId int64, recordIdDoc int64,
 		Joins:       data_query.ConvertQueryToDataJoins(q.Joins),
 		Limit:       1,
 		GetPerm:     false,
+		AsOf:        doc.asOf,
+	}
+	if err := checkAsOf(dataGet); err != nil {
+		return err
 	}
 
 	// fetch data
@@ -44,6 +51,10 @@ func getDataDoc(ctx context.Context, doc *doc, loginId int64, recordIdDoc int64,
 	}
 	tx.Commit(ctx)
 
+	if err := checkAsOfResults(dataGet, rows); err != nil {
+		return err
+	}
+
 	if len(rows) != 1 {
 		return fmt.Errorf("failed to process document query, expected 1 row, got %d", len(rows))
 	}
@@ -60,6 +71,53 @@ func getDataDoc(ctx context.Context, doc *doc, loginId int64, recordIdDoc int64,
 	return nil
 }
 
+// documents must not mix current with past values, all expressions must be reconstructable
+func checkAsOf(dataGet types.DataGet) error {
+	if !dataGet.AsOf.Valid {
+		return nil
+	}
+	positions := data.GetAsOfExpressionsInvalid(dataGet)
+	if len(positions) == 0 {
+		return nil
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	names := make([]string, 0)
+	for _, pos := range positions {
+		if atr, exists := cache.AttributeIdMap[dataGet.Expressions[pos].AttributeId.Bytes]; exists {
+			names = append(names, atr.Name)
+		}
+	}
+	return fmt.Errorf("failed to create document as of given date, values cannot be reconstructed from change logs: %s",
+		strings.Join(names, ", "))
+}
+
+// change logs of single records might have been removed by retention, their values are not reconstructable
+func checkAsOfResults(dataGet types.DataGet, rows []types.DataGetResult) error {
+	if !dataGet.AsOf.Valid {
+		return nil
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	names := make([]string, 0)
+	for _, row := range rows {
+		for _, pos := range row.ValuesNoAsOf {
+			if atr, exists := cache.AttributeIdMap[dataGet.Expressions[pos].AttributeId.Bytes]; exists && !slices.Contains(names, atr.Name) {
+				names = append(names, atr.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to create document as of given date, change logs were removed by retention for values: %s",
+		strings.Join(names, ", "))
+}
+
 // returns whether an attribute value can be returned as string and the string value itself if valid
 func getAttributeString(font types.DocFont, atr types.Attribute, convertHtmlToString bool, valueIf any) (bool, string, error) {
 
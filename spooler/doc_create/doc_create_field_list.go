@@ -46,6 +46,7 @@ func addFieldList(ctx context.Context, doc *doc, loginId int64, recordIdDoc int6
 		Joins:       data_query.ConvertQueryToDataJoins(f.Query.Joins),
 		Orders:      data_query.ConvertQueryToDataOrders(f.Query.Orders),
 		Limit:       f.Query.FixedLimit,
+		AsOf:        doc.asOf,
 	}
 
 	// build expressions from columns
@@ -57,6 +58,9 @@ func addFieldList(ctx context.Context, doc *doc, loginId int64, recordIdDoc int6
 	if len(dataGet.Expressions) == 0 {
 		return fmt.Errorf("failed to add list field, 0 expressions defined")
 	}
+	if err := checkAsOf(dataGet); err != nil {
+		return err
+	}
 
 	// fetch data
 	var query string
@@ -67,6 +71,9 @@ func addFieldList(ctx context.Context, doc *doc, loginId int64, recordIdDoc int6
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
+	if err := checkAsOfResults(dataGet, rows); err != nil {
+		return err
+	}
 
 	// disable auto paging
 	_, pageMarginB := doc.p.GetAutoPageBreak()
@@ -0,0 +1,234 @@
+package file_text
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"r3/data"
+	"r3/db"
+	"r3/log"
+	"r3/tools"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/h2non/filetype"
+)
+
+// extracts text from uploaded file versions, to make file contents searchable
+// PDF, office documents (DOCX, ODT) & plain text files are read directly
+// PDF & images are processed by external tools if available (pdftotext, tesseract for OCR)
+
+var (
+	canPdf          bool   // has access to PDF text extractor
+	canOcr          bool   // has access to OCR tool
+	pdfPath         string // path to PDF text extractor
+	ocrPath         string // path to OCR tool
+	batchSize       = 50
+	fileSizeMaxKb   = 51200           // larger files are not processed
+	textLengthMax   = 256 * 1024      // max. length of stored text in bytes, text search vectors are limited in size
+	toolTimeout     = 2 * time.Minute // max. duration of external tool call
+	regexTagsMarkup = regexp.MustCompile(`<[^>]*>`)
+
+	extsImage = []string{"bmp", "gif", "jpeg", "jpg", "png", "tif", "tiff", "webp"}
+	extsText  = []string{"csv", "htm", "html", "ini", "json", "log", "md", "sql", "tsv", "txt", "xml", "yaml", "yml"}
+)
+
+// checks for external tools to extract text with
+func PrepareProcessing() {
+	canPdf, canOcr = setCheckToolPaths()
+
+	log.Info(log.ContextFile, fmt.Sprintf("text extraction capabilities, PDF tool: %v, OCR: %v", canPdf, canOcr))
+}
+
+func DoAll() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	type file struct {
+		id      uuid.UUID
+		version int64
+		name    string
+		sizeKb  int64
+	}
+	files := make([]file, 0)
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT t.file_id, t.version, t.name, v.size_kb
+		FROM instance.file_text         AS t
+		JOIN instance.file_version AS v
+			ON  v.file_id = t.file_id
+			AND v.version = t.version
+		WHERE t.date_extract IS NULL
+		ORDER BY v.date_change ASC
+		LIMIT $1
+	`, batchSize)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var f file
+		if err := rows.Scan(&f.id, &f.version, &f.name, &f.sizeKb); err != nil {
+			rows.Close()
+			return err
+		}
+		files = append(files, f)
+	}
+	rows.Close()
+
+	for _, f := range files {
+		var text string
+		if f.sizeKb <= int64(fileSizeMaxKb) {
+			text, err = extract(data.GetFilePathVersion(f.id, f.version), f.name)
+			if err != nil {
+				// failed extractions are not repeated, file is stored without text
+				log.Warning(log.ContextFile, fmt.Sprintf("failed to extract text from file '%s' (version %d)", f.id, f.version), err)
+			}
+		}
+
+		if err := store(ctx, f.id, f.version, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func store(ctx context.Context, fileId uuid.UUID, version int64, text string) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	text = cleanText(text)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE instance.file_text
+		SET content = NULLIF($1,''), date_extract = $2
+		WHERE file_id = $3
+		AND   version = $4
+	`, text, tools.GetTimeUnix(), fileId, version); err != nil {
+		return err
+	}
+
+	if text != "" {
+		if err := data.SearchIndexSetForFile_tx(ctx, tx, fileId); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// returns text content of file, empty if file type is not supported
+func extract(filePath string, fileName string) (string, error) {
+
+	ext, err := getFileType(filePath, fileName)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case ext == "docx":
+		return extractZipXml(filePath, "word/document.xml", []string{"p"})
+	case ext == "odt":
+		return extractZipXml(filePath, "content.xml", []string{"h", "p"})
+	case ext == "pdf":
+		if canPdf {
+			return runTool(pdfPath, "-enc", "UTF-8", "-q", filePath, "-")
+		}
+		return extractPdf(filePath)
+	case slices.Contains(extsImage, ext):
+		if canOcr {
+			return runTool(ocrPath, filePath, "stdout")
+		}
+		return "", nil
+	case slices.Contains(extsText, ext):
+		b, err := readLimited(filePath)
+		if err != nil {
+			return "", err
+		}
+		text := string(tools.RemoveUtf8Bom(b))
+		if slices.Contains([]string{"htm", "html", "xml"}, ext) {
+			text = regexTagsMarkup.ReplaceAllString(text, " ")
+		}
+		return text, nil
+	}
+	return "", nil
+}
+
+// returns lower case file extension, detected from file content if file name has no extension
+func getFileType(filePath string, fileName string) (string, error) {
+	if ext := strings.ToLower(tools.GetFileExtension(fileName)); ext != "" {
+		return ext, nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 8192)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+
+	kind, err := filetype.Match(head)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case kind.Extension == "zip" && bytes.Contains(head, []byte("application/vnd.oasis.opendocument.text")):
+		return "odt", nil
+	case kind != filetype.Unknown:
+		return kind.Extension, nil
+	case utf8.Valid(head):
+		return "txt", nil
+	}
+	return "", nil
+}
+
+func readLimited(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, int64(textLengthMax)))
+}
+
+func runTool(path string, args ...string) (string, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), toolTimeout)
+	defer ctxCanc()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed, %v: %s", filepath.Base(path), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// returns valid UTF8 text without NUL characters (not supported by Postgres) & collapsed white space, limited in length
+func cleanText(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.ReplaceAll(text, "\x00", "")
+	text = strings.Join(strings.Fields(text), " ")
+
+	if len(text) > textLengthMax {
+		text = strings.ToValidUTF8(text[:textLengthMax], "")
+	}
+	return text
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package file_text
+
+import "os/exec"
+
+func setCheckToolPaths() (bool, bool) {
+	pdfPath = "pdftotext"
+	ocrPath = "tesseract"
+
+	_, errPdf := exec.LookPath(pdfPath)
+	_, errOcr := exec.LookPath(ocrPath)
+	return errPdf == nil, errOcr == nil
+}
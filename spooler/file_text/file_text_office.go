@@ -0,0 +1,58 @@
+package file_text
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// returns text of XML document inside ZIP archive (DOCX, ODT)
+// paragraph elements are separated by line breaks
+func extractZipXml(filePath string, entryName string, paragraphElements []string) (string, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != entryName {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		var out strings.Builder
+		dec := xml.NewDecoder(io.LimitReader(r, int64(textLengthMax)*8))
+		for out.Len() < textLengthMax {
+			token, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return out.String(), err
+			}
+			switch t := token.(type) {
+			case xml.CharData:
+				out.Write(t)
+			case xml.EndElement:
+				if slices.Contains(paragraphElements, t.Name.Local) {
+					out.WriteString("\n")
+				}
+			case xml.StartElement:
+				// tabs & line breaks inside paragraphs
+				if slices.Contains([]string{"br", "line-break", "s", "tab"}, t.Name.Local) {
+					out.WriteString(" ")
+				}
+			}
+		}
+		return out.String(), nil
+	}
+	return "", fmt.Errorf("document '%s' not found in archive", entryName)
+}
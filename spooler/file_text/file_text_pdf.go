@@ -0,0 +1,236 @@
+package file_text
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// returns text of PDF file, used if no external PDF tool is available
+// reads text operators from uncompressed & deflated content streams
+// text of fonts with custom encodings (e. g. embedded subsets) cannot be decoded without font mappings and is skipped
+func extractPdf(filePath string) (string, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	pos := 0
+	for out.Len() < textLengthMax {
+		i := bytes.Index(b[pos:], []byte("stream"))
+		if i == -1 {
+			break
+		}
+		keyword := pos + i
+		start := keyword + len("stream")
+		pos = start
+
+		// ignore 'endstream', stream keyword must be followed by line break
+		if bytes.HasSuffix(b[:keyword], []byte("end")) {
+			continue
+		}
+		if start < len(b) && b[start] == '\r' {
+			start++
+		}
+		if start >= len(b) || b[start] != '\n' {
+			continue
+		}
+		start++
+
+		end := bytes.Index(b[start:], []byte("endstream"))
+		if end == -1 {
+			break
+		}
+		content := b[start : start+end]
+		pos = start + end + len("endstream")
+
+		// stream dictionary, starting after the object definition
+		dict := b[max(0, keyword-1024):keyword]
+		if k := bytes.LastIndex(dict, []byte(" obj")); k != -1 {
+			dict = dict[k:]
+		}
+
+		// skip images, fonts & other non-content streams
+		if bytes.Contains(dict, []byte("/Subtype")) || bytes.Contains(dict, []byte("/Length1")) ||
+			bytes.Contains(dict, []byte("/Type/XRef")) || bytes.Contains(dict, []byte("/Type /XRef")) {
+			continue
+		}
+
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			r, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			content, err = io.ReadAll(io.LimitReader(r, int64(textLengthMax)*16))
+			r.Close()
+			if err != nil && len(content) == 0 {
+				continue
+			}
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			// other filters are not supported
+			continue
+		}
+		readPdfContent(&out, content)
+	}
+	return out.String(), nil
+}
+
+// writes text shown by text operators (Tj, TJ, ', ") of PDF content stream
+func readPdfContent(out *strings.Builder, content []byte) {
+	inText := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '%':
+			// comment until end of line
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, n := readPdfLiteral(content[i:])
+			if inText {
+				out.WriteString(s)
+			}
+			i += n - 1
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			n := bytes.IndexByte(content[i:], '>')
+			if n == -1 {
+				return
+			}
+			if inText {
+				out.WriteString(decodePdfHex(content[i+1 : i+n]))
+			}
+			i += n
+		case isPdfRegular(c):
+			n := 1
+			for i+n < len(content) && isPdfRegular(content[i+n]) {
+				n++
+			}
+			token := string(content[i : i+n])
+			i += n - 1
+
+			switch token {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				out.WriteString("\n")
+			case "Td", "TD", "T*", "Tm", "'", "\"":
+				out.WriteString(" ")
+			case "ID":
+				// inline image data, skip until end of image
+				if k := bytes.Index(content[i:], []byte("EI")); k != -1 {
+					i += k + 1
+				}
+			default:
+				// large negative offsets in TJ arrays usually separate words
+				if inText {
+					if f, err := strconv.ParseFloat(token, 64); err == nil && f < -200 {
+						out.WriteString(" ")
+					}
+				}
+			}
+		}
+	}
+}
+
+// returns decoded PDF literal string & its length in bytes, including parentheses
+func readPdfLiteral(b []byte) (string, int) {
+	var out []rune
+	depth := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(out), i + 1
+			}
+		case '\\':
+			i++
+			if i >= len(b) {
+				return string(out), i
+			}
+			switch e := b[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					n := 1
+					for n < 3 && i+n < len(b) && b[i+n] >= '0' && b[i+n] <= '7' {
+						n++
+					}
+					v, _ := strconv.ParseUint(string(b[i:i+n]), 8, 8)
+					out = append(out, rune(v))
+					i += n - 1
+				} else {
+					out = append(out, rune(e))
+				}
+			}
+			continue
+		}
+		out = append(out, rune(c))
+	}
+	return string(out), len(b)
+}
+
+// returns text of PDF hex string, UTF16 if marked by byte order mark, single byte characters otherwise
+func decodePdfHex(b []byte) string {
+	hex := strings.Join(strings.Fields(string(b)), "")
+	if len(hex)%2 != 0 {
+		hex += "0"
+	}
+	raw := make([]byte, 0, len(hex)/2)
+	for i := 0; i < len(hex); i += 2 {
+		v, err := strconv.ParseUint(hex[i:i+2], 16, 8)
+		if err != nil {
+			return ""
+		}
+		raw = append(raw, byte(v))
+	}
+
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		u := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			u = append(u, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(u))
+	}
+
+	// 2-byte character codes of composite fonts cannot be decoded without font mappings
+	for _, c := range raw {
+		if c == 0 {
+			return ""
+		}
+	}
+	out := make([]rune, len(raw))
+	for i, c := range raw {
+		out[i] = rune(c)
+	}
+	return string(out)
+}
+
+func isPdfRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
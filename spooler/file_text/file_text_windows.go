@@ -0,0 +1,16 @@
+//go:build windows
+
+package file_text
+
+import (
+	"r3/tools"
+)
+
+func setCheckToolPaths() (bool, bool) {
+	pdfPath = "poppler/pdftotext.exe"
+	ocrPath = "tesseract/tesseract.exe"
+
+	existsPdf, errPdf := tools.Exists(pdfPath)
+	existsOcr, errOcr := tools.Exists(ocrPath)
+	return errPdf == nil && existsPdf, errOcr == nil && existsOcr
+}
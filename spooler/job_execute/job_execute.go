@@ -0,0 +1,284 @@
+// for executing background jobs, added from backend functions via instance.job_add()
+
+package job_execute
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/log"
+	"r3/schema"
+	"r3/tools"
+	"sync"
+
+	"github.com/gofrs/uuid"
+)
+
+// jobs are claimed by all cluster nodes with 'FOR UPDATE SKIP LOCKED'
+// concurrency limits of queues apply to the entire cluster, the queue row is locked while claiming
+// claimed jobs are executed in the background, task execution is not blocked while jobs are running
+
+var (
+	access_mx          sync.Mutex
+	doAll_mx           sync.Mutex
+	jobIdMapRunning    = make(map[int64]bool) // IDs of jobs running on this node
+	jobsRunningMax     = 10                   // max. number of jobs running in parallel on this node
+	backoffSecondsBase = 30                   // delay before retrying failed job, doubled with each attempt
+	backoffSecondsMax  = 3600                 // max. delay before retrying failed job
+)
+
+type job struct {
+	id           int64
+	pgFunctionId uuid.UUID
+	args         []byte // JSONB, nil if not set
+	attempts     int
+	attemptsMax  int
+}
+
+func DoAll() error {
+	doAll_mx.Lock()
+	defer doAll_mx.Unlock()
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	if err := resetInterrupted(ctx); err != nil {
+		return err
+	}
+	if err := cleanup(ctx); err != nil {
+		return err
+	}
+
+	// get queues with jobs waiting to run
+	queues := make([]string, 0)
+	rows, err := db.Pool.Query(ctx, `
+		SELECT q.name
+		FROM instance.job_queue AS q
+		WHERE EXISTS (
+			SELECT id
+			FROM instance.job
+			WHERE queue = q.name
+			AND   state = 'waiting'
+			AND   date_run_after <= $1
+		)
+		ORDER BY q.name ASC
+	`, tools.GetTimeUnix())
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		queues = append(queues, name)
+	}
+	rows.Close()
+
+	for _, queue := range queues {
+		access_mx.Lock()
+		slotsNode := jobsRunningMax - len(jobIdMapRunning)
+		access_mx.Unlock()
+
+		if slotsNode <= 0 {
+			break
+		}
+
+		jobs, err := claim(ctx, queue, slotsNode)
+		if err != nil {
+			return err
+		}
+		for _, j := range jobs {
+			access_mx.Lock()
+			jobIdMapRunning[j.id] = true
+			access_mx.Unlock()
+
+			go execute(j)
+		}
+	}
+	return nil
+}
+
+// claims waiting jobs of queue for this node, up to the free slots of the queue & node
+func claim(ctx context.Context, queue string, slotsNode int) ([]job, error) {
+	jobs := make([]job, 0)
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return jobs, err
+	}
+	defer tx.Rollback(ctx)
+
+	// lock queue to keep concurrency limit, while jobs are being claimed
+	var concurrency, running int
+	if err := tx.QueryRow(ctx, `
+		SELECT concurrency
+		FROM instance.job_queue
+		WHERE name = $1
+		FOR UPDATE
+	`, queue).Scan(&concurrency); err != nil {
+		return jobs, err
+	}
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.job
+		WHERE queue = $1
+		AND   state = 'running'
+	`, queue).Scan(&running); err != nil {
+		return jobs, err
+	}
+
+	slots := min(concurrency-running, slotsNode)
+	if slots <= 0 {
+		return jobs, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE instance.job
+		SET state = 'running', node_id = $1, attempts = attempts + 1,
+			date_milli_start = $2, date_milli_end = NULL
+		WHERE id IN (
+			SELECT id
+			FROM instance.job
+			WHERE queue = $3
+			AND   state = 'waiting'
+			AND   date_run_after <= $4
+			ORDER BY priority DESC, date_run_after ASC, id ASC
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, pg_function_id, args, attempts, attempts_max
+	`, cache.GetNodeId(), tools.GetTimeUnixMilli(), queue, tools.GetTimeUnix(), slots)
+	if err != nil {
+		return jobs, err
+	}
+	for rows.Next() {
+		var j job
+		if err := rows.Scan(&j.id, &j.pgFunctionId, &j.args, &j.attempts, &j.attemptsMax); err != nil {
+			rows.Close()
+			return jobs, err
+		}
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return jobs, err
+	}
+	return jobs, tx.Commit(ctx)
+}
+
+func execute(j job) {
+	if err := executeFunction(j); err != nil {
+		log.Error(log.ContextScheduler, fmt.Sprintf("failed to execute job %d (attempt %d of %d)",
+			j.id, j.attempts, j.attemptsMax), err)
+
+		if err := setFailed(j, err); err != nil {
+			log.Error(log.ContextScheduler, fmt.Sprintf("failed to update state of job %d", j.id), err)
+		}
+	}
+
+	access_mx.Lock()
+	delete(jobIdMapRunning, j.id)
+	access_mx.Unlock()
+}
+
+// executes backend function of job and stores its result, in the same transaction
+func executeFunction(j job) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutPgFunc)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	modName, fncName, _, _, err := schema.GetPgFunctionDetailsById_tx(ctx, tx, j.pgFunctionId)
+	if err != nil {
+		return err
+	}
+
+	// functions without arguments are called if no arguments were given, otherwise a single JSONB argument is passed
+	var result *string
+	if j.args == nil {
+		err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT "%s"."%s"()::TEXT`, modName, fncName)).Scan(&result)
+	} else {
+		err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT "%s"."%s"($1::JSONB)::TEXT`, modName, fncName), j.args).Scan(&result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE instance.job
+		SET state = 'done', date_milli_end = $1, result = $2, error_message = NULL
+		WHERE id = $3
+	`, tools.GetTimeUnixMilli(), result, j.id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// sets job to be retried later or, if no attempts are left, to have failed
+func setFailed(j job, errJob error) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE instance.job
+		SET state = CASE WHEN attempts < attempts_max THEN 'waiting' ELSE 'failed' END::instance.job_state,
+			date_milli_end = $1, error_message = $2,
+			date_run_after = $3 + LEAST($4 * POWER(2, attempts - 1), $5)::BIGINT
+		WHERE id = $6
+	`, tools.GetTimeUnixMilli(), errJob.Error(), tools.GetTimeUnix(),
+		backoffSecondsBase, backoffSecondsMax, j.id)
+	return err
+}
+
+// resets jobs that were interrupted (node restarted or went missing while executing)
+func resetInterrupted(ctx context.Context) error {
+	access_mx.Lock()
+	jobIdsRunning := make([]int64, 0, len(jobIdMapRunning))
+	for id := range jobIdMapRunning {
+		jobIdsRunning = append(jobIdsRunning, id)
+	}
+	access_mx.Unlock()
+
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE instance.job
+		SET state = CASE WHEN attempts < attempts_max THEN 'waiting' ELSE 'failed' END::instance.job_state,
+			date_milli_end = $1, error_message = 'job was interrupted'
+		WHERE state = 'running'
+		AND (
+			(node_id = $2 AND id <> ALL($3))
+			OR node_id IN (
+				SELECT id
+				FROM instance_cluster.node
+				WHERE id <> $2
+				AND (running = FALSE OR date_check_in < $4)
+			)
+		)
+	`, tools.GetTimeUnixMilli(), cache.GetNodeId(), jobIdsRunning,
+		tools.GetTimeUnix()-int64(config.GetUint64("clusterNodeMissingAfter")))
+	return err
+}
+
+// deletes finished jobs after the configured number of days
+func cleanup(ctx context.Context) error {
+	keepForDays := config.GetUint64("jobsKeepDays")
+	if keepForDays == 0 {
+		return nil
+	}
+
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.job
+		WHERE state IN ('done','failed')
+		AND   date_milli_end < $1
+	`, (tools.GetTimeUnix()-int64(60*60*24*keepForDays))*1000)
+	return err
+}
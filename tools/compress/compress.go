@@ -16,10 +16,16 @@ func Path(zipPath string, sourcePath string) error {
 	}
 	defer zipFile.Close()
 
-	zipWriter := zip.NewWriter(zipFile)
+	return PathToWriter(zipFile, sourcePath)
+}
+
+// writes zip file of source path to writer (like an encrypting stream)
+func PathToWriter(w io.Writer, sourcePath string) error {
+
+	zipWriter := zip.NewWriter(w)
 	defer zipWriter.Close()
 
-	return filepath.Walk(sourcePath, func(pathWalked string, info os.FileInfo, err error) error {
+	if err := filepath.Walk(sourcePath, func(pathWalked string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -50,5 +56,56 @@ func Path(zipPath string, sourcePath string) error {
 
 		_, err = io.Copy(zipFileWriter, fileWalked)
 		return err
-	})
+	}); err != nil {
+		return err
+	}
+
+	// central directory must be written before writer is completed
+	return zipWriter.Close()
+}
+
+// extracts files from a zip file created by Path() into the target path
+// the compressed source directory is replaced by the target path
+func Extract(zipPath string, targetPath string) error {
+
+	zipReader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+
+	for _, zipFile := range zipReader.File {
+
+		// zip files written on windows use backslashes, remove source directory from file path
+		_, pathRel, found := strings.Cut(strings.ReplaceAll(zipFile.Name, `\`, "/"), "/")
+		if !found || !filepath.IsLocal(pathRel) {
+			continue
+		}
+
+		if err := extractFile(zipFile, filepath.Join(targetPath, filepath.FromSlash(pathRel))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(zipFile *zip.File, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+
+	zipFileReader, err := zipFile.Open()
+	if err != nil {
+		return err
+	}
+	defer zipFileReader.Close()
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, zipFileReader)
+	return err
 }
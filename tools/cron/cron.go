@@ -0,0 +1,417 @@
+/*
+Parses and evaluates cron expressions with seconds:
+second minute hour day-of-month month day-of-week
+
+5 field expressions (without seconds) are accepted as well, seconds are then set to 0
+supported per field: * ? , - / and names for months (JAN-DEC) and weekdays (SUN-SAT)
+day-of-month also supports: L (last day), L-n (n days before last day), nW (nearest business day), LW (last business day)
+day-of-week also supports: nL (last weekday n of month), n#k (k-th weekday n of month)
+
+business days are weekdays (Monday to Friday) that are not excluded
+excluded days (e. g. public holidays) never match any expression
+*/
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// returns true if the given day is to be excluded (e. g. public holiday)
+type ExcludeFn func(year int, month time.Month, day int) bool
+
+type Schedule struct {
+	seconds uint64 // bit set of matching seconds   (0-59)
+	minutes uint64 // bit set of matching minutes   (0-59)
+	hours   uint64 // bit set of matching hours     (0-23)
+	days    uint64 // bit set of matching days      (1-31)
+	months  uint64 // bit set of matching months    (1-12)
+	weekday uint64 // bit set of matching weekdays  (0-6, 0 = Sunday)
+
+	daysAny    bool // day-of-month is * or ?
+	weekdayAny bool // day-of-week is * or ?
+
+	// special day-of-month options
+	dayLast         bool  // L
+	dayLastOffsets  []int // L-n
+	dayBusiness     []int // nW
+	dayBusinessLast bool  // LW
+
+	// special day-of-week options
+	weekdayLast []int    // nL
+	weekdayNth  [][2]int // n#k (weekday, nth)
+}
+
+var (
+	namesMonth   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	namesWeekday = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	searchYears  = 5 // how far into the future to look for the next match
+)
+
+func Parse(expr string) (Schedule, error) {
+	var s Schedule
+
+	fields := strings.Fields(strings.ToUpper(expr))
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return s, fmt.Errorf("cron expression must have 5 or 6 fields, got %d", len(fields))
+	}
+
+	var err error
+	if s.seconds, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return s, fmt.Errorf("invalid seconds, %s", err)
+	}
+	if s.minutes, err = parseField(fields[1], 0, 59, nil); err != nil {
+		return s, fmt.Errorf("invalid minutes, %s", err)
+	}
+	if s.hours, err = parseField(fields[2], 0, 23, nil); err != nil {
+		return s, fmt.Errorf("invalid hours, %s", err)
+	}
+	if s.months, err = parseField(fields[4], 1, 12, namesMonth); err != nil {
+		return s, fmt.Errorf("invalid month, %s", err)
+	}
+	if err := s.parseDays(fields[3]); err != nil {
+		return s, fmt.Errorf("invalid day of month, %s", err)
+	}
+	if err := s.parseWeekdays(fields[5]); err != nil {
+		return s, fmt.Errorf("invalid day of week, %s", err)
+	}
+	return s, nil
+}
+
+// returns the first time after t that matches the schedule, in the location of t
+// returns false if no time matches within the search period
+func (s Schedule) Next(t time.Time, excludeFn ExcludeFn) (time.Time, bool) {
+	loc := t.Location()
+	t = t.Truncate(time.Second)
+
+	y, m, d := t.Date()
+	dayEnd := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).AddDate(searchYears, 0, 0)
+
+	for day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC); day.Before(dayEnd); day = day.AddDate(0, 0, 1) {
+		y, m, d := day.Date()
+
+		if !hasBit(s.months, int(m)) {
+			// skip to first day of next month
+			day = time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchDay(y, m, d, excludeFn) {
+			continue
+		}
+
+		for hour := 0; hour < 24; hour++ {
+			if !hasBit(s.hours, hour) {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !hasBit(s.minutes, minute) {
+					continue
+				}
+				for second := 0; second < 60; second++ {
+					if !hasBit(s.seconds, second) {
+						continue
+					}
+
+					// compare as absolute time, as wall clock times inside a DST overlap exist twice
+					tm := getTimeFirst(y, m, d, hour, minute, second, loc)
+					if tm.After(t) {
+						return tm, true
+					}
+				}
+			}
+		}
+	}
+	return t, false
+}
+
+// returns the next n times after t that match the schedule
+func (s Schedule) NextN(t time.Time, n int, excludeFn ExcludeFn) []time.Time {
+	out := make([]time.Time, 0)
+	for len(out) < n {
+		next, ok := s.Next(t, excludeFn)
+		if !ok {
+			break
+		}
+		out = append(out, next)
+		t = next
+	}
+	return out
+}
+
+// day matching
+func (s Schedule) matchDay(y int, m time.Month, d int, excludeFn ExcludeFn) bool {
+	if excludeFn != nil && excludeFn(y, m, d) {
+		return false
+	}
+
+	// as in common cron implementations, if day-of-month and day-of-week are both restricted, either can match
+	switch {
+	case s.daysAny && s.weekdayAny:
+		return true
+	case s.daysAny:
+		return s.matchWeekday(y, m, d)
+	case s.weekdayAny:
+		return s.matchDayOfMonth(y, m, d, excludeFn)
+	}
+	return s.matchDayOfMonth(y, m, d, excludeFn) || s.matchWeekday(y, m, d)
+}
+func (s Schedule) matchDayOfMonth(y int, m time.Month, d int, excludeFn ExcludeFn) bool {
+	if hasBit(s.days, d) {
+		return true
+	}
+
+	dayLast := daysInMonth(y, m)
+	if s.dayLast && d == dayLast {
+		return true
+	}
+	for _, offset := range s.dayLastOffsets {
+		if d == dayLast-offset {
+			return true
+		}
+	}
+	if s.dayBusinessLast && d == getBusinessDayLast(y, m, excludeFn) {
+		return true
+	}
+	for _, target := range s.dayBusiness {
+		if d == getBusinessDayNearest(y, m, target, excludeFn) {
+			return true
+		}
+	}
+	return false
+}
+func (s Schedule) matchWeekday(y int, m time.Month, d int) bool {
+	weekday := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday())
+	if hasBit(s.weekday, weekday) {
+		return true
+	}
+	for _, w := range s.weekdayLast {
+		if w == weekday && d+7 > daysInMonth(y, m) {
+			return true
+		}
+	}
+	for _, wn := range s.weekdayNth {
+		if wn[0] == weekday && (d-1)/7+1 == wn[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// parsing
+func (s *Schedule) parseDays(field string) error {
+	if field == "*" || field == "?" {
+		s.daysAny = true
+		return nil
+	}
+
+	regular := make([]string, 0)
+	for _, part := range strings.Split(field, ",") {
+		switch {
+		case part == "L":
+			s.dayLast = true
+		case part == "LW":
+			s.dayBusinessLast = true
+		case strings.HasPrefix(part, "L-"):
+			offset, err := strconv.Atoi(part[2:])
+			if err != nil || offset < 0 || offset > 30 {
+				return fmt.Errorf("invalid offset '%s'", part)
+			}
+			s.dayLastOffsets = append(s.dayLastOffsets, offset)
+		case strings.HasSuffix(part, "W"):
+			day, err := strconv.Atoi(strings.TrimSuffix(part, "W"))
+			if err != nil || day < 1 || day > 31 {
+				return fmt.Errorf("invalid business day '%s'", part)
+			}
+			s.dayBusiness = append(s.dayBusiness, day)
+		default:
+			regular = append(regular, part)
+		}
+	}
+	if len(regular) == 0 {
+		return nil
+	}
+
+	var err error
+	s.days, err = parseField(strings.Join(regular, ","), 1, 31, nil)
+	return err
+}
+func (s *Schedule) parseWeekdays(field string) error {
+	if field == "*" || field == "?" {
+		s.weekdayAny = true
+		return nil
+	}
+
+	regular := make([]string, 0)
+	for _, part := range strings.Split(field, ",") {
+		switch {
+		case strings.Contains(part, "#"):
+			v := strings.SplitN(part, "#", 2)
+			weekday, err := parseValue(v[0], 0, 7, namesWeekday)
+			if err != nil {
+				return err
+			}
+			nth, err := strconv.Atoi(v[1])
+			if err != nil || nth < 1 || nth > 5 {
+				return fmt.Errorf("invalid occurrence '%s'", part)
+			}
+			s.weekdayNth = append(s.weekdayNth, [2]int{weekday % 7, nth})
+		case len(part) > 1 && strings.HasSuffix(part, "L"):
+			weekday, err := parseValue(strings.TrimSuffix(part, "L"), 0, 7, namesWeekday)
+			if err != nil {
+				return err
+			}
+			s.weekdayLast = append(s.weekdayLast, weekday%7)
+		default:
+			regular = append(regular, part)
+		}
+	}
+	if len(regular) == 0 {
+		return nil
+	}
+
+	bits, err := parseField(strings.Join(regular, ","), 0, 7, namesWeekday)
+	if err != nil {
+		return err
+	}
+
+	// 7 is also Sunday
+	if hasBit(bits, 7) {
+		bits |= 1
+	}
+	s.weekday = bits
+	return nil
+}
+
+// parses a list of values, ranges and steps into a bit set
+func parseField(field string, min int, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return 0, errors.New("empty list element")
+		}
+
+		step := 1
+		if rangeStr, stepStr, hasStep := strings.Cut(part, "/"); hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step '%s'", part)
+			}
+			part = rangeStr
+		}
+
+		var from, to int
+		switch {
+		case part == "*" || part == "?":
+			from, to = min, max
+		case strings.Contains(part, "-"):
+			v := strings.SplitN(part, "-", 2)
+			var err error
+			if from, err = parseValue(v[0], min, max, names); err != nil {
+				return 0, err
+			}
+			if to, err = parseValue(v[1], min, max, names); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid range '%s'", part)
+			}
+		default:
+			var err error
+			if from, err = parseValue(part, min, max, names); err != nil {
+				return 0, err
+			}
+
+			// a single value with step runs until the maximum (e. g. 5/15 = 5,20,35,50)
+			to = from
+			if step > 1 {
+				to = max
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+func parseValue(value string, min int, max int, names []string) (int, error) {
+	for i, name := range names {
+		if value == name {
+			// month names start at 1, weekday names at 0
+			return i + min, nil
+		}
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("value '%s' is outside of %d-%d", value, min, max)
+	}
+	return v, nil
+}
+
+// helpers
+// returns the first point in time at which the wall clock in the location shows the given date & time
+// times inside a DST gap do not exist, they are shifted forward by the gap length
+// times inside a DST overlap exist twice, the first occurrence is used so that repeated times do not match again
+func getTimeFirst(y int, m time.Month, d int, hour int, minute int, second int, loc *time.Location) time.Time {
+	tm := time.Date(y, m, d, hour, minute, second, 0, loc)
+
+	// wall clock time with the offset valid before a possible transition
+	_, offsetBefore := tm.Add(-3 * time.Hour).Zone()
+	first := time.Unix(time.Date(y, m, d, hour, minute, second, 0, time.UTC).Unix()-int64(offsetBefore), 0).In(loc)
+
+	if first.Before(tm) && first.Day() == d && first.Hour() == hour &&
+		first.Minute() == minute && first.Second() == second {
+
+		return first
+	}
+	return tm
+}
+func daysInMonth(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+func hasBit(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+func isBusinessDay(y int, m time.Month, d int, excludeFn ExcludeFn) bool {
+	weekday := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday()
+	if weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	return excludeFn == nil || !excludeFn(y, m, d)
+}
+
+// returns the last business day of the month, 0 if there is none
+func getBusinessDayLast(y int, m time.Month, excludeFn ExcludeFn) int {
+	for d := daysInMonth(y, m); d > 0; d-- {
+		if isBusinessDay(y, m, d, excludeFn) {
+			return d
+		}
+	}
+	return 0
+}
+
+// returns the business day nearest to the target day within the same month, 0 if there is none
+// if two business days are equally near, the earlier one is used
+func getBusinessDayNearest(y int, m time.Month, target int, excludeFn ExcludeFn) int {
+	dayLast := daysInMonth(y, m)
+	if target > dayLast {
+		return 0
+	}
+	for offset := 0; offset < dayLast; offset++ {
+		if d := target - offset; d >= 1 && isBusinessDay(y, m, d, excludeFn) {
+			return d
+		}
+		if d := target + offset; d <= dayLast && isBusinessDay(y, m, d, excludeFn) {
+			return d
+		}
+	}
+	return 0
+}
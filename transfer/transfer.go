@@ -51,19 +51,21 @@ func AddVersion_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) error {
 	file.Content.Module.ReleaseBuild = file.Content.Module.ReleaseBuild + 1
 	file.Content.Module.ReleaseDate = tools.GetTimeUnix()
 
-	// update release info - take uncommited logs (build 0) and create a new release for them
-	anyReleaseLogs := false
+	// update release info - take uncommited logs & migrations (build 0) and create a new release for them
+	anyReleaseEntries := false
 	for i, r := range file.Content.Module.Releases {
 		if r.Build == 0 {
-			if len(r.Logs) != 0 {
+			if len(r.Logs) != 0 || len(r.Migrations) != 0 {
 				file.Content.Module.Releases = append(file.Content.Module.Releases, types.Release{
 					Build:       int64(file.Content.Module.ReleaseBuild),
 					BuildApp:    int64(file.Content.Module.ReleaseBuildApp),
 					DateCreated: file.Content.Module.ReleaseDate,
 					Logs:        r.Logs,
+					Migrations:  r.Migrations,
 				})
 				file.Content.Module.Releases[i].Logs = make([]types.ReleaseLog, 0)
-				anyReleaseLogs = true
+				file.Content.Module.Releases[i].Migrations = make([]uuid.UUID, 0)
+				anyReleaseEntries = true
 			}
 			break
 		}
@@ -79,8 +81,8 @@ func AddVersion_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) error {
 		return err
 	}
 
-	// update version info & release logs in DB
-	if anyReleaseLogs {
+	// update version info, release logs & migrations in DB
+	if anyReleaseEntries {
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO app.release (module_id, build, build_app, date_created)
 			VALUES ($1,$2,$3,$4)
@@ -95,6 +97,14 @@ func AddVersion_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) error {
 		`, file.Content.Module.ReleaseBuild, moduleId); err != nil {
 			return err
 		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE app.release_migration
+			SET build = $1
+			WHERE module_id = $2
+			AND   build     = 0
+		`, file.Content.Module.ReleaseBuild, moduleId); err != nil {
+			return err
+		}
 	}
 
 	_, err = tx.Exec(ctx, `
@@ -161,6 +171,25 @@ func verifyCompatibilityWithApp(releaseBuildApp int) error {
 	return nil
 }
 
+// verifies that modules to import only depend on modules that are installed or imported as well
+func verifyDependencies(moduleIdMapImportMeta map[uuid.UUID]importMeta) error {
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	for _, meta := range moduleIdMapImportMeta {
+		for _, id := range meta.module.DependsOn {
+			_, isInstalled := cache.ModuleIdMap[id]
+			_, isImported := moduleIdMapImportMeta[id]
+
+			if !isInstalled && !isImported {
+				return fmt.Errorf("module '%s' depends on module %s, which is neither installed nor part of the import",
+					meta.module.Name, id)
+			}
+		}
+	}
+	return nil
+}
+
 // verifies that the raw content of JSON file matches given signature
 // verify raw content, as target JSON might have different structure (new elements due to schema change)
 // returns error if verification fails, also module hash
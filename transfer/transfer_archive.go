@@ -0,0 +1,356 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"r3/cache"
+	"r3/config"
+	"r3/config/module_meta"
+	"r3/db"
+	"r3/log"
+	"r3/tools"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// installed module versions are archived before being replaced by an import
+// archived versions can be used to roll back modules, rollbacks only apply the archived schema:
+//  records are kept, except for data in entities that do not exist in the archived version
+
+func ArchiveDel_tx(ctx context.Context, tx pgx.Tx, id int64) error {
+	var fileName string
+	if err := tx.QueryRow(ctx, `
+		DELETE FROM instance.module_archive
+		WHERE id = $1
+		RETURNING file_name
+	`, id).Scan(&fileName); err != nil {
+		return err
+	}
+	return removeArchiveFile(fileName)
+}
+
+func ArchiveGet_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.ModuleArchive, error) {
+	archives := make([]types.ModuleArchive, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, module_id, release_build, release_build_app,
+			release_build_replaced_by, date_created
+		FROM instance.module_archive
+		WHERE module_id = $1
+		ORDER BY date_created DESC, id DESC
+	`, moduleId)
+	if err != nil {
+		return archives, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a types.ModuleArchive
+		if err := rows.Scan(&a.Id, &a.ModuleId, &a.ReleaseBuild, &a.ReleaseBuildApp,
+			&a.ReleaseBuildReplacedBy, &a.DateCreated); err != nil {
+
+			return archives, err
+		}
+		archives = append(archives, a)
+	}
+	return archives, nil
+}
+
+// rolls module back to archived version
+// modules that depend on it and were changed since the archive was created are rolled back to their versions from that time
+// dry-run returns changes without applying them
+func RollbackFromArchive(ctx context.Context, archiveId int64, dryRun bool) (types.TransferDryRun, error) {
+
+	var res types.TransferDryRun
+	res.Modules = make([]types.TransferDiff, 0)
+
+	import_mx.Lock()
+	defer import_mx.Unlock()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback(ctx)
+
+	var moduleId uuid.UUID
+	var dateCreated int64
+	if err := tx.QueryRow(ctx, `
+		SELECT module_id, date_created
+		FROM instance.module_archive
+		WHERE id = $1
+	`, archiveId).Scan(&moduleId, &dateCreated); err != nil {
+		return res, err
+	}
+
+	// collect archives to roll back to, starting with requested module
+	archiveIds := []int64{archiveId}
+	for _, id := range getModuleIdsDependent(moduleId) {
+
+		// dependent module was changed since archive was created, use its earliest version since then
+		var archiveIdDependent int64
+		err := tx.QueryRow(ctx, `
+			SELECT id
+			FROM instance.module_archive
+			WHERE module_id     = $1
+			AND   date_created >= $2
+			ORDER BY date_created ASC, id ASC
+			LIMIT 1
+		`, id, dateCreated).Scan(&archiveIdDependent)
+
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return res, err
+		}
+		archiveIds = append(archiveIds, archiveIdDependent)
+	}
+
+	// read & verify archived module versions
+	moduleIdMapImportMeta := make(map[uuid.UUID]importMeta)
+	for _, id := range archiveIds {
+		var fileName, hash string
+		if err := tx.QueryRow(ctx, `
+			SELECT file_name, hash
+			FROM instance.module_archive
+			WHERE id = $1
+		`, id).Scan(&fileName, &hash); err != nil {
+			return res, err
+		}
+
+		jsonFileData, err := os.ReadFile(filepath.Join(getArchivePath(), fileName))
+		if err != nil {
+			return res, err
+		}
+
+		// archives are created locally, their content hash replaces the signature check
+		hashArchive, err := getContentHash(jsonFileData)
+		if err != nil {
+			return res, err
+		}
+		if hashArchive != hash {
+			return res, fmt.Errorf("archive file '%s' does not match its stored hash", fileName)
+		}
+
+		var fileData types.TransferFile
+		if err := json.Unmarshal(jsonFileData, &fileData); err != nil {
+			return res, err
+		}
+
+		log.Info(log.ContextTransfer, fmt.Sprintf("rollback will install module '%s' v%d from archive",
+			fileData.Content.Module.Name, fileData.Content.Module.ReleaseBuild))
+
+		// archive file is kept, import moves a copy to the transfer path
+		filePathCopy, err := tools.GetUniqueFilePath(config.File.Paths.Temp, 8999999, 9999999)
+		if err != nil {
+			return res, err
+		}
+		if err := tools.FileCopy(filepath.Join(getArchivePath(), fileName), filePathCopy, false); err != nil {
+			return res, err
+		}
+		defer os.Remove(filePathCopy)
+
+		moduleIdMapImportMeta[fileData.Content.Module.Id] = importMeta{
+			filePath: filePathCopy,
+			hash:     hash,
+			isNew:    false,
+			module:   fileData.Content.Module,
+		}
+	}
+
+	// current versions are archived as well, rollbacks can be undone
+	return importExecute_tx(ctx, tx, getModulesInImportOrder(moduleIdMapImportMeta), moduleIdMapImportMeta, dryRun)
+}
+
+// stores installed versions of modules that are about to be replaced
+// the original transfer file is used if it matches the installed version, to keep its signature for exports
+// returns paths of written archive files
+func archiveModules_tx(ctx context.Context, tx pgx.Tx, moduleIdMapImportMeta map[uuid.UUID]importMeta) ([]string, error) {
+
+	filePaths := make([]string, 0)
+	dateCreated := tools.GetTimeUnix()
+
+	// archive is disabled
+	if config.GetUint64("moduleArchiveKeepCount") == 0 {
+		return filePaths, nil
+	}
+
+	if err := os.MkdirAll(getArchivePath(), 0700); err != nil {
+		return filePaths, err
+	}
+
+	for moduleId, meta := range moduleIdMapImportMeta {
+		if meta.isNew {
+			continue
+		}
+
+		cache.Schema_mx.RLock()
+		mod, exists := cache.ModuleIdMap[moduleId]
+		cache.Schema_mx.RUnlock()
+
+		if !exists {
+			continue
+		}
+
+		hashInstalled, err := module_meta.GetHash_tx(ctx, tx, moduleId)
+		if err != nil {
+			return filePaths, err
+		}
+
+		var hash string
+		jsonFileData, err := os.ReadFile(filepath.Join(config.File.Paths.Transfer, getModuleFilename(moduleId)))
+		if err == nil {
+			hash, err = getContentHash(jsonFileData)
+			if err != nil || hash != hashInstalled {
+				jsonFileData = nil
+			}
+		} else {
+			jsonFileData = nil
+		}
+
+		// no matching transfer file, archive installed version from schema
+		if jsonFileData == nil {
+			var file types.TransferFile
+			file.Content.Module = mod
+
+			jsonFileData, err = json.Marshal(file)
+			if err != nil {
+				return filePaths, err
+			}
+			hash, err = getContentHash(jsonFileData)
+			if err != nil {
+				return filePaths, err
+			}
+		}
+
+		fileName := fmt.Sprintf("%s_%d_%d.json", moduleId, mod.ReleaseBuild, tools.GetTimeUnixMilli())
+		filePath := filepath.Join(getArchivePath(), fileName)
+		if err := os.WriteFile(filePath, jsonFileData, 0600); err != nil {
+			return filePaths, err
+		}
+		filePaths = append(filePaths, filePath)
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.module_archive (module_id, release_build,
+				release_build_app, release_build_replaced_by, hash,
+				file_name, date_created)
+			VALUES ($1,$2,$3,$4,$5,$6,$7)
+		`, moduleId, mod.ReleaseBuild, mod.ReleaseBuildApp, meta.module.ReleaseBuild,
+			hash, fileName, dateCreated); err != nil {
+
+			return filePaths, err
+		}
+		log.Info(log.ContextTransfer, fmt.Sprintf("archived module '%s' v%d before import",
+			mod.Name, mod.ReleaseBuild))
+	}
+	return filePaths, nil
+}
+
+// deletes the oldest archived versions of each module above the configured count
+// archived versions of deleted modules are removed as well
+func archiveCleanup(ctx context.Context) error {
+	keepCount := config.GetUint64("moduleArchiveKeepCount")
+
+	rows, err := db.Pool.Query(ctx, `
+		DELETE FROM instance.module_archive
+		WHERE module_id NOT IN (
+			SELECT id
+			FROM app.module
+		)
+		OR id IN (
+			SELECT id
+			FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY module_id
+					ORDER BY date_created DESC, id DESC
+				) AS pos
+				FROM instance.module_archive
+			) AS a
+			WHERE a.pos > $1
+		)
+		RETURNING file_name
+	`, keepCount)
+	if err != nil {
+		return err
+	}
+	fileNames := make([]string, 0)
+	for rows.Next() {
+		var fileName string
+		if err := rows.Scan(&fileName); err != nil {
+			rows.Close()
+			return err
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	rows.Close()
+
+	for _, fileName := range fileNames {
+		if err := removeArchiveFile(fileName); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// returns IDs of all installed modules that depend on the given one, directly or indirectly
+func getModuleIdsDependent(moduleId uuid.UUID) []uuid.UUID {
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	moduleIds := make([]uuid.UUID, 0)
+
+	var addDependents func(id uuid.UUID)
+	addDependents = func(id uuid.UUID) {
+		for _, m := range cache.ModuleIdMap {
+			if m.Id == moduleId || slices.Contains(moduleIds, m.Id) || !slices.Contains(m.DependsOn, id) {
+				continue
+			}
+			moduleIds = append(moduleIds, m.Id)
+			addDependents(m.Id)
+		}
+	}
+	addDependents(moduleId)
+	return moduleIds
+}
+
+// returns the hash of the raw content of a module transfer file
+func getContentHash(jsonFileData []byte) (string, error) {
+	var verify types.TransferFileVerify
+	if err := json.Unmarshal(jsonFileData, &verify); err != nil {
+		return "", err
+	}
+	if len(verify.Content) == 0 {
+		return "", errors.New("module transfer file has no content")
+	}
+	hashed := sha256.Sum256(verify.Content)
+	return base64.URLEncoding.EncodeToString(hashed[:]), nil
+}
+
+func getArchivePath() string {
+	return filepath.Join(config.File.Paths.Transfer, "archive")
+}
+
+func removeArchiveFile(fileName string) error {
+	if err := os.Remove(filepath.Join(getArchivePath(), fileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func removeFiles(filePaths []string) {
+	for _, filePath := range filePaths {
+		if err := os.Remove(filePath); err != nil {
+			log.Warning(log.ContextTransfer, "failed to remove file", err)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"r3/schema/jsFunction"
 	"r3/schema/loginForm"
 	"r3/schema/menuTab"
+	"r3/schema/numberRange"
 	"r3/schema/pgFunction"
 	"r3/schema/pgIndex"
 	"r3/schema/pgTrigger"
@@ -119,6 +120,11 @@ func NotExisting_tx(ctx context.Context, tx pgx.Tx, module types.Module) error {
 		return err
 	}
 
+	// number ranges
+	if err := deleteNumberRanges_tx(ctx, tx, module.Id, module.NumberRanges); err != nil {
+		return err
+	}
+
 	// variables
 	if err := deleteVariables_tx(ctx, tx, module.Id, module.Variables); err != nil {
 		return err
@@ -573,6 +579,23 @@ func deleteSearchBars_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, bar
 	}
 	return nil
 }
+func deleteNumberRanges_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, numberRanges []types.NumberRange) error {
+	idsKeep := make([]uuid.UUID, 0)
+	for _, entity := range numberRanges {
+		idsKeep = append(idsKeep, entity.Id)
+	}
+	idsDelete, err := importGetIdsToDeleteFromModule_tx(ctx, tx, schema.DbNumberRange, moduleId, idsKeep)
+	if err != nil {
+		return err
+	}
+	for _, id := range idsDelete {
+		log.Info(log.ContextTransfer, fmt.Sprintf("del number range %s", id.String()))
+		if err := numberRange.Del_tx(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 func deleteVariables_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, variables []types.Variable) error {
 	idsKeep := make([]uuid.UUID, 0)
 	for _, entity := range variables {
@@ -0,0 +1,418 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/cache"
+	"r3/schema"
+	"r3/types"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type diffEntity struct {
+	id    uuid.UUID
+	name  string
+	value any // entity to compare
+}
+
+// returns changes that importing the given module would apply, compared to the installed module in the schema cache
+// must run before the import is applied, as affected records are counted in the existing data
+func getModuleDiff_tx(ctx context.Context, tx pgx.Tx, modNew types.Module) (types.TransferDiff, error) {
+
+	cache.Schema_mx.RLock()
+	modOld, exists := cache.ModuleIdMap[modNew.Id]
+	cache.Schema_mx.RUnlock()
+
+	diff := types.TransferDiff{
+		ModuleId:       modNew.Id,
+		ModuleName:     modNew.Name,
+		IsNew:          !exists,
+		ReleaseBuildTo: modNew.ReleaseBuild,
+		Changes:        make([]types.TransferDiffChange, 0),
+		Migrations:     make([]string, 0),
+	}
+	if exists {
+		diff.ReleaseBuildFrom = modOld.ReleaseBuild
+
+		migrations, err := getMigrationsPending_tx(ctx, tx, modNew, modOld.ReleaseBuild)
+		if err != nil {
+			return diff, err
+		}
+		for _, m := range migrations {
+			diff.Migrations = append(diff.Migrations, fmt.Sprintf("v%d: %s", m.build, m.pgFunctionName))
+		}
+	}
+
+	// lookups by ID for old & new states
+	relIdMapOld := make(map[uuid.UUID]types.Relation)
+	relIdMapNew := make(map[uuid.UUID]types.Relation)
+	atrIdMapOld := make(map[uuid.UUID]types.Attribute)
+	atrIdMapNew := make(map[uuid.UUID]types.Attribute)
+	idxIdMapOld := make(map[uuid.UUID]types.PgIndex)
+	idxIdMapNew := make(map[uuid.UUID]types.PgIndex)
+	fncIdMapNames := make(map[uuid.UUID]string)
+
+	for _, r := range modOld.Relations {
+		relIdMapOld[r.Id] = r
+		for _, a := range r.Attributes {
+			atrIdMapOld[a.Id] = a
+		}
+		for _, i := range r.Indexes {
+			idxIdMapOld[i.Id] = i
+		}
+	}
+	for _, r := range modNew.Relations {
+		relIdMapNew[r.Id] = r
+		for _, a := range r.Attributes {
+			atrIdMapNew[a.Id] = a
+		}
+		for _, i := range r.Indexes {
+			idxIdMapNew[i.Id] = i
+		}
+	}
+	for _, f := range modOld.PgFunctions {
+		fncIdMapNames[f.Id] = f.Name
+	}
+	for _, f := range modNew.PgFunctions {
+		fncIdMapNames[f.Id] = f.Name
+	}
+
+	// entities are compared without their sub entities, which are compared separately
+	getRelations := func(relations []types.Relation) []diffEntity {
+		out := make([]diffEntity, 0)
+		for _, r := range relations {
+			r.Attributes = nil
+			r.Indexes = nil
+			r.Presets = nil
+			r.Triggers = nil
+			out = append(out, diffEntity{r.Id, r.Name, r})
+		}
+		return out
+	}
+	getAttributes := func(relations []types.Relation) []diffEntity {
+		out := make([]diffEntity, 0)
+		for _, r := range relations {
+			for _, a := range r.Attributes {
+				out = append(out, diffEntity{a.Id, fmt.Sprintf("%s.%s", r.Name, a.Name), a})
+			}
+		}
+		return out
+	}
+	getIndexes := func(relations []types.Relation, atrIdMap map[uuid.UUID]types.Attribute) []diffEntity {
+		out := make([]diffEntity, 0)
+		for _, r := range relations {
+			for _, i := range r.Indexes {
+				names := make([]string, 0)
+				for _, ia := range i.Attributes {
+					names = append(names, atrIdMap[ia.AttributeId].Name)
+				}
+				out = append(out, diffEntity{i.Id, fmt.Sprintf("%s(%s)", r.Name, strings.Join(names, ", ")), i})
+			}
+		}
+		return out
+	}
+	getTriggers := func(triggers []types.PgTrigger, relIdMap map[uuid.UUID]types.Relation) []diffEntity {
+		out := make([]diffEntity, 0)
+		for _, t := range triggers {
+			out = append(out, diffEntity{t.Id, fmt.Sprintf("%s -> %s()",
+				relIdMap[t.RelationId].Name, fncIdMapNames[t.PgFunctionId]), t})
+		}
+		return out
+	}
+	getFunctions := func(functions []types.PgFunction) []diffEntity {
+		out := make([]diffEntity, 0)
+		for _, f := range functions {
+			out = append(out, diffEntity{f.Id, f.Name, f})
+		}
+		return out
+	}
+	getForms := func(forms []types.Form) []diffEntity {
+		out := make([]diffEntity, 0)
+		for _, f := range forms {
+			out = append(out, diffEntity{f.Id, f.Name, f})
+		}
+		return out
+	}
+	getRoles := func(roles []types.Role) []diffEntity {
+		out := make([]diffEntity, 0)
+		for _, r := range roles {
+			out = append(out, diffEntity{r.Id, r.Name, r})
+		}
+		return out
+	}
+
+	for _, e := range []struct {
+		name string
+		old  []diffEntity
+		new  []diffEntity
+	}{
+		{"relation", getRelations(modOld.Relations), getRelations(modNew.Relations)},
+		{"attribute", getAttributes(modOld.Relations), getAttributes(modNew.Relations)},
+		{"pgIndex", getIndexes(modOld.Relations, atrIdMapOld), getIndexes(modNew.Relations, atrIdMapNew)},
+		{"pgFunction", getFunctions(modOld.PgFunctions), getFunctions(modNew.PgFunctions)},
+		{"pgTrigger", getTriggers(modOld.PgTriggers, relIdMapOld), getTriggers(modNew.PgTriggers, relIdMapNew)},
+		{"form", getForms(modOld.Forms), getForms(modNew.Forms)},
+		{"role", getRoles(modOld.Roles), getRoles(modNew.Roles)},
+	} {
+		changes, err := getDiffChanges(e.name, e.old, e.new)
+		if err != nil {
+			return diff, err
+		}
+
+		for _, c := range changes {
+			skip := false
+			switch c.Entity {
+			case "attribute":
+				skip, err = setDiffAttribute_tx(ctx, tx, &c, modOld.Name, relIdMapOld, relIdMapNew, atrIdMapOld, atrIdMapNew)
+			case "pgIndex":
+				skip = setDiffIndex(&c, relIdMapNew, idxIdMapOld, idxIdMapNew)
+			case "relation":
+				err = setDiffRelation_tx(ctx, tx, &c, modOld.Name, relIdMapOld)
+			case "role":
+				err = setDiffRole_tx(ctx, tx, &c)
+			}
+			if err != nil {
+				return diff, err
+			}
+			if !skip {
+				diff.Changes = append(diff.Changes, c)
+			}
+		}
+	}
+	return diff, nil
+}
+
+// relations: dropping a relation deletes all its records
+func setDiffRelation_tx(ctx context.Context, tx pgx.Tx, c *types.TransferDiffChange,
+	modNameOld string, relIdMapOld map[uuid.UUID]types.Relation) error {
+
+	if c.Action != "removed" {
+		return nil
+	}
+	c.Destructive = append(c.Destructive, "dropped")
+	return tx.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM "%s"."%s"`,
+		modNameOld, relIdMapOld[c.EntityId].Name)).Scan(&c.RowsAffected)
+}
+
+// attributes: dropping, changing type, reducing length & adding NOT NULL affect existing values
+// attributes of dropped relations are skipped, as the relation change covers them
+func setDiffAttribute_tx(ctx context.Context, tx pgx.Tx, c *types.TransferDiffChange, modNameOld string,
+	relIdMapOld map[uuid.UUID]types.Relation, relIdMapNew map[uuid.UUID]types.Relation,
+	atrIdMapOld map[uuid.UUID]types.Attribute, atrIdMapNew map[uuid.UUID]types.Attribute) (bool, error) {
+
+	atrOld, existsOld := atrIdMapOld[c.EntityId]
+	atrNew := atrIdMapNew[c.EntityId]
+
+	if existsOld {
+		if _, exists := relIdMapNew[atrOld.RelationId]; !exists {
+			return true, nil
+		}
+	}
+
+	// conditions to count affected records with, in existing relation
+	conditions := make([]string, 0)
+	switch c.Action {
+	case "added":
+		relOld, exists := relIdMapOld[atrNew.RelationId]
+		if !exists || atrNew.Nullable || atrNew.Def != "" || schema.IsContentFiles(atrNew.Content) {
+			return false, nil
+		}
+
+		// new NOT NULL attribute without default value on relation with existing records
+		c.Destructive = append(c.Destructive, "notNullAdded")
+		return false, tx.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM "%s"."%s"`,
+			modNameOld, relOld.Name)).Scan(&c.RowsAffected)
+
+	case "removed":
+		c.Destructive = append(c.Destructive, "dropped")
+		conditions = append(conditions, `"%s" IS NOT NULL`)
+
+	case "changed":
+		if atrOld.Content != atrNew.Content {
+			c.Destructive = append(c.Destructive, "typeChanged")
+			conditions = append(conditions, `"%s" IS NOT NULL`)
+		} else if atrNew.Length != 0 && (atrOld.Length == 0 || atrNew.Length < atrOld.Length) {
+			if atrNew.Content == "varchar" {
+				c.Destructive = append(c.Destructive, "lengthReduced")
+				conditions = append(conditions, fmt.Sprintf(`CHAR_LENGTH("%%s") > %d`, atrNew.Length))
+			}
+			if schema.IsContentNumeric(atrNew.Content) {
+				c.Destructive = append(c.Destructive, "lengthReduced")
+				conditions = append(conditions, `"%s" IS NOT NULL`)
+			}
+		}
+		if atrOld.Nullable && !atrNew.Nullable {
+			c.Destructive = append(c.Destructive, "notNullAdded")
+			conditions = append(conditions, `"%s" IS NULL`)
+		}
+	}
+
+	// files attributes are not stored in relation columns
+	if len(conditions) == 0 || schema.IsContentFiles(atrOld.Content) {
+		return false, nil
+	}
+	relOld := relIdMapOld[atrOld.RelationId]
+
+	for i, cond := range conditions {
+		conditions[i] = fmt.Sprintf(cond, atrOld.Name)
+	}
+	return false, tx.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM "%s"."%s" WHERE %s`,
+		modNameOld, relOld.Name, strings.Join(conditions, " OR "))).Scan(&c.RowsAffected)
+}
+
+// indexes: enforcing uniqueness fails if duplicate values exist
+// indexes of dropped relations & auto-generated indexes are skipped, as the relation/attribute changes cover them
+func setDiffIndex(c *types.TransferDiffChange, relIdMapNew map[uuid.UUID]types.Relation,
+	idxIdMapOld map[uuid.UUID]types.PgIndex, idxIdMapNew map[uuid.UUID]types.PgIndex) bool {
+
+	idxOld, existsOld := idxIdMapOld[c.EntityId]
+	idxNew, existsNew := idxIdMapNew[c.EntityId]
+
+	if existsOld {
+		if _, exists := relIdMapNew[idxOld.RelationId]; !exists || idxOld.AutoFki || idxOld.PrimaryKey {
+			return true
+		}
+	}
+	if existsNew && (idxNew.AutoFki || idxNew.PrimaryKey) {
+		return true
+	}
+
+	if existsNew && idxNew.NoDuplicates && (!existsOld || !idxOld.NoDuplicates) {
+		c.Destructive = append(c.Destructive, "uniqueAdded")
+	}
+	return false
+}
+
+// roles: dropping a role removes its memberships
+func setDiffRole_tx(ctx context.Context, tx pgx.Tx, c *types.TransferDiffChange) error {
+	if c.Action != "removed" {
+		return nil
+	}
+	c.Destructive = append(c.Destructive, "dropped")
+	return tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.login_role
+		WHERE role_id = $1
+	`, c.EntityId).Scan(&c.RowsAffected)
+}
+
+// returns added, changed & removed entities, in order of new entities followed by removed ones
+func getDiffChanges(entity string, entitiesOld []diffEntity, entitiesNew []diffEntity) ([]types.TransferDiffChange, error) {
+	changes := make([]types.TransferDiffChange, 0)
+
+	idMapOld := make(map[uuid.UUID]diffEntity)
+	for _, e := range entitiesOld {
+		idMapOld[e.id] = e
+	}
+	idsNew := make([]uuid.UUID, 0)
+
+	for _, eNew := range entitiesNew {
+		idsNew = append(idsNew, eNew.id)
+
+		c := types.TransferDiffChange{
+			Entity:      entity,
+			EntityId:    eNew.id,
+			Name:        eNew.name,
+			Details:     make([]string, 0),
+			Destructive: make([]string, 0),
+		}
+
+		eOld, exists := idMapOld[eNew.id]
+		if !exists {
+			c.Action = "added"
+			changes = append(changes, c)
+			continue
+		}
+
+		properties, err := getDiffProperties(eOld.value, eNew.value)
+		if err != nil {
+			return changes, err
+		}
+		if len(properties) == 0 {
+			continue
+		}
+		c.Action = "changed"
+		c.Details = properties
+		changes = append(changes, c)
+	}
+
+	for _, eOld := range entitiesOld {
+		if slices.Contains(idsNew, eOld.id) {
+			continue
+		}
+		changes = append(changes, types.TransferDiffChange{
+			Entity:      entity,
+			EntityId:    eOld.id,
+			Name:        eOld.name,
+			Action:      "removed",
+			Details:     make([]string, 0),
+			Destructive: make([]string, 0),
+		})
+	}
+	return changes, nil
+}
+
+// returns names of top level properties that differ between both entities, sorted by name
+// empty values (null, empty lists & objects) are treated as equal
+func getDiffProperties(entityOld any, entityNew any) ([]string, error) {
+	properties := make([]string, 0)
+
+	var mapOld, mapNew map[string]any
+	for _, v := range []struct {
+		entity any
+		target *map[string]any
+	}{{entityOld, &mapOld}, {entityNew, &mapNew}} {
+		j, err := json.Marshal(v.entity)
+		if err != nil {
+			return properties, err
+		}
+		if err := json.Unmarshal(j, v.target); err != nil {
+			return properties, err
+		}
+	}
+
+	for k := range mapOld {
+		if _, exists := mapNew[k]; !exists {
+			mapNew[k] = nil
+		}
+	}
+	for k, vNew := range mapNew {
+		if !reflect.DeepEqual(getDiffValueNormalized(mapOld[k]), getDiffValueNormalized(vNew)) {
+			properties = append(properties, k)
+		}
+	}
+	slices.Sort(properties)
+	return properties, nil
+}
+
+func getDiffValueNormalized(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any)
+		for k, e := range t {
+			if n := getDiffValueNormalized(e); n != nil {
+				out[k] = n
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []any:
+		if len(t) == 0 {
+			return nil
+		}
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = getDiffValueNormalized(e)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,656 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/tools"
+	"r3/types"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// modules can be exported as directory trees, to be stored & compared in version control systems
+// directory layout:
+//  module.json              module without sub entities, with file names of sub entities in order
+//  <entity>/<name>.json     one file per sub entity (relations, forms, roles, ...)
+//  pgFunctions/<name>.sql   function body of backend function
+//  jsFunctions/<name>.js    function body of frontend function
+//  captions/<language>.json captions of all entities for one language
+// directory exports are not signed, imports are limited to full admins on instances in builder mode
+// otherwise they apply the same compatibility & dependency checks as regular imports
+
+const (
+	dirFileKeyModule = "module" // caption file key for module itself
+	dirFileModule    = "module.json"
+	dirPathCaptions  = "captions"
+)
+
+var (
+	dirCodeFileExt   = map[string]string{"jsFunctions": ".js", "pgFunctions": ".sql"}
+	dirFileNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_\-\.]`)
+)
+
+type dirModule struct {
+	Module map[string]any      `json:"module"`
+	Files  map[string][]string `json:"files"` // sub entity file names by entity key, in order
+}
+
+// captions of one language: file key -> object path -> content -> value
+type dirCaptions map[string]map[string]map[string]string
+
+// writes module as directory tree into given directory
+// files from previous exports are replaced, other files (like version control data) are kept
+func ExportToDirectory(ctx context.Context, moduleId uuid.UUID, dirPath string) error {
+
+	cache.Schema_mx.RLock()
+	mod, exists := cache.ModuleIdMap[moduleId]
+	cache.Schema_mx.RUnlock()
+
+	if !exists {
+		return handler.ErrSchemaUnknownModule(moduleId)
+	}
+
+	log.Info(log.ContextTransfer, fmt.Sprintf("start directory export for module '%s' v%d", mod.Name, mod.ReleaseBuild))
+
+	var dm dirModule
+	if err := jsonToObject(mod, &dm.Module); err != nil {
+		return err
+	}
+	dm.Files = make(map[string][]string)
+
+	// move sub entities to their own files
+	fileKeyMapEntity := make(map[string]map[string]any)
+	keysList := make([]string, 0) // keys of all entity lists, including empty ones
+	for key, value := range dm.Module {
+		list, isList := value.([]any)
+		if !isList {
+			continue
+		}
+		keysList = append(keysList, key)
+
+		if !isDirEntityList(list) {
+			continue
+		}
+
+		namesLower := make(map[string]bool)
+		for _, e := range list {
+			entity := e.(map[string]any)
+			name := getDirFileName(entity, namesLower)
+
+			dm.Files[key] = append(dm.Files[key], name)
+			fileKeyMapEntity[fmt.Sprintf("%s/%s", key, name)] = entity
+		}
+		delete(dm.Module, key)
+	}
+
+	// move captions to language files
+	langMapCaptions := make(map[string]dirCaptions)
+	extractDirCaptions(dm.Module, dirFileKeyModule, "", langMapCaptions)
+	for fileKey, entity := range fileKeyMapEntity {
+		extractDirCaptions(entity, fileKey, "", langMapCaptions)
+	}
+
+	// remove files from previous export
+	for _, name := range append(keysList, dirPathCaptions, dirFileModule) {
+		if err := os.RemoveAll(filepath.Join(dirPath, name)); err != nil {
+			return err
+		}
+	}
+
+	// write files
+	if err := os.MkdirAll(dirPath, 0700); err != nil {
+		return err
+	}
+	if err := writeDirJson(filepath.Join(dirPath, dirFileModule), dm); err != nil {
+		return err
+	}
+	for key := range dm.Files {
+		if err := os.MkdirAll(filepath.Join(dirPath, key), 0700); err != nil {
+			return err
+		}
+	}
+	for fileKey, entity := range fileKeyMapEntity {
+		key, name, _ := strings.Cut(fileKey, "/")
+
+		// move function body to code file
+		if ext, exists := dirCodeFileExt[key]; exists {
+			if code, isString := entity["codeFunction"].(string); isString {
+				if err := os.WriteFile(filepath.Join(dirPath, key, name+ext), []byte(code), 0600); err != nil {
+					return err
+				}
+				delete(entity, "codeFunction")
+			}
+		}
+		if err := writeDirJson(filepath.Join(dirPath, key, name+".json"), entity); err != nil {
+			return err
+		}
+	}
+	if len(langMapCaptions) != 0 {
+		if err := os.MkdirAll(filepath.Join(dirPath, dirPathCaptions), 0700); err != nil {
+			return err
+		}
+	}
+	for lang, captions := range langMapCaptions {
+		if err := writeDirJson(filepath.Join(dirPath, dirPathCaptions, lang+".json"), captions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exports module as directory tree, packaged as compressed file
+func ExportToDirectoryZip(ctx context.Context, moduleId uuid.UUID, zipFilePath string) error {
+
+	dirPath, err := os.MkdirTemp(config.File.Paths.Temp, "export_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dirPath)
+
+	if err := ExportToDirectory(ctx, moduleId, dirPath); err != nil {
+		return err
+	}
+	return writeDirToZip(zipFilePath, dirPath)
+}
+
+// imports module from directory tree
+// directory trees are not signed, they can only be imported on instances in builder mode
+// dry-run returns changes without applying them
+func ImportFromDirectory(ctx context.Context, dirPath string, dryRun bool) (types.TransferDryRun, error) {
+
+	var res types.TransferDryRun
+	res.Modules = make([]types.TransferDiff, 0)
+
+	if config.GetUint64("builderMode") != 1 {
+		return res, errors.New("directory imports are only allowed in builder mode, as they are not signed")
+	}
+
+	import_mx.Lock()
+	defer import_mx.Unlock()
+
+	log.Info(log.ContextTransfer, fmt.Sprintf("start import for module from directory '%s' (dry-run: %v)", dirPath, dryRun))
+
+	var file types.TransferFile
+	var err error
+	file.Content.Module, err = readDirModule(dirPath)
+	if err != nil {
+		return res, err
+	}
+
+	hashedStr, err := getModuleHashFromFile(file)
+	if err != nil {
+		return res, err
+	}
+
+	// store assembled module as transfer file, it is moved to the transfer path for future exports
+	filePath, err := tools.GetUniqueFilePath(config.File.Paths.Temp, 8999999, 9999999)
+	if err != nil {
+		return res, err
+	}
+	jsonFile, err := json.Marshal(file)
+	if err != nil {
+		return res, err
+	}
+	if err := os.WriteFile(filePath, jsonFile, 0600); err != nil {
+		return res, err
+	}
+	defer os.Remove(filePath)
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback(ctx)
+
+	moduleIdMapImportMeta := make(map[uuid.UUID]importMeta)
+	if err := addModuleForImport_tx(ctx, tx, file.Content.Module, hashedStr, filePath, moduleIdMapImportMeta); err != nil {
+		return res, err
+	}
+	if err := verifyDependencies(moduleIdMapImportMeta); err != nil {
+		return res, err
+	}
+	return importExecute_tx(ctx, tx, getModulesInImportOrder(moduleIdMapImportMeta), moduleIdMapImportMeta, dryRun)
+}
+
+// imports module from directory tree, packaged as compressed file
+// the directory tree can be placed at the root of the package or inside a single directory
+func ImportFromDirectoryZip(ctx context.Context, zipFilePath string, dryRun bool) (types.TransferDryRun, error) {
+
+	dirPath, err := os.MkdirTemp(config.File.Paths.Temp, "import_")
+	if err != nil {
+		return types.TransferDryRun{}, err
+	}
+	defer os.RemoveAll(dirPath)
+
+	if err := writeDirFromZip(zipFilePath, dirPath); err != nil {
+		return types.TransferDryRun{}, err
+	}
+
+	// find module file with the shortest path
+	dirPathModule := ""
+	if err := filepath.WalkDir(dirPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != dirFileModule {
+			return nil
+		}
+		if dirPathModule == "" || len(filepath.Dir(path)) < len(dirPathModule) {
+			dirPathModule = filepath.Dir(path)
+		}
+		return nil
+	}); err != nil {
+		return types.TransferDryRun{}, err
+	}
+
+	if dirPathModule == "" {
+		return types.TransferDryRun{}, fmt.Errorf("package does not contain a module directory export, '%s' is missing", dirFileModule)
+	}
+	return ImportFromDirectory(ctx, dirPathModule, dryRun)
+}
+
+// reads module from directory tree
+func readDirModule(dirPath string) (types.Module, error) {
+
+	var mod types.Module
+	var dm dirModule
+	if err := readDirJson(filepath.Join(dirPath, dirFileModule), &dm); err != nil {
+		return mod, err
+	}
+	if dm.Module == nil {
+		return mod, fmt.Errorf("invalid directory export, '%s' does not contain a module", dirFileModule)
+	}
+
+	// read sub entities from their files
+	fileKeyMapEntity := make(map[string]map[string]any)
+	for key, names := range dm.Files {
+		if !isDirEntityKey(key) {
+			return mod, fmt.Errorf("invalid directory export, '%s' references unknown entity type '%s'", dirFileModule, key)
+		}
+		list := make([]any, 0, len(names))
+		for _, name := range names {
+			if !isDirFileName(name) {
+				return mod, fmt.Errorf("invalid directory export, '%s' references invalid file name '%s'", dirFileModule, name)
+			}
+			var entity map[string]any
+			if err := readDirJson(filepath.Join(dirPath, key, name+".json"), &entity); err != nil {
+				return mod, err
+			}
+
+			if ext, exists := dirCodeFileExt[key]; exists {
+				code, err := os.ReadFile(filepath.Join(dirPath, key, name+ext))
+				if err != nil && !os.IsNotExist(err) {
+					return mod, err
+				}
+				if err == nil {
+					entity["codeFunction"] = string(code)
+				}
+			}
+			fileKeyMapEntity[fmt.Sprintf("%s/%s", key, name)] = entity
+			list = append(list, entity)
+		}
+		dm.Module[key] = list
+	}
+
+	// read captions from language files
+	entries, err := os.ReadDir(filepath.Join(dirPath, dirPathCaptions))
+	if err != nil && !os.IsNotExist(err) {
+		return mod, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		lang := strings.TrimSuffix(entry.Name(), ".json")
+
+		var captions dirCaptions
+		if err := readDirJson(filepath.Join(dirPath, dirPathCaptions, entry.Name()), &captions); err != nil {
+			return mod, err
+		}
+
+		for fileKey, pathMap := range captions {
+			root := dm.Module
+			if fileKey != dirFileKeyModule {
+				entity, exists := fileKeyMapEntity[fileKey]
+				if !exists {
+					return mod, fmt.Errorf("captions for language '%s' reference unknown file '%s'", lang, fileKey)
+				}
+				root = entity
+			}
+			for path, contentMap := range pathMap {
+				if err := injectDirCaptions(root, path, lang, contentMap); err != nil {
+					return mod, fmt.Errorf("captions for language '%s' in file '%s': %w", lang, fileKey, err)
+				}
+			}
+		}
+	}
+
+	// convert to module
+	moduleJson, err := json.Marshal(dm.Module)
+	if err != nil {
+		return mod, err
+	}
+	if err := json.Unmarshal(moduleJson, &mod); err != nil {
+		return mod, err
+	}
+	if mod.Id.IsNil() {
+		return mod, fmt.Errorf("invalid directory export, module has no ID")
+	}
+	return mod, nil
+}
+
+// moves captions from given JSON value to language files, object paths are built from keys & entity IDs
+func extractDirCaptions(v any, fileKey string, path string, langMapCaptions map[string]dirCaptions) {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, child := range t {
+			if key == "captions" && isDirCaptionMap(child) {
+				pathCaptions := path
+				if pathCaptions == "" {
+					pathCaptions = "/"
+				}
+				for content, langMapValue := range child.(map[string]any) {
+					for lang, value := range langMapValue.(map[string]any) {
+						if _, exists := langMapCaptions[lang]; !exists {
+							langMapCaptions[lang] = make(dirCaptions)
+						}
+						if _, exists := langMapCaptions[lang][fileKey]; !exists {
+							langMapCaptions[lang][fileKey] = make(map[string]map[string]string)
+						}
+						if _, exists := langMapCaptions[lang][fileKey][pathCaptions]; !exists {
+							langMapCaptions[lang][fileKey][pathCaptions] = make(map[string]string)
+						}
+						langMapCaptions[lang][fileKey][pathCaptions][content] = value.(string)
+					}
+				}
+				t[key] = make(map[string]any)
+				continue
+			}
+			extractDirCaptions(child, fileKey, fmt.Sprintf("%s/%s", path, key), langMapCaptions)
+		}
+	case []any:
+		for i, child := range t {
+			extractDirCaptions(child, fileKey, fmt.Sprintf("%s/%s", path, getDirElementKey(child, i)), langMapCaptions)
+		}
+	}
+}
+
+// adds captions of one language to the object at given path
+func injectDirCaptions(root map[string]any, path string, lang string, contentMap map[string]string) error {
+	var node any = root
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		switch t := node.(type) {
+		case map[string]any:
+			child, exists := t[seg]
+			if !exists {
+				return fmt.Errorf("unknown path '%s'", path)
+			}
+			node = child
+		case []any:
+			found := false
+			for i, child := range t {
+				if getDirElementKey(child, i) == seg {
+					node = child
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("unknown path '%s'", path)
+			}
+		default:
+			return fmt.Errorf("unknown path '%s'", path)
+		}
+	}
+
+	obj, isObj := node.(map[string]any)
+	if !isObj {
+		return fmt.Errorf("path '%s' does not refer to an object", path)
+	}
+	captions, isObj := obj["captions"].(map[string]any)
+	if !isObj {
+		captions = make(map[string]any)
+		obj["captions"] = captions
+	}
+	for content, value := range contentMap {
+		langMapValue, isObj := captions[content].(map[string]any)
+		if !isObj {
+			langMapValue = make(map[string]any)
+			captions[content] = langMapValue
+		}
+		langMapValue[lang] = value
+	}
+	return nil
+}
+
+// returns unique, file system safe name for entity file, based on entity name or ID
+func getDirFileName(entity map[string]any, namesLower map[string]bool) string {
+	id, _ := entity["id"].(string)
+	name, _ := entity["name"].(string)
+	name = dirFileNameRegex.ReplaceAllString(name, "_")
+
+	if name == "" || strings.HasPrefix(name, ".") {
+		name = id
+	}
+	if namesLower[strings.ToLower(name)] {
+		name = fmt.Sprintf("%s_%s", name, id)
+	}
+	namesLower[strings.ToLower(name)] = true
+	return name
+}
+
+// returns key of array element for object paths, entity ID if available, otherwise its index
+func getDirElementKey(v any, index int) string {
+	if obj, isObj := v.(map[string]any); isObj {
+		if id, isString := obj["id"].(string); isString && id != "" {
+			return id
+		}
+	}
+	return strconv.Itoa(index)
+}
+
+// key of sub entity list of module (relations, forms, roles, ...)
+func isDirEntityKey(key string) bool {
+	t := reflect.TypeOf(types.Module{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct &&
+			strings.Split(f.Tag.Get("json"), ",")[0] == key {
+
+			return true
+		}
+	}
+	return false
+}
+
+// file name as created by directory export, without path separators or references to parent directories
+func isDirFileName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.Contains(name, "..") &&
+		!dirFileNameRegex.MatchString(name)
+}
+
+// list of entities, with each having an ID
+func isDirEntityList(list []any) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, e := range list {
+		obj, isObj := e.(map[string]any)
+		if !isObj {
+			return false
+		}
+		if id, isString := obj["id"].(string); !isString || id == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// caption map: content -> language code -> value
+func isDirCaptionMap(v any) bool {
+	obj, isObj := v.(map[string]any)
+	if !isObj {
+		return false
+	}
+	for _, langMapValue := range obj {
+		langMap, isObj := langMapValue.(map[string]any)
+		if !isObj {
+			return false
+		}
+		for _, value := range langMap {
+			if _, isString := value.(string); !isString {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// converts value to generic JSON object, numbers are kept as is
+func jsonToObject(v any, obj *map[string]any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(obj)
+}
+
+func readDirJson(filePath string, v any) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse '%s', %w", filePath, err)
+	}
+	return nil
+}
+
+// writes indented JSON with sorted keys, for readable & stable diffs
+func writeDirJson(filePath string, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, buf.Bytes(), 0600)
+}
+
+func writeDirFromZip(zipFilePath string, dirPath string) error {
+	reader, err := zip.OpenReader(zipFilePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+
+		// only allow paths inside target directory
+		filePath := filepath.Join(dirPath, filepath.FromSlash(file.Name))
+		if !strings.HasPrefix(filePath, filepath.Clean(dirPath)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path in package: '%s'", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(filePath, 0700); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			return err
+		}
+
+		fileIn, err := file.Open()
+		if err != nil {
+			return err
+		}
+		fileOut, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			fileIn.Close()
+			return err
+		}
+		_, err = io.Copy(fileOut, fileIn)
+		fileIn.Close()
+		fileOut.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDirToZip(zipFilePath string, dirPath string) error {
+	zipFile, err := os.Create(zipFilePath)
+	if err != nil {
+		return err
+	}
+	defer zipFile.Close()
+
+	zipWriter := zip.NewWriter(zipFile)
+	defer zipWriter.Close()
+
+	filePaths := make([]string, 0)
+	if err := filepath.WalkDir(dirPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			filePaths = append(filePaths, path)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	slices.Sort(filePaths)
+
+	for _, filePath := range filePaths {
+		name, err := filepath.Rel(dirPath, filePath)
+		if err != nil {
+			return err
+		}
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:   filepath.ToSlash(name),
+			Method: zip.Deflate,
+		})
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"r3/config"
 	"r3/config/module_meta"
 	"r3/db"
+	"r3/handler"
 	"r3/log"
 	"r3/types"
 	"slices"
@@ -148,3 +149,78 @@ func export_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID, exportKey str
 	}
 	return os.WriteFile(filePath, jsonFile, 0644)
 }
+
+// export stored original files of a module and the modules it depends on as compressed file
+// original files keep their signatures, they are used when serving modules to other instances
+func ExportOriginalsToFile(ctx context.Context, moduleId uuid.UUID, zipFilePath string) error {
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	filePaths, err := GetOriginalFilePaths_tx(ctx, tx, moduleId)
+	if err != nil {
+		return err
+	}
+	return writeFilesToZip(zipFilePath, filePaths)
+}
+
+// returns paths of stored original files of a module and the modules it depends on
+// fails if any file is missing, not signed or does not match the installed version
+func GetOriginalFilePaths_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]string, error) {
+	filePaths := make([]string, 0)
+	moduleIdsChecked := make([]uuid.UUID, 0)
+
+	var addFilePath func(id uuid.UUID) error
+	addFilePath = func(id uuid.UUID) error {
+		if slices.Contains(moduleIdsChecked, id) {
+			return nil
+		}
+		moduleIdsChecked = append(moduleIdsChecked, id)
+
+		cache.Schema_mx.RLock()
+		mod, exists := cache.ModuleIdMap[id]
+		cache.Schema_mx.RUnlock()
+
+		if !exists {
+			return handler.ErrSchemaUnknownModule(id)
+		}
+
+		for _, idDependsOn := range mod.DependsOn {
+			if err := addFilePath(idDependsOn); err != nil {
+				return err
+			}
+		}
+
+		filePath := filepath.Join(config.File.Paths.Transfer, getModuleFilename(id))
+		jsonFileData, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("no original file for module '%s', %w", mod.Name, err)
+		}
+
+		var verify types.TransferFileVerify
+		if err := json.Unmarshal(jsonFileData, &verify); err != nil {
+			return err
+		}
+		if verify.Signature == "" {
+			return fmt.Errorf("original file for module '%s' is not signed", mod.Name)
+		}
+
+		hash, err := getContentHash(jsonFileData)
+		if err != nil {
+			return err
+		}
+		hashInstalled, err := module_meta.GetHash_tx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if hash != hashInstalled {
+			return fmt.Errorf("original file for module '%s' does not match the installed version", mod.Name)
+		}
+		filePaths = append(filePaths, filePath)
+		return nil
+	}
+	return filePaths, addFilePath(moduleId)
+}
@@ -28,6 +28,7 @@ import (
 	"r3/schema/loginForm"
 	"r3/schema/menuTab"
 	"r3/schema/module"
+	"r3/schema/numberRange"
 	"r3/schema/pgFunction"
 	"r3/schema/pgIndex"
 	"r3/schema/pgTrigger"
@@ -46,6 +47,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type importMeta struct {
@@ -57,15 +59,33 @@ type importMeta struct {
 
 // imports extracted modules from given file paths
 func ImportFromFiles(ctx context.Context, filePathsImport []string) error {
+	_, err := importFromFiles(ctx, filePathsImport, false)
+	return err
+}
+
+// executes import of modules from given file paths without applying it, the import transaction is always rolled back
+// returns changes per module compared to installed modules & the error the import would fail with
+func ImportDryRun(ctx context.Context, filePathsImport []string) (types.TransferDryRun, error) {
+	return importFromFiles(ctx, filePathsImport, true)
+}
+
+func importFromFiles(ctx context.Context, filePathsImport []string, dryRun bool) (types.TransferDryRun, error) {
+
+	var res types.TransferDryRun
+	res.Modules = make([]types.TransferDiff, 0)
 
 	if len(filePathsImport) == 0 {
-		return fmt.Errorf("cannot import modules, no file paths defined")
+		return res, fmt.Errorf("cannot import modules, no file paths defined")
 	}
 
 	import_mx.Lock()
 	defer import_mx.Unlock()
 
-	log.Info(log.ContextTransfer, fmt.Sprintf("start import for modules from file(s): '%s'", strings.Join(filePathsImport, "', '")))
+	if dryRun {
+		log.Info(log.ContextTransfer, fmt.Sprintf("start import dry-run for modules from file(s): '%s'", strings.Join(filePathsImport, "', '")))
+	} else {
+		log.Info(log.ContextTransfer, fmt.Sprintf("start import for modules from file(s): '%s'", strings.Join(filePathsImport, "', '")))
+	}
 
 	// extract module packages
 	filePathsModules := make([]string, 0)
@@ -77,14 +97,19 @@ func ImportFromFiles(ctx context.Context, filePathsImport []string) error {
 
 		filePaths, err := writeFilesFromZip(zipPath, config.File.Paths.Temp, prefix)
 		if err != nil {
-			return err
+			return res, err
 		}
 		filePathsModules = append(filePathsModules, filePaths...)
 	}
 
+	// dry-run does not keep extracted module files
+	if dryRun {
+		defer removeFiles(filePathsModules)
+	}
+
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
-		return err
+		return res, err
 	}
 	defer tx.Rollback(ctx)
 
@@ -92,9 +117,23 @@ func ImportFromFiles(ctx context.Context, filePathsImport []string) error {
 	moduleIdMapImportMeta := make(map[uuid.UUID]importMeta)
 	modules, err := parseModulesFromPaths_tx(ctx, tx, filePathsModules, moduleIdMapImportMeta)
 	if err != nil {
-		return err
+		return res, err
 	}
 
+	if err := verifyDependencies(moduleIdMapImportMeta); err != nil {
+		return res, err
+	}
+	return importExecute_tx(ctx, tx, modules, moduleIdMapImportMeta, dryRun)
+}
+
+// applies parsed modules, changes are committed unless in dry-run
+// installed versions of modules are archived before being replaced
+func importExecute_tx(ctx context.Context, tx pgx.Tx, modules []types.Module, moduleIdMapImportMeta map[uuid.UUID]importMeta, dryRun bool) (types.TransferDryRun, error) {
+
+	var err error
+	var res types.TransferDryRun
+	res.Modules = make([]types.TransferDiff, 0)
+
 	// apply compatibility fixes
 	for i := range modules {
 		// fix import < 3.7: move triggers from relations to module
@@ -103,11 +142,106 @@ func ImportFromFiles(ctx context.Context, filePathsImport []string) error {
 		// fix import < 3.10: add initial menu tab
 		modules[i].MenuTabs, err = compatible.FixMissingMenuTab(modules[i].Id, modules[i].MenuTabs, modules[i].Menus)
 		if err != nil {
+			return res, err
+		}
+	}
+
+	if dryRun {
+		// compare with installed modules before import is applied
+		for _, m := range modules {
+			diff, err := getModuleDiff_tx(ctx, tx, m)
+			if err != nil {
+				return res, err
+			}
+			res.Modules = append(res.Modules, diff)
+		}
+
+		// errors during import & data migrations are part of the dry-run result
+		err = importModules_tx(ctx, tx, modules, moduleIdMapImportMeta)
+		if err == nil {
+			err = executeMigrations_tx(ctx, tx, modules, moduleIdMapImportMeta)
+		}
+		if err != nil {
+			log.Info(log.ContextTransfer, fmt.Sprintf("import dry-run failed, error: %s", err))
+			res.Error = pgtype.Text{String: err.Error(), Valid: true}
+		}
+		log.Info(log.ContextTransfer, "import dry-run finished, changes were rolled back")
+		return res, nil
+	}
+
+	// archive installed versions of modules to be replaced, to allow for rollbacks
+	filePathsArchived, err := archiveModules_tx(ctx, tx, moduleIdMapImportMeta)
+	if err != nil {
+		removeFiles(filePathsArchived)
+		return res, err
+	}
+
+	if err := importModules_tx(ctx, tx, modules, moduleIdMapImportMeta); err != nil {
+		removeFiles(filePathsArchived)
+		return res, err
+	}
+	if err := executeMigrations_tx(ctx, tx, modules, moduleIdMapImportMeta); err != nil {
+		removeFiles(filePathsArchived)
+		return res, err
+	}
+	if err := importCommit(ctx, tx, modules, moduleIdMapImportMeta); err != nil {
+		removeFiles(filePathsArchived)
+		return res, err
+	}
+	if err := archiveCleanup(ctx); err != nil {
+		log.Warning(log.ContextTransfer, "failed to clean up module archive", err)
+	}
+	return res, importUpdateCache(ctx, moduleIdMapImportMeta)
+}
+
+// after all tasks were successful, final checks and clean ups
+func importCommit(ctx context.Context, tx pgx.Tx, modules []types.Module, moduleIdMapImportMeta map[uuid.UUID]importMeta) error {
+
+	for _, m := range modules {
+
+		// set new module hash value in instance
+		if err := module_meta.SetHash_tx(ctx, tx, m.Id, moduleIdMapImportMeta[m.Id].hash); err != nil {
 			return err
 		}
+
+		// move imported module file to transfer path for future exports
+		if err := tools.FileMove(moduleIdMapImportMeta[m.Id].filePath, filepath.Join(
+			config.File.Paths.Transfer, getModuleFilename(m.Id)), true); err != nil {
+
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
 	}
 
-	// import modules
+	log.Info(log.ContextTransfer, "module files were moved to transfer path if imported")
+	return nil
+}
+
+// update schema cache
+func importUpdateCache(ctx context.Context, moduleIdMapImportMeta map[uuid.UUID]importMeta) error {
+	moduleIdsUpdated := make([]uuid.UUID, 0)
+	for id := range moduleIdMapImportMeta {
+		moduleIdsUpdated = append(moduleIdsUpdated, id)
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := cluster.SchemaChanged_tx(ctx, tx, true, moduleIdsUpdated); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// applies modules in import loops, entities that fail are retried in later loops
+func importModules_tx(ctx context.Context, tx pgx.Tx, modules []types.Module, moduleIdMapImportMeta map[uuid.UUID]importMeta) error {
+
 	idMapSkipped := make(map[uuid.UUID]types.Void)
 	loopsToRun := 10
 
@@ -158,45 +292,7 @@ func ImportFromFiles(ctx context.Context, filePathsImport []string) error {
 			log.Info(log.ContextTransfer, fmt.Sprintf("import END, module '%s', %s", m.Name, m.Id))
 		}
 	}
-
-	// after all tasks were successful, final checks and clean ups
-	for _, m := range modules {
-
-		// set new module hash value in instance
-		if err := module_meta.SetHash_tx(ctx, tx, m.Id, moduleIdMapImportMeta[m.Id].hash); err != nil {
-			return err
-		}
-
-		// move imported module file to transfer path for future exports
-		if err := tools.FileMove(moduleIdMapImportMeta[m.Id].filePath, filepath.Join(
-			config.File.Paths.Transfer, getModuleFilename(m.Id)), true); err != nil {
-
-			return err
-		}
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return err
-	}
-
-	log.Info(log.ContextTransfer, "module files were moved to transfer path if imported")
-
-	// update schema cache
-	moduleIdsUpdated := make([]uuid.UUID, 0)
-	for id := range moduleIdMapImportMeta {
-		moduleIdsUpdated = append(moduleIdsUpdated, id)
-	}
-
-	tx, err = db.Pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback(ctx)
-
-	if err := cluster.SchemaChanged_tx(ctx, tx, true, moduleIdsUpdated); err != nil {
-		return err
-	}
-	return tx.Commit(ctx)
+	return nil
 }
 
 func importModule_tx(ctx context.Context, tx pgx.Tx, mod types.Module, firstRun bool, lastRun bool,
@@ -313,12 +409,40 @@ func importModule_tx(ctx context.Context, tx pgx.Tx, mod types.Module, firstRun
 			}
 			log.Info(log.ContextTransfer, fmt.Sprintf("set attribute %s", e.Id))
 
+			// validation rules can refer to attributes not imported yet, they are set afterwards
+			e.Validations = nil
+
 			if err := importCheckResultAndApply(ctx, tx, attribute.Set_tx(ctx, tx, e, false), e.Id, idMapSkipped); err != nil {
 				return err
 			}
 		}
 	}
 
+	// attribute validation rules
+	for _, relation := range mod.Relations {
+		for _, e := range relation.Attributes {
+			if len(e.Validations) == 0 {
+				continue
+			}
+
+			// validation rules are stored with their attribute, they need an own entity ID for retries
+			entityId := uuid.NewV5(e.Id, "validations")
+
+			run, err := importCheckRunAndSave(ctx, tx, firstRun, entityId, idMapSkipped)
+			if err != nil {
+				return err
+			}
+			if !run {
+				continue
+			}
+			log.Info(log.ContextTransfer, fmt.Sprintf("set validation rules of attribute %s", e.Id))
+
+			if err := importCheckResultAndApply(ctx, tx, attribute.SetValidations_tx(ctx, tx, e), entityId, idMapSkipped); err != nil {
+				return err
+			}
+		}
+	}
+
 	// collections
 	for _, e := range mod.Collections {
 		run, err := importCheckRunAndSave(ctx, tx, firstRun, e.Id, idMapSkipped)
@@ -386,6 +510,22 @@ func importModule_tx(ctx context.Context, tx pgx.Tx, mod types.Module, firstRun
 		}
 	}
 
+	// number ranges
+	for _, e := range mod.NumberRanges {
+		run, err := importCheckRunAndSave(ctx, tx, firstRun, e.Id, idMapSkipped)
+		if err != nil {
+			return err
+		}
+		if !run {
+			continue
+		}
+		log.Info(log.ContextTransfer, fmt.Sprintf("set number range %s", e.Id))
+
+		if err := importCheckResultAndApply(ctx, tx, numberRange.Set_tx(ctx, tx, e), e.Id, idMapSkipped); err != nil {
+			return err
+		}
+	}
+
 	// variables
 	for _, e := range mod.Variables {
 		run, err := importCheckRunAndSave(ctx, tx, firstRun, e.Id, idMapSkipped)
@@ -667,71 +807,85 @@ func parseModulesFromPaths_tx(ctx context.Context, tx pgx.Tx, filePaths []string
 		if err := json.Unmarshal(jsonFileData, &fileData); err != nil {
 			return modules, err
 		}
-		moduleId := fileData.Content.Module.Id
-
-		log.Info(log.ContextTransfer, fmt.Sprintf("import is validating module '%s' v%d",
-			fileData.Content.Module.Name, fileData.Content.Module.ReleaseBuild))
 
-		// verify application compatibility
-		if err := verifyCompatibilityWithApp(fileData.Content.Module.ReleaseBuildApp); err != nil {
+		if err := addModuleForImport_tx(ctx, tx, fileData.Content.Module, hashedStr, filePath, moduleIdMapImportMeta); err != nil {
 			return modules, err
 		}
+	}
 
-		// check whether module is imported anew or updated
-		cache.Schema_mx.RLock()
-		exModule, isModuleUpgrade := cache.ModuleIdMap[moduleId]
-		cache.Schema_mx.RUnlock()
+	return getModulesInImportOrder(moduleIdMapImportMeta), nil
+}
 
-		if isModuleUpgrade {
+// validates module for import, adds it to import meta map if it needs to be imported
+func addModuleForImport_tx(ctx context.Context, tx pgx.Tx, mod types.Module, hashedStr string,
+	filePath string, moduleIdMapImportMeta map[uuid.UUID]importMeta) error {
 
-			// check for newer version of installed module
-			if exModule.ReleaseBuild >= fileData.Content.Module.ReleaseBuild {
+	moduleId := mod.Id
 
-				log.Info(log.ContextTransfer, fmt.Sprintf("import of module '%s' not required, same or newer version (%d -> %d) installed",
-					fileData.Content.Module.Name, exModule.ReleaseBuild,
-					fileData.Content.Module.ReleaseBuild))
+	log.Info(log.ContextTransfer, fmt.Sprintf("import is validating module '%s' v%d",
+		mod.Name, mod.ReleaseBuild))
 
-				continue
-			}
+	// verify application compatibility
+	if err := verifyCompatibilityWithApp(mod.ReleaseBuildApp); err != nil {
+		return err
+	}
 
-			// check whether installed module hash changed at all
-			hashedStrEx, err := module_meta.GetHash_tx(ctx, tx, moduleId)
-			if err != nil {
-				return modules, err
-			}
+	// check whether module is imported anew or updated
+	cache.Schema_mx.RLock()
+	exModule, isModuleUpgrade := cache.ModuleIdMap[moduleId]
+	cache.Schema_mx.RUnlock()
 
-			if hashedStr == hashedStrEx {
-				log.Info(log.ContextTransfer, fmt.Sprintf("import of module '%s' not required, no changes",
-					fileData.Content.Module.Name))
+	if isModuleUpgrade {
 
-				continue
-			}
+		// check for newer version of installed module
+		if exModule.ReleaseBuild >= mod.ReleaseBuild {
+
+			log.Info(log.ContextTransfer, fmt.Sprintf("import of module '%s' not required, same or newer version (%d -> %d) installed",
+				mod.Name, exModule.ReleaseBuild, mod.ReleaseBuild))
+
+			return nil
 		}
 
-		// check whether module was added previously (multiple import files used with similar modules)
-		if _, exists := moduleIdMapImportMeta[moduleId]; exists {
-			if moduleIdMapImportMeta[moduleId].module.ReleaseBuild >= fileData.Content.Module.ReleaseBuild {
-				log.Info(log.ContextTransfer, fmt.Sprintf("import of module '%s' not required, same or newer version (%d -> %d) to be added",
-					fileData.Content.Module.Name, moduleIdMapImportMeta[moduleId].module.ReleaseBuild,
-					fileData.Content.Module.ReleaseBuild))
+		// check whether installed module hash changed at all
+		hashedStrEx, err := module_meta.GetHash_tx(ctx, tx, moduleId)
+		if err != nil {
+			return err
+		}
 
-				continue
-			}
+		if hashedStr == hashedStrEx {
+			log.Info(log.ContextTransfer, fmt.Sprintf("import of module '%s' not required, no changes", mod.Name))
+
+			return nil
 		}
+	}
 
-		log.Info(log.ContextTransfer, fmt.Sprintf("import will install module '%s' v%d",
-			fileData.Content.Module.Name, fileData.Content.Module.ReleaseBuild))
+	// check whether module was added previously (multiple import files used with similar modules)
+	if _, exists := moduleIdMapImportMeta[moduleId]; exists {
+		if moduleIdMapImportMeta[moduleId].module.ReleaseBuild >= mod.ReleaseBuild {
+			log.Info(log.ContextTransfer, fmt.Sprintf("import of module '%s' not required, same or newer version (%d -> %d) to be added",
+				mod.Name, moduleIdMapImportMeta[moduleId].module.ReleaseBuild, mod.ReleaseBuild))
 
-		moduleIdMapImportMeta[moduleId] = importMeta{
-			filePath: filePath,
-			hash:     hashedStr,
-			isNew:    !isModuleUpgrade,
-			module:   fileData.Content.Module,
+			return nil
 		}
 	}
 
-	// return modules in optimized import order
-	// add modules in order of least dependencies
+	log.Info(log.ContextTransfer, fmt.Sprintf("import will install module '%s' v%d",
+		mod.Name, mod.ReleaseBuild))
+
+	moduleIdMapImportMeta[moduleId] = importMeta{
+		filePath: filePath,
+		hash:     hashedStr,
+		isNew:    !isModuleUpgrade,
+		module:   mod,
+	}
+	return nil
+}
+
+// returns modules in optimized import order
+// add modules in order of least dependencies
+func getModulesInImportOrder(moduleIdMapImportMeta map[uuid.UUID]importMeta) []types.Module {
+
+	modules := make([]types.Module, 0)
 	moduleIdsAdded := make([]uuid.UUID, 0)
 	moduleIdsSort := make([]uuid.UUID, 0)
 	moduleNames := make([]string, 0)
@@ -771,5 +925,5 @@ func parseModulesFromPaths_tx(ctx context.Context, tx pgx.Tx, filePaths []string
 	log.Info(log.ContextTransfer, fmt.Sprintf("import has decided on installation order: %s",
 		strings.Join(moduleNames, ", ")))
 
-	return modules, nil
+	return modules
 }
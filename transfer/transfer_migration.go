@@ -0,0 +1,144 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"r3/cache"
+	"r3/log"
+	"r3/tools"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// module releases can include data migrations, PG functions that update existing data to changed schemas
+// migrations are executed once per instance, when upgrading from a lower to the same or a higher build than their release
+
+type migration struct {
+	build          int64
+	pgFunctionId   uuid.UUID
+	pgFunctionName string
+}
+
+func MigrationsGet_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.ModuleMigration, error) {
+	migrations := make([]types.ModuleMigration, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT module_id, build, pg_function_id, release_build_from,
+			release_build_to, date_executed
+		FROM instance.module_migration
+		WHERE module_id = $1
+		ORDER BY date_executed DESC, build DESC
+	`, moduleId)
+	if err != nil {
+		return migrations, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m types.ModuleMigration
+		if err := rows.Scan(&m.ModuleId, &m.Build, &m.PgFunctionId, &m.ReleaseBuildFrom,
+			&m.ReleaseBuildTo, &m.DateExecuted); err != nil {
+
+			return migrations, err
+		}
+		migrations = append(migrations, m)
+	}
+	return migrations, nil
+}
+
+// executes pending migrations of all upgraded modules, in import order
+// must run after the import was applied, as migrations work on the new schema
+func executeMigrations_tx(ctx context.Context, tx pgx.Tx, modules []types.Module, moduleIdMapImportMeta map[uuid.UUID]importMeta) error {
+
+	for _, mod := range modules {
+		if moduleIdMapImportMeta[mod.Id].isNew {
+			continue
+		}
+
+		cache.Schema_mx.RLock()
+		modOld, exists := cache.ModuleIdMap[mod.Id]
+		cache.Schema_mx.RUnlock()
+
+		if !exists {
+			continue
+		}
+
+		migrations, err := getMigrationsPending_tx(ctx, tx, mod, modOld.ReleaseBuild)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			log.Info(log.ContextTransfer, fmt.Sprintf("executing migration '%s' of module '%s' v%d",
+				m.pgFunctionName, mod.Name, m.build))
+
+			if _, err := tx.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"()`, mod.Name, m.pgFunctionName)); err != nil {
+				return fmt.Errorf("migration '%s' of module '%s' v%d failed, %w",
+					m.pgFunctionName, mod.Name, m.build, err)
+			}
+
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO instance.module_migration (module_id, build, pg_function_id,
+					release_build_from, release_build_to, date_executed)
+				VALUES ($1,$2,$3,$4,$5,$6)
+			`, mod.Id, m.build, m.pgFunctionId, modOld.ReleaseBuild, mod.ReleaseBuild, tools.GetTimeUnix()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// returns migrations of releases between installed and new build, that were not executed yet
+func getMigrationsPending_tx(ctx context.Context, tx pgx.Tx, mod types.Module, buildFrom int) ([]migration, error) {
+
+	migrations := make([]migration, 0)
+	fncIdMapName := make(map[uuid.UUID]string)
+	for _, f := range mod.PgFunctions {
+		fncIdMapName[f.Id] = f.Name
+	}
+
+	releases := slices.Clone(mod.Releases)
+	slices.SortFunc(releases, func(a, b types.Release) int {
+		return int(a.Build - b.Build)
+	})
+
+	for _, r := range releases {
+		if r.Build <= int64(buildFrom) || r.Build > int64(mod.ReleaseBuild) {
+			continue
+		}
+
+		for _, pgFunctionId := range r.Migrations {
+			name, exists := fncIdMapName[pgFunctionId]
+			if !exists {
+				return migrations, fmt.Errorf("migration function '%s' of module '%s' v%d does not exist",
+					pgFunctionId, mod.Name, r.Build)
+			}
+
+			var executed bool
+			if err := tx.QueryRow(ctx, `
+				SELECT EXISTS (
+					SELECT *
+					FROM instance.module_migration
+					WHERE module_id      = $1
+					AND   build          = $2
+					AND   pg_function_id = $3
+				)
+			`, mod.Id, r.Build, pgFunctionId).Scan(&executed); err != nil {
+				return migrations, err
+			}
+
+			if !executed {
+				migrations = append(migrations, migration{
+					build:          r.Build,
+					pgFunctionId:   pgFunctionId,
+					pgFunctionName: name,
+				})
+			}
+		}
+	}
+	return migrations, nil
+}
@@ -1,14 +1,65 @@
 package types
 
 import (
+	"encoding/json"
+
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AuditLog struct {
+	Id        int64       `json:"id"`
+	Date      int64       `json:"date"`
+	LoginId   pgtype.Int8 `json:"loginId"`   // empty if not executed by login
+	LoginName string      `json:"loginName"` // name at time of action, login might be renamed or deleted
+	Address   string      `json:"address"`   // network address of client
+	Ressource string      `json:"ressource"`
+	Action    string      `json:"action"`
+	ObjectId  pgtype.Text `json:"objectId"` // ID of affected object, if included in payload
+	Payload   string      `json:"payload"`  // request payload as JSON, secrets redacted
+	Diff      pgtype.Text `json:"diff"`     // changed values of affected object compared to its state before the action, as JSON
+	HashPrev  string      `json:"hashPrev"` // hash of previous entry, empty for first entry
+	Hash      string      `json:"hash"`     // hash of this entry, including previous hash
+}
+type AuditLogDiff struct {
+	Key    string          `json:"key"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
 type BackupDef struct {
-	AppBuild  int    `json:"appBuild"`
-	JobName   string `json:"jobName"`
-	Timestamp int64  `json:"timestamp"`
+	AppBuild    int         `json:"appBuild"`
+	Encrypted   bool        `json:"encrypted"` // backup files are encrypted with public key
+	JobName     string      `json:"jobName"`
+	Scope       string      `json:"scope"`   // database, files, full
+	Targets     []string    `json:"targets"` // names of remote targets with verified uploads
+	Timestamp   int64       `json:"timestamp"`
+	VerifyDate  pgtype.Int8 `json:"verifyDate"`  // date of restore verification, null if not verified
+	VerifyError pgtype.Text `json:"verifyError"` // error of restore verification, null if successful
+}
+type BackupJob struct {
+	Id            int32  `json:"id"`
+	Name          string `json:"name"`
+	Scope         string `json:"scope"` // database, files, full
+	IntervalHours int    `json:"intervalHours"`
+	KeepCount     int    `json:"keepCount"`
+	Active        bool   `json:"active"`
+}
+type BackupTarget struct {
+	Id         int32  `json:"id"`
+	Name       string `json:"name"`
+	Content    string `json:"content"` // local, s3, sftp
+	Active     bool   `json:"active"`
+	HostName   string `json:"hostName"`   // SFTP host name, S3 endpoint URL
+	HostPort   int    `json:"hostPort"`   // SFTP port
+	HostKey    string `json:"hostKey"`    // SFTP public host key, in authorized keys format
+	Path       string `json:"path"`       // local or SFTP directory, S3 key prefix
+	Bucket     string `json:"bucket"`     // S3 bucket
+	Region     string `json:"region"`     // S3 region
+	Username   string `json:"username"`   // SFTP user, S3 access key ID
+	Password   string `json:"password"`   // SFTP password, S3 secret access key
+	PrivateKey string `json:"privateKey"` // SFTP private key, in PEM format
+	KeepCount  int    `json:"keepCount"`  // backups kept per job on target, 0 = no cleanup
 }
 type BackupTocFile struct {
 	Backups []BackupDef `json:"backups"`
@@ -23,13 +74,41 @@ type Log struct {
 	Date       int64       `json:"date"`
 }
 
+type Job struct {
+	Id             int64       `json:"id"`
+	PgFunctionId   uuid.UUID   `json:"pgFunctionId"`
+	Queue          string      `json:"queue"`
+	Args           pgtype.Text `json:"args"` // JSONB as text
+	Priority       int         `json:"priority"`
+	State          string      `json:"state"` // waiting, running, done, failed
+	UniqueKey      pgtype.Text `json:"uniqueKey"`
+	Attempts       int         `json:"attempts"`
+	AttemptsMax    int         `json:"attemptsMax"`
+	NodeName       pgtype.Text `json:"nodeName"`
+	DateAdded      int64       `json:"dateAdded"`
+	DateRunAfter   int64       `json:"dateRunAfter"`
+	DateMilliStart pgtype.Int8 `json:"dateMilliStart"`
+	DateMilliEnd   pgtype.Int8 `json:"dateMilliEnd"`
+	Result         pgtype.Text `json:"result"`
+	ErrorMessage   pgtype.Text `json:"errorMessage"`
+}
+type JobQueue struct {
+	Name        string `json:"name"`
+	Concurrency int    `json:"concurrency"` // max. number of jobs running in parallel, over all cluster nodes
+	Waiting     int64  `json:"waiting"`
+	Running     int64  `json:"running"`
+	Failed      int64  `json:"failed"`
+}
 type LoginAdmin struct {
 	Id               int64              `json:"id"`
 	LdapId           pgtype.Int4        `json:"ldapId"`
 	OauthClientId    pgtype.Int4        `json:"oauthClientId"`
+	ScimClientId     pgtype.Int4        `json:"scimClientId"`
+	SamlIdpId        pgtype.Int4        `json:"samlIdpId"`
 	Name             string             `json:"name"`
 	Active           bool               `json:"active"`
 	Admin            bool               `json:"admin"`
+	AdminPermissions []string           `json:"adminPermissions"` // partial admin permissions, full admin has all
 	Meta             LoginMeta          `json:"meta"`
 	NoAuth           bool               `json:"noAuth"`
 	LanguageCode     string             `json:"languageCode"`
@@ -62,6 +141,7 @@ type Ldap struct {
 	LoginTemplateId  pgtype.Int8       `json:"loginTemplateId"` // template for new logins (applies login settings)
 	Name             string            `json:"name"`
 	Host             string            `json:"host"`
+	HostsFailover    []string          `json:"hostsFailover"` // hosts tried in order if primary host is unreachable, same port
 	Port             int               `json:"port"`
 	BindUserDn       string            `json:"bindUserDn"`       // DN of bind user, example: 'CN=readonly,OU=User,DC=test,DC=local'
 	BindUserPw       string            `json:"bindUserPw"`       // password of bind user in clear text
@@ -70,6 +150,8 @@ type Ldap struct {
 	KeyAttribute     string            `json:"keyAttribute"`     // name of attribute used as key, example: 'objectGUID'
 	LoginAttribute   string            `json:"loginAttribute"`   // name of attribute used as login, example: 'sAMAccountName'
 	MemberAttribute  string            `json:"memberAttribute"`  // name of attribute used as membership, example: 'memberOf'
+	FilterGroupDn    pgtype.Text       `json:"filterGroupDn"`    // only members of this group DN are imported (nested with MS AD extensions)
+	DeltaAttribute   pgtype.Text       `json:"deltaAttribute"`   // attribute for delta sync, example: 'uSNChanged' or 'modifyTimestamp', full sync if empty
 	LoginMetaMap     LoginMeta         `json:"loginMetaMap"`     // names of LDAP attributes to map to login meta data
 	LoginRolesAssign []LoginRoleAssign `json:"loginRolesAssign"` // assign login roles based on LDAP group membership
 	AssignRoles      bool              `json:"assignRoles"`      // assign login roles from group membership (see member attribute)
@@ -79,6 +161,17 @@ type Ldap struct {
 	TlsVerify        bool              `json:"tlsVerify"`        // verify TLS connection, can be used to allow non-trusted certificates
 }
 
+type LdapImportChange struct {
+	Key            string      `json:"key"`            // value of key attribute
+	Name           string      `json:"name"`           // login name
+	Create         bool        `json:"create"`         // login is new
+	Change         bool        `json:"change"`         // name or meta data changed
+	Disable        bool        `json:"disable"`        // login is disabled
+	Enable         bool        `json:"enable"`         // login is re-enabled
+	RoleIdsAdded   []uuid.UUID `json:"roleIdsAdded"`   // roles assigned, if role assignment is active
+	RoleIdsRemoved []uuid.UUID `json:"roleIdsRemoved"` // roles removed, if role assignment is active
+}
+
 type OauthClient struct {
 	Id           int32       `json:"id"`
 	Name         string      `json:"name"`         // reference name, also shown on login page if authCodePkce
@@ -103,6 +196,69 @@ type OauthClient struct {
 	RedirectUrl      pgtype.Text       `json:"redirectUrl"`
 }
 
+type PrivacyRelation struct {
+	RelationId            uuid.UUID   `json:"relationId"`
+	Person                bool        `json:"person"`                // relation contains data subjects, only one relation can be the person relation
+	AttributeIdLogin      pgtype.UUID `json:"attributeIdLogin"`      // attribute of person relation containing login ID, to find data subjects by login
+	Strategy              string      `json:"strategy"`              // erasure strategy (delete, anonymize, keep)
+	AttributeIdsAnonymize []uuid.UUID `json:"attributeIdsAnonymize"` // attributes to clear, if strategy is anonymize
+}
+type PrivacyRecords struct {
+	RelationId uuid.UUID `json:"relationId"`
+	RecordIds  []int64   `json:"recordIds"`
+	Referenced bool      `json:"referenced"` // records are referenced by other found records (not referencing them)
+	Strategy   string    `json:"strategy"`   // erasure strategy applied to records
+}
+type PrivacySubject struct {
+	LoginId    pgtype.Int8 `json:"loginId"`    // find data subject by login
+	RelationId pgtype.UUID `json:"relationId"` // find data subject by record
+	RecordId   pgtype.Int8 `json:"recordId"`
+}
+type ScheduleAlert struct {
+	Id           int64  `json:"id"`
+	ScheduleId   int64  `json:"scheduleId"`
+	Content      string `json:"content"`      // failures (consecutive failed runs), missed (run overdue), runtime (run took too long)
+	Threshold    int    `json:"threshold"`    // failures: number of runs, missed/runtime: seconds
+	DateLastSent int64  `json:"dateLastSent"` // last time the alert was sent
+}
+type ScheduleRun struct {
+	Id           int64       `json:"id"`
+	ScheduleId   int64       `json:"scheduleId"`
+	NodeName     pgtype.Text `json:"nodeName"` // empty if node was removed
+	DateStart    int64       `json:"dateStart"`
+	DateEnd      pgtype.Int8 `json:"dateEnd"`      // empty while running or if run was interrupted
+	Duration     pgtype.Int8 `json:"duration"`     // run time in milliseconds
+	Success      pgtype.Bool `json:"success"`      // empty while running or if run was interrupted
+	ErrorMessage pgtype.Text `json:"errorMessage"` // empty if successful
+}
+type SearchDictionary struct {
+	LanguageCode string `json:"languageCode"` // login language, e.g. 'en_us'
+	Dictionary   string `json:"dictionary"`   // full text search dictionary for language, e.g. 'english'
+}
+type ScimClient struct {
+	Id               int32             `json:"id"`
+	LoginTemplateId  pgtype.Int8       `json:"loginTemplateId"`  // template for new logins (applies login settings)
+	Name             string            `json:"name"`             // reference name
+	LoginRolesAssign []LoginRoleAssign `json:"loginRolesAssign"` // assign login roles based on SCIM group names
+}
+
+type SamlIdp struct {
+	Id                  int32             `json:"id"`
+	LoginTemplateId     pgtype.Int8       `json:"loginTemplateId"`     // template for new logins (applies login settings)
+	Name                string            `json:"name"`                // reference name, also shown on login page
+	EntityId            string            `json:"entityId"`            // entity ID of identity provider, must match issuer of assertions
+	SsoUrl              string            `json:"ssoUrl"`              // single sign-on URL of identity provider (HTTP-Redirect binding)
+	Certificate         string            `json:"certificate"`         // PEM encoded signing certificate of identity provider
+	SpEntityId          string            `json:"spEntityId"`          // entity ID of this service provider, must match audience of assertions
+	AcsUrl              string            `json:"acsUrl"`              // assertion consumer service URL of this service provider, such as https://my-instance/saml/acs/1
+	LoginMetaMap        LoginMeta         `json:"loginMetaMap"`        // map assertion attribute <-> login meta data key
+	LoginRolesAssign    []LoginRoleAssign `json:"loginRolesAssign"`    // assign login roles based on values of roles attribute
+	AttributeAdmin      pgtype.Text       `json:"attributeAdmin"`      // name of attribute that contains value to assign instance admin permission
+	AttributeAdminValue pgtype.Text       `json:"attributeAdminValue"` // expected value of admin attribute, must match precisely
+	AttributeRoles      pgtype.Text       `json:"attributeRoles"`      // name of (multi-valued) attribute that contains values for role mapping
+	AttributeUsername   pgtype.Text       `json:"attributeUsername"`   // name of attribute that contains username, name ID is used if empty
+}
+
 // public reference for OAUTH client for Open ID Connect authentication
 // must not contain sensitive data such as client secret
 type OauthClientOpenId struct {
@@ -113,3 +269,9 @@ type OauthClientOpenId struct {
 	RedirectUrl pgtype.Text `json:"redirectUrl"`
 	Scopes      []string    `json:"scopes"`
 }
+
+// public reference for SAML identity provider, shown on login page
+type SamlIdpLogin struct {
+	Id   int32  `json:"id"`
+	Name string `json:"name"`
+}
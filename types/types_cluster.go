@@ -45,6 +45,14 @@ type ClusterEventMasterAssigned struct {
 	// filled by instance_cluster.master_role_request()
 	State bool `json:"state"`
 }
+type ClusterEventRecordsChanged struct {
+	// filled by instance.records_changed()
+	AttributeIds []uuid.UUID `json:"attributeIds"` // changed attributes, empty if unknown (changed by backend functions)
+	Deleted      bool        `json:"deleted"`
+	LoginId      int64       `json:"loginId"` // login that changed the records, 0 if unknown
+	RecordIds    []int64     `json:"recordIds"`
+	RelationId   uuid.UUID   `json:"relationId"`
+}
 type ClusterEventTaskTriggered struct {
 	// filled by instance_cluster.run_task()
 	PgFunctionId         uuid.UUID `json:"pgFunctionId"`
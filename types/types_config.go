@@ -7,6 +7,12 @@ type Version struct {
 }
 
 type FileType struct {
+	// key of audit log hash chain, kept outside of the database & shared by all cluster nodes
+	Audit struct {
+		Key          string `json:"key"`
+		UnkeyedIdMax int64  `json:"unkeyedIdMax"` // entries up to this ID were hashed before the key was introduced
+	} `json:"audit"`
+
 	Cluster struct {
 		NodeId string `json:"nodeId"`
 	} `json:"cluster"`
@@ -89,13 +89,17 @@ type DataGet struct {
 	Offset      int                 `json:"offset"`      // result offset
 	GetPerm     bool                `json:"getPerm"`     // get result permissions (SET/DEL) from relation policy, GET is ignored as results are filtered by it already
 	SearchDicts []string            `json:"searchDicts"` // list of fulltext search dictionaries (english, german, ...)
+	AsOf        pgtype.Int8         `json:"asOf"`        // unix time to reconstruct attribute values for from change logs, current values if empty
+	GetVersion  bool                `json:"getVersion"`  // get record versions, to be sent with data SET to detect changes made in the meantime
 }
 type DataGetResult struct {
-	IndexRecordIds     map[int]any    `json:"indexRecordIds"`     // IDs of relation records, key: relation index
-	IndexRecordEncKeys map[int]string `json:"indexRecordEncKeys"` // record data keys, encrypted with login´s public key, key: relation index
-	IndexesPermNoDel   []int          `json:"indexesPermNoDel"`   // if getPerm, relation indexes of which records may not be deleted
-	IndexesPermNoSet   []int          `json:"indexesPermNoSet"`   // if getPerm, relation indexes of which records may not be updated
-	Values             []any          `json:"values"`             // expression values, same order as requested expressions
+	IndexRecordIds      map[int]any    `json:"indexRecordIds"`      // IDs of relation records, key: relation index
+	IndexRecordEncKeys  map[int]string `json:"indexRecordEncKeys"`  // record data keys, encrypted with login´s public key, key: relation index
+	IndexRecordVersions map[int]int64  `json:"indexRecordVersions"` // if getVersion, record versions, key: relation index
+	IndexesPermNoDel    []int          `json:"indexesPermNoDel"`    // if getPerm, relation indexes of which records may not be deleted
+	IndexesPermNoSet    []int          `json:"indexesPermNoSet"`    // if getPerm, relation indexes of which records may not be updated
+	Values              []any          `json:"values"`              // expression values, same order as requested expressions
+	ValuesNoAsOf        []int          `json:"valuesNoAsOf"`        // if asOf, positions of values that could not be reconstructed as their change logs were removed by retention
 }
 type DataGetValueFile struct {
 	Id      uuid.UUID `json:"id"`
@@ -132,11 +136,36 @@ type DataSet struct {
 	RecordId    int64              `json:"recordId"`    // record ID to update (0 if new)
 	Attributes  []DataSetAttribute `json:"attributes"`  // attribute values to set
 	EncKeysSet  []DataSetEncKeys   `json:"encKeysSet"`  // data encryption keys to store, encrypted with login´s public key
+	Version     pgtype.Int8        `json:"version"`     // record version known to requestor, SET is rejected if record was changed since (optional)
+}
+type DataSetVersionConflict struct {
+	Deleted  bool  `json:"deleted"`  // record was deleted since
+	Index    int   `json:"index"`    // relation index of changed record
+	RecordId int64 `json:"recordId"` // ID of changed record
+	Version  int64 `json:"version"`  // current record version
+	Values   []any `json:"values"`   // current values, same order as attributes of data SET
 }
 type DataSetResult struct {
 	IndexRecordIds map[int]int64 `json:"indexRecordIds"` // IDs of relation records, key: relation index
 }
 
+// data SUBSCRIPTION request - clients are notified about changes to records
+type DataSubscription struct {
+	Id                     string                `json:"id"`                     // subscription ID, defined by client
+	Query                  *DataGet              `json:"query"`                  // notify about changes to records of query relations, nil if not used
+	RelationIdMapRecordIds map[uuid.UUID][]int64 `json:"relationIdMapRecordIds"` // notify about changes to specific records
+}
+
+// data RECYCLE request - records kept after deletion
+type DataRecycle struct {
+	Id         uuid.UUID         `json:"id"`
+	RelationId uuid.UUID         `json:"relationId"`
+	RecordId   int64             `json:"recordId"`
+	DateDelete int64             `json:"dateDelete"`
+	LoginName  pgtype.Text       `json:"loginName"` // empty if deleted by the system or login no longer exists
+	Values     map[uuid.UUID]any `json:"values"`    // record values by attribute ID, only readable & non-encrypted attributes
+}
+
 // data LOG request - includes either a list of changed attribute values or a comment set from an instance function
 type DataLog struct {
 	Id         uuid.UUID `json:"id"`
@@ -152,3 +181,16 @@ type DataLog struct {
 	// if record comment - in this case, no attribute values exist
 	Comment pgtype.Text `json:"comment"`
 }
+
+// data SEARCH request - global search across all relations with searchable attributes
+type DataSearch struct {
+	Input string `json:"input"` // search input, interpreted as web search query
+	Limit int    `json:"limit"`
+}
+type DataSearchResult struct {
+	RelationId uuid.UUID   `json:"relationId"`
+	RecordId   int64       `json:"recordId"`
+	FormId     pgtype.UUID `json:"formId"` // form to open record with, empty if none is defined
+	Title      pgtype.Text `json:"title"`  // record title, empty if relation has no readable title
+	Rank       float64     `json:"rank"`
+}
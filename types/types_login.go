@@ -10,15 +10,17 @@ type Login struct {
 	Name string `json:"name"`
 }
 type LoginAccess struct {
-	RoleIds     []uuid.UUID          `json:"roleIds"`     // all assigned roles (incl. inherited)
-	Api         map[uuid.UUID]Access `json:"api"`         // effective access to specific API
-	Attribute   map[uuid.UUID]Access `json:"attribute"`   // effective access to specific attributes
-	ClientEvent map[uuid.UUID]Access `json:"clientEvent"` // effective access to specific client events
-	Collection  map[uuid.UUID]Access `json:"collection"`  // effective access to specific collection
-	Menu        map[uuid.UUID]Access `json:"menu"`        // effective access to specific menus
-	Relation    map[uuid.UUID]Access `json:"relation"`    // effective access to specific relations
-	SearchBar   map[uuid.UUID]Access `json:"searchBar"`   // effective access to specific search bars
-	Widget      map[uuid.UUID]Access `json:"widget"`      // effective access to specific widgets
+	RoleIds          []uuid.UUID          `json:"roleIds"`          // all assigned roles (incl. inherited)
+	AdminPermissions []string             `json:"adminPermissions"` // admin permissions (builder, logins, logs, mails, system), not used for full admins
+	Api              map[uuid.UUID]Access `json:"api"`              // effective access to specific API
+	Attribute        map[uuid.UUID]Access `json:"attribute"`        // effective access to specific attributes
+	ClientEvent      map[uuid.UUID]Access `json:"clientEvent"`      // effective access to specific client events
+	Collection       map[uuid.UUID]Access `json:"collection"`       // effective access to specific collection
+	Menu             map[uuid.UUID]Access `json:"menu"`             // effective access to specific menus
+	Relation         map[uuid.UUID]Access `json:"relation"`         // effective access to specific relations
+	SearchBar        map[uuid.UUID]Access `json:"searchBar"`        // effective access to specific search bars
+	Widget           map[uuid.UUID]Access `json:"widget"`           // effective access to specific widgets
+	AttributeMask    map[uuid.UUID]string `json:"attributeMask"`    // effective masks for attribute values
 }
 type LoginAuthResult struct {
 	// auth types: user, token, fixed token, openId
@@ -45,3 +45,21 @@ type RepoModuleMeta struct {
 	Description string `json:"description"`
 	SupportPage string `json:"supportPage"`
 }
+
+// instance serving modules as repository for other instances
+type RepoServe struct {
+	Logins  []RepoServeLogin  `json:"logins"`  // logins that may fetch modules, used as repository credentials by other instances
+	Modules []RepoServeModule `json:"modules"` // published modules
+}
+type RepoServeLogin struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+type RepoServeModule struct {
+	ModuleId    uuid.UUID   `json:"moduleId"`
+	Author      string      `json:"author"`
+	Description string      `json:"description"`
+	InStore     bool        `json:"inStore"` // shown in repository, otherwise only available as dependency
+	SupportPage string      `json:"supportPage"`
+	FileError   pgtype.Text `json:"fileError"` // reason why module cannot be served, read only
+}
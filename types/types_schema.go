@@ -29,21 +29,30 @@ type Article struct {
 	Captions CaptionMap `json:"captions"`
 }
 type Attribute struct {
-	Id             uuid.UUID   `json:"id"`
-	RelationId     uuid.UUID   `json:"relationId"`     // attribute belongs to this relation
-	RelationshipId pgtype.UUID `json:"relationshipId"` // ID of target relation
-	IconId         pgtype.UUID `json:"iconId"`         // default icon
-	Name           string      `json:"name"`           // name, used as table column
-	Content        string      `json:"content"`        // content (integer, varchar, text, real, uuid, files, n:1, ...)
-	ContentUse     string      `json:"contentUse"`     // content use (default, richtext, color, datetime, ...)
-	Length         int         `json:"length"`         // numeric precision (digits number + fractions) / varchar length / max file size in KB
-	LengthFract    int         `json:"lengthFract"`    // numeric scale (digits fractions)
-	Nullable       bool        `json:"nullable"`       // value is nullable
-	Encrypted      bool        `json:"encrypted"`      // value is encrypted (end-to-end for logins)
-	Def            string      `json:"def"`            // default value
-	OnUpdate       string      `json:"onUpdate"`       // relationship attribute, action on 'UPDATE'
-	OnDelete       string      `json:"onDelete"`       // relationship attribute, action on 'DELETE'
-	Captions       CaptionMap  `json:"captions"`
+	Id             uuid.UUID             `json:"id"`
+	RelationId     uuid.UUID             `json:"relationId"`     // attribute belongs to this relation
+	RelationshipId pgtype.UUID           `json:"relationshipId"` // ID of target relation
+	IconId         pgtype.UUID           `json:"iconId"`         // default icon
+	Name           string                `json:"name"`           // name, used as table column
+	Content        string                `json:"content"`        // content (integer, varchar, text, real, uuid, files, n:1, ...)
+	ContentUse     string                `json:"contentUse"`     // content use (default, richtext, color, datetime, ...)
+	Length         int                   `json:"length"`         // numeric precision (digits number + fractions) / varchar length / max file size in KB
+	LengthFract    int                   `json:"lengthFract"`    // numeric scale (digits fractions)
+	Nullable       bool                  `json:"nullable"`       // value is nullable
+	Encrypted      bool                  `json:"encrypted"`      // value is encrypted (end-to-end for logins)
+	Def            string                `json:"def"`            // default value
+	OnUpdate       string                `json:"onUpdate"`       // relationship attribute, action on 'UPDATE'
+	OnDelete       string                `json:"onDelete"`       // relationship attribute, action on 'DELETE'
+	Validations    []AttributeValidation `json:"validations"`    // validation rules, enforced when data is set
+	Captions       CaptionMap            `json:"captions"`
+}
+type AttributeValidation struct {
+	Content            string      `json:"content"`            // rule type: regex, min, max, values, compare, unique
+	Value              pgtype.Text `json:"value"`              // regex pattern (regex), lower/upper limit (min/max: number value or text length)
+	Values             []string    `json:"values"`             // allowed values (values)
+	Operator           pgtype.Text `json:"operator"`           // comparison operator (compare): =, <>, <, <=, >, >=
+	AttributeIdCompare pgtype.UUID `json:"attributeIdCompare"` // attribute of same relation to compare value with (compare)
+	AttributeIdsScope  []uuid.UUID `json:"attributeIdsScope"`  // attributes of same relation, value must be unique among records with equal values for these (unique), entire relation if empty
 }
 type CaptionMap map[string]map[string]string // content->language_code->value
 type ClientEvent struct {
@@ -473,6 +482,7 @@ type Module struct {
 	Languages            []string          `json:"languages"` // language codes that this module supports
 	LoginForms           []LoginForm       `json:"loginForms"`
 	MenuTabs             []MenuTab         `json:"menuTabs"`
+	NumberRanges         []NumberRange     `json:"numberRanges"`
 	PgFunctions          []PgFunction      `json:"pgFunctions"`
 	PgTriggers           []PgTrigger       `json:"pgTriggers"`
 	Relations            []Relation        `json:"relations"`
@@ -492,6 +502,18 @@ type ModuleStartForm struct {
 	RoleId   uuid.UUID `json:"roleId"`
 	FormId   uuid.UUID `json:"formId"`
 }
+type NumberRange struct {
+	Id               uuid.UUID   `json:"id"`
+	ModuleId         uuid.UUID   `json:"moduleId"`
+	AttributeId      pgtype.UUID `json:"attributeId"`      // text attribute to assign numbers to on record creation, optional
+	AttributeIdScope pgtype.UUID `json:"attributeIdScope"` // attribute of the same relation, each value has its own numbers (e.g. per tenant), optional
+	Name             string      `json:"name"`
+	Comment          pgtype.Text `json:"comment"` // author comment
+	Prefix           string      `json:"prefix"`  // text before number, date placeholders {YYYY}, {YY}, {MM}, {DD} are replaced
+	Suffix           string      `json:"suffix"`  // text after number, same placeholders as prefix
+	Digits           int         `json:"digits"`  // minimum number of digits, padded with zeros
+	Reset            string      `json:"reset"`   // period after which numbers restart (never, year, month, day)
+}
 type OpenDoc struct {
 	DocIdOpen         uuid.UUID   `json:"docIdOpen"`         // document to open
 	RelationIndexOpen int         `json:"relationIndexOpen"` // relation index of record to open
@@ -530,6 +552,7 @@ type Role struct {
 	AccessRelations    map[uuid.UUID]Access `json:"accessRelations"`
 	AccessSearchBars   map[uuid.UUID]Access `json:"accessSearchBars"`
 	AccessWidgets      map[uuid.UUID]Access `json:"accessWidgets"`
+	AttributeMasks     map[uuid.UUID]string `json:"attributeMasks"` // masks applied to attribute values (last4, hash, placeholder, year)
 	Captions           CaptionMap           `json:"captions"`
 }
 type PgFunction struct {
@@ -548,13 +571,16 @@ type PgFunction struct {
 	Captions       CaptionMap           `json:"captions"`
 }
 type PgFunctionSchedule struct {
-	Id            uuid.UUID `json:"id"`
-	AtSecond      int       `json:"atSecond"`
-	AtMinute      int       `json:"atMinute"`
-	AtHour        int       `json:"atHour"`
-	AtDay         int       `json:"atDay"`
-	IntervalType  string    `json:"intervalType"`
-	IntervalValue int       `json:"intervalValue"`
+	Id                 uuid.UUID   `json:"id"`
+	AtSecond           int         `json:"atSecond"`
+	AtMinute           int         `json:"atMinute"`
+	AtHour             int         `json:"atHour"`
+	AtDay              int         `json:"atDay"`
+	AttributeIdExclude pgtype.UUID `json:"attributeIdExclude"` // date attribute, its values are days on which the schedule does not run (e. g. public holidays)
+	CronExpr           pgtype.Text `json:"cronExpr"`           // cron expression with seconds, used for interval type 'cron'
+	IntervalType       string      `json:"intervalType"`
+	IntervalValue      int         `json:"intervalValue"`
+	TimeZone           pgtype.Text `json:"timeZone"` // IANA time zone the schedule is evaluated in, server time zone if not set
 }
 type PgTrigger struct {
 	Id            uuid.UUID `json:"id"`
@@ -603,20 +629,23 @@ type PresetValue struct {
 	Value         pgtype.Text `json:"value"`
 }
 type Relation struct {
-	Id                uuid.UUID        `json:"id"`
-	ModuleId          uuid.UUID        `json:"moduleId"`
-	AttributeIdPk     uuid.UUID        `json:"attributeIdPk"`  // read only, ID of PK attribute
-	Name              string           `json:"name"`           // unique (within module) relation name
-	Comment           pgtype.Text      `json:"comment"`        // author comment
-	Encryption        bool             `json:"encryption"`     // relation supports encrypted attribute values
-	RetentionCount    pgtype.Int4      `json:"retentionCount"` // minimum number of retained change events
-	RetentionDays     pgtype.Int4      `json:"retentionDays"`  // minimum age of retained change events
-	Captions          CaptionMap       `json:"captions"`
-	Attributes        []Attribute      `json:"attributes"`        // read only, all relation attributes
-	AttributeIdsTitle []uuid.UUID      `json:"attributeIdsTitle"` // ordered list of attributes that are used as record title
-	Indexes           []PgIndex        `json:"indexes"`           // read only, all relation indexes
-	Policies          []RelationPolicy `json:"policies"`          // read only, all relation policies
-	Presets           []Preset         `json:"presets"`           // read only, all relation presets
+	Id                 uuid.UUID        `json:"id"`
+	ModuleId           uuid.UUID        `json:"moduleId"`
+	AttributeIdPk      uuid.UUID        `json:"attributeIdPk"`  // read only, ID of PK attribute
+	Name               string           `json:"name"`           // unique (within module) relation name
+	Comment            pgtype.Text      `json:"comment"`        // author comment
+	Encryption         bool             `json:"encryption"`     // relation supports encrypted attribute values
+	RetentionCount     pgtype.Int4      `json:"retentionCount"` // minimum number of retained change events
+	RetentionDays      pgtype.Int4      `json:"retentionDays"`  // minimum age of retained change events
+	RecycleDays        pgtype.Int4      `json:"recycleDays"`    // days to keep deleted records in recycle bin, recycle bin is disabled if empty
+	Captions           CaptionMap       `json:"captions"`
+	Attributes         []Attribute      `json:"attributes"`         // read only, all relation attributes
+	AttributeIdsTitle  []uuid.UUID      `json:"attributeIdsTitle"`  // ordered list of attributes that are used as record title
+	AttributeIdsSearch []uuid.UUID      `json:"attributeIdsSearch"` // attributes whose values are included in global search
+	FormIdSearch       pgtype.UUID      `json:"formIdSearch"`       // form to open records found by global search
+	Indexes            []PgIndex        `json:"indexes"`            // read only, all relation indexes
+	Policies           []RelationPolicy `json:"policies"`           // read only, all relation policies
+	Presets            []Preset         `json:"presets"`            // read only, all relation presets
 
 	// legacy
 	Triggers []PgTrigger `json:"triggers"` // moved to module pgTriggers
@@ -634,6 +663,7 @@ type Release struct {
 	BuildApp    int64        `json:"buildApp"`
 	DateCreated int64        `json:"dateCreated"`
 	Logs        []ReleaseLog `json:"logs"`
+	Migrations  []uuid.UUID  `json:"migrations"` // PG functions executed once when upgrading across this build, in order
 }
 type ReleaseLog struct {
 	Category int    `json:"category"` // index of module release log categories
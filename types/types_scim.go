@@ -0,0 +1,76 @@
+package types
+
+import "encoding/json"
+
+// SCIM 2.0 resources, as defined by RFC 7643/7644
+type ScimAddress struct {
+	Formatted string `json:"formatted,omitempty"`
+	Locality  string `json:"locality,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Primary   bool   `json:"primary,omitempty"`
+}
+type ScimEnterpriseUser struct {
+	Department   string `json:"department,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+type ScimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+type ScimGroup struct {
+	Schemas     []string         `json:"schemas"`
+	Id          string           `json:"id"`
+	ExternalId  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []ScimMultiValue `json:"members"`
+	Meta        ScimMeta         `json:"meta"`
+}
+type ScimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+type ScimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+type ScimMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+type ScimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+type ScimPatch struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []ScimPatchOperation `json:"Operations"`
+}
+type ScimPatchOperation struct {
+	Op    string          `json:"op"`   // add, remove, replace (case insensitive)
+	Path  string          `json:"path"` // optional attribute path, example: 'emails[type eq "work"].value'
+	Value json.RawMessage `json:"value"`
+}
+type ScimUser struct {
+	Schemas      []string            `json:"schemas"`
+	Id           string              `json:"id"`
+	ExternalId   string              `json:"externalId,omitempty"`
+	UserName     string              `json:"userName"`
+	Active       *bool               `json:"active,omitempty"`   // if not set, login is active
+	Password     string              `json:"password,omitempty"` // write only, is never returned
+	DisplayName  string              `json:"displayName,omitempty"`
+	Name         ScimName            `json:"name"`
+	Emails       []ScimMultiValue    `json:"emails,omitempty"`
+	PhoneNumbers []ScimMultiValue    `json:"phoneNumbers,omitempty"`
+	Addresses    []ScimAddress       `json:"addresses,omitempty"`
+	Groups       []ScimMultiValue    `json:"groups,omitempty"` // read only, managed via group resources
+	Enterprise   *ScimEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta         ScimMeta            `json:"meta"`
+}
@@ -2,6 +2,9 @@ package types
 
 import (
 	"encoding/json"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // a module transfer file
@@ -22,3 +25,49 @@ type TransferFileVerify struct {
 	Content   json.RawMessage `json:"content"`   // content to check signature against
 	Signature string          `json:"signature"` // signature of content hash
 }
+
+// changes a module import would apply, compared to the installed module
+type TransferDiff struct {
+	ModuleId         uuid.UUID            `json:"moduleId"`
+	ModuleName       string               `json:"moduleName"`
+	IsNew            bool                 `json:"isNew"`            // module is not installed yet
+	ReleaseBuildFrom int                  `json:"releaseBuildFrom"` // installed build, 0 if new
+	ReleaseBuildTo   int                  `json:"releaseBuildTo"`   // build to import
+	Changes          []TransferDiffChange `json:"changes"`
+	Migrations       []string             `json:"migrations"` // data migrations to be executed, in order
+}
+type TransferDiffChange struct {
+	Entity       string      `json:"entity"` // attribute, form, pgFunction, pgIndex, pgTrigger, relation, role
+	EntityId     uuid.UUID   `json:"entityId"`
+	Name         string      `json:"name"`         // entity name, attributes & indexes are prefixed by their relation name
+	Action       string      `json:"action"`       // added, changed, removed
+	Details      []string    `json:"details"`      // changed entity properties, for changed entities
+	Destructive  []string    `json:"destructive"`  // changes that can lose data or fail due to existing data (dropped, lengthReduced, notNullAdded, typeChanged, uniqueAdded)
+	RowsAffected pgtype.Int8 `json:"rowsAffected"` // number of existing records affected by destructive changes, if known
+}
+
+// result of an import dry-run, import is executed but always rolled back
+type TransferDryRun struct {
+	Modules []TransferDiff `json:"modules"`
+	Error   pgtype.Text    `json:"error"` // error the import would fail with, empty if successful
+}
+
+// archived version of an installed module, stored before an import replaced it
+type ModuleArchive struct {
+	Id                     int64       `json:"id"`
+	ModuleId               uuid.UUID   `json:"moduleId"`
+	ReleaseBuild           int         `json:"releaseBuild"`           // archived build
+	ReleaseBuildApp        int         `json:"releaseBuildApp"`        // platform build the archived build was released for
+	ReleaseBuildReplacedBy pgtype.Int4 `json:"releaseBuildReplacedBy"` // build that replaced the archived one, empty if unknown
+	DateCreated            int64       `json:"dateCreated"`
+}
+
+// data migration of a module release, executed in this instance
+type ModuleMigration struct {
+	ModuleId         uuid.UUID `json:"moduleId"`
+	Build            int       `json:"build"` // release build the migration belongs to
+	PgFunctionId     uuid.UUID `json:"pgFunctionId"`
+	ReleaseBuildFrom int       `json:"releaseBuildFrom"` // installed build before upgrade
+	ReleaseBuildTo   int       `json:"releaseBuildTo"`   // build upgraded to
+	DateExecuted     int64     `json:"dateExecuted"`
+}
@@ -16,127 +16,157 @@ export default {
 			</div>
 			
 			<!-- system configuration -->
-			<router-link class="entry clickable" tag="div" to="/admin/config">
+			<router-link class="entry clickable" tag="div" to="/admin/config" v-if="adminPermissions.includes('system')">
 				<img src="images/server.png" />
 				<span>{{ capApp.navigationConfig }}</span>
 			</router-link>
 			
 			<!-- logins -->
-			<router-link class="entry clickable" tag="div" to="/admin/logins">
+			<router-link class="entry clickable" tag="div" to="/admin/logins" v-if="adminPermissions.includes('logins')">
 				<img src="images/person.png" />
 				<span>{{ capApp.navigationLogins }}</span>
 			</router-link>
 			
 			<!-- login sessions -->
-			<router-link class="entry clickable" tag="div" to="/admin/login-sessions">
+			<router-link class="entry clickable" tag="div" to="/admin/login-sessions" v-if="adminPermissions.includes('logins')">
 				<img src="images/personServer.png" />
 				<span>{{ capApp.navigationLoginSessions }}</span>
 			</router-link>
 			
 			<!-- login templates -->
-			<router-link class="entry clickable" tag="div" to="/admin/login-templates">
+			<router-link class="entry clickable" tag="div" to="/admin/login-templates" v-if="adminPermissions.includes('logins')">
 				<img src="images/personTemplate.png" />
 				<span>{{ capApp.navigationLoginTemplates }}</span>
 			</router-link>
 			
 			<!-- roles -->
-			<router-link class="entry clickable" tag="div" to="/admin/roles">
+			<router-link class="entry clickable" tag="div" to="/admin/roles" v-if="adminPermissions.includes('logins')">
 				<img src="images/admin.png" />
 				<span>{{ capApp.navigationRoles }}</span>
 			</router-link>
 			
 			<!-- modules -->
-			<router-link class="entry clickable" tag="div" to="/admin/modules">
+			<router-link class="entry clickable" tag="div" to="/admin/modules" v-if="adminPermissions.includes('system')">
 				<img src="images/builder.png" />
 				<span>{{ capApp.navigationModules }}</span>
 			</router-link>
 			
 			<!-- mail accounts -->
-			<router-link class="entry clickable" tag="div" to="/admin/mail-accounts">
+			<router-link class="entry clickable" tag="div" to="/admin/mail-accounts" v-if="adminPermissions.includes('mails')">
 				<img src="images/mail2.png" />
 				<span>{{ capApp.navigationMailAccounts }}</span>
 			</router-link>
 			
 			<!-- mail spooler -->
-			<router-link class="entry clickable" tag="div" to="/admin/mail-spooler">
+			<router-link class="entry clickable" tag="div" to="/admin/mail-spooler" v-if="adminPermissions.includes('mails')">
 				<img src="images/mail_spool.png" />
 				<span>{{ capApp.navigationMailSpooler }}</span>
 			</router-link>
 			
 			<!-- mail traffic -->
-			<router-link class="entry clickable" tag="div" to="/admin/mail-traffic">
+			<router-link class="entry clickable" tag="div" to="/admin/mail-traffic" v-if="adminPermissions.includes('mails')">
 				<img src="images/mail_clock.png" />
 				<span>{{ capApp.navigationMailTraffic }}</span>
 			</router-link>
 			
 			<!-- backups -->
-			<router-link class="entry clickable" tag="div" to="/admin/backups">
+			<router-link class="entry clickable" tag="div" to="/admin/backups" v-if="adminPermissions.includes('system')">
 				<img src="images/backup.png" />
 				<span>{{ capApp.navigationBackups }}</span>
 			</router-link>
 			
 			<!-- files -->
-			<router-link class="entry clickable" tag="div" to="/admin/files">
+			<router-link class="entry clickable" tag="div" to="/admin/files" v-if="adminPermissions.includes('system')">
 				<img src="images/files.png" />
 				<span>{{ capApp.navigationFiles }}</span>
 			</router-link>
 			
 			<!-- logs -->
-			<router-link class="entry clickable" tag="div" to="/admin/logs">
+			<router-link class="entry clickable" tag="div" to="/admin/logs" v-if="adminPermissions.includes('logs')">
 				<img src="images/fileText.png" />
 				<span>{{ capApp.navigationLogs }}</span>
 			</router-link>
 			
+			<!-- audit log -->
+			<router-link class="entry clickable" tag="div" to="/admin/audit" v-if="adminPermissions.includes('logs')">
+				<img src="images/keyLocked.png" />
+				<span>{{ capApp.navigationAudit }}</span>
+			</router-link>
+			
+			<!-- data privacy -->
+			<router-link class="entry clickable" tag="div" to="/admin/privacy" v-if="adminPermissions.includes('system')">
+				<img src="images/personCog.png" />
+				<span>{{ capApp.navigationPrivacy }}</span>
+			</router-link>
+			
+			<!-- global search -->
+			<router-link class="entry clickable" tag="div" to="/admin/search" v-if="adminPermissions.includes('system')">
+				<img src="images/search.png" />
+				<span>{{ capApp.navigationSearch }}</span>
+			</router-link>
+			
 			<!-- scheduler -->
-			<router-link class="entry clickable" tag="div" to="/admin/scheduler">
+			<router-link class="entry clickable" tag="div" to="/admin/scheduler" v-if="adminPermissions.includes('system')">
 				<img src="images/clock.png" />
 				<span>{{ capApp.navigationScheduler }}</span>
 			</router-link>
 			
 			<!-- caption map -->
-			<router-link class="entry clickable" tag="div" to="/admin/caption-map">
+			<router-link class="entry clickable" tag="div" to="/admin/caption-map" v-if="adminPermissions.includes('system')">
 				<img src="images/languages.png" />
 				<span>{{ capApp.navigationCaptionMap }}</span>
 			</router-link>
 			
 			<!-- REI3 Professional -->
-			<div class="entry isTitle separator" tag="div">
+			<div class="entry isTitle separator" tag="div" v-if="adminPermissions.includes('system')">
 				<img src="images/icon_naked.png" />
 				<span>{{ licenseTitle }}</span>
 			</div>
 			
 			<!-- activation -->
-			<router-link class="entry clickable" tag="div" to="/admin/license">
+			<router-link class="entry clickable" tag="div" to="/admin/license" v-if="adminPermissions.includes('system')">
 				<img src="images/key.png" />
 				<span>{{ capApp.navigationActivation }}</span>
 			</router-link>
 			
 			<!-- system message -->
-			<router-link class="entry clickable" tag="div" to="/admin/system-msg" :class="{ inactive:!activated }">
+			<router-link class="entry clickable" tag="div" to="/admin/system-msg" v-if="adminPermissions.includes('system')" :class="{ inactive:!activated }">
 				<img src="images/warning.png" />
 				<span>{{ capApp.navigationSystemMsg }}</span>
 			</router-link>
 			
 			<!-- customizing -->
-			<router-link class="entry clickable" tag="div" to="/admin/custom" :class="{ inactive:!activated }">
+			<router-link class="entry clickable" tag="div" to="/admin/custom" v-if="adminPermissions.includes('system')" :class="{ inactive:!activated }">
 				<img src="images/colors.png" />
 				<span>{{ capApp.navigationCustom }}</span>
 			</router-link>
 			
 			<!-- LDAP -->
-			<router-link class="entry clickable" tag="div" to="/admin/ldaps" :class="{ inactive:!activated }">
+			<router-link class="entry clickable" tag="div" to="/admin/ldaps" v-if="adminPermissions.includes('system')" :class="{ inactive:!activated }">
 				<img src="images/hierarchy.png" />
 				<span>{{ capApp.navigationLdaps }}</span>
 			</router-link>
 			
 			<!-- OAuth clients -->
-			<router-link class="entry clickable" tag="div" to="/admin/oauth-clients" :class="{ inactive:!activated }">
+			<router-link class="entry clickable" tag="div" to="/admin/oauth-clients" v-if="adminPermissions.includes('system')" :class="{ inactive:!activated }">
 				<img src="images/lockCog.png" />
 				<span>{{ capApp.navigationOauthClients }}</span>
 			</router-link>
 			
+			<!-- SAML identity providers -->
+			<router-link class="entry clickable" tag="div" to="/admin/saml-idps" v-if="adminPermissions.includes('system')" :class="{ inactive:!activated }">
+				<img src="images/lockCog.png" />
+				<span>{{ capApp.navigationSamlIdps }}</span>
+			</router-link>
+			
+			<!-- SCIM clients -->
+			<router-link class="entry clickable" tag="div" to="/admin/scim-clients" v-if="adminPermissions.includes('system')" :class="{ inactive:!activated }">
+				<img src="images/personServer.png" />
+				<span>{{ capApp.navigationScimClients }}</span>
+			</router-link>
+			
 			<!-- cluster -->
-			<router-link class="entry clickable" tag="div" to="/admin/cluster" :class="{ inactive:!activated }">
+			<router-link class="entry clickable" tag="div" to="/admin/cluster" v-if="adminPermissions.includes('system')" :class="{ inactive:!activated }">
 				<img src="images/cluster.png" />
 				<span>{{ capApp.navigationCluster }}</span>
 			</router-link>
@@ -173,14 +203,23 @@ export default {
 		};
 	},
 	mounted() {
-		if(!this.isAdmin)
+		if(!this.isAdminAny)
 			return this.$router.push('/');
 		
+		// default admin page is system configuration, go to first accessible page instead
+		if(!this.adminPermissions.includes('system') && this.$route.path === '/admin/config') {
+			if(this.adminPermissions.includes('logins')) return this.$router.replace('/admin/logins');
+			if(this.adminPermissions.includes('mails'))  return this.$router.replace('/admin/mail-accounts');
+			if(this.adminPermissions.includes('logs'))   return this.$router.replace('/admin/logs');
+			return this.$router.replace('/builder');
+		}
+		
 		this.getConcurrentLogins();
 		this.ready = true;
 	},
 	computed:{
 		contentTitle:s => {
+			if(s.$route.path.includes('audit'))           return s.capApp.navigationAudit;
 			if(s.$route.path.includes('backups'))         return s.capApp.navigationBackups;
 			if(s.$route.path.includes('caption-map'))     return s.capApp.navigationCaptionMap;
 			if(s.$route.path.includes('cluster'))         return s.capApp.navigationCluster;
@@ -198,8 +237,12 @@ export default {
 			if(s.$route.path.includes('mail-traffic'))    return s.capApp.navigationMailTraffic;
 			if(s.$route.path.includes('modules'))         return s.capApp.navigationModules;
 			if(s.$route.path.includes('oauth-clients'))   return s.capApp.navigationOauthClients;
+			if(s.$route.path.includes('privacy'))         return s.capApp.navigationPrivacy;
 			if(s.$route.path.includes('roles'))           return s.capApp.navigationRoles;
+			if(s.$route.path.includes('saml-idps'))       return s.capApp.navigationSamlIdps;
 			if(s.$route.path.includes('scheduler'))       return s.capApp.navigationScheduler;
+			if(s.$route.path.includes('scim-clients'))    return s.capApp.navigationScimClients;
+			if(s.$route.path.includes('search'))          return s.capApp.navigationSearch;
 			if(s.$route.path.includes('system-msg'))      return s.capApp.navigationSystemMsg;
 			return '';
 		},
@@ -208,13 +251,14 @@ export default {
 			:`${s.capApp.navigationLicense} (${s.concurrentLogins}/${s.license.loginCount} - ${s.concurrentLoginsLimited}/${s.license.loginCount * s.limitedFactor})`,
 		
 		// stores
-		activated:    s => s.$store.getters['local/activated'],
-		bgStyle:      s => s.$store.getters.colorMenuStyle,
-		capApp:       s => s.$store.getters.captions.admin,
-		colorMenu:    s => s.$store.getters.colorMenu,
-		isAdmin:      s => s.$store.getters.isAdmin,
-		license:      s => s.$store.getters.license,
-		limitedFactor:s => s.$store.getters.constants.loginLimitedFactor
+		activated:       s => s.$store.getters['local/activated'],
+		adminPermissions:s => s.$store.getters.adminPermissions,
+		bgStyle:         s => s.$store.getters.colorMenuStyle,
+		capApp:          s => s.$store.getters.captions.admin,
+		colorMenu:       s => s.$store.getters.colorMenu,
+		isAdminAny:      s => s.$store.getters.isAdminAny,
+		license:         s => s.$store.getters.license,
+		limitedFactor:   s => s.$store.getters.constants.loginLimitedFactor
 	},
 	methods:{
 		// backend calls
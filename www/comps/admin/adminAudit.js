@@ -0,0 +1,237 @@
+import MyInputDateWrap from '../inputDateWrap.js';
+import MyInputOffset   from '../inputOffset.js';
+import {getUnixFormat} from '../shared/time.js';
+
+export default {
+	name:'my-admin-audit',
+	components:{
+		MyInputDateWrap,
+		MyInputOffset
+	},
+	template:`<div class="contentBox admin-logs grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/keyLocked.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area nowrap default-inputs">
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+				<my-input-date-wrap class="long"
+					@set-unix-from="setDate($event,true)"
+					@set-unix-to="setDate($event,false)"
+					:isDate="true"
+					:isTime="true"
+					:isRange="true"
+					:isValid="true"
+					:unixFrom="unixFrom"
+					:unixTo="unixTo"
+				/>
+			</div>
+			<div class="area">
+				<my-input-offset
+					@input="offset = $event;get()"
+					:caption="true"
+					:limit="limit"
+					:offset="offset"
+					:total="total"
+				/>
+			</div>
+			<div class="area gap default-inputs">
+				<input class="short"
+					v-model="byString"
+					@keyup.enter="offset = 0;get()"
+					:placeholder="capGen.textSearch"
+				/>
+				<input class="short"
+					v-model="ressource"
+					@keyup.enter="offset = 0;get()"
+					:placeholder="capApp.ressource"
+				/>
+				<select class="short" v-model.number="limit" @change="offset = 0;get()">
+					<option value="100">100</option>
+					<option value="250">250</option>
+					<option value="500">500</option>
+					<option value="1000">1000</option>
+				</select>
+				<my-button image="ok.png"
+					@trigger="verify"
+					:caption="capApp.button.verify"
+				/>
+				<a target="_blank" :href="exportUrl">
+					<my-button image="download.png"
+						:caption="capApp.button.export"
+					/>
+				</a>
+			</div>
+		</div>
+
+		<div class="content admin-logs-content no-padding">
+			<div class="admin-logs-table">
+				<table class="generic-table bright sticky-top">
+					<thead>
+						<tr class="title">
+							<th class="minimum">{{ capGen.button.show }}</th>
+							<th class="minimum">{{ capApp.date }}</th>
+							<th class="minimum">{{ capApp.login }}</th>
+							<th class="minimum">{{ capApp.address }}</th>
+							<th class="minimum">{{ capApp.ressource }}</th>
+							<th class="minimum">{{ capApp.action }}</th>
+							<th class="minimum">{{ capApp.objectId }}</th>
+							<th>{{ capApp.diff }}</th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-if="logs.length === 0">
+							<td colspan="999">{{ capGen.nothingThere }}</td>
+						</tr>
+
+						<tr v-for="(l,i) in logs">
+							<td>
+								<my-button image="open.png"
+									@trigger="showEntry(i)"
+								/>
+							</td>
+							<td class="minimum">{{ displayDate(l.date) }}</td>
+							<td class="minimum">{{ l.loginName }}</td>
+							<td class="minimum">{{ l.address }}</td>
+							<td class="minimum">{{ l.ressource }}</td>
+							<td class="minimum">{{ l.action }}</td>
+							<td class="minimum">{{ l.objectId }}</td>
+							<td>{{ displayDiff(l) }}</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			// inputs
+			byString:'',
+			limit:100,
+			offset:0,
+			ressource:'',
+			total:0,
+			unixFrom:null,
+			unixTo:null,
+
+			// data
+			logs:[]
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+
+		// set date range for log retrieval (7 days ago to now)
+		let d = new Date();
+		d.setDate(d.getDate()-7);
+		d.setHours(0,0,0);
+		this.setDate(Math.floor(d.getTime() / 1000),true);
+	},
+	computed:{
+		exportUrl:s => {
+			let url = `/audit/export?token=${s.token}`;
+			if(s.unixFrom !== null) url += `&date_from=${s.unixFrom}`;
+			if(s.unixTo   !== null) url += `&date_to=${s.unixTo}`;
+			return url;
+		},
+
+		// stores
+		settings:(s) => s.$store.getters.settings,
+		token:   (s) => s.$store.getters['local/token'],
+		capApp:  (s) => s.$store.getters.captions.admin.audit,
+		capGen:  (s) => s.$store.getters.captions.generic
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+
+		// presentation
+		displayDate(date) {
+			let format = [this.settings.dateFormat,'H:i:S'];
+			return this.getUnixFormat(date,format.join(' '));
+		},
+		displayDiff(l) {
+			if(l.diff === null)
+				return '';
+
+			return JSON.parse(l.diff).map(v => v.key).join(', ');
+		},
+
+		// actions
+		setDate(unix,from) {
+			if(from) {
+				this.unixFrom = unix;
+			}
+			else {
+				this.unixTo = unix;
+
+				// add 23:59:59 to to date, if from and to date are equal
+				let d = new Date(this.unixTo * 1000);
+				if(d.getHours() === 0 && d.getMinutes() === 0 && d.getSeconds() === 0)
+					this.unixTo += 86399;
+			}
+			this.offset = 0;
+			this.get();
+		},
+		showEntry(index) {
+			const l = this.logs[index];
+			const parts = [
+				`${this.capApp.payload}:`,
+				JSON.stringify(JSON.parse(l.payload),null,2)
+			];
+			if(l.diff !== null)
+				parts.push('',`${this.capApp.diff}:`,JSON.stringify(JSON.parse(l.diff),null,2));
+
+			parts.push('',`${this.capApp.hash}: ${l.hash}`,`${this.capApp.hashPrev}: ${l.hashPrev}`);
+
+			this.$store.commit('dialog',{
+				captionBody:parts.join('\n'),
+				textDisplay:'textarea',
+				width:800
+			});
+		},
+
+		// backend calls
+		get() {
+			ws.send('audit','get',{
+				byString:this.byString,
+				dateFrom:this.unixFrom,
+				dateTo:this.unixTo,
+				limit:this.limit,
+				offset:this.offset,
+				ressource:this.ressource
+			},true).then(
+				res => {
+					this.logs  = res.payload.logs;
+					this.total = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		verify() {
+			ws.send('audit','verify',{},true).then(
+				res => {
+					const broken = res.payload.idBroken !== null;
+					const msg    = broken ? this.capApp.dialog.verifyBroken : this.capApp.dialog.verifyOk;
+
+					this.$store.commit('dialog',{
+						captionBody:msg
+							.replace('{COUNT}',res.payload.count)
+							.replace('{ID}',res.payload.idBroken),
+						image:broken ? 'warning.png' : 'ok.png'
+					});
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
\ No newline at end of file
@@ -0,0 +1,246 @@
+import {deepIsEqual} from '../shared/generic.js';
+import {
+	dialogCloseAsk,
+	dialogDeleteAsk
+} from '../shared/dialog.js';
+
+export default {
+	name:'my-admin-backup-target',
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="closeAsk">
+	
+		<div class="contentBox scroll float">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/backup.png" />
+					<h1 class="title">{{ isNew ? capApp.titleNew : capApp.title.replace('{NAME}',inputs.name) }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="closeAsk"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="canSave"
+						:caption="isNew ? capGen.button.create : capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						v-if="!isNew"
+						@trigger="reset"
+						:active="isChanged"
+						:caption="capGen.button.refresh"
+					/>
+					<my-button image="ok.png"
+						@trigger="test"
+						:active="canTest"
+						:caption="capApp.button.test"
+					/>
+				</div>
+				<div class="area">
+					<my-button image="delete.png"
+						v-if="!isNew"
+						@trigger="dialogDeleteAsk(del,capApp.dialog.delete)"
+						:cancel="true"
+						:caption="capGen.button.delete"
+					/>
+				</div>
+			</div>
+			
+			<div class="content no-padding default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capGen.name }}*</td>
+							<td><input v-model="inputs.name" /></td>
+							<td></td>
+						</tr>
+						<tr>
+							<td>{{ capGen.active }}</td>
+							<td><my-bool v-model="inputs.active" /></td>
+							<td></td>
+						</tr>
+						<tr>
+							<td>{{ capGen.type }}*</td>
+							<td>
+								<select v-model="inputs.content" :disabled="!isNew">
+									<option value="local">{{ capApp.option.content.local }}</option>
+									<option value="sftp">{{ capApp.option.content.sftp }}</option>
+									<option value="s3">{{ capApp.option.content.s3 }}</option>
+								</select>
+							</td>
+							<td>{{ capApp.contentHint[inputs.content] }}</td>
+						</tr>
+						<tr v-if="!isLocal">
+							<td>{{ isS3 ? capApp.endpoint : capApp.hostName }}*</td>
+							<td><input v-model="inputs.hostName" :placeholder="isS3 ? 'https://s3.example.com' : ''" /></td>
+							<td>{{ isS3 ? capApp.endpointHint : '' }}</td>
+						</tr>
+						<tr v-if="isSftp">
+							<td>{{ capApp.hostPort }}*</td>
+							<td><input v-model.number="inputs.hostPort" /></td>
+							<td></td>
+						</tr>
+						<tr v-if="isSftp">
+							<td>{{ capApp.hostKey }}*</td>
+							<td><textarea class="key" v-model="inputs.hostKey"></textarea></td>
+							<td>{{ capApp.hostKeyHint }}</td>
+						</tr>
+						<tr v-if="isS3">
+							<td>{{ capApp.bucket }}*</td>
+							<td><input v-model="inputs.bucket" /></td>
+							<td></td>
+						</tr>
+						<tr v-if="isS3">
+							<td>{{ capApp.region }}*</td>
+							<td><input v-model="inputs.region" /></td>
+							<td></td>
+						</tr>
+						<tr>
+							<td>{{ isLocal ? capApp.pathLocal : capApp.path }}{{ isLocal ? '*' : '' }}</td>
+							<td><input v-model="inputs.path" /></td>
+							<td>{{ isLocal ? capApp.pathLocalHint : capApp.pathHint }}</td>
+						</tr>
+						<tr v-if="!isLocal">
+							<td>{{ isS3 ? capApp.accessKey : capGen.username }}*</td>
+							<td><input v-model="inputs.username" /></td>
+							<td></td>
+						</tr>
+						<tr v-if="!isLocal">
+							<td>{{ isS3 ? capApp.secretKey : capGen.password }}{{ isS3 ? '*' : '' }}</td>
+							<td><input v-model="inputs.password" type="password" /></td>
+							<td>{{ capApp.secretHint }}</td>
+						</tr>
+						<tr v-if="isSftp">
+							<td>{{ capGen.keyPrivate }}</td>
+							<td><textarea class="key" v-model="inputs.privateKey"></textarea></td>
+							<td>{{ capApp.privateKeyHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.keepCount }}*</td>
+							<td><input v-model.number="inputs.keepCount" /></td>
+							<td>{{ capApp.keepCountHint }}</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		id:         { type:Number, required:true },
+		targetIdMap:{ type:Object, required:true }
+	},
+	emits:['close'],
+	watch:{
+		id:{
+			handler(v) { this.reset(); },
+			immediate:true
+		},
+	},
+	data() {
+		return {
+			inputs:{},
+			isReady:false
+		};
+	},
+	computed:{
+		inputsOrg:s => s.isNew ? {
+			id:0,
+			name:'',
+			content:'sftp',
+			active:true,
+			hostName:'',
+			hostPort:22,
+			hostKey:'',
+			path:'',
+			bucket:'',
+			region:'',
+			username:'',
+			password:'',
+			privateKey:'',
+			keepCount:7
+		} : s.targetIdMap[s.id],
+		
+		// simple states
+		canSave:s => s.isReady && s.isChanged && s.canTest,
+		canTest:s =>
+			s.isReady &&
+			s.inputs.name !== '' &&
+			Number.isInteger(s.inputs.keepCount) && s.inputs.keepCount >= 0 && (
+				(s.isLocal && s.inputs.path !== '') ||
+				(s.isS3    && s.inputs.hostName !== '' && s.inputs.bucket !== '' && s.inputs.region !== '' &&
+					s.inputs.username !== '' && s.inputs.password !== '') ||
+				(s.isSftp  && s.inputs.hostName !== '' && s.inputs.hostKey !== '' && s.inputs.username !== '' &&
+					(s.inputs.password !== '' || s.inputs.privateKey !== ''))
+			),
+		isChanged:s => !s.deepIsEqual(s.inputsOrg,s.inputs),
+		isLocal:  s => s.inputs.content === 'local',
+		isNew:    s => s.id             === 0,
+		isS3:     s => s.inputs.content === 's3',
+		isSftp:   s => s.inputs.content === 'sftp',
+		
+		// stores
+		capApp:s => s.$store.getters.captions.admin.backups.target,
+		capGen:s => s.$store.getters.captions.generic
+	},
+	mounted() {
+		window.addEventListener('keydown',this.handleHotkeys);
+	},
+	unmounted() {
+		window.removeEventListener('keydown',this.handleHotkeys);
+	},
+	methods:{
+		// externals
+		deepIsEqual,
+		dialogCloseAsk,
+		dialogDeleteAsk,
+		
+		handleHotkeys(e) {
+			if(e.ctrlKey && e.key === 's') {
+				if(this.canSave)
+					this.set();
+				
+				e.preventDefault();
+			}
+			if(e.key === 'Escape') {
+				this.closeAsk();
+				e.preventDefault();
+			}
+		},
+		
+		// actions
+		closeAsk() {
+			this.dialogCloseAsk(this.close,this.isChanged);
+		},
+		close() {
+			this.$emit('close');
+		},
+		reset() {
+			this.inputs  = JSON.parse(JSON.stringify(this.inputsOrg));
+			this.isReady = true;
+		},
+		
+		// backend calls
+		del() {
+			ws.send('backup','delTarget',this.id,true).then(
+				this.close,
+				this.$root.genericError
+			);
+		},
+		set() {
+			ws.send('backup','setTarget',this.inputs,true).then(
+				this.close,
+				this.$root.genericError
+			);
+		},
+		test() {
+			ws.send('backup','testTarget',this.inputs,true).then(
+				() => this.$store.commit('dialog',{ captionBody:this.capApp.testOk }),
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -1,8 +1,10 @@
-import {getUnixFormat} from '../shared/time.js';
+import MyAdminBackupTarget from './adminBackupTarget.js';
+import {getUnixFormat}     from '../shared/time.js';
 export {MyAdminBackups as default};
 
 let MyAdminBackups = {
 	name:'my-admin-backups',
+	components:{ MyAdminBackupTarget },
 	template:`<div class="admin-backups contentBox grow">
 		
 		<div class="top">
@@ -68,9 +70,102 @@ let MyAdminBackups = {
 							<td><input class="short" v-model="configInput.backupCountMonthly" /></td>
 						</template>
 					</tr>
+					
+					<!-- restore verification -->
+					<tr>
+						<td>{{ capApp.verify }}</td>
+						<td><my-bool-string-number v-model="configInput.backupVerify" /></td>
+					</tr>
+					
+					<!-- encryption -->
+					<tr>
+						<td>{{ capApp.encryptKey }}</td>
+						<td colspan="3">
+							<textarea class="key"
+								v-model="configInput.backupEncryptKey"
+								placeholder="-----BEGIN PUBLIC KEY-----"
+							></textarea>
+						</td>
+					</tr>
 				</tbody>
 			</table>
 			<div class="note">{{ capApp.dirNote }}</div>
+			<div class="note">{{ capApp.verifyNote }}</div>
+			<div class="note">{{ capApp.encryptKeyNote }}</div>
+			<br />
+			
+			<!-- custom jobs -->
+			<my-label image="time.png" :caption="capApp.jobs" :large="true" />
+			<br />
+			
+			<table class="generic-table bright default-inputs shade sets">
+				<thead>
+					<tr>
+						<th>{{ capGen.name }}</th>
+						<th>{{ capApp.scope }}</th>
+						<th>{{ capApp.intervalHours }}</th>
+						<th>{{ capApp.count }}</th>
+						<th>{{ capGen.active }}</th>
+						<th></th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="(j,i) in jobsInput">
+						<td><input v-model="j.name" /></td>
+						<td>
+							<select v-model="j.scope">
+								<option v-for="s in scopes" :value="s">{{ capApp[s] }}</option>
+							</select>
+						</td>
+						<td><input class="short" v-model.number="j.intervalHours" /></td>
+						<td><input class="short" v-model.number="j.keepCount" /></td>
+						<td><my-bool v-model="j.active" /></td>
+						<td>
+							<my-button image="delete.png"
+								@trigger="jobsInput.splice(i,1)"
+								:cancel="true"
+								:naked="true"
+							/>
+						</td>
+					</tr>
+				</tbody>
+			</table>
+			<div>
+				<my-button image="add.png"
+					@trigger="jobAdd"
+					:caption="capGen.button.add"
+				/>
+			</div>
+			<div class="note">{{ capApp.jobsNote }}</div>
+			<br />
+			
+			<!-- remote targets -->
+			<my-label image="server.png" :caption="capApp.targets" :large="true" />
+			<br />
+			
+			<div class="generic-entry-list wide">
+				<div class="entry clickable"
+					@click="targetIdOpen = 0"
+					:title="capGen.button.new"
+				>
+					<div class="row gap centered">
+						<img src="images/add.png" />
+						<span>{{ capGen.button.new }}</span>
+					</div>
+				</div>
+				<div class="entry clickable"
+					v-for="t in targetIdMap"
+					@click="targetIdOpen = t.id"
+					:key="t.id"
+					:title="t.name"
+				>
+					<div class="lines">
+						<span>{{ t.name }}</span>
+						<span class="subtitle">{{ displayTarget(t) }}</span>
+					</div>
+				</div>
+			</div>
+			<div class="note">{{ capApp.targetsNote }}</div>
 			<br />
 			
 			<my-label image="backup.png" :caption="capApp.list" :large="true" />
@@ -83,27 +178,54 @@ let MyAdminBackups = {
 						<th>{{ capGen.type }}</th>
 						<th>{{ capGen.interval }}</th>
 						<th>{{ capGen.version }}</th>
+						<th>{{ capGen.encryption }}</th>
+						<th>{{ capApp.targets }}</th>
+						<th>{{ capApp.verifyState }}</th>
 					</tr>
 				</thead>
 				<tbody>
 					<tr v-for="b in backups">
 						<td>{{ displayDate(b.timestamp) }}</td>
-						<td>{{ capApp.full }}</td>
-						<td>{{ capApp[b.jobName] }}</td>
+						<td>{{ capApp[b.scope] }}</td>
+						<td>{{ displayJobName(b.jobName) }}</td>
 						<td>{{ b.appBuild }}</td>
+						<td>
+							<span v-if="!b.encrypted">-</span>
+							<my-label image="lock.png" v-if="b.encrypted" :caption="capGen.boolTrue" />
+						</td>
+						<td>{{ b.targets.length !== 0 ? b.targets.join(', ') : '-' }}</td>
+						<td>
+							<span v-if="b.verifyDate === null">-</span>
+							<my-label
+								v-if="b.verifyDate !== null"
+								:caption="displayDate(b.verifyDate) + ': ' + (b.verifyError === null ? capApp.verifyOk : b.verifyError)"
+								:error="b.verifyError !== null"
+								:image="b.verifyError === null ? 'ok.png' : 'warning.png'"
+							/>
+						</td>
 					</tr>
 				</tbody>
 			</table>
+			
+			<my-admin-backup-target
+				v-if="targetIdOpen !== null"
+				@close="targetIdOpen = null;getTargets()"
+				:id="targetIdOpen"
+				:targetIdMap="targetIdMap"
+			/>
 		</div>
 	</div>`,
 	props:{
 		menuTitle:{ type:String, required:true }
 	},
 	computed:{
-		hasChanges:(s) => s.config.backupDir !== s.configInput.backupDir
+		hasChanges:(s) => s.hasChangesConfig || JSON.stringify(s.jobs) !== JSON.stringify(s.jobsInput),
+		hasChangesConfig:(s) => s.config.backupDir !== s.configInput.backupDir
+			|| s.config.backupEncryptKey   !== s.configInput.backupEncryptKey
 			|| s.config.backupDaily        !== s.configInput.backupDaily
 			|| s.config.backupWeekly       !== s.configInput.backupWeekly
 			|| s.config.backupMonthly      !== s.configInput.backupMonthly
+			|| s.config.backupVerify       !== s.configInput.backupVerify
 			|| s.config.backupCountDaily   !== s.configInput.backupCountDaily
 			|| s.config.backupCountWeekly  !== s.configInput.backupCountWeekly
 			|| s.config.backupCountMonthly !== s.configInput.backupCountMonthly,
@@ -118,7 +240,13 @@ let MyAdminBackups = {
 		return {
 			backups:[],
 			configInput:{},
-			ready:false
+			jobNamesFixed:['daily','weekly','monthly'],
+			jobs:[],
+			jobsInput:[],
+			ready:false,
+			scopes:['full','database','files'],
+			targetIdMap:{},
+			targetIdOpen:null
 		};
 	},
 	mounted() {
@@ -138,11 +266,32 @@ let MyAdminBackups = {
 		displayDate(date) {
 			return this.getUnixFormat(date,[this.settings.dateFormat,'H:i:S'].join(' '));
 		},
+		displayJobName(name) {
+			return this.jobNamesFixed.includes(name) ? this.capApp[name] : name;
+		},
+		displayTarget(t) {
+			let out = this.capApp.target.option.content[t.content];
+			if(t.content !== 'local') out += ', ' + t.hostName;
+			if(!t.active)             out += ' [' + this.capGen.disabled + ']';
+			return out;
+		},
 		
 		// actions
+		jobAdd() {
+			this.jobsInput.push({
+				id:0,
+				name:'',
+				scope:'database',
+				intervalHours:6,
+				keepCount:4,
+				active:true
+			});
+		},
 		reset() {
 			this.configInput = JSON.parse(JSON.stringify(this.config));
 			this.get();
+			this.getJobs();
+			this.getTargets();
 		},
 		
 		// backend calls,
@@ -153,11 +302,39 @@ let MyAdminBackups = {
 				this.$root.genericError
 			);
 		},
+		getJobs() {
+			ws.send('backup','getJobs',{},true).then(
+				res => {
+					this.jobs      = res.payload;
+					this.jobsInput = JSON.parse(JSON.stringify(this.jobs));
+				},
+				this.$root.genericError
+			);
+		},
+		getTargets() {
+			ws.send('backup','getTargets',{},true).then(
+				res => {
+					this.targetIdMap = {};
+					for(const t of res.payload) {
+						this.targetIdMap[t.id] = t;
+					}
+				},
+				this.$root.genericError
+			);
+		},
 		set() {
 			if(!this.hasChanges) return;
 			
-			ws.send('config','set',this.configInput,true).then(
-				() => {}, this.$root.genericError
+			let requests = [];
+			if(this.hasChangesConfig)
+				requests.push(ws.prepare('config','set',this.configInput));
+			
+			if(JSON.stringify(this.jobs) !== JSON.stringify(this.jobsInput))
+				requests.push(ws.prepare('backup','setJobs',this.jobsInput));
+			
+			ws.sendMultiple(requests,true).then(
+				this.getJobs,
+				this.$root.genericError
 			);
 		}
 	}
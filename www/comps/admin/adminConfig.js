@@ -76,6 +76,17 @@ export default {
 								</div>
 							</td>
 						</tr>
+						<tr>
+							<td>{{ capApp.moduleArchiveKeepCount }}</td>
+							<td>
+								<div class="row gap centered">
+									<input v-model="configInput.moduleArchiveKeepCount" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.moduleArchiveKeepCountDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
 						<tr><td colspan="2"><hr /></td></tr>
 						<tr><td colspan="2"><b>{{ capGen.systemModes }}</b></td></tr>
 						<tr>
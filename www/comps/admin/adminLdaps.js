@@ -21,7 +21,7 @@ export default {
 			<div class="area">
 				<my-button image="add.png"
 					@trigger="open(0)"
-					:active="licenseValid"
+					:active="licenseValid && isAdmin"
 					:caption="capApp.button.new"
 				/>
 			</div>
@@ -74,9 +74,16 @@ export default {
 						@trigger="runCheck"
 						:caption="capApp.button.test"
 					/>
+					<my-button image="search.png"
+						v-if="!isNew"
+						@trigger="runPreview"
+						:active="licenseValid && !hasChanges"
+						:caption="capApp.button.preview"
+					/>
 					<my-button image="delete.png"
 						v-if="!isNew"
 						@trigger="dialogDeleteAsk(del,capApp.dialog.delete)"
+						:active="isAdmin"
 						:cancel="true"
 						:caption="capGen.button.delete"
 					/>
@@ -107,6 +114,10 @@ export default {
 							<td>{{ capApp.host }}</td>
 							<td><input v-model="inputs.host" :placeholder="capApp.hostHint" /></td>
 						</tr>
+						<tr>
+							<td>{{ capApp.hostsFailover }}</td>
+							<td><input v-model="hostsFailoverInput" :placeholder="capApp.hostsFailoverHint" /></td>
+						</tr>
 						<tr>
 							<td>{{ capApp.port }}</td>
 							<td><input v-model.number="inputs.port" :placeholder="capApp.portHint" /></td>
@@ -160,6 +171,14 @@ export default {
 							<td>{{ capApp.loginAttribute }}</td>
 							<td><input v-model="inputs.loginAttribute" :placeholder="capApp.loginAttributeHint" /></td>
 						</tr>
+						<tr>
+							<td>{{ capApp.filterGroupDn }}</td>
+							<td><input v-model="filterGroupDnInput" :placeholder="capApp.filterGroupDnHint" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.deltaAttribute }}</td>
+							<td><input v-model="deltaAttributeInput" :placeholder="capApp.deltaAttributeHint" /></td>
+						</tr>
 						<tr>
 							<td colspan="2"><b>{{ capApp.loginMetaMap }}</b></td>
 						</tr>
@@ -176,7 +195,7 @@ export default {
 							<td><span v-html="capApp.assignRoles" /></td>
 							<td><my-bool v-model="inputs.assignRoles" /></td>
 						</tr>
-						<tr v-if="inputs.assignRoles">
+						<tr v-if="inputs.assignRoles || inputs.filterGroupDn !== null">
 							<td>{{ capApp.memberAttribute }}</td>
 							<td>
 								<input
@@ -216,8 +235,22 @@ export default {
 		this.$store.commit('pageTitle',this.menuTitle);
 	},
 	computed:{
+		// inputs
+		deltaAttributeInput:{
+			get()  { return this.inputs.deltaAttribute === null ? '' : this.inputs.deltaAttribute; },
+			set(v) { this.inputs.deltaAttribute = v === '' ? null : v; }
+		},
+		filterGroupDnInput:{
+			get()  { return this.inputs.filterGroupDn === null ? '' : this.inputs.filterGroupDn; },
+			set(v) { this.inputs.filterGroupDn = v === '' ? null : v; }
+		},
+		hostsFailoverInput:{
+			get()  { return this.inputs.hostsFailover.join(', '); },
+			set(v) { this.inputs.hostsFailover = v.split(',').map(h => h.trim()).filter(h => h !== ''); }
+		},
+		
 		// simple
-		canSave:   (s) => s.hasChanges && s.searchDn !== '',
+		canSave:   (s) => s.hasChanges && s.searchDn !== '' && s.isAdmin,
 		isNew:     (s) => s.idEdit === 0,
 		hasChanges:(s) => s.idEdit === -1 ? false : !s.deepIsEqual(s.inputsOrg,s.inputs),
 		
@@ -226,6 +259,7 @@ export default {
 		capApp:      (s) => s.$store.getters.captions.admin.ldaps,
 		capAppLogin: (s) => s.$store.getters.captions.admin.login,
 		capGen:      (s) => s.$store.getters.captions.generic,
+		isAdmin:     (s) => s.$store.getters.isAdmin,
 		licenseValid:(s) => s.$store.getters.licenseValid
 	},
 	methods:{
@@ -242,9 +276,12 @@ export default {
 			let ldap = {
 				name:'',
 				host:'',
+				hostsFailover:[],
 				port:636,
 				bindUserDn:'',
 				bindUserPw:'',
+				deltaAttribute:null,
+				filterGroupDn:null,
 				keyAttribute:'objectGUID',
 				loginMetaMap:{
 					department:'department',
@@ -304,6 +341,36 @@ export default {
 				this.$root.genericError
 			);
 		},
+		runPreview() {
+			ws.send('ldap','preview',{id:this.idEdit},true).then(
+				res => {
+					const roleNames = roleIds => roleIds.map(
+						id => this.roleIdMap[id] !== undefined ? this.roleIdMap[id].name : id
+					).join(', ');
+					let lines = [];
+					for(const c of res.payload) {
+						let states = [];
+						if(c.create)  states.push(this.capApp.preview.create);
+						if(c.change)  states.push(this.capApp.preview.change);
+						if(c.disable) states.push(this.capApp.preview.disable);
+						if(c.enable)  states.push(this.capApp.preview.enable);
+						if(c.roleIdsAdded.length !== 0)
+							states.push(`${this.capApp.preview.rolesAdded}: ${roleNames(c.roleIdsAdded)}`);
+						
+						if(c.roleIdsRemoved.length !== 0)
+							states.push(`${this.capApp.preview.rolesRemoved}: ${roleNames(c.roleIdsRemoved)}`);
+						
+						lines.push(`${c.name} (${states.join(', ')})`);
+					}
+					this.$store.commit('dialog',{
+						captionTop:this.capApp.preview.title,
+						captionBody:lines.length !== 0 ? lines.join('\n') : this.capApp.preview.empty,
+						textDisplay:'textarea'
+					});
+				},
+				this.$root.genericError
+			);
+		},
 		runCheck() {
 			ws.send('ldap','check',{id:this.idEdit},true).then(
 				() => {
@@ -347,9 +414,12 @@ export default {
 				id:this.idEdit,
 				name:this.inputs.name,
 				host:this.inputs.host,
+				hostsFailover:this.inputs.hostsFailover,
 				port:this.inputs.port,
 				bindUserDn:this.inputs.bindUserDn,
 				bindUserPw:this.inputs.bindUserPw,
+				deltaAttribute:this.inputs.deltaAttribute,
+				filterGroupDn:this.inputs.filterGroupDn,
 				keyAttribute:this.inputs.keyAttribute,
 				loginAttribute:this.inputs.loginAttribute,
 				loginMetaMap:this.inputs.loginMetaMap,
@@ -35,6 +35,14 @@ export default {
 							<td>{{ capApp.keepDays }}</td>
 							<td><input v-model="configInput.logsKeepDays" /></td>
 						</tr>
+						<tr>
+							<td>{{ capApp.keepDaysScheduleRuns }}</td>
+							<td><input v-model="configInput.scheduleRunsKeepDays" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.keepDaysJobs }}</td>
+							<td><input v-model="configInput.jobsKeepDays" /></td>
+						</tr>
 						<tr><td class="grouping" colspan="2">{{ capApp.logLevel }}</td></tr>
 						<tr v-for="c in contextsValid">
 							<td class="minimum">{{ capApp.contextLabel[c] }}*</td>
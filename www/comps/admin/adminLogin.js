@@ -253,9 +253,30 @@ export default {
 											<span>{{ capApp.admin }}</span>
 										</div>
 									</td>
-									<td><my-bool v-model="inputs.admin" /></td>
+									<td><my-bool v-model="inputs.admin" :readonly="!isAdmin" /></td>
 									<td>{{ capApp.hint.admin }}</td>
 								</tr>
+								<tr v-if="!inputs.admin">
+									<td>
+										<div class="title-cell">
+											<img src="images/personCog.png" />
+											<span>{{ capApp.adminPermissions }}</span>
+										</div>
+									</td>
+									<td>
+										<div class="column gap">
+											<div class="row gap centered" v-for="p in constants.adminPermissions">
+												<my-bool
+													@update:modelValue="toggleAdminPermission(p)"
+													:modelValue="inputs.adminPermissions.includes(p)"
+													:readonly="!isAdmin"
+												/>
+												<span>{{ capApp.adminPermission[p] }}</span>
+											</div>
+										</div>
+									</td>
+									<td>{{ capApp.hint.adminPermissions }}</td>
+								</tr>
 								
 								<!-- login records -->
 								<tr v-for="(lf,lfi) in loginForms">
@@ -414,7 +435,7 @@ export default {
 		isExtRole: (s) => s.isLdapAssignedRoles || s.isOauthClientAssignedRoles,
 		isFormOpen:(s) => s.loginFormIndexOpen !== null,
 		isLdap:    (s) => s.inputs.ldapId !== null,
-		isLimited: (s) => s.activated && s.inputs.roleIds.length < 2 && !s.inputs.admin && !s.inputs.noAuth,
+		isLimited: (s) => s.activated && s.inputs.roleIds.length < 2 && !s.inputs.admin && s.inputs.adminPermissions.length === 0 && !s.inputs.noAuth,
 		isNew:     (s) => s.loginId === 0,
 		isOauth:   (s) => s.inputs.oauthClientId !== null,
 		noAuthUrl: (s) => !s.inputs.noAuth ? '' : `${location.protocol}//${location.host}/#/?login=${s.inputs.name}`,
@@ -430,6 +451,8 @@ export default {
 		roleIdMap:      (s) => s.$store.getters['schema/roleIdMap'],
 		capApp:         (s) => s.$store.getters.captions.admin.login,
 		capGen:         (s) => s.$store.getters.captions.generic,
+		constants:      (s) => s.$store.getters.constants,
+		isAdmin:        (s) => s.$store.getters.isAdmin,
 		moduleIdMapMeta:(s) => s.$store.getters.moduleIdMapMeta
 	},
 	mounted() {
@@ -501,6 +524,7 @@ export default {
 					oauthClientId:null,
 					active:true,
 					admin:false,
+					adminPermissions:[],
 					meta:{},
 					name:'',
 					noAuth:false,
@@ -523,6 +547,11 @@ export default {
 			this.ready          = true;
 			this.getTemplates();
 		},
+		toggleAdminPermission(permission) {
+			const pos = this.inputs.adminPermissions.indexOf(permission);
+			if(pos === -1) this.inputs.adminPermissions.push(permission);
+			else           this.inputs.adminPermissions.splice(pos,1);
+		},
 		toggleRoleId(roleId) {
 			const pos = this.inputs.roleIds.indexOf(roleId);
 			if(pos === -1) this.inputs.roleIds.push(roleId);
@@ -660,6 +689,7 @@ export default {
 				pass:this.inputs.pass,
 				active:this.inputs.active,
 				admin:this.inputs.admin,
+				adminPermissions:this.inputs.adminPermissions,
 				meta:this.inputs.meta,
 				noAuth:this.inputs.noAuth,
 				tokenExpiryHours:/^(0|[1-9]\d*)$/.test(this.inputs.tokenExpiryHours) ? parseInt(this.inputs.tokenExpiryHours) : null,
@@ -159,12 +159,17 @@ export default {
 		// stores
 		modules:(s) => s.$store.getters['schema/modules'],
 		capApp: (s) => s.$store.getters.captions.admin.login,
-		capGen: (s) => s.$store.getters.captions.generic
+		capGen: (s) => s.$store.getters.captions.generic,
+		
+		adminPermissions:(s) => s.$store.getters.adminPermissions
 	},
 	mounted() {
 		this.get();
-		this.getLdaps();
-		this.getOauthClients();
+		// identity providers are part of system configuration
+		if(this.adminPermissions.includes('system')) {
+			this.getLdaps();
+			this.getOauthClients();
+		}
 		this.$store.commit('pageTitle',this.menuTitle);
 	},
 	methods:{
@@ -1,16 +1,22 @@
-import MyAdminModulesItem from './adminModulesItem.js';
-import MyAdminRepos       from './adminRepos.js';
-import MyAdminRepoInstall from './adminRepoInstall.js';
-import MyAdminRepoKeys    from './adminRepoKeys.js';
-import MyArticles         from '../articles.js';
+import MyAdminModulesArchive from './adminModulesArchive.js';
+import MyAdminModulesDiff    from './adminModulesDiff.js';
+import MyAdminModulesItem    from './adminModulesItem.js';
+import MyAdminRepos          from './adminRepos.js';
+import MyAdminRepoInstall    from './adminRepoInstall.js';
+import MyAdminRepoKeys       from './adminRepoKeys.js';
+import MyAdminRepoServe      from './adminRepoServe.js';
+import MyArticles            from '../articles.js';
 
 export default {
 	name:'my-admin-modules',
 	components:{
+		MyAdminModulesArchive,
+		MyAdminModulesDiff,
 		MyAdminModulesItem,
 		MyAdminRepos,
 		MyAdminRepoInstall,
 		MyAdminRepoKeys,
+		MyAdminRepoServe,
 		MyArticles
 	},
 	template:`<div class="contentBox scroll admin-modules grow">
@@ -24,16 +30,32 @@ export default {
 			/>
 		</div>
 
+		<!-- archived application versions -->
+		<my-admin-modules-archive
+			v-if="moduleArchive !== null"
+			@close="moduleArchive = null"
+			:module="moduleArchive"
+		/>
+
+		<!-- import dry run results -->
+		<my-admin-modules-diff
+			v-if="dryRun !== null"
+			@apply="dryRunApply"
+			@close="dryRun = null;dryRunApply = null"
+			:dryRun="dryRun"
+		/>
+
 		<my-tabs
 			v-model="tabTarget"
-			:entries="['modules','installFromRepo','installFromFile','repos','keys']"
-			:entriesIcon="['images/builder.png','images/box.png','images/upload.png','images/boxMultiple.png','images/key.png']"
-			:entriesText="[capApp.installedApps,capApp.repoInstallFrom,capApp.import,capApp.reposManage,capApp.publicKeys]"
+			:entries="['modules','installFromRepo','installFromFile','repos','keys','serve']"
+			:entriesIcon="['images/builder.png','images/box.png','images/upload.png','images/boxMultiple.png','images/key.png','images/server.png']"
+			:entriesText="[capApp.installedApps,capApp.repoInstallFrom,capApp.import,capApp.reposManage,capApp.publicKeys,capApp.repoServe]"
 		/>
 
 		<my-admin-repos        v-if="tabTarget === 'repos'" />
 		<my-admin-repo-install v-if="tabTarget === 'installFromRepo'" />
 		<my-admin-repo-keys    v-if="tabTarget === 'keys'" />
+		<my-admin-repo-serve   v-if="tabTarget === 'serve'" />
 
 		<template v-if="tabTarget === 'installFromFile'">
 			<div class="content">
@@ -44,14 +66,25 @@ export default {
 						@change="fileToUpload = $event.target.files[0]"
 						:disabled="!canUploadFile"
 					/>
+					<my-button-check
+						v-if="builderEnabled && isAdmin"
+						v-model="fileIsDirectory"
+						:caption="capApp.importDirectory"
+						:readonly="!canUploadFile"
+					/>
 					<br />
-					<div class="row">
+					<div class="row gap">
 						<my-button
-							@trigger="importModule"
+							@trigger="importModule(false)"
 							:active="canUploadFile && fileToUpload !== null"
 							:caption="capGen.button.apply"
 							:image="fileUploading ? 'load.gif' : 'ok.png'"
 						/>
+						<my-button image="search.png"
+							@trigger="importModule(true)"
+							:active="canUploadFile && fileToUpload !== null"
+							:caption="capApp.button.preview"
+						/>
 					</div>
 				</div>
 			</div>
@@ -71,6 +104,11 @@ export default {
 						:caption="capApp.button.updateAll.replace('{COUNT}',moduleIdsUpdate.length)"
 						:image="!installStarted ? 'download.png' : 'load.gif'"
 					/>
+					<my-button image="search.png"
+						@trigger="installAllDryRun"
+						:active="moduleIdsUpdate.length !== 0 && !installStarted && !productionMode"
+						:caption="capApp.button.preview"
+					/>
 				</div>
 				<div class="area">
 					<my-button image="refresh.png"
@@ -158,6 +196,8 @@ export default {
 							v-for="(m,i) in modules"
 							@change="updateMeta"
 							@install="install"
+							@installDryRun="installDryRun"
+							@showArchive="moduleArchive = $event"
 							@showHelp="showHelp"
 							@showLog="showLog"
 							@shownWarning="warningShown = true"
@@ -178,9 +218,13 @@ export default {
 	},
 	data() {
 		return {
+			dryRun:null,           // result of import dry run, shown if set
+			dryRunApply:null,      // function to apply the previewed changes
+			fileIsDirectory:false, // file to upload is a directory export, packaged as compressed file
 			fileToUpload:null,
 			fileUploading:false,
 			installStarted:false,
+			moduleArchive:null,    // module to show archived versions for
 			moduleIdMapUpdated:{}, // module ID map of updated module meta data (empty if nothing changed)
 			moduleIdShowHelp:null,
 			repoModules:[],
@@ -225,6 +269,7 @@ export default {
 		modules:        s => s.$store.getters['schema/modules'],
 		moduleIdMap:    s => s.$store.getters['schema/moduleIdMap'],
 		builderEnabled: s => s.$store.getters.builderEnabled,
+		isAdmin:        s => s.$store.getters.isAdmin,
 		capApp:         s => s.$store.getters.captions.admin.modules,
 		capGen:         s => s.$store.getters.captions.generic,
 		moduleIdMapMeta:s => s.$store.getters.moduleIdMapMeta,
@@ -252,7 +297,7 @@ export default {
 		},
 		
 		// actions
-		importModule() {
+		importModule(dryRun) {
 			this.fileUploading = true;
 			let formData       = new FormData();
 			let httpRequest    = new XMLHttpRequest();
@@ -271,8 +316,14 @@ export default {
 					this.$root.genericError(this.capApp.error.uploadFailed);
 					return;
 				}
+				if(dryRun) {
+					this.dryRun      = res.dryRun;
+					this.dryRunApply = () => this.importModule(false);
+				}
 			}
 			formData.append('token',this.token);
+			formData.append('dryRun',dryRun ? 'true' : 'false');
+			formData.append('format',this.fileIsDirectory ? 'directory' : 'package');
 			formData.append('file',this.fileToUpload);
 			httpRequest.open('POST','import',true);
 			httpRequest.send(formData);
@@ -302,6 +353,15 @@ export default {
 			);
 			this.installStarted = true;
 		},
+		installDryRun(moduleId) {
+			ws.send('repoModule','installDryRun',moduleId,true,true).then(
+				res => {
+					this.dryRun      = res.payload;
+					this.dryRunApply = () => this.install(moduleId);
+				},
+				this.installError
+			);
+		},
 		installAll() {
 			ws.send('repoModule','installAll',{},true,true).then(
 				() => this.installOk(),
@@ -309,6 +369,15 @@ export default {
 			);
 			this.installStarted = true;
 		},
+		installAllDryRun() {
+			ws.send('repoModule','installAllDryRun',{},true,true).then(
+				res => {
+					this.dryRun      = res.payload;
+					this.dryRunApply = this.installAll;
+				},
+				this.installError
+			);
+		},
 		installOk() {
 			this.$store.commit('dialog',{
 				captionBody:this.capApp.updateDone
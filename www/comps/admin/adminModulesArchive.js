@@ -0,0 +1,193 @@
+import MyAdminModulesDiff from './adminModulesDiff.js';
+import {getUnixFormat}    from '../shared/time.js';
+
+export default {
+	name:'my-admin-modules-archive',
+	components:{ MyAdminModulesDiff },
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="close">
+		<div class="contentBox admin-modules-archive float scroll">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/versionHistory.png" />
+					<h1 class="title">{{ capApp.archive.title.replace('{NAME}',module.name) }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png" @trigger="close" :cancel="true" />
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="refresh.png"
+						@trigger="get"
+						:caption="capGen.button.refresh"
+					/>
+				</div>
+			</div>
+
+			<div class="content">
+				<p>{{ capApp.archive.hint }}</p>
+				<table class="generic-table bright">
+					<thead>
+						<tr>
+							<th>{{ capApp.archive.releaseBuild }}</th>
+							<th>{{ capApp.archive.releaseBuildReplacedBy }}</th>
+							<th>{{ capApp.archive.dateCreated }}</th>
+							<th></th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="a in archives">
+							<td>v{{ a.releaseBuild }}</td>
+							<td>{{ a.releaseBuildReplacedBy !== null ? 'v' + a.releaseBuildReplacedBy : '-' }}</td>
+							<td>{{ getUnixFormat(a.dateCreated,settings.dateFormat + ' H:i:S') }}</td>
+							<td>
+								<div class="row gap">
+									<my-button image="undo.png"
+										@trigger="rollback(a.id,true)"
+										:active="!running && !productionMode"
+										:caption="capApp.button.rollback"
+									/>
+									<my-button image="delete.png"
+										@trigger="delAsk(a.id)"
+										:active="!running"
+										:cancel="true"
+										:captionTitle="capGen.button.delete"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr v-if="archives.length === 0">
+							<td colspan="4">{{ capGen.nothingThere }}</td>
+						</tr>
+					</tbody>
+				</table>
+
+				<!-- data migrations executed in this instance -->
+				<template v-if="migrations.length !== 0">
+					<p>{{ capApp.archive.migrationsHint }}</p>
+					<table class="generic-table bright">
+						<thead>
+							<tr>
+								<th>{{ capApp.archive.migration }}</th>
+								<th>{{ capApp.archive.releaseBuild }}</th>
+								<th>{{ capApp.archive.migrationUpgrade }}</th>
+								<th>{{ capApp.archive.dateExecuted }}</th>
+							</tr>
+						</thead>
+						<tbody>
+							<tr v-for="m in migrations">
+								<td>{{ pgFunctionIdMap[m.pgFunctionId] !== undefined ? pgFunctionIdMap[m.pgFunctionId].name : m.pgFunctionId }}</td>
+								<td>v{{ m.build }}</td>
+								<td>v{{ m.releaseBuildFrom }} -> v{{ m.releaseBuildTo }}</td>
+								<td>{{ getUnixFormat(m.dateExecuted,settings.dateFormat + ' H:i:S') }}</td>
+							</tr>
+						</tbody>
+					</table>
+				</template>
+			</div>
+		</div>
+
+		<!-- rollback preview, with changes that would lose data -->
+		<my-admin-modules-diff
+			v-if="dryRun !== null"
+			@apply="rollback(archiveIdDryRun,false)"
+			@close="dryRun = null"
+			:dryRun="dryRun"
+		/>
+	</div>`,
+	props:{
+		module:{ type:Object, required:true }
+	},
+	emits:['close'],
+	data() {
+		return {
+			archives:[],
+			archiveIdDryRun:null, // archive ID of shown rollback preview
+			dryRun:null,          // result of rollback dry run, shown if set
+			migrations:[],        // data migrations executed in this instance
+			running:false
+		};
+	},
+	computed:{
+		// stores
+		pgFunctionIdMap:s => s.$store.getters['schema/pgFunctionIdMap'],
+		capApp:         s => s.$store.getters.captions.admin.modules,
+		capGen:         s => s.$store.getters.captions.generic,
+		productionMode: s => s.$store.getters.productionMode,
+		settings:       s => s.$store.getters.settings
+	},
+	mounted() {
+		this.get();
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.close,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.close);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+
+		// actions
+		close() {
+			this.$emit('close');
+		},
+		delAsk(id) {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.archiveDelete,
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.delete,
+					exec:() => this.del(id),
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+
+		// backend calls
+		del(id) {
+			ws.send('moduleArchive','del',id,true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.sendMultiple([
+				ws.prepare('moduleArchive','get',this.module.id),
+				ws.prepare('moduleArchive','getMigrations',this.module.id)
+			],true).then(
+				res => {
+					this.archives   = res[0].payload;
+					this.migrations = res[1].payload;
+				},
+				this.$root.genericError
+			);
+		},
+		rollback(id,dryRun) {
+			this.running = true;
+			ws.send('moduleArchive','rollback',{id:id,dryRun:dryRun},true,true).then(
+				res => {
+					this.running = false;
+
+					if(dryRun) {
+						this.archiveIdDryRun = id;
+						this.dryRun          = res.payload;
+						return;
+					}
+					this.$store.commit('dialog',{
+						captionBody:this.capApp.archive.rollbackDone
+					});
+					this.get();
+				},
+				err => {
+					this.running = false;
+					this.$root.genericError(err);
+				}
+			);
+		}
+	}
+};
@@ -0,0 +1,121 @@
+export default {
+	name:'my-admin-modules-diff',
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="close">
+		<div class="contentBox admin-modules-diff float scroll">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/search.png" />
+					<h1 class="title">{{ capApp.diff.title }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png" @trigger="close" :cancel="true" />
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="ok.png"
+						@trigger="apply"
+						:active="dryRun.error === null && !productionMode"
+						:caption="capGen.button.apply"
+					/>
+				</div>
+				<div class="area">
+					<my-button-check
+						v-model="destructiveOnly"
+						:caption="capApp.diff.destructiveOnly"
+					/>
+				</div>
+			</div>
+
+			<div class="content">
+				<p class="message error" v-if="dryRun.error !== null">
+					{{ capApp.diff.error.replace('{ERROR}',dryRun.error) }}
+				</p>
+				<p class="message" v-if="destructiveCount !== 0">
+					<my-label image="warning.png" :caption="capApp.diff.destructiveCount.replace('{COUNT}',destructiveCount)" />
+				</p>
+
+				<div class="admin-modules-diff-module" v-for="m in dryRun.modules">
+					<my-label image="module.png" :large="true"
+						:caption="m.isNew
+							? capApp.diff.moduleNew.replace('{NAME}',m.moduleName).replace('{TO}',m.releaseBuildTo)
+							: capApp.diff.moduleUpdate.replace('{NAME}',m.moduleName).replace('{FROM}',m.releaseBuildFrom).replace('{TO}',m.releaseBuildTo)"
+					/>
+					<table class="generic-table bright">
+						<thead>
+							<tr>
+								<th>{{ capApp.diff.entity }}</th>
+								<th>{{ capGen.name }}</th>
+								<th>{{ capApp.diff.action }}</th>
+								<th>{{ capApp.diff.details }}</th>
+								<th>{{ capApp.diff.destructive }}</th>
+								<th>{{ capApp.diff.rowsAffected }}</th>
+							</tr>
+						</thead>
+						<tbody>
+							<tr v-for="c in m.changes.filter(v => !destructiveOnly || v.destructive.length !== 0)"
+								:class="{ destructive:c.destructive.length !== 0 }"
+							>
+								<td>{{ capApp.diff.entities[c.entity] !== undefined ? capApp.diff.entities[c.entity] : c.entity }}</td>
+								<td :title="c.entityId">{{ c.name }}</td>
+								<td>{{ capApp.diff.actions[c.action] }}</td>
+								<td>{{ c.details.join(', ') }}</td>
+								<td>{{ c.destructive.map(v => capApp.diff.destructives[v]).join(', ') }}</td>
+								<td>{{ c.rowsAffected !== null ? c.rowsAffected : '' }}</td>
+							</tr>
+							<tr v-if="m.changes.length === 0">
+								<td colspan="6">{{ capApp.diff.noChanges }}</td>
+							</tr>
+						</tbody>
+					</table>
+					<template v-if="m.migrations.length !== 0">
+						<my-label image="databasePlay.png" :caption="capApp.diff.migrations" />
+						<ul>
+							<li v-for="name in m.migrations">{{ name }}</li>
+						</ul>
+					</template>
+				</div>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		dryRun:{ type:Object, required:true } // result of import dry run, with module diffs & import error
+	},
+	emits:['apply','close'],
+	data() {
+		return {
+			destructiveOnly:false
+		};
+	},
+	computed:{
+		destructiveCount:s => {
+			let cnt = 0;
+			for(const m of s.dryRun.modules) {
+				cnt += m.changes.filter(v => v.destructive.length !== 0).length;
+			}
+			return cnt;
+		},
+
+		// stores
+		capApp:        s => s.$store.getters.captions.admin.modules,
+		capGen:        s => s.$store.getters.captions.generic,
+		productionMode:s => s.$store.getters.productionMode
+	},
+	mounted() {
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.close,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.close);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		apply() {
+			this.$emit('apply');
+			this.close();
+		},
+		close() {
+			this.$emit('close');
+		}
+	}
+};
@@ -34,13 +34,19 @@ export default {
 				{{ capApp.repoUpToDate }}
 			</div>
 		
-			<my-button
-				v-if="isReadyForUpdate"
-				@trigger="$emit('install',repoModule.moduleId)"
-				:active="!installStarted && !productionMode"
-				:caption="capApp.button.update.replace('{VERSION}',repoModule.releaseBuild)"
-				:image="!installStarted ? 'download.png' : 'load.gif'"
-			/>
+			<div class="row gap" v-if="isReadyForUpdate">
+				<my-button
+					@trigger="$emit('install',repoModule.moduleId)"
+					:active="!installStarted && !productionMode"
+					:caption="capApp.button.update.replace('{VERSION}',repoModule.releaseBuild)"
+					:image="!installStarted ? 'download.png' : 'load.gif'"
+				/>
+				<my-button image="search.png"
+					@trigger="$emit('installDryRun',repoModule.moduleId)"
+					:active="!installStarted && !productionMode"
+					:captionTitle="capApp.button.preview"
+				/>
+			</div>
 		</td>
 		<td class="noWrap">
 			<my-button image="question.png"
@@ -86,6 +92,10 @@ export default {
 					@trigger-middle="openBuilder(true)"
 					:captionTitle="capGen.button.openBuilder"
 				/>
+				<my-button image="versionHistory.png"
+					@trigger="$emit('showArchive',module)"
+					:captionTitle="capApp.button.archive"
+				/>
 				<my-button image="delete.png"
 					@trigger="delAsk"
 					:active="!productionMode"
@@ -103,7 +113,7 @@ export default {
 		repoModules:   { type:Array,   required:true },
 		warningShown:  { type:Boolean, required:true }
 	},
-	emits:['change','install','showLog','showHelp','shownWarning'],
+	emits:['change','install','installDryRun','showArchive','showLog','showHelp','shownWarning'],
 	data() {
 		return {
 			id:this.module.id,
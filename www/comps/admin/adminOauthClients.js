@@ -15,7 +15,7 @@ export default {
 			<div class="area">
 				<my-button image="add.png"
 					@trigger="idOpen = 0"
-					:active="licenseValid"
+					:active="licenseValid && isAdmin"
 					:caption="capGen.button.new"
 				/>
 				<my-button image="refresh.png"
@@ -47,7 +47,7 @@ export default {
 				:id="idOpen"
 				:loginTemplates
 				:oauthClientIdMap
-				:readonly="!licenseValid"
+				:readonly="!licenseValid || !isAdmin"
 			/>
 		</div>
 	</div>`,
@@ -65,6 +65,7 @@ export default {
 		// stores
 		capApp:      s => s.$store.getters.captions.admin.oauthClient,
 		capGen:      s => s.$store.getters.captions.generic,
+		isAdmin:     s => s.$store.getters.isAdmin,
 		licenseValid:s => s.$store.getters.licenseValid,
 		settings:    s => s.$store.getters.settings
 	},
@@ -0,0 +1,355 @@
+import MyInputLogin        from '../inputLogin.js';
+import {isAttributeFiles} from '../shared/attribute.js';
+
+const MyAdminPrivacyRelation = {
+	name:'my-admin-privacy-relation',
+	template:`<tr>
+		<td>{{ moduleIdMap[relation.moduleId].name + '.' + relation.name }}</td>
+		<td>
+			<my-bool
+				@update:modelValue="update('person',$event)"
+				:modelValue="modelValue.person"
+			/>
+		</td>
+		<td>
+			<select
+				v-if="modelValue.person"
+				@change="update('attributeIdLogin',$event.target.value === '' ? null : $event.target.value)"
+				:value="modelValue.attributeIdLogin === null ? '' : modelValue.attributeIdLogin"
+			>
+				<option value="">-</option>
+				<option v-for="a in attributesLogin" :value="a.id">{{ a.name }}</option>
+			</select>
+		</td>
+		<td>
+			<select
+				@change="update('strategy',$event.target.value)"
+				:value="modelValue.strategy"
+			>
+				<option value="keep">{{ capApp.option.strategyKeep }}</option>
+				<option value="anonymize">{{ capApp.option.strategyAnonymize }}</option>
+				<option value="delete">{{ capApp.option.strategyDelete }}</option>
+			</select>
+		</td>
+		<td>
+			<div class="row wrap gap" v-if="modelValue.strategy === 'anonymize'">
+				<div class="row gap centered" v-for="a in attributesAnonymize">
+					<my-bool
+						@update:modelValue="toggleAnonymize(a.id)"
+						:modelValue="modelValue.attributeIdsAnonymize.includes(a.id)"
+					/>
+					<span>{{ a.name }}</span>
+				</div>
+			</div>
+		</td>
+		<td>
+			<my-button image="delete.png"
+				@trigger="$emit('remove')"
+				:cancel="true"
+			/>
+		</td>
+	</tr>`,
+	props:{
+		modelValue:{ type:Object, required:true }
+	},
+	emits:['remove','update:modelValue'],
+	computed:{
+		// anonymized values are cleared, only nullable attributes can be cleared
+		attributesAnonymize:s => s.relation.attributes.filter(a => a.id !== s.relation.attributeIdPk
+			&& (a.nullable || s.isAttributeFiles(a.content))),
+		attributesLogin:s => s.relation.attributes.filter(a => a.id !== s.relation.attributeIdPk
+			&& ['integer','bigint'].includes(a.content)),
+
+		// simple
+		relation:s => s.relationIdMap[s.modelValue.relationId],
+
+		// stores
+		moduleIdMap:  s => s.$store.getters['schema/moduleIdMap'],
+		relationIdMap:s => s.$store.getters['schema/relationIdMap'],
+		capApp:       s => s.$store.getters.captions.admin.privacy
+	},
+	methods:{
+		// externals
+		isAttributeFiles,
+
+		// actions
+		toggleAnonymize(attributeId) {
+			let ids = JSON.parse(JSON.stringify(this.modelValue.attributeIdsAnonymize));
+			const pos = ids.indexOf(attributeId);
+			if(pos === -1) ids.push(attributeId);
+			else           ids.splice(pos,1);
+			this.update('attributeIdsAnonymize',ids);
+		},
+		update(name,value) {
+			let v = JSON.parse(JSON.stringify(this.modelValue));
+			v[name] = value;
+
+			if(name === 'person' && !value)
+				v.attributeIdLogin = null;
+
+			this.$emit('update:modelValue',v);
+		}
+	}
+};
+
+export default {
+	name:'my-admin-privacy',
+	components:{
+		MyAdminPrivacyRelation,
+		MyInputLogin
+	},
+	template:`<div class="contentBox grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/personCog.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area nowrap default-inputs">
+				<select v-model="subjectBy" @change="found = null">
+					<option value="login">{{ capApp.option.subjectByLogin }}</option>
+					<option value="record">{{ capApp.option.subjectByRecord }}</option>
+				</select>
+				<my-input-login
+					v-if="subjectBy === 'login'"
+					v-model="loginId"
+					:placeholder="capApp.login"
+				/>
+				<template v-if="subjectBy === 'record'">
+					<select v-model="relationId">
+						<option :value="null">-</option>
+						<optgroup v-for="m in modules" :label="m.name">
+							<option v-for="r in m.relations" :value="r.id">{{ r.name }}</option>
+						</optgroup>
+					</select>
+					<input class="short" v-model.number="recordId" :placeholder="capApp.recordId" />
+				</template>
+				<my-button image="search.png"
+					@trigger="find"
+					:active="subjectValid"
+					:caption="capApp.button.find"
+				/>
+			</div>
+			<div class="area default-inputs" v-if="found !== null">
+				<a target="_blank" :href="exportUrl">
+					<my-button image="download.png"
+						:caption="capApp.button.export"
+					/>
+				</a>
+				<my-button image="delete.png"
+					@trigger="eraseAsk"
+					:active="found.length !== 0 || delLogin"
+					:cancel="true"
+					:caption="capApp.button.erase"
+				/>
+				<div class="row gap centered" v-if="subjectBy === 'login'">
+					<my-bool v-model="delLogin" />
+					<span>{{ capApp.delLogin }}</span>
+				</div>
+			</div>
+		</div>
+
+		<div class="content">
+			<table class="generic-table bright" v-if="found !== null">
+				<thead>
+					<tr>
+						<th>{{ capGen.relation }}</th>
+						<th>{{ capApp.records }}</th>
+						<th>{{ capApp.strategy }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-if="found.length === 0">
+						<td colspan="999">{{ capGen.nothingThere }}</td>
+					</tr>
+					<tr v-for="f in found">
+						<td>
+							{{ moduleIdMap[relationIdMap[f.relationId].moduleId].name + '.' + relationIdMap[f.relationId].name }}
+							<span v-if="f.referenced">{{ capApp.referenced }}</span>
+						</td>
+						<td>{{ f.recordIds.length }}</td>
+						<td>{{ capApp.option['strategy' + f.strategy.charAt(0).toUpperCase() + f.strategy.slice(1)] }}</td>
+					</tr>
+				</tbody>
+			</table>
+
+			<h2>{{ capApp.settings }}</h2>
+			<p>{{ capApp.settingsHint }}</p>
+			<div class="row gap default-inputs">
+				<select v-model="relationIdAdd">
+					<option :value="null">{{ capApp.relationAdd }}</option>
+					<optgroup v-for="m in modules" :label="m.name">
+						<option
+							v-for="r in m.relations.filter(v => !relationIdsSet.includes(v.id))"
+							:value="r.id"
+						>{{ r.name }}</option>
+					</optgroup>
+				</select>
+				<my-button image="add.png"
+					@trigger="add"
+					:active="relationIdAdd !== null"
+					:caption="capGen.button.add"
+				/>
+				<my-button image="save.png"
+					@trigger="set"
+					:active="hasChanges"
+					:caption="capGen.button.save"
+				/>
+				<my-button image="refresh.png"
+					@trigger="get"
+					:active="hasChanges"
+					:caption="capGen.button.refresh"
+				/>
+			</div>
+			<table class="generic-table bright default-inputs">
+				<thead>
+					<tr>
+						<th>{{ capGen.relation }}</th>
+						<th>{{ capApp.person }}</th>
+						<th>{{ capApp.attributeLogin }}</th>
+						<th>{{ capApp.strategy }}</th>
+						<th>{{ capApp.attributesAnonymize }}</th>
+						<th></th>
+					</tr>
+				</thead>
+				<tbody>
+					<my-admin-privacy-relation
+						v-for="(r,i) in relations"
+						v-model="relations[i]"
+						@remove="relations.splice(i,1)"
+						:key="r.relationId"
+					/>
+				</tbody>
+			</table>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			// inputs
+			delLogin:false,
+			loginId:null,
+			recordId:null,
+			relationId:null,
+			relationIdAdd:null,
+			relations:[],
+			subjectBy:'login',
+
+			// data
+			found:null,       // records of data subject by relation, null if not searched yet
+			relationsOrg:[]
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+		this.get();
+	},
+	computed:{
+		exportUrl:s => {
+			let url = `/privacy/export?token=${s.token}`;
+			if(s.subjectBy === 'login') url += `&login_id=${s.loginId}`;
+			else                        url += `&relation_id=${s.relationId}&record_id=${s.recordId}`;
+			return url;
+		},
+		subject:s => {
+			return {
+				loginId:s.subjectBy === 'login' ? s.loginId : null,
+				relationId:s.subjectBy === 'record' ? s.relationId : null,
+				recordId:s.subjectBy === 'record' ? s.recordId : null
+			};
+		},
+		subjectValid:s => s.subjectBy === 'login'
+			? s.loginId !== null
+			: s.relationId !== null && Number.isInteger(s.recordId),
+
+		// simple
+		hasChanges:    s => JSON.stringify(s.relations) !== JSON.stringify(s.relationsOrg),
+		relationIdsSet:s => s.relations.map(v => v.relationId),
+
+		// stores
+		modules:      s => s.$store.getters['schema/modules'],
+		moduleIdMap:  s => s.$store.getters['schema/moduleIdMap'],
+		relationIdMap:s => s.$store.getters['schema/relationIdMap'],
+		token:        s => s.$store.getters['local/token'],
+		capApp:       s => s.$store.getters.captions.admin.privacy,
+		capGen:       s => s.$store.getters.captions.generic
+	},
+	methods:{
+		// actions
+		add() {
+			this.relations.push({
+				relationId:this.relationIdAdd,
+				person:false,
+				attributeIdLogin:null,
+				strategy:'keep',
+				attributeIdsAnonymize:[]
+			});
+			this.relationIdAdd = null;
+		},
+		eraseAsk() {
+			let count = 0;
+			for(const f of this.found) {
+				if(f.strategy !== 'keep')
+					count += f.recordIds.length;
+			}
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.erase.replace('{COUNT}',count),
+				image:'warning.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.erase,
+					exec:this.erase,
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+
+		// backend calls
+		erase() {
+			ws.send('privacy','erase',{
+				subject:this.subject,
+				delLogin:this.subjectBy === 'login' && this.delLogin
+			},true).then(
+				() => {
+					this.delLogin = false;
+					this.found    = null;
+					this.$store.commit('dialog',{
+						captionBody:this.capApp.dialog.erased,
+						image:'ok.png'
+					});
+				},
+				this.$root.genericError
+			);
+		},
+		find() {
+			if(!this.subjectValid) return;
+
+			ws.send('privacy','find',this.subject,true).then(
+				res => this.found = res.payload,
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.send('privacy','get',{},true).then(
+				res => {
+					this.relations    = res.payload;
+					this.relationsOrg = JSON.parse(JSON.stringify(res.payload));
+				},
+				this.$root.genericError
+			);
+		},
+		set() {
+			ws.send('privacy','set',this.relations,true).then(
+				this.get,
+				this.$root.genericError
+			);
+		}
+	}
+};
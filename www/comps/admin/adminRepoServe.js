@@ -0,0 +1,177 @@
+import MyInputLogin from '../inputLogin.js';
+
+export default {
+	name:'my-admin-repo-serve',
+	components:{ MyInputLogin },
+	template:`<div class="admin-repo-serve contentBox grow">
+		<div class="top lower">
+			<div class="area">
+				<my-button image="save.png"    @trigger="set"   :active="isChanged" :caption="capGen.button.save" />
+				<my-button image="refresh.png" @trigger="reset" :caption="capGen.button.refresh" />
+			</div>
+		</div>
+
+		<div class="content grow" v-if="ready">
+			<div class="column gap">
+				<table class="default-inputs">
+					<tbody>
+						<tr>
+							<td>{{ capApp.serve.active }}</td>
+							<td><my-bool-string-number v-model="configInput.repoServeActive" /></td>
+						</tr>
+					</tbody>
+				</table>
+				<p>{{ capApp.serve.activeDesc.replace('{URL}',url) }}</p>
+			</div>
+
+			<!-- logins, used as repository credentials by other instances -->
+			<br />
+			<div class="column gap">
+				<my-label image="person.png" :caption="capApp.serve.logins" :large="true" />
+				<p>{{ capApp.serve.loginsDesc }}</p>
+				<div class="admin-repo-serve-logins default-inputs">
+					<my-input-login
+						@update:modelValue="loginAdd"
+						:clearInput="true"
+						:idsExclude="serve.logins.map(v => v.id)"
+						:modelValue="null"
+						:placeholder="capApp.serve.loginAdd"
+					/>
+					<div class="row gap centered" v-for="(l,i) in serve.logins" :key="l.id">
+						<my-button image="cancel.png" @trigger="serve.logins.splice(i,1)" :naked="true" />
+						<span>{{ l.name }}</span>
+					</div>
+				</div>
+			</div>
+
+			<!-- published modules -->
+			<br />
+			<div class="column gap">
+				<my-label image="builder.png" :caption="capApp.serve.modules" :large="true" />
+				<p>{{ capApp.serve.modulesDesc }}</p>
+				<table class="generic-table bright default-inputs shade">
+					<thead>
+						<tr>
+							<th>{{ capApp.serve.publish }}</th>
+							<th>{{ capGen.name }}</th>
+							<th>{{ capApp.serve.inStore }}</th>
+							<th>{{ capApp.serve.author }}</th>
+							<th>{{ capApp.serve.description }}</th>
+							<th>{{ capApp.serve.supportPage }}</th>
+							<th>{{ capApp.serve.state }}</th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="mod in modules" :key="mod.id">
+							<td class="minimum">
+								<my-bool
+									@update:modelValue="publishToggle(mod.id,$event)"
+									:modelValue="moduleIdMapServe[mod.id] !== undefined"
+								/>
+							</td>
+							<td>{{ mod.name }} v{{ mod.releaseBuild }}</td>
+							<template v-if="moduleIdMapServe[mod.id] !== undefined">
+								<td class="minimum"><my-bool v-model="moduleIdMapServe[mod.id].inStore" /></td>
+								<td><input v-model="moduleIdMapServe[mod.id].author" /></td>
+								<td><input v-model="moduleIdMapServe[mod.id].description" /></td>
+								<td><input v-model="moduleIdMapServe[mod.id].supportPage" /></td>
+								<td>
+									<my-label
+										:caption="moduleIdMapServe[mod.id].fileError !== null ? moduleIdMapServe[mod.id].fileError : capApp.serve.stateOk"
+										:error="moduleIdMapServe[mod.id].fileError !== null"
+										:image="moduleIdMapServe[mod.id].fileError !== null ? 'warning.png' : 'ok.png'"
+									/>
+								</td>
+							</template>
+							<td colspan="5" v-else></td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	data() {
+		return {
+			configInput:{},
+			ready:false,
+			serve:{ logins:[], modules:[] },
+			serveCopy:{ logins:[], modules:[] } // copy of serve settings from backend, to detect changes
+		};
+	},
+	mounted() {
+		this.reset();
+	},
+	computed:{
+		isChanged:s => s.config.repoServeActive !== s.configInput.repoServeActive
+			|| JSON.stringify(s.serve) !== JSON.stringify(s.serveCopy),
+		moduleIdMapServe:s => {
+			let out = {};
+			for(const m of s.serve.modules) {
+				out[m.moduleId] = m;
+			}
+			return out;
+		},
+		url:s => `${location.protocol}//${location.host}`,
+
+		// stores
+		modules:s => s.$store.getters['schema/modules'],
+		capApp: s => s.$store.getters.captions.admin.repo,
+		capGen: s => s.$store.getters.captions.generic,
+		config: s => s.$store.getters.config
+	},
+	methods:{
+		// actions
+		loginAdd(id) {
+			if(id === null) return;
+
+			ws.send('login','getNames',{id:id},true).then(
+				res => {
+					if(res.payload.length === 1)
+						this.serve.logins.push({ id:id, name:res.payload[0].name });
+				},
+				this.$root.genericError
+			);
+		},
+		publishToggle(moduleId,state) {
+			if(!state)
+				return this.serve.modules = this.serve.modules.filter(v => v.moduleId !== moduleId);
+
+			this.serve.modules.push({
+				moduleId:moduleId,
+				author:'',
+				description:'',
+				inStore:true,
+				supportPage:'',
+				fileError:null
+			});
+		},
+		reset() {
+			this.configInput = JSON.parse(JSON.stringify(this.config));
+			this.get();
+		},
+
+		// backend calls
+		get() {
+			ws.send('repoServe','get',{},true).then(
+				res => {
+					this.serve     = res.payload;
+					this.serveCopy = JSON.parse(JSON.stringify(res.payload));
+					this.ready     = true;
+				},
+				this.$root.genericError
+			);
+		},
+		set() {
+			if(!this.isChanged) return;
+
+			let requests = [ws.prepare('repoServe','set',this.serve)];
+			if(this.config.repoServeActive !== this.configInput.repoServeActive)
+				requests.push(ws.prepare('config','set',this.configInput));
+
+			ws.sendMultiple(requests,true).then(
+				this.get,
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -0,0 +1,297 @@
+import MyAdminLoginMeta        from './adminLoginMeta.js';
+import MyAdminLoginRolesAssign from './adminLoginRolesAssign.js';
+import {dialogDeleteAsk}       from '../shared/dialog.js';
+import {deepIsEqual}           from '../shared/generic.js';
+
+export default {
+	name:'my-admin-saml-idp',
+	components:{
+		MyAdminLoginMeta,
+		MyAdminLoginRolesAssign
+	},
+	template:`<div v-if="ready" class="app-sub-window under-header at-top with-margin" @mousedown.self="$emit('close')">
+		
+		<div class="contentBox admin-saml-idp scroll float">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/lockCog.png" />
+					<h1 class="title">{{ isNew ? capApp.titleNew : capApp.title.replace('{NAME}',inputs.name) }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="canSave"
+						:caption="isNew ? capGen.button.create : capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						v-if="!isNew"
+						@trigger="reset"
+						:active="isChanged"
+						:caption="capGen.button.refresh"
+					/>
+					<my-button image="add.png"
+						v-if="!isNew"
+						@trigger="$emit('makeNew')"
+						:active="!readonly"
+						:caption="capGen.button.new"
+					/>
+				</div>
+				<div class="area">
+					<my-button image="delete.png"
+						v-if="!isNew"
+						@trigger="dialogDeleteAsk(del,capApp.dialog.delete)"
+						:active="!readonly"
+						:cancel="true"
+						:caption="capGen.button.delete"
+					/>
+				</div>
+			</div>
+			
+			<div class="content no-padding default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capGen.name }}*</td>
+							<td><input v-model="inputs.name" :disabled="readonly" v-focus /></td>
+							<td>{{ capApp.nameHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.metadata }}</td>
+							<td colspan="2">
+								<div class="column gap">
+									<textarea v-model="metadata" :disabled="readonly" :placeholder="capApp.metadataPlaceholder"></textarea>
+									<div class="row">
+										<my-button image="upload.png"
+											@trigger="parseMetadata"
+											:active="!readonly && metadata !== ''"
+											:caption="capApp.button.parseMetadata"
+										/>
+									</div>
+									<span v-html="capApp.metadataHint" />
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.entityId }}*</td>
+							<td><input v-model="inputs.entityId" :disabled="readonly" /></td>
+							<td>{{ capApp.entityIdHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.ssoUrl }}*</td>
+							<td><input v-model="inputs.ssoUrl" :disabled="readonly" /></td>
+							<td>{{ capApp.ssoUrlHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.certificate }}*</td>
+							<td><textarea v-model="inputs.certificate" :disabled="readonly"></textarea></td>
+							<td>{{ capApp.certificateHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.spEntityId }}*</td>
+							<td><input v-model="inputs.spEntityId" :disabled="readonly" /></td>
+							<td>{{ capApp.spEntityIdHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.acsUrl }}*</td>
+							<td><input v-model="inputs.acsUrl" :disabled="readonly" /></td>
+							<td>{{ capApp.acsUrlHint }}</td>
+						</tr>
+						<tr v-if="!isNew">
+							<td>{{ capApp.spMetadataUrl }}</td>
+							<td><a :href="spMetadataUrl" target="_blank">{{ spMetadataUrl }}</a></td>
+							<td>{{ capApp.spMetadataUrlHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capGen.loginTemplate }}</td>
+							<td>
+								<select v-model="inputs.loginTemplateId" :disabled="readonly">
+									<option v-for="t in loginTemplates" :title="t.comment" :value="t.id">{{ t.name }}</option>
+								</select>
+							</td>
+							<td>{{ capGen.loginTemplateHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.attributeUsername }}</td>
+							<td><input v-model="inputs.attributeUsername" :disabled="readonly" /></td>
+							<td>{{ capApp.attributeUsernameHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.attributeAdmin }}</td>
+							<td>
+								<div class="column gap">
+									<input v-model="inputs.attributeAdmin"      :disabled="readonly" :placeholder="capGen.name" />
+									<input v-model="inputs.attributeAdminValue" :disabled="readonly" :placeholder="capGen.valueExpected" />
+									<div class="row">
+										<my-button image="cancel.png"
+											@trigger="inputs.attributeAdmin = null; inputs.attributeAdminValue = null"
+											:active="inputs.attributeAdmin !== null && inputs.attributeAdmin !== ''"
+											:cancel="true"
+											:caption="capGen.button.clear"
+										/>
+									</div>
+								</div>
+							</td>
+							<td>{{ capApp.attributeAdminHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.attributeRoles }}</td>
+							<td colspan="2">
+								<div class="column gap">
+									<input v-model="inputs.attributeRoles" :disabled="readonly" />
+									<span>{{ capApp.attributeRolesHint }}</span>
+									<my-admin-login-roles-assign
+										v-model="inputs.loginRolesAssign"
+										:readonly="readonly || !isAttributeRolesSet"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td colspan="3">
+								<span>{{ capApp.loginMetaMap }}</span>
+								<my-admin-login-meta
+									v-model="inputs.loginMetaMap"
+									:is-mapper="true"
+									:readonly="readonly"
+								/>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		id:            { type:Number,  required:true },
+		loginTemplates:{ type:Array,   required:true },
+		readonly:      { type:Boolean, required:true },
+		samlIdpIdMap:  { type:Object,  required:true }
+	},
+	emits:['close','makeNew'],
+	watch:{
+		id:{
+			handler(v) { this.reset(); },
+			immediate:true
+		},
+	},
+	data() {
+		return {
+			inputs:{},
+			metadata:'',
+			ready:false
+		};
+	},
+	computed:{
+		canSave:s =>
+			s.ready &&
+			!s.readonly &&
+			s.isChanged &&
+			s.inputs.name        !== '' &&
+			s.inputs.entityId    !== '' &&
+			s.inputs.ssoUrl      !== '' &&
+			s.inputs.certificate !== '' &&
+			s.inputs.spEntityId  !== '' &&
+			s.inputs.acsUrl      !== '',
+		inputsOrg:s => s.isNew ? {
+			id:0,
+			loginTemplateId:null,
+			name:'',
+			entityId:'',
+			ssoUrl:'',
+			certificate:'',
+			spEntityId:`${location.origin}/saml/metadata/{ID}`,
+			acsUrl:`${location.origin}/saml/acs/{ID}`,
+			loginMetaMap:{},
+			loginRolesAssign:[],
+			attributeAdmin:null,
+			attributeAdminValue:null,
+			attributeRoles:null,
+			attributeUsername:null
+		} : s.samlIdpIdMap[s.id],
+		
+		// simple
+		isAttributeRolesSet:s => s.inputs.attributeRoles !== null && s.inputs.attributeRoles !== '',
+		isChanged:          s => !s.deepIsEqual(s.inputsOrg,s.inputs),
+		isNew:              s => s.id === 0,
+		spMetadataUrl:      s => `${location.origin}/saml/metadata/${s.id}`,
+		
+		// stores
+		capApp:s => s.$store.getters.captions.admin.samlIdp,
+		capGen:s => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.set,key:'s',keyCtrl:true});
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.close,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.set);
+		this.$store.commit('keyDownHandlerDel',this.close);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		// external
+		deepIsEqual,
+		dialogDeleteAsk,
+		
+		// actions
+		close() {
+			this.$emit('close');
+		},
+		reloadAndClose() {
+			ws.send('samlIdp','reload',{},true).then(
+				() => this.$emit('close'),
+				this.$root.genericError
+			);
+		},
+		reset() {
+			this.inputs   = JSON.parse(JSON.stringify(this.inputsOrg));
+			this.metadata = '';
+			
+			if(this.isNew && this.loginTemplates.length > 0)
+				this.inputs.loginTemplateId = this.loginTemplates[0].id;
+			
+			this.ready = true;
+		},
+		
+		// backend calls
+		del() {
+			ws.send('samlIdp','del',this.id,true).then(
+				this.reloadAndClose,
+				this.$root.genericError
+			);
+		},
+		parseMetadata() {
+			ws.send('samlIdp','parseMetadata',this.metadata,true).then(
+				res => {
+					this.inputs.entityId    = res.payload.entityId;
+					this.inputs.ssoUrl      = res.payload.ssoUrl;
+					this.inputs.certificate = res.payload.certificate;
+					this.metadata           = '';
+				},
+				this.$root.genericError
+			);
+		},
+		set() {
+			if(!this.canSave) return;
+			
+			if(this.inputs.attributeAdmin      === '') this.inputs.attributeAdmin      = null;
+			if(this.inputs.attributeAdminValue === '') this.inputs.attributeAdminValue = null;
+			if(this.inputs.attributeRoles      === '') this.inputs.attributeRoles      = null;
+			if(this.inputs.attributeUsername   === '') this.inputs.attributeUsername   = null;
+			
+			ws.send('samlIdp','set',this.inputs,true).then(
+				this.reloadAndClose,
+				this.$root.genericError
+			);
+		}
+	}
+};
\ No newline at end of file
@@ -0,0 +1,88 @@
+import MyAdminSamlIdp from './adminSamlIdp.js';
+
+export default {
+	name:'my-admin-saml-idps',
+	components:{ MyAdminSamlIdp },
+	template:`<div class="admin-saml-idp contentBox grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/lockCog.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="add.png"
+					@trigger="idOpen = 0"
+					:active="licenseValid && isAdmin"
+					:caption="capGen.button.new"
+				/>
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+			</div>
+		</div>
+		
+		<div class="content grow">
+			<div class="generic-entry-list wide">
+				<div class="entry clickable"
+					v-for="(p,k) in samlIdpIdMap"
+					@click="idOpen = p.id"
+					:key="p.id"
+					:title="p.name"
+				>
+					<div class="lines">
+						<span>{{ p.name }}</span>
+						<span class="subtitle">{{ p.entityId }}</span>
+					</div>
+				</div>
+			</div>
+			
+			<my-admin-saml-idp
+				v-if="idOpen !== null"
+				@close="idOpen = null;get()"
+				@makeNew="idOpen = 0"
+				:id="idOpen"
+				:loginTemplates
+				:readonly="!licenseValid || !isAdmin"
+				:samlIdpIdMap
+			/>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			loginTemplates:[],
+			samlIdpIdMap:{},
+			idOpen:null
+		};
+	},
+	computed:{
+		// stores
+		capGen:      s => s.$store.getters.captions.generic,
+		isAdmin:     s => s.$store.getters.isAdmin,
+		licenseValid:s => s.$store.getters.licenseValid
+	},
+	mounted() {
+		this.get();
+		this.$store.commit('pageTitle',this.menuTitle);
+	},
+	methods:{
+		// backend calls
+		get() {
+			ws.sendMultiple([
+				ws.prepare('samlIdp','get',{}),
+				ws.prepare('loginTemplate','get',{byId:0})
+			],true).then(
+				res => {
+					this.samlIdpIdMap   = res[0].payload;
+					this.loginTemplates = res[1].payload;
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
\ No newline at end of file
@@ -1,10 +1,16 @@
-import {getStringFilled} from '../shared/generic.js';
-import srcBase64Icon     from '../shared/image.js';
-import {getUnixFormat}   from '../shared/time.js';
-import {getCaption}      from '../shared/language.js';
+import MyAdminSchedulerJobs from './adminSchedulerJobs.js';
+import MyAdminSchedulerRuns from './adminSchedulerRuns.js';
+import {getStringFilled}    from '../shared/generic.js';
+import srcBase64Icon        from '../shared/image.js';
+import {getUnixFormat}      from '../shared/time.js';
+import {getCaption}         from '../shared/language.js';
 
 export default {
 	name:'my-admin-scheduler',
+	components:{
+		MyAdminSchedulerJobs,
+		MyAdminSchedulerRuns
+	},
 	template:`<div class="admin-scheduler contentBox grow">
 		<div class="top">
 			<div class="area">
@@ -24,6 +30,12 @@ export default {
 					:caption="capGen.button.refresh"
 				/>
 			</div>
+			<div class="area">
+				<my-button image="tasks.png"
+					@trigger="showJobs = true"
+					:caption="capApp.button.jobs"
+				/>
+			</div>
 		</div>
 		
 		<div class="content no-padding">
@@ -64,11 +76,17 @@ export default {
 									/>
 								</td>
 								<td>
-									<my-button image="clock.png"
-										@trigger="runSystemTask(s.taskName)"
-										:active="!mirrorMode || !tasksDisabledMirrorMode.includes(s.taskName) ? schedulersInput[i].active : false"
-										:caption="capApp.button.runNow"
-									/>
+									<div class="row gap">
+										<my-button image="clock.png"
+											@trigger="runSystemTask(s.taskName)"
+											:active="!mirrorMode || !tasksDisabledMirrorMode.includes(s.taskName) ? schedulersInput[i].active : false"
+											:caption="capApp.button.runNow"
+										/>
+										<my-button image="log.png"
+											@trigger="showRuns(s.id,displayName(s.taskName),true)"
+											:caption="capApp.button.runs"
+										/>
+									</div>
 								</td>
 							</tr>
 						</template>
@@ -113,12 +131,18 @@ export default {
 										/>
 									</td>
 									<td>
-										<my-button image="clock.png"
-											@trigger="runSystemTask(s.taskName)"
-											:active="schedulers[i].active"
-											:caption="capApp.button.runNow"
-											:captionTitle="capApp.button.runNowHint"
-										/>
+										<div class="row gap">
+											<my-button image="clock.png"
+												@trigger="runSystemTask(s.taskName)"
+												:active="schedulers[i].active"
+												:caption="capApp.button.runNow"
+												:captionTitle="capApp.button.runNowHint"
+											/>
+											<my-button image="log.png"
+												@trigger="showRuns(s.id,displayName(s.taskName),true)"
+												:caption="capApp.button.runs"
+											/>
+										</div>
 									</td>
 								</tr>
 								<tr v-if="schedulersExpanded.includes(i)" v-for="meta in s.nodeMeta">
@@ -163,16 +187,37 @@ export default {
 							<td>{{ displayTime(s.dateAttempt) }}</td>
 							<td>{{ displayTime(s.dateSuccess) }}</td>
 							<td>
-								<my-button image="clock.png"
-									@trigger="runPgFunction(s.pgFunctionId,s.pgFunctionScheduleId)"
-									:caption="capApp.button.runNow"
-								/>
+								<div class="row gap">
+									<my-button image="clock.png"
+										@trigger="runPgFunction(s.pgFunctionId,s.pgFunctionScheduleId)"
+										:caption="capApp.button.runNow"
+									/>
+									<my-button image="log.png"
+										@trigger="showRuns(s.id,displayFunctionName(s.pgFunctionId),false)"
+										:caption="capApp.button.runs"
+									/>
+								</div>
 							</td>
 						</tr>
 					</tbody>
 				</table>
 			</div>
 		</div>
+		
+		<!-- run history & alerts of single schedule -->
+		<my-admin-scheduler-runs
+			v-if="runsScheduleId !== null"
+			@close="runsScheduleId = null"
+			:name="runsName"
+			:runsReduced
+			:scheduleId="runsScheduleId"
+		/>
+		
+		<!-- background job queues -->
+		<my-admin-scheduler-jobs
+			v-if="showJobs"
+			@close="showJobs = false"
+		/>
 	</div>`,
 	props:{
 		menuTitle:{ type:String, required:true }
@@ -180,8 +225,12 @@ export default {
 	data() {
 		return {
 			schedulers:[],
-			schedulersInput:[],    // changes to schedulers
-			schedulersExpanded:[], // indexes of schedules that show all nodes
+			schedulersInput:[],     // changes to schedulers
+			schedulersExpanded:[],  // indexes of schedules that show all nodes
+			runsName:'',            // name of task to show run history for
+			runsReduced:false,      // task to show run history for has reduced run history
+			runsScheduleId:null,    // ID of schedule to show run history for
+			showJobs:false,         // show background job queues
 			tasksDisabledMirrorMode:['adminMails','backupRun','mailAttach','mailRetrieve','mailSend','restExecute']
 		};
 	},
@@ -253,6 +302,15 @@ export default {
 			let parts    = [];
 			let typeName = '';
 			
+			if(s.intervalType === 'cron') {
+				parts.push(this.capApp.scheduleLineCron.replace('{EXPR}',s.cronExpr));
+				
+				if(s.timeZone !== null)
+					parts.push(s.timeZone);
+				
+				return parts.join(', ');
+			}
+			
 			switch(s.intervalType) {
 				case 'days':    typeName = this.capApp.intervalTypeDays;    break;
 				case 'hours':   typeName = this.capApp.intervalTypeHours;   break;
@@ -281,8 +339,19 @@ export default {
 					.replace('{SS}',this.getStringFilled(s.atSecond,2,'0'))
 				);
 			
+			if(s.timeZone !== null)
+				parts.push(s.timeZone);
+			
 			return parts.join(', ');
 		},
+		showRuns(scheduleId,name,isSystemTask) {
+			// system tasks running more often than every 10 minutes have reduced run history, see backend
+			const s = this.schedulers.find(v => v.id === scheduleId);
+			
+			this.runsName       = name;
+			this.runsReduced    = isSystemTask && s !== undefined && s.intervalValue < 600;
+			this.runsScheduleId = scheduleId;
+		},
 		expandScheduler(i) {
 			let pos = this.schedulersExpanded.indexOf(i);
 			
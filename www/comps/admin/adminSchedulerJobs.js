@@ -0,0 +1,302 @@
+import MyInputOffset   from '../inputOffset.js';
+import {dialogCloseAsk} from '../shared/dialog.js';
+import {getCaption}     from '../shared/language.js';
+import {getUnixFormat}  from '../shared/time.js';
+
+export default {
+	name:'my-admin-scheduler-jobs',
+	components:{ MyInputOffset },
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="closeAsk">
+		<div class="contentBox admin-scheduler-jobs float scroll">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/tasks.png" />
+					<h1 class="title">{{ capApp.jobs }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png" @trigger="closeAsk" :cancel="true" />
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="setQueues"
+						:active="hasChanges"
+						:caption="capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						@trigger="get"
+						:caption="capGen.button.refresh"
+					/>
+				</div>
+			</div>
+
+			<div class="content default-inputs">
+				<!-- queues -->
+				<my-label image="tasks.png" :caption="capApp.jobQueues" :large="true" />
+				<p>{{ capApp.jobQueuesHint }}</p>
+				<table class="generic-table bright">
+					<thead>
+						<tr>
+							<th>{{ capGen.name }}</th>
+							<th>{{ capApp.jobQueueConcurrency }}</th>
+							<th>{{ capApp.jobState.waiting }}</th>
+							<th>{{ capApp.jobState.running }}</th>
+							<th>{{ capApp.jobState.failed }}</th>
+							<th></th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="(q,i) in queuesInput">
+							<td>{{ q.name }}</td>
+							<td><input class="short" v-model.number="queuesInput[i].concurrency" /></td>
+							<td>{{ q.waiting }}</td>
+							<td>{{ q.running }}</td>
+							<td>{{ q.failed }}</td>
+							<td>
+								<div class="row gap">
+									<my-button image="search.png"
+										@trigger="queue = q.name;offset = 0;getJobs()"
+										:caption="capApp.button.jobsShow"
+									/>
+									<my-button image="delete.png"
+										@trigger="delQueueAsk(q.name)"
+										:active="q.running === 0"
+										:cancel="true"
+										:captionTitle="capGen.button.delete"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr v-if="queuesInput.length === 0">
+							<td colspan="6">{{ capGen.nothingThere }}</td>
+						</tr>
+					</tbody>
+				</table>
+
+				<!-- jobs -->
+				<br />
+				<div class="row gap centered space-between">
+					<my-label image="tasks.png" :caption="capApp.jobs" :large="true" />
+					<div class="row gap centered">
+						<select v-model="queue" @change="offset = 0;getJobs()">
+							<option value="">{{ capApp.jobQueuesAll }}</option>
+							<option v-for="q in queues" :value="q.name">{{ q.name }}</option>
+						</select>
+						<select v-model="state" @change="offset = 0;getJobs()">
+							<option value="">{{ capApp.jobStatesAll }}</option>
+							<option v-for="s in states" :value="s">{{ capApp.jobState[s] }}</option>
+						</select>
+						<my-input-offset
+							@input="offset = $event;getJobs()"
+							:caption="true"
+							:limit="limit"
+							:offset="offset"
+							:total="total"
+						/>
+					</div>
+				</div>
+				<table class="generic-table bright">
+					<thead>
+						<tr>
+							<th>{{ capGen.id }}</th>
+							<th>{{ capApp.jobFunction }}</th>
+							<th>{{ capApp.jobQueue }}</th>
+							<th>{{ capApp.jobPriority }}</th>
+							<th>{{ capApp.jobState.title }}</th>
+							<th>{{ capApp.jobAttempts }}</th>
+							<th>{{ capApp.jobDateRunAfter }}</th>
+							<th>{{ capApp.runStart }}</th>
+							<th>{{ capApp.runDuration }}</th>
+							<th>{{ capApp.runNode }}</th>
+							<th>{{ capApp.jobResult }}</th>
+							<th></th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="j in jobs">
+							<td>{{ j.id }}</td>
+							<td :title="j.args !== null ? j.args : ''">{{ displayFunctionName(j.pgFunctionId) }}</td>
+							<td>{{ j.queue }}</td>
+							<td>{{ j.priority }}</td>
+							<td>
+								<my-label
+									:caption="capApp.jobState[j.state]"
+									:image="displayStateImage(j.state)"
+								/>
+							</td>
+							<td>{{ j.attempts }} / {{ j.attemptsMax }}</td>
+							<td>{{ displayTime(j.dateRunAfter) }}</td>
+							<td>{{ j.dateMilliStart !== null ? displayTime(Math.floor(j.dateMilliStart / 1000)) : '-' }}</td>
+							<td>{{ j.dateMilliStart !== null && j.dateMilliEnd !== null ? displayDuration(j.dateMilliEnd - j.dateMilliStart) : '-' }}</td>
+							<td>{{ j.nodeName !== null ? j.nodeName : '-' }}</td>
+							<td class="admin-scheduler-runs-error">{{ j.errorMessage !== null ? j.errorMessage : (j.result !== null ? j.result : '') }}</td>
+							<td>
+								<div class="row gap">
+									<my-button image="refresh.png"
+										v-if="j.state === 'failed'"
+										@trigger="retry(j.id)"
+										:captionTitle="capApp.button.jobRetry"
+									/>
+									<my-button image="delete.png"
+										@trigger="del(j.id)"
+										:active="j.state !== 'running'"
+										:cancel="true"
+										:captionTitle="capGen.button.delete"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr v-if="jobs.length === 0">
+							<td colspan="12">{{ capGen.nothingThere }}</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	emits:['close'],
+	data() {
+		return {
+			jobs:[],
+			limit:50,
+			offset:0,
+			queue:'',        // filter jobs by queue, empty for all
+			queues:[],
+			queuesInput:[],  // changes to queue concurrency
+			state:'',        // filter jobs by state, empty for all
+			states:['waiting','running','done','failed'],
+			total:0
+		};
+	},
+	computed:{
+		// simple
+		hasChanges:s => JSON.stringify(s.queues) !== JSON.stringify(s.queuesInput),
+
+		// stores
+		pgFunctionIdMap:s => s.$store.getters['schema/pgFunctionIdMap'],
+		capApp:         s => s.$store.getters.captions.admin.scheduler,
+		capGen:         s => s.$store.getters.captions.generic,
+		settings:       s => s.$store.getters.settings
+	},
+	mounted() {
+		this.get();
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.setQueues,key:'s',keyCtrl:true});
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.closeAsk,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.setQueues);
+		this.$store.commit('keyDownHandlerDel',this.closeAsk);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		// externals
+		dialogCloseAsk,
+		getCaption,
+		getUnixFormat,
+
+		// presentation
+		displayDuration(ms) {
+			return ms < 1000 ? `${ms} ms` : `${(ms / 1000).toFixed(1)} s`;
+		},
+		displayFunctionName(pgFunctionId) {
+			const f = this.pgFunctionIdMap[pgFunctionId];
+			return f === undefined ? pgFunctionId : this.getCaption('pgFunctionTitle',f.moduleId,f.id,f.captions,f.name);
+		},
+		displayStateImage(state) {
+			switch(state) {
+				case 'done':    return 'ok.png';      break;
+				case 'failed':  return 'warning.png'; break;
+				case 'running': return 'clock.png';   break;
+			}
+			return 'tasks.png';
+		},
+		displayTime(unixTime) {
+			return this.getUnixFormat(unixTime,`${this.settings.dateFormat} H:i:S`);
+		},
+
+		// actions
+		close() {
+			this.$emit('close');
+		},
+		closeAsk() {
+			this.dialogCloseAsk(this.close,this.hasChanges);
+		},
+		delQueueAsk(name) {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.jobQueueDelete.replace('{NAME}',name),
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.delete,
+					exec:() => this.delQueue(name),
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+
+		// backend calls
+		get() {
+			this.getQueues();
+			this.getJobs();
+		},
+		getJobs() {
+			ws.send('job','get',{
+				limit:this.limit,
+				offset:this.offset,
+				queue:this.queue,
+				state:this.state
+			},true).then(
+				res => {
+					this.jobs  = res.payload.jobs;
+					this.total = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		getQueues() {
+			ws.send('jobQueue','get',{},true).then(
+				res => {
+					this.queues      = res.payload;
+					this.queuesInput = JSON.parse(JSON.stringify(res.payload));
+				},
+				this.$root.genericError
+			);
+		},
+		del(id) {
+			ws.send('job','del',{ids:[id]},true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		delQueue(name) {
+			ws.send('jobQueue','del',{name:name},true).then(
+				() => {
+					if(this.queue === name)
+						this.queue = '';
+
+					this.get();
+				},
+				this.$root.genericError
+			);
+		},
+		retry(id) {
+			ws.send('job','retry',{ids:[id]},true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		setQueues() {
+			if(!this.hasChanges)
+				return;
+
+			ws.send('jobQueue','set',this.queuesInput,true).then(
+				this.getQueues,
+				this.$root.genericError
+			);
+		}
+	}
+};
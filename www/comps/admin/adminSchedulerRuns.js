@@ -0,0 +1,227 @@
+import MyInputOffset   from '../inputOffset.js';
+import {dialogCloseAsk} from '../shared/dialog.js';
+import {getUnixFormat}  from '../shared/time.js';
+
+export default {
+	name:'my-admin-scheduler-runs',
+	components:{ MyInputOffset },
+	template:`<div class="app-sub-window under-header at-top with-margin" @mousedown.self="closeAsk">
+		<div class="contentBox admin-scheduler-runs float scroll">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/log.png" />
+					<h1 class="title">{{ name }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png" @trigger="closeAsk" :cancel="true" />
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="setAlerts"
+						:active="hasChanges"
+						:caption="capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						@trigger="get"
+						:caption="capGen.button.refresh"
+					/>
+				</div>
+			</div>
+
+			<div class="content default-inputs">
+				<!-- alerts -->
+				<my-label image="bell.png" :caption="capApp.alerts" :large="true" />
+				<p>{{ capApp.alertsHint }}</p>
+				<table class="generic-table bright">
+					<tbody>
+						<tr v-for="c in alertContents">
+							<td class="minimum">
+								<my-bool
+									@update:modelValue="toggleAlert(c,$event)"
+									:modelValue="alertsInput[c] !== undefined"
+								/>
+							</td>
+							<td>{{ capApp.alertContent[c] }}</td>
+							<td>
+								<input class="short"
+									v-if="alertsInput[c] !== undefined"
+									v-model.number="alertsInput[c]"
+								/>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+
+				<!-- run history -->
+				<br />
+				<div class="row gap centered space-between">
+					<my-label image="log.png" :caption="capApp.runs" :large="true" />
+					<div class="row gap centered">
+						<my-bool
+							@update:modelValue="onlyFailed = $event;offset = 0;getRuns()"
+							:caption0="capApp.runsAll"
+							:caption1="capApp.runsFailed"
+							:modelValue="onlyFailed"
+						/>
+						<my-input-offset
+							@input="offset = $event;getRuns()"
+							:caption="true"
+							:limit="limit"
+							:offset="offset"
+							:total="total"
+						/>
+					</div>
+				</div>
+				<p v-if="runsReduced">{{ capApp.runsReducedHint }}</p>
+				<table class="generic-table bright">
+					<thead>
+						<tr>
+							<th>{{ capApp.runStart }}</th>
+							<th>{{ capApp.runDuration }}</th>
+							<th>{{ capApp.runNode }}</th>
+							<th>{{ capApp.runResult }}</th>
+							<th>{{ capApp.runError }}</th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="r in runs">
+							<td>{{ displayTime(r.dateStart) }}</td>
+							<td>{{ r.duration !== null ? displayDuration(r.duration) : '-' }}</td>
+							<td>{{ r.nodeName !== null ? r.nodeName : '-' }}</td>
+							<td>
+								<my-label
+									:caption="displayResult(r)"
+									:image="r.success === null ? 'clock.png' : (r.success ? 'ok.png' : 'warning.png')"
+								/>
+							</td>
+							<td class="admin-scheduler-runs-error">{{ r.errorMessage !== null ? r.errorMessage : '' }}</td>
+						</tr>
+						<tr v-if="runs.length === 0">
+							<td colspan="5">{{ capGen.nothingThere }}</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		name:       { type:String,  required:true },
+		runsReduced:{ type:Boolean, required:true }, // only failed runs & changes to run outcome are stored, no runtime alerts
+		scheduleId: { type:Number,  required:true }
+	},
+	emits:['close'],
+	data() {
+		return {
+			alerts:{},      // alert thresholds by content
+			alertsInput:{}, // changes to alert thresholds
+			limit:50,
+			offset:0,
+			onlyFailed:false,
+			runs:[],
+			total:0
+		};
+	},
+	computed:{
+		alertContents:s => s.runsReduced ? ['failures','missed'] : ['failures','runtime','missed'],
+		
+		// simple
+		hasChanges:s => JSON.stringify(s.alerts) !== JSON.stringify(s.alertsInput),
+
+		// stores
+		capApp:  s => s.$store.getters.captions.admin.scheduler,
+		capGen:  s => s.$store.getters.captions.generic,
+		settings:s => s.$store.getters.settings
+	},
+	mounted() {
+		this.get();
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.setAlerts,key:'s',keyCtrl:true});
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.closeAsk,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.setAlerts);
+		this.$store.commit('keyDownHandlerDel',this.closeAsk);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		// externals
+		dialogCloseAsk,
+		getUnixFormat,
+
+		// presentation
+		displayDuration(ms) {
+			return ms < 1000 ? `${ms} ms` : `${(ms / 1000).toFixed(1)} s`;
+		},
+		displayResult(r) {
+			if(r.success === null) return this.capApp.runRunning;
+			return r.success ? this.capApp.runSuccess : this.capApp.runFailed;
+		},
+		displayTime(unixTime) {
+			return this.getUnixFormat(unixTime,`${this.settings.dateFormat} H:i:S`);
+		},
+
+		// actions
+		close() {
+			this.$emit('close');
+		},
+		closeAsk() {
+			this.dialogCloseAsk(this.close,this.hasChanges);
+		},
+		toggleAlert(content,state) {
+			if(state) this.alertsInput[content] = content === 'failures' ? 3 : 3600;
+			else      delete this.alertsInput[content];
+		},
+
+		// backend calls
+		get() {
+			this.getAlerts();
+			this.getRuns();
+		},
+		getAlerts() {
+			ws.send('scheduler','getAlerts',{},true).then(
+				res => {
+					let alerts = {};
+					for(const a of res.payload) {
+						if(a.scheduleId === this.scheduleId && this.alertContents.includes(a.content))
+							alerts[a.content] = a.threshold;
+					}
+					this.alerts      = alerts;
+					this.alertsInput = JSON.parse(JSON.stringify(alerts));
+				},
+				this.$root.genericError
+			);
+		},
+		getRuns() {
+			ws.send('scheduler','getRuns',{
+				limit:this.limit,
+				offset:this.offset,
+				onlyFailed:this.onlyFailed,
+				scheduleId:this.scheduleId
+			},true).then(
+				res => {
+					this.runs  = res.payload.runs;
+					this.total = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		setAlerts() {
+			if(!this.hasChanges)
+				return;
+
+			let alerts = [];
+			for(const c in this.alertsInput) {
+				alerts.push({ content:c, threshold:this.alertsInput[c] });
+			}
+			ws.send('scheduler','setAlerts',{
+				alerts:alerts,
+				scheduleId:this.scheduleId
+			},true).then(
+				this.getAlerts,
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -0,0 +1,202 @@
+import MyAdminLoginRolesAssign from './adminLoginRolesAssign.js';
+import {dialogDeleteAsk}       from '../shared/dialog.js';
+import {deepIsEqual}           from '../shared/generic.js';
+
+export default {
+	name:'my-admin-scim-client',
+	components:{ MyAdminLoginRolesAssign },
+	template:`<div v-if="ready" class="app-sub-window under-header at-top with-margin" @mousedown.self="$emit('close')">
+		
+		<div class="contentBox admin-scim-client scroll float">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/personServer.png" />
+					<h1 class="title">{{ isNew ? capApp.titleNew : capApp.title.replace('{NAME}',inputs.name) }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="canSave"
+						:caption="isNew ? capGen.button.create : capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						v-if="!isNew"
+						@trigger="reset"
+						:active="isChanged || tokenRenew"
+						:caption="capGen.button.refresh"
+					/>
+					<my-button image="add.png"
+						v-if="!isNew"
+						@trigger="$emit('makeNew')"
+						:active="!readonly"
+						:caption="capGen.button.new"
+					/>
+				</div>
+				<div class="area">
+					<my-button image="delete.png"
+						v-if="!isNew"
+						@trigger="dialogDeleteAsk(del,capApp.dialog.delete)"
+						:active="!readonly"
+						:cancel="true"
+						:caption="capGen.button.delete"
+					/>
+				</div>
+			</div>
+			
+			<div class="content no-padding default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capGen.name }}*</td>
+							<td><input v-model="inputs.name" :disabled="readonly" v-focus /></td>
+							<td>{{ capApp.nameHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.endpointUrl }}</td>
+							<td><input :value="endpointUrl" disabled /></td>
+							<td>{{ capApp.endpointUrlHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.token }}*</td>
+							<td>
+								<span v-if="isNew || tokenRenew">{{ capApp.tokenGenerated }}</span>
+								<my-button image="refresh.png"
+									v-if="!isNew && !tokenRenew"
+									@trigger="tokenRenew = true"
+									:active="!readonly"
+									:caption="capApp.button.generateToken"
+								/>
+							</td>
+							<td>{{ capApp.tokenHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capGen.loginTemplate }}</td>
+							<td>
+								<select v-model="inputs.loginTemplateId" :disabled="readonly">
+									<option v-for="t in loginTemplates" :title="t.comment" :value="t.id">{{ t.name }}</option>
+								</select>
+							</td>
+							<td>{{ capGen.loginTemplateHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.loginRolesAssign }}</td>
+							<td colspan="2">
+								<div class="column gap">
+									<span>{{ capApp.loginRolesAssignHint }}</span>
+									<my-admin-login-roles-assign
+										v-model="inputs.loginRolesAssign"
+										:readonly="readonly"
+									/>
+								</div>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		endpointUrl:   { type:String,  required:true },
+		id:            { type:Number,  required:true },
+		loginTemplates:{ type:Array,   required:true },
+		readonly:      { type:Boolean, required:true },
+		scimClients:   { type:Array,   required:true }
+	},
+	emits:['close','makeNew'],
+	watch:{
+		id:{
+			handler(v) { this.reset(); },
+			immediate:true
+		},
+	},
+	data() {
+		return {
+			inputs:{},
+			ready:false,
+			tokenRenew:false
+		};
+	},
+	computed:{
+		canSave:s =>
+			s.ready &&
+			!s.readonly &&
+			(s.isChanged || s.tokenRenew) &&
+			s.inputs.name !== '',
+		inputsOrg:s => s.isNew ? {
+			id:0,
+			loginTemplateId:null,
+			name:'',
+			loginRolesAssign:[]
+		} : s.scimClients.find(v => v.id === s.id),
+		
+		// simple
+		isChanged:s => !s.deepIsEqual(s.inputsOrg,s.inputs),
+		isNew:    s => s.id === 0,
+		
+		// stores
+		capApp:s => s.$store.getters.captions.admin.scimClient,
+		capGen:s => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.set,key:'s',keyCtrl:true});
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.close,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.set);
+		this.$store.commit('keyDownHandlerDel',this.close);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		// external
+		deepIsEqual,
+		dialogDeleteAsk,
+		
+		// actions
+		close() {
+			this.$emit('close');
+		},
+		reset() {
+			this.inputs     = JSON.parse(JSON.stringify(this.inputsOrg));
+			this.tokenRenew = false;
+			
+			if(this.isNew && this.loginTemplates.length > 0)
+				this.inputs.loginTemplateId = this.loginTemplates[0].id;
+			
+			this.ready = true;
+		},
+		
+		// backend calls
+		del() {
+			ws.send('scimClient','del',{id:this.id},true).then(
+				() => this.$emit('close'),
+				this.$root.genericError
+			);
+		},
+		set() {
+			if(!this.canSave) return;
+			
+			ws.send('scimClient','set',{...this.inputs,tokenRenew:this.tokenRenew},true).then(
+				res => {
+					// token is only stored as hash, it can only be shown once
+					if(res.payload !== '')
+						this.$store.commit('dialog',{
+							captionBody:`${this.capApp.dialog.token}\n\n${res.payload}`,
+							textDisplay:'textarea'
+						});
+					
+					this.$emit('close');
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
\ No newline at end of file
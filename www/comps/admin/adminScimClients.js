@@ -0,0 +1,91 @@
+import MyAdminScimClient from './adminScimClient.js';
+
+export default {
+	name:'my-admin-scim-clients',
+	components:{ MyAdminScimClient },
+	template:`<div class="admin-scim-client contentBox grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/personServer.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="add.png"
+					@trigger="idOpen = 0"
+					:active="licenseValid && isAdmin"
+					:caption="capGen.button.new"
+				/>
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+			</div>
+		</div>
+		
+		<div class="content grow">
+			<div class="generic-entry-list wide">
+				<div class="entry clickable"
+					v-for="c in scimClients"
+					@click="idOpen = c.id"
+					:key="c.id"
+					:title="c.name"
+				>
+					<div class="lines">
+						<span>{{ c.name }}</span>
+						<span class="subtitle">{{ endpointUrl }}</span>
+					</div>
+				</div>
+			</div>
+			
+			<my-admin-scim-client
+				v-if="idOpen !== null"
+				@close="idOpen = null;get()"
+				@makeNew="idOpen = 0"
+				:endpointUrl
+				:id="idOpen"
+				:loginTemplates
+				:readonly="!licenseValid || !isAdmin"
+				:scimClients
+			/>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			loginTemplates:[],
+			scimClients:[],
+			idOpen:null
+		};
+	},
+	computed:{
+		endpointUrl:s => `${location.origin}/scim/v2`,
+		
+		// stores
+		capGen:      s => s.$store.getters.captions.generic,
+		isAdmin:     s => s.$store.getters.isAdmin,
+		licenseValid:s => s.$store.getters.licenseValid
+	},
+	mounted() {
+		this.get();
+		this.$store.commit('pageTitle',this.menuTitle);
+	},
+	methods:{
+		// backend calls
+		get() {
+			ws.sendMultiple([
+				ws.prepare('scimClient','get',{}),
+				ws.prepare('loginTemplate','get',{byId:0})
+			],true).then(
+				res => {
+					this.scimClients    = res[0].payload;
+					this.loginTemplates = res[1].payload;
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
\ No newline at end of file
@@ -0,0 +1,129 @@
+export default {
+	name:'my-admin-search',
+	template:`<div class="contentBox grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/search.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="save.png"
+					@trigger="set"
+					:active="hasChanges"
+					:caption="capGen.button.save"
+				/>
+				<my-button image="refresh.png"
+					@trigger="get"
+					:active="hasChanges"
+					:caption="capGen.button.refresh"
+				/>
+			</div>
+			<div class="area">
+				<my-button image="refresh.png"
+					@trigger="reindex"
+					:active="!hasChanges"
+					:caption="capApp.button.reindex"
+				/>
+			</div>
+		</div>
+
+		<div class="content">
+			<p>{{ capApp.dictionariesHint }}</p>
+			<table class="generic-table bright default-inputs">
+				<thead>
+					<tr>
+						<th>{{ capApp.languageCode }}</th>
+						<th>{{ capApp.dictionary }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="code in languageCodes">
+						<td>{{ code }}</td>
+						<td>
+							<select
+								@change="update(code,$event.target.value)"
+								:value="languageCodeMapDictionary[code] !== undefined ? languageCodeMapDictionary[code] : ''"
+							>
+								<option value="">-</option>
+								<option v-for="d in searchDictionaries" :value="d">
+									{{ capFts.dictionary[d] !== undefined ? capFts.dictionary[d] : d }}
+								</option>
+							</select>
+						</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			dictionaries:[],
+			dictionariesOrg:[]
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+		this.get();
+	},
+	computed:{
+		languageCodeMapDictionary:s => {
+			let out = {};
+			for(const d of s.dictionaries) {
+				out[d.languageCode] = d.dictionary;
+			}
+			return out;
+		},
+
+		// simple
+		hasChanges:s => JSON.stringify(s.dictionaries) !== JSON.stringify(s.dictionariesOrg),
+
+		// stores
+		languageCodes:     s => s.$store.getters['schema/languageCodes'],
+		searchDictionaries:s => s.$store.getters.searchDictionaries,
+		capApp:            s => s.$store.getters.captions.admin.search,
+		capFts:            s => s.$store.getters.captions.fullTextSearch,
+		capGen:            s => s.$store.getters.captions.generic
+	},
+	methods:{
+		// actions
+		update(languageCode,dictionary) {
+			this.dictionaries = this.dictionaries.filter(v => v.languageCode !== languageCode);
+
+			if(dictionary !== '')
+				this.dictionaries.push({ languageCode:languageCode, dictionary:dictionary });
+
+			this.dictionaries.sort((a,b) => a.languageCode.localeCompare(b.languageCode));
+		},
+
+		// backend calls
+		get() {
+			ws.send('searchIndex','get',{},true).then(
+				res => {
+					this.dictionaries    = res.payload;
+					this.dictionariesOrg = JSON.parse(JSON.stringify(res.payload));
+				},
+				this.$root.genericError
+			);
+		},
+		reindex() {
+			ws.send('searchIndex','reindex',{},true).then(
+				() => this.$store.commit('dialog',{
+					captionBody:this.capApp.dialog.reindexed,
+					image:'ok.png'
+				}),
+				this.$root.genericError
+			);
+		},
+		set() {
+			ws.send('searchIndex','set',this.dictionaries,true).then(
+				this.get,
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -527,6 +527,9 @@ export default {
 				case 'jsFunctionCalled':
 					this.jsFunctionRun(res.payload.jsFunctionId,res.payload.arguments,{});
 				break;
+				case 'recordsChanged':
+					this.$store.commit('recordsChanged',res.payload);
+				break;
 				
 				// affects everyone logged in
 				case 'collectionChanged':
@@ -654,6 +657,7 @@ export default {
 					this.$store.commit('pageTitleRefresh'); // update page title with new app name
 					this.$store.commit('pwaDomainMap',res.payload.pwaDomainMap);
 					this.$store.commit('reposFeedback',res.payload.reposFeedback);
+					this.$store.commit('samlIdpIdMapLogin',res.payload.samlIdpIdMapLogin);
 					this.$store.commit('searchDictionaries',res.payload.searchDictionaries);
 					this.$store.commit('systemMsg',res.payload.systemMsg);
 					this.$store.commit('tokenKeepEnable',res.payload.tokenKeepEnable);
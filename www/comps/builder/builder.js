@@ -147,6 +147,11 @@ export default {
 						<span>{{ capGen.apis }}</span>
 					</router-link>
 					
+					<router-link class="entry clickable" :to="'/builder/number-ranges/'+module.id">
+						<img src="images/numbers.png" />
+						<span>{{ capGen.numberRanges }}</span>
+					</router-link>
+					
 					<router-link class="entry clickable" :to="'/builder/variables/'+module.id">
 						<img src="images/variable.png" />
 						<span>{{ capGen.variables }}</span>
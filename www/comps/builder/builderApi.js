@@ -88,8 +88,10 @@ const MyBuilderApiPreview = {
 						</thead>
 						<tbody>
 							<tr><th>Authorization</th><th>Bearer {TOKEN_FROM_AUTH_CALL}</th></tr>
+							<tr v-if="isPost"><th>If-Match</th><th>"{VERSION_FROM_ETAG}"</th></tr>
 						</tbody>
 					</table>
+					<span v-if="isPost">{{ capApp.ifMatchHint }}</span>
 				</td>
 			</tr>
 			<tr v-if="isGet || isPost">
@@ -107,6 +109,11 @@ const MyBuilderApiPreview = {
 								<td><input v-model.number="params.offset" /></td>
 								<td>{{ capApp.offsetHint }}</td>
 							</tr>
+							<tr v-if="isGet">
+								<td>As of</td>
+								<td><input v-model.number="params.asOf" /></td>
+								<td>{{ capApp.asOfHint }}</td>
+							</tr>
 							<tr v-if="isGet || isPost">
 								<td>Verbose</td>
 								<td><my-bool v-model="params.verbose" @update:modelValue="verboseChanged = true" /></td>
@@ -164,6 +171,7 @@ const MyBuilderApiPreview = {
 			contentType:'application/json',
 			limitChanged:false,
 			params:{
+				asOf:0,
 				limit:100,
 				offset:0,
 				verbose:false
@@ -182,6 +190,7 @@ const MyBuilderApiPreview = {
 			if(s.verboseChanged)       out.push(`verbose=${s.params.verbose ? '1' : '0'}`);
 			if(s.isGet && s.limitSet)  out.push(`limit=${s.params.limit}`);
 			if(s.isGet && s.offsetSet) out.push(`offset=${s.params.offset}`);
+			if(s.isGet && s.asOfSet)   out.push(`as_of=${s.params.asOf}`);
 			return out.length === 0 ? '' : `?${out.join('&')}`;
 		},
 		request:(s) => {
@@ -215,6 +224,7 @@ const MyBuilderApiPreview = {
 		},
 		
 		// simple
+		asOfSet:  (s) => s.params.asOf   !== '' && s.params.asOf   !== 0,
 		isAuth:   (s) => s.call === 'AUTH',
 		isDelete: (s) => s.call === 'DELETE',
 		isGet:    (s) => s.call === 'GET',
@@ -4,7 +4,10 @@ import MyBuilderSchemaLookup  from './builderSchemaLookup.js';
 import {getUuidV4}            from '../shared/crypto.js';
 import {getFieldMap}          from '../shared/form.js';
 import {copyValueDialog}      from '../shared/generic.js';
-import {getTemplateAttribute} from '../shared/builderTemplate.js';
+import {
+	getTemplateAttribute,
+	getTemplateAttributeValidation
+} from '../shared/builderTemplate.js';
 import {dialogDeleteAsk}      from '../shared/dialog.js';
 import {getHasAnyReferences}  from '../shared/schemaLookup.js';
 import {
@@ -27,9 +30,126 @@ import {
 	isAttributeUuid
 } from '../shared/attribute.js';
 
+const MyBuilderAttributeValidation = {
+	name:'my-builder-attribute-validation',
+	template:`<tr>
+		<td>
+			<select v-model="content" :disabled="readonly">
+				<option value="regex"   :disabled="!isText">{{ capApp.validation.option.regex }}</option>
+				<option value="min"     :disabled="!isText && !isNumber">{{ isText ? capApp.validation.option.minLength : capApp.validation.option.min }}</option>
+				<option value="max"     :disabled="!isText && !isNumber">{{ isText ? capApp.validation.option.maxLength : capApp.validation.option.max }}</option>
+				<option value="values"  :disabled="!isText && !isNumber">{{ capApp.validation.option.values }}</option>
+				<option value="compare" :disabled="isRelationship">{{ capApp.validation.option.compare }}</option>
+				<option value="unique">{{ capApp.validation.option.unique }}</option>
+			</select>
+		</td>
+		<td>
+			<input v-if="content === 'regex'" v-model="value" :disabled="readonly" :placeholder="capApp.validation.regexHint" />
+			<input v-if="content === 'min' || content === 'max'" v-model="value" :disabled="readonly" type="number" />
+			<textarea v-if="content === 'values'" v-model="values" :disabled="readonly" :placeholder="capApp.validation.valuesHint"></textarea>
+			<div class="row gap" v-if="content === 'compare'">
+				<select v-model="operator" :disabled="readonly">
+					<option v-for="o in operators" :value="o">{{ o }}</option>
+				</select>
+				<select v-model="attributeIdCompare" :disabled="readonly">
+					<option :value="null">-</option>
+					<option v-for="a in attributesOther" :value="a.id">{{ a.name }}</option>
+				</select>
+			</div>
+			<div class="column gap" v-if="content === 'unique'">
+				<span>{{ capApp.validation.scopeHint }}</span>
+				<div class="row gap centered" v-for="a in attributesOther">
+					<my-bool
+						@update:modelValue="toggleScope(a.id,$event)"
+						:modelValue="modelValue.attributeIdsScope.includes(a.id)"
+						:readonly="readonly"
+					/>
+					<span>{{ a.name }}</span>
+				</div>
+			</div>
+		</td>
+		<td>
+			<my-button image="cancel.png"
+				@trigger="$emit('remove')"
+				:active="!readonly"
+				:naked="true"
+			/>
+		</td>
+	</tr>`,
+	props:{
+		attribute: { type:Object,  required:true },
+		modelValue:{ type:Object,  required:true },
+		readonly:  { type:Boolean, required:true },
+		relation:  { type:Object,  required:true }
+	},
+	emits:['remove','update:modelValue'],
+	data() {
+		return {
+			operators:['=','<>','<','<=','>','>=']
+		};
+	},
+	computed:{
+		// inputs
+		attributeIdCompare:{
+			get()  { return this.modelValue.attributeIdCompare; },
+			set(v) { this.update('attributeIdCompare',v); }
+		},
+		content:{
+			get()  { return this.modelValue.content; },
+			set(v) { this.update('content',v); }
+		},
+		operator:{
+			get()  { return this.modelValue.operator; },
+			set(v) { this.update('operator',v); }
+		},
+		value:{
+			get()  { return this.modelValue.value === null ? '' : this.modelValue.value; },
+			set(v) { this.update('value',v === '' ? null : String(v)); }
+		},
+		values:{
+			get()  { return this.modelValue.values.join('\n'); },
+			set(v) { this.update('values',v.split('\n').filter(v => v !== '')); }
+		},
+		
+		// attributes to compare with or to scope uniqueness by
+		attributesOther:(s) => s.relation.attributes.filter(v => v.id !== s.attribute.id
+			&& !v.encrypted && !s.isAttributeFiles(v.content)),
+		
+		// simple
+		isNumber:      (s) => s.isAttributeInteger(s.attribute.content) || s.isAttributeNumeric(s.attribute.content) || s.isAttributeFloat(s.attribute.content),
+		isRelationship:(s) => s.isAttributeRelationship(s.attribute.content),
+		isText:        (s) => s.isAttributeString(s.attribute.content),
+		
+		// stores
+		capApp:(s) => s.$store.getters.captions.builder.attribute
+	},
+	methods:{
+		// external
+		isAttributeFiles,
+		isAttributeFloat,
+		isAttributeInteger,
+		isAttributeNumeric,
+		isAttributeRelationship,
+		isAttributeString,
+		
+		toggleScope(attributeId,state) {
+			let ids = this.modelValue.attributeIdsScope.filter(v => v !== attributeId);
+			if(state) ids.push(attributeId);
+			this.update('attributeIdsScope',ids);
+		},
+		update(name,value) {
+			let v = JSON.parse(JSON.stringify(this.modelValue));
+			v[name] = value;
+			
+			this.$emit('update:modelValue',v);
+		}
+	}
+};
+
 export default {
 	name:'my-builder-attribute',
 	components:{
+		MyBuilderAttributeValidation,
 		MyBuilderCaption,
 		MyBuilderIconInput,
 		MyBuilderSchemaLookup
@@ -319,6 +439,36 @@ export default {
 							<td>{{ capApp.defaultsHint }}</td>
 						</tr>
 						
+						<!-- validation rules -->
+						<tr v-if="canValidate">
+							<td>{{ capApp.validations }}</td>
+							<td>
+								<div class="column gap">
+									<table v-if="values.validations.length !== 0">
+										<tbody>
+											<my-builder-attribute-validation
+												v-for="(v,i) in values.validations"
+												@remove="values.validations.splice(i,1)"
+												@update:modelValue="values.validations[i] = $event"
+												:attribute="values"
+												:modelValue="v"
+												:readonly
+												:relation
+											/>
+										</tbody>
+									</table>
+									<div>
+										<my-button image="add.png"
+											@trigger="values.validations.push(getTemplateAttributeValidation())"
+											:active="!readonly"
+											:caption="capGen.button.add"
+										/>
+									</div>
+								</div>
+							</td>
+							<td>{{ capApp.validationsHint }}</td>
+						</tr>
+						
 						<!-- expert info -->
 						<tr>
 							<td>{{ capApp.content }}</td>
@@ -483,6 +633,7 @@ export default {
 		
 		// simple
 		canEncrypt:    (s) => s.relation.encryption && s.values.content === 'text',
+		canValidate:   (s) => !s.isId && !s.isFiles && !s.values.encrypted,
 		canSave:       (s) => !s.readonly && s.hasChanges && !s.nameTaken,
 		hasChanges:    (s) => s.values.name !== '' && JSON.stringify(s.values) !== JSON.stringify(s.valuesOrg),
 		hasLength:     (s) => ['decimal','files','richtext','text','textarea'].includes(s.usedFor),
@@ -547,6 +698,7 @@ export default {
 		getHasAnyReferences,
 		getItemTitle,
 		getTemplateAttribute,
+		getTemplateAttributeValidation,
 		getUuidV4,
 		isAttributeBoolean,
 		isAttributeFiles,
@@ -7,6 +7,7 @@ import {
 	getTemplateForm,
 	getTemplateJsFunction,
 	getTemplateModule,
+	getTemplateNumberRange,
 	getTemplatePgFunction,
 	getTemplateRelation,
 	getTemplateRole,
@@ -187,6 +188,7 @@ export default {
 				case 'form':       return 64; break;
 				case 'jsFunction': return 64; break;
 				case 'module':     return 60; break;
+				case 'numberRange':return 64; break;
 				case 'pgFunction': return 60; break;
 				case 'relation':   return 60; break;
 				case 'role':       return 64; break;
@@ -208,6 +210,7 @@ export default {
 				case 'doc':        searchList = s.module.docs;        break;
 				case 'form':       searchList = s.module.forms;       break;
 				case 'jsFunction': searchList = s.module.jsFunctions; break;
+				case 'numberRange':searchList = s.module.numberRanges; break;
 				case 'pgFunction': searchList = s.module.pgFunctions; break;
 				case 'relation':   searchList = s.module.relations;   break;
 				case 'role':       searchList = s.module.roles;       break;
@@ -239,6 +242,7 @@ export default {
 				case 'form':       return s.capApp.form;       break;
 				case 'jsFunction': return s.capApp.jsFunction; break;
 				case 'module':     return s.capApp.module;     break;
+				case 'numberRange':return s.capApp.numberRange;break;
 				case 'pgFunction': return s.capApp.pgFunction; break;
 				case 'relation':   return s.capApp.relation;   break;
 				case 'role':       return s.capApp.role;       break;
@@ -256,6 +260,7 @@ export default {
 				case 'form':       return 'images/fileText.png';       break;
 				case 'jsFunction': return 'images/codeScreen.png';     break;
 				case 'module':     return 'images/module.png';         break;
+				case 'numberRange':return 'images/numbers.png';        break;
 				case 'pgFunction': return 'images/codeDatabase.png';   break;
 				case 'relation':   return 'images/database.png';       break;
 				case 'role':       return 'images/personMultiple.png'; break;
@@ -303,6 +308,7 @@ export default {
 		getTemplateForm,
 		getTemplateJsFunction,
 		getTemplateModule,
+		getTemplateNumberRange,
 		getTemplatePgFunction,
 		getTemplateRelation,
 		getTemplateRole,
@@ -325,6 +331,7 @@ export default {
 				case 'collection': request = this.getTemplateCollection(this.module.id,this.inputs.name); break;
 				case 'jsFunction': request = this.getTemplateJsFunction(this.moduleId,this.inputs.formId,this.inputs.name); break;
 				case 'module':     request = this.getTemplateModule(this.inputs.name); break;
+				case 'numberRange':request = this.getTemplateNumberRange(this.moduleId,this.inputs.name); break;
 				case 'pgFunction': request = this.getTemplatePgFunction(this.moduleId,this.inputs.name,this.inputs.template,this.inputs.isTrigger); break;
 				case 'relation':   request = this.getTemplateRelation(this.module.id,this.inputs.name,this.inputs.encryption); break;
 				case 'role':       request = this.getTemplateRole(this.moduleId,this.inputs.name); break;
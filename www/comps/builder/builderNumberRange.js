@@ -0,0 +1,236 @@
+import {dialogDeleteAsk} from '../shared/dialog.js';
+import {copyValueDialog} from '../shared/generic.js';
+import {
+	isAttributeFiles,
+	isAttributeString
+} from '../shared/attribute.js';
+
+export default {
+	name:'my-builder-number-range',
+	template:`<div class="app-sub-window under-header" @mousedown.self="$emit('close')">
+		<div class="contentBox builder-number-range float" v-if="values !== null">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/numbers.png" />
+					<h1 class="title">{{ title }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="canSave"
+						:caption="capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						@trigger="reset"
+						:active="hasChanges"
+						:caption="capGen.button.refresh"
+					/>
+				</div>
+				<div class="area">
+					<my-button image="visible1.png"
+						@trigger="copyValueDialog(values.name,numberRangeId,numberRangeId)"
+						:caption="capGen.id"
+					/>
+					<my-button image="delete.png"
+						@trigger="dialogDeleteAsk(del,capApp.dialog.delete)"
+						:active="!readonly"
+						:cancel="true"
+						:caption="capGen.button.delete"
+					/>
+				</div>
+			</div>
+			
+			<div class="content no-padding default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capGen.name }}</td>
+							<td>
+								<input v-focus v-model="values.name" :disabled="readonly" />
+								<p class="error" v-if="nameTaken">{{ capGen.error.nameTaken }}</p>
+							</td>
+							<td>{{ capGen.internalName }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.prefix }}</td>
+							<td><input v-model="values.prefix" :disabled="readonly" /></td>
+							<td rowspan="2">{{ capApp.prefixHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.suffix }}</td>
+							<td><input v-model="values.suffix" :disabled="readonly" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.digits }}</td>
+							<td><input v-model.number="values.digits" :disabled="readonly" /></td>
+							<td>{{ capApp.digitsHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.reset }}</td>
+							<td>
+								<select v-model="values.reset" :disabled="readonly">
+									<option value="never">{{ capApp.option.resetNever }}</option>
+									<option value="year">{{ capApp.option.resetYear }}</option>
+									<option value="month">{{ capApp.option.resetMonth }}</option>
+									<option value="day">{{ capApp.option.resetDay }}</option>
+								</select>
+							</td>
+							<td></td>
+						</tr>
+						<tr>
+							<td>{{ capGen.preview }}</td>
+							<td><b>{{ preview }}</b></td>
+							<td></td>
+						</tr>
+						<tr>
+							<td colspan="999" class="grouping">{{ capApp.assignment }}</td>
+						</tr>
+						<tr>
+							<td>{{ capGen.attribute }}</td>
+							<td>
+								<select v-model="values.attributeId" @change="values.attributeIdScope = null" :disabled="readonly">
+									<option :value="null">-</option>
+									<optgroup v-for="r in module.relations" :label="r.name">
+										<option
+											v-for="a in r.attributes.filter(v => isAttributeString(v.content))"
+											:value="a.id"
+										>{{ a.name }}</option>
+									</optgroup>
+								</select>
+							</td>
+							<td>{{ capApp.attributeHint }}</td>
+						</tr>
+						<tr v-if="values.attributeId !== null">
+							<td>{{ capApp.scope }}</td>
+							<td>
+								<select v-model="values.attributeIdScope" :disabled="readonly">
+									<option :value="null">-</option>
+									<option
+										v-for="a in relationIdMap[attributeIdMap[values.attributeId].relationId].attributes.filter(v => v.id !== values.attributeId && !isAttributeFiles(v.content))"
+										:value="a.id"
+									>{{ a.name }}</option>
+								</select>
+							</td>
+							<td>{{ capApp.scopeHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capGen.comments }}</td>
+							<td colspan="2">
+								<textarea class="dynamic" v-model="values.comment" :disabled="readonly"></textarea>
+							</td>
+						</tr>
+						<tr>
+							<td colspan="999"><i>{{ capApp.pgFunctionHint.replace('{ID}',numberRangeId) }}</i></td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		module:       { type:Object,  required:true },
+		numberRangeId:{ type:String,  required:true },
+		readonly:     { type:Boolean, required:true }
+	},
+	emits:['close'],
+	data() {
+		return {
+			values:null,
+			valuesOrg:null
+		};
+	},
+	computed:{
+		nameTaken:(s) => {
+			for(let n of s.module.numberRanges) {
+				if(n.id !== s.numberRangeId && n.name === s.values.name)
+					return true;
+			}
+			return false;
+		},
+		preview:(s) => {
+			const d   = new Date();
+			const pad = v => String(v).padStart(2,'0');
+			const fmt = v => v
+				.replaceAll('{YYYY}',d.getFullYear())
+				.replaceAll('{YY}',String(d.getFullYear()).slice(-2))
+				.replaceAll('{MM}',pad(d.getMonth()+1))
+				.replaceAll('{DD}',pad(d.getDate()));
+			
+			return fmt(s.values.prefix) + '1'.padStart(s.values.digits,'0') + fmt(s.values.suffix);
+		},
+		
+		// simple
+		canSave:    (s) => !s.readonly && s.hasChanges && !s.nameTaken && s.digitsValid,
+		digitsValid:(s) => Number.isInteger(s.values.digits) && s.values.digits >= 0 && s.values.digits <= 20,
+		hasChanges: (s) => s.values.name !== '' && JSON.stringify(s.values) !== JSON.stringify(s.valuesOrg),
+		title:      (s) => s.capApp.edit.replace('{NAME}',s.values.name),
+		
+		// stores
+		attributeIdMap:  (s) => s.$store.getters['schema/attributeIdMap'],
+		numberRangeIdMap:(s) => s.$store.getters['schema/numberRangeIdMap'],
+		relationIdMap:   (s) => s.$store.getters['schema/relationIdMap'],
+		capApp:          (s) => s.$store.getters.captions.builder.numberRange,
+		capGen:          (s) => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.reset();
+		window.addEventListener('keydown',this.handleHotkeys);
+	},
+	unmounted() {
+		window.removeEventListener('keydown',this.handleHotkeys);
+	},
+	methods:{
+		// external
+		copyValueDialog,
+		dialogDeleteAsk,
+		isAttributeFiles,
+		isAttributeString,
+		
+		// actions
+		handleHotkeys(e) {
+			if(e.ctrlKey && e.key === 's' && this.canSave) {
+				this.set();
+				e.preventDefault();
+			}
+			if(e.key === 'Escape') {
+				this.$emit('close');
+				e.preventDefault();
+			}
+		},
+		reset() {
+			this.values    = JSON.parse(JSON.stringify(this.numberRangeIdMap[this.numberRangeId]));
+			this.valuesOrg = JSON.parse(JSON.stringify(this.values));
+		},
+		
+		// backend calls
+		del() {
+			ws.send('numberRange','del',this.numberRangeId,true).then(
+				() => {
+					this.$root.schemaReload(this.module.id);
+					this.$emit('close');
+				},
+				this.$root.genericError
+			);
+		},
+		set() {
+			ws.sendMultiple([
+				ws.prepare('numberRange','set',this.values),
+				ws.prepare('schema','check',{ moduleId:this.module.id })
+			],true).then(
+				() => {
+					this.$root.schemaReload(this.module.id);
+					this.$emit('close');
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
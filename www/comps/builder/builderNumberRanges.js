@@ -0,0 +1,101 @@
+import MyBuilderNumberRange from './builderNumberRange.js';
+import {routeParseParams}   from '../shared/router.js';
+export {MyBuilderNumberRanges as default};
+
+let MyBuilderNumberRanges = {
+	name:'my-builder-number-ranges',
+	components:{MyBuilderNumberRange},
+	template:`<div class="contentBox grow builder-number-ranges">
+		
+		<div class="top lower">
+			<div class="area nowrap">
+				<img class="icon" src="images/numbers.png" />
+				<h1 class="title">{{ capApp.title }}</h1>
+			</div>
+			<div class="area default-inputs">
+				<input v-model="filter" placeholder="..." />
+			</div>
+		</div>
+		
+		<div class="content" v-if="module">
+			<div class="generic-entry-list">
+				<div class="entry"
+					v-if="!readonly"
+					@click="$emit('createNew','numberRange')"
+					:class="{ clickable:!readonly }"
+				>
+					<div class="row gap centered">
+						<img class="icon" src="images/add.png" />
+						<span>{{ capGen.button.new }}</span>
+					</div>
+				</div>
+				
+				<div class="entry clickable"
+					v-for="n in module.numberRanges.filter(v => filter === '' || v.name.toLowerCase().includes(filter.toLowerCase()))"
+					@click="numberRangeIdEdit = n.id"
+				>
+					<div class="lines">
+						<span>{{ n.name }}</span>
+						<span class="subtitle" v-if="n.attributeId !== null && attributeIdMap[n.attributeId] !== undefined">
+							{{ getAttributeLabel(n.attributeId) }}
+						</span>
+					</div>
+				</div>
+			</div>
+		</div>
+		
+		<!-- number range dialog -->
+		<my-builder-number-range
+			v-if="module && numberRangeIdEdit !== false"
+			@close="numberRangeIdEdit = false"
+			:module="module"
+			:numberRangeId="numberRangeIdEdit"
+			:readonly="readonly"
+		/>
+	</div>`,
+	emits:['createNew'],
+	props:{
+		id:      { type:String,  required:true },
+		readonly:{ type:Boolean, required:true }
+	},
+	data() {
+		return {
+			filter:'',
+			numberRangeIdEdit:false
+		};
+	},
+	mounted() {
+		this.parseRoute();
+	},
+	watch:{
+		'$route.query':function() { this.parseRoute(); }
+	},
+	computed:{
+		// stores
+		module:        (s) => s.moduleIdMap[s.id] === undefined ? false : s.moduleIdMap[s.id],
+		moduleIdMap:   (s) => s.$store.getters['schema/moduleIdMap'],
+		relationIdMap: (s) => s.$store.getters['schema/relationIdMap'],
+		attributeIdMap:(s) => s.$store.getters['schema/attributeIdMap'],
+		capApp:        (s) => s.$store.getters.captions.builder.numberRange,
+		capGen:        (s) => s.$store.getters.captions.generic
+	},
+	methods:{
+		// externals
+		routeParseParams,
+		
+		// presentation
+		getAttributeLabel(attributeId) {
+			const atr = this.attributeIdMap[attributeId];
+			return `${this.relationIdMap[atr.relationId].name}.${atr.name}`;
+		},
+		
+		// actions
+		parseRoute() {
+			let params = { numberRangeIdEdit:{ parse:'string', value:null } };
+			this.routeParseParams(params);
+			
+			if(params.numberRangeIdEdit.value !== null)
+				this.numberRangeIdEdit = params.numberRangeIdEdit.value;
+		}
+	}
+};
@@ -33,8 +33,8 @@ const MyBuilderPgFunctionItemSchedule = {
 			</select>
 			
 			<template v-if="intervalType !== 'once'">
-				<span>{{ capApp.intervalEvery }}</span>
-				<input class="dynamic" v-model.number="intervalValue" :disabled="readonly" />
+				<span v-if="intervalType !== 'cron'">{{ capApp.intervalEvery }}</span>
+				<input class="dynamic" v-if="intervalType !== 'cron'" v-model.number="intervalValue" :disabled="readonly" />
 				
 				<select class="dynamic" v-model="intervalType" :disabled="readonly">
 					<option value="seconds">{{ capApp.option.intervalSeconds }}</option>
@@ -44,7 +44,11 @@ const MyBuilderPgFunctionItemSchedule = {
 					<option value="weeks"  >{{ capApp.option.intervalWeeks   }}</option>
 					<option value="months" >{{ capApp.option.intervalMonths  }}</option>
 					<option value="years"  >{{ capApp.option.intervalYears   }}</option>
+					<option value="cron"   >{{ capApp.option.intervalCron    }}</option>
 				</select>
+				
+				<!-- cron expression with seconds -->
+				<input class="long" v-if="intervalType === 'cron'" v-model="cronExpr" :disabled="readonly" :placeholder="capApp.cronExprHint" :title="capApp.cronExprHint" />
 			</template>
 		</div>
 		
@@ -73,13 +77,66 @@ const MyBuilderPgFunctionItemSchedule = {
 				:naked="true"
 			/>
 		</div>
+		
+		<div class="line" v-if="intervalType !== 'once'">
+			<!-- time zone to evaluate schedule in -->
+			<span>{{ capApp.timeZone }}</span>
+			<input class="long" list="builder-function-time-zones" v-model="timeZone" :disabled="readonly" :placeholder="capApp.timeZoneHint" />
+			<datalist id="builder-function-time-zones">
+				<option v-for="tz in timeZones" :value="tz" />
+			</datalist>
+			
+			<!-- days on which the schedule does not run -->
+			<span>{{ capApp.attributeIdExclude }}</span>
+			<select class="dynamic" v-model="attributeIdExclude" :disabled="readonly" :title="capApp.attributeIdExcludeHint">
+				<option :value="null">-</option>
+				<option v-for="a in attributesDate" :value="a.id">{{ a.label }}</option>
+			</select>
+			
+			<my-button image="question.png"
+				@trigger="preview"
+				:caption="capApp.button.schedulePreview"
+				:naked="true"
+			/>
+		</div>
+		
+		<div class="line preview" v-if="previewDates !== null">
+			<span v-if="previewDates.length === 0">{{ capApp.schedulePreviewEmpty }}</span>
+			<span v-for="d in previewDates">{{ displayPreviewDate(d) }}</span>
+		</div>
 	</div>`,
 	props:{
+		module:    { type:Object,  required:true },
 		modelValue:{ type:Object,  required:true },
 		readonly:  { type:Boolean, required:true }
 	},
 	emits:['remove','update:modelValue'],
+	data() {
+		return {
+			previewDates:null // next run times of schedule
+		};
+	},
+	watch:{
+		modelValue() {
+			this.previewDates = null;
+		}
+	},
 	computed:{
+		attributesDate:s => {
+			let out = [];
+			for(const mod of s.getDependentModules(s.module)) {
+				for(const rel of mod.relations) {
+					for(const atr of rel.attributes) {
+						if(atr.contentUse === 'date')
+							out.push({ id:atr.id, label:`${mod.name}: ${rel.name}.${atr.name}` });
+					}
+				}
+			}
+			return out;
+		},
+		timeZones:s => typeof Intl.supportedValuesOf === 'function' ? Intl.supportedValuesOf('timeZone') : [],
+		
+
 		// inputs
 		atDay:{
 			get()  { return this.modelValue.atDay; },
@@ -105,6 +162,18 @@ const MyBuilderPgFunctionItemSchedule = {
 			get()  { return this.modelValue.intervalValue; },
 			set(v) { this.update('intervalValue',v); }
 		},
+		attributeIdExclude:{
+			get()  { return this.modelValue.attributeIdExclude; },
+			set(v) { this.update('attributeIdExclude',v); }
+		},
+		cronExpr:{
+			get()  { return this.modelValue.cronExpr !== null ? this.modelValue.cronExpr : ''; },
+			set(v) { this.update('cronExpr',v === '' ? null : v); }
+		},
+		timeZone:{
+			get()  { return this.modelValue.timeZone !== null ? this.modelValue.timeZone : ''; },
+			set(v) { this.update('timeZone',v === '' ? null : v); }
+		},
 		runOnce:{
 			get()  { return this.intervalType === 'once'; },
 			set(v) {
@@ -117,10 +186,35 @@ const MyBuilderPgFunctionItemSchedule = {
 		capApp:(s) => s.$store.getters.captions.builder.function
 	},
 	methods:{
+		// externals
+		getDependentModules,
+		
+		// presentation
+		displayPreviewDate(unixTime) {
+			// show run times in the time zone of the schedule
+			return new Date(unixTime * 1000).toLocaleString([],{
+				timeZone:this.modelValue.timeZone !== null ? this.modelValue.timeZone : undefined,
+				weekday:'short', year:'numeric', month:'2-digit', day:'2-digit',
+				hour:'2-digit', minute:'2-digit', second:'2-digit'
+			});
+		},
+		
+		// actions
 		update(name,value) {
 			let v = JSON.parse(JSON.stringify(this.modelValue));
 			v[name] = value;
 			this.$emit('update:modelValue',v);
+		},
+		
+		// backend calls
+		preview() {
+			ws.send('pgFunction','schedulePreview',{
+				count:10,
+				schedule:this.modelValue
+			},true).then(
+				res => this.previewDates = res.payload,
+				this.$root.genericError
+			);
 		}
 	}
 };
@@ -633,6 +727,7 @@ export default {
 									v-model="fnc.schedules[i]"
 									@remove="fnc.schedules.splice(i,1)"
 									:key="i"
+									:module="module"
 									:readonly="readonly"
 								/>
 							</td>
@@ -705,8 +800,8 @@ export default {
 				'file_export','file_export_text','file_import','file_import_text','file_link',
 				'file_text_read','file_text_read_cb','file_text_write','file_unlink','files_get',
 				'get_e2ee_data_key_enc','get_language_code','get_name','get_public_hostname','get_role_ids',
-				'get_user_id','has_role','has_role_any','log_error','log_info','log_warning','mail_delete',
-				'mail_delete_after_attach','mail_get_next','mail_send','rest_call','rest_get_placeholder_file_base64',
+				'get_user_id','has_role','has_role_any','job_add','log_error','log_info','log_warning','mail_delete',
+				'mail_delete_after_attach','mail_get_next','mail_send','number_range_next','records_changed','rest_call','rest_get_placeholder_file_base64',
 				'rest_get_placeholder_file_raw','update_collection','user_meta_set','user_sync_all'
 			],
 			showHolderDoc:false,
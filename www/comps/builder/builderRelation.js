@@ -326,6 +326,37 @@ export default {
 								</td>
 								<td v-html="capApp.recordTitleHint.join('<br /><br />')"></td>
 							</tr>
+							<tr>
+								<td>{{ capApp.searchAttributes }}</td>
+								<td>
+									<div class="column gap">
+										<select @input="searchAttributeAdd($event.target.value)" :disabled="readonly" :value="searchAttributeId">
+											<option value="">[{{ capGen.button.add }}]</option>
+											<option v-for="a in attributesSearchCandidates" :value="a.id">{{ a.name }}</option>
+										</select>
+										<div class="row gap">
+											<my-button image="delete.png"
+												v-for="id in relation.attributeIdsSearch"
+												@trigger="searchAttributeRemove(id)"
+												:active="!readonly"
+												:caption="attributeIdMap[id].name"
+												:naked="true"
+											/>
+										</div>
+									</div>
+								</td>
+								<td>{{ capApp.searchAttributesHint }}</td>
+							</tr>
+							<tr>
+								<td>{{ capApp.searchForm }}</td>
+								<td>
+									<select v-model="relation.formIdSearch" :disabled="readonly">
+										<option :value="null">-</option>
+										<option v-for="f in formsSearchCandidates" :value="f.id">{{ f.name }}</option>
+									</select>
+								</td>
+								<td>{{ capApp.searchFormHint }}</td>
+							</tr>
 							<tr>
 								<td>{{ capApp.retention }}</td>
 								<td>
@@ -344,6 +375,11 @@ export default {
 								</td>
 								<td>{{ capApp.retentionHint }}</td>
 							</tr>
+							<tr>
+								<td>{{ capApp.recycleDays }}</td>
+								<td><my-input-decimal v-model="relation.recycleDays" :min="0" :allowNull="true" :lengthFract="0" :readonly /></td>
+								<td>{{ capApp.recycleDaysHint }}</td>
+							</tr>
 							<tr>
 								<td>{{ capApp.encryption }}</td>
 								<td><my-bool v-model="relation.encryption" :readonly="true" /></td>
@@ -581,6 +617,7 @@ export default {
 			previewRowCount:0,
 			previewValueLength:50,
 			recordTitleAttributeId:'',
+			searchAttributeId:'',
 			showLookup:false,
 			tabTarget:'attributes'
 		};
@@ -605,6 +642,9 @@ export default {
 			}
 			return out;
 		},
+		attributesSearchCandidates:s => s.relation.attributes.filter(a => !s.relation.attributeIdsSearch.includes(a.id)
+			&& !a.encrypted && (s.isAttributeString(a.content) || s.isAttributeFiles(a.content))),
+		formsSearchCandidates:s => s.moduleIdMap[s.relation.moduleId].forms.filter(f => f.query.relationId === s.relation.id),
 		tabCaptions:s => {
 			let triggerCnt = 0;
 			for(const mod of s.modules) {
@@ -787,6 +827,15 @@ export default {
 			if(pos !== -1)
 				this.relation.attributeIdsTitle.splice(pos,1);
 		},
+		searchAttributeAdd(id) {
+			this.relation.attributeIdsSearch.push(id);
+			this.searchAttributeId = '';
+		},
+		searchAttributeRemove(id) {
+			const pos = this.relation.attributeIdsSearch.indexOf(id);
+			if(pos !== -1)
+				this.relation.attributeIdsSearch.splice(pos,1);
+		},
 		reset(manuelReset) {
 			if(this.relationSchema !== false && (manuelReset || !this.deepIsEqual(this.relationCopy,this.relationSchema))) {
 				this.relation     = JSON.parse(JSON.stringify(this.relationSchema));
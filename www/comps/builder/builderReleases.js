@@ -57,17 +57,40 @@ const MyBuilderReleaseLogs = {
 				</draggable>
 			</td>
 		</tr>
-	</template>`,
-	emits:['delete','update:modelValue'],
+	</template>
+	<tr v-if="(show || showAll) && (isEdit || migrations.length !== 0)">
+		<td class="minimum"> </td>
+		<td class="minimum topAligned">
+			<my-label image="databasePlay.png" :caption="capApp.migrations" :title="capApp.migrationsHint" />
+		</td>
+		<td class="topAligned">
+			<div class="builder-release-logs">
+				<div class="builder-release-log" v-for="(id,i) in migrations">
+					<my-label image="codeDatabase.png" :caption="pgFunctionIdMap[id] !== undefined ? pgFunctionIdMap[id].name : id" />
+					<my-button image="arrowUp.png" v-if="isEdit && i !== 0" @trigger="migrationMoveUp(i)" :naked="true" />
+					<my-button image="cancel.png" v-if="isEdit" @trigger="migrationDel(i)" :naked="true" />
+				</div>
+				<select v-if="isEdit" @change="migrationAdd($event.target.value); $event.target.value = ''">
+					<option value="">{{ capApp.migrationAdd }}</option>
+					<option v-for="f in pgFunctionsMigration.filter(v => !migrations.includes(v.id))" :value="f.id">
+						{{ f.name }}
+					</option>
+				</select>
+			</div>
+		</td>
+	</tr>`,
+	emits:['delete','update:migrations','update:modelValue'],
 	props:{
-		build:     { type:Number,  required:true },
-		buildApp:  { type:Number,  required:true },
-		categories:{ type:Array,   required:true },
-		date:      { type:Number,  required:true },
-		modelValue:{ type:Array,   required:true },
-		moduleName:{ type:String,  required:true },
-		readonly:  { type:Boolean, required:true },
-		showAll:   { type:Boolean, required:true }
+		build:      { type:Number,  required:true },
+		buildApp:   { type:Number,  required:true },
+		categories: { type:Array,   required:true },
+		date:       { type:Number,  required:true },
+		migrations: { type:Array,   required:true }, // IDs of PG functions, executed in order when upgrading across this build
+		modelValue: { type:Array,   required:true },
+		moduleName: { type:String,  required:true },
+		pgFunctions:{ type:Array,   required:true },
+		readonly:   { type:Boolean, required:true },
+		showAll:    { type:Boolean, required:true }
 	},
 	watch:{
 		modelValue:{
@@ -88,9 +111,14 @@ const MyBuilderReleaseLogs = {
 		isZero:s => s.build === 0,
 		title: s => s.isZero ? s.capGen.versionAppNew : `${s.moduleName} - v${s.build} (${s.capGen.appName} ${s.buildApp}) - ${s.getUnixFormat(s.date,s.settings.dateFormat)}`,
 
+		// migrations are called without arguments
+		pgFunctionsMigration:s => s.pgFunctions.filter(v => !v.isTrigger && v.codeArgs.trim() === ''),
+
 		// stores
-		capGen:  s => s.$store.getters.captions.generic,
-		settings:s => s.$store.getters.settings
+		pgFunctionIdMap:s => s.$store.getters['schema/pgFunctionIdMap'],
+		capApp:         s => s.$store.getters.captions.builder.releases,
+		capGen:         s => s.$store.getters.captions.generic,
+		settings:       s => s.$store.getters.settings
 	},
 	methods:{
 		// externals
@@ -105,6 +133,18 @@ const MyBuilderReleaseLogs = {
 			this.logsByCategoryIndex[categoryIndex].splice(entryIndex,1);
 			this.update();
 		},
+		migrationAdd(id) {
+			if(id !== '')
+				this.$emit('update:migrations',this.migrations.concat([id]));
+		},
+		migrationDel(index) {
+			this.$emit('update:migrations',this.migrations.filter((v,i) => i !== index));
+		},
+		migrationMoveUp(index) {
+			let out = this.migrations.slice();
+			out.splice(index-1,0,out.splice(index,1)[0]);
+			this.$emit('update:migrations',out);
+		},
 		reset() {
 			let out = [];
 			for(const c of this.categories) {
@@ -177,11 +217,13 @@ export default {
 				<tbody>
 					<my-builder-release-logs :key="0"
 						v-model="releases[0].logs"
+						v-model:migrations="releases[0].migrations"
 						:build="releases[0].build"
 						:buildApp="releases[0].buildApp"
 						:categories
 						:date="releases[0].dateCreated"
 						:moduleName="module.name"
+						:pgFunctions="module.pgFunctions"
 						:readonly
 						:showAll
 					/>
@@ -189,12 +231,14 @@ export default {
 						@delete="del"
 						v-for="r in releases.slice().filter((v,i) => i !== 0).reverse()"
 						v-model="r.logs"
+						v-model:migrations="r.migrations"
 						:build="r.build"
 						:buildApp="r.buildApp"
 						:categories
 						:date="r.dateCreated"
 						:key="r.build"
 						:moduleName="module.name"
+						:pgFunctions="module.pgFunctions"
 						:readonly
 						:showAll
 					/>
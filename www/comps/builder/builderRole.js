@@ -1,5 +1,6 @@
 import MyBuilderCaption       from './builderCaption.js';
 import MyBuilderMenuTabSelect from './builderMenuTabSelect.js';
+import {isAttributeString}    from '../shared/attribute.js';
 import {getDependentModules}  from '../shared/builder.js';
 import {dialogDeleteAsk}      from '../shared/dialog.js';
 import {copyValueDialog}      from '../shared/generic.js';
@@ -118,6 +119,7 @@ const MyBuilderRoleAccessRelation = {
 				:readonly
 			/>
 		</td>
+		<td></td>
 		<td class="maximum"></td>
 	</tr>
 	<tr class="entry"
@@ -153,6 +155,17 @@ const MyBuilderRoleAccessRelation = {
 			/>
 		</td>
 		<td></td>
+		<td>
+			<select
+				v-if="getMaskOptions(atr).length !== 0"
+				@change="$emit('apply-mask',atr.id,$event.target.value)"
+				:disabled="readonly"
+				:value="attributeIdMapMask[atr.id] !== undefined ? attributeIdMapMask[atr.id] : ''"
+			>
+				<option value="">-</option>
+				<option v-for="m in getMaskOptions(atr)" :value="m">{{ capApp.option.mask[m] }}</option>
+			</select>
+		</td>
 		<td class="maximum"></td>
 	</tr>`,
 	props:{
@@ -161,9 +174,10 @@ const MyBuilderRoleAccessRelation = {
 		role:                { type:Object,  required:true },
 		showEntries:         { type:Boolean, required:true },
 		relationIdMapAccess: { type:Object,  required:true },
-		attributeIdMapAccess:{ type:Object,  required:true }
+		attributeIdMapAccess:{ type:Object,  required:true },
+		attributeIdMapMask:  { type:Object,  required:true }
 	},
-	emits:['apply-attribute','apply-relation','relation-selected'],
+	emits:['apply-attribute','apply-mask','apply-relation','relation-selected'],
 	computed:{
 		access:s => s.relationIdMapAccess[s.relation.id] === undefined
 			? -1 : s.relationIdMapAccess[s.relation.id],
@@ -192,6 +206,18 @@ const MyBuilderRoleAccessRelation = {
 		capApp:        s => s.$store.getters.captions.builder.role
 	},
 	methods:{
+		// externals
+		isAttributeString,
+		
+		// presentation
+		getMaskOptions(atr) {
+			if(atr.encrypted) return [];
+			if(this.isAttributeString(atr.content)) return ['last4','hash','placeholder'];
+			if(['date','datetime'].includes(atr.contentUse)) return ['year'];
+			return [];
+		},
+		
+		// actions
 		setAttribute(access,attributeId) {
 			this.$emit('apply-attribute',attributeId,this.attributeIdMapAccessParsed[attributeId] >= access ? access - 1 : access);
 		},
@@ -328,6 +354,12 @@ export default {
 										<span>{{ capApp.accessDelete }}</span>
 									</div>
 								</th>
+								<th :title="capApp.maskHint">
+									<div class="mixed-header">
+										<img src="images/lock.png" />
+										<span>{{ capApp.mask }}</span>
+									</div>
+								</th>
 								<th class="maximum"></th>
 							</tr>
 						</thead>
@@ -335,9 +367,11 @@ export default {
 							<my-builder-role-access-relation
 								v-for="rel in module.relations"
 								@apply-attribute="(...args) => apply('attribute',args[0],args[1])"
+								@apply-mask="applyMask"
 								@apply-relation="(...args) => apply('relation',args[0],args[1])"
 								@relation-selected="toggleRelation"
 								:attributeIdMapAccess="accessAttributes"
+								:attributeIdMapMask="attributeMasks"
 								:key="role.id + '_' + rel.id"
 								:relation="rel"
 								:role
@@ -631,6 +665,7 @@ export default {
 			accessSearchBars:{},
 			accessWidgets:{},
 			assignable:true,
+			attributeMasks:{},
 			captions:{},
 			childrenIds:[],
 			content:'user',
@@ -658,6 +693,7 @@ export default {
 			|| JSON.stringify(s.accessRelations)    !== JSON.stringify(s.role.accessRelations)
 			|| JSON.stringify(s.accessSearchBars)   !== JSON.stringify(s.role.accessSearchBars)
 			|| JSON.stringify(s.accessWidgets)      !== JSON.stringify(s.role.accessWidgets)
+			|| JSON.stringify(s.attributeMasks)     !== JSON.stringify(s.role.attributeMasks)
 			|| JSON.stringify(s.captions)           !== JSON.stringify(s.role.captions),
 		menuIdsAll:s => {
 			let out = [];
@@ -719,6 +755,10 @@ export default {
 				case 'widget':      this.accessWidgets[id]      = access; break;
 			}
 		},
+		applyMask(attributeId,mask) {
+			if(mask === '') delete this.attributeMasks[attributeId];
+			else            this.attributeMasks[attributeId] = mask;
+		},
 		childAdd(id) {
 			this.childrenIds.push(id);
 		},
@@ -740,6 +780,7 @@ export default {
 			this.accessRelations    = JSON.parse(JSON.stringify(this.role.accessRelations));
 			this.accessSearchBars   = JSON.parse(JSON.stringify(this.role.accessSearchBars));
 			this.accessWidgets      = JSON.parse(JSON.stringify(this.role.accessWidgets));
+			this.attributeMasks     = JSON.parse(JSON.stringify(this.role.attributeMasks));
 			this.captions           = JSON.parse(JSON.stringify(this.role.captions));
 
 			if(this.menuTabsIndexShown > this.module.menuTabs.length - 1)
@@ -803,6 +844,7 @@ export default {
 				accessRelations:this.accessRelations,
 				accessSearchBars:this.accessSearchBars,
 				accessWidgets:this.accessWidgets,
+				attributeMasks:this.attributeMasks,
 				captions:this.captions
 			},true).then(
 				() => {
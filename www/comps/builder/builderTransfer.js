@@ -221,6 +221,7 @@ export default {
 					<div class="column gap-large">
 						<select @input="transferTarget = $event.target.value; getRepoCred()" :value="transferTarget">
 							<option value="fileDownload">{{ capApp.option.method.fileDownload }}</option>
+							<option value="directoryDownload">{{ capApp.option.method.directoryDownload }}</option>
 							<optgroup :label="capApp.option.method.repoUpload">
 								<option v-for="r in repos.filter(v => v.active)" :value="r.id">{{ r.name }}</option>
 							</optgroup>
@@ -233,6 +234,16 @@ export default {
 								/>
 							</a>
 						</div>
+						<template v-if="transferTarget === 'directoryDownload'">
+							<p>{{ capApp.directoryDownloadHint }}</p>
+							<div class="row gap">
+								<a target="_blank" :href="exportUrlDirectory" :download="exportNameDirectory">
+									<my-button image="download.png"
+										:caption="capGen.button.download"
+									/>
+								</a>
+							</div>
+						</template>
 					</div>
 				</div>
 
@@ -301,7 +312,11 @@ export default {
 		exportName: s => `${s.module.name}_${s.module.releaseBuild}.rei3`,
 		exportUrl:  s => !s.isExportKeySet || !s.changesOk ? null : `/export/${s.exportName}?token=${s.token}&module_id=${s.moduleId}&date=${Math.floor(new Date().getTime() / 1000)}`,
 		isE2eeReady:s => s.loginEncEnabled && !s.loginEncLocked,
-		repoId:     s => s.transferTarget === 'fileDownload' ? null : s.transferTarget,
+		repoId:     s => ['fileDownload','directoryDownload'].includes(s.transferTarget) ? null : s.transferTarget,
+
+		// directory tree export for version control
+		exportNameDirectory:s => `${s.module.name}_${s.module.releaseBuild}_directory.zip`,
+		exportUrlDirectory: s => `/export/${s.exportNameDirectory}?token=${s.token}&module_id=${s.moduleId}&format=directory&date=${Math.floor(new Date().getTime() / 1000)}`,
 		repoUrl:    s => s.repoId === null ? '' : s.repos.filter(v => v.id === s.repoId)[0].url,
 		
 		// stores
@@ -331,7 +346,7 @@ export default {
 			repoCredUser:'',
 			repos:[],
 			showKeyCreate:false,
-			transferTarget:'fileDownload' // either 'fileDownload' or ID of repository to commit to
+			transferTarget:'fileDownload' // either 'fileDownload', 'directoryDownload' or ID of repository to commit to
 		};
 	},
 	mounted() {
@@ -920,6 +920,7 @@ export default {
 					&& !s.attribute.nullable                // value not optional
 					&& !s.isRelationship1N                  // not 0...n partners
 					&& (!s.isNew || s.attribute.def === '') // existing record or new one with no defaults
+					&& (!s.isNew || !s.isNumberRange)       // new record gets number on save
 				) state = 'required';
 			}
 
@@ -1015,6 +1016,10 @@ export default {
 				return false;
 			}
 			
+			// masked values are not known to the client, they cannot be edited
+			if((!s.isNew || s.isBulkUpdate) && s.access.attributeMask[s.field.attributeId] !== undefined)
+				return false;
+			
 			if(!s.isNew || s.isBulkUpdate)
 				return true;
 			
@@ -1154,6 +1159,7 @@ export default {
 		isList:          (s) => s.content === 'list',
 		isLogin:         (s) => s.isData && s.field.display === 'login',
 		isMonospace:     (s) => s.field.flags.includes('monospace'),
+		isNumberRange:   (s) => s.isData && !s.isVariable && s.moduleIdMap[s.relationIdMap[s.attribute.relationId].moduleId].numberRanges.some(v => v.attributeId === s.field.attributeId),
 		isPassword:      (s) => s.isData && s.field.display === 'password',
 		isRating:        (s) => s.isData && s.field.display === 'rating',
 		isReadonly:      (s) => s.stateFinal === 'readonly',
@@ -1174,6 +1180,7 @@ export default {
 		isUuid:          (s) => s.isData && s.isAttributeUuid(s.contentData),
 		
 		// stores
+		moduleIdMap:        (s) => s.$store.getters['schema/moduleIdMap'],
 		relationIdMap:      (s) => s.$store.getters['schema/relationIdMap'],
 		attributeIdMap:     (s) => s.$store.getters['schema/attributeIdMap'],
 		iconIdMap:          (s) => s.$store.getters['schema/iconIdMap'],
@@ -1,7 +1,10 @@
 import MyBuilderQuery                      from './builder/builderQuery.js';
 import MyInputDateWrap                     from './inputDateWrap.js';
 import MyInputDictionary                   from './inputDictionary.js';
-import {isAttributeString}                 from './shared/attribute.js';
+import {
+	isAttributeFiles,
+	isAttributeString
+} from './shared/attribute.js';
 import {getTemplateQuery}                  from './shared/builderTemplate.js';
 import {getColumnIsFilterable}             from './shared/column.js';
 import {getNestedIndexAttributeIdsByJoins} from './shared/query.js';
@@ -72,7 +75,10 @@ const MyFilterOperator = {
 	template:`<select v-model="value" :disabled="readonly">
 
 		<!-- operators in Builder mode -->
-		<template v-if="builderMode">
+		<template v-if="builderMode && onlyFts">
+			<option value="@@" :title="getTitle('@@')">@@</option>
+		</template>
+		<template v-if="builderMode && !onlyFts">
 			<option v-for="op in optionsEqual.filter(v => !disableOperators.includes(v))" :title="getTitle(op)">{{ op }}</option>
 
 			<optgroup v-for="(operators,label) in optionGroupsBuilder" :label>
@@ -98,6 +104,7 @@ const MyFilterOperator = {
 		hasFts:          { type:Boolean, required:false, default:false }, // show full text search operators
 		modelValue:      { type:String,  required:true },
 		onlyDates:       { type:Boolean, required:false, default:false }, // only show operators that can be used for date values (e. g. unix time)
+		onlyFts:         { type:Boolean, required:false, default:false }, // only show full text search operators (e. g. file contents)
 		onlyString:      { type:Boolean, required:false, default:false }, // only show string operators
 		readonly:        { type:Boolean, required:true }
 	},
@@ -109,6 +116,9 @@ const MyFilterOperator = {
 		},
 		optionsEqual:s => ['=','<>'],
 		optionsUser: s => {
+			if(s.onlyFts)
+				return ['@@'];
+
 			// default options
 			let out = ['=', '<>'];
 			if(!s.onlyString)             out.push('<','>','<=','>=');
@@ -860,6 +870,7 @@ const MyFilter = {
 			:disableOperators
 			:hasFts="side0ColumFtsMode !== null"
 			:onlyDates="side0ColumDate || side0ColumTime"
+			:onlyFts="side0ColumFiles"
 			:onlyString="isStringInput"
 			:readonly
 		/>
@@ -942,6 +953,14 @@ const MyFilter = {
 					return this.operatorInput = '=';
 			},
 			immediate:false
+		},
+		side0ColumFiles:{
+			handler(v) {
+				// file contents can only be searched via full text search
+				if(v && this.operator !== '@@')
+					this.operatorInput = '@@';
+			},
+			immediate:true
 		}
 	},
 	computed:{
@@ -1007,7 +1026,11 @@ const MyFilter = {
 		side0ColumFtsMode:s => {
 			if(!s.side0Column) return null;
 
+			// file contents are searched with a chosen dictionary
 			const atr = s.attributeIdMap[s.side0Column.attributeId];
+			if(s.isAttributeFiles(atr.content))
+				return 'dict';
+
 			const rel = s.relationIdMap[atr.relationId];
 			for(const ind of rel.indexes) {
 				if(ind.method === 'GIN' && ind.attributes.length === 1
@@ -1018,6 +1041,7 @@ const MyFilter = {
 			}
 			return null;
 		},
+		side0ColumFiles: s => s.side0Column && s.isAttributeFiles(s.attributeIdMap[s.side0Column.attributeId].content),
 		side0ColumDate:  s => s.side0Column && ['date','datetime'].includes(s.attributeIdMap[s.side0Column.attributeId].contentUse),
 		side0ColumTime:  s => s.side0Column && ['datetime','time'].includes(s.attributeIdMap[s.side0Column.attributeId].contentUse),
 		isAnyBracketsSet:s => s.brackets0Input !== 0 || s.brackets1Input !== 0,
@@ -1041,6 +1065,7 @@ const MyFilter = {
 	methods:{
 		// externals
 		getDictByLang,
+		isAttributeFiles,
 		isAttributeString,
 
 		// actions
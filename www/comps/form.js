@@ -2,6 +2,8 @@ import MyArticles                    from './articles.js';
 import MyField                       from './field.js';
 import MyFormActions                 from './formActions.js';
 import MyFormLog                     from './formLog.js';
+import MyFormRecycle                 from './formRecycle.js';
+import {hasAccessToRelation}         from './shared/access.js';
 import {getAttributeFileVersionHref} from './shared/attribute.js';
 import {getCollectionValues}         from './shared/collection.js';
 import {getColumnsProcessed}         from './shared/column.js';
@@ -11,7 +13,9 @@ import {jsFunctionRun}               from './shared/jsFunction.js';
 import {srcBase64}                   from './shared/image.js';
 import {getCaption}                  from './shared/language.js';
 import {layoutSettleSpace}           from './shared/layout.js';
+import {getUnixFormat}               from './shared/time.js';
 import {
+	isAttributeFiles,
 	isAttributeRelationship,
 	isAttributeRelationshipN1,
 	getAttributeValueFromString,
@@ -53,6 +57,12 @@ import {
 	getQueryFiltersProcessed,
 	getRelationsJoined
 } from './shared/query.js';
+import {
+	getSubscriptionId,
+	isSubscriptionAffected,
+	subscribeRecords,
+	unsubscribe
+} from './shared/subscription.js';
 import {
 	variableValueGet,
 	variableValueSet
@@ -64,7 +74,8 @@ export default {
 		MyArticles,
 		MyField,
 		MyFormActions,
-		MyFormLog
+		MyFormLog,
+		MyFormRecycle
 	},
 	template:`<div class="form-wrap"
 		:class="{ float:isPopUpFloating, fullscreen:popUpFullscreen, popUp:isPopUp }"
@@ -148,6 +159,11 @@ export default {
 							:active="!isNew || !isData"
 							:captionTitle="capApp.button.logHint"
 						/>
+						<my-button image="undo.png"
+							v-if="hasRecycle"
+							@trigger="toggleRecycle"
+							:captionTitle="capApp.button.recycleHint"
+						/>
 					</template>
 
 					<my-button image="star1.png"
@@ -222,6 +238,19 @@ export default {
 						:caption="capApp.noAccess"
 						:cancel="true"
 					/>
+					<my-button image="time.png"
+						v-if="asOf !== null"
+						@trigger="asOfSet(null)"
+						:caption="capApp.asOf.replace('{DATE}',getUnixFormat(asOf,settings.dateFormat + ' H:i:S'))"
+						:captionTitle="capApp.asOfHint"
+						:cancel="true"
+					/>
+					<my-button image="warning.png"
+						v-if="asOf !== null && asOfInvalidCount !== 0"
+						:caption="capApp.asOfInvalid.replace('{COUNT}',asOfInvalidCount)"
+						:captionTitle="capApp.asOfInvalidHint"
+						:cancel="true"
+					/>
 				</div>
 				<my-form-actions
 					v-if="hasFormActions"
@@ -302,7 +331,9 @@ export default {
 		<!-- form change logs -->
 		<my-form-log
 			v-if="showLog"
+			@as-of="asOfSet"
 			@close="toggleLog"
+			@restored="get"
 			:entityIdMapEffect
 			:fields
 			:fieldIdMapIndexMapRecordIds
@@ -314,6 +345,15 @@ export default {
 			:moduleId
 		/>
 
+		<!-- recycle bin -->
+		<my-form-recycle
+			v-if="showRecycle"
+			@close="toggleRecycle"
+			@restored="recycleRestored"
+			:moduleId
+			:relationId
+		/>
+
 		<!-- form help articles -->
 		<my-articles class="form-help"
 			v-if="showHelp"
@@ -340,6 +380,7 @@ export default {
 	emits:['close','pop-up-replace','record-deleted','record-updated','records-open','refresh-parent'],
 	mounted() {
 		this.$watch('appResized',() => this.resized());
+		this.$watch('recordsChanged',this.handleRecordsChanged);
 		this.$watch(() => [this.favoriteId,this.formId,this.recordIds],this.reset,{
 			immediate:true
 		});
@@ -357,10 +398,15 @@ export default {
 
 		window.removeEventListener('keydown',this.handleHotkeys);
 		this.timerClearAll();
+
+		if(this.subscribed)
+			this.unsubscribe(this.subscriptionId);
 	},
 	data() {
 		return {
 			// states
+			asOf:null,              // unix time, record values are reconstructed from change logs for this date, form is read only
+			asOfInvalidCount:0,     // number of values that could not be reconstructed for as-of date
 			badLoad:false,          // attempted record load with no return (can happen if access is lost during save)
 			badSave:false,          // attempted save (data SET) with invalid fields, also updates data fields
 			blockInputs:false,      // disable all user inputs (used by frontend functions)
@@ -384,6 +430,9 @@ export default {
 			routingGuardSkip:false, // skip routing guard once
 			showHelp:false,         // show form context help
 			showLog:false,          // show data change log
+			showRecycle:false,      // show recycle bin of form relation
+			subscribed:false,       // form is subscribed to changes of its records
+			subscriptionId:getSubscriptionId('form'), // ID of record subscription
 			titleOverwrite:null,    // custom form title, can be set via frontend function
 
 			// form data
@@ -395,6 +444,7 @@ export default {
 			},
 			indexMapRecordId:{},          // record IDs for form, key: relation index
 			indexMapRecordKey:{},         // record en-/decryption keys, key: relation index
+			indexMapRecordVersion:{},     // record versions as loaded, sent on save to detect changes by others, key: relation index
 			indexesNoDel:[],              // relation indexes with no DEL permission (via relation policy)
 			indexesNoSet:[],              // relation indexes with no SET permission (via relation policy)
 			loginIdsEncryptFor:[],        // login IDs for which data keys are encrypted (e2ee), for current form relations/records
@@ -863,6 +913,7 @@ export default {
 		hasChanges:    (s) => s.fieldIdsChanged.length !== 0,
 		hasChangesBulk:(s) => s.fieldIdsTouched.length !== 0 && s.isBulkUpdate,
 		hasFormActions:(s) => s.form.actions.filter(v => (s.entityIdMapEffect.formAction[v.id]?.state !== undefined ? s.entityIdMapEffect.formAction[v.id].state : v.state) !== 'hidden').length > 0,
+		hasRecycle:    (s) => s.isData && !s.isBulkUpdate && s.relationIdMap[s.relationId].recycleDays > 0 && s.hasAccessToRelation(s.access,s.relationId,3),
 		helpAvailable: (s) => s.form.articleIdsHelp.length !== 0 || s.moduleIdMap[s.moduleId].articleIdsHelp.length !== 0,
 		isBulkUpdate:  (s) => s.isData && s.recordIds.length > 1,
 		isData:        (s) => s.relationId !== null,
@@ -939,6 +990,7 @@ export default {
 		loginPublicKey:     (s) => s.$store.getters.loginPublicKey,
 		loginPrivateKey:    (s) => s.$store.getters.loginPrivateKey,
 		patternStyle:       (s) => s.$store.getters.patternStyle,
+		recordsChanged:     (s) => s.$store.getters.recordsChanged,
 		settings:           (s) => s.$store.getters.settings
 	},
 	methods:{
@@ -972,8 +1024,12 @@ export default {
 		getRelationsJoined,
 		getResolvedPlaceholders,
 		getRowsDecrypted,
+		getUnixFormat,
+		hasAccessToRelation,
+		isAttributeFiles,
 		isAttributeRelationship,
 		isAttributeRelationshipN1,
+		isSubscriptionAffected,
 		jsFunctionRun,
 		layoutSettleSpace,
 		openLink,
@@ -982,6 +1038,8 @@ export default {
 		rsaDecrypt,
 		rsaEncrypt,
 		srcBase64,
+		subscribeRecords,
+		unsubscribe,
 		variableValueGet,
 		variableValueSet,
 
@@ -1005,6 +1063,23 @@ export default {
 				}
 			}
 		},
+		handleRecordsChanged(change) {
+			// own changes are already known, as they were saved by this or another form of the same login
+			if(!this.isSubscriptionAffected(change,this.subscriptionId) || change.loginId === this.loginId
+				|| this.changingRecord || this.asOf !== null) {
+
+				return;
+			}
+
+			if(change.deleted)
+				return this.messageSet(this.capApp.message.recordDeletedOther,10000);
+
+			// reload record if no unsaved changes would be lost
+			if(this.hasChanges)
+				return this.messageSet(this.capApp.message.recordChangedOther,10000);
+
+			this.get();
+		},
 		messageSet(message,duration) {
 			// convert message codes
 			switch(message) {
@@ -1033,6 +1108,7 @@ export default {
 
 				this.message            = null;
 				this.showLog            = false;
+				this.showRecycle        = false;
 				this.titleOverwrite     = null;
 				this.lastFormId         = this.form.id;
 				this.variableIdMapLocal = {};
@@ -1052,6 +1128,7 @@ export default {
 			}
 
 			// reset form behaviour and load record
+			this.asOf        = null;
 			this.blockInputs = false;
 			this.firstLoad   = false;
 			this.fieldIdMapIndexMapRecordIds = {};
@@ -1087,6 +1164,7 @@ export default {
 			this.indexesNoSet              = [];
 			this.indexMapRecordId          = {};
 			this.indexMapRecordKey         = {};
+			this.indexMapRecordVersion     = {};
 			this.fieldIdsTouched           = [];
 		},
 		releaseLoadingOnNextTick() {
@@ -1182,6 +1260,11 @@ export default {
 					this.indexesNoSet.splice(pos,1);
 			}
 
+			// update record versions for each relation index
+			for(let index in row.indexRecordVersions) {
+				this.indexMapRecordVersion[index] = row.indexRecordVersions[index];
+			}
+
 			// update record data keys for each relation index
 			for(let index in row.indexRecordEncKeys) {
 				this.indexMapRecordKey[index] = await this.rsaDecrypt(
@@ -1417,6 +1500,22 @@ export default {
 			this.showLog = !this.showLog;
 			this.resized();
 		},
+		asOfSet(unix) {
+			this.asOf    = unix;
+			this.showLog = false;
+
+			if(unix === null)
+				this.blockInputs = false;
+
+			this.get();
+		},
+		recycleRestored(recordId) {
+			this.showRecycle = false;
+			this.openForm([recordId]);
+		},
+		toggleRecycle() {
+			this.showRecycle = !this.showRecycle;
+		},
 
 		// timer
 		timerClear(name) {
@@ -1449,14 +1548,15 @@ export default {
 		openDoc(openDoc) {
 			const recordId = this.indexMapRecordId[openDoc.relationIndexOpen] !== undefined ? this.indexMapRecordId[openDoc.relationIndexOpen] : 0;
 			if(openDoc.fieldIdAddTo === null || this.fieldIdMapData[openDoc.fieldIdAddTo] === undefined)
-				return this.openLinkNoCache(`/doc/download/file.pdf?doc_id=${openDoc.docIdOpen}&record_id=${recordId}&token=${this.token}`,true);
+				return this.openLinkNoCache(`/doc/download/file.pdf?doc_id=${openDoc.docIdOpen}&record_id=${recordId}${this.asOf !== null ? `&as_of=${this.asOf}` : ''}&token=${this.token}`,true);
 
 			const fieldId = openDoc.fieldIdAddTo;
 			const atr     = this.attributeIdMap[this.fieldIdMapData[fieldId].attributeId];
 			ws.send('doc','create',{
 				docId:openDoc.docIdOpen,
 				recordIdLoad:recordId,
-				attributeIdTarget:atr.id
+				attributeIdTarget:atr.id,
+				asOf:this.asOf
 			},true,true).then(
 				res => {
 					const ia = this.getIndexAttributeIdByField(this.fieldIdMapData[fieldId],false);
@@ -1618,6 +1718,10 @@ export default {
 
 			// no or multiple records defined, no need to load record data
 			if(this.isNew || this.isBulkUpdate) {
+				if(this.subscribed) {
+					this.unsubscribe(this.subscriptionId);
+					this.subscribed = false;
+				}
 				this.resetRecordMeta();
 				this.triggerEventAfter('open');
 				this.releaseLoadingOnNextTick();
@@ -1648,15 +1752,25 @@ export default {
 				joins:this.relationsJoined,
 				expressions:expressions,
 				filters:filters,
-				getPerm:true
+				getPerm:true,
+				getVersion:true,
+				asOf:this.asOf
 			},true).then(
 				res => {
 					// reset states
 					this.resetRecordMeta();
 					this.loading = true;
+					this.asOfInvalidCount = res.payload.expressionsNoAsOf.length
+						+ res.payload.rows.reduce((sum,row) => sum + row.valuesNoAsOf.length,0);
+
+					if(this.asOf !== null)
+						this.blockInputs = true;
 
 					this.valueSetByRows(res.payload.rows,expressions).then(
-						() => this.triggerEventAfter('open'),
+						() => {
+							this.subscribeToRecords();
+							this.triggerEventAfter('open');
+						},
 						err => {
 							this.badLoad = true;
 							this.consoleError(err);
@@ -1745,11 +1859,15 @@ export default {
 				joins:joins,
 				expressions:expressions,
 				filters:filters,
-				getPerm:true
+				getPerm:true,
+				getVersion:true
 			},true).then(
 				res => {
 					this.valueSetByRows(res.payload.rows,expressions).then(
-						() => this.triggerEventAfter('open'),
+						() => {
+							this.subscribeToRecords();
+							this.triggerEventAfter('open');
+						},
 						this.$root.genericError
 					);
 				},
@@ -1833,7 +1951,9 @@ export default {
 					indexFrom:j.indexFrom,
 					recordId:j.recordId,
 					attributes:[],
-					encKeysSet:encLoginKeys
+					encKeysSet:encLoginKeys,
+					version:!isNew && this.indexMapRecordVersion[index] !== undefined
+						? this.indexMapRecordVersion[index] : null
 				};
 			};
 
@@ -1950,7 +2070,7 @@ export default {
 				catch(err) { return handleEncErr(err); }
 			}
 
-			ws.sendMultiple(requests,true).then(
+			return ws.sendMultiple(requests,true).then(
 				res => {
 					const resSet = res[0];
 
@@ -1980,11 +2100,87 @@ export default {
 					// if we knew nothing triggered, we could update our values without reload
 					this.get();
 				},
-				this.$root.genericError
+				err => {
+					// record was changed by someone else since it was loaded
+					if(typeof err === 'string' && err.startsWith('{ERR_APP_010}'))
+						return this.setConflict(JSON.parse(err.substring(13)),relations,saveAndNew,saveAndClose);
+
+					this.$root.genericError(err);
+				}
 			).finally(
 				() => this.changingRecord = false
 			);
 		},
+		setConflict:async function(conflict,relations,saveAndNew,saveAndClose) {
+			if(conflict.deleted)
+				return this.messageSet(this.capApp.message.recordDeletedOther,10000);
+
+			// compare current values with loaded ones, to find values that were changed here and by others
+			const r = relations[conflict.index];
+			let conflicts = [];
+			for(let i = 0, j = r.attributes.length; i < j; i++) {
+				const a   = r.attributes[i];
+				const atr = this.attributeIdMap[a.attributeId];
+				const ia  = this.getIndexAttributeId(conflict.index,a.attributeId,a.outsideIn,a.attributeIdNm);
+				let value = conflict.values[i];
+
+				// file attributes only send changes, these are applied without conflict
+				if(this.isAttributeFiles(atr.content))
+					continue;
+
+				if(value !== null && atr.encrypted) {
+					try {
+						value = await this.aesGcmDecryptBase64WithPhrase(value,this.indexMapRecordKey[conflict.index]);
+					}
+					catch(err) { return this.consoleError(err); }
+				}
+
+				if(!this.valueIsEqual(value,this.valuesOrg[ia]) && !this.valueIsEqual(value,this.values[ia]))
+					conflicts.push({ atr:atr, ia:ia, value:value });
+			}
+
+			// others only changed different values, save own changes to new version
+			this.indexMapRecordVersion[conflict.index] = conflict.version;
+			if(conflicts.length === 0)
+				return this.set(saveAndNew,saveAndClose);
+
+			const getDisplay = v => {
+				const el = document.createElement('span');
+				el.textContent = v === null ? '-' : (typeof v === 'object' ? JSON.stringify(v) : String(v));
+				return el.innerHTML;
+			};
+
+			let list = [];
+			for(const c of conflicts) {
+				const rel = this.relationIdMap[c.atr.relationId];
+				list.push(`<li><b>${this.getCaption('attributeTitle',rel.moduleId,c.atr.id,c.atr.captions,c.atr.name)}</b>: `
+					+ `${getDisplay(this.values[c.ia])} / ${getDisplay(c.value)}</li>`);
+			}
+
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.conflict.replace('{LIST}',`<ul>${list.join('')}</ul>`),
+				captionTop:this.capApp.dialog.conflictTitle,
+				image:'warning.png',
+				buttons:[{
+					caption:this.capApp.button.conflictKeepMine,
+					exec:() => this.set(saveAndNew,saveAndClose),
+					image:'save.png'
+				},{
+					caption:this.capApp.button.conflictKeepTheirs,
+					exec:() => {
+						for(const c of conflicts) {
+							this.valueSet(c.ia,c.value,true,false);
+						}
+						this.messageSet(this.capApp.message.conflictResolved,10000);
+					},
+					image:'refresh.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
 		setBulkUpdate() {
 			// bulk update, limitations:
 			// only existing records, only pop-up, no encryption, no joins
@@ -2057,6 +2253,22 @@ export default {
 			).finally(
 				() => this.changingRecord = false
 			);
+		},
+		subscribeToRecords() {
+			// subscribe to all records of form relations, changes by others are pushed by the server
+			let relationIdMapRecordIds = {};
+			for(const j of this.joins) {
+				const recordId = this.indexMapRecordId[j.index];
+				if(recordId === undefined || recordId === null || recordId === 0)
+					continue;
+
+				if(relationIdMapRecordIds[j.relationId] === undefined)
+					relationIdMapRecordIds[j.relationId] = [];
+
+				relationIdMapRecordIds[j.relationId].push(recordId);
+			}
+			this.subscribeRecords(this.subscriptionId,relationIdMapRecordIds);
+			this.subscribed = true;
 		}
 	}
 };
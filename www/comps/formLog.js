@@ -171,8 +171,21 @@ const myFormLogValueSidebar = {
 			:showLarge=true
 			:value="attributeValue.value"
 		/>
+		<div class="row gap wrap" v-if="source.fieldId === null">
+			<my-button image="undo.png"
+				v-if="isRestorable"
+				@trigger="$emit('restore')"
+				:caption="capApp.button.restore"
+				:captionTitle="capApp.button.restoreHint"
+			/>
+			<my-button image="time.png"
+				@trigger="$emit('as-of',log.dateChange)"
+				:caption="capApp.button.asOf"
+				:captionTitle="capApp.button.asOfHint"
+			/>
+		</div>
 	</div>`,
-	emits:['close'],
+	emits:['as-of','close','restore'],
 	props:{
 		attributeId:                  { type:[String,null], required:true },
 		isFullscreen:                 { type:Boolean,       required:true },
@@ -191,6 +204,11 @@ const myFormLogValueSidebar = {
 				? s.capGen.record + ': ' + s.relationIdMapRecordIdMapTitle[s.log.relationId]?.[s.log.recordId] : null;
 		},
 		isReady:         s => s.isValueComment || s.attributeValue !== null,
+
+		// file changes are logged as deltas, encrypted values cannot be restored without data keys
+		isRestorable:s => s.isValueAttribute
+			&& !s.source.attributeIdsFiles.includes(s.attributeId)
+			&& !s.source.attributeIdsEnc.includes(s.attributeId),
 		isValueAttribute:s => s.attributeId !== null,
 		isValueComment:  s => s.log.comment !== null,
 
@@ -349,7 +367,9 @@ export default {
 					</div>
 					<my-form-log-value-sidebar
 						v-if="isSidebarLogShown"
+						@as-of="$emit('as-of',$event)"
 						@close="logShownSidebarSet(null,null,false)"
+						@restore="restoreAsk"
 						:attributeId="logShownAttributeId"
 						:isFullscreen="showFullscreen"
 						:isSingleSource="isSingleSourceForm"
@@ -372,7 +392,7 @@ export default {
 		joinsIndexMap:              { type:Object, required:true },
 		moduleId:                   { type:String, required:true }
 	},
-	emits:['close'],
+	emits:['as-of','close','restored'],
 	data() {
 		return {
 			logs:[],
@@ -655,6 +675,38 @@ export default {
 		},
 
 		// backend calls
+		restoreAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.restore,
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.restore,
+					exec:this.restore,
+					keyEnter:true,
+					image:'undo.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		restore() {
+			const l = this.logShownSidebar;
+			ws.send('data','restoreLog',{
+				relationId:l.relationId,
+				recordId:l.recordId,
+				dataLogId:l.id,
+				attributeIds:[this.logShownAttributeId]
+			},true).then(
+				() => {
+					this.$emit('restored');
+					this.logShownSidebarSet(null,null,false);
+					this.get(false);
+				},
+				this.$root.genericError
+			);
+		},
 		get(isNextPage) {
 			let requests = [];
 
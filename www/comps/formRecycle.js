@@ -0,0 +1,170 @@
+import MyInputOffset from './inputOffset.js';
+import {getCaption}    from './shared/language.js';
+import {getUnixFormat} from './shared/time.js';
+
+export default {
+	name:'my-form-recycle',
+	components:{ MyInputOffset },
+	template:`<div class="app-sub-window" @mousedown.left.self="$emit('close')" :class="{ 'under-header':!isMobile }">
+		<div class="contentBox scroll float form-log">
+			<div class="top lower">
+				<div class="area nowrap">
+					<img class="icon" src="images/delete.png" />
+					<h1>{{ capApp.title }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="refresh.png"
+						@trigger="get"
+						:captionTitle="capGen.button.refresh"
+					/>
+					<my-button image="cancel.png" @trigger="$emit('close')" :caption="capGen.button.close" :cancel="true" />
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<span>{{ capApp.hint.replace('{DAYS}',relation.recycleDays) }}</span>
+				</div>
+				<div class="area">
+					<my-input-offset
+						@input="offset = $event;get()"
+						:caption="true"
+						:limit
+						:offset
+						:total
+					/>
+				</div>
+			</div>
+			<div class="form-log-content">
+				<div class="form-log-table">
+					<table class="generic-table auto-height sticky-top no-wrap-text-header bright topAligned">
+						<thead>
+							<tr>
+								<th></th>
+								<th>{{ capApp.dateDelete }}</th>
+								<th v-if="!isMobile">{{ capApp.deletedBy }}</th>
+								<th v-for="a in attributesShown">{{ getAttributeTitle(a) }}</th>
+							</tr>
+						</thead>
+						<tbody>
+							<tr v-if="entries.length === 0"><td colspan="999">{{ capGen.nothingThere }}</td></tr>
+							<tr v-for="(e,i) in entries" :class="{ 'row-contrast':i % 2 === 0 }">
+								<td class="minimum">
+									<div class="row gap">
+										<my-button image="undo.png"
+											@trigger="restore(e.id)"
+											:caption="isMobile ? '' : capApp.button.restore"
+										/>
+										<my-button image="delete.png"
+											@trigger="delAsk(e.id)"
+											:cancel="true"
+											:captionTitle="capApp.button.purge"
+										/>
+									</div>
+								</td>
+								<td class="minimum">{{ getUnixFormat(e.dateDelete,settings.dateFormat + ' H:i:S') }}</td>
+								<td class="minimum" v-if="!isMobile">
+									<span v-if="e.loginName !== null">{{ e.loginName }}</span>
+									<span v-else><i>[{{ capGen.system }}]</i></span>
+								</td>
+								<td v-for="a in attributesShown">
+									<my-value-rich
+										v-if="e.values[a.id] !== undefined && e.values[a.id] !== null"
+										:attributeId="a.id"
+										:length="60"
+										:value="e.values[a.id]"
+									/>
+								</td>
+							</tr>
+						</tbody>
+					</table>
+				</div>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		moduleId:  { type:String, required:true },
+		relationId:{ type:String, required:true }
+	},
+	emits:['close','restored'],
+	data() {
+		return {
+			entries:[],
+			limit:50,
+			offset:0,
+			total:0
+		};
+	},
+	computed:{
+		// show attributes with values, relationship values are record IDs and not meaningful
+		attributesShown:s => s.relation.attributes.filter(a =>
+			a.name !== 'id' && a.relationshipId === null
+			&& s.entries.findIndex(e => e.values[a.id] !== undefined) !== -1
+		),
+
+		// simple
+		relation:s => s.relationIdMap[s.relationId],
+
+		// stores
+		relationIdMap:s => s.$store.getters['schema/relationIdMap'],
+		capApp:       s => s.$store.getters.captions.formRecycle,
+		capGen:       s => s.$store.getters.captions.generic,
+		isMobile:     s => s.$store.getters.isMobile,
+		settings:     s => s.$store.getters.settings
+	},
+	mounted() {
+		this.get();
+	},
+	methods:{
+		// externals
+		getCaption,
+		getUnixFormat,
+
+		// presentation
+		getAttributeTitle(atr) {
+			return this.getCaption('attributeTitle',this.moduleId,atr.id,atr.captions,atr.name);
+		},
+
+		// backend calls
+		delAsk(id) {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.purge,
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.purge,
+					exec:() => this.del(id),
+					keyEnter:true,
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		del(id) {
+			ws.send('data','delRecycle',{id:id},true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.send('data','getRecycle',{
+				relationId:this.relationId,
+				limit:this.limit,
+				offset:this.offset
+			},true).then(
+				res => {
+					this.entries = res.payload.entries;
+					this.total   = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		restore(id) {
+			ws.send('data','restoreRecycle',{id:id},true).then(
+				res => this.$emit('restored',res.payload),
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -297,9 +297,103 @@ const MyGlobalSearchModule = {
 	}
 };
 
+const MyGlobalSearchIndex = {
+	name:'my-global-search-index',
+	template:`<div class="global-search-index column gap" v-if="results.length !== 0">
+		<my-label class="global-search-header" image="search.png"
+			:caption="capApp.indexResults.replace('{CNT}',results.length)"
+			:large="true"
+		/>
+		<div class="global-search-index-result row gap centered"
+			v-for="r in results"
+			@click="openForm(r,false)"
+			@click.middle="openForm(r,true)"
+			:class="{ clickable:r.formId !== null }"
+		>
+			<my-label
+				:caption="getRelationCaption(r.relationId)"
+				:imageBase64="srcBase64Icon(moduleIdMap[relationIdMap[r.relationId].moduleId].iconId,'images/module.png')"
+			/>
+			<span>{{ r.title !== null ? r.title : '#' + r.recordId }}</span>
+		</div>
+	</div>`,
+	emits:['close','pop-up-open','result-count-update'],
+	props:{
+		input:{ type:String, required:true }
+	},
+	watch:{
+		input:{
+			handler() { this.get(); },
+			immediate:true
+		}
+	},
+	data() {
+		return {
+			results:[]
+		};
+	},
+	computed:{
+		// stores
+		moduleIdMap:  (s) => s.$store.getters['schema/moduleIdMap'],
+		relationIdMap:(s) => s.$store.getters['schema/relationIdMap'],
+		capApp:       (s) => s.$store.getters.captions.globalSearch,
+		options:      (s) => s.$store.getters['local/globalSearchOptions']
+	},
+	methods:{
+		// externals
+		getCaption,
+		getFormPopUpConfig,
+		getFormRoute,
+		openLink,
+		srcBase64Icon,
+
+		// presentation
+		getRelationCaption(relationId) {
+			const r = this.relationIdMap[relationId];
+			return this.getCaption('relationTitle',r.moduleId,r.id,r.captions,r.name);
+		},
+
+		// actions
+		openForm(result,newTab) {
+			if(result.formId === null)
+				return;
+
+			if(this.options.openAsPopUp && !newTab)
+				return this.$emit('pop-up-open',this.getFormPopUpConfig([result.recordId],{
+					formIdOpen:result.formId,
+					maxHeight:0,
+					maxWidth:0,
+					popUpType:'float'
+				},[],null));
+
+			const path = this.getFormRoute(null,result.formId,result.recordId,true,[]);
+			if(newTab)
+				return this.openLink('#'+path,true);
+
+			this.$router.push(path);
+			this.$emit('close');
+		},
+
+		// backend calls
+		get() {
+			if(this.input === '') {
+				this.results = [];
+				return this.$emit('result-count-update',0);
+			}
+			ws.send('data','search',{ input:this.input, limit:this.options.limit },false).then(
+				res => {
+					this.results = res.payload;
+					this.$emit('result-count-update',this.results.length);
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
+
 const MyGlobalSearch = {
 	name:'my-global-search',
-	components:{ MyForm, MyGlobalSearchModule, MyInputDictionary },
+	components:{ MyForm, MyGlobalSearchIndex, MyGlobalSearchModule, MyInputDictionary },
 	template:`<div class="app-sub-window"
 		@mousedown.self="close"
 		:class="{ 'under-header':!isMobile }"
@@ -410,6 +504,12 @@ const MyGlobalSearch = {
 			</div>
 			<div class="content column grow no-padding global-search-results" :style="patternStyle">
 				<div class="global-search-modules column gap">
+					<my-global-search-index
+						@close="close"
+						@pop-up-open="popUp = $event"
+						@result-count-update="indexResultCount = $event"
+						:input="inputActive"
+					/>
 					<my-global-search-module
 						@close="close"
 						@pop-up-open="popUp = $event"
@@ -443,7 +543,8 @@ const MyGlobalSearch = {
 	</div>`,
 	data() {
 		return {
-			inputActive:'', // submitted input
+			indexResultCount:0, // result count of global search index
+			inputActive:'',     // submitted input
 			input:'',       // input text from input element
 			larger:false,
 			moduleIdMapResultCount:{}, // result count per module ID
@@ -477,7 +578,7 @@ const MyGlobalSearch = {
 			return out;
 		},
 		resultCount:(s) => {
-			let cnt = 0;
+			let cnt = s.indexResultCount;
 			for(const k in s.moduleIdMapResultCount) {
 				cnt += s.moduleIdMapResultCount[k];
 			}
@@ -24,9 +24,9 @@ export default {
 				<img src="images/dots.png" />
 			</div>
 			
-			<template v-if="!isMobile && isAdmin && !pwaSingle" >
+			<template v-if="!isMobile && isAdminAny && !pwaSingle" >
 				<router-link class="entry no-wrap clickable" to="/builder"
-					v-if="builderEnabled"
+					v-if="builderEnabled && adminPermissions.includes('builder')"
 					:title="capGen.button.openBuilder"
 				>
 					<img src="images/builder.png" />
@@ -343,7 +343,7 @@ export default {
 		
 		// simple
 		isDark:          (s) => s.colorHeaderMain.isDark(),
-		isGlobalSearchOn:(s) => s.searchModuleIds.length !== 0,
+		isGlobalSearchOn:(s) => s.searchModuleIds.length !== 0 || s.searchIndexOn,
 		pwaSingle:       (s) => s.pwaModuleId !== null,
 		showCollections: (s) => s.layoutElementsProcessed.includes('collections'),
 		showFeedback:    (s) => s.layoutElementsProcessed.includes('feedback') && s.reposFeedback.length !== 0 && !s.isNoAuth,
@@ -360,6 +360,7 @@ export default {
 		moduleNameMap:       (s) => s.$store.getters['schema/moduleNameMap'],
 		formIdMap:           (s) => s.$store.getters['schema/formIdMap'],
 		collectionIdMap:     (s) => s.$store.getters['schema/collectionIdMap'],
+		adminPermissions:    (s) => s.$store.getters.adminPermissions,
 		appResized:          (s) => s.$store.getters.appResized,
 		builderEnabled:      (s) => s.$store.getters.builderEnabled,
 		busyCounter:         (s) => s.$store.getters.busyCounter,
@@ -367,7 +368,7 @@ export default {
 		capGen:              (s) => s.$store.getters.captions.generic,
 		colorHeaderAccent:   (s) => s.$store.getters.colorHeaderAccent,
 		colorHeaderMain:     (s) => s.$store.getters.colorHeaderMain,
-		isAdmin:             (s) => s.$store.getters.isAdmin,
+		isAdminAny:          (s) => s.$store.getters.isAdminAny,
 		isAtHistoryEnd:      (s) => s.$store.getters.isAtHistoryEnd,
 		isAtHistoryStart:    (s) => s.$store.getters.isAtHistoryStart,
 		isAtMenu:            (s) => s.$store.getters.isAtMenu,
@@ -380,6 +381,7 @@ export default {
 		pwaModuleId:         (s) => s.$store.getters.pwaModuleId,
 		moduleIdLast:        (s) => s.$store.getters.moduleIdLast,
 		reposFeedback:       (s) => s.$store.getters.reposFeedback,
+		searchIndexOn:       (s) => s.$store.getters.searchIndexOn,
 		searchModuleIds:     (s) => s.$store.getters.searchModuleIds,
 		settings:            (s) => s.$store.getters.settings,
 		systemMsgActive:     (s) => s.$store.getters.systemMsgActive,
@@ -37,6 +37,12 @@ import {
 	routeChangeFieldReload,
 	routeParseParams
 } from './shared/router.js';
+import {
+	getSubscriptionId,
+	isSubscriptionAffected,
+	subscribeQuery,
+	unsubscribe
+} from './shared/subscription.js';
 
 export default {
 	name:'my-list',
@@ -681,6 +687,9 @@ export default {
 			showCsv:false,              // show UI for CSV import/export
 			showFilters:false,          // show UI for user filters
 			showOptions:false,          // show UI for list options
+			subscribed:false,           // list is subscribed to changes of its query relations
+			subscriptionId:getSubscriptionId('list'), // ID of record subscription
+			subscriptionTimer:null,     // timer for reload after records changed
 			
 			// constants
 			refTabindex:'input_row_', // prefix for vue references to tabindex elements
@@ -846,6 +855,7 @@ export default {
 		capApp:        (s) => s.$store.getters.captions.list,
 		capGen:        (s) => s.$store.getters.captions.generic,
 		isMobile:      (s) => s.$store.getters.isMobile,
+		recordsChanged:(s) => s.$store.getters.recordsChanged,
 		scrollFormId:  (s) => s.$store.getters.constants.scrollFormId,
 		settings:      (s) => s.$store.getters.settings
 	},
@@ -860,6 +870,7 @@ export default {
 		// setup watchers
 		this.$watch('appResized',this.resized);
 		this.$watch('limit',this.get);
+		this.$watch('recordsChanged',this.handleRecordsChanged);
 		this.$watch('dropdownShow',v => {
 			if(v) this.setOffsetAndReload(0);
 			this.focusOnInput();
@@ -931,6 +942,10 @@ export default {
 	},
 	beforeUnmount() {
 		this.clearAutoRenewTimer();
+		clearTimeout(this.subscriptionTimer);
+
+		if(this.subscribed)
+			this.unsubscribe(this.subscriptionId);
 	},
 	methods:{
 		// externals
@@ -950,9 +965,12 @@ export default {
 		getRelationsJoined,
 		getRowsDecrypted,
 		isAttributeTextSearchable,
+		isSubscriptionAffected,
 		layoutSettleSpace,
 		routeChangeFieldReload,
 		routeParseParams,
+		subscribeQuery,
+		unsubscribe,
 
 		handleKeydownLocal(ev) {
 			let focusTarget = null;
@@ -987,6 +1005,18 @@ export default {
 				}
 			}
 		},
+		handleRecordsChanged(change) {
+			if(!this.isSubscriptionAffected(change,this.subscriptionId))
+				return;
+
+			// bulk changes arrive in quick succession, reload once they settle
+			// reload is skipped while rows are selected, as selection would be lost
+			clearTimeout(this.subscriptionTimer);
+			this.subscriptionTimer = setTimeout(() => {
+				if(this.selectedRows.length === 0)
+					this.get();
+			},1000);
+		},
 		updateRecordIdsLoaded() {
 			let indexMapRecordIds = {};
 			for(const j of this.joins) {
@@ -1447,6 +1477,28 @@ export default {
 						this.consoleError
 					);
 					
+					// regular lists are kept up to date with changes to their records
+					// subscription is renewed with each retrieval, as filters & retrieved records change
+					if(!this.isInput) {
+						let relationIdMapRecordIds = {};
+						for(const r of res.payload.rows) {
+							for(const j of this.joins) {
+								const id = r.indexRecordIds[j.index];
+								if(id === null || id === undefined)
+									continue;
+								
+								if(relationIdMapRecordIds[j.relationId] === undefined)
+									relationIdMapRecordIds[j.relationId] = [];
+								
+								if(!relationIdMapRecordIds[j.relationId].includes(id))
+									relationIdMapRecordIds[j.relationId].push(id);
+							}
+						}
+						this.subscribeQuery(this.subscriptionId,this.query.relationId,
+							this.relationsJoined,this.filtersCombined,relationIdMapRecordIds);
+						
+						this.subscribed = true;
+					}
 				},
 				this.$root.genericError
 			).finally(() => this.rowsFetching = false);
@@ -71,8 +71,8 @@ export default {
 				<span>{{ message.license[licenseErrCode][language] }}</span>
 			</div>
 
-			<!-- Open ID Connect OAUTH2 clients -->
-			<template v-if="!showMfa && hasOpenIdClients">
+			<!-- Open ID Connect OAUTH2 clients & SAML identity providers -->
+			<template v-if="!showMfa && hasExtClients">
 				<div class="message">
 					<img src="images/globe.png" />
 					<span>{{ message.authExt[language] }}</span>
@@ -82,12 +82,16 @@ export default {
 						v-for="c in oauthClientIdMapOpenId"
 						@click="authenticateExternalOpenId(c)"
 					>{{ c.name }}</div>
+					<div class="open-id-client clickable"
+						v-for="p in samlIdpIdMapLogin"
+						@click="authenticateExternalSaml(p)"
+					>{{ p.name }}</div>
 				</div>
 			</template>
 			
 			<!-- credentials input -->
 			<div class="credentials" v-if="!showMfa">
-				<div class="message" v-if="hasOpenIdClients">
+				<div class="message" v-if="hasExtClients">
 					<img src="images/server.png" />
 					<span>{{ message.authInt[language] }}</span>
 				</div>
@@ -279,7 +283,7 @@ export default {
 			
 			return !s.badAuth && s.mfaTokenId !== null && s.mfaTokenPin !== null;
 		},
-		hasOpenIdClients:(s) => Object.keys(s.oauthClientIdMapOpenId).length !== 0,
+		hasExtClients:   (s) => Object.keys(s.oauthClientIdMapOpenId).length !== 0 || Object.keys(s.samlIdpIdMapLogin).length !== 0,
 		showCustom:      (s) => s.activated && (s.companyName !== '' || s.companyWelcome !== ''),
 		showMfa:         (s) => s.mfaTokens.length !== 0,
 		
@@ -301,6 +305,7 @@ export default {
 		loginSessionExpired:   (s) => s.$store.getters.loginSessionExpired,
 		oauthClientIdMapOpenId:(s) => s.$store.getters.oauthClientIdMapOpenId,
 		productionMode:        (s) => s.$store.getters.productionMode,
+		samlIdpIdMapLogin:     (s) => s.$store.getters.samlIdpIdMapLogin,
 		tokenKeepEnable:       (s) => s.$store.getters.tokenKeepEnable
 	},
 	watch:{
@@ -338,6 +343,13 @@ export default {
 				window.history.pushState({},'','/');
 				this.$store.commit('local/openIdAuthDetailsReset');
 			}
+
+			// check for SAML authentication redirect
+			if(params.has('samlCode')) {
+				this.authenticateBySaml(params.get('samlCode'));
+				window.history.pushState({},'','/');
+				return;
+			}
 			
 			// attempt authentication if token is available
 			if(this.token !== '')
@@ -440,6 +452,12 @@ export default {
 			);
 		},
 		
+		authenticateExternalSaml(p) {
+			// backend creates authentication request and redirects to identity provider
+			this.loading = true;
+			window.location.replace(`/saml/login/${p.id}`);
+		},
+		
 		// authentication against backend
 		authenticate() {
 			if(!this.isValid) return;
@@ -508,6 +526,21 @@ export default {
 			this.loading = true;
 
 		},
+		authenticateBySaml(code) {
+			ws.send('auth','saml',{code:code},true).then(
+				res => {
+					this.authenticatedByUser(
+						res.payload.id,
+						res.payload.name,
+						res.payload.token,
+						res.payload.saltKdf,
+						true
+					);
+				},
+				err => this.handleError('authUser',err)
+			);
+			this.loading = true;
+		},
 		authenticateByToken() {
 			ws.send('auth','token',this.token,true).then(
 				res => this.appEnable(res.payload.id,res.payload.name),
@@ -54,11 +54,22 @@ export function getTemplateAttribute(moduleId,relationId) {
 		def:'',
 		onUpdate:'NO ACTION',
 		onDelete:'NO ACTION',
+		validations:[],
 		captions:{
 			attributeTitle:{}
 		}
 	};
 };
+export function getTemplateAttributeValidation() {
+	return {
+		content:'unique',
+		value:null,
+		values:[],
+		operator:null,
+		attributeIdCompare:null,
+		attributeIdsScope:[]
+	};
+};
 export function getTemplateClientEvent(moduleId) {
 	return {
 		id:getUuidV4(),
@@ -681,13 +692,27 @@ export function getTemplateModule(name) {
 		releaseBuildApp:0,
 		releaseDate:0,
 		releaseLogCategories:['Added','Improved','Fixed'],
-		releases:[{build:0,buildApp:0,dateCreated:0,logs:[]}],
+		releases:[{build:0,buildApp:0,dateCreated:0,logs:[],migrations:[]}],
 		startForms:[],
 		captions:{
 			moduleTitle:{}
 		}
 	};
 };
+export function getTemplateNumberRange(moduleId,name) {
+	return {
+		id:getUuidV4(),
+		moduleId:moduleId,
+		attributeId:null,
+		attributeIdScope:null,
+		name:name,
+		comment:null,
+		prefix:'',
+		suffix:'',
+		digits:5,
+		reset:'never'
+	};
+};
 export function getTemplateOpenDoc() {
 	return {
 		docIdOpen:null,
@@ -732,8 +757,11 @@ export function getTemplatePgFunctionSchedule() {
 		atMinute:0,
 		atHour:12,
 		atDay:1,
+		attributeIdExclude:null,
+		cronExpr:null,
 		intervalType:'days',
-		intervalValue:3
+		intervalValue:3,
+		timeZone:null
 	};
 };
 export function getTemplatePgIndex(relationId) {
@@ -852,9 +880,12 @@ export function getTemplateRelation(moduleId,name,encryption) {
 		comment:null,
 		attributes:[],
 		attributeIdsTitle:[],
+		attributeIdsSearch:[],
 		encryption:encryption,
+		formIdSearch:null,
 		retentionCount:null,
 		retentionDays:null,
+		recycleDays:null,
 		policies:[],
 		captions:{
 			relationTitle:{}
@@ -891,7 +922,8 @@ export function getTemplateRole(moduleId,name) {
 		accessClientEvents:{},
 		accessCollections:{},
 		accessMenus:{},
-		accessRelations:{}
+		accessRelations:{},
+		attributeMasks:{}
 	};
 };
 export function getTemplateSearchBar(moduleId,name) {
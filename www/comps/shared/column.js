@@ -153,7 +153,8 @@ export function getColumnIsFilterable(c) {
 		return false;
 	
 	const atr = MyStore.getters['schema/attributeIdMap'][c.attributeId];
-	if(isAttributeFiles(atr.content) || atr.encrypted || atr.contentUse === 'color')
+	// files attributes are filterable by their names & extracted contents (full text search)
+	if(atr.encrypted || atr.contentUse === 'color')
 		return false;
 
 	return true;
@@ -65,6 +65,37 @@ export function resolveErrCode(message) {
 	let   cap  = MyStore.getters.captions.error[errContext][errNumber];
 	
 	// handle cases with error context data
+	if(errContext === 'APP') {
+		const getAtrTitle = id => {
+			const atr = MyStore.getters['schema/attributeIdMap'][id];
+			if(atr === undefined) return id;
+			
+			const rel = MyStore.getters['schema/relationIdMap'][atr.relationId];
+			return getCaption('attributeTitle',rel.moduleId,atr.id,atr.captions,atr.name);
+		};
+		
+		switch(errNumber) {
+			case '011': // fallthrough, validation failed: regex
+			case '012': // fallthrough, validation failed: min. value
+			case '013': // fallthrough, validation failed: max. value
+			case '014': // fallthrough, validation failed: min. length
+			case '015': // fallthrough, validation failed: max. length
+			case '016': // validation failed: allowed values
+				return cap.replace('{NAME}',getAtrTitle(data.attributeId))
+					.replace('{VALUE}',data.value).replace('{VALUES}',data.values.join(', '));
+			break;
+			case '017': // validation failed: comparison
+				return cap.replace('{NAME}',getAtrTitle(data.attributeId))
+					.replace('{OPERATOR}',data.operator)
+					.replace('{NAME_COMPARE}',getAtrTitle(data.attributeIdCompare));
+			break;
+			case '018': // validation failed: not unique
+				return cap.replace('{NAME}',getAtrTitle(data.attributeId))
+					.replace('{SCOPE}',data.attributeIdsScope.length === 0
+						? '' : ` (${data.attributeIdsScope.map(getAtrTitle).join(', ')})`);
+			break;
+		}
+	}
 	if(errContext === 'CSV') {
 		switch(errNumber) {
 			case '001': // fallthrough, invalid number (int)
@@ -51,8 +51,8 @@ const exposedFunctionsGlobal = {
 
 	// global search
 	global_search_start:(input) => {
-		if(MyStore.getters.searchModuleIds.length === 0)
-			return console.warn('cannot start global search, no search bars available');
+		if(MyStore.getters.searchModuleIds.length === 0 && !MyStore.getters.searchIndexOn)
+			return console.warn('cannot start global search, no search bars or searchable relations available');
 
 		MyStore.commit('globalSearchInput',input !== undefined ? input : window.getSelection().toString());
 	},
@@ -0,0 +1,47 @@
+import {consoleError} from './error.js';
+
+// record subscriptions inform about changes to records, pushed by the server as 'recordsChanged'
+// subscriptions are bound to the websocket connection, components subscribe again when remounted after reconnecting
+
+let subscriptionCounter = 0;
+
+export function getSubscriptionId(prefix) {
+	subscriptionCounter++;
+	return `${prefix}_${subscriptionCounter}`;
+};
+export function isSubscriptionAffected(change,subscriptionId) {
+	return change !== null && change.subscriptionIds.includes(subscriptionId);
+};
+
+// subscribe to specific records, key: relation ID, value: record IDs
+export function subscribeRecords(subscriptionId,relationIdMapRecordIds) {
+	subscriptionSet({
+		id:subscriptionId,
+		query:null,
+		relationIdMapRecordIds:relationIdMapRecordIds
+	});
+};
+
+// subscribe to records of all relations used in a data GET query
+// changed records must match query filters, unless they are already known (retrieved records, key: relation ID, value: record IDs)
+// deletions are only sent for known records
+export function subscribeQuery(subscriptionId,relationId,joins,filters,relationIdMapRecordIds) {
+	subscriptionSet({
+		id:subscriptionId,
+		query:{ relationId:relationId, joins:joins, filters:filters },
+		relationIdMapRecordIds:relationIdMapRecordIds
+	});
+};
+export function unsubscribe(subscriptionId) {
+	ws.send('subscription','del',{id:subscriptionId},false).then(
+		() => {},
+		consoleError
+	);
+};
+
+function subscriptionSet(subscription) {
+	ws.send('subscription','set',subscription,false).then(
+		() => {},
+		consoleError
+	);
+};
@@ -19,6 +19,7 @@ import {
 
 // admin
 import MyAdmin               from './comps/admin/admin.js';
+import MyAdminAudit          from './comps/admin/adminAudit.js';
 import MyAdminBackups        from './comps/admin/adminBackups.js';
 import MyAdminCaptionMap     from './comps/admin/adminCaptionMap.js';
 import MyAdminCluster        from './comps/admin/adminCluster.js';
@@ -36,8 +37,12 @@ import MyAdminMailSpooler    from './comps/admin/adminMailSpooler.js';
 import MyAdminMailTraffic    from './comps/admin/adminMailTraffic.js';
 import MyAdminModules        from './comps/admin/adminModules.js';
 import MyAdminOauthClients   from './comps/admin/adminOauthClients.js';
+import MyAdminPrivacy        from './comps/admin/adminPrivacy.js';
 import MyAdminRoles          from './comps/admin/adminRoles.js';
+import MyAdminSamlIdps       from './comps/admin/adminSamlIdps.js';
+import MyAdminScimClients    from './comps/admin/adminScimClients.js';
 import MyAdminScheduler      from './comps/admin/adminScheduler.js';
+import MyAdminSearch         from './comps/admin/adminSearch.js';
 import MyAdminSystemMsg      from './comps/admin/adminSystemMsg.js';
 
 // builder
@@ -59,6 +64,7 @@ import MyBuilderLoginForms  from './comps/builder/builderLoginForms.js';
 import MyBuilderMenu        from './comps/builder/builderMenu.js';
 import MyBuilderModule      from './comps/builder/builderModule.js';
 import MyBuilderModules     from './comps/builder/builderModules.js';
+import MyBuilderNumberRanges from './comps/builder/builderNumberRanges.js';
 import MyBuilderPgFunction  from './comps/builder/builderPgFunction.js';
 import MyBuilderPgFunctions from './comps/builder/builderPgFunctions.js';
 import MyBuilderRelation    from './comps/builder/builderRelation.js';
@@ -109,6 +115,7 @@ const MyRouter = VueRouter.createRouter({
 				component:MyAdminModules,
 				meta:{ target:'repo' }
 			},
+			{ path:'audit',           component:MyAdminAudit },
 			{ path:'backups',         component:MyAdminBackups },
 			{ path:'caption-map',     component:MyAdminCaptionMap },
 			{ path:'cluster',         component:MyAdminCluster },
@@ -126,8 +133,12 @@ const MyRouter = VueRouter.createRouter({
 			{ path:'mail-traffic',    component:MyAdminMailTraffic },
 			{ path:'modules',         component:MyAdminModules },
 			{ path:'oauth-clients',   component:MyAdminOauthClients },
+			{ path:'privacy',         component:MyAdminPrivacy },
 			{ path:'roles',           component:MyAdminRoles },
+			{ path:'saml-idps',       component:MyAdminSamlIdps },
 			{ path:'scheduler',       component:MyAdminScheduler },
+			{ path:'scim-clients',    component:MyAdminScimClients },
+			{ path:'search',          component:MyAdminSearch },
 			{ path:'system-msg',      component:MyAdminSystemMsg }
 		]
 	},{
@@ -263,6 +274,11 @@ const MyRouter = VueRouter.createRouter({
 				meta:{ nav:'releases', target:'module' },
 				component:MyBuilderReleases,
 				props:true
+			},{
+				path:'number-ranges/:id',
+				meta:{ nav:'number-ranges', target:'module' },
+				component:MyBuilderNumberRanges,
+				props:true
 			},{
 				path:'variables/:id',
 				meta:{ nav:'variables', target:'module' },
@@ -35,6 +35,7 @@ const MyStore = Vuex.createStore({
 		colorMenuDefaultDark:'1e2022', // default menu color, if not overwritten, dark mode
 		config:{},                     // configuration values (admin only)
 		constants:{                    // constant variables, codes/messages/IDs
+			adminPermissions:['builder','logins','logs','mails','system'], // partial admin permissions, as defined in the backend
 			dragFieldContent:'dragDropPrevField', // content name for drag&drop preview fields
 			kdfIterations:10000,       // number of iterations for PBKDF2 key derivation function
 			keyLength:64,              // length of new symmetric keys for data encryption
@@ -47,7 +48,8 @@ const MyStore = Vuex.createStore({
 				ldap:'ldap',
 				local:'local',
 				noAuth:'noAuth',
-				oauth:'oauth'
+				oauth:'oauth',
+				saml:'saml'
 			},
 			hotkeyMod:['ALT','CMD','CTRL','SHIFT'], // modifier keys for hotkeys
 			scrollFormId:'form-scroll' // ID of form page element (to recover scroll position during routing)
@@ -103,7 +105,9 @@ const MyStore = Vuex.createStore({
 		popUpFormGlobal:null,          // configuration of global pop-up form
 		productionMode:false,          // system in production mode, false if maintenance
 		pwaDomainMap:{},               // map of modules per PWA sub domain, key: sub domain, value: module ID
+		recordsChanged:null,           // last change of subscribed records, pushed by server ({ subscriptionIds:[], relationId:UUID, recordIds:[], deleted:false, ... })
 		reposFeedback:[],              // list of repositories with feedback enabled, [ { id:UUID, name:'Prod', url:'https://my-repo.local' }, ... ]
+		samlIdpIdMapLogin:{},          // SAML identity providers for authentication
 		routingGuards:[],              // functions to call before routing, abort if any returns falls
 		searchDictionaries:[],         // dictionaries used for full text search for this login, ['english', 'german', ...]
 		settings:{},                   // setting values for logged in user, key: settings name
@@ -319,7 +323,9 @@ const MyStore = Vuex.createStore({
 		popUpFormGlobal:         (state,payload) => state.popUpFormGlobal          = payload,
 		productionMode:          (state,payload) => state.productionMode           = payload,
 		pwaDomainMap:            (state,payload) => state.pwaDomainMap             = payload,
+		recordsChanged:          (state,payload) => state.recordsChanged           = payload,
 		reposFeedback:           (state,payload) => state.reposFeedback            = payload,
+		samlIdpIdMapLogin:       (state,payload) => state.samlIdpIdMapLogin        = payload,
 		searchDictionaries:      (state,payload) => state.searchDictionaries       = payload,
 		settings:                (state,payload) => state.settings                 = payload,
 		system:                  (state,payload) => state.system                   = payload,
@@ -339,6 +345,12 @@ const MyStore = Vuex.createStore({
 		}
 	},
 	getters:{
+		adminPermissions:(state) => {
+			if(state.isAdmin)
+				return state.constants.adminPermissions;
+			
+			return state.access.adminPermissions !== undefined ? state.access.adminPermissions : [];
+		},
 		colorHeaderAccent:(state,payload) => {
 			let colorRgb = state.colorHeaderDefault;
 			let brighten = 0;
@@ -442,6 +454,14 @@ const MyStore = Vuex.createStore({
 			}
 			return out;
 		},
+		searchIndexOn:(s) => {
+			for(const k in MyStoreSchema.state.relationIdMap) {
+				const r = MyStoreSchema.state.relationIdMap[k];
+				if(r.attributeIdsSearch.length !== 0 && s.access.relation[r.id] !== undefined && s.access.relation[r.id] >= 1)
+					return true;
+			}
+			return false;
+		},
 		
 		// simple
 		access:                  (state) => state.access,
@@ -468,6 +488,7 @@ const MyStore = Vuex.createStore({
 		globalSearchInput:       (state) => state.globalSearchInput,
 		hotkeyModExcl:           (state) => state.hotkeyModExcl,
 		isAdmin:                 (state) => state.isAdmin,
+		isAdminAny:              (state,getters) => getters.adminPermissions.length !== 0,
 		isAllowedMfa:            (state) => state.loginType === state.constants.loginType.local || state.loginType === state.constants.loginType.ldap,
 		isAllowedPwChange:       (state) => state.loginType === state.constants.loginType.local,
 		isAtDialog:              (state) => state.isAtDialog,
@@ -509,7 +530,9 @@ const MyStore = Vuex.createStore({
 		popUpFormGlobal:         (state) => state.popUpFormGlobal,
 		productionMode:          (state) => state.productionMode,
 		pwaDomainMap:            (state) => state.pwaDomainMap,
+		recordsChanged:          (state) => state.recordsChanged,
 		reposFeedback:           (state) => state.reposFeedback,
+		samlIdpIdMapLogin:       (state) => state.samlIdpIdMapLogin,
 		routingGuards:           (state) => state.routingGuards,
 		searchDictionaries:      (state) => state.searchDictionaries,
 		settings:                (state) => state.settings,
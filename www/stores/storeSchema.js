@@ -18,6 +18,7 @@ export default {
 		loginFormIdMap:{},
 		moduleIdMap:{},
 		moduleNameMap:{},
+		numberRangeIdMap:{},
 		pgFunctionIdMap:{},
 		pgTriggerIdMap:{},
 		presetIdMap:{},
@@ -124,6 +125,11 @@ export default {
 					s.clientEventIdMap[clientEvent.id] = clientEvent;
 				}
 
+				// process number ranges
+				for(const numberRange of mod.numberRanges) {
+					s.numberRangeIdMap[numberRange.id] = numberRange;
+				}
+
 				// process variables
 				for(const variable of mod.variables) {
 					s.variableIdMap[variable.id] = variable;
@@ -169,6 +175,7 @@ export default {
 		loginFormIdMap:     s => s.loginFormIdMap,
 		moduleIdMap:        s => s.moduleIdMap,
 		moduleNameMap:      s => s.moduleNameMap,
+		numberRangeIdMap:   s => s.numberRangeIdMap,
 		pgFunctionIdMap:    s => s.pgFunctionIdMap,
 		pgTriggerIdMap:     s => s.pgTriggerIdMap,
 		presetIdMap:        s => s.presetIdMap,